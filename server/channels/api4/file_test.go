@@ -1239,6 +1239,35 @@ func TestGetFilePreview(t *testing.T) {
 	CheckForbiddenStatus(t, resp)
 }
 
+func TestGetQuarantinedFileInfos(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	info, err := th.App.Srv().Store().FileInfo().Save(th.Context, &model.FileInfo{
+		CreatorId:     th.BasicUser.Id,
+		ChannelId:     th.BasicChannel.Id,
+		Name:          "infected.exe",
+		Path:          "quarantine/infected.exe",
+		DeleteAt:      model.GetMillis(),
+		ScanVerdict:   model.FileScanVerdictInfected,
+		ScanSignature: "Eicar-Signature",
+	})
+	require.NoError(t, err)
+
+	_, resp, err := th.Client.GetQuarantinedFileInfos(context.Background(), 0, 10)
+	require.Error(t, err)
+	CheckForbiddenStatus(t, resp)
+
+	infos, resp, err := th.SystemAdminClient.GetQuarantinedFileInfos(context.Background(), 0, 10)
+	require.NoError(t, err)
+	CheckOKStatus(t, resp)
+	require.Len(t, infos, 1)
+	assert.Equal(t, info.Id, infos[0].Id)
+	assert.Equal(t, model.FileScanVerdictInfected, infos[0].ScanVerdict)
+	assert.Equal(t, "Eicar-Signature", infos[0].ScanSignature)
+}
+
 func TestGetFileInfo(t *testing.T) {
 	mainHelper.Parallel(t)
 	th := Setup(t).InitBasic()
@@ -1549,3 +1578,51 @@ func TestSearchFilesAcrossTeams(t *testing.T) {
 	require.Len(t, fileInfos.Order, 1, "wrong search")
 	require.Equal(t, fileInfos.FileInfos[fileInfos.Order[0]].ChannelId, channels[0].Id, "wrong search")
 }
+
+func TestGetFilePreviewWebP(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	if *th.App.Config().FileSettings.DriverName == "" {
+		t.Skip("skipping because no file driver is enabled")
+	}
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableWebPPreviews = true
+	})
+
+	sent, err := testutils.ReadTestFile("test.png")
+	require.NoError(t, err)
+	fileResp, _, err := th.Client.UploadFile(context.Background(), sent, th.BasicChannel.Id, "test.png")
+	require.NoError(t, err)
+	fileID := fileResp.FileInfos[0].Id
+
+	for _, route := range []string{"/thumbnail", "/preview"} {
+		t.Run(route, func(t *testing.T) {
+			get := func(accept string) *http.Response {
+				r, err := th.Client.DoAPIRequestWithHeaders(context.Background(), http.MethodGet,
+					th.Client.APIURL+"/files/"+fileID+route, "", map[string]string{"Accept": accept})
+				require.NoError(t, err)
+				return r
+			}
+
+			r := get("image/avif,image/webp,*/*;q=0.8")
+			defer closeBody(r)
+			assert.Equal(t, "image/webp", r.Header.Get("Content-Type"))
+			assert.Contains(t, r.Header.Values("Vary"), "Accept")
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Greater(t, len(data), 12)
+			assert.Equal(t, "WEBP", string(data[8:12]))
+
+			r = get("*/*")
+			defer closeBody(r)
+			assert.Equal(t, "image/jpeg", r.Header.Get("Content-Type"))
+
+			r = get("image/webp;q=0, */*")
+			defer closeBody(r)
+			assert.Equal(t, "image/jpeg", r.Header.Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/app"
+)
+
+func (api *API) InitPoll() {
+	api.BaseRoutes.Polls.Handle("", api.APISessionRequired(createPoll)).Methods(http.MethodPost)
+	api.BaseRoutes.Post.Handle("/poll", api.APISessionRequired(getPoll)).Methods(http.MethodGet)
+	api.BaseRoutes.Post.Handle("/poll/votes", api.APISessionRequired(votePoll)).Methods(http.MethodPut)
+	api.BaseRoutes.Post.Handle("/poll/votes", api.APISessionRequired(retractPollVotes)).Methods(http.MethodDelete)
+	api.BaseRoutes.Post.Handle("/poll/close", api.APISessionRequired(closePoll)).Methods(http.MethodPost)
+}
+
+func createPoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	var pollRequest model.PollRequest
+	if err := json.NewDecoder(r.Body).Decode(&pollRequest); err != nil {
+		c.SetInvalidParamWithErr("poll", err)
+		return
+	}
+
+	if !model.IsValidId(pollRequest.ChannelId) {
+		c.SetInvalidParam("channel_id")
+		return
+	}
+
+	auditRec := c.MakeAuditRecord(model.AuditEventCreatePoll, model.AuditStatusFail)
+	defer c.LogAuditRecWithLevel(auditRec, app.LevelContent)
+	model.AddEventParameterAuditableToAuditRec(auditRec, "poll", &pollRequest)
+
+	userCreatePostPermissionCheckWithContext(c, pollRequest.ChannelId)
+	if c.Err != nil {
+		return
+	}
+
+	poll, appErr := c.App.CreatePoll(c.AppContext, &pollRequest, c.AppContext.Session().UserId, true)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddEventResultState(poll)
+	auditRec.AddEventObjectType("poll")
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getPoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(*c.AppContext.Session(), c.Params.PostId, model.PermissionReadChannelContent) {
+		c.SetPermissionError(model.PermissionReadChannelContent)
+		return
+	}
+
+	poll, appErr := c.App.GetPoll(c.AppContext, c.Params.PostId, c.AppContext.Session().UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func votePoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	var voteRequest model.PollVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&voteRequest); err != nil {
+		c.SetInvalidParamWithErr("vote", err)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(*c.AppContext.Session(), c.Params.PostId, model.PermissionReadChannelContent) {
+		c.SetPermissionError(model.PermissionReadChannelContent)
+		return
+	}
+
+	poll, appErr := c.App.VotePoll(c.AppContext, c.Params.PostId, c.AppContext.Session().UserId, voteRequest.OptionIds)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func retractPollVotes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(*c.AppContext.Session(), c.Params.PostId, model.PermissionReadChannelContent) {
+		c.SetPermissionError(model.PermissionReadChannelContent)
+		return
+	}
+
+	poll, appErr := c.App.RetractPollVotes(c.AppContext, c.Params.PostId, c.AppContext.Session().UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func closePoll(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord(model.AuditEventClosePoll, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "post_id", c.Params.PostId)
+
+	if !c.App.SessionHasPermissionToChannelByPost(*c.AppContext.Session(), c.Params.PostId, model.PermissionReadChannelContent) {
+		c.SetPermissionError(model.PermissionReadChannelContent)
+		return
+	}
+
+	post, appErr := c.App.GetSinglePost(c.AppContext, c.Params.PostId, false)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	// Polls are closed by their author, or by those who can edit the posts of others.
+	if post.UserId != c.AppContext.Session().UserId && !c.App.SessionHasPermissionToChannel(c.AppContext, *c.AppContext.Session(), post.ChannelId, model.PermissionEditOthersPosts) {
+		c.SetPermissionError(model.PermissionEditOthersPosts)
+		return
+	}
+
+	poll, appErr := c.App.ClosePoll(c.AppContext, c.Params.PostId, c.AppContext.Session().UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddEventResultState(poll)
+	auditRec.AddEventObjectType("poll")
+
+	if err := json.NewEncoder(w).Encode(poll); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
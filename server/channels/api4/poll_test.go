@@ -0,0 +1,146 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestPolls(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	client2 := th.CreateClient()
+	th.LoginBasic2WithClient(client2)
+
+	poll, resp, err := th.Client.CreatePoll(context.Background(), &model.PollRequest{
+		ChannelId: th.BasicChannel.Id,
+		Question:  "Lunch?",
+		Options:   []string{"Pizza", "Salad"},
+	})
+	require.NoError(t, err)
+	CheckCreatedStatus(t, resp)
+	require.Len(t, poll.Options, 2)
+	pizza, salad := poll.Options[0].Id, poll.Options[1].Id
+
+	t.Run("invalid poll", func(t *testing.T) {
+		_, resp, err := th.Client.CreatePoll(context.Background(), &model.PollRequest{
+			ChannelId: th.BasicChannel.Id,
+			Question:  "Lunch?",
+			Options:   []string{"Pizza", "Pizza"},
+		})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("polls can't be posted as posts", func(t *testing.T) {
+		_, resp, err := th.Client.CreatePost(context.Background(), &model.Post{
+			ChannelId: th.BasicChannel.Id,
+			Type:      model.PostTypePoll,
+			Message:   "Lunch?",
+		})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("channel the user can't post to", func(t *testing.T) {
+		channel := th.CreatePrivateChannel()
+		th.RemoveUserFromChannel(th.BasicUser2, channel)
+
+		_, resp, err := client2.CreatePoll(context.Background(), &model.PollRequest{
+			ChannelId: channel.Id,
+			Question:  "Lunch?",
+			Options:   []string{"Pizza", "Salad"},
+		})
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("vote and retract", func(t *testing.T) {
+		voted, _, err := client2.VotePoll(context.Background(), poll.PostId, []string{salad})
+		require.NoError(t, err)
+		assert.Equal(t, []string{salad}, voted.MyVotes)
+		assert.Equal(t, []string{th.BasicUser2.Id}, voted.Options[1].Voters)
+
+		_, resp, err := client2.VotePoll(context.Background(), poll.PostId, []string{pizza, salad})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+
+		got, _, err := th.Client.GetPoll(context.Background(), poll.PostId)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), got.VoterCount)
+		assert.Empty(t, got.MyVotes)
+
+		retracted, _, err := client2.RetractPollVotes(context.Background(), poll.PostId)
+		require.NoError(t, err)
+		assert.Zero(t, retracted.VoterCount)
+	})
+
+	t.Run("poll in a channel the user can't read", func(t *testing.T) {
+		channel := th.CreatePrivateChannel()
+		private, _, err := th.Client.CreatePoll(context.Background(), &model.PollRequest{
+			ChannelId: channel.Id,
+			Question:  "Lunch?",
+			Options:   []string{"Pizza", "Salad"},
+		})
+		require.NoError(t, err)
+
+		_, resp, err := client2.GetPoll(context.Background(), private.PostId)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp, err = client2.VotePoll(context.Background(), private.PostId, []string{private.Options[0].Id})
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("not a poll", func(t *testing.T) {
+		_, resp, err := th.Client.GetPoll(context.Background(), th.BasicPost.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		_, _, err := client2.VotePoll(context.Background(), poll.PostId, []string{pizza})
+		require.NoError(t, err)
+
+		// Only the author of the poll, or users who can edit others' posts, close it.
+		_, resp, err := client2.ClosePoll(context.Background(), poll.PostId)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		closed, _, err := th.Client.ClosePoll(context.Background(), poll.PostId)
+		require.NoError(t, err)
+		assert.True(t, closed.IsClosed())
+		assert.Equal(t, int64(1), closed.Options[0].VoteCount)
+
+		_, resp, err = client2.VotePoll(context.Background(), poll.PostId, []string{salad})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+
+		_, resp, err = th.Client.ClosePoll(context.Background(), poll.PostId)
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("close as an admin", func(t *testing.T) {
+		other, _, err := client2.CreatePoll(context.Background(), &model.PollRequest{
+			ChannelId: th.BasicChannel.Id,
+			Question:  "Dinner?",
+			Options:   []string{"Pizza", "Salad"},
+		})
+		require.NoError(t, err)
+
+		closed, _, err := th.SystemAdminClient.ClosePoll(context.Background(), other.PostId)
+		require.NoError(t, err)
+		assert.True(t, closed.IsClosed())
+	})
+}
@@ -36,6 +36,8 @@ func (api *API) InitPost() {
 
 	api.BaseRoutes.Team.Handle("/posts/search", api.APISessionRequiredDisableWhenBusy(searchPostsInTeam)).Methods(http.MethodPost)
 	api.BaseRoutes.Posts.Handle("/search", api.APISessionRequiredDisableWhenBusy(searchPostsInAllTeams)).Methods(http.MethodPost)
+	api.BaseRoutes.Team.Handle("/posts/search/hybrid", api.APISessionRequiredDisableWhenBusy(searchPostsHybridInTeam)).Methods(http.MethodPost)
+	api.BaseRoutes.Posts.Handle("/search/hybrid", api.APISessionRequiredDisableWhenBusy(searchPostsHybridInAllTeams)).Methods(http.MethodPost)
 	api.BaseRoutes.Post.Handle("", api.APISessionRequired(updatePost)).Methods(http.MethodPut)
 	api.BaseRoutes.Post.Handle("/patch", api.APISessionRequired(patchPost)).Methods(http.MethodPut)
 	api.BaseRoutes.Post.Handle("/restore/{restore_version_id:[A-Za-z0-9]+}", api.APISessionRequired(restorePostVersion)).Methods(http.MethodPost)
@@ -94,6 +96,12 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Polls are posted with their options through the polls API.
+	if post.Type == model.PostTypePoll {
+		c.SetInvalidParam("post.type")
+		return
+	}
+
 	setOnline := r.URL.Query().Get("set_online")
 	setOnlineBool := true // By default, always set online.
 	var err2 error
@@ -762,14 +770,34 @@ func searchPostsInTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	searchPosts(c, w, r, c.Params.TeamId)
+	searchPosts(c, w, r, c.Params.TeamId, false)
 }
 
 func searchPostsInAllTeams(c *Context, w http.ResponseWriter, r *http.Request) {
-	searchPosts(c, w, r, "")
+	searchPosts(c, w, r, "", false)
+}
+
+func searchPostsHybridInTeam(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(*c.AppContext.Session(), c.Params.TeamId, model.PermissionViewTeam) {
+		c.SetPermissionError(model.PermissionViewTeam)
+		return
+	}
+
+	searchPosts(c, w, r, c.Params.TeamId, true)
 }
 
-func searchPosts(c *Context, w http.ResponseWriter, r *http.Request, teamId string) {
+func searchPostsHybridInAllTeams(c *Context, w http.ResponseWriter, r *http.Request) {
+	searchPosts(c, w, r, "", true)
+}
+
+// searchPosts searches the posts of the user, ranking them by merging the full text search
+// results with the semantically similar posts when hybrid is set.
+func searchPosts(c *Context, w http.ResponseWriter, r *http.Request, teamId string, hybrid bool) {
 	var params model.SearchParameter
 	if jsonErr := json.NewDecoder(r.Body).Decode(&params); jsonErr != nil {
 		c.Err = model.NewAppError("searchPosts", "api.post.search_posts.invalid_body.app_error", nil, "", http.StatusBadRequest).Wrap(jsonErr)
@@ -813,7 +841,13 @@ func searchPosts(c *Context, w http.ResponseWriter, r *http.Request, teamId stri
 
 	startTime := time.Now()
 
-	results, err := c.App.SearchPostsForUser(c.AppContext, terms, c.AppContext.Session().UserId, teamId, isOrSearch, includeDeletedChannels, timeZoneOffset, page, perPage)
+	var results *model.PostSearchResults
+	var err *model.AppError
+	if hybrid {
+		results, err = c.App.SearchPostsForUserHybrid(c.AppContext, terms, c.AppContext.Session().UserId, teamId, isOrSearch, includeDeletedChannels, timeZoneOffset, page, perPage)
+	} else {
+		results, err = c.App.SearchPostsForUser(c.AppContext, terms, c.AppContext.Session().UserId, teamId, isOrSearch, includeDeletedChannels, timeZoneOffset, page, perPage)
+	}
 
 	elapsedTime := float64(time.Since(startTime)) / float64(time.Second)
 	metrics := c.App.Metrics()
@@ -3990,6 +3990,53 @@ func TestSearchHashtagPosts(t *testing.T) {
 	CheckUnauthorizedStatus(t, resp)
 }
 
+func TestSearchPostsHybrid(t *testing.T) {
+	mainHelper.Parallel(t)
+
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	th.LoginBasic()
+	client := th.Client
+
+	post := th.CreateMessagePost("the deployment failed")
+	_ = th.CreateMessagePost("what is for lunch")
+
+	terms := "deployment"
+	searchParams := model.SearchParameter{Terms: &terms}
+
+	_, resp, err := client.SearchPostsHybrid(context.Background(), th.BasicTeam.Id, &searchParams)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.SemanticSearchSettings.Enable = model.NewPointer(true)
+		cfg.SemanticSearchSettings.EmbeddingProvider = model.NewPointer(model.SemanticSearchEmbeddingProviderLocal)
+	})
+
+	results, _, err := client.SearchPostsHybrid(context.Background(), th.BasicTeam.Id, &searchParams)
+	require.NoError(t, err)
+	require.NotEmpty(t, results.Order)
+	assert.Equal(t, post.Id, results.Order[0])
+
+	results, _, err = client.SearchPostsHybrid(context.Background(), "", &searchParams)
+	require.NoError(t, err)
+	assert.Contains(t, results.Order, post.Id)
+
+	t.Run("empty terms", func(t *testing.T) {
+		empty := ""
+		_, resp, err := client.SearchPostsHybrid(context.Background(), th.BasicTeam.Id, &model.SearchParameter{Terms: &empty})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("team the user can't view", func(t *testing.T) {
+		team := th.CreateTeamWithClient(th.SystemAdminClient)
+		_, resp, err := client.SearchPostsHybrid(context.Background(), team.Id, &searchParams)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+}
+
 func TestSearchPostsInChannel(t *testing.T) {
 	mainHelper.Parallel(t)
 
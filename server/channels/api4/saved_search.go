@@ -0,0 +1,176 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+func (api *API) InitSavedSearch() {
+	api.BaseRoutes.User.Handle("/saved_searches", api.APISessionRequired(createSavedSearch)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/saved_searches", api.APISessionRequired(getSavedSearches)).Methods(http.MethodGet)
+	api.BaseRoutes.User.Handle("/saved_searches/{saved_search_id:[A-Za-z0-9]+}", api.APISessionRequired(getSavedSearch)).Methods(http.MethodGet)
+	api.BaseRoutes.User.Handle("/saved_searches/{saved_search_id:[A-Za-z0-9]+}/patch", api.APISessionRequired(patchSavedSearch)).Methods(http.MethodPut)
+	api.BaseRoutes.User.Handle("/saved_searches/{saved_search_id:[A-Za-z0-9]+}", api.APISessionRequired(deleteSavedSearch)).Methods(http.MethodDelete)
+}
+
+// getSavedSearchForRequest gets the saved search of the request, checking that it belongs to
+// the user of the request and that the session can access it.
+func getSavedSearchForRequest(c *Context) *model.SavedSearch {
+	c.RequireUserId().RequireSavedSearchId()
+	if c.Err != nil {
+		return nil
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return nil
+	}
+
+	savedSearch, appErr := c.App.GetSavedSearch(c.Params.SavedSearchId)
+	if appErr != nil {
+		c.Err = appErr
+		return nil
+	}
+
+	if savedSearch.UserId != c.Params.UserId {
+		c.Err = model.NewAppError("getSavedSearchForRequest", "app.saved_search.get.app_error", nil, "", http.StatusNotFound)
+		return nil
+	}
+
+	return savedSearch
+}
+
+func createSavedSearch(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	var savedSearch model.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&savedSearch); err != nil {
+		c.SetInvalidParamWithErr("saved_search", err)
+		return
+	}
+	savedSearch.UserId = c.Params.UserId
+
+	auditRec := c.MakeAuditRecord(model.AuditEventCreateSavedSearch, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "user_id", c.Params.UserId)
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	if savedSearch.TeamId != "" && !c.App.SessionHasPermissionToTeam(*c.AppContext.Session(), savedSearch.TeamId, model.PermissionViewTeam) {
+		c.SetPermissionError(model.PermissionViewTeam)
+		return
+	}
+
+	saved, appErr := c.App.CreateSavedSearch(&savedSearch)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddEventResultState(saved)
+	auditRec.AddEventObjectType("saved_search")
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(saved); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getSavedSearches(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	savedSearches, appErr := c.App.GetSavedSearchesForUser(c.Params.UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(savedSearches); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getSavedSearch(c *Context, w http.ResponseWriter, r *http.Request) {
+	savedSearch := getSavedSearchForRequest(c)
+	if c.Err != nil {
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(savedSearch); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func patchSavedSearch(c *Context, w http.ResponseWriter, r *http.Request) {
+	var patch model.SavedSearchPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		c.SetInvalidParamWithErr("saved_search_patch", err)
+		return
+	}
+
+	auditRec := c.MakeAuditRecord(model.AuditEventPatchSavedSearch, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "saved_search_id", c.Params.SavedSearchId)
+
+	savedSearch := getSavedSearchForRequest(c)
+	if c.Err != nil {
+		return
+	}
+	auditRec.AddEventPriorState(savedSearch)
+
+	patched, appErr := c.App.PatchSavedSearch(savedSearch, &patch)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddEventResultState(patched)
+	auditRec.AddEventObjectType("saved_search")
+
+	if err := json.NewEncoder(w).Encode(patched); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func deleteSavedSearch(c *Context, w http.ResponseWriter, r *http.Request) {
+	auditRec := c.MakeAuditRecord(model.AuditEventDeleteSavedSearch, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "saved_search_id", c.Params.SavedSearchId)
+
+	savedSearch := getSavedSearchForRequest(c)
+	if c.Err != nil {
+		return
+	}
+	auditRec.AddEventPriorState(savedSearch)
+
+	if appErr := c.App.DeleteSavedSearch(savedSearch.Id); appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+
+	ReturnStatusOK(w)
+}
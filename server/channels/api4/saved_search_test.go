@@ -0,0 +1,103 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestSavedSearches(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	savedSearch, resp, err := th.Client.CreateSavedSearch(context.Background(), th.BasicUser.Id, &model.SavedSearch{
+		Name:  "Outages",
+		Terms: "outage in:" + th.BasicChannel.Name,
+		Watch: true,
+	})
+	require.NoError(t, err)
+	CheckCreatedStatus(t, resp)
+	assert.Equal(t, th.BasicUser.Id, savedSearch.UserId)
+	require.Len(t, savedSearch.Params, 1)
+	assert.Equal(t, "outage", savedSearch.Params[0].Terms)
+
+	t.Run("invalid saved search", func(t *testing.T) {
+		_, resp, err := th.Client.CreateSavedSearch(context.Background(), th.BasicUser.Id, &model.SavedSearch{Name: "Empty"})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("team the user can't view", func(t *testing.T) {
+		team := th.CreateTeamWithClient(th.SystemAdminClient)
+		_, resp, err := th.Client.CreateSavedSearch(context.Background(), th.BasicUser.Id, &model.SavedSearch{Name: "Team", Terms: "outage", TeamId: team.Id})
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("get and list", func(t *testing.T) {
+		got, _, err := th.Client.GetSavedSearch(context.Background(), th.BasicUser.Id, savedSearch.Id)
+		require.NoError(t, err)
+		assert.Equal(t, savedSearch.Terms, got.Terms)
+
+		savedSearches, _, err := th.Client.GetSavedSearches(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Len(t, savedSearches, 1)
+		assert.Equal(t, savedSearch.Id, savedSearches[0].Id)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		patched, _, err := th.Client.PatchSavedSearch(context.Background(), th.BasicUser.Id, savedSearch.Id, &model.SavedSearchPatch{
+			Terms: model.NewPointer("acme"),
+			Watch: model.NewPointer(false),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Outages", patched.Name)
+		assert.False(t, patched.Watch)
+		require.Len(t, patched.Params, 1)
+		assert.Equal(t, "acme", patched.Params[0].Terms)
+	})
+
+	t.Run("other users can't access the saved searches", func(t *testing.T) {
+		_, resp, err := th.Client.GetSavedSearches(context.Background(), th.BasicUser2.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp, err = th.Client.GetSavedSearch(context.Background(), th.BasicUser2.Id, savedSearch.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		th.LoginBasic2()
+		defer th.LoginBasic()
+
+		_, resp, err = th.Client.GetSavedSearch(context.Background(), th.BasicUser2.Id, savedSearch.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+
+		resp, err = th.Client.DeleteSavedSearch(context.Background(), th.BasicUser2.Id, savedSearch.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+	})
+
+	t.Run("admins can manage the saved searches of users", func(t *testing.T) {
+		savedSearches, _, err := th.SystemAdminClient.GetSavedSearches(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Len(t, savedSearches, 1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := th.Client.DeleteSavedSearch(context.Background(), th.BasicUser.Id, savedSearch.Id)
+		require.NoError(t, err)
+
+		_, resp, err := th.Client.GetSavedSearch(context.Background(), th.BasicUser.Id, savedSearch.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+	})
+}
@@ -46,6 +46,7 @@ func (api *API) InitSystem() {
 	api.BaseRoutes.APIRoot.Handle("/audits", api.APISessionRequired(getAudits)).Methods(http.MethodGet)
 	api.BaseRoutes.APIRoot.Handle("/notifications/test", api.APISessionRequired(testNotifications)).Methods(http.MethodPost)
 	api.BaseRoutes.APIRoot.Handle("/email/test", api.APISessionRequired(testEmail)).Methods(http.MethodPost)
+	api.BaseRoutes.APIRoot.Handle("/email/inbound", api.APISessionRequired(receiveInboundEmail)).Methods(http.MethodPost)
 	api.BaseRoutes.APIRoot.Handle("/site_url/test", api.APISessionRequired(testSiteURL)).Methods(http.MethodPost)
 	api.BaseRoutes.APIRoot.Handle("/file/s3_test", api.APISessionRequired(testS3)).Methods(http.MethodPost)
 	api.BaseRoutes.APIRoot.Handle("/database/recycle", api.APISessionRequired(databaseRecycle)).Methods(http.MethodPost)
@@ -258,6 +259,41 @@ func testEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+// receiveInboundEmail posts a reply received by email, as piped by an MTA with a personal
+// access token of a system admin, to the thread the message was addressed to.
+func receiveInboundEmail(c *Context, w http.ResponseWriter, r *http.Request) {
+	auditRec := c.MakeAuditRecord(model.AuditEventReceiveInboundEmail, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionManageSystem) {
+		c.SetPermissionError(model.PermissionManageSystem)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, *c.App.Config().FileSettings.MaxFileSize)
+	post, appErr := c.App.ProcessInboundEmail(c.AppContext, body)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+
+	// Automatic replies are accepted so that the MTA doesn't bounce them, but not posted
+	if post == nil {
+		ReturnStatusOK(w)
+		return
+	}
+
+	auditRec.AddEventResultState(post)
+	auditRec.AddEventObjectType("post")
+
+	w.WriteHeader(http.StatusCreated)
+	if err := post.EncodeJSON(w); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
 func testSiteURL(c *Context, w http.ResponseWriter, r *http.Request) {
 	if !c.App.SessionHasPermissionToAndNotRestrictedAdmin(*c.AppContext.Session(), model.PermissionTestSiteURL) {
 		c.SetPermissionError(model.PermissionTestSiteURL)
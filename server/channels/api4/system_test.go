@@ -320,6 +320,61 @@ func TestSupportPacketFileName(t *testing.T) {
 	}
 }
 
+func TestReceiveInboundEmail(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.EmailSettings.EnableReplyByEmail = true
+		*cfg.EmailSettings.ReplyByEmailAddress = "reply@example.com"
+		*cfg.EmailSettings.ReplyByEmailSigningKey = model.NewRandomString(32)
+	})
+
+	message := []byte("From: " + th.BasicUser.Email + "\r\n" +
+		"To: " + th.App.ReplyByEmailAddress(th.BasicUser.Id, th.BasicPost.Id) + "\r\n" +
+		"Subject: Re: Notification\r\n" +
+		"\r\n" +
+		"Replying by email\r\n")
+
+	t.Run("requires manage system", func(t *testing.T) {
+		_, resp, err := th.Client.ReceiveInboundEmail(context.Background(), message)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("posts the reply", func(t *testing.T) {
+		post, resp, err := th.SystemAdminClient.ReceiveInboundEmail(context.Background(), message)
+		require.NoError(t, err)
+		CheckCreatedStatus(t, resp)
+		assert.Equal(t, th.BasicUser.Id, post.UserId)
+		assert.Equal(t, th.BasicPost.Id, post.RootId)
+		assert.Equal(t, "Replying by email", post.Message)
+	})
+
+	t.Run("ignores automatic replies", func(t *testing.T) {
+		autoReply := append([]byte("Auto-Submitted: auto-replied\r\n"), message...)
+		post, resp, err := th.SystemAdminClient.ReceiveInboundEmail(context.Background(), autoReply)
+		require.NoError(t, err)
+		CheckOKStatus(t, resp)
+		assert.Nil(t, post)
+	})
+
+	t.Run("invalid message", func(t *testing.T) {
+		_, resp, err := th.SystemAdminClient.ReceiveInboundEmail(context.Background(), []byte("not an email"))
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.EmailSettings.EnableReplyByEmail = false })
+
+		_, resp, err := th.SystemAdminClient.ReceiveInboundEmail(context.Background(), message)
+		require.Error(t, err)
+		CheckNotImplementedStatus(t, resp)
+	})
+}
+
 func TestSiteURLTest(t *testing.T) {
 	mainHelper.Parallel(t)
 	th := Setup(t)
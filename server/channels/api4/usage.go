@@ -19,6 +19,14 @@ func (api *API) InitUsage() {
 	api.BaseRoutes.Usage.Handle("/storage", api.APISessionRequired(getStorageUsage)).Methods(http.MethodGet)
 	// GET /api/v4/usage/teams
 	api.BaseRoutes.Usage.Handle("/teams", api.APISessionRequired(getTeamsUsage)).Methods(http.MethodGet)
+	// GET /api/v4/usage/storage/users
+	api.BaseRoutes.Usage.Handle("/storage/users", api.APISessionRequired(getUsersFileStorageUsage)).Methods(http.MethodGet)
+	// GET /api/v4/usage/storage/users/{user_id}
+	api.BaseRoutes.Usage.Handle("/storage/users/{user_id:[A-Za-z0-9]+}", api.APISessionRequired(getUserFileStorageUsage)).Methods(http.MethodGet)
+	// GET /api/v4/usage/storage/teams
+	api.BaseRoutes.Usage.Handle("/storage/teams", api.APISessionRequired(getTeamsFileStorageUsage)).Methods(http.MethodGet)
+	// GET /api/v4/usage/storage/teams/{team_id}
+	api.BaseRoutes.Usage.Handle("/storage/teams/{team_id:[A-Za-z0-9]+}", api.APISessionRequired(getTeamFileStorageUsage)).Methods(http.MethodGet)
 }
 
 func getPostsUsage(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -79,3 +87,72 @@ func getTeamsUsage(c *Context, w http.ResponseWriter, r *http.Request) {
 		c.Logger.Warn("Error while writing response", mlog.Err(err))
 	}
 }
+
+func getUserFileStorageUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	usage, appErr := c.App.GetUserFileStorageUsage(c.Params.UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getTeamFileStorageUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(*c.AppContext.Session(), c.Params.TeamId, model.PermissionViewTeam) {
+		c.SetPermissionError(model.PermissionViewTeam)
+		return
+	}
+
+	usage, appErr := c.App.GetTeamFileStorageUsage(c.Params.TeamId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(usage); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getUsersFileStorageUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	getFileStorageUsagePage(c, w, model.FileStorageUsageOwnerTypeUser)
+}
+
+func getTeamsFileStorageUsage(c *Context, w http.ResponseWriter, r *http.Request) {
+	getFileStorageUsagePage(c, w, model.FileStorageUsageOwnerTypeTeam)
+}
+
+func getFileStorageUsagePage(c *Context, w http.ResponseWriter, ownerType string) {
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionManageSystem) {
+		c.SetPermissionError(model.PermissionManageSystem)
+		return
+	}
+
+	usages, appErr := c.App.GetFileStorageUsagePage(ownerType, c.Params.Page, c.Params.PerPage)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(usages); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
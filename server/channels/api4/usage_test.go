@@ -99,3 +99,67 @@ func TestGetTeamsUsage(t *testing.T) {
 		assert.Equal(t, int64(3), usage.Active)
 	})
 }
+
+func TestGetFileStorageUsage(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.UserStorageQuotaBytes = 1024
+		*cfg.FileSettings.TeamStorageQuotaBytes = 4096
+	})
+
+	data := make([]byte, 600)
+	_, _, err := th.Client.UploadFile(context.Background(), data, th.BasicChannel.Id, "data.bin")
+	require.NoError(t, err)
+
+	t.Run("user usage", func(t *testing.T) {
+		usage, r, err := th.Client.GetUserFileStorageUsage(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		CheckOKStatus(t, r)
+		assert.EqualValues(t, 600, usage.UsedBytes)
+		assert.EqualValues(t, 1024, usage.QuotaBytes)
+
+		_, r, err = th.Client.GetUserFileStorageUsage(context.Background(), th.BasicUser2.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, r)
+	})
+
+	t.Run("team usage", func(t *testing.T) {
+		usage, r, err := th.Client.GetTeamFileStorageUsage(context.Background(), th.BasicTeam.Id)
+		require.NoError(t, err)
+		CheckOKStatus(t, r)
+		assert.EqualValues(t, 600, usage.UsedBytes)
+		assert.EqualValues(t, 4096, usage.QuotaBytes)
+	})
+
+	t.Run("top users and teams", func(t *testing.T) {
+		_, r, err := th.Client.GetUsersFileStorageUsage(context.Background(), 0, 10)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, r)
+
+		usages, r, err := th.SystemAdminClient.GetUsersFileStorageUsage(context.Background(), 0, 10)
+		require.NoError(t, err)
+		CheckOKStatus(t, r)
+		assert.NotEmpty(t, usages)
+
+		usages, _, err = th.SystemAdminClient.GetTeamsFileStorageUsage(context.Background(), 0, 10)
+		require.NoError(t, err)
+		assert.NotEmpty(t, usages)
+	})
+
+	t.Run("uploads over the quota are rejected", func(t *testing.T) {
+		_, r, err := th.Client.UploadFile(context.Background(), data, th.BasicChannel.Id, "data.bin")
+		require.Error(t, err)
+		CheckRequestEntityTooLargeStatus(t, r)
+
+		_, r, err = th.Client.CreateUpload(context.Background(), &model.UploadSession{
+			ChannelId: th.BasicChannel.Id,
+			Filename:  "data.bin",
+			FileSize:  600,
+		})
+		require.Error(t, err)
+		CheckRequestEntityTooLargeStatus(t, r)
+	})
+}
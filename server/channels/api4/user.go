@@ -63,10 +63,17 @@ func (api *API) InitUser() {
 
 	api.BaseRoutes.User.Handle("/mfa", api.APISessionRequiredMfa(updateUserMfa)).Methods(http.MethodPut)
 	api.BaseRoutes.User.Handle("/mfa/generate", api.APISessionRequiredMfa(generateMfaSecret)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/recovery_codes", api.APISessionRequiredMfa(generateMfaRecoveryCodes)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/recovery_codes", api.APISessionRequiredMfa(revokeMfaRecoveryCodes)).Methods(http.MethodDelete)
+	api.BaseRoutes.User.Handle("/webauthn/register/start", api.APISessionRequiredMfa(startWebAuthnRegistration)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/webauthn/register/finish", api.APISessionRequiredMfa(finishWebAuthnRegistration)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/webauthn/credentials", api.APISessionRequiredMfa(getWebAuthnCredentials)).Methods(http.MethodGet)
+	api.BaseRoutes.User.Handle("/webauthn/credentials/{webauthn_credential_id:[A-Za-z0-9]+}", api.APISessionRequiredMfa(deleteWebAuthnCredential)).Methods(http.MethodDelete)
 
 	api.BaseRoutes.Users.Handle("/login", api.APIHandler(login)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/sso/code-exchange", api.APIHandler(loginSSOCodeExchange)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/desktop_token", api.RateLimitedHandler(api.APIHandler(loginWithDesktopToken), model.RateLimitSettings{PerSec: model.NewPointer(2), MaxBurst: model.NewPointer(1)})).Methods(http.MethodPost)
+	api.BaseRoutes.Users.Handle("/login/webauthn", api.RateLimitedHandler(api.APIHandler(startWebAuthnLogin), model.RateLimitSettings{PerSec: model.NewPointer(2), MaxBurst: model.NewPointer(5)})).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/switch", api.APIHandler(switchAccountType)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/cws", api.APIHandlerTrustRequester(loginCWS)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/logout", api.APIHandler(logout)).Methods(http.MethodPost)
@@ -1774,7 +1781,8 @@ func updateUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	c.LogAudit("attempt")
 
-	if appErr := c.App.UpdateMfa(c.AppContext, activate, c.Params.UserId, code); appErr != nil {
+	recoveryCodes, appErr := c.App.UpdateMfa(c.AppContext, activate, c.Params.UserId, code)
+	if appErr != nil {
 		c.Err = appErr
 		return
 	}
@@ -1783,7 +1791,17 @@ func updateUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("activate", activate)
 	c.LogAudit("success - mfa updated")
 
-	ReturnStatusOK(w)
+	if !activate {
+		ReturnStatusOK(w)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	if err := json.NewEncoder(w).Encode(map[string]any{"status": "OK", "recovery_codes": recoveryCodes}); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
 }
 
 func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -1817,6 +1835,103 @@ func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func generateMfaRecoveryCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord(model.AuditEventGenerateMfaRecoveryCodes, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "user_id", c.Params.UserId)
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	// Recovery codes bypass the second factor of the user, so they are only ever returned to
+	// the user themselves, once they re-authenticated. Other users can only revoke them.
+	if c.Params.UserId != c.AppContext.Session().UserId {
+		c.Err = model.NewAppError("generateMfaRecoveryCodes", "api.user.generate_mfa_recovery_codes.other_user.app_error", nil, "", http.StatusForbidden)
+		return
+	}
+
+	props := model.MapFromJSON(r.Body)
+	password, code := props["password"], props["code"]
+	if password == "" && code == "" {
+		c.SetInvalidParam("password")
+		return
+	}
+
+	user, appErr := c.App.GetUser(c.Params.UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	if password != "" {
+		appErr = c.App.DoubleCheckPassword(c.AppContext, user, password)
+	} else if !user.MfaActive {
+		appErr = model.NewAppError("generateMfaRecoveryCodes", "api.user.generate_mfa_recovery_codes.not_active.app_error", nil, "", http.StatusBadRequest)
+	} else {
+		appErr = c.App.DoubleCheckMfa(c.AppContext, user.Id, code)
+	}
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	recoveryCodes, appErr := c.App.GenerateMfaRecoveryCodes(c.Params.UserId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	c.LogAudit("success - mfa recovery codes generated")
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	if err := json.NewEncoder(w).Encode(&model.MfaRecoveryCodes{RecoveryCodes: recoveryCodes}); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func revokeMfaRecoveryCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord(model.AuditEventRevokeMfaRecoveryCodes, model.AuditStatusFail)
+	defer c.LogAuditRec(auditRec)
+	model.AddEventParameterToAuditRec(auditRec, "user_id", c.Params.UserId)
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	if appErr := c.App.RevokeMfaRecoveryCodes(c.Params.UserId); appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.Success()
+	c.LogAudit("success - mfa recovery codes revoked")
+
+	ReturnStatusOK(w)
+}
+
 func updatePassword(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireUserId()
 	if c.Err != nil {
@@ -1949,6 +2064,7 @@ func login(c *Context, w http.ResponseWriter, r *http.Request) {
 		unmaskedErrors := []string{
 			"mfa.validate_token.authenticate.app_error",
 			"api.user.check_user_mfa.bad_code.app_error",
+			"api.user.check_user_mfa.webauthn_required.app_error",
 			"api.user.login.blank_pwd.app_error",
 			"api.user.login.bot_login_forbidden.app_error",
 			"api.user.login.remote_users.login.error",
@@ -32,6 +32,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/utils/testutils"
 	"github.com/mattermost/mattermost/server/v8/einterfaces/mocks"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
 
 	_ "github.com/mattermost/mattermost/server/v8/channels/app/oauthproviders/gitlab"
 )
@@ -3637,6 +3638,156 @@ func TestUserLoginMFAFlow(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotNil(t, user)
 	})
+
+	t.Run("WithRecoveryCode", func(t *testing.T) {
+		recoveryCodes, appErr := th.App.GenerateMfaRecoveryCodes(th.BasicUser.Id)
+		require.Nil(t, appErr)
+
+		user, _, err := th.Client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, recoveryCodes[0])
+		require.NoError(t, err)
+		assert.NotNil(t, user)
+
+		user, _, err = th.Client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, recoveryCodes[0])
+		CheckErrorID(t, err, "api.user.check_user_mfa.bad_code.app_error")
+		assert.Nil(t, user)
+
+		user, _, err = th.Client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, strings.ToUpper(recoveryCodes[1]))
+		require.NoError(t, err)
+		assert.NotNil(t, user)
+	})
+}
+
+func TestGenerateMfaRecoveryCodes(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableMultifactorAuthentication = false })
+
+	_, resp, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, th.BasicUser.Password, "")
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableMultifactorAuthentication = true })
+
+	_, resp, err = th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, th.BasicUser.Password, "")
+	require.Error(t, err)
+	CheckBadRequestStatus(t, resp)
+	CheckErrorID(t, err, "api.user.generate_mfa_recovery_codes.not_active.app_error")
+
+	secret, _, err := th.Client.GenerateMfaSecret(context.Background(), th.BasicUser.Id)
+	require.NoError(t, err)
+
+	code := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30))
+	activated, _, err := th.Client.ActivateUserMfa(context.Background(), th.BasicUser.Id, code)
+	require.NoError(t, err)
+	require.Len(t, activated.RecoveryCodes, mfa.RecoveryCodeCount)
+
+	t.Run("re-authentication is required", func(t *testing.T) {
+		_, resp, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "", "")
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+
+		_, resp, err = th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "wrong password", "")
+		require.Error(t, err)
+		CheckUnauthorizedStatus(t, resp)
+
+		_, resp, err = th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "", "000000")
+		require.Error(t, err)
+		CheckUnauthorizedStatus(t, resp)
+	})
+
+	t.Run("with password", func(t *testing.T) {
+		regenerated, _, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, th.BasicUser.Password, "")
+		require.NoError(t, err)
+		require.Len(t, regenerated.RecoveryCodes, mfa.RecoveryCodeCount)
+		assert.NotContains(t, regenerated.RecoveryCodes, activated.RecoveryCodes[0])
+	})
+
+	t.Run("with mfa token", func(t *testing.T) {
+		// Use the next time step, the current one was already used to activate MFA.
+		code := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30+1))
+		regenerated, _, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "", code)
+		require.NoError(t, err)
+		require.Len(t, regenerated.RecoveryCodes, mfa.RecoveryCodeCount)
+	})
+
+	t.Run("failed mfa tokens count towards the login lockout", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.MaximumLoginAttempts = 2 })
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.ServiceSettings.MaximumLoginAttempts = model.ServiceSettingsDefaultMaxLoginAttempts
+		})
+
+		for range 2 {
+			_, resp, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "", "000000")
+			require.Error(t, err)
+			CheckUnauthorizedStatus(t, resp)
+		}
+
+		code := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30))
+		_, resp, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, "", code)
+		require.Error(t, err)
+		CheckUnauthorizedStatus(t, resp)
+		CheckErrorID(t, err, "api.user.check_user_login_attempts.too_many.app_error")
+
+		require.NoError(t, th.App.Srv().Store().User().UpdateFailedPasswordAttempts(th.BasicUser.Id, 0))
+		th.App.InvalidateCacheForUser(th.BasicUser.Id)
+	})
+
+	t.Run("other users can't generate codes", func(t *testing.T) {
+		_, resp, err := th.Client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser2.Id, th.BasicUser.Password, "")
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp, err = th.SystemAdminClient.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id, th.SystemAdminUser.Password, "")
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("oauth session", func(t *testing.T) {
+		client := th.CreateClient()
+		_, _, err := client.Login(context.Background(), th.BasicUser2.Email, th.BasicUser2.Password)
+		require.NoError(t, err)
+
+		session, _ := th.App.GetSession(client.AuthToken)
+		session.IsOAuth = true
+		th.App.AddSessionToCache(session)
+
+		_, resp, err := client.GenerateMfaRecoveryCodes(context.Background(), th.BasicUser2.Id, th.BasicUser2.Password, "")
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+}
+
+func TestRevokeMfaRecoveryCodes(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableMultifactorAuthentication = true })
+
+	generateCodes := func() []string {
+		t.Helper()
+		secret, appErr := th.App.GenerateMfaSecret(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		code := fmt.Sprintf("%06d", dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30))
+		recoveryCodes, appErr := th.App.ActivateMfa(th.BasicUser.Id, code)
+		require.Nil(t, appErr)
+		return recoveryCodes
+	}
+
+	recoveryCodes := generateCodes()
+
+	resp, err := th.Client.RevokeMfaRecoveryCodes(context.Background(), th.BasicUser2.Id)
+	require.Error(t, err)
+	CheckForbiddenStatus(t, resp)
+
+	// Admins revoke the codes of other users, without ever seeing them.
+	_, err = th.SystemAdminClient.RevokeMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	require.NoError(t, err)
+
+	_, _, err = th.Client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, recoveryCodes[0])
+	CheckErrorID(t, err, "api.user.check_user_mfa.bad_code.app_error")
 }
 
 func TestGenerateMfaSecret(t *testing.T) {
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestWebAuthnRegistration(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.SiteURL = "https://chat.example.com"
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = false
+	})
+
+	_, resp, err := th.Client.StartWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableWebAuthn = true })
+
+	options, _, err := th.Client.StartWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "chat.example.com", options.RelyingParty.Id)
+	assert.Equal(t, th.BasicUser.Username, options.User.Name)
+	assert.NotEmpty(t, options.Challenge)
+	assert.NotEmpty(t, options.PubKeyCredParams)
+
+	t.Run("users can only register their own credentials", func(t *testing.T) {
+		_, resp, err := th.SystemAdminClient.StartWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp, err = th.Client.FinishWebAuthnRegistration(context.Background(), th.BasicUser2.Id, &model.WebAuthnAttestationResponse{Name: "Key"})
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("invalid registration response", func(t *testing.T) {
+		_, resp, err := th.Client.FinishWebAuthnRegistration(context.Background(), th.BasicUser.Id, &model.WebAuthnAttestationResponse{Name: "Key"})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+
+	t.Run("list credentials", func(t *testing.T) {
+		credentials, _, err := th.Client.GetWebAuthnCredentials(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		assert.Empty(t, credentials)
+
+		_, resp, err := th.Client.GetWebAuthnCredentials(context.Background(), th.BasicUser2.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, _, err = th.SystemAdminClient.GetWebAuthnCredentials(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+	})
+
+	t.Run("delete credential", func(t *testing.T) {
+		credential, err := th.App.Srv().Store().WebAuthnCredential().Save(&model.WebAuthnCredential{
+			UserId:       th.BasicUser2.Id,
+			Name:         "Security key",
+			CredentialId: model.NewId(),
+			PublicKey:    []byte{0xa5},
+		})
+		require.NoError(t, err)
+
+		resp, err := th.Client.DeleteWebAuthnCredential(context.Background(), th.BasicUser.Id, credential.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+
+		resp, err = th.Client.DeleteWebAuthnCredential(context.Background(), th.BasicUser2.Id, credential.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, err = th.SystemAdminClient.DeleteWebAuthnCredential(context.Background(), th.BasicUser2.Id, credential.Id)
+		require.NoError(t, err)
+
+		credentials, _, err := th.SystemAdminClient.GetWebAuthnCredentials(context.Background(), th.BasicUser2.Id)
+		require.NoError(t, err)
+		assert.Empty(t, credentials)
+	})
+}
+
+func TestWebAuthnLoginOptions(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.SiteURL = "https://chat.example.com"
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = true
+	})
+
+	_, err := th.Client.Logout(context.Background())
+	require.NoError(t, err)
+
+	_, resp, err := th.Client.GetWebAuthnLoginOptions(context.Background(), "")
+	require.Error(t, err)
+	CheckBadRequestStatus(t, resp)
+
+	options, _, err := th.Client.GetWebAuthnLoginOptions(context.Background(), th.BasicUser.Email)
+	require.NoError(t, err)
+	assert.Equal(t, "chat.example.com", options.RelyingPartyId)
+	assert.NotEmpty(t, options.Challenge)
+	assert.Empty(t, options.AllowCredentials)
+
+	options, _, err = th.Client.GetWebAuthnLoginOptions(context.Background(), "unknown"+model.NewId())
+	require.NoError(t, err)
+	assert.NotEmpty(t, options.Challenge)
+	assert.Empty(t, options.AllowCredentials)
+}
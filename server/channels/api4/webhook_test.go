@@ -5,6 +5,8 @@ package api4
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -1079,6 +1081,147 @@ func TestRegenOutgoingHookToken(t *testing.T) {
 	CheckNotImplementedStatus(t, resp)
 }
 
+func TestRegenOutgoingHookSigningSecret(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	client := th.Client
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableOutgoingWebhooks = true })
+
+	hook := &model.OutgoingWebhook{ChannelId: th.BasicChannel.Id, TeamId: th.BasicChannel.TeamId, CallbackURLs: []string{"http://nowhere.com"}}
+	rhook, _, err := th.SystemAdminClient.CreateOutgoingWebhook(context.Background(), hook)
+	require.NoError(t, err)
+	require.NotEmpty(t, rhook.SigningSecret)
+
+	_, resp, err := th.SystemAdminClient.RegenOutgoingHookSigningSecret(context.Background(), "junk")
+	require.Error(t, err)
+	CheckBadRequestStatus(t, resp)
+
+	regenHook, _, err := th.SystemAdminClient.RegenOutgoingHookSigningSecret(context.Background(), rhook.Id)
+	require.NoError(t, err)
+	require.NotEmpty(t, regenHook.SigningSecret)
+	require.NotEqual(t, rhook.SigningSecret, regenHook.SigningSecret, "regen didn't work properly")
+	require.Equal(t, rhook.Token, regenHook.Token)
+
+	_, resp, err = client.RegenOutgoingHookSigningSecret(context.Background(), rhook.Id)
+	require.Error(t, err)
+	CheckForbiddenStatus(t, resp)
+
+	t.Run("update does not change the signing secret", func(t *testing.T) {
+		regenHook.SigningSecret = "changed"
+		updatedHook, _, err := th.SystemAdminClient.UpdateOutgoingWebhook(context.Background(), regenHook)
+		require.NoError(t, err)
+
+		fetchedHook, _, err := th.SystemAdminClient.GetOutgoingWebhook(context.Background(), updatedHook.Id)
+		require.NoError(t, err)
+		require.NotEqual(t, "changed", fetchedHook.SigningSecret)
+	})
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableOutgoingWebhooks = false })
+	_, resp, err = th.SystemAdminClient.RegenOutgoingHookSigningSecret(context.Background(), rhook.Id)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+}
+
+func TestOutgoingHookDeliveries(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	client := th.Client
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableOutgoingWebhooks = true
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost,127.0.0.1"
+	})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	hook := &model.OutgoingWebhook{ChannelId: th.BasicChannel.Id, TeamId: th.BasicChannel.TeamId, CallbackURLs: []string{ts.URL}}
+	rhook, _, err := th.SystemAdminClient.CreateOutgoingWebhook(context.Background(), hook)
+	require.NoError(t, err)
+
+	delivery, err := th.App.Srv().Store().OutgoingWebhookDelivery().Save(&model.OutgoingWebhookDelivery{
+		HookId:        rhook.Id,
+		TeamId:        rhook.TeamId,
+		ChannelId:     th.BasicChannel.Id,
+		PostId:        th.BasicPost.Id,
+		CallbackURL:   ts.URL,
+		ContentType:   "application/json",
+		Payload:       `{"text":"hello"}`,
+		Status:        model.OutgoingWebhookDeliveryStatusDead,
+		Attempts:      model.OutgoingWebhookDeliveryMaxAttempts,
+		LastError:     "connection refused",
+		NextAttemptAt: 0,
+	})
+	require.NoError(t, err)
+
+	t.Run("list", func(t *testing.T) {
+		deliveries, _, err := th.SystemAdminClient.GetOutgoingWebhookDeliveries(context.Background(), rhook.Id, "", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, delivery.Id, deliveries[0].Id)
+
+		deliveries, _, err = th.SystemAdminClient.GetOutgoingWebhookDeliveries(context.Background(), rhook.Id, model.OutgoingWebhookDeliveryStatusPending, 0, 10)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		_, resp, err := th.SystemAdminClient.GetOutgoingWebhookDeliveries(context.Background(), rhook.Id, "junk", 0, 10)
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+
+		_, resp, err = client.GetOutgoingWebhookDeliveries(context.Background(), rhook.Id, "", 0, 10)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		fetched, _, err := th.SystemAdminClient.GetOutgoingWebhookDelivery(context.Background(), rhook.Id, delivery.Id)
+		require.NoError(t, err)
+		require.Equal(t, delivery.Payload, fetched.Payload)
+
+		_, resp, err := th.SystemAdminClient.GetOutgoingWebhookDelivery(context.Background(), rhook.Id, model.NewId())
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+
+		_, resp, err = client.GetOutgoingWebhookDelivery(context.Background(), rhook.Id, delivery.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("delivery of another hook", func(t *testing.T) {
+		otherHook, _, err := th.SystemAdminClient.CreateOutgoingWebhook(context.Background(), &model.OutgoingWebhook{ChannelId: th.BasicChannel.Id, TeamId: th.BasicChannel.TeamId, CallbackURLs: []string{ts.URL}})
+		require.NoError(t, err)
+
+		_, resp, err := th.SystemAdminClient.GetOutgoingWebhookDelivery(context.Background(), otherHook.Id, delivery.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+
+		_, resp, err = th.SystemAdminClient.RedeliverOutgoingWebhookDelivery(context.Background(), otherHook.Id, delivery.Id)
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		_, resp, err := client.RedeliverOutgoingWebhookDelivery(context.Background(), rhook.Id, delivery.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		redelivered, _, err := th.SystemAdminClient.RedeliverOutgoingWebhookDelivery(context.Background(), rhook.Id, delivery.Id)
+		require.NoError(t, err)
+		require.Equal(t, model.OutgoingWebhookDeliveryStatusDelivered, redelivered.Status)
+		require.Equal(t, model.OutgoingWebhookDeliveryMaxAttempts+1, redelivered.Attempts)
+	})
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableOutgoingWebhooks = false })
+	_, resp, err := th.SystemAdminClient.GetOutgoingWebhookDeliveries(context.Background(), rhook.Id, "", 0, 10)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+}
+
 func TestUpdateOutgoingHook(t *testing.T) {
 	mainHelper.Parallel(t)
 	th := Setup(t).InitBasic()
@@ -92,7 +92,7 @@ func (a *App) checkUserPassword(user *model.User, password string, invalidateCac
 	}
 
 	// Migrate the password if needed
-	if !hashers.IsLatestHasher(hasher) {
+	if hasher != a.Srv().PasswordHasher() {
 		return a.migratePassword(user, password)
 	}
 
@@ -100,10 +100,10 @@ func (a *App) checkUserPassword(user *model.User, password string, invalidateCac
 }
 
 // migratePassword updates the database with the user's password hashed with the
-// latest hashing method. It assumes that the password has been already validated.
+// configured hashing method. It assumes that the password has been already validated.
 func (a *App) migratePassword(user *model.User, password string) *model.AppError {
-	// Compute the new hash with the latest hashing method
-	newHash, err := hashers.Hash(password)
+	// Compute the new hash with the configured hashing method
+	newHash, err := a.Srv().PasswordHasher().Hash(password)
 	if err != nil {
 		return model.NewAppError("migratePassword", "app.user.check_user_password.failed_migration", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
@@ -117,6 +117,43 @@ func (a *App) migratePassword(user *model.User, password string) *model.AppError
 	return nil
 }
 
+// passwordHasherFromSettings returns the hasher selected in the password settings.
+func passwordHasherFromSettings(settings *model.PasswordSettings) (hashers.PasswordHasher, error) {
+	switch *settings.HashingAlgorithm {
+	case model.PasswordHashingAlgorithmArgon2id:
+		return hashers.NewArgon2id(uint32(*settings.Argon2idMemoryKiB), uint32(*settings.Argon2idIterations), uint8(*settings.Argon2idParallelism))
+	default:
+		return hashers.DefaultPBKDF2(), nil
+	}
+}
+
+// configurePasswordHasher makes the hasher selected in the password settings the
+// one used for updated passwords. Passwords hashed with any other hasher, including
+// the ones of new users hashed when saved, are migrated to it when their users
+// next log in.
+func (s *Server) configurePasswordHasher() {
+	hasher, err := passwordHasherFromSettings(&s.platform.Config().PasswordSettings)
+	if err != nil {
+		mlog.Error("Invalid password hashing settings, keeping the current hasher", mlog.Err(err))
+		return
+	}
+
+	s.passwordHasherMut.Lock()
+	defer s.passwordHasherMut.Unlock()
+	s.passwordHasher = hasher
+}
+
+// PasswordHasher returns the hasher selected in the password settings, or the
+// default one until the settings are applied.
+func (s *Server) PasswordHasher() hashers.PasswordHasher {
+	s.passwordHasherMut.RLock()
+	defer s.passwordHasherMut.RUnlock()
+	if s.passwordHasher == nil {
+		return hashers.DefaultPBKDF2()
+	}
+	return s.passwordHasher
+}
+
 func (a *App) CheckPasswordAndAllCriteria(rctx request.CTX, userID string, password string, mfaToken string) *model.AppError {
 	// MM-37585
 	// Use locks to avoid concurrently checking AND updating the failed login attempts.
@@ -183,6 +220,41 @@ func (a *App) DoubleCheckPassword(rctx request.CTX, user *model.User, password s
 	return nil
 }
 
+// DoubleCheckMfa checks the MFA token of a logged in user re-authenticating. As when logging in,
+// failed attempts count towards the maximum number of login attempts.
+func (a *App) DoubleCheckMfa(rctx request.CTX, userID string, mfaToken string) *model.AppError {
+	// MM-37585
+	// Use locks to avoid concurrently checking AND updating the failed login attempts.
+	a.ch.emailLoginAttemptsMut.Lock()
+	defer a.ch.emailLoginAttemptsMut.Unlock()
+
+	user, err := a.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkUserLoginAttempts(user, *a.Config().ServiceSettings.MaximumLoginAttempts); err != nil {
+		return err
+	}
+
+	if err := a.CheckUserMfa(rctx, user, mfaToken); err != nil {
+		if passErr := a.Srv().Store().User().UpdateFailedPasswordAttempts(user.Id, user.FailedAttempts+1); passErr != nil {
+			return model.NewAppError("DoubleCheckMfa", "app.user.update_failed_pwd_attempts.app_error", nil, "", http.StatusInternalServerError).Wrap(passErr)
+		}
+		a.InvalidateCacheForUser(user.Id)
+
+		return err
+	}
+
+	if passErr := a.Srv().Store().User().UpdateFailedPasswordAttempts(user.Id, 0); passErr != nil {
+		return model.NewAppError("DoubleCheckMfa", "app.user.update_failed_pwd_attempts.app_error", nil, "", http.StatusInternalServerError).Wrap(passErr)
+	}
+
+	a.InvalidateCacheForUser(user.Id)
+
+	return nil
+}
+
 func (a *App) checkLdapUserPasswordAndAllCriteria(rctx request.CTX, user *model.User, password, mfaToken string) (*model.User, *model.AppError) {
 	// MM-37585: Use locks to avoid concurrently checking AND updating the failed login attempts.
 	a.ch.ldapLoginAttemptsMut.Lock()
@@ -312,6 +384,29 @@ func (a *App) CheckUserMfa(rctx request.CTX, user *model.User, token string) *mo
 		return model.NewAppError("CheckUserMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
+	if model.IsWebAuthnAssertion(token) {
+		return a.checkUserWebAuthnAssertion(rctx, user, token)
+	}
+
+	if mfa.IsRecoveryCode(token) {
+		return a.checkUserMfaRecoveryCode(rctx, user, token)
+	}
+
+	if a.isWebAuthnEnforced() {
+		hasCredentials, appErr := a.hasWebAuthnCredentials(user.Id)
+		if appErr != nil {
+			return appErr
+		}
+		if hasCredentials {
+			return model.NewAppError("checkUserMfa", "api.user.check_user_mfa.webauthn_required.app_error", nil, "", http.StatusUnauthorized)
+		}
+	}
+
+	// Users with only WebAuthn credentials have no TOTP secret to validate against.
+	if user.MfaSecret == "" {
+		return model.NewAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+	}
+
 	ok, err := mfa.New(a.Srv().Store().User()).ValidateToken(user, token)
 	if err != nil {
 		return model.NewAppError("CheckUserMfa", "mfa.validate_token.authenticate.app_error", nil, "", http.StatusBadRequest).Wrap(err)
@@ -324,6 +419,36 @@ func (a *App) CheckUserMfa(rctx request.CTX, user *model.User, token string) *mo
 	return nil
 }
 
+// checkUserMfaRecoveryCode redeems one of the user's recovery codes in place of an MFA token.
+// Every attempt is audited, since a recovery code bypasses the user's usual second factor.
+func (a *App) checkUserMfaRecoveryCode(rctx request.CTX, user *model.User, code string) *model.AppError {
+	auditRec := a.MakeAuditRecord(rctx, model.AuditEventUseMfaRecoveryCode, model.AuditStatusFail)
+	auditRec.Actor.UserId = user.Id
+	auditRec.Actor.IpAddress = rctx.IPAddress()
+	auditRec.Actor.XForwardedFor = rctx.XForwardedFor()
+	auditRec.AddMeta(model.AuditKeyAPIPath, rctx.Path())
+	model.AddEventParameterToAuditRec(auditRec, "user_id", user.Id)
+
+	var appErr *model.AppError
+	defer func() { a.LogAuditRec(rctx, auditRec, appErr) }()
+
+	ok, err := mfa.New(a.Srv().Store().User()).ValidateToken(user, code)
+	if err != nil {
+		appErr = model.NewAppError("CheckUserMfa", "mfa.validate_token.authenticate.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+		return appErr
+	}
+
+	if !ok {
+		appErr = model.NewAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+		return appErr
+	}
+
+	auditRec.Success()
+	rctx.Logger().Info("MFA recovery code used", mlog.String("user_id", user.Id))
+
+	return nil
+}
+
 func (a *App) MFARequired(rctx request.CTX) *model.AppError {
 	if license := a.Channels().License(); license == nil || !*license.Features.MFA || !*a.Config().ServiceSettings.EnableMultifactorAuthentication || !*a.Config().ServiceSettings.EnforceMultifactorAuthentication {
 		return nil
@@ -370,6 +495,16 @@ func (a *App) MFARequired(rctx request.CTX) *model.AppError {
 		return model.NewAppError("MfaRequired", "api.context.mfa_required.app_error", nil, "", http.StatusForbidden)
 	}
 
+	if a.isWebAuthnEnforced() {
+		hasCredentials, appErr := a.hasWebAuthnCredentials(user.Id)
+		if appErr != nil {
+			return appErr
+		}
+		if !hasCredentials {
+			return model.NewAppError("MfaRequired", "api.context.webauthn_required.app_error", nil, "", http.StatusForbidden)
+		}
+	}
+
 	return nil
 }
 
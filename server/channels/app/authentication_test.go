@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -160,6 +161,63 @@ func TestCheckPasswordAndAllCriteria(t *testing.T) {
 	})
 }
 
+func TestCheckUserMfaRecoveryCode(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+	})
+
+	user := th.CreateUser()
+	secret, appErr := th.App.GenerateMfaSecret(user.Id)
+	require.Nil(t, appErr)
+
+	code := dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30)
+	recoveryCodes, appErr := th.App.ActivateMfa(user.Id, fmt.Sprintf("%06d", code))
+	require.Nil(t, appErr)
+	require.NotEmpty(t, recoveryCodes)
+
+	user, appErr = th.App.GetUser(user.Id)
+	require.Nil(t, appErr)
+
+	appErr = th.App.CheckUserMfa(th.Context, user, recoveryCodes[0])
+	require.Nil(t, appErr)
+
+	appErr = th.App.CheckUserMfa(th.Context, user, recoveryCodes[0])
+	require.NotNil(t, appErr, "recovery codes are single use")
+	require.Equal(t, "api.user.check_user_mfa.bad_code.app_error", appErr.Id)
+
+	t.Run("regenerating replaces the previous codes", func(t *testing.T) {
+		newCodes, appErr := th.App.GenerateMfaRecoveryCodes(user.Id)
+		require.Nil(t, appErr)
+
+		appErr = th.App.CheckUserMfa(th.Context, user, recoveryCodes[1])
+		require.NotNil(t, appErr)
+
+		appErr = th.App.CheckUserMfa(th.Context, user, newCodes[0])
+		require.Nil(t, appErr)
+	})
+
+	t.Run("deactivating removes the codes", func(t *testing.T) {
+		newCodes, appErr := th.App.GenerateMfaRecoveryCodes(user.Id)
+		require.Nil(t, appErr)
+
+		appErr = th.App.DeactivateMfa(user.Id)
+		require.Nil(t, appErr)
+
+		_, appErr = th.App.GenerateMfaRecoveryCodes(user.Id)
+		require.NotNil(t, appErr)
+		require.Equal(t, "api.user.generate_mfa_recovery_codes.not_active.app_error", appErr.Id)
+
+		// Check the codes directly, since users without MFA are not asked for a token.
+		user.MfaActive = true
+		appErr = th.App.CheckUserMfa(th.Context, user, newCodes[0])
+		require.NotNil(t, appErr)
+	})
+}
+
 func TestCheckLdapUserPasswordAndAllCriteria(t *testing.T) {
 	th := SetupEnterprise(t).InitBasic()
 	defer th.TearDown()
@@ -499,6 +557,51 @@ func TestCheckUserPassword(t *testing.T) {
 		appErr = th.App.checkUserPassword(user, pwd, false)
 		require.Nil(t, appErr)
 	})
+
+	t.Run("password migration to argon2id when configured", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PasswordSettings.HashingAlgorithm = model.PasswordHashingAlgorithmArgon2id
+			*cfg.PasswordSettings.Argon2idMemoryKiB = 1024
+			*cfg.PasswordSettings.Argon2idIterations = 1
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PasswordSettings.HashingAlgorithm = model.PasswordHashingAlgorithmPBKDF2
+		})
+
+		for name, hash := range map[string]string{"bcrypt": pwdBcrypt, "pbkdf2": pwdPBKDF2} {
+			t.Run(name, func(t *testing.T) {
+				user := createUserWithHash(hash)
+
+				appErr := th.App.checkUserPassword(user, pwd, false)
+				require.Nil(t, appErr)
+
+				updatedUser, appErr := th.App.GetUser(user.Id)
+				require.Nil(t, appErr)
+				require.True(t, strings.HasPrefix(updatedUser.Password, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+				// Re-check with updated password
+				appErr = th.App.checkUserPassword(updatedUser, pwd, false)
+				require.Nil(t, appErr)
+			})
+		}
+
+		t.Run("switching back to pbkdf2", func(t *testing.T) {
+			th.App.UpdateConfig(func(cfg *model.Config) {
+				*cfg.PasswordSettings.HashingAlgorithm = model.PasswordHashingAlgorithmPBKDF2
+			})
+
+			argon2idHash, err := hashers.DefaultArgon2id().Hash(pwd)
+			require.NoError(t, err)
+			user := createUserWithHash(argon2idHash)
+
+			appErr := th.App.checkUserPassword(user, pwd, false)
+			require.Nil(t, appErr)
+
+			updatedUser, appErr := th.App.GetUser(user.Id)
+			require.Nil(t, appErr)
+			require.Contains(t, updatedUser.Password, "$pbkdf2")
+		})
+	})
 }
 
 func TestMigratePassword(t *testing.T) {
@@ -11,17 +11,25 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
 )
 
 const (
-	EmailBatchingTaskName = "Email Batching"
+	// emailBatchingUsersPerPage is the number of users whose pending notifications are
+	// loaded at once by the email batching job.
+	emailBatchingUsersPerPage = 100
+
+	// emailBatchingClaimLease is how long notifications claimed by a run are left to it. Once
+	// it expires, notifications the run neither sent nor released are claimed again.
+	emailBatchingClaimLease = 10 * time.Minute
 )
 
 type postData struct {
@@ -37,26 +45,22 @@ type postData struct {
 	MessageAttachments       []*EmailMessageAttachment
 }
 
-func (es *Service) InitEmailBatching() {
-	if *es.config().EmailSettings.EnableEmailBatching {
-		if es.EmailBatching == nil {
-			es.EmailBatching = NewEmailBatchingJob(es, *es.config().EmailSettings.EmailBatchingBufferSize)
-		}
-
-		// note that we don't support changing EmailBatchingBufferSize without restarting the server
-
-		es.EmailBatching.Start()
-	}
-}
-
+// AddNotificationEmailToBatch stores the notification for the post so that it is sent
+// as part of the user's next batched email by the email batching job.
 func (es *Service) AddNotificationEmailToBatch(user *model.User, post *model.Post, team *model.Team) *model.AppError {
 	if !*es.config().EmailSettings.EnableEmailBatching {
 		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	if !es.EmailBatching.Add(user, post, team) {
-		mlog.Error("Email batching job's receiving buffer was full. Please increase the EmailBatchingBufferSize. Falling back to sending immediate mail.")
-		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.channel_full.app_error", nil, "", http.StatusInternalServerError)
+	notification := &model.PendingEmailNotification{
+		UserId:   user.Id,
+		PostId:   post.Id,
+		TeamName: team.Name,
+		CreateAt: post.CreateAt,
+	}
+	if _, err := es.store.PendingEmailNotification().Save(notification); err != nil {
+		mlog.Warn("Unable to queue email notification for batching. Falling back to sending immediate mail.", mlog.String("user_id", user.Id), mlog.Err(err))
+		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
 	return nil
@@ -68,173 +72,175 @@ type batchedNotification struct {
 	teamName string
 }
 
-type EmailBatchingJob struct {
-	config  func() *model.Config
-	service *Service
-
-	newNotifications     chan *batchedNotification
-	pendingNotifications map[string][]*batchedNotification
-	task                 *model.ScheduledTask
-	taskMutex            sync.Mutex
+// SendPendingEmailNotifications sends a batched email to every user whose email interval
+// has elapsed since their oldest pending notification. It is run by the email batching
+// job, so only one node in the cluster processes the pending notifications at a time.
+func (es *Service) SendPendingEmailNotifications() error {
+	// it's a bit weird to pass the send email function through here, but it makes it so that we can test
+	// without actually sending emails
+	return es.sendPendingEmailNotifications(time.Now(), es.sendBatchedEmailNotification)
 }
 
-func NewEmailBatchingJob(es *Service, bufferSize int) *EmailBatchingJob {
-	return &EmailBatchingJob{
-		config:               es.config,
-		service:              es,
-		newNotifications:     make(chan *batchedNotification, bufferSize),
-		pendingNotifications: make(map[string][]*batchedNotification),
-	}
-}
+func (es *Service) sendPendingEmailNotifications(now time.Time, handler func(string, []*batchedNotification) error) error {
+	afterUserID := ""
+	for {
+		userIDs, err := es.store.PendingEmailNotification().GetUserIds(afterUserID, emailBatchingUsersPerPage)
+		if err != nil {
+			return errors.Wrap(err, "failed to get users with pending email notifications")
+		}
 
-func (job *EmailBatchingJob) Start() {
-	mlog.Debug("Email batching job starting. Checking for pending emails periodically.", mlog.Int("interval_in_seconds", *job.config().EmailSettings.EmailBatchingInterval))
-	newTask := model.CreateRecurringTask(EmailBatchingTaskName, job.CheckPendingEmails, time.Duration(*job.config().EmailSettings.EmailBatchingInterval)*time.Second)
+		if len(userIDs) == 0 {
+			return nil
+		}
 
-	job.taskMutex.Lock()
-	oldTask := job.task
-	job.task = newTask
-	job.taskMutex.Unlock()
+		for _, userID := range userIDs {
+			if err := es.checkPendingNotifications(now, userID, handler); err != nil {
+				mlog.Warn("Unable to check pending email notifications for user", mlog.String("user_id", userID), mlog.Err(err))
+			}
+		}
 
-	if oldTask != nil {
-		oldTask.Cancel()
+		afterUserID = userIDs[len(userIDs)-1]
 	}
 }
 
-// Stop will cancel the task properly, flushing out any pending notifications.
-// Although this still won't send those notifications which are yet to be sent
-// due to a user's PreferenceNameEmailInterval.
-func (job *EmailBatchingJob) Stop() {
-	job.taskMutex.Lock()
-	if task := job.task; task != nil {
-		task.Cancel()
+// checkPendingNotifications sends the pending notifications of the user once their email
+// interval has elapsed. The notifications are claimed while they are sent, and only deleted
+// once sent, so that they are sent again by a later run if sending them fails.
+func (es *Service) checkPendingNotifications(now time.Time, userID string, handler func(string, []*batchedNotification) error) (err error) {
+	pending, err := es.store.PendingEmailNotification().GetForUser(userID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pending email notifications")
 	}
-	job.taskMutex.Unlock()
-}
 
-func (job *EmailBatchingJob) Add(user *model.User, post *model.Post, team *model.Team) bool {
-	notification := &batchedNotification{
-		userID:   user.Id,
-		post:     post,
-		teamName: team.Name,
+	if len(pending) == 0 {
+		return nil
 	}
 
-	select {
-	case job.newNotifications <- notification:
-		return true
-	default:
-		// return false if we couldn't queue the email notification so that we can send an immediate email
-		return false
+	// get how long we need to wait to send notifications to the user
+	var interval int64
+	preference, err := es.store.Preference().Get(userID, model.PreferenceCategoryNotifications, model.PreferenceNameEmailInterval)
+	if err != nil {
+		// use the default batching interval if an error occurs while fetching user preferences
+		interval, _ = strconv.ParseInt(model.PreferenceEmailIntervalBatchingSeconds, 10, 64)
+	} else {
+		if value, err := strconv.ParseInt(preference.Value, 10, 64); err != nil {
+			// // use the default batching interval if an error occurs while deserializing user preferences
+			interval, _ = strconv.ParseInt(model.PreferenceEmailIntervalBatchingSeconds, 10, 64)
+		} else {
+			interval = value
+		}
 	}
-}
 
-func (job *EmailBatchingJob) CheckPendingEmails() {
-	job.handleNewNotifications()
-
-	// it's a bit weird to pass the send email function through here, but it makes it so that we can test
-	// without actually sending emails
-	job.checkPendingNotifications(time.Now(), job.service.sendBatchedEmailNotification)
-
-	mlog.Debug("Email batching job ran. Notifications might be still pending.", mlog.Int("number_of_users", len(job.pendingNotifications)))
-}
+	batchStartTime := pending[0].CreateAt
+	// Ignore if it isn't time yet to send.
+	if now.Sub(time.UnixMilli(batchStartTime)) <= time.Duration(interval)*time.Second {
+		return nil
+	}
 
-func (job *EmailBatchingJob) handleNewNotifications() {
-	receiving := true
+	ids := make([]string, 0, len(pending))
+	postIDs := make([]string, 0, len(pending))
+	for _, notification := range pending {
+		ids = append(ids, notification.Id)
+		postIDs = append(postIDs, notification.PostId)
+	}
 
-	// read in new notifications to send
-	for receiving {
-		select {
-		case notification := <-job.newNotifications:
-			userID := notification.userID
+	// Claim the notifications before doing anything else: if they are claimed or were
+	// deleted, they are being handled by another run and must not be sent twice.
+	claimed, err := es.store.PendingEmailNotification().Claim(ids, now.UnixMilli(), now.Add(-emailBatchingClaimLease).UnixMilli())
+	if err != nil {
+		return errors.Wrap(err, "failed to claim pending email notifications")
+	}
+	if !claimed {
+		mlog.Debug("Pending email notifications are already being handled", mlog.String("user_id", userID))
+		return nil
+	}
 
-			if _, ok := job.pendingNotifications[userID]; !ok {
-				job.pendingNotifications[userID] = []*batchedNotification{notification}
-			} else {
-				job.pendingNotifications[userID] = append(job.pendingNotifications[userID], notification)
+	// Delete the notifications once handled, or release them to be sent by the next run.
+	handled := false
+	defer func() {
+		if !handled {
+			if releaseErr := es.store.PendingEmailNotification().Release(ids); releaseErr != nil {
+				mlog.Warn("Unable to release pending email notifications", mlog.String("user_id", userID), mlog.Err(releaseErr))
 			}
-		default:
-			receiving = false
+			return
 		}
+
+		if _, deleteErr := es.store.PendingEmailNotification().Delete(ids); deleteErr != nil {
+			err = errors.Wrap(deleteErr, "failed to delete pending email notifications")
+		}
+	}()
+
+	posts, err := es.store.Post().GetPostsByIds(postIDs)
+	var nfErr *store.ErrNotFound
+	if err != nil && !errors.As(err, &nfErr) {
+		return errors.Wrap(err, "failed to get posts for pending email notifications")
+	}
+
+	postsByID := make(map[string]*model.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.Id] = post
 	}
-}
 
-func (job *EmailBatchingJob) checkPendingNotifications(now time.Time, handler func(string, []*batchedNotification)) {
-	for userID, notifications := range job.pendingNotifications {
-		// Defensive code.
-		if len(notifications) == 0 {
-			mlog.Warn("Unexpected result. Got 0 pending notifications for batched email.", mlog.String("user_id", userID))
+	notifications := make([]*batchedNotification, 0, len(pending))
+	for _, notification := range pending {
+		post, ok := postsByID[notification.PostId]
+		if !ok || post.DeleteAt != 0 {
 			continue
 		}
 
-		// get how long we need to wait to send notifications to the user
-		var interval int64
-		preference, err := job.service.store.Preference().Get(userID, model.PreferenceCategoryNotifications, model.PreferenceNameEmailInterval)
-		if err != nil {
-			// use the default batching interval if an error occurs while fetching user preferences
-			interval, _ = strconv.ParseInt(model.PreferenceEmailIntervalBatchingSeconds, 10, 64)
-		} else {
-			if value, err := strconv.ParseInt(preference.Value, 10, 64); err != nil {
-				// // use the default batching interval if an error occurs while deserializing user preferences
-				interval, _ = strconv.ParseInt(model.PreferenceEmailIntervalBatchingSeconds, 10, 64)
-			} else {
-				interval = value
-			}
-		}
+		notifications = append(notifications, &batchedNotification{
+			userID:   userID,
+			post:     post,
+			teamName: notification.TeamName,
+		})
+	}
 
-		batchStartTime := notifications[0].post.CreateAt
-		// Ignore if it isn't time yet to send.
-		if now.Sub(time.UnixMilli(batchStartTime)) <= time.Duration(interval)*time.Second {
+	// If the user has viewed any channels in this team since the notification was queued, drop
+	// all queued notifications
+	inspectedTeamNames := make(map[string]string)
+	for _, notification := range notifications {
+		// at most, we'll do one check for each team that notifications were sent for
+		if inspectedTeamNames[notification.teamName] != "" {
 			continue
 		}
 
-		// If the user has viewed any channels in this team since the notification was queued, delete
-		// all queued notifications
-		inspectedTeamNames := make(map[string]string)
-		for _, notification := range notifications {
-			// at most, we'll do one check for each team that notifications were sent for
-			if inspectedTeamNames[notification.teamName] != "" {
-				continue
-			}
-
-			team, nErr := job.service.store.Team().GetByName(notifications[0].teamName)
-			if nErr != nil {
-				mlog.Error("Unable to find Team id for notification", mlog.Err(nErr))
-				continue
-			}
+		team, nErr := es.store.Team().GetByName(notification.teamName)
+		if nErr != nil {
+			mlog.Error("Unable to find Team id for notification", mlog.Err(nErr))
+			continue
+		}
 
-			if team != nil {
-				inspectedTeamNames[notification.teamName] = team.Id
-			}
+		if team != nil {
+			inspectedTeamNames[notification.teamName] = team.Id
+		}
 
-			channelMembers, err := job.service.store.Channel().GetMembersForUser(inspectedTeamNames[notification.teamName], userID)
-			if err != nil {
-				mlog.Error("Unable to find ChannelMembers for user", mlog.Err(err))
-				continue
-			}
+		channelMembers, err := es.store.Channel().GetMembersForUser(inspectedTeamNames[notification.teamName], userID)
+		if err != nil {
+			mlog.Error("Unable to find ChannelMembers for user", mlog.Err(err))
+			continue
+		}
 
-			deleted := false
-			for _, channelMember := range channelMembers {
-				if channelMember.LastViewedAt >= batchStartTime {
-					mlog.Debug("Deleted notifications for user", mlog.String("user_id", userID))
-					delete(job.pendingNotifications, userID)
-					deleted = true
-					break
-				}
-			}
-			if deleted {
-				break
+		for _, channelMember := range channelMembers {
+			if channelMember.LastViewedAt >= batchStartTime {
+				mlog.Debug("Deleted notifications for user", mlog.String("user_id", userID))
+				handled = true
+				return nil
 			}
 		}
+	}
 
-		// The notifications might have been cleared from the above step.
-		// We need to check again.
-		if len(job.pendingNotifications[userID]) == 0 {
-			continue
-		}
+	// The posts might have been deleted since the notifications were queued.
+	if len(notifications) == 0 {
+		handled = true
+		return nil
+	}
 
-		handler(userID, job.pendingNotifications[userID])
-		delete(job.pendingNotifications, userID)
+	if err := handler(userID, notifications); err != nil {
+		return errors.Wrap(err, "failed to send batched email notification")
 	}
+
+	handled = true
+	return nil
 }
 
 /**
@@ -249,11 +255,13 @@ func truncateUserNames(name string, i int) string {
 	return name
 }
 
-func (es *Service) sendBatchedEmailNotification(userID string, notifications []*batchedNotification) {
+// sendBatchedEmailNotification sends the notifications to the user in a single email. It only
+// returns an error when the email should be sent again later.
+func (es *Service) sendBatchedEmailNotification(userID string, notifications []*batchedNotification) error {
 	user, err := es.userService.GetUser(userID)
 	if err != nil {
 		mlog.Warn("Unable to find recipient for batched email notification")
-		return
+		return nil
 	}
 
 	translateFunc := i18n.GetUserTranslations(user.Locale)
@@ -375,5 +383,8 @@ func (es *Service) sendBatchedEmailNotification(userID string, notifications []*
 
 	if nErr := es.SendMailWithEmbeddedFiles(user.Email, subject, renderedPage, embeddedFiles, "", "", "", "BatchedEmailNotification"); nErr != nil {
 		mlog.Warn("Unable to send batched email notification", mlog.String("email", user.Email), mlog.Err(nErr))
+		return nErr
 	}
+
+	return nil
 }
@@ -4,75 +4,81 @@
 package email
 
 import (
-	"sync/atomic"
+	"errors"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest/mocks"
 )
 
-func TestHandleNewNotifications(t *testing.T) {
+func TestAddNotificationEmailToBatch(t *testing.T) {
 	mainHelper.Parallel(t)
 
 	th := SetupWithStoreMock(t)
 	defer th.TearDown()
 
-	id1 := model.NewId()
-	id2 := model.NewId()
-	id3 := model.NewId()
-
-	// test queueing of received posts by user
-	job := NewEmailBatchingJob(th.service, 128)
-
-	job.handleNewNotifications()
-
-	require.Empty(t, job.pendingNotifications, "shouldn't have added any pending notifications")
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	require.Empty(t, job.pendingNotifications, "shouldn't have added any pending notifications")
-
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 1, "should have received posts for 1 user")
-	require.Len(t, job.pendingNotifications[id1], 1, "should have received 1 post for user")
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 1, "should have received posts for 1 user")
-	require.Len(t, job.pendingNotifications[id1], 2, "should have received 2 posts for user1")
-
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 2, "should have received posts for 2 users")
-	require.Len(t, job.pendingNotifications[id1], 2, "should have received 2 posts for user1")
-	require.Len(t, job.pendingNotifications[id2], 1, "should have received 1 post for user2")
-
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id2, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id3, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id3}, &model.Post{UserId: id3, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id2, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 3, "should have received posts for 3 users")
-	require.Len(t, job.pendingNotifications[id1], 3, "should have received 3 posts for user1")
-	require.Len(t, job.pendingNotifications[id2], 3, "should have received 3 posts for user2")
-	require.Len(t, job.pendingNotifications[id3], 1, "should have received 1 post for user3")
-
-	// test ordering of received posts
-	job = NewEmailBatchingJob(th.service, 128)
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test1"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test2"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test3"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test4"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test5"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	assert.Equal(t, job.pendingNotifications[id1][0].post.Message, "test1", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id1][1].post.Message, "test2", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id1][2].post.Message, "test4", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id2][0].post.Message, "test3", "incorrect order of received posts for user2")
-	assert.Equal(t, job.pendingNotifications[id2][1].post.Message, "test5", "incorrect order of received posts for user2")
+	user := &model.User{Id: model.NewId()}
+	post := &model.Post{Id: model.NewId(), UserId: model.NewId(), CreateAt: 10000000}
+	team := &model.Team{Name: "team"}
+
+	pendingStore := mocks.PendingEmailNotificationStore{}
+	mockStore := th.service.store.(*mocks.Store)
+	mockStore.On("PendingEmailNotification").Return(&pendingStore)
+
+	th.UpdateConfig(func(cfg *model.Config) { *cfg.EmailSettings.EnableEmailBatching = false })
+	appErr := th.service.AddNotificationEmailToBatch(user, post, team)
+	require.NotNil(t, appErr)
+	assert.Equal(t, http.StatusNotImplemented, appErr.StatusCode)
+
+	th.UpdateConfig(func(cfg *model.Config) { *cfg.EmailSettings.EnableEmailBatching = true })
+
+	pendingStore.On("Save", mock.MatchedBy(func(n *model.PendingEmailNotification) bool {
+		return n.UserId == user.Id && n.PostId == post.Id && n.TeamName == team.Name && n.CreateAt == post.CreateAt
+	})).Return(&model.PendingEmailNotification{}, nil).Once()
+	require.Nil(t, th.service.AddNotificationEmailToBatch(user, post, team))
+
+	pendingStore.On("Save", mock.Anything).Return(nil, errors.New("some error")).Once()
+	appErr = th.service.AddNotificationEmailToBatch(user, post, team)
+	require.NotNil(t, appErr, "the caller should fall back to sending an immediate email")
+	assert.Equal(t, "api.email_batching.add_notification_email_to_batch.save.app_error", appErr.Id)
+
+	pendingStore.AssertExpectations(t)
+}
+
+func queuePendingEmailNotification(t *testing.T, th *TestHelper, createAt int64, message string) *model.Post {
+	t.Helper()
+
+	post, err := th.store.Post().Save(th.Context, &model.Post{
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		CreateAt:  createAt,
+		Message:   message,
+	})
+	require.NoError(t, err)
+
+	_, err = th.store.PendingEmailNotification().Save(&model.PendingEmailNotification{
+		UserId:   th.BasicUser.Id,
+		PostId:   post.Id,
+		TeamName: th.BasicTeam.Name,
+		CreateAt: createAt,
+	})
+	require.NoError(t, err)
+
+	return post
+}
+
+func requirePendingEmailNotifications(t *testing.T, th *TestHelper, expected int, msgAndArgs ...any) {
+	t.Helper()
+
+	pending, err := th.store.PendingEmailNotification().GetForUser(th.BasicUser.Id)
+	require.NoError(t, err)
+	require.Len(t, pending, expected, msgAndArgs...)
 }
 
 func TestCheckPendingNotifications(t *testing.T) {
@@ -80,17 +86,7 @@ func TestCheckPendingNotifications(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
-	job.pendingNotifications[th.BasicUser.Id] = []*batchedNotification{
-		{
-			post: &model.Post{
-				UserId:    th.BasicUser.Id,
-				ChannelId: th.BasicChannel.Id,
-				CreateAt:  10000000,
-			},
-			teamName: th.BasicTeam.Name,
-		},
-	}
+	queuePendingEmailNotification(t, th, 10000000, "")
 
 	channelMember, err := th.store.Channel().GetMember(th.Context, th.BasicChannel.Id, th.BasicUser.Id)
 	require.NoError(t, err)
@@ -107,10 +103,9 @@ func TestCheckPendingNotifications(t *testing.T) {
 	require.NoError(t, nErr)
 
 	// test that notifications aren't sent before interval
-	job.checkPendingNotifications(time.Unix(10001, 0), func(string, []*batchedNotification) {})
-
-	require.NotNil(t, job.pendingNotifications[th.BasicUser.Id])
-	require.Len(t, job.pendingNotifications[th.BasicUser.Id], 1, "shouldn't have sent queued post")
+	err = th.service.checkPendingNotifications(time.Unix(10001, 0), th.BasicUser.Id, func(string, []*batchedNotification) error { return nil })
+	require.NoError(t, err)
+	requirePendingEmailNotifications(t, th, 1, "shouldn't have sent queued post")
 
 	// test that notifications are cleared if the user has acted
 	channelMember, err = th.store.Channel().GetMember(th.Context, th.BasicChannel.Id, th.BasicUser.Id)
@@ -128,68 +123,134 @@ func TestCheckPendingNotifications(t *testing.T) {
 	}})
 	require.NoError(t, nErr)
 
-	var wasCalled int32
-	job.checkPendingNotifications(time.Unix(10050, 0), func(string, []*batchedNotification) {
-		atomic.StoreInt32(&wasCalled, int32(1))
+	wasCalled := false
+	err = th.service.checkPendingNotifications(time.Unix(10050, 0), th.BasicUser.Id, func(string, []*batchedNotification) error {
+		wasCalled = true
+		return nil
 	})
-
-	// A hack to check whether the handler was called.
-	// It's not straightforward to just wait for it using a channel because the test should
-	// NOT call the handler, and it will be called only if the test fails.
-	time.Sleep(1 * time.Second)
-	// We do a check outside the email handler, because otherwise, failing from
-	// inside the handler doesn't let the .Go() function exit cleanly, and it gets
-	// stuck during server shutdown, trying to wait for the goroutine to exit
-	require.Equal(t, int32(0), atomic.LoadInt32(&wasCalled), "email handler should not have been called")
-
-	require.Nil(t, job.pendingNotifications[th.BasicUser.Id])
-	require.Empty(t, job.pendingNotifications[th.BasicUser.Id], "should've remove queued post since user acted")
+	require.NoError(t, err)
+	require.False(t, wasCalled, "email handler should not have been called")
+	requirePendingEmailNotifications(t, th, 0, "should've removed queued post since user acted")
 
 	// test that notifications are sent if enough time passes since the first message
-	job.pendingNotifications[th.BasicUser.Id] = []*batchedNotification{
-		{
-			post: &model.Post{
-				UserId:    th.BasicUser.Id,
-				ChannelId: th.BasicChannel.Id,
-				CreateAt:  10060000,
-				Message:   "post1",
-			},
-			teamName: th.BasicTeam.Name,
-		},
-		{
-			post: &model.Post{
-				UserId:    th.BasicUser.Id,
-				ChannelId: th.BasicChannel.Id,
-				CreateAt:  10090000,
-				Message:   "post2",
-			},
-			teamName: th.BasicTeam.Name,
-		},
-	}
-
-	received := make(chan *model.Post, 2)
+	queuePendingEmailNotification(t, th, 10060000, "post1")
+	queuePendingEmailNotification(t, th, 10090000, "post2")
 
-	job.checkPendingNotifications(time.Unix(10130, 0), func(s string, notifications []*batchedNotification) {
+	var received []*model.Post
+	err = th.service.checkPendingNotifications(time.Unix(10130, 0), th.BasicUser.Id, func(s string, notifications []*batchedNotification) error {
 		for _, notification := range notifications {
-			received <- notification.post
+			received = append(received, notification.post)
 		}
+		return nil
 	})
+	require.NoError(t, err)
+	requirePendingEmailNotifications(t, th, 0, "should have sent queued posts")
+
+	require.Len(t, received, 2)
+	require.Equal(t, "post1", received[0].Message, "should've received post1 first")
+	require.Equal(t, "post2", received[1].Message, "should've received post2 second")
+}
+
+func TestSendPendingEmailNotifications(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
 
-	require.Nil(t, job.pendingNotifications[th.BasicUser.Id], "shouldn't have sent queued post")
+	// bypasses recent user activity check
+	channelMember, err := th.store.Channel().GetMember(th.Context, th.BasicChannel.Id, th.BasicUser.Id)
+	require.NoError(t, err)
+	channelMember.LastViewedAt = 9999000
+	_, err = th.store.Channel().UpdateMember(th.Context, channelMember)
+	require.NoError(t, err)
+
+	queuePendingEmailNotification(t, th, 10000000, "post1")
+	deleted := queuePendingEmailNotification(t, th, 10000001, "post2")
+	err = th.store.Post().Delete(th.Context, deleted.Id, model.GetMillis(), th.BasicUser.Id)
+	require.NoError(t, err)
 
-	select {
-	case post := <-received:
-		require.Equal(t, post.Message, "post1", "should've received post1 first")
-	case <-time.After(5 * time.Second):
-		require.Fail(t, "timed out waiting for first post notification")
+	sent := map[string][]*batchedNotification{}
+	handler := func(userID string, notifications []*batchedNotification) error {
+		sent[userID] = append(sent[userID], notifications...)
+		return nil
 	}
 
-	select {
-	case post := <-received:
-		require.Equal(t, post.Message, "post2", "should've received post2 second")
-	case <-time.After(5 * time.Second):
-		require.Fail(t, "timed out waiting for second post notification")
+	require.NoError(t, th.service.sendPendingEmailNotifications(time.Unix(10901, 0), handler))
+	require.Len(t, sent[th.BasicUser.Id], 1, "deleted posts shouldn't be sent")
+	assert.Equal(t, "post1", sent[th.BasicUser.Id][0].post.Message)
+	requirePendingEmailNotifications(t, th, 0)
+
+	// The notifications are persisted, so a later run never sends them again
+	require.NoError(t, th.service.sendPendingEmailNotifications(time.Unix(10902, 0), handler))
+	require.Len(t, sent[th.BasicUser.Id], 1)
+}
+
+func TestCheckPendingNotificationsHandlerFails(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	// bypasses recent user activity check
+	channelMember, err := th.store.Channel().GetMember(th.Context, th.BasicChannel.Id, th.BasicUser.Id)
+	require.NoError(t, err)
+	channelMember.LastViewedAt = 9999000
+	_, err = th.store.Channel().UpdateMember(th.Context, channelMember)
+	require.NoError(t, err)
+
+	queuePendingEmailNotification(t, th, 10000000, "post1")
+
+	err = th.service.checkPendingNotifications(time.Unix(10901, 0), th.BasicUser.Id, func(string, []*batchedNotification) error {
+		return errors.New("smtp server unavailable")
+	})
+	require.Error(t, err)
+	requirePendingEmailNotifications(t, th, 1, "notifications that failed to be sent should be kept")
+
+	var received []*batchedNotification
+	err = th.service.checkPendingNotifications(time.Unix(10902, 0), th.BasicUser.Id, func(_ string, notifications []*batchedNotification) error {
+		received = append(received, notifications...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, received, 1, "notifications should be sent by the next run")
+	assert.Equal(t, "post1", received[0].post.Message)
+	requirePendingEmailNotifications(t, th, 0, "should have sent queued post")
+}
+
+func TestCheckPendingNotificationsClaimed(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	// bypasses recent user activity check
+	channelMember, err := th.store.Channel().GetMember(th.Context, th.BasicChannel.Id, th.BasicUser.Id)
+	require.NoError(t, err)
+	channelMember.LastViewedAt = 9999000
+	_, err = th.store.Channel().UpdateMember(th.Context, channelMember)
+	require.NoError(t, err)
+
+	queuePendingEmailNotification(t, th, 10000000, "post1")
+	pending, err := th.store.PendingEmailNotification().GetForUser(th.BasicUser.Id)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	// Another run claimed the notifications, and crashed before sending them.
+	claimedAt := time.Unix(10901, 0)
+	claimed, err := th.store.PendingEmailNotification().Claim([]string{pending[0].Id}, claimedAt.UnixMilli(), 0)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	wasCalled := false
+	handler := func(string, []*batchedNotification) error {
+		wasCalled = true
+		return nil
 	}
+
+	require.NoError(t, th.service.checkPendingNotifications(claimedAt.Add(time.Minute), th.BasicUser.Id, handler))
+	require.False(t, wasCalled, "claimed notifications shouldn't be sent twice")
+	requirePendingEmailNotifications(t, th, 1)
+
+	require.NoError(t, th.service.checkPendingNotifications(claimedAt.Add(emailBatchingClaimLease+time.Minute), th.BasicUser.Id, handler))
+	require.True(t, wasCalled, "notifications should be sent once their claim expired")
+	requirePendingEmailNotifications(t, th, 0)
 }
 
 /**
@@ -200,8 +261,6 @@ func TestCheckPendingNotificationsDefaultInterval(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
-
 	// bypasses recent user activity check
 	require.NotNil(t, th.store)
 	require.NotNil(t, th.store.Channel())
@@ -214,26 +273,17 @@ func TestCheckPendingNotificationsDefaultInterval(t *testing.T) {
 	_, err = th.store.Channel().UpdateMember(th.Context, channelMember)
 	require.NoError(t, err)
 
-	job.pendingNotifications[th.BasicUser.Id] = []*batchedNotification{
-		{
-			post: &model.Post{
-				UserId:    th.BasicUser.Id,
-				ChannelId: th.BasicChannel.Id,
-				CreateAt:  10000000,
-			},
-			teamName: th.BasicTeam.Name,
-		},
-	}
+	queuePendingEmailNotification(t, th, 10000000, "")
 
 	// notifications should not be sent 1s after post was created, because default batch interval is 15mins
-	job.checkPendingNotifications(time.Unix(10001, 0), func(string, []*batchedNotification) {})
-	require.NotNil(t, job.pendingNotifications[th.BasicUser.Id])
-	require.Len(t, job.pendingNotifications[th.BasicUser.Id], 1, "shouldn't have sent queued post")
+	err = th.service.checkPendingNotifications(time.Unix(10001, 0), th.BasicUser.Id, func(string, []*batchedNotification) error { return nil })
+	require.NoError(t, err)
+	requirePendingEmailNotifications(t, th, 1, "shouldn't have sent queued post")
 
 	// notifications should be sent 901s after post was created, because default batch interval is 15mins
-	job.checkPendingNotifications(time.Unix(10901, 0), func(string, []*batchedNotification) {})
-	require.Nil(t, job.pendingNotifications[th.BasicUser.Id])
-	require.Empty(t, job.pendingNotifications[th.BasicUser.Id], "should have sent queued post")
+	err = th.service.checkPendingNotifications(time.Unix(10901, 0), th.BasicUser.Id, func(string, []*batchedNotification) error { return nil })
+	require.NoError(t, err)
+	requirePendingEmailNotifications(t, th, 0, "should have sent queued post")
 }
 
 /**
@@ -244,8 +294,6 @@ func TestCheckPendingNotificationsCantParseInterval(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
-
 	require.NotNil(t, th.store)
 	require.NotNil(t, th.store.Channel())
 	require.NotNil(t, th.BasicChannel)
@@ -266,24 +314,16 @@ func TestCheckPendingNotificationsCantParseInterval(t *testing.T) {
 	}})
 	require.NoError(t, nErr)
 
-	job.pendingNotifications[th.BasicUser.Id] = []*batchedNotification{
-		{
-			post: &model.Post{
-				UserId:    th.BasicUser.Id,
-				ChannelId: th.BasicChannel.Id,
-				CreateAt:  10000000,
-			},
-			teamName: th.BasicTeam.Name,
-		},
-	}
+	queuePendingEmailNotification(t, th, 10000000, "")
 
 	// notifications should not be sent 1s after post was created, because default batch interval is 15mins
-	job.checkPendingNotifications(time.Unix(10001, 0), func(string, []*batchedNotification) {})
-	require.NotNil(t, job.pendingNotifications[th.BasicUser.Id])
-	require.Len(t, job.pendingNotifications[th.BasicUser.Id], 1, "shouldn't have sent queued post")
+	err = th.service.checkPendingNotifications(time.Unix(10001, 0), th.BasicUser.Id, func(string, []*batchedNotification) error { return nil })
+	require.NoError(t, err)
+	requirePendingEmailNotifications(t, th, 1, "shouldn't have sent queued post")
 
 	// notifications should be sent 901s after post was created, because default batch interval is 15mins
-	job.checkPendingNotifications(time.Unix(10901, 0), func(string, []*batchedNotification) {})
+	err = th.service.checkPendingNotifications(time.Unix(10901, 0), th.BasicUser.Id, func(string, []*batchedNotification) error { return nil })
+	require.NoError(t, err)
 
-	require.Nil(t, job.pendingNotifications[th.BasicUser.Id], "should have sent queued post")
+	requirePendingEmailNotifications(t, th, 0, "should have sent queued post")
 }
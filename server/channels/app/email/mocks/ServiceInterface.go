@@ -141,11 +141,6 @@ func (_m *ServiceInterface) GetPerDayEmailRateLimiter() *throttled.GCRARateLimit
 	return r0
 }
 
-// InitEmailBatching provides a mock function with no fields
-func (_m *ServiceInterface) InitEmailBatching() {
-	_m.Called()
-}
-
 // NewEmailTemplateData provides a mock function with given fields: locale
 func (_m *ServiceInterface) NewEmailTemplateData(locale string) templates.Data {
 	ret := _m.Called(locale)
@@ -374,6 +369,24 @@ func (_m *ServiceInterface) SendMailWithEmbeddedFiles(to string, subject string,
 	return r0
 }
 
+// SendMailWithEmbeddedFilesAndCustomReplyTo provides a mock function with given fields: to, subject, htmlBody, replyToAddress, embeddedFiles, messageID, inReplyTo, references, category
+func (_m *ServiceInterface) SendMailWithEmbeddedFilesAndCustomReplyTo(to string, subject string, htmlBody string, replyToAddress string, embeddedFiles map[string]io.Reader, messageID string, inReplyTo string, references string, category string) error {
+	ret := _m.Called(to, subject, htmlBody, replyToAddress, embeddedFiles, messageID, inReplyTo, references, category)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMailWithEmbeddedFilesAndCustomReplyTo")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, map[string]io.Reader, string, string, string, string) error); ok {
+		r0 = rf(to, subject, htmlBody, replyToAddress, embeddedFiles, messageID, inReplyTo, references, category)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendMfaChangeEmail provides a mock function with given fields: _a0, activated, locale, siteURL
 func (_m *ServiceInterface) SendMfaChangeEmail(_a0 string, activated bool, locale string, siteURL string) error {
 	ret := _m.Called(_a0, activated, locale, siteURL)
@@ -456,6 +469,24 @@ func (_m *ServiceInterface) SendPasswordResetEmail(_a0 string, token *model.Toke
 	return r0, r1
 }
 
+// SendPendingEmailNotifications provides a mock function with no fields
+func (_m *ServiceInterface) SendPendingEmailNotifications() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendPendingEmailNotifications")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendRemoveExpiredLicenseEmail provides a mock function with given fields: ctaText, ctaLink, _a2, locale, siteURL
 func (_m *ServiceInterface) SendRemoveExpiredLicenseEmail(ctaText string, ctaLink string, _a2 string, locale string, siteURL string) error {
 	ret := _m.Called(ctaText, ctaLink, _a2, locale, siteURL)
@@ -551,11 +582,6 @@ func (_m *ServiceInterface) SetStore(st store.Store) {
 	_m.Called(st)
 }
 
-// Stop provides a mock function with no fields
-func (_m *ServiceInterface) Stop() {
-	_m.Called()
-}
-
 // NewServiceInterface creates a new instance of ServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewServiceInterface(t interface {
@@ -0,0 +1,356 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/i18n"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+)
+
+const (
+	// emailDigestWeekday is the day weekly digests are sent on.
+	emailDigestWeekday = time.Monday
+
+	// emailDigestSendWindow is how late a digest may still be sent after it became due,
+	// so that users who opt in, or a server that was down, don't get one at an odd hour.
+	emailDigestSendWindow = 6 * time.Hour
+
+	emailDigestMaxThreads  = 10
+	emailDigestMaxChannels = 5
+)
+
+// emailDigestChannel is a channel summarized in a digest.
+type emailDigestChannel struct {
+	channel  *model.Channel
+	unread   int64
+	mentions int64
+}
+
+// emailDigest holds what happened since the previous digest of a user.
+type emailDigest struct {
+	threads        []*model.ThreadResponse
+	mentions       int64
+	activeChannels []*emailDigestChannel
+	teamNames      map[string]string
+	channels       map[string]*model.Channel
+}
+
+func (d *emailDigest) isEmpty() bool {
+	return len(d.threads) == 0 && d.mentions == 0 && len(d.activeChannels) == 0
+}
+
+// emailDigestDueAt returns the time the latest digest became due at or before now, at the
+// configured hour in the given timezone.
+func emailDigestDueAt(now time.Time, loc *time.Location, hour int, frequency string) time.Time {
+	local := now.In(loc)
+	due := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, loc)
+	if due.After(local) {
+		due = due.AddDate(0, 0, -1)
+	}
+
+	if frequency == model.PreferenceEmailDigestWeekly {
+		for due.Weekday() != emailDigestWeekday {
+			due = due.AddDate(0, 0, -1)
+		}
+	}
+
+	return due
+}
+
+// SendEmailDigests emails a summary of unread followed threads, mentions and the most
+// active channels to every user who opted in and whose digest is due.
+func (a *App) SendEmailDigests() error {
+	return a.sendEmailDigests(request.EmptyContext(a.Log()), time.Now())
+}
+
+func (a *App) sendEmailDigests(rctx request.CTX, now time.Time) error {
+	preferences, err := a.Srv().Store().Preference().GetCategoryAndName(model.PreferenceCategoryNotifications, model.PreferenceNameEmailDigest)
+	if err != nil {
+		return errors.Wrap(err, "failed to get email digest preferences")
+	}
+
+	for _, preference := range preferences {
+		if preference.Value != model.PreferenceEmailDigestDaily && preference.Value != model.PreferenceEmailDigestWeekly {
+			continue
+		}
+
+		if err := a.sendEmailDigest(rctx, now, preference.UserId, preference.Value); err != nil {
+			rctx.Logger().Warn("Unable to send email digest", mlog.String("user_id", preference.UserId), mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+func (a *App) sendEmailDigest(rctx request.CTX, now time.Time, userID, frequency string) error {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if user.DeleteAt != 0 || user.IsBot {
+		return nil
+	}
+
+	due := emailDigestDueAt(now, user.GetTimezoneLocation(), *a.Config().EmailSettings.EmailDigestHour, frequency)
+	if now.Sub(due) > emailDigestSendWindow {
+		return nil
+	}
+
+	var lastSentAt int64
+	if preference, err := a.Srv().Store().Preference().Get(userID, model.PreferenceCategoryNotifications, model.PreferenceNameEmailDigestLastSentAt); err == nil {
+		lastSentAt, _ = strconv.ParseInt(preference.Value, 10, 64)
+	}
+	if lastSentAt >= due.UnixMilli() {
+		return nil
+	}
+
+	// Summarize the day or week before the digest became due, without repeating what the
+	// previous digest already covered
+	since := due.AddDate(0, 0, -1)
+	if frequency == model.PreferenceEmailDigestWeekly {
+		since = due.AddDate(0, 0, -7)
+	}
+	sinceMillis := max(since.UnixMilli(), lastSentAt)
+
+	digest, err := a.getEmailDigest(rctx, user, sinceMillis)
+	if err != nil {
+		return err
+	}
+
+	if !digest.isEmpty() {
+		if err := a.sendEmailDigestEmail(rctx, user, frequency, digest); err != nil {
+			return err
+		}
+	}
+
+	return a.Srv().Store().Preference().Save(model.Preferences{{
+		UserId:   userID,
+		Category: model.PreferenceCategoryNotifications,
+		Name:     model.PreferenceNameEmailDigestLastSentAt,
+		Value:    strconv.FormatInt(now.UnixMilli(), 10),
+	}})
+}
+
+func (a *App) getEmailDigest(rctx request.CTX, user *model.User, since int64) (*emailDigest, error) {
+	teams, appErr := a.GetTeamsForUser(user.Id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	digest := &emailDigest{
+		teamNames: make(map[string]string, len(teams)),
+		channels:  make(map[string]*model.Channel),
+	}
+
+	channels, err := a.Srv().Store().Channel().GetChannels("", user.Id, &model.ChannelSearchOpts{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get channels")
+	}
+	for _, channel := range channels {
+		digest.channels[channel.Id] = channel
+	}
+
+	threads := make(map[string]*model.ThreadResponse)
+	members := make(map[string]*model.ChannelMember)
+	for _, team := range teams {
+		digest.teamNames[team.Id] = team.Name
+
+		// Direct and group messages are returned for every team
+		result, appErr := a.GetThreadsForUser(rctx, user.Id, team.Id, model.GetUserThreadsOpts{
+			Unread:      true,
+			ThreadsOnly: true,
+			Since:       uint64(since),
+			PageSize:    emailDigestMaxThreads,
+		})
+		if appErr != nil {
+			return nil, appErr
+		}
+		for _, thread := range result.Threads {
+			threads[thread.PostId] = thread
+		}
+
+		teamMembers, err := a.Srv().Store().Channel().GetMembersForUser(team.Id, user.Id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get channel members")
+		}
+		for i := range teamMembers {
+			members[teamMembers[i].ChannelId] = &teamMembers[i]
+		}
+	}
+
+	for _, thread := range threads {
+		if thread.Post != nil && digest.channels[thread.Post.ChannelId] != nil {
+			digest.threads = append(digest.threads, thread)
+		}
+	}
+	sort.Slice(digest.threads, func(i, j int) bool {
+		return digest.threads[i].LastReplyAt > digest.threads[j].LastReplyAt
+	})
+	if len(digest.threads) > emailDigestMaxThreads {
+		digest.threads = digest.threads[:emailDigestMaxThreads]
+	}
+
+	for channelID, member := range members {
+		channel := digest.channels[channelID]
+		if channel == nil || channel.LastPostAt < since {
+			continue
+		}
+
+		digest.mentions += member.MentionCount
+
+		// Direct and group messages are personal conversations rather than active channels
+		if channel.IsGroupOrDirect() {
+			continue
+		}
+
+		unread := channel.TotalMsgCount - member.MsgCount
+		if unread > 0 {
+			digest.activeChannels = append(digest.activeChannels, &emailDigestChannel{
+				channel:  channel,
+				unread:   unread,
+				mentions: member.MentionCount,
+			})
+		}
+	}
+	sort.Slice(digest.activeChannels, func(i, j int) bool {
+		return digest.activeChannels[i].unread > digest.activeChannels[j].unread
+	})
+	if len(digest.activeChannels) > emailDigestMaxChannels {
+		digest.activeChannels = digest.activeChannels[:emailDigestMaxChannels]
+	}
+
+	return digest, nil
+}
+
+func (a *App) sendEmailDigestEmail(rctx request.CTX, user *model.User, frequency string, digest *emailDigest) error {
+	translateFunc := i18n.GetUserTranslations(user.Locale)
+	siteURL := a.GetSiteURL()
+	siteName := *a.Config().TeamSettings.SiteName
+
+	// Links to direct messages go through any team of the user
+	var defaultTeamName string
+	for _, name := range digest.teamNames {
+		defaultTeamName = name
+		break
+	}
+
+	emailNotificationContentsType := model.EmailNotificationContentsFull
+	if license := a.Srv().License(); license != nil && *license.Features.EmailNotificationContents {
+		emailNotificationContentsType = *a.Config().EmailSettings.EmailNotificationContentsType
+	}
+
+	posts := []postData{}
+	embeddedFiles := make(map[string]io.Reader)
+	if emailNotificationContentsType == model.EmailNotificationContentsFull {
+		for i, thread := range digest.threads {
+			channel := digest.channels[thread.Post.ChannelId]
+			teamName, ok := digest.teamNames[channel.TeamId]
+			if !ok {
+				teamName = defaultTeamName
+			}
+
+			sender, appErr := a.GetUser(thread.Post.UserId)
+			if appErr != nil {
+				rctx.Logger().Warn("Unable to find the author of a thread for the email digest", mlog.String("post_id", thread.PostId), mlog.Err(appErr))
+				continue
+			}
+
+			senderPhoto := fmt.Sprintf("user-avatar-%d.png", i)
+			if image, _, appErr := a.GetProfileImage(sender); appErr == nil {
+				embeddedFiles[senderPhoto] = bytes.NewReader(image)
+			}
+
+			channelName := channel.DisplayName
+			if channel.IsGroupOrDirect() {
+				channelName = ""
+			}
+
+			posts = append(posts, postData{
+				SenderName:      sender.GetDisplayName(*a.Config().TeamSettings.TeammateNameDisplay),
+				ChannelName:     channelName,
+				Message:         template.HTML(a.GetMessageForNotification(thread.Post, teamName, siteURL, translateFunc)),
+				MessageURL:      siteURL + "/" + teamName + "/pl/" + thread.PostId,
+				SenderPhoto:     senderPhoto,
+				Time:            translateFunc("app.email_digest.thread.unread_replies", thread.UnreadReplies),
+				ShowChannelIcon: channelName != "",
+			})
+		}
+	}
+
+	subjectID := "app.email_digest.subject.daily"
+	titleID := "app.email_digest.title.daily"
+	if frequency == model.PreferenceEmailDigestWeekly {
+		subjectID = "app.email_digest.subject.weekly"
+		titleID = "app.email_digest.title.weekly"
+	}
+
+	data := a.Srv().EmailService.NewEmailTemplateData(user.Locale)
+	data.Props["SiteURL"] = siteURL
+	data.Props["Title"] = translateFunc(titleID)
+	data.Props["SubTitle"] = emailDigestSummary(digest, translateFunc)
+	data.Props["Button"] = translateFunc("api.email_batching.send_batched_email_notification.button")
+	data.Props["ButtonURL"] = siteURL
+	data.Props["Posts"] = posts
+	data.Props["MessageButton"] = translateFunc("app.email_digest.message_button")
+	data.Props["NotificationFooterTitle"] = translateFunc("app.notification.footer.title")
+	data.Props["NotificationFooterInfoLogin"] = translateFunc("app.notification.footer.infoLogin")
+	data.Props["NotificationFooterInfo"] = translateFunc("app.notification.footer.info")
+
+	body, err := a.Srv().TemplatesContainer().RenderToString("messages_notification", data)
+	if err != nil {
+		return errors.Wrap(err, "failed to render the email digest")
+	}
+
+	subject := translateFunc(subjectID, map[string]any{"SiteName": siteName})
+	if err := a.Srv().EmailService.SendMailWithEmbeddedFiles(user.Email, subject, body, embeddedFiles, "", "", "", "EmailDigest"); err != nil {
+		return errors.Wrap(err, "failed to send the email digest")
+	}
+
+	return nil
+}
+
+// emailDigestSummary lists the counts shown above the threads of a digest, one per line.
+func emailDigestSummary(digest *emailDigest, translateFunc i18n.TranslateFunc) template.HTML {
+	var lines []string
+	if len(digest.threads) > 0 {
+		lines = append(lines, translateFunc("app.email_digest.summary.threads", len(digest.threads)))
+	}
+
+	if digest.mentions > 0 {
+		lines = append(lines, translateFunc("app.email_digest.summary.mentions", digest.mentions))
+	}
+
+	if len(digest.activeChannels) > 0 {
+		names := make([]string, 0, len(digest.activeChannels))
+		for _, active := range digest.activeChannels {
+			names = append(names, translateFunc("app.email_digest.summary.active_channel", active.unread, map[string]any{
+				"ChannelName": active.channel.DisplayName,
+			}))
+		}
+		lines = append(lines, translateFunc("app.email_digest.summary.active_channels", map[string]any{
+			"Channels": strings.Join(names, ", "),
+		}))
+	}
+
+	for i, line := range lines {
+		lines[i] = html.EscapeString(line)
+	}
+
+	return template.HTML(strings.Join(lines, "<br/>"))
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestEmailDigestDueAt(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		now       time.Time
+		loc       *time.Location
+		frequency string
+		expected  time.Time
+	}{
+		{
+			name:      "daily after the digest hour",
+			now:       time.Date(2024, 3, 13, 9, 30, 0, 0, time.UTC),
+			loc:       time.UTC,
+			frequency: model.PreferenceEmailDigestDaily,
+			expected:  time.Date(2024, 3, 13, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily before the digest hour",
+			now:       time.Date(2024, 3, 13, 7, 59, 0, 0, time.UTC),
+			loc:       time.UTC,
+			frequency: model.PreferenceEmailDigestDaily,
+			expected:  time.Date(2024, 3, 12, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "daily in the user's timezone",
+			now:       time.Date(2024, 3, 13, 9, 30, 0, 0, time.UTC),
+			loc:       newYork,
+			frequency: model.PreferenceEmailDigestDaily,
+			expected:  time.Date(2024, 3, 12, 8, 0, 0, 0, newYork),
+		},
+		{
+			name:      "weekly on a wednesday",
+			now:       time.Date(2024, 3, 13, 9, 30, 0, 0, time.UTC),
+			loc:       time.UTC,
+			frequency: model.PreferenceEmailDigestWeekly,
+			expected:  time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "weekly on a monday before the digest hour",
+			now:       time.Date(2024, 3, 11, 7, 0, 0, 0, time.UTC),
+			loc:       time.UTC,
+			frequency: model.PreferenceEmailDigestWeekly,
+			expected:  time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			due := emailDigestDueAt(tc.now, tc.loc, model.EmailDigestHour, tc.frequency)
+			assert.True(t, tc.expected.Equal(due), "expected %v, got %v", tc.expected, due)
+		})
+	}
+}
+
+func TestSendEmailDigests(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.EmailSettings.EnableEmailDigest = true
+		*cfg.EmailSettings.EmailDigestHour = model.EmailDigestHour
+	})
+
+	err := th.App.Srv().Store().Preference().Save(model.Preferences{{
+		UserId:   th.BasicUser2.Id,
+		Category: model.PreferenceCategoryNotifications,
+		Name:     model.PreferenceNameEmailDigest,
+		Value:    model.PreferenceEmailDigestDaily,
+	}})
+	require.NoError(t, err)
+
+	rootPost := th.CreatePost(th.BasicChannel)
+	_, appErr := th.App.CreatePostAsUser(th.Context, &model.Post{
+		ChannelId: th.BasicChannel.Id,
+		UserId:    th.BasicUser2.Id,
+		RootId:    rootPost.Id,
+		Message:   "following along",
+	}, "", false)
+	require.Nil(t, appErr)
+	th.CreatePostReply(rootPost)
+
+	lastSentAt := func() int64 {
+		preference, err := th.App.Srv().Store().Preference().Get(th.BasicUser2.Id, model.PreferenceCategoryNotifications, model.PreferenceNameEmailDigestLastSentAt)
+		if err != nil {
+			return 0
+		}
+		value, _ := strconv.ParseInt(preference.Value, 10, 64)
+		return value
+	}
+
+	today := time.Now().UTC()
+	due := time.Date(today.Year(), today.Month(), today.Day(), model.EmailDigestHour, 0, 0, 0, time.UTC)
+
+	t.Run("not sent before the digest hour", func(t *testing.T) {
+		err := th.App.sendEmailDigests(th.Context, due.Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, lastSentAt())
+	})
+
+	t.Run("sent once when due", func(t *testing.T) {
+		digest, err := th.App.getEmailDigest(th.Context, th.BasicUser2, due.AddDate(0, 0, -1).UnixMilli())
+		require.NoError(t, err)
+		require.Len(t, digest.threads, 1)
+		assert.Equal(t, rootPost.Id, digest.threads[0].PostId)
+
+		now := due.Add(time.Minute)
+		err = th.App.sendEmailDigests(th.Context, now)
+		require.NoError(t, err)
+		assert.Equal(t, now.UnixMilli(), lastSentAt())
+
+		err = th.App.sendEmailDigests(th.Context, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, now.UnixMilli(), lastSentAt(), "the digest must not be sent twice")
+	})
+
+	t.Run("not sent outside of the send window", func(t *testing.T) {
+		tomorrow := due.AddDate(0, 0, 1)
+
+		err := th.App.sendEmailDigests(th.Context, tomorrow.Add(emailDigestSendWindow+time.Minute))
+		require.NoError(t, err)
+		assert.Less(t, lastSentAt(), tomorrow.UnixMilli())
+	})
+}
@@ -766,6 +766,13 @@ func (a *App) exportAllPosts(rctx request.CTX, job *model.Job, writer io.Writer,
 				}
 			}
 
+			if post.Type == model.PostTypePoll {
+				postLine.Post.Poll, err = a.buildPollImportData(rctx, post.Id)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			if len(post.FileIds) > 0 {
 				postAttachments, err := a.buildPostAttachments(post.Id)
 				if err != nil {
@@ -803,6 +810,13 @@ func (a *App) buildPostReplies(rctx request.CTX, postID string, withAttachments
 				return nil, nil, appErr
 			}
 		}
+		if reply.Type == model.PostTypePoll {
+			var appErr *model.AppError
+			replyImportObject.Poll, appErr = a.buildPollImportData(rctx, reply.Id)
+			if appErr != nil {
+				return nil, nil, appErr
+			}
+		}
 		if len(reply.FileIds) > 0 {
 			postAttachments, appErr := a.buildPostAttachments(reply.Id)
 			if appErr != nil {
@@ -859,6 +873,50 @@ func (a *App) BuildPostReactions(rctx request.CTX, postID string) (*[]ReactionIm
 	return &reactionsOfPost, nil
 }
 
+// buildPollImportData returns the poll of a post with the usernames of its voters, or nil when
+// the poll of the post wasn't saved.
+func (a *App) buildPollImportData(rctx request.CTX, postID string) (*imports.PollImportData, *model.AppError) {
+	poll, nErr := a.Srv().Store().Poll().Get(postID)
+	if nErr != nil {
+		var nfErr *store.ErrNotFound
+		if errors.As(nErr, &nfErr) {
+			return nil, nil
+		}
+		return nil, model.NewAppError("buildPollImportData", "app.poll.get.app_error", nil, "post_id="+postID, http.StatusInternalServerError).Wrap(nErr)
+	}
+
+	votes, nErr := a.Srv().Store().Poll().GetVotes(postID)
+	if nErr != nil {
+		return nil, model.NewAppError("buildPollImportData", "app.poll.get_votes.app_error", nil, "post_id="+postID, http.StatusInternalServerError).Wrap(nErr)
+	}
+
+	usernames := map[string]string{}
+	voters := map[string][]string{}
+	for _, vote := range votes {
+		username, ok := usernames[vote.UserId]
+		if !ok {
+			user, err := a.Srv().Store().User().Get(context.Background(), vote.UserId)
+			if err != nil {
+				var nfErr *store.ErrNotFound
+				if errors.As(err, &nfErr) { // the user that voted might've been deleted by now
+					rctx.Logger().Info("Skipping poll votes by user since the entity doesn't exist anymore", mlog.String("user_id", vote.UserId))
+					usernames[vote.UserId] = ""
+					continue
+				}
+				return nil, model.NewAppError("buildPollImportData", "app.user.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+			}
+			username = user.Username
+			usernames[vote.UserId] = username
+		}
+		if username == "" {
+			continue
+		}
+		voters[vote.OptionId] = append(voters[vote.OptionId], username)
+	}
+
+	return importPollFromPoll(poll, voters), nil
+}
+
 func (a *App) buildPostAttachments(postID string) ([]imports.AttachmentImportData, *model.AppError) {
 	infos, nErr := a.Srv().Store().FileInfo().GetForPost(postID, false, false, false)
 	if nErr != nil {
@@ -1177,6 +1235,13 @@ func (a *App) exportAllDirectPosts(rctx request.CTX, job *model.Job, writer io.W
 				postLine.DirectPost.Attachments = &postAttachments
 			}
 
+			if post.Type == model.PostTypePoll {
+				postLine.DirectPost.Poll, err = a.buildPollImportData(rctx, post.Id)
+				if err != nil {
+					return nil, err
+				}
+			}
+
 			followers, err := a.buildThreadFollowers(rctx, post.Id)
 			if err != nil {
 				return nil, err
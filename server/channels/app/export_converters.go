@@ -320,6 +320,25 @@ func importReactionFromPost(user *model.User, reaction *model.Reaction) *imports
 	}
 }
 
+func importPollFromPoll(poll *model.Poll, voters map[string][]string) *imports.PollImportData {
+	options := make([]imports.PollOptionImportData, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		optionVoters := voters[option.Id]
+		options = append(options, imports.PollOptionImportData{
+			Text:   model.NewPointer(option.Text),
+			Voters: &optionVoters,
+		})
+	}
+
+	return &imports.PollImportData{
+		Question:       &poll.Question,
+		Options:        &options,
+		Anonymous:      &poll.Anonymous,
+		MultipleChoice: &poll.MultipleChoice,
+		ClosedAt:       &poll.ClosedAt,
+	}
+}
+
 func importLineFromEmoji(emoji *model.Emoji, filePath string) *imports.LineImportData {
 	return &imports.LineImportData{
 		Type: "emoji",
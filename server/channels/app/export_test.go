@@ -58,6 +58,43 @@ func TestReactionsOfPost(t *testing.T) {
 	assert.Equal(t, reactionObject.EmojiName, *(*reactionsOfPost)[0].EmojiName)
 }
 
+func TestBuildPollImportData(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	poll, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+		ChannelId: th.BasicChannel.Id,
+		Question:  "Lunch?",
+		Options:   []string{"Pizza", "Salad"},
+		Anonymous: true,
+	}, th.BasicUser.Id, false)
+	require.Nil(t, appErr)
+
+	deletedUser := th.CreateUser()
+	for _, userID := range []string{th.BasicUser.Id, th.BasicUser2.Id, deletedUser.Id} {
+		_, appErr = th.App.VotePoll(th.Context, poll.PostId, userID, []string{poll.Options[0].Id})
+		require.Nil(t, appErr)
+	}
+	require.NoError(t, th.App.Srv().Store().User().PermanentDelete(th.Context, deletedUser.Id))
+
+	pollData, appErr := th.App.buildPollImportData(th.Context, poll.PostId)
+	require.Nil(t, appErr)
+	require.NotNil(t, pollData)
+	assert.Equal(t, "Lunch?", *pollData.Question)
+	assert.True(t, *pollData.Anonymous)
+	require.Len(t, *pollData.Options, 2)
+	// The voters of anonymous polls are exported too, so that the poll can be imported as is.
+	assert.ElementsMatch(t, []string{th.BasicUser.Username, th.BasicUser2.Username}, *(*pollData.Options)[0].Voters)
+	assert.Empty(t, *(*pollData.Options)[1].Voters)
+
+	t.Run("post without a poll", func(t *testing.T) {
+		pollData, appErr := th.App.buildPollImportData(th.Context, th.BasicPost.Id)
+		require.Nil(t, appErr)
+		assert.Nil(t, pollData)
+	})
+}
+
 func TestExportUserNotifyProps(t *testing.T) {
 	mainHelper.Parallel(t)
 	th := SetupWithStoreMock(t)
@@ -714,6 +714,7 @@ type UploadFileTask struct {
 	fileinfo     *model.FileInfo
 	maxFileSize  int64
 	maxImageRes  int64
+	enableWebP   bool
 
 	// Cached image data that (may) get initialized in preprocessImage and
 	// is used in postprocessImage
@@ -777,6 +778,7 @@ func (a *App) UploadFileX(rctx request.CTX, channelID, name string, input io.Rea
 		Input:          input,
 		maxFileSize:    *a.Config().FileSettings.MaxFileSize,
 		maxImageRes:    *a.Config().FileSettings.MaxImageResolution,
+		enableWebP:     *a.Config().FileSettings.EnableWebPPreviews,
 		imgDecoder:     a.ch.imgDecoder,
 		imgEncoder:     a.ch.imgEncoder,
 		ExtractContent: true,
@@ -822,6 +824,17 @@ func (a *App) UploadFileX(rctx request.CTX, channelID, name string, input io.Rea
 
 	t.fileinfo.Size = written
 
+	if aerr = a.CheckFileStorageQuota(rctx, t.UserId, t.ChannelId, written); aerr != nil {
+		if fileErr := a.RemoveFile(t.fileinfo.Path); fileErr != nil {
+			rctx.Logger().Error("Failed to remove file", mlog.Err(fileErr))
+		}
+		return nil, aerr
+	}
+
+	if aerr = a.scanUploadedFile(rctx, t.fileinfo); aerr != nil {
+		return nil, aerr
+	}
+
 	file, aerr := a.FileReader(t.fileinfo.Path)
 	if aerr != nil {
 		return nil, aerr
@@ -834,6 +847,15 @@ func (a *App) UploadFileX(rctx request.CTX, channelID, name string, input io.Rea
 	}
 
 	if !t.Raw && t.fileinfo.IsImage() {
+		var stripped []byte
+		if stripped, aerr = a.stripImageMetadata(rctx, t.fileinfo); aerr != nil {
+			return nil, aerr
+		}
+		if stripped != nil {
+			// The orientation was either applied to the re-encoded image or kept in its metadata.
+			t.imageOrientation, _ = imaging.GetImageOrientation(bytes.NewReader(stripped), t.fileinfo.MimeType)
+		}
+
 		file, aerr = a.FileReader(t.fileinfo.Path)
 		if aerr != nil {
 			return nil, aerr
@@ -842,6 +864,8 @@ func (a *App) UploadFileX(rctx request.CTX, channelID, name string, input io.Rea
 		t.postprocessImage(file)
 	}
 
+	a.deduplicateFile(rctx, t.fileinfo)
+
 	if _, err := t.saveToDatabase(rctx, t.fileinfo); err != nil {
 		var appErr *model.AppError
 		switch {
@@ -947,19 +971,18 @@ func (t *UploadFileTask) postprocessImage(file io.Reader) {
 		return
 	}
 
-	writeImage := func(img image.Image, path string) {
+	// Animated GIFs get animated WebP previews. The file is still unread when the
+	// first frame was cached by preprocessImage.
+	var anim *imaging.Animation
+	if t.enableWebP && t.decoded != nil && imgType == "gif" {
+		anim = decodeAnimatedPreview(t.Logger, file)
+	}
+
+	writeImage := func(img image.Image, path string, encode func(io.Writer, image.Image) error) {
 		r, w := io.Pipe()
 		go func() {
-			var err error
-			// It's okay to access imgType in a separate goroutine,
-			// because imgType is only written once and never written again.
-			if imgType == "png" {
-				err = t.imgEncoder.EncodePNG(w, img)
-			} else {
-				err = t.imgEncoder.EncodeJPEG(w, img, jpegEncQuality)
-			}
-			if err != nil {
-				t.Logger.Error("Unable to encode image as jpeg", mlog.String("path", path), mlog.Err(err))
+			if err := encode(w, img); err != nil {
+				t.Logger.Error("Unable to encode image", mlog.String("path", path), mlog.Err(err))
 				w.CloseWithError(err)
 			} else {
 				w.Close()
@@ -973,18 +996,47 @@ func (t *UploadFileTask) postprocessImage(file io.Reader) {
 		}
 	}
 
+	// It's okay to access imgType in a separate goroutine,
+	// because imgType is only written once and never written again.
+	encodeImage := func(w io.Writer, img image.Image) error {
+		if imgType == "png" {
+			return t.imgEncoder.EncodePNG(w, img)
+		}
+		return t.imgEncoder.EncodeJPEG(w, img, jpegEncQuality)
+	}
+
+	writeImages := func(img image.Image, path string, resize func(image.Image) image.Image) {
+		img = resize(img)
+		writeImage(img, path, encodeImage)
+		if !t.enableWebP {
+			return
+		}
+
+		var resizedAnim *imaging.Animation
+		if anim != nil {
+			resizedAnim = imaging.ResizeAnimation(anim, resize)
+		}
+		writeImage(img, webpPreviewPath(path), func(w io.Writer, img image.Image) error {
+			return encodeWebPPreview(t.imgEncoder, w, img, resizedAnim, imgType)
+		})
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(3)
 	// Generating thumbnail and preview regardless of HasPreviewImage value.
 	// This is needed on mobile in case of animated GIFs.
 	go func() {
 		defer wg.Done()
-		writeImage(imaging.GenerateThumbnail(decoded, imageThumbnailWidth, imageThumbnailHeight), t.fileinfo.ThumbnailPath)
+		writeImages(decoded, t.fileinfo.ThumbnailPath, func(img image.Image) image.Image {
+			return imaging.GenerateThumbnail(img, imageThumbnailWidth, imageThumbnailHeight)
+		})
 	}()
 
 	go func() {
 		defer wg.Done()
-		writeImage(imaging.GeneratePreview(decoded, imagePreviewWidth), t.fileinfo.PreviewPath)
+		writeImages(decoded, t.fileinfo.PreviewPath, func(img image.Image) image.Image {
+			return imaging.GeneratePreview(img, imagePreviewWidth)
+		})
 	}()
 
 	go func() {
@@ -1106,6 +1158,22 @@ func (a *App) DoUploadFileExpectModification(rctx request.CTX, now time.Time, ra
 		return nil, data, err
 	}
 
+	if err := a.scanUploadedFile(rctx, info); err != nil {
+		return nil, data, err
+	}
+
+	if info.IsImage() {
+		stripped, appErr := a.stripImageMetadata(rctx, info)
+		if appErr != nil {
+			return nil, data, appErr
+		}
+		if stripped != nil {
+			data = stripped
+		}
+	}
+
+	a.deduplicateFile(rctx, info)
+
 	if _, err := a.Srv().Store().FileInfo().Save(rctx, info); err != nil {
 		var appErr *model.AppError
 		switch {
@@ -1141,16 +1209,23 @@ func (a *App) HandleImages(rctx request.CTX, previewPathList []string, thumbnail
 			rctx.Logger().Debug("Failed to prepare image", mlog.Err(err))
 			continue
 		}
+
+		// Animated GIFs get animated WebP previews instead of still ones.
+		var anim *imaging.Animation
+		if imgType == "gif" && *a.Config().FileSettings.EnableWebPPreviews {
+			anim = decodeAnimatedPreview(rctx.Logger(), bytes.NewReader(fileData[i]))
+		}
+
 		wg.Add(2)
-		go func(img image.Image, imgType, path string) {
+		go func(img image.Image, anim *imaging.Animation, imgType, path string) {
 			defer wg.Done()
-			a.generateThumbnailImage(rctx, img, imgType, path)
-		}(img, imgType, thumbnailPathList[i])
+			a.generateThumbnailImage(rctx, img, anim, imgType, path)
+		}(img, anim, imgType, thumbnailPathList[i])
 
-		go func(img image.Image, imgType, path string) {
+		go func(img image.Image, anim *imaging.Animation, imgType, path string) {
 			defer wg.Done()
-			a.generatePreviewImage(rctx, img, imgType, path)
-		}(img, imgType, previewPathList[i])
+			a.generatePreviewImage(rctx, img, anim, imgType, path)
+		}(img, anim, imgType, previewPathList[i])
 
 		wg.Wait()
 		release()
@@ -1177,7 +1252,9 @@ func prepareImage(rctx request.CTX, imgDecoder *imaging.Decoder, imgData io.Read
 	return img, imgType, release, nil
 }
 
-func (a *App) generateThumbnailImage(rctx request.CTX, img image.Image, imgType, thumbnailPath string) {
+// generateThumbnailImage writes the thumbnail of img, and its WebP variant when enabled. The
+// WebP variant is generated from anim instead when it is set.
+func (a *App) generateThumbnailImage(rctx request.CTX, img image.Image, anim *imaging.Animation, imgType, thumbnailPath string) {
 	var buf bytes.Buffer
 
 	thumb := imaging.GenerateThumbnail(img, imageThumbnailWidth, imageThumbnailHeight)
@@ -1197,9 +1274,20 @@ func (a *App) generateThumbnailImage(rctx request.CTX, img image.Image, imgType,
 		rctx.Logger().Error("Unable to upload thumbnail", mlog.String("path", thumbnailPath), mlog.Err(err))
 		return
 	}
+
+	if *a.Config().FileSettings.EnableWebPPreviews {
+		if anim != nil {
+			anim = imaging.ResizeAnimation(anim, func(img image.Image) image.Image {
+				return imaging.GenerateThumbnail(img, imageThumbnailWidth, imageThumbnailHeight)
+			})
+		}
+		a.writeWebPPreview(rctx, thumb, anim, imgType, thumbnailPath)
+	}
 }
 
-func (a *App) generatePreviewImage(rctx request.CTX, img image.Image, imgType, previewPath string) {
+// generatePreviewImage writes the preview of img, and its WebP variant when enabled. The
+// WebP variant is generated from anim instead when it is set.
+func (a *App) generatePreviewImage(rctx request.CTX, img image.Image, anim *imaging.Animation, imgType, previewPath string) {
 	var buf bytes.Buffer
 
 	preview := imaging.GeneratePreview(img, imagePreviewWidth)
@@ -1219,6 +1307,15 @@ func (a *App) generatePreviewImage(rctx request.CTX, img image.Image, imgType, p
 		rctx.Logger().Error("Unable to upload preview", mlog.Err(err), mlog.String("path", previewPath))
 		return
 	}
+
+	if *a.Config().FileSettings.EnableWebPPreviews {
+		if anim != nil {
+			anim = imaging.ResizeAnimation(anim, func(img image.Image) image.Image {
+				return imaging.GeneratePreview(img, imagePreviewWidth)
+			})
+		}
+		a.writeWebPPreview(rctx, preview, anim, imgType, previewPath)
+	}
 }
 
 // generateMiniPreview updates mini preview if needed
@@ -1496,14 +1593,14 @@ func (a *App) ExtractContentFromFileInfo(rctx request.CTX, fileInfo *model.FileI
 	defer file.Close()
 	text, err := docextractor.Extract(rctx.Logger(), fileInfo.Name, file, docextractor.ExtractSettings{
 		ArchiveRecursion: *a.Config().FileSettings.ArchiveRecursion,
+		MaxFileSize:      *a.Config().FileSettings.ExtractContentMaxFileSizeBytes,
+		MaxContentSize:   maxContentExtractionSize,
+		Timeout:          time.Duration(*a.Config().FileSettings.ExtractContentTimeoutMilliseconds) * time.Millisecond,
 	})
 	if err != nil {
 		return errors.Wrap(err, "failed to extract file content")
 	}
 	if text != "" {
-		if len(text) > maxContentExtractionSize {
-			text = text[0:maxContentExtractionSize]
-		}
 		if storeErr := a.Srv().Store().FileInfo().SetContent(rctx, fileInfo.Id, text); storeErr != nil {
 			return errors.Wrap(storeErr, "failed to save the extracted file content")
 		}
@@ -1647,12 +1744,20 @@ func (a *App) PermanentDeleteFilesByPost(rctx request.CTX, postID string) *model
 
 func (a *App) RemoveFilesFromFileStore(rctx request.CTX, fileInfos []*model.FileInfo) {
 	for _, info := range fileInfos {
-		a.RemoveFileFromFileStore(rctx, info.Path)
+		// Deduplicated blobs are removed once their last reference is gone.
+		if info.ContentHash == "" {
+			a.RemoveFileFromFileStore(rctx, info.Path)
+		}
+		if info.OriginalPath != "" {
+			a.RemoveFileFromFileStore(rctx, info.OriginalPath)
+		}
 		if info.PreviewPath != "" {
 			a.RemoveFileFromFileStore(rctx, info.PreviewPath)
+			a.removeWebPPreview(rctx, info.PreviewPath)
 		}
 		if info.ThumbnailPath != "" {
 			a.RemoveFileFromFileStore(rctx, info.ThumbnailPath)
+			a.removeWebPPreview(rctx, info.ThumbnailPath)
 		}
 	}
 }
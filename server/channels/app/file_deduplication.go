@@ -0,0 +1,225 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const (
+	fileBlobsDirectory = "blobs/sha256/"
+
+	fileDeduplicationBatchSize = 100
+
+	// unreferencedFileBlobGracePeriod is how long a blob must have been left without
+	// references before it is removed. Uploads reserve the blob before storing it, so
+	// this leaves them plenty of time to save the FileInfo referencing it.
+	unreferencedFileBlobGracePeriod = time.Hour
+)
+
+// fileBlobPath returns the path of the deduplicated blob with the given content hash.
+func fileBlobPath(hash string) string {
+	return fileBlobsDirectory + hash[:2] + "/" + hash[2:4] + "/" + hash
+}
+
+func (a *App) hashFile(path string) (string, error) {
+	file, appErr := a.FileReader(path)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", errors.Wrapf(err, "failed to read file %s", path)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// storeFileBlob makes sure the blob with the given content hash is present in the file
+// store, taking it from the file at path when it isn't referenced yet. When move is true,
+// the file at path is moved into the blob or removed if the blob already exists, otherwise
+// it is left untouched.
+func (a *App) storeFileBlob(path, hash string, size int64, move bool) (string, error) {
+	blobPath := fileBlobPath(hash)
+
+	refCount, err := a.Srv().Store().FileInfo().ReserveBlob(hash, size)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to reserve the file blob")
+	}
+
+	if refCount > 0 {
+		exists, appErr := a.FileExists(blobPath)
+		if appErr != nil {
+			return "", appErr
+		}
+		if exists {
+			if move {
+				if appErr := a.RemoveFile(path); appErr != nil {
+					return "", appErr
+				}
+			}
+			return blobPath, nil
+		}
+	}
+
+	if move {
+		if appErr := a.MoveFile(path, blobPath); appErr != nil {
+			return "", appErr
+		}
+		return blobPath, nil
+	}
+
+	file, appErr := a.FileReader(path)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer file.Close()
+
+	if _, appErr := a.WriteFile(file, blobPath); appErr != nil {
+		return "", appErr
+	}
+	return blobPath, nil
+}
+
+// deduplicateFile moves a newly uploaded file into the content-addressed blob storage,
+// sharing the blob with any previously uploaded file with the same content. It must be
+// called right before the FileInfo is saved, which adds its reference to the blob. Files
+// that can't be deduplicated are left where they were uploaded.
+func (a *App) deduplicateFile(rctx request.CTX, info *model.FileInfo) {
+	if !*a.Config().FileSettings.EnableContentDeduplication || info.ContentHash != "" {
+		return
+	}
+
+	hash, err := a.hashFile(info.Path)
+	if err != nil {
+		rctx.Logger().Warn("Failed to hash the uploaded file", mlog.String("path", info.Path), mlog.Err(err))
+		return
+	}
+
+	blobPath, err := a.storeFileBlob(info.Path, hash, info.Size, true)
+	if err != nil {
+		rctx.Logger().Warn("Failed to deduplicate the uploaded file", mlog.String("path", info.Path), mlog.Err(err))
+		return
+	}
+
+	info.Path = blobPath
+	info.ContentHash = hash
+}
+
+// DeduplicateFiles moves the files uploaded before content deduplication was enabled into
+// the content-addressed blob storage, and removes the blobs whose last reference is gone.
+// Files that can't be deduplicated are logged and skipped. It returns the number of
+// deduplicated files and of removed blobs.
+func (a *App) DeduplicateFiles(rctx request.CTX) (int, int, error) {
+	deduplicated, failed := 0, 0
+
+	afterID := ""
+	for {
+		infos, err := a.Srv().Store().FileInfo().GetBatchWithoutContentHash(afterID, fileDeduplicationBatchSize)
+		if err != nil {
+			return deduplicated, 0, errors.Wrap(err, "failed to get the files to deduplicate")
+		}
+		if len(infos) == 0 {
+			break
+		}
+		afterID = infos[len(infos)-1].Id
+
+		// Copied FileInfos share their path, which is deduplicated all at once.
+		seenPaths := make(map[string]bool, len(infos))
+		for _, info := range infos {
+			if seenPaths[info.Path] {
+				continue
+			}
+			seenPaths[info.Path] = true
+
+			n, err := a.deduplicateStoredFile(rctx, info)
+			if err != nil {
+				rctx.Logger().Warn("Failed to deduplicate a file", mlog.String("file_info_id", info.Id), mlog.String("path", info.Path), mlog.Err(err))
+				failed++
+				continue
+			}
+			deduplicated += n
+		}
+	}
+
+	removed, err := a.removeUnreferencedFileBlobs(rctx)
+	if err != nil {
+		return deduplicated, removed, err
+	}
+
+	rctx.Logger().Info("Deduplicated stored files", mlog.Int("deduplicated", deduplicated), mlog.Int("failed", failed), mlog.Int("removed_blobs", removed))
+	if failed > 0 {
+		return deduplicated, removed, errors.Errorf("failed to deduplicate %d files", failed)
+	}
+	return deduplicated, removed, nil
+}
+
+func (a *App) deduplicateStoredFile(rctx request.CTX, info *model.FileInfo) (int, error) {
+	hash, err := a.hashFile(info.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	blobPath, err := a.storeFileBlob(info.Path, hash, info.Size, false)
+	if err != nil {
+		return 0, err
+	}
+
+	updated, err := a.Srv().Store().FileInfo().SetContentHashForPath(rctx, info.Path, hash, blobPath, info.Size)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to update the file infos")
+	}
+
+	if len(updated) == 0 {
+		return 0, nil
+	}
+
+	if appErr := a.RemoveFile(info.Path); appErr != nil {
+		rctx.Logger().Warn("Failed to remove a deduplicated file", mlog.String("path", info.Path), mlog.Err(appErr))
+	}
+
+	return len(updated), nil
+}
+
+// removeUnreferencedFileBlobs removes from the file store the blobs whose last reference
+// was deleted more than unreferencedFileBlobGracePeriod ago.
+func (a *App) removeUnreferencedFileBlobs(rctx request.CTX) (int, error) {
+	removed := 0
+	updatedBefore := model.GetMillisForTime(time.Now().Add(-unreferencedFileBlobGracePeriod))
+	for {
+		hashes, err := a.Srv().Store().FileInfo().DeleteUnreferencedBlobs(updatedBefore, fileDeduplicationBatchSize)
+		if err != nil {
+			return removed, errors.Wrap(err, "failed to delete the unreferenced file blobs")
+		}
+
+		for _, hash := range hashes {
+			// An upload may have stored the blob again since its row was deleted.
+			var nfErr *store.ErrNotFound
+			if _, err := a.Srv().Store().FileInfo().GetBlob(hash); !errors.As(err, &nfErr) {
+				continue
+			}
+
+			if appErr := a.RemoveFile(fileBlobPath(hash)); appErr != nil {
+				rctx.Logger().Warn("Failed to remove an unreferenced file blob", mlog.String("hash", hash), mlog.Err(appErr))
+				continue
+			}
+			removed++
+		}
+
+		if len(hashes) < fileDeduplicationBatchSize {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestDeduplicateUploadedFiles(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableContentDeduplication = true
+	})
+
+	data := []byte("the same content shared into many channels")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	upload := func(channelID, name string) *model.FileInfo {
+		t.Helper()
+
+		info, appErr := th.App.UploadFileX(th.Context, channelID, name, bytes.NewReader(data),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()),
+			UploadFileSetRaw())
+		require.Nil(t, appErr)
+		return info
+	}
+
+	info1 := upload(th.BasicChannel.Id, "installer.bin")
+	info2 := upload(th.CreateChannel(th.Context, th.BasicTeam).Id, "copy.bin")
+
+	assert.Equal(t, hash, info1.ContentHash)
+	assert.Equal(t, fileBlobPath(hash), info1.Path)
+	assert.Equal(t, info1.Path, info2.Path)
+
+	blob, err := th.App.Srv().Store().FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, blob.RefCount)
+	assert.EqualValues(t, len(data), blob.Size)
+
+	th.App.RemoveFilesFromFileStore(th.Context, []*model.FileInfo{info1})
+	require.NoError(t, th.App.Srv().Store().FileInfo().PermanentDelete(th.Context, info1.Id))
+
+	content, appErr := th.App.ReadFile(info2.Path)
+	require.Nil(t, appErr)
+	assert.Equal(t, data, content, "the blob is kept while it is still referenced")
+
+	require.NoError(t, th.App.Srv().Store().FileInfo().PermanentDelete(th.Context, info2.Id))
+
+	removed, err := th.App.removeUnreferencedFileBlobs(th.Context)
+	require.NoError(t, err)
+	assert.Zero(t, removed, "recently released blobs are kept")
+
+	_, err = th.GetSqlStore().GetMaster().Exec("UPDATE FileBlobs SET UpdateAt = 0 WHERE Hash = ?", hash)
+	require.NoError(t, err)
+
+	removed, err = th.App.removeUnreferencedFileBlobs(th.Context)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	exists, appErr := th.App.FileExists(fileBlobPath(hash))
+	require.Nil(t, appErr)
+	assert.False(t, exists)
+}
+
+func TestDeduplicateFiles(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	data := []byte("uploaded before deduplication was enabled")
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var infos []*model.FileInfo
+	for _, name := range []string{"a.txt", "b.txt"} {
+		info, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, name, bytes.NewReader(data),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()),
+			UploadFileSetRaw())
+		require.Nil(t, appErr)
+		assert.Empty(t, info.ContentHash)
+		infos = append(infos, info)
+	}
+
+	copyIDs, appErr := th.App.CopyFileInfos(th.Context, th.BasicUser.Id, []string{infos[0].Id})
+	require.Nil(t, appErr)
+
+	// The store may hold file infos left by other tests, whose files are long gone.
+	deduplicated, _, _ := th.App.DeduplicateFiles(th.Context)
+	assert.GreaterOrEqual(t, deduplicated, 3)
+
+	for _, id := range []string{infos[0].Id, infos[1].Id, copyIDs[0]} {
+		info, err := th.App.Srv().Store().FileInfo().Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, hash, info.ContentHash)
+		assert.Equal(t, fileBlobPath(hash), info.Path)
+	}
+
+	blob, err := th.App.Srv().Store().FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, blob.RefCount)
+
+	for _, info := range infos {
+		exists, appErr := th.App.FileExists(info.Path)
+		require.Nil(t, appErr)
+		assert.False(t, exists, "the original files are removed")
+	}
+
+	content, appErr := th.App.ReadFile(fileBlobPath(hash))
+	require.Nil(t, appErr)
+	assert.Equal(t, data, content)
+
+	_, _, _ = th.App.DeduplicateFiles(th.Context)
+	blob, err = th.App.Srv().Store().FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, blob.RefCount, "deduplicated files are left alone")
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+// RewrapFileEncryptionKeys wraps the data keys of the stored files with the active master key
+// after it has been rotated, so that the retired master keys can then be removed. Files that
+// can't be re-wrapped are logged and skipped. It returns the number of re-wrapped files.
+func (a *App) RewrapFileEncryptionKeys(logger mlog.LoggerIFace) (int, error) {
+	fileBackend := a.FileBackend()
+	if dualRead, ok := fileBackend.(*filestore.DualReadFileBackend); ok {
+		// Files still in the fallback backend are re-encrypted as they are migrated.
+		fileBackend = dualRead.Primary()
+	}
+
+	backend, ok := fileBackend.(*filestore.EncryptedFileBackend)
+	if !ok || !backend.HasRetiredMasterKeys() {
+		return 0, nil
+	}
+
+	paths, err := backend.ListDirectoryRecursively("")
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list the stored files")
+	}
+
+	rewrapped, failed := 0, 0
+	for _, path := range paths {
+		ok, err := backend.RewrapFile(path)
+		if err != nil {
+			logger.Warn("Failed to re-wrap the data key of a file", mlog.String("path", path), mlog.Err(err))
+			failed++
+			continue
+		}
+		if ok {
+			rewrapped++
+		}
+	}
+
+	logger.Info("Re-wrapped the data keys of stored files", mlog.Int("rewrapped", rewrapped), mlog.Int("failed", failed))
+	if failed > 0 {
+		return rewrapped, errors.Errorf("failed to re-wrap the data keys of %d files", failed)
+	}
+	return rewrapped, nil
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+func TestRewrapFileEncryptionKeys(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	newKey := func() []byte {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		return key
+	}
+	oldKey, activeKey := newKey(), newKey()
+
+	backend := th.App.FileBackend()
+	t.Cleanup(func() {
+		th.App.ch.filestore = backend
+	})
+
+	t.Run("encryption disabled", func(t *testing.T) {
+		rewrapped, err := th.App.RewrapFileEncryptionKeys(th.TestLogger)
+		require.NoError(t, err)
+		assert.Zero(t, rewrapped)
+	})
+
+	oldBackend, err := filestore.NewEncryptedFileBackend(backend, [][]byte{oldKey})
+	require.NoError(t, err)
+	_, err = oldBackend.WriteFile(bytes.NewReader([]byte("content")), "encryption/file")
+	require.NoError(t, err)
+
+	th.App.ch.filestore, err = filestore.NewEncryptedFileBackend(backend, [][]byte{activeKey, oldKey})
+	require.NoError(t, err)
+
+	rewrapped, err := th.App.RewrapFileEncryptionKeys(th.TestLogger)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rewrapped)
+
+	rewrapped, err = th.App.RewrapFileEncryptionKeys(th.TestLogger)
+	require.NoError(t, err)
+	assert.Zero(t, rewrapped)
+
+	th.App.ch.filestore, err = filestore.NewEncryptedFileBackend(backend, [][]byte{activeKey})
+	require.NoError(t, err)
+	data, err := th.App.ReadFile("encryption/file")
+	require.Nil(t, err)
+	assert.Equal(t, "content", string(data))
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/services/malwarescan"
+)
+
+// quarantinePathPrefix is the file store directory infected files are moved to when they're quarantined.
+const quarantinePathPrefix = "quarantine/"
+
+// scanUploadedFile scans the stored file of info for malware when a scanner is configured, and
+// records the verdict on info. The upload is rejected when the file is infected or can't be
+// scanned: the file is then removed, or moved to the quarantine with its file info saved as deleted.
+func (a *App) scanUploadedFile(rctx request.CTX, info *model.FileInfo) *model.AppError {
+	settings := a.Config().FileSettings
+	scanner, err := malwarescan.New(malwarescan.Settings{
+		Driver:  *settings.MalwareScanDriver,
+		Address: *settings.MalwareScanAddress,
+		Timeout: time.Duration(*settings.MalwareScanTimeoutMilliseconds) * time.Millisecond,
+	})
+	if err != nil {
+		a.removeRejectedFile(rctx, info.Path)
+		return model.NewAppError("scanUploadedFile", "app.file.malware_scan.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if scanner == nil {
+		return nil
+	}
+
+	file, appErr := a.FileReader(info.Path)
+	if appErr != nil {
+		a.removeRejectedFile(rctx, info.Path)
+		return appErr
+	}
+	verdict, err := scanner.Scan(rctx.Context(), file)
+	file.Close()
+	if err != nil {
+		a.removeRejectedFile(rctx, info.Path)
+		return model.NewAppError("scanUploadedFile", "app.file.malware_scan.app_error", nil, "", http.StatusServiceUnavailable).Wrap(err)
+	}
+
+	if !verdict.Infected {
+		info.ScanVerdict = model.FileScanVerdictClean
+		return nil
+	}
+
+	info.ScanVerdict = model.FileScanVerdictInfected
+	info.ScanSignature = truncateScanSignature(verdict.Signature)
+	rctx.Logger().Warn("Rejected an infected file",
+		mlog.String("file_id", info.Id),
+		mlog.String("user_id", info.CreatorId),
+		mlog.String("signature", verdict.Signature),
+		mlog.String("action", *settings.MalwareScanAction),
+	)
+
+	infectedErr := model.NewAppError("scanUploadedFile", "app.file.malware_scan.infected.app_error", map[string]any{"Filename": info.Name}, "signature="+verdict.Signature, http.StatusBadRequest)
+	if *settings.MalwareScanAction != model.MalwareScanActionQuarantine {
+		a.removeRejectedFile(rctx, info.Path)
+		return infectedErr
+	}
+
+	if appErr := a.quarantineFile(rctx, info); appErr != nil {
+		a.removeRejectedFile(rctx, info.Path)
+		return appErr
+	}
+	return infectedErr
+}
+
+// quarantineFile moves the file of info to the quarantine and saves info as deleted, so that it
+// can only be listed by admins.
+func (a *App) quarantineFile(rctx request.CTX, info *model.FileInfo) *model.AppError {
+	quarantinePath := quarantinePathPrefix + info.Path
+	if appErr := a.MoveFile(info.Path, quarantinePath); appErr != nil {
+		return appErr
+	}
+
+	quarantined := *info
+	quarantined.Path = quarantinePath
+	quarantined.PostId = ""
+	quarantined.ThumbnailPath = ""
+	quarantined.PreviewPath = ""
+	quarantined.HasPreviewImage = false
+	quarantined.MiniPreview = nil
+	quarantined.DeleteAt = model.GetMillis()
+	if _, err := a.Srv().Store().FileInfo().Save(rctx, &quarantined); err != nil {
+		a.removeRejectedFile(rctx, quarantinePath)
+		return model.NewAppError("quarantineFile", "app.file_info.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+// truncateScanSignature shortens the signature reported by the scanner to the size of its column.
+func truncateScanSignature(signature string) string {
+	runes := []rune(signature)
+	if len(runes) > model.FileScanSignatureMaxRunes {
+		runes = runes[:model.FileScanSignatureMaxRunes]
+	}
+	return string(runes)
+}
+
+func (a *App) removeRejectedFile(rctx request.CTX, path string) {
+	if fileErr := a.RemoveFile(path); fileErr != nil {
+		rctx.Logger().Warn("Failed to remove file", mlog.Err(fileErr))
+	}
+}
+
+// GetQuarantinedFileInfos returns the infected files kept in quarantine, most recent first.
+func (a *App) GetQuarantinedFileInfos(page, perPage int) ([]*model.FileInfo, *model.AppError) {
+	infos, err := a.Srv().Store().FileInfo().GetQuarantined(page*perPage, perPage)
+	if err != nil {
+		return nil, model.NewAppError("GetQuarantinedFileInfos", "app.file_info.get_quarantined.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return infos, nil
+}
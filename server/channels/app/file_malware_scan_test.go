@@ -0,0 +1,149 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// startFakeClamd serves INSTREAM scans, reporting the files containing "EICAR" as infected.
+func startFakeClamd(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := io.ReadFull(conn, make([]byte, len("zINSTREAM\x00"))); err != nil {
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if err := binary.Read(conn, binary.BigEndian, &size); err != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					if _, err := io.CopyN(&data, conn, int64(size)); err != nil {
+						return
+					}
+				}
+				reply := "stream: OK\x00"
+				if bytes.Contains(data.Bytes(), []byte("EICAR")) {
+					reply = "stream: Eicar-Signature FOUND\x00"
+				}
+				conn.Write([]byte(reply))
+			}()
+		}
+	}()
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func TestScanUploadedFile(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	upload := func(data string) (*model.FileInfo, *model.AppError) {
+		t.Helper()
+
+		return th.App.UploadFileX(th.Context, th.BasicChannel.Id, "scanned.txt", bytes.NewReader([]byte(data)),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()),
+			UploadFileSetRaw())
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		info, appErr := upload("EICAR")
+		require.Nil(t, appErr)
+		assert.Empty(t, info.ScanVerdict)
+	})
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.MalwareScanDriver = model.MalwareScanDriverClamd
+		*cfg.FileSettings.MalwareScanAddress = startFakeClamd(t)
+	})
+
+	t.Run("clean file", func(t *testing.T) {
+		info, appErr := upload("hello world")
+		require.Nil(t, appErr)
+		assert.Equal(t, model.FileScanVerdictClean, info.ScanVerdict)
+
+		saved, appErr := th.App.GetFileInfo(th.Context, info.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.FileScanVerdictClean, saved.ScanVerdict)
+	})
+
+	t.Run("infected file is rejected", func(t *testing.T) {
+		_, appErr := upload("EICAR")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.malware_scan.infected.app_error", appErr.Id)
+		assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+		infos, appErr := th.App.GetQuarantinedFileInfos(0, 10)
+		require.Nil(t, appErr)
+		assert.Empty(t, infos)
+	})
+
+	t.Run("infected file is quarantined", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.MalwareScanAction = model.MalwareScanActionQuarantine
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.MalwareScanAction = model.MalwareScanActionReject
+		})
+
+		_, appErr := upload("EICAR")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.malware_scan.infected.app_error", appErr.Id)
+
+		infos, appErr := th.App.GetQuarantinedFileInfos(0, 10)
+		require.Nil(t, appErr)
+		require.Len(t, infos, 1)
+		assert.Equal(t, model.FileScanVerdictInfected, infos[0].ScanVerdict)
+		assert.Equal(t, "Eicar-Signature", infos[0].ScanSignature)
+		assert.NotZero(t, infos[0].DeleteAt)
+		assert.Regexp(t, "^"+quarantinePathPrefix, infos[0].Path)
+
+		data, appErr := th.App.ReadFile(infos[0].Path)
+		require.Nil(t, appErr)
+		assert.Equal(t, "EICAR", string(data))
+
+		_, appErr = th.App.GetFileInfo(th.Context, infos[0].Id)
+		require.NotNil(t, appErr, "quarantined files can't be downloaded")
+	})
+
+	t.Run("unreachable scanner", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.MalwareScanAddress = "unix:///nonexistent/clamd.sock"
+		})
+
+		_, appErr := upload("hello world")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.malware_scan.app_error", appErr.Id)
+		assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"image"
+	"net/http"
+	"path"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/app/imaging"
+)
+
+// The image formats whose metadata are stripped, by MIME type.
+var strippableImageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// originalFilePath returns the path the upload stored at filePath is kept at once its
+// metadata are stripped.
+func originalFilePath(filePath string) string {
+	return path.Dir(filePath) + "/original/" + path.Base(filePath)
+}
+
+// stripImageMetadata rewrites an uploaded JPEG, PNG or WebP image without its EXIF, IPTC
+// and XMP metadata when metadata stripping is enabled. JPEG and animated WebP images only lose
+// their metadata segments or chunks and keep their orientation, while the other images are
+// re-encoded with their orientation applied to the pixels. The PNG and WebP images that can't
+// be decoded only lose their metadata chunks, and an image whose metadata can't be removed at
+// all is rejected and deleted. When compliance export is enabled, the original upload
+// is kept aside for it. It returns the content of the rewritten image, or nil when it was
+// left as is.
+func (a *App) stripImageMetadata(rctx request.CTX, info *model.FileInfo) ([]byte, *model.AppError) {
+	if !*a.Config().FileSettings.StripImageMetadata || info.MetadataStripped {
+		return nil, nil
+	}
+	format, ok := strippableImageFormats[info.MimeType]
+	if !ok {
+		return nil, nil
+	}
+
+	data, appErr := a.ReadFile(info.Path)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	// Images whose metadata can't be parsed are rewritten anyway.
+	if found, err := imaging.HasMetadata(bytes.NewReader(data), format); err == nil && !found {
+		return nil, nil
+	}
+
+	orientation, err := imaging.GetImageOrientation(bytes.NewReader(data), format)
+	if err != nil {
+		rctx.Logger().Debug("GetImageOrientation failed", mlog.Err(err))
+	}
+
+	// Re-encoding JPEG images would lower their quality and drop their color profile.
+	var stripped []byte
+	if format == "jpeg" {
+		if stripped, err = imaging.StripJPEGMetadata(data, orientation); err != nil {
+			rctx.Logger().Debug("Unable to strip the metadata segments of the image, re-encoding it", mlog.String("path", info.Path), mlog.Err(err))
+		}
+	} else if format == "webp" && imaging.IsAnimatedWebP(data) {
+		// Animated WebP images can't be decoded, so only their metadata chunks are removed.
+		if stripped, err = imaging.StripWebPMetadata(data, orientation); err != nil {
+			return nil, model.NewAppError("stripImageMetadata", "app.file.strip_metadata.encode.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	var img image.Image
+	if stripped == nil {
+		var release func()
+		img, _, release, err = a.ch.imgDecoder.DecodeMemBounded(bytes.NewReader(data))
+		if err != nil {
+			// The metadata chunks of the images that can't be decoded are removed without decoding them.
+			rctx.Logger().Debug("Unable to decode the image to strip its metadata, removing its metadata chunks", mlog.String("path", info.Path), mlog.Err(err))
+			switch format {
+			case "png":
+				stripped, err = imaging.StripPNGMetadata(data, orientation)
+			case "webp":
+				stripped, err = imaging.StripWebPMetadata(data, orientation)
+			}
+			if stripped == nil {
+				// The image can't be kept with its metadata.
+				a.removeRejectedFile(rctx, info.Path)
+				return nil, model.NewAppError("stripImageMetadata", "app.file.strip_metadata.decode.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+			}
+		} else {
+			defer release()
+		}
+	}
+
+	if img != nil {
+		img = imaging.MakeImageUpright(img, orientation)
+
+		var buf bytes.Buffer
+		switch format {
+		case "png":
+			err = a.ch.imgEncoder.EncodePNG(&buf, img)
+		case "webp":
+			err = a.ch.imgEncoder.EncodeWebP(&buf, img, imaging.WebPOptions{
+				Lossless: imaging.IsLosslessWebP(data),
+				Quality:  jpegEncQuality,
+			})
+		default:
+			err = a.ch.imgEncoder.EncodeJPEG(&buf, img, jpegEncQuality)
+		}
+		if err != nil {
+			return nil, model.NewAppError("stripImageMetadata", "app.file.strip_metadata.encode.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+
+		stripped = buf.Bytes()
+		bounds := img.Bounds()
+		info.Width, info.Height = bounds.Dx(), bounds.Dy()
+	}
+
+	if *a.Config().MessageExportSettings.EnableExport {
+		originalPath := originalFilePath(info.Path)
+		if appErr := a.MoveFile(info.Path, originalPath); appErr != nil {
+			return nil, appErr
+		}
+		info.OriginalPath = originalPath
+	}
+
+	if _, appErr := a.WriteFile(bytes.NewReader(stripped), info.Path); appErr != nil {
+		return nil, appErr
+	}
+
+	info.Size = int64(len(stripped))
+	info.MetadataStripped = true
+	return stripped, nil
+}
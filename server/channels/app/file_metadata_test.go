@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/app/imaging"
+	"github.com/mattermost/mattermost/server/v8/channels/utils/fileutils"
+)
+
+func TestStripImageMetadata(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	imgDir, ok := fileutils.FindDir("tests/exif_samples")
+	require.True(t, ok)
+
+	uploadData := func(t *testing.T, name string, data []byte) *model.FileInfo {
+		t.Helper()
+
+		info, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, name, bytes.NewReader(data),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()))
+		require.Nil(t, appErr)
+		return info
+	}
+
+	upload := func(t *testing.T, name string) *model.FileInfo {
+		t.Helper()
+
+		data, err := os.ReadFile(filepath.Join(imgDir, name))
+		require.NoError(t, err)
+		return uploadData(t, name, data)
+	}
+
+	hasMetadata := func(t *testing.T, path, format string) bool {
+		t.Helper()
+
+		data, appErr := th.App.ReadFile(path)
+		require.Nil(t, appErr)
+		found, err := imaging.HasMetadata(bytes.NewReader(data), format)
+		require.NoError(t, err)
+		return found
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		info := upload(t, "left.jpg")
+		assert.False(t, info.MetadataStripped)
+		assert.True(t, hasMetadata(t, info.Path, "jpeg"))
+	})
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.StripImageMetadata = true
+	})
+
+	for name, format := range map[string]string{
+		"left.jpg":  "jpeg",
+		"left.png":  "png",
+		"left.webp": "webp",
+	} {
+		t.Run(name, func(t *testing.T) {
+			info := upload(t, name)
+			assert.True(t, info.MetadataStripped)
+			assert.Empty(t, info.OriginalPath)
+			assert.False(t, hasMetadata(t, info.Path, format))
+
+			data, appErr := th.App.ReadFile(info.Path)
+			require.Nil(t, appErr)
+			assert.EqualValues(t, len(data), info.Size)
+			orientation, err := imaging.GetImageOrientation(bytes.NewReader(data), format)
+			require.NoError(t, err)
+			width, height, err := imaging.GetDimensions(bytes.NewReader(data))
+			require.NoError(t, err)
+			if format == "jpeg" {
+				// The orientation was kept, since the image wasn't re-encoded.
+				assert.Equal(t, imaging.RotatedCCW, orientation)
+				assert.Equal(t, info.Width, height)
+				assert.Equal(t, info.Height, width)
+			} else {
+				// The orientation was applied to the pixels.
+				assert.Equal(t, imaging.Upright, orientation)
+				assert.Equal(t, info.Width, width)
+				assert.Equal(t, info.Height, height)
+			}
+
+			saved, appErr := th.App.GetFileInfo(th.Context, info.Id)
+			require.Nil(t, appErr)
+			assert.True(t, saved.MetadataStripped)
+		})
+	}
+
+	t.Run("animated webp", func(t *testing.T) {
+		encoder, err := imaging.NewEncoder(imaging.EncoderOptions{})
+		require.NoError(t, err)
+		frame := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+		var buf bytes.Buffer
+		require.NoError(t, encoder.EncodeAnimatedWebP(&buf, &imaging.Animation{
+			Frames:    []image.Image{frame, frame},
+			Durations: []int{100, 100},
+		}, imaging.WebPOptions{Lossless: true}))
+
+		// An XMP chunk is added after the frames, and announced by the VP8X chunk.
+		xmp := "<x:xmpmeta xmlns:x='adobe:ns:meta/'></x:xmpmeta>"
+		data := buf.Bytes()
+		data[20] |= 0x04
+		data = append(data, "XMP "...)
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(xmp)))
+		data = append(data, xmp...)
+		binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+		require.True(t, imaging.IsAnimatedWebP(data))
+
+		info := uploadData(t, "animated.webp", data)
+		assert.True(t, info.MetadataStripped)
+		assert.False(t, hasMetadata(t, info.Path, "webp"))
+
+		stripped, appErr := th.App.ReadFile(info.Path)
+		require.Nil(t, appErr)
+		assert.True(t, imaging.IsAnimatedWebP(stripped))
+		assert.EqualValues(t, len(stripped), info.Size)
+	})
+
+	t.Run("png that can't be decoded", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(imgDir, "left.png"))
+		require.NoError(t, err)
+		// Corrupting the image data keeps the chunks, and the metadata, readable.
+		data[bytes.Index(data, []byte("IDAT"))+4] ^= 0xff
+
+		info := uploadData(t, "corrupted.png", data)
+		assert.True(t, info.MetadataStripped)
+		assert.False(t, hasMetadata(t, info.Path, "png"))
+	})
+
+	t.Run("original kept for compliance export", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.MessageExportSettings.EnableExport = true
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.MessageExportSettings.EnableExport = false
+		})
+
+		info := upload(t, "left.jpg")
+		assert.True(t, info.MetadataStripped)
+		assert.Equal(t, originalFilePath(info.Path), info.OriginalPath)
+		assert.False(t, hasMetadata(t, info.Path, "jpeg"))
+		assert.True(t, hasMetadata(t, info.OriginalPath, "jpeg"))
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"image"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/app/imaging"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	webpEncQuality = 80
+
+	// animatedPreviewMaxPixels bounds the size of the decoded frames of an animated GIF,
+	// all of which are kept in memory to generate its animated thumbnail and preview.
+	animatedPreviewMaxPixels = 32 * 1024 * 1024
+
+	filePreviewsRegenerationBatchSize = 50
+)
+
+// webpPreviewPath returns the path of the WebP variant of a thumbnail or preview image.
+func webpPreviewPath(previewPath string) string {
+	return strings.TrimSuffix(previewPath, path.Ext(previewPath)) + ".webp"
+}
+
+// webpPreviewOptions returns the options to encode the WebP variants of the previews of an
+// image of the given type. Lossless images keep screenshots and GIFs sharp, and are smaller
+// than their PNG counterparts.
+func webpPreviewOptions(imgType string) imaging.WebPOptions {
+	return imaging.WebPOptions{
+		Lossless: imgType == "png" || imgType == "gif",
+		Quality:  webpEncQuality,
+	}
+}
+
+// decodeAnimatedPreview decodes the frames of an animated GIF. It returns nil for still
+// images, and for animations too large to be previewed.
+func decodeAnimatedPreview(logger mlog.LoggerIFace, rd io.Reader) *imaging.Animation {
+	anim, err := imaging.DecodeGIFAnimation(rd, animatedPreviewMaxPixels)
+	if err != nil {
+		logger.Debug("Unable to decode the gif animation", mlog.Err(err))
+		return nil
+	}
+	if len(anim.Frames) < 2 {
+		return nil
+	}
+	return anim
+}
+
+// encodeWebPPreview encodes a thumbnail or preview image in WebP format. When anim is set,
+// the animated image is encoded instead of img.
+func encodeWebPPreview(encoder *imaging.Encoder, w io.Writer, img image.Image, anim *imaging.Animation, imgType string) error {
+	if anim != nil {
+		return encoder.EncodeAnimatedWebP(w, anim, webpPreviewOptions(imgType))
+	}
+	return encoder.EncodeWebP(w, img, webpPreviewOptions(imgType))
+}
+
+func (a *App) writeWebPPreview(rctx request.CTX, img image.Image, anim *imaging.Animation, imgType, previewPath string) {
+	var buf bytes.Buffer
+	if err := encodeWebPPreview(a.ch.imgEncoder, &buf, img, anim, imgType); err != nil {
+		rctx.Logger().Error("Unable to encode image as webp", mlog.String("path", previewPath), mlog.Err(err))
+		return
+	}
+
+	webpPath := webpPreviewPath(previewPath)
+	if _, err := a.WriteFile(&buf, webpPath); err != nil {
+		rctx.Logger().Error("Unable to upload webp image", mlog.String("path", webpPath), mlog.Err(err))
+	}
+}
+
+// removeWebPPreview removes the WebP variant of a thumbnail or preview image, which only
+// exists for the images processed while WebP previews were enabled.
+func (a *App) removeWebPPreview(rctx request.CTX, previewPath string) {
+	webpPath := webpPreviewPath(previewPath)
+	if exists, appErr := a.FileExists(webpPath); appErr != nil || !exists {
+		return
+	}
+	if appErr := a.RemoveFile(webpPath); appErr != nil {
+		rctx.Logger().Warn("Unable to remove file", mlog.String("path", webpPath), mlog.Err(appErr))
+	}
+}
+
+// OpenFilePreviewImage opens a thumbnail or preview image and returns its content type. The
+// WebP variant is preferred when acceptWebP is true and it exists.
+func (a *App) OpenFilePreviewImage(previewPath, contentType string, acceptWebP bool) (filestore.ReadCloseSeeker, string, *model.AppError) {
+	if acceptWebP && *a.Config().FileSettings.EnableWebPPreviews {
+		webpPath := webpPreviewPath(previewPath)
+		if exists, appErr := a.FileExists(webpPath); appErr == nil && exists {
+			fileReader, appErr := a.FileReader(webpPath)
+			if appErr == nil {
+				return fileReader, "image/webp", nil
+			}
+		}
+	}
+
+	fileReader, appErr := a.FileReader(previewPath)
+	if appErr != nil {
+		return nil, "", appErr
+	}
+	return fileReader, contentType, nil
+}
+
+// RegenerateFilePreviewsBatch regenerates the thumbnail and preview images, and their WebP
+// variants when enabled, of the next batch of image files. The position of the job is kept
+// in data. It returns whether all the files were processed and the progress of the job.
+func (a *App) RegenerateFilePreviewsBatch(rctx request.CTX, data model.StringMap) (bool, int64, error) {
+	count := func(key string) int64 {
+		n, _ := strconv.ParseInt(data[key], 10, 64)
+		return n
+	}
+
+	if data["total_files"] == "" {
+		total, err := a.Srv().Store().FileInfo().CountAll()
+		if err != nil {
+			return false, 0, errors.Wrap(err, "failed to count the files")
+		}
+		data["total_files"] = strconv.FormatInt(total, 10)
+	}
+
+	files, err := a.Srv().Store().FileInfo().GetFilesBatchForIndexing(count("start_create_at"), data["start_file_id"], false, filePreviewsRegenerationBatchSize)
+	if err != nil {
+		return false, 0, errors.Wrap(err, "failed to get the files to regenerate the previews of")
+	}
+	if len(files) == 0 {
+		rctx.Logger().Info("Regenerated the file previews",
+			mlog.Int("regenerated_files", count("regenerated_files")),
+			mlog.Int("failed_files", count("failed_files")),
+		)
+		return true, 100, nil
+	}
+
+	regenerated, failed := count("regenerated_files"), count("failed_files")
+	for _, file := range files {
+		if !file.IsImage() || file.IsSvg() || file.ThumbnailPath == "" || file.PreviewPath == "" {
+			continue
+		}
+		if appErr := a.regenerateFilePreviews(rctx, &file.FileInfo); appErr != nil {
+			rctx.Logger().Warn("Failed to regenerate the previews of a file", mlog.String("file_info_id", file.Id), mlog.Err(appErr))
+			failed++
+			continue
+		}
+		regenerated++
+	}
+
+	last := files[len(files)-1]
+	data["start_create_at"] = strconv.FormatInt(last.CreateAt, 10)
+	data["start_file_id"] = last.Id
+	data["processed_files"] = strconv.FormatInt(count("processed_files")+int64(len(files)), 10)
+	data["regenerated_files"] = strconv.FormatInt(regenerated, 10)
+	data["failed_files"] = strconv.FormatInt(failed, 10)
+
+	progress := int64(0)
+	if total := count("total_files"); total > 0 {
+		progress = min(count("processed_files")*100/total, 99)
+	}
+	return false, progress, nil
+}
+
+func (a *App) regenerateFilePreviews(rctx request.CTX, info *model.FileInfo) *model.AppError {
+	file, appErr := a.FileReader(info.Path)
+	if appErr != nil {
+		return appErr
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return model.NewAppError("regenerateFilePreviews", "api.file.read_file.reading_local.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	a.HandleImages(rctx, []string{info.PreviewPath}, []string{info.ThumbnailPath}, [][]byte{data})
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/utils/testutils"
+)
+
+func TestWebPPreviewPath(t *testing.T) {
+	assert.Equal(t, "20240101/teams/t/channels/c/users/u/f/test_thumb.webp", webpPreviewPath("20240101/teams/t/channels/c/users/u/f/test_thumb.jpg"))
+	assert.Equal(t, "a.b/test_preview.webp", webpPreviewPath("a.b/test_preview.png"))
+}
+
+// webpChunkTypes returns the types of the chunks of a WebP file.
+func webpChunkTypes(t *testing.T, data []byte) []string {
+	t.Helper()
+
+	require.Greater(t, len(data), 12)
+	require.Equal(t, "WEBP", string(data[8:12]))
+	var types []string
+	for data = data[12:]; len(data) >= 8; {
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		types = append(types, string(data[:4]))
+		data = data[min(8+size+size%2, len(data)):]
+	}
+	return types
+}
+
+func readFilePreviewImage(t *testing.T, th *TestHelper, path, contentType string, acceptWebP bool) ([]byte, string) {
+	t.Helper()
+
+	fileReader, contentType, appErr := th.App.OpenFilePreviewImage(path, contentType, acceptWebP)
+	require.Nil(t, appErr)
+	defer fileReader.Close()
+	data, err := io.ReadAll(fileReader)
+	require.NoError(t, err)
+	return data, contentType
+}
+
+func TestUploadFileWebPPreviews(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableWebPPreviews = true
+	})
+
+	upload := func(name string, data []byte) *model.FileInfo {
+		info, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, name, bytes.NewReader(data),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()))
+		require.Nil(t, appErr)
+		return info
+	}
+
+	t.Run("still image", func(t *testing.T) {
+		data, err := testutils.ReadTestFile("test.png")
+		require.NoError(t, err)
+		info := upload("test.png", data)
+
+		for _, path := range []string{info.ThumbnailPath, info.PreviewPath} {
+			preview, contentType := readFilePreviewImage(t, th, path, "image/jpeg", true)
+			assert.Equal(t, "image/webp", contentType)
+			assert.Equal(t, []string{"VP8L"}, webpChunkTypes(t, preview))
+
+			_, contentType = readFilePreviewImage(t, th, path, "image/jpeg", false)
+			assert.Equal(t, "image/jpeg", contentType)
+		}
+	})
+
+	t.Run("animated gif", func(t *testing.T) {
+		palette := color.Palette{color.Black, color.White}
+		g := &gif.GIF{}
+		for i := 0; i < 3; i++ {
+			frame := image.NewPaletted(image.Rect(0, 0, 300, 200), palette)
+			for j := range frame.Pix {
+				frame.Pix[j] = uint8((j/50 + i) % 2)
+			}
+			g.Image = append(g.Image, frame)
+			g.Delay = append(g.Delay, 20)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, gif.EncodeAll(&buf, g))
+		info := upload("animated.gif", buf.Bytes())
+
+		for _, path := range []string{info.ThumbnailPath, info.PreviewPath} {
+			preview, contentType := readFilePreviewImage(t, th, path, "image/jpeg", true)
+			assert.Equal(t, "image/webp", contentType)
+			assert.Equal(t, []string{"VP8X", "ANIM", "ANMF", "ANMF", "ANMF"}, webpChunkTypes(t, preview))
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		data, err := testutils.ReadTestFile("test.png")
+		require.NoError(t, err)
+		info := upload("test.png", data)
+
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.EnableWebPPreviews = false
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.EnableWebPPreviews = true
+		})
+
+		_, contentType := readFilePreviewImage(t, th, info.ThumbnailPath, "image/jpeg", true)
+		assert.Equal(t, "image/jpeg", contentType)
+	})
+}
+
+func TestRegenerateFilePreviewsBatch(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	data, err := testutils.ReadTestFile("test.png")
+	require.NoError(t, err)
+	info, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, "test.png", bytes.NewReader(data),
+		UploadFileSetTeamId(th.BasicTeam.Id),
+		UploadFileSetUserId(th.BasicUser.Id),
+		UploadFileSetTimestamp(time.Now()))
+	require.Nil(t, appErr)
+
+	exists, appErr := th.App.FileExists(webpPreviewPath(info.PreviewPath))
+	require.Nil(t, appErr)
+	require.False(t, exists)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableWebPPreviews = true
+	})
+
+	jobData := model.StringMap{}
+	var progress int64
+	for done := false; !done; {
+		done, progress, err = th.App.RegenerateFilePreviewsBatch(th.Context, jobData)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 100, progress)
+
+	for _, path := range []string{info.ThumbnailPath, info.PreviewPath} {
+		exists, appErr := th.App.FileExists(webpPreviewPath(path))
+		require.Nil(t, appErr)
+		assert.True(t, exists)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const fileStorageMigrationBatchSize = 100
+
+// The files are migrated in phases, one for each kind of stored file. Attachments are found
+// through their FileInfo, while the other files are listed from their directory.
+const (
+	fileStorageMigrationPhaseFileInfos = "file_infos"
+	fileStorageMigrationPhaseEmoji     = "emoji"
+	fileStorageMigrationPhaseUsers     = "users"
+	fileStorageMigrationPhaseTeams     = "teams"
+	fileStorageMigrationPhaseBrand     = "brand"
+	fileStorageMigrationPhasePlugins   = "plugins"
+)
+
+var fileStorageMigrationDirectories = []struct {
+	phase     string
+	directory string
+	progress  int64
+}{
+	{fileStorageMigrationPhaseEmoji, "emoji/", 90},
+	// The profile images of the users and the icons of the teams.
+	{fileStorageMigrationPhaseUsers, "users/", 92},
+	{fileStorageMigrationPhaseTeams, "teams/", 95},
+	{fileStorageMigrationPhaseBrand, BrandFilePath, 97},
+	{fileStorageMigrationPhasePlugins, fileStorePluginFolder + "/", 98},
+}
+
+// fileStorageMigrationBackends returns the backends files are migrated from and to. During the
+// cutover, the target backend already receives the new files, which must not be overwritten.
+func (a *App) fileStorageMigrationBackends() (src, dst filestore.FileBackend, overwrite bool, err error) {
+	if dualRead, ok := a.FileBackend().(*filestore.DualReadFileBackend); ok {
+		return dualRead.Fallback(), dualRead.Primary(), false, nil
+	}
+
+	if *a.Config().FileSettings.MigrationDriverName == "" {
+		return nil, nil, false, errors.New("no file storage migration target is configured")
+	}
+
+	license := a.Srv().License()
+	insecure := a.Config().ServiceSettings.EnableInsecureOutgoingConnections
+	dst, err = filestore.NewFileBackend(filestore.NewMigrationFileBackendSettingsFromConfig(&a.Config().FileSettings, license != nil && *license.Features.Compliance, insecure != nil && *insecure))
+	if err != nil {
+		return nil, nil, false, errors.Wrap(err, "failed to initialize the file storage migration target")
+	}
+	return a.FileBackend(), dst, true, nil
+}
+
+// fileStorageMigration tracks a migration in the data of its job, so that it can be resumed.
+type fileStorageMigration struct {
+	rctx      request.CTX
+	data      model.StringMap
+	src       filestore.FileBackend
+	dst       filestore.FileBackend
+	overwrite bool
+	// Whether the thumbnails and previews have WebP variants to migrate.
+	webpPreviews bool
+}
+
+func (m *fileStorageMigration) count(key string) int64 {
+	n, _ := strconv.ParseInt(m.data[key], 10, 64)
+	return n
+}
+
+func (m *fileStorageMigration) add(key string, n int64) {
+	m.data[key] = strconv.FormatInt(m.count(key)+n, 10)
+}
+
+func (m *fileStorageMigration) migrateFile(path string) {
+	result, written, err := filestore.MigrateFile(m.src, m.dst, path, m.overwrite)
+	if err != nil {
+		m.rctx.Logger().Warn("Failed to migrate a file", mlog.String("path", path), mlog.Err(err))
+		m.add("failed_files", 1)
+		return
+	}
+
+	switch result {
+	case filestore.MigrateFileCopied:
+		m.add("copied_files", 1)
+		m.add("copied_bytes", written)
+	case filestore.MigrateFileVerified:
+		m.add("verified_files", 1)
+	case filestore.MigrateFileKept:
+		m.rctx.Logger().Info("Kept a file already updated in the migration target", mlog.String("path", path))
+		m.add("kept_files", 1)
+	case filestore.MigrateFileMissing:
+		m.add("missing_files", 1)
+	}
+}
+
+// MigrateFileStorageBatch copies the next batch of stored files to the file storage migration
+// target, checking the size and checksum of every copy. The position of the migration and its
+// counters are kept in data. It returns whether the migration is done and its progress, and
+// fails once done if some files couldn't be migrated.
+func (a *App) MigrateFileStorageBatch(rctx request.CTX, data model.StringMap) (bool, int64, error) {
+	src, dst, overwrite, err := a.fileStorageMigrationBackends()
+	if err != nil {
+		return false, 0, err
+	}
+
+	m := &fileStorageMigration{
+		rctx:         rctx,
+		data:         data,
+		src:          src,
+		dst:          dst,
+		overwrite:    overwrite,
+		webpPreviews: *a.Config().FileSettings.EnableWebPPreviews,
+	}
+
+	if data["phase"] == "" {
+		total, err := a.Srv().Store().FileInfo().CountAll()
+		if err != nil {
+			return false, 0, errors.Wrap(err, "failed to count the files to migrate")
+		}
+		data["phase"] = fileStorageMigrationPhaseFileInfos
+		data["total_file_infos"] = strconv.FormatInt(total, 10)
+	}
+
+	if data["phase"] == fileStorageMigrationPhaseFileInfos {
+		done, err := a.migrateFileInfosBatch(m)
+		if err != nil || !done {
+			progress := int64(0)
+			if total := m.count("total_file_infos"); total > 0 {
+				progress = min(m.count("migrated_file_infos")*90/total, 89)
+			}
+			return false, progress, err
+		}
+		data["phase"] = fileStorageMigrationDirectories[0].phase
+		return false, fileStorageMigrationDirectories[0].progress, nil
+	}
+
+	for i, dir := range fileStorageMigrationDirectories {
+		if data["phase"] != dir.phase {
+			continue
+		}
+
+		done, err := migrateDirectoryBatch(m, dir.directory)
+		if err != nil || !done {
+			return false, dir.progress, err
+		}
+		delete(data, "start_path")
+
+		if i+1 < len(fileStorageMigrationDirectories) {
+			data["phase"] = fileStorageMigrationDirectories[i+1].phase
+			return false, fileStorageMigrationDirectories[i+1].progress, nil
+		}
+
+		data["phase"] = "done"
+		rctx.Logger().Info("Migrated the file storage",
+			mlog.Int("copied_files", m.count("copied_files")),
+			mlog.Int("verified_files", m.count("verified_files")),
+			mlog.Int("failed_files", m.count("failed_files")),
+		)
+		if failed := m.count("failed_files"); failed > 0 {
+			return true, 100, errors.Errorf("failed to migrate %d files", failed)
+		}
+		return true, 100, nil
+	}
+
+	return false, 0, errors.Errorf("unknown file storage migration phase %q", data["phase"])
+}
+
+func (a *App) migrateFileInfosBatch(m *fileStorageMigration) (bool, error) {
+	startCreateAt := m.count("start_create_at")
+	files, err := a.Srv().Store().FileInfo().GetFilesBatchForIndexing(startCreateAt, m.data["start_file_id"], true, fileStorageMigrationBatchSize)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get the files to migrate")
+	}
+	if len(files) == 0 {
+		return true, nil
+	}
+
+	// Copied and deduplicated FileInfos share their path, which is migrated once per batch.
+	seenPaths := make(map[string]bool, len(files))
+	for _, file := range files {
+		paths := []string{file.Path, file.ThumbnailPath, file.PreviewPath, file.OriginalPath}
+		if m.webpPreviews && file.ThumbnailPath != "" && file.PreviewPath != "" {
+			paths = append(paths, webpPreviewPath(file.ThumbnailPath), webpPreviewPath(file.PreviewPath))
+		}
+		for _, path := range paths {
+			if path == "" || seenPaths[path] {
+				continue
+			}
+			seenPaths[path] = true
+			m.migrateFile(path)
+		}
+	}
+
+	last := files[len(files)-1]
+	m.data["start_create_at"] = strconv.FormatInt(last.CreateAt, 10)
+	m.data["start_file_id"] = last.Id
+	m.add("migrated_file_infos", int64(len(files)))
+	return false, nil
+}
+
+func migrateDirectoryBatch(m *fileStorageMigration, directory string) (bool, error) {
+	// The listing resumes from the last migrated path rather than listing the whole directory
+	// for every batch.
+	paths, err := filestore.ListDirectoryRecursivelyAfter(m.src, directory, m.data["start_path"], fileStorageMigrationBatchSize)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list the files in %s", directory)
+	}
+	if len(paths) == 0 {
+		return true, nil
+	}
+
+	for _, path := range paths {
+		m.migrateFile(path)
+	}
+
+	m.data["start_path"] = paths[len(paths)-1]
+	return false, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestMigrateFileStorageBatch(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	t.Run("no target configured", func(t *testing.T) {
+		_, _, err := th.App.MigrateFileStorageBatch(th.Context, model.StringMap{})
+		require.Error(t, err)
+	})
+
+	targetDir := t.TempDir()
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.MigrationDriverName = model.ImageDriverLocal
+		*cfg.FileSettings.MigrationDirectory = targetDir
+	})
+
+	data := []byte("a file to migrate")
+	info, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, "migrate.txt", bytes.NewReader(data),
+		UploadFileSetTeamId(th.BasicTeam.Id),
+		UploadFileSetUserId(th.BasicUser.Id),
+		UploadFileSetTimestamp(time.Now()),
+		UploadFileSetRaw())
+	require.Nil(t, appErr)
+
+	emojiPath := getEmojiImagePath(model.NewId())
+	_, appErr = th.App.WriteFile(bytes.NewReader([]byte("emoji")), emojiPath)
+	require.Nil(t, appErr)
+
+	profileImagePath := getProfileImagePath(th.BasicUser.Id)
+	_, appErr = th.App.WriteFile(bytes.NewReader([]byte("profile image")), profileImagePath)
+	require.Nil(t, appErr)
+
+	teamIconPath := "teams/" + th.BasicTeam.Id + "/teamIcon.png"
+	_, appErr = th.App.WriteFile(bytes.NewReader([]byte("team icon")), teamIconPath)
+	require.Nil(t, appErr)
+
+	jobData := model.StringMap{}
+	var progress int64
+	for done := false; !done; {
+		var err error
+		// The store may hold file infos left by other tests, whose files are long gone.
+		done, progress, err = th.App.MigrateFileStorageBatch(th.Context, jobData)
+		if done {
+			break
+		}
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 100, progress)
+	assert.Equal(t, "done", jobData["phase"])
+
+	migrated, err := os.ReadFile(filepath.Join(targetDir, info.Path))
+	require.NoError(t, err)
+	assert.Equal(t, data, migrated)
+
+	migrated, err = os.ReadFile(filepath.Join(targetDir, emojiPath))
+	require.NoError(t, err)
+	assert.Equal(t, "emoji", string(migrated))
+
+	migrated, err = os.ReadFile(filepath.Join(targetDir, profileImagePath))
+	require.NoError(t, err)
+	assert.Equal(t, "profile image", string(migrated))
+
+	migrated, err = os.ReadFile(filepath.Join(targetDir, teamIconPath))
+	require.NoError(t, err)
+	assert.Equal(t, "team icon", string(migrated))
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+)
+
+func (a *App) fileStorageQuota(ownerType string) int64 {
+	if ownerType == model.FileStorageUsageOwnerTypeTeam {
+		return *a.Config().FileSettings.TeamStorageQuotaBytes
+	}
+	return *a.Config().FileSettings.UserStorageQuotaBytes
+}
+
+func (a *App) getFileStorageUsage(ownerType, ownerID string) (*model.FileStorageUsage, *model.AppError) {
+	usedBytes, err := a.Srv().Store().FileInfo().GetFileStorageUsage(ownerType, ownerID)
+	if err != nil {
+		return nil, model.NewAppError("GetFileStorageUsage", "app.file_info.get_file_storage_usage.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return &model.FileStorageUsage{
+		OwnerId:    ownerID,
+		OwnerType:  ownerType,
+		UsedBytes:  usedBytes,
+		QuotaBytes: a.fileStorageQuota(ownerType),
+	}, nil
+}
+
+// GetUserFileStorageUsage returns the storage used by the files uploaded by the user.
+func (a *App) GetUserFileStorageUsage(userID string) (*model.FileStorageUsage, *model.AppError) {
+	return a.getFileStorageUsage(model.FileStorageUsageOwnerTypeUser, userID)
+}
+
+// GetTeamFileStorageUsage returns the storage used by the files uploaded into the channels of the team.
+func (a *App) GetTeamFileStorageUsage(teamID string) (*model.FileStorageUsage, *model.AppError) {
+	return a.getFileStorageUsage(model.FileStorageUsageOwnerTypeTeam, teamID)
+}
+
+// GetFileStorageUsagePage returns the users or teams using the most file storage.
+func (a *App) GetFileStorageUsagePage(ownerType string, page, perPage int) ([]*model.FileStorageUsage, *model.AppError) {
+	usages, err := a.Srv().Store().FileInfo().GetFileStorageUsagePage(ownerType, page*perPage, perPage)
+	if err != nil {
+		return nil, model.NewAppError("GetFileStorageUsagePage", "app.file_info.get_file_storage_usage.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	quota := a.fileStorageQuota(ownerType)
+	for _, usage := range usages {
+		usage.QuotaBytes = quota
+	}
+	return usages, nil
+}
+
+// CheckFileStorageQuota returns an error when storing a file of the given size would take its
+// uploader or the team of the channel it's uploaded to over their storage quota.
+func (a *App) CheckFileStorageQuota(rctx request.CTX, userID, channelID string, size int64) *model.AppError {
+	userQuota := *a.Config().FileSettings.UserStorageQuotaBytes
+	teamQuota := *a.Config().FileSettings.TeamStorageQuotaBytes
+
+	if userQuota > 0 && userID != "" {
+		usage, appErr := a.GetUserFileStorageUsage(userID)
+		if appErr != nil {
+			return appErr
+		}
+		if usage.IsOverQuota(size) {
+			return model.NewAppError("CheckFileStorageQuota", "app.file.storage_quota.user_exceeded.app_error",
+				map[string]any{"Used": usage.UsedBytes, "Quota": usage.QuotaBytes}, "", http.StatusRequestEntityTooLarge)
+		}
+	}
+
+	if teamQuota > 0 && channelID != "" {
+		channel, appErr := a.GetChannel(rctx, channelID)
+		if appErr != nil {
+			return appErr
+		}
+		if channel.TeamId == "" {
+			return nil
+		}
+
+		usage, appErr := a.GetTeamFileStorageUsage(channel.TeamId)
+		if appErr != nil {
+			return appErr
+		}
+		if usage.IsOverQuota(size) {
+			return model.NewAppError("CheckFileStorageQuota", "app.file.storage_quota.team_exceeded.app_error",
+				map[string]any{"Used": usage.UsedBytes, "Quota": usage.QuotaBytes}, "", http.StatusRequestEntityTooLarge)
+		}
+	}
+
+	return nil
+}
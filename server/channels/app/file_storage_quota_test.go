@@ -0,0 +1,100 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestCheckFileStorageQuota(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	upload := func(channelID string, size int) *model.AppError {
+		t.Helper()
+
+		_, appErr := th.App.UploadFileX(th.Context, channelID, "quota.bin", bytes.NewReader(make([]byte, size)),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()),
+			UploadFileSetRaw())
+		return appErr
+	}
+
+	require.Nil(t, upload(th.BasicChannel.Id, 600))
+
+	t.Run("unlimited by default", func(t *testing.T) {
+		require.Nil(t, th.App.CheckFileStorageQuota(th.Context, th.BasicUser.Id, th.BasicChannel.Id, 1<<40))
+	})
+
+	t.Run("user quota", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.UserStorageQuotaBytes = 1000
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.UserStorageQuotaBytes = 0
+		})
+
+		require.Nil(t, th.App.CheckFileStorageQuota(th.Context, th.BasicUser.Id, th.BasicChannel.Id, 400))
+
+		appErr := th.App.CheckFileStorageQuota(th.Context, th.BasicUser.Id, th.BasicChannel.Id, 401)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.storage_quota.user_exceeded.app_error", appErr.Id)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, appErr.StatusCode)
+
+		require.Nil(t, th.App.CheckFileStorageQuota(th.Context, th.BasicUser2.Id, th.BasicChannel.Id, 1000), "other users have their own quota")
+
+		appErr = upload(th.BasicChannel.Id, 600)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.storage_quota.user_exceeded.app_error", appErr.Id)
+
+		_, appErr = th.App.CreateUploadSession(th.Context, &model.UploadSession{
+			Id:        model.NewId(),
+			Type:      model.UploadTypeAttachment,
+			UserId:    th.BasicUser.Id,
+			ChannelId: th.BasicChannel.Id,
+			Filename:  "quota.bin",
+			FileSize:  600,
+		})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.storage_quota.user_exceeded.app_error", appErr.Id)
+	})
+
+	t.Run("team quota", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.TeamStorageQuotaBytes = 1000
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.TeamStorageQuotaBytes = 0
+		})
+
+		appErr := th.App.CheckFileStorageQuota(th.Context, th.BasicUser2.Id, th.BasicChannel.Id, 401)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.storage_quota.team_exceeded.app_error", appErr.Id)
+
+		dm := th.CreateDmChannel(th.BasicUser2)
+		require.Nil(t, th.App.CheckFileStorageQuota(th.Context, th.BasicUser.Id, dm.Id, 1000), "direct messages don't count for any team")
+	})
+
+	t.Run("permanently deleted files are released", func(t *testing.T) {
+		usage, appErr := th.App.GetUserFileStorageUsage(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.EqualValues(t, 600, usage.UsedBytes)
+
+		_, err := th.App.Srv().Store().FileInfo().PermanentDeleteByUser(th.Context, th.BasicUser.Id)
+		require.NoError(t, err)
+
+		usage, appErr = th.App.GetUserFileStorageUsage(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.Zero(t, usage.UsedBytes)
+	})
+}
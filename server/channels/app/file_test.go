@@ -453,7 +453,7 @@ func TestGenerateThumbnailImage(t *testing.T) {
 		thumbnailPath := filepath.Join(dataPath, thumbnailName)
 
 		// when
-		th.App.generateThumbnailImage(th.Context, img, "jpg", thumbnailName)
+		th.App.generateThumbnailImage(th.Context, img, nil, "jpg", thumbnailName)
 		defer os.Remove(thumbnailPath)
 
 		// then
@@ -0,0 +1,295 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/bep/imagemeta"
+)
+
+// HasMetadata reads the input data and tells whether the image holds any EXIF,
+// IPTC or XMP metadata, besides the EXIF orientation which only tells how the
+// image is displayed. Supported formats are JPEG, PNG, TIFF, and WebP.
+func HasMetadata(input io.Reader, format string) (bool, error) {
+	imgFormat, err := metadataImageFormat(format)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	opts := imagemeta.Options{
+		R: toReadSeeker(input),
+		HandleTag: func(ti imagemeta.TagInfo) error {
+			if ti.Source == imagemeta.EXIF && ti.Tag == "Orientation" {
+				return nil
+			}
+			found = true
+			// A single tag is enough.
+			return errStopDecoding
+		},
+		ShouldHandleTag: func(imagemeta.TagInfo) bool {
+			return true
+		},
+		HandleXMP: func(io.Reader) error {
+			found = true
+			return errStopDecoding
+		},
+		Sources:     imagemeta.EXIF | imagemeta.IPTC | imagemeta.XMP,
+		ImageFormat: imgFormat,
+	}
+
+	if err := imagemeta.Decode(opts); err != nil && !errors.Is(err, errStopDecoding) {
+		return false, fmt.Errorf("failed to decode image metadata: %w", err)
+	}
+
+	return found, nil
+}
+
+const (
+	jpegMarkerTEM   = 0x01
+	jpegMarkerRST0  = 0xd0
+	jpegMarkerRST7  = 0xd7
+	jpegMarkerSOI   = 0xd8
+	jpegMarkerEOI   = 0xd9
+	jpegMarkerSOS   = 0xda
+	jpegMarkerAPP1  = 0xe1
+	jpegMarkerAPP2  = 0xe2
+	jpegMarkerAPP13 = 0xed
+)
+
+var errInvalidJPEG = errors.New("imaging: invalid jpeg image")
+
+// StripJPEGMetadata removes the EXIF and XMP (APP1) and the IPTC (APP13)
+// segments of a JPEG image without decoding it, along with the pictures
+// following the image in multi-picture files. The other segments, such as the
+// ICC color profile, are kept. Since the pixels aren't rotated, an orientation
+// other than upright is kept in a minimal EXIF segment.
+func StripJPEGMetadata(data []byte, orientation int) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != jpegMarkerSOI {
+		return nil, errInvalidJPEG
+	}
+
+	keepOrientation := orientation > Upright && orientation <= RotatedCW
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	for pos := 2; ; {
+		if pos+2 > len(data) || data[pos] != 0xff {
+			return nil, errInvalidJPEG
+		}
+
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff:
+			// Markers may be preceded by fill bytes.
+			pos++
+			continue
+		case marker == jpegMarkerEOI:
+			// Anything past the end of the image, such as the other pictures of
+			// multi-picture files, is left out.
+			return append(out, data[pos:pos+2]...), nil
+		case marker == jpegMarkerTEM || (marker >= jpegMarkerRST0 && marker <= jpegMarkerRST7):
+			out = append(out, data[pos:pos+2]...)
+			pos += 2
+			continue
+		}
+
+		if pos+4 > len(data) {
+			return nil, errInvalidJPEG
+		}
+		end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+		if end < pos+4 || end > len(data) {
+			return nil, errInvalidJPEG
+		}
+
+		segment := data[pos:end]
+		if isJPEGMetadataSegment(marker, segment[4:]) {
+			if keepOrientation {
+				out = append(out, exifOrientationSegment(orientation)...)
+				keepOrientation = false
+			}
+		} else {
+			out = append(out, segment...)
+		}
+		pos = end
+
+		if marker == jpegMarkerSOS {
+			scanEnd := jpegScanEnd(data, pos)
+			out = append(out, data[pos:scanEnd]...)
+			if scanEnd == len(data) {
+				// The end of the image is missing, which decoders tolerate.
+				return out, nil
+			}
+			pos = scanEnd
+		}
+	}
+}
+
+// isJPEGMetadataSegment tells whether a segment holds EXIF, XMP or IPTC
+// metadata, or describes the pictures following the image (MPF).
+func isJPEGMetadataSegment(marker byte, payload []byte) bool {
+	switch marker {
+	case jpegMarkerAPP1, jpegMarkerAPP13:
+		return true
+	case jpegMarkerAPP2:
+		return bytes.HasPrefix(payload, []byte("MPF\x00"))
+	}
+	return false
+}
+
+// jpegScanEnd returns the position of the marker following the entropy-coded
+// data starting at pos, where 0xff bytes are either stuffed with a zero byte or
+// restart markers.
+func jpegScanEnd(data []byte, pos int) int {
+	for ; pos+1 < len(data); pos++ {
+		if data[pos] != 0xff {
+			continue
+		}
+		next := data[pos+1]
+		if next != 0 && (next < jpegMarkerRST0 || next > jpegMarkerRST7) {
+			return pos
+		}
+	}
+	return len(data)
+}
+
+// exifOrientationSegment returns an APP1 segment only holding the orientation
+// of the image.
+func exifOrientationSegment(orientation int) []byte {
+	exif := append([]byte("Exif\x00\x00"), exifOrientation(orientation)...)
+
+	segment := []byte{0xff, jpegMarkerAPP1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exif)))
+	return append(segment, exif...)
+}
+
+// exifOrientation returns EXIF data only holding the orientation of the image:
+// a big-endian TIFF header followed by an IFD with a single SHORT entry for the
+// orientation tag, and no next IFD.
+func exifOrientation(orientation int) []byte {
+	exif := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	exif = append(exif, 0x00, 0x01, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	exif = append(exif, 0x00, byte(orientation), 0x00, 0x00)
+	exif = append(exif, 0x00, 0x00, 0x00, 0x00)
+	return exif
+}
+
+var errInvalidWebP = errors.New("imaging: invalid webp image")
+
+// StripWebPMetadata removes the EXIF and XMP chunks of a WebP image without
+// decoding it, so that it also works for the animated images, which can't be
+// decoded. The other chunks, such as the ICC color profile, are kept. As for
+// JPEG images, an orientation other than upright is kept in a minimal EXIF
+// chunk.
+func StripWebPMetadata(data []byte, orientation int) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errInvalidWebP
+	}
+
+	// Anything past the end of the RIFF container is left out.
+	riffEnd := min(8+int(binary.LittleEndian.Uint32(data[4:])), len(data))
+	var chunks []byte
+	vp8xFlags := -1
+	for rest := data[12:riffEnd]; len(rest) > 0; {
+		if len(rest) < 8 {
+			return nil, errInvalidWebP
+		}
+		fourCC := string(rest[:4])
+		size := int(binary.LittleEndian.Uint32(rest[4:]))
+		if size > len(rest)-8 {
+			return nil, errInvalidWebP
+		}
+		payload := rest[8 : 8+size]
+		rest = rest[min(8+size+size%2, len(rest)):]
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			continue
+		case "VP8X":
+			if size < 10 {
+				return nil, errInvalidWebP
+			}
+			vp8xFlags = len(chunks) + 8
+		}
+		chunks = appendChunk(chunks, fourCC, payload)
+	}
+
+	// The metadata chunks can only be found in the extended format, which
+	// starts with a VP8X chunk telling which ones are present.
+	if vp8xFlags >= 0 {
+		chunks[vp8xFlags] &^= webpFlagEXIF | webpFlagXMP
+		if orientation > Upright && orientation <= RotatedCW {
+			chunks[vp8xFlags] |= webpFlagEXIF
+			chunks = appendChunk(chunks, "EXIF", exifOrientation(orientation))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeRIFF(&buf, chunks); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	errInvalidPNG = errors.New("imaging: invalid png image")
+
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// StripPNGMetadata removes the EXIF (eXIf), text (tEXt, zTXt and iTXt, which
+// also hold the XMP and the legacy EXIF and IPTC profiles) and modification
+// time (tIME) chunks of a PNG image without decoding it, so that it also works
+// for the images that can't be decoded. The other chunks, such as the ICC color
+// profile or the frames of animated images, are kept. As for JPEG images, an
+// orientation other than upright is kept in a minimal EXIF chunk.
+func StripPNGMetadata(data []byte, orientation int) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errInvalidPNG
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	for pos := len(pngSignature); ; {
+		if pos+12 > len(data) {
+			return nil, errInvalidPNG
+		}
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		if size > len(data)-pos-12 {
+			return nil, errInvalidPNG
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		chunk := data[pos : pos+12+size]
+		pos += 12 + size
+
+		switch chunkType {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+			continue
+		}
+		out = append(out, chunk...)
+
+		switch chunkType {
+		case "IHDR":
+			if orientation > Upright && orientation <= RotatedCW {
+				out = appendPNGChunk(out, "eXIf", exifOrientation(orientation))
+			}
+		case "IEND":
+			// Anything past the end of the image is left out.
+			return out, nil
+		}
+	}
+}
+
+func appendPNGChunk(buf []byte, chunkType string, data []byte) []byte {
+	start := len(buf)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, chunkType...)
+	buf = append(buf, data...)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf[start+4:]))
+}
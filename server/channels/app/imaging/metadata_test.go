@@ -0,0 +1,250 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/v8/channels/utils/fileutils"
+)
+
+func TestHasMetadata(t *testing.T) {
+	imgDir, ok := fileutils.FindDir("tests/exif_samples")
+	require.True(t, ok)
+
+	for name, format := range map[string]string{
+		"left.jpg":  "jpeg",
+		"left.png":  "png",
+		"left.webp": "image/webp",
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(imgDir, name))
+			require.NoError(t, err)
+
+			found, err := HasMetadata(bytes.NewReader(data), format)
+			require.NoError(t, err)
+			assert.True(t, found)
+		})
+	}
+
+	t.Run("no metadata", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		found, err := HasMetadata(bytes.NewReader(buf.Bytes()), "png")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		buf.Reset()
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+		found, err = HasMetadata(bytes.NewReader(buf.Bytes()), "jpeg")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		e, err := NewEncoder(EncoderOptions{})
+		require.NoError(t, err)
+		buf.Reset()
+		require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Lossless: true}))
+		found, err = HasMetadata(bytes.NewReader(buf.Bytes()), "webp")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := HasMetadata(bytes.NewReader(nil), "gif")
+		require.Error(t, err)
+	})
+}
+
+func TestStripJPEGMetadata(t *testing.T) {
+	imgDir, ok := fileutils.FindDir("tests/exif_samples")
+	require.True(t, ok)
+
+	decode := func(t *testing.T, data []byte) image.Image {
+		t.Helper()
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		return img
+	}
+
+	for name, orientation := range map[string]int{
+		"up.jpg":            Upright,
+		"left.jpg":          RotatedCCW,
+		"down-mirrored.jpg": UpsideDownMirrored,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(imgDir, name))
+			require.NoError(t, err)
+
+			stripped, err := StripJPEGMetadata(data, orientation)
+			require.NoError(t, err)
+			assert.Less(t, len(stripped), len(data))
+
+			found, err := HasMetadata(bytes.NewReader(stripped), "jpeg")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			got, err := GetImageOrientation(bytes.NewReader(stripped), "jpeg")
+			require.NoError(t, err)
+			assert.Equal(t, orientation, got)
+
+			// The image isn't re-encoded.
+			assert.Equal(t, decode(t, data), decode(t, stripped))
+		})
+	}
+
+	t.Run("color profile and trailing pictures", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 16)), nil))
+		encoded := buf.Bytes()
+
+		segment := func(marker byte, payload string) []byte {
+			return append([]byte{0xff, marker, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+		}
+		icc := segment(jpegMarkerAPP2, "ICC_PROFILE\x00\x01\x01profile")
+		var data []byte
+		data = append(data, encoded[:2]...)
+		data = append(data, segment(jpegMarkerAPP1, "Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00")...)
+		data = append(data, icc...)
+		data = append(data, segment(jpegMarkerAPP2, "MPF\x00MM")...)
+		data = append(data, segment(jpegMarkerAPP13, "Photoshop 3.0\x00")...)
+		data = append(data, encoded[2:]...)
+		data = append(data, encoded...)
+
+		stripped, err := StripJPEGMetadata(data, Upright)
+		require.NoError(t, err)
+
+		want := append(append(append([]byte{}, encoded[:2]...), icc...), encoded[2:]...)
+		assert.Equal(t, want, stripped)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := StripJPEGMetadata([]byte("not a jpeg"), Upright)
+		require.Error(t, err)
+
+		_, err = StripJPEGMetadata([]byte{0xff, jpegMarkerSOI, 0xff, jpegMarkerAPP1, 0xff}, Upright)
+		require.Error(t, err)
+	})
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	imgDir, ok := fileutils.FindDir("tests/exif_samples")
+	require.True(t, ok)
+
+	t.Run("still image", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(imgDir, "left.webp"))
+		require.NoError(t, err)
+
+		stripped, err := StripWebPMetadata(data, RotatedCCW)
+		require.NoError(t, err)
+
+		found, err := HasMetadata(bytes.NewReader(stripped), "webp")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		orientation, err := GetImageOrientation(bytes.NewReader(stripped), "webp")
+		require.NoError(t, err)
+		assert.Equal(t, RotatedCCW, orientation)
+	})
+
+	t.Run("animated image", func(t *testing.T) {
+		e, err := NewEncoder(EncoderOptions{})
+		require.NoError(t, err)
+		frame := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeAnimatedWebP(&buf, &Animation{
+			Frames:    []image.Image{frame, frame},
+			Durations: []int{100, 100},
+		}, WebPOptions{Lossless: true}))
+		encoded := bytes.Clone(buf.Bytes())
+
+		// The metadata chunks follow the frames, and are announced by the VP8X chunk.
+		chunks := append([]byte{}, encoded[12:]...)
+		chunks[8] |= webpFlagEXIF | webpFlagXMP
+		chunks = appendChunk(chunks, "EXIF", []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x00"))
+		chunks = appendChunk(chunks, "XMP ", []byte("<x:xmpmeta xmlns:x='adobe:ns:meta/'/>"))
+		buf.Reset()
+		require.NoError(t, writeRIFF(&buf, chunks))
+		data := buf.Bytes()
+		require.True(t, IsAnimatedWebP(data))
+
+		stripped, err := StripWebPMetadata(data, Upright)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, stripped)
+		assert.True(t, IsAnimatedWebP(stripped))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := StripWebPMetadata([]byte("not a webp"), Upright)
+		require.Error(t, err)
+
+		_, err = StripWebPMetadata([]byte("RIFF\x10\x00\x00\x00WEBPVP8X\xff\x00\x00\x00"), Upright)
+		require.Error(t, err)
+	})
+}
+
+func TestStripPNGMetadata(t *testing.T) {
+	imgDir, ok := fileutils.FindDir("tests/exif_samples")
+	require.True(t, ok)
+
+	data, err := os.ReadFile(filepath.Join(imgDir, "left.png"))
+	require.NoError(t, err)
+	orientation, err := GetImageOrientation(bytes.NewReader(data), "png")
+	require.NoError(t, err)
+	require.NotEqual(t, Upright, orientation)
+
+	t.Run("still image", func(t *testing.T) {
+		stripped, err := StripPNGMetadata(data, orientation)
+		require.NoError(t, err)
+
+		found, err := HasMetadata(bytes.NewReader(stripped), "png")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		strippedOrientation, err := GetImageOrientation(bytes.NewReader(stripped), "png")
+		require.NoError(t, err)
+		assert.Equal(t, orientation, strippedOrientation)
+
+		original, err := png.Decode(bytes.NewReader(data))
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(stripped))
+		require.NoError(t, err)
+		assert.Equal(t, original, img)
+	})
+
+	t.Run("image that can't be decoded", func(t *testing.T) {
+		// Corrupting the first byte of the image data keeps the chunks intact.
+		corrupted := bytes.Clone(data)
+		idat := bytes.Index(corrupted, []byte("IDAT"))
+		require.Positive(t, idat)
+		corrupted[idat+4] ^= 0xff
+		_, err := png.Decode(bytes.NewReader(corrupted))
+		require.Error(t, err)
+
+		stripped, err := StripPNGMetadata(corrupted, Upright)
+		require.NoError(t, err)
+
+		found, err := HasMetadata(bytes.NewReader(stripped), "png")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := StripPNGMetadata([]byte("not a png"), Upright)
+		require.Error(t, err)
+
+		_, err = StripPNGMetadata(data[:len(data)-4], Upright)
+		require.Error(t, err)
+	})
+}
@@ -98,38 +98,47 @@ func (f *fwSeeker) Seek(offset int64, whence int) (int64, error) {
 	return f.pos, nil
 }
 
-// GetImageOrientation reads the input data and returns the EXIF encoded
-// image orientation. Supported formats are JPEG, PNG, TIFF, and WebP.
-// Passing an io.ReadSeeker is preferable as we can't guarantee a plain
-// io.Reader will work for all formats (e.g. TIFF requires backwards seeking).
-func GetImageOrientation(input io.Reader, format string) (int, error) {
-	orientation := Upright
-
+// metadataImageFormat returns the imagemeta format of the given image format or MIME type.
+func metadataImageFormat(format string) (imagemeta.ImageFormat, error) {
 	// Strip the "image/" prefix from the format in case it's a MIME type.
 	format, _ = strings.CutPrefix(format, "image/")
 
-	var imgFormat imagemeta.ImageFormat
 	switch format {
 	case "jpeg":
-		imgFormat = imagemeta.JPEG
+		return imagemeta.JPEG, nil
 	case "png":
-		imgFormat = imagemeta.PNG
+		return imagemeta.PNG, nil
 	case "tiff":
-		imgFormat = imagemeta.TIFF
+		return imagemeta.TIFF, nil
 	case "webp":
-		imgFormat = imagemeta.WebP
+		return imagemeta.WebP, nil
 	default:
 		// We don't support EXIF on any other format.
-		return orientation, fmt.Errorf("unsupported image format: %s", format)
+		return 0, fmt.Errorf("unsupported image format: %s", format)
 	}
+}
 
-	var rs io.ReadSeeker
+func toReadSeeker(input io.Reader) io.ReadSeeker {
 	if r, ok := input.(io.ReadSeeker); ok {
-		rs = r
-	} else {
-		rs = &fwSeeker{r: input}
+		return r
+	}
+	return &fwSeeker{r: input}
+}
+
+// GetImageOrientation reads the input data and returns the EXIF encoded
+// image orientation. Supported formats are JPEG, PNG, TIFF, and WebP.
+// Passing an io.ReadSeeker is preferable as we can't guarantee a plain
+// io.Reader will work for all formats (e.g. TIFF requires backwards seeking).
+func GetImageOrientation(input io.Reader, format string) (int, error) {
+	orientation := Upright
+
+	imgFormat, err := metadataImageFormat(format)
+	if err != nil {
+		return orientation, err
 	}
 
+	rs := toReadSeeker(input)
+
 	opts := imagemeta.Options{
 		R: rs,
 		HandleTag: func(tag imagemeta.TagInfo) error {
@@ -0,0 +1,311 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// The WebP encoders are implemented here, since golang.org/x/image/webp only
+// decodes and there is no maintained pure Go encoder of the lossy format. The
+// libwebp bindings require cgo, which would prevent cross-compiling the server,
+// and calling cwebp would add an external dependency to every installation.
+// Both encoders are checked against the decoder by the fuzz tests.
+
+// The maximum dimension of a WebP image.
+const webpMaxDimension = 1 << 14
+
+const (
+	webpFlagAnimation = 0x02
+	webpFlagXMP       = 0x04
+	webpFlagEXIF      = 0x08
+	webpFlagAlpha     = 0x10
+
+	// webpAlphaLossless is the ALPH chunk header for VP8L compressed alpha
+	// values, without filtering or preprocessing.
+	webpAlphaLossless = 0x01
+
+	// webpFrameNoBlend is the ANMF frame flag disabling alpha blending with the
+	// previous canvas.
+	webpFrameNoBlend = 0x02
+)
+
+// WebPOptions holds the options of the WebP encoder.
+type WebPOptions struct {
+	// Lossless selects the lossless VP8L format. It suits images with few
+	// colors and sharp edges, such as screenshots and GIFs.
+	Lossless bool
+	// Quality is the quality of lossy images, between 0 and 100.
+	Quality int
+}
+
+// Animation holds the frames of an animated image. Frames are fully
+// composited and all have the same size.
+type Animation struct {
+	Frames []image.Image
+	// Durations are the display times of the frames, in milliseconds.
+	Durations []int
+	// LoopCount is the number of times the animation is played, or 0 to
+	// loop forever.
+	LoopCount int
+}
+
+// EncodeWebP encodes the given image in WebP format and writes the data to
+// the passed writer.
+func (e *Encoder) EncodeWebP(wr io.Writer, img image.Image, opts WebPOptions) error {
+	if e.opts.ConcurrencyLevel > 0 {
+		e.sem <- struct{}{}
+		defer func() {
+			<-e.sem
+		}()
+	}
+
+	if err := checkWebPBounds(img.Bounds()); err != nil {
+		return err
+	}
+
+	var chunks []byte
+	if opts.Lossless {
+		chunks = appendChunk(nil, "VP8L", encodeVP8L(img))
+	} else if hasAlpha(img) {
+		b := img.Bounds()
+		chunks = appendChunk(nil, "VP8X", webpHeader(webpFlagAlpha, b.Dx(), b.Dy()))
+		chunks = appendChunk(chunks, "ALPH", append([]byte{webpAlphaLossless}, encodeVP8LAlpha(img)...))
+		chunks = appendChunk(chunks, "VP8 ", encodeVP8(img, opts.Quality))
+	} else {
+		chunks = appendChunk(nil, "VP8 ", encodeVP8(img, opts.Quality))
+	}
+
+	if err := writeRIFF(wr, chunks); err != nil {
+		return fmt.Errorf("imaging: failed to encode webp: %w", err)
+	}
+	return nil
+}
+
+// EncodeAnimatedWebP encodes the given animation in WebP format and writes the
+// data to the passed writer.
+func (e *Encoder) EncodeAnimatedWebP(wr io.Writer, anim *Animation, opts WebPOptions) error {
+	if e.opts.ConcurrencyLevel > 0 {
+		e.sem <- struct{}{}
+		defer func() {
+			<-e.sem
+		}()
+	}
+
+	if len(anim.Frames) == 0 || len(anim.Frames) != len(anim.Durations) {
+		return errors.New("imaging: invalid animation")
+	}
+	b := anim.Frames[0].Bounds()
+	if err := checkWebPBounds(b); err != nil {
+		return err
+	}
+
+	flags := byte(webpFlagAnimation)
+	var frames []byte
+	for i, frame := range anim.Frames {
+		if frame.Bounds().Size() != b.Size() {
+			return errors.New("imaging: animation frames must have the same size")
+		}
+
+		var data []byte
+		if opts.Lossless {
+			data = appendChunk(nil, "VP8L", encodeVP8L(frame))
+		} else {
+			if hasAlpha(frame) {
+				data = appendChunk(nil, "ALPH", append([]byte{webpAlphaLossless}, encodeVP8LAlpha(frame)...))
+			}
+			data = appendChunk(data, "VP8 ", encodeVP8(frame, opts.Quality))
+		}
+		if hasAlpha(frame) {
+			flags |= webpFlagAlpha
+		}
+
+		header := make([]byte, 16, 16+len(data))
+		putUint24(header[6:], uint32(b.Dx()-1))
+		putUint24(header[9:], uint32(b.Dy()-1))
+		putUint24(header[12:], uint32(min(max(anim.Durations[i], 0), 1<<24-1)))
+		header[15] = webpFrameNoBlend
+		frames = appendChunk(frames, "ANMF", append(header, data...))
+	}
+
+	// The ANIM chunk holds a transparent background color and the loop count.
+	animHeader := make([]byte, 6)
+	binary.LittleEndian.PutUint16(animHeader[4:], uint16(min(max(anim.LoopCount, 0), 1<<16-1)))
+	chunks := appendChunk(nil, "VP8X", webpHeader(flags, b.Dx(), b.Dy()))
+	chunks = appendChunk(chunks, "ANIM", animHeader)
+	chunks = append(chunks, frames...)
+
+	if err := writeRIFF(wr, chunks); err != nil {
+		return fmt.Errorf("imaging: failed to encode webp: %w", err)
+	}
+	return nil
+}
+
+// IsLosslessWebP tells whether data holds a still lossless WebP image.
+func IsLosslessWebP(data []byte) bool {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return false
+	}
+	for data = data[12:]; len(data) >= 8; {
+		switch string(data[:4]) {
+		case "VP8L":
+			return true
+		case "VP8 ", "ANIM":
+			return false
+		}
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		if size > len(data)-8 {
+			return false
+		}
+		data = data[min(8+size+size%2, len(data)):]
+	}
+	return false
+}
+
+// IsAnimatedWebP tells whether data holds an animated WebP image.
+func IsAnimatedWebP(data []byte) bool {
+	if len(data) < 21 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" || string(data[12:16]) != "VP8X" {
+		return false
+	}
+	return data[20]&webpFlagAnimation != 0
+}
+
+func checkWebPBounds(b image.Rectangle) error {
+	if b.Empty() || b.Dx() > webpMaxDimension || b.Dy() > webpMaxDimension {
+		return fmt.Errorf("imaging: invalid webp dimensions %dx%d", b.Dx(), b.Dy())
+	}
+	return nil
+}
+
+// webpHeader returns the payload of a VP8X chunk.
+func webpHeader(flags byte, width, height int) []byte {
+	header := make([]byte, 10)
+	header[0] = flags
+	putUint24(header[4:], uint32(width-1))
+	putUint24(header[7:], uint32(height-1))
+	return header
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// appendChunk appends a RIFF chunk to buf, padded to an even size.
+func appendChunk(buf []byte, fourCC string, data []byte) []byte {
+	buf = append(buf, fourCC...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	if len(data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func writeRIFF(wr io.Writer, chunks []byte) error {
+	header := make([]byte, 12)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+len(chunks)))
+	copy(header[8:], "WEBP")
+	if _, err := wr.Write(header); err != nil {
+		return err
+	}
+	_, err := wr.Write(chunks)
+	return err
+}
+
+// toNRGBA returns img as an NRGBA image whose bounds start at the origin.
+func toNRGBA(img image.Image) *image.NRGBA {
+	if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Rect.Min == (image.Point{}) {
+		return nrgba
+	}
+	b := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, b.Min, draw.Src)
+	return nrgba
+}
+
+func hasAlpha(img image.Image) bool {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok {
+		return !opaque.Opaque()
+	}
+	return true
+}
+
+// gifDefaultFrameDelay is the delay browsers use for GIF frames that don't
+// specify a meaningful one, in milliseconds.
+const gifDefaultFrameDelay = 100
+
+// DecodeGIFAnimation decodes all the frames of a GIF image and composites
+// them. It fails when the decoded frames would take more than maxPixels pixels.
+func DecodeGIFAnimation(rd io.Reader, maxPixels int64) (*Animation, error) {
+	g, err := gif.DecodeAll(rd)
+	if err != nil {
+		return nil, fmt.Errorf("imaging: failed to decode gif: %w", err)
+	}
+
+	canvasRect := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if canvasRect.Empty() && len(g.Image) > 0 {
+		canvasRect = g.Image[0].Bounds()
+	}
+	if int64(canvasRect.Dx())*int64(canvasRect.Dy())*int64(len(g.Image)) > maxPixels {
+		return nil, errors.New("imaging: gif animation is too large")
+	}
+
+	anim := &Animation{}
+	switch {
+	case g.LoopCount == 0:
+		anim.LoopCount = 0
+	case g.LoopCount < 0:
+		anim.LoopCount = 1
+	default:
+		anim.LoopCount = g.LoopCount + 1
+	}
+
+	canvas := image.NewNRGBA(canvasRect)
+	for i, frame := range g.Image {
+		var previous *image.NRGBA
+		if g.Disposal[i] == gif.DisposalPrevious {
+			previous = image.NewNRGBA(canvasRect)
+			copy(previous.Pix, canvas.Pix)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		composited := image.NewNRGBA(canvasRect)
+		copy(composited.Pix, canvas.Pix)
+		anim.Frames = append(anim.Frames, composited)
+
+		delay := g.Delay[i] * 10
+		if delay <= 10 {
+			delay = gifDefaultFrameDelay
+		}
+		anim.Durations = append(anim.Durations, delay)
+
+		switch g.Disposal[i] {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return anim, nil
+}
+
+// ResizeAnimation resizes every frame of anim with resize.
+func ResizeAnimation(anim *Animation, resize func(image.Image) image.Image) *Animation {
+	resized := &Animation{
+		Frames:    make([]image.Image, len(anim.Frames)),
+		Durations: anim.Durations,
+		LoopCount: anim.LoopCount,
+	}
+	for i, frame := range anim.Frames {
+		resized.Frames[i] = resize(frame)
+	}
+	return resized
+}
@@ -0,0 +1,803 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"image"
+	"sort"
+)
+
+// This file implements a VP8L (lossless WebP) encoder. The bitstream uses the
+// color indexing transform, or the subtract-green and predictor transforms,
+// followed by LZ77 backward references and canonical prefix codes, as
+// described in the WebP lossless bitstream specification.
+
+const (
+	vp8lMagic = 0x2f
+
+	vp8lTransformPredictor     = 0
+	vp8lTransformSubtractGreen = 2
+	vp8lTransformColorIndexing = 3
+
+	// vp8lPredictorBits is the log-2 size of the tiles sharing a predictor mode.
+	vp8lPredictorBits = 4
+	vp8lNumPredictors = 14
+
+	vp8lNumLiteralCodes  = 256
+	vp8lNumLengthCodes   = 24
+	vp8lNumDistanceCodes = 40
+	vp8lNumCodeLengths   = 19
+
+	vp8lMaxCodeLength       = 15
+	vp8lMaxCodeLengthLength = 7
+
+	vp8lMinMatchLength = 3
+	vp8lMaxMatchLength = 4096
+	vp8lMaxDistance    = 1<<20 - 120
+	vp8lHashBits       = 16
+	vp8lMaxChainLength = 32
+)
+
+// vp8lCodeLengthOrder is the order in which the code length code lengths are written.
+var vp8lCodeLengthOrder = [vp8lNumCodeLengths]int{
+	17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+}
+
+// vp8lDistanceMap is the table mapping short distance codes to two-dimensional
+// pixel offsets, where the high nibble is the row offset and the low nibble is
+// 8 minus the column offset.
+var vp8lDistanceMap = [120]uint8{
+	0x18, 0x07, 0x17, 0x19, 0x28, 0x06, 0x27, 0x29, 0x16, 0x1a,
+	0x26, 0x2a, 0x38, 0x05, 0x37, 0x39, 0x15, 0x1b, 0x36, 0x3a,
+	0x25, 0x2b, 0x48, 0x04, 0x47, 0x49, 0x14, 0x1c, 0x35, 0x3b,
+	0x46, 0x4a, 0x24, 0x2c, 0x58, 0x45, 0x4b, 0x34, 0x3c, 0x03,
+	0x57, 0x59, 0x13, 0x1d, 0x56, 0x5a, 0x23, 0x2d, 0x44, 0x4c,
+	0x55, 0x5b, 0x33, 0x3d, 0x68, 0x02, 0x67, 0x69, 0x12, 0x1e,
+	0x66, 0x6a, 0x22, 0x2e, 0x54, 0x5c, 0x43, 0x4d, 0x65, 0x6b,
+	0x32, 0x3e, 0x78, 0x01, 0x77, 0x79, 0x53, 0x5d, 0x11, 0x1f,
+	0x64, 0x6c, 0x42, 0x4e, 0x76, 0x7a, 0x21, 0x2f, 0x75, 0x7b,
+	0x31, 0x3f, 0x63, 0x6d, 0x52, 0x5e, 0x00, 0x74, 0x7c, 0x41,
+	0x4f, 0x10, 0x20, 0x62, 0x6e, 0x30, 0x73, 0x7d, 0x51, 0x5f,
+	0x40, 0x72, 0x7e, 0x61, 0x6f, 0x50, 0x71, 0x7f, 0x60, 0x70,
+}
+
+// vp8lDistanceCodes is the inverse of vp8lDistanceMap: it maps a pixel offset
+// to its short distance code, or zero when it has none.
+var vp8lDistanceCodes = func() [256]uint8 {
+	var codes [256]uint8
+	for i, offset := range vp8lDistanceMap {
+		codes[offset] = uint8(i + 1)
+	}
+	return codes
+}()
+
+// vp8lBitWriter writes bits least significant bit first.
+type vp8lBitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits uint
+}
+
+func (w *vp8lBitWriter) writeBits(v uint32, n uint) {
+	w.bits |= uint64(v) << w.nBits
+	w.nBits += n
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *vp8lBitWriter) bytes() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nBits = 0, 0
+	}
+	return w.buf
+}
+
+// encodeVP8L encodes img as a VP8L bitstream, including its header.
+func encodeVP8L(img image.Image) []byte {
+	b := img.Bounds()
+	argb, hasAlpha := toARGB(img)
+
+	var w vp8lBitWriter
+	w.writeBits(vp8lMagic, 8)
+	w.writeBits(uint32(b.Dx()-1), 14)
+	w.writeBits(uint32(b.Dy()-1), 14)
+	if hasAlpha {
+		w.writeBits(1, 1)
+	} else {
+		w.writeBits(0, 1)
+	}
+	w.writeBits(0, 3) // Version.
+	return append(w.bytes(), encodeVP8LImage(argb, b.Dx(), b.Dy(), true)...)
+}
+
+// encodeVP8LAlpha encodes the alpha channel of img as the headerless VP8L
+// bitstream of an ALPH chunk, which stores the alpha values in the green channel.
+func encodeVP8LAlpha(img image.Image) []byte {
+	b := img.Bounds()
+	argb, _ := toARGB(img)
+	for i, p := range argb {
+		argb[i] = 0xff000000 | (p>>24)<<8
+	}
+	return encodeVP8LImage(argb, b.Dx(), b.Dy(), false)
+}
+
+// toARGB converts img to non-premultiplied ARGB pixels, and reports whether
+// some of them aren't fully opaque.
+func toARGB(img image.Image) ([]uint32, bool) {
+	nrgba := toNRGBA(img)
+	b := nrgba.Bounds()
+	argb := make([]uint32, 0, b.Dx()*b.Dy())
+	hasAlpha := false
+	for y := 0; y < b.Dy(); y++ {
+		row := nrgba.Pix[y*nrgba.Stride : y*nrgba.Stride+4*b.Dx()]
+		for i := 0; i < len(row); i += 4 {
+			if row[i+3] != 0xff {
+				hasAlpha = true
+			}
+			argb = append(argb, uint32(row[i+3])<<24|uint32(row[i])<<16|uint32(row[i+1])<<8|uint32(row[i+2]))
+		}
+	}
+	return argb, hasAlpha
+}
+
+// encodeVP8LImage encodes the transforms and the entropy-coded pixels of the
+// top-level image. Images with at most 256 colors are also encoded with the
+// color indexing transform, and the smallest result is kept.
+func encodeVP8LImage(argb []uint32, width, height int, subtractGreen bool) []byte {
+	var indexed []byte
+	if palette := vp8lPalette(argb); palette != nil {
+		var w vp8lBitWriter
+		writeVP8LIndexedImage(&w, argb, palette, width, height)
+		indexed = w.bytes()
+	}
+
+	var w vp8lBitWriter
+	writeVP8LPredictedImage(&w, argb, width, height, subtractGreen)
+	if predicted := w.bytes(); indexed == nil || len(predicted) < len(indexed) {
+		return predicted
+	}
+	return indexed
+}
+
+// vp8lPalette returns the sorted colors of the image, or nil when it has more than 256.
+func vp8lPalette(argb []uint32) []uint32 {
+	colors := make(map[uint32]struct{}, 256)
+	for _, p := range argb {
+		if _, ok := colors[p]; !ok {
+			if len(colors) == 256 {
+				return nil
+			}
+			colors[p] = struct{}{}
+		}
+	}
+	palette := make([]uint32, 0, len(colors))
+	for c := range colors {
+		palette = append(palette, c)
+	}
+	sort.Slice(palette, func(i, j int) bool { return palette[i] < palette[j] })
+	return palette
+}
+
+// writeVP8LIndexedImage writes the image with the color indexing transform,
+// packing several indices per pixel when the palette is small enough.
+func writeVP8LIndexedImage(w *vp8lBitWriter, argb, palette []uint32, width, height int) {
+	w.writeBits(1, 1)
+	w.writeBits(vp8lTransformColorIndexing, 2)
+	w.writeBits(uint32(len(palette)-1), 8)
+	deltas := make([]uint32, len(palette))
+	deltas[0] = palette[0]
+	for i := 1; i < len(palette); i++ {
+		deltas[i] = vp8lSubPixels(palette[i], palette[i-1])
+	}
+	writeVP8LEntropyImage(w, deltas, len(palette), false)
+
+	var bits uint
+	switch {
+	case len(palette) <= 2:
+		bits = 3
+	case len(palette) <= 4:
+		bits = 2
+	case len(palette) <= 16:
+		bits = 1
+	}
+	indices := make(map[uint32]uint32, len(palette))
+	for i, c := range palette {
+		indices[c] = uint32(i)
+	}
+	packedWidth := (width + 1<<bits - 1) >> bits
+	packed := make([]uint32, packedWidth*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			shift := uint(x&(1<<bits-1)) * (8 >> bits)
+			packed[y*packedWidth+x>>bits] |= indices[argb[y*width+x]] << shift
+		}
+	}
+	for i, p := range packed {
+		packed[i] = 0xff000000 | p<<8
+	}
+
+	w.writeBits(0, 1) // No more transforms.
+	writeVP8LEntropyImage(w, packed, packedWidth, true)
+}
+
+// writeVP8LPredictedImage writes the image with the subtract-green and
+// predictor transforms. It overwrites argb with the residuals.
+func writeVP8LPredictedImage(w *vp8lBitWriter, argb []uint32, width, height int, subtractGreen bool) {
+	if subtractGreen {
+		w.writeBits(1, 1)
+		w.writeBits(vp8lTransformSubtractGreen, 2)
+		for i, p := range argb {
+			green := (p >> 8) & 0xff
+			argb[i] = p&0xff00ff00 | ((p>>16-green)&0xff)<<16 | (p-green)&0xff
+		}
+	}
+
+	if width > 1 || height > 1 {
+		w.writeBits(1, 1)
+		w.writeBits(vp8lTransformPredictor, 2)
+		w.writeBits(vp8lPredictorBits-2, 3)
+		modes, tilesX := vp8lPredict(argb, width, height)
+		writeVP8LEntropyImage(w, modes, tilesX, false)
+	}
+
+	w.writeBits(0, 1) // No more transforms.
+	writeVP8LEntropyImage(w, argb, width, true)
+}
+
+// vp8lPredict replaces argb with the residuals of the best predictor of each
+// tile, and returns the sub-image holding the predictor modes along with its width.
+func vp8lPredict(argb []uint32, width, height int) ([]uint32, int) {
+	tilesX := (width + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+	tilesY := (height + 1<<vp8lPredictorBits - 1) >> vp8lPredictorBits
+	modes := make([]uint32, tilesX*tilesY)
+	residuals := make([]uint32, len(argb))
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, y0 := tx<<vp8lPredictorBits, ty<<vp8lPredictorBits
+			x1, y1 := min(x0+1<<vp8lPredictorBits, width), min(y0+1<<vp8lPredictorBits, height)
+
+			bestMode, bestCost := 0, -1
+			for mode := 0; mode < vp8lNumPredictors; mode++ {
+				cost := 0
+				for y := y0; y < y1 && (bestCost < 0 || cost < bestCost); y++ {
+					for x := x0; x < x1; x++ {
+						cost += vp8lResidualCost(vp8lSubPixels(argb[y*width+x], vp8lPrediction(argb, width, x, y, mode)))
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					bestMode, bestCost = mode, cost
+				}
+			}
+
+			modes[ty*tilesX+tx] = 0xff000000 | uint32(bestMode)<<8
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					i := y*width + x
+					residuals[i] = vp8lSubPixels(argb[i], vp8lPrediction(argb, width, x, y, bestMode))
+				}
+			}
+		}
+	}
+	copy(argb, residuals)
+	return modes, tilesX
+}
+
+// vp8lResidualCost estimates the cost of coding the per-channel difference d.
+func vp8lResidualCost(d uint32) int {
+	cost := 0
+	for shift := 0; shift < 32; shift += 8 {
+		c := int(int8(d >> shift))
+		if c < 0 {
+			c = -c
+		}
+		cost += c
+	}
+	return cost
+}
+
+// vp8lPrediction returns the prediction of the pixel at x, y with the given
+// mode. The first row and column always use fixed predictors.
+func vp8lPrediction(argb []uint32, width, x, y, mode int) uint32 {
+	i := y*width + x
+	switch {
+	case y == 0 && x == 0:
+		return 0xff000000
+	case y == 0:
+		return argb[i-1]
+	case x == 0:
+		return argb[i-width]
+	}
+
+	l, t, tl, tr := argb[i-1], argb[i-width], argb[i-width-1], argb[i-width+1]
+	switch mode {
+	case 0:
+		return 0xff000000
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 5:
+		return vp8lAverage2(vp8lAverage2(l, tr), t)
+	case 6:
+		return vp8lAverage2(l, tl)
+	case 7:
+		return vp8lAverage2(l, t)
+	case 8:
+		return vp8lAverage2(tl, t)
+	case 9:
+		return vp8lAverage2(t, tr)
+	case 10:
+		return vp8lAverage2(vp8lAverage2(l, tl), vp8lAverage2(t, tr))
+	case 11:
+		return vp8lSelect(l, t, tl)
+	case 12:
+		return vp8lMapChannels(func(a, b, c int32) int32 { return a + b - c }, l, t, tl)
+	default:
+		return vp8lMapChannels(func(a, b, _ int32) int32 { return a + (a-b)/2 }, vp8lAverage2(l, t), tl, 0)
+	}
+}
+
+func vp8lAverage2(a, b uint32) uint32 {
+	return vp8lMapChannels(func(a, b, _ int32) int32 { return (a + b) / 2 }, a, b, 0)
+}
+
+// vp8lMapChannels applies f to each channel of a, b and c, and clamps the results.
+func vp8lMapChannels(f func(a, b, c int32) int32, a, b, c uint32) uint32 {
+	var out uint32
+	for shift := 0; shift < 32; shift += 8 {
+		v := f(int32(a>>shift&0xff), int32(b>>shift&0xff), int32(c>>shift&0xff))
+		out |= uint32(min(max(v, 0), 255)) << shift
+	}
+	return out
+}
+
+func vp8lSelect(l, t, tl uint32) uint32 {
+	var distL, distT int32
+	for shift := 0; shift < 32; shift += 8 {
+		c := int32(tl >> shift & 0xff)
+		distL += abs32(c - int32(t>>shift&0xff))
+		distT += abs32(c - int32(l>>shift&0xff))
+	}
+	if distL < distT {
+		return l
+	}
+	return t
+}
+
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// vp8lSubPixels subtracts b from a channel by channel, modulo 256.
+func vp8lSubPixels(a, b uint32) uint32 {
+	alphaGreen := 0x00ff00ff + (a & 0xff00ff00) - (b & 0xff00ff00)
+	redBlue := 0xff00ff00 + (a & 0x00ff00ff) - (b & 0x00ff00ff)
+	return alphaGreen&0xff00ff00 | redBlue&0x00ff00ff
+}
+
+// vp8lSymbol is a literal pixel, or a backward reference when length isn't zero.
+type vp8lSymbol struct {
+	argb     uint32
+	length   int
+	distCode int
+}
+
+// vp8lPrefixCode returns the prefix code, the number of extra bits and the
+// extra bits value coding v in a length or distance alphabet.
+func vp8lPrefixCode(v int) (int, uint, uint32) {
+	x := v - 1
+	if x < 4 {
+		return x, 0, 0
+	}
+	highBit := 0
+	for x>>(highBit+1) != 0 {
+		highBit++
+	}
+	second := (x >> (highBit - 1)) & 1
+	extraBits := uint(highBit - 1)
+	return 2*highBit + second, extraBits, uint32(x & (1<<extraBits - 1))
+}
+
+// vp8lDistanceCode returns the distance code of a backward reference, using
+// the short codes for the pixels close to the current one.
+func vp8lDistanceCode(width, dist int) int {
+	y, x := dist/width, dist%width
+	var offset int
+	switch {
+	case x <= 8 && y < 8:
+		offset = y<<4 | (8 - x)
+	case x > width-8 && y < 7:
+		offset = (y+1)<<4 | (8 + width - x)
+	default:
+		return dist + 120
+	}
+	if code := vp8lDistanceCodes[offset]; code != 0 {
+		return int(code)
+	}
+	return dist + 120
+}
+
+// vp8lBackwardReferences turns the pixels into literals and LZ77 backward
+// references, found through hash chains over pixel pairs.
+func vp8lBackwardReferences(argb []uint32, width int) []vp8lSymbol {
+	n := len(argb)
+	head := make([]int32, 1<<vp8lHashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	chain := make([]int32, n)
+	hash := func(i int) uint32 {
+		h := argb[i]*0x9e3779b1 ^ argb[i+1]*0x85ebca6b
+		return h >> (32 - vp8lHashBits)
+	}
+	insert := func(i int) {
+		if i+1 < n {
+			h := hash(i)
+			chain[i] = head[h]
+			head[h] = int32(i)
+		}
+	}
+	matchLength := func(i, j, maxLength int) int {
+		l := 0
+		for l < maxLength && argb[i+l] == argb[j+l] {
+			l++
+		}
+		return l
+	}
+
+	symbols := make([]vp8lSymbol, 0, n/2)
+	for i := 0; i < n; {
+		maxLength := min(vp8lMaxMatchLength, n-i)
+		bestLength, bestDist := 0, 0
+		if maxLength >= vp8lMinMatchLength {
+			// The previous pixel and the one above have short distance codes.
+			for _, dist := range []int{1, width} {
+				if dist <= i {
+					if l := matchLength(i, i-dist, maxLength); l > bestLength {
+						bestLength, bestDist = l, dist
+					}
+				}
+			}
+			if i+1 < n {
+				for j, steps := head[hash(i)], 0; j >= 0 && steps < vp8lMaxChainLength && bestLength < maxLength; j, steps = chain[j], steps+1 {
+					dist := i - int(j)
+					if dist > vp8lMaxDistance {
+						break
+					}
+					if l := matchLength(i, int(j), maxLength); l > bestLength {
+						bestLength, bestDist = l, dist
+					}
+				}
+			}
+		}
+
+		if bestLength >= vp8lMinMatchLength {
+			symbols = append(symbols, vp8lSymbol{length: bestLength, distCode: vp8lDistanceCode(width, bestDist)})
+			for k := 0; k < bestLength; k++ {
+				insert(i + k)
+			}
+			i += bestLength
+			continue
+		}
+		symbols = append(symbols, vp8lSymbol{argb: argb[i]})
+		insert(i)
+		i++
+	}
+	return symbols
+}
+
+// writeVP8LEntropyImage writes the entropy-coded pixels of an image, using a
+// single group of prefix codes and no color cache.
+func writeVP8LEntropyImage(w *vp8lBitWriter, argb []uint32, width int, topLevel bool) {
+	symbols := vp8lBackwardReferences(argb, width)
+
+	green := make([]uint32, vp8lNumLiteralCodes+vp8lNumLengthCodes)
+	red := make([]uint32, vp8lNumLiteralCodes)
+	blue := make([]uint32, vp8lNumLiteralCodes)
+	alpha := make([]uint32, vp8lNumLiteralCodes)
+	dist := make([]uint32, vp8lNumDistanceCodes)
+	for _, s := range symbols {
+		if s.length == 0 {
+			green[s.argb>>8&0xff]++
+			red[s.argb>>16&0xff]++
+			blue[s.argb&0xff]++
+			alpha[s.argb>>24]++
+			continue
+		}
+		lengthCode, _, _ := vp8lPrefixCode(s.length)
+		green[vp8lNumLiteralCodes+lengthCode]++
+		distCode, _, _ := vp8lPrefixCode(s.distCode)
+		dist[distCode]++
+	}
+
+	w.writeBits(0, 1) // No color cache.
+	if topLevel {
+		w.writeBits(0, 1) // No meta prefix codes.
+	}
+	codes := [5]*vp8lPrefixCodeTable{}
+	for i, histogram := range [][]uint32{green, red, blue, alpha, dist} {
+		codes[i] = newVP8LPrefixCodeTable(histogram, vp8lMaxCodeLength)
+		codes[i].write(w)
+	}
+
+	for _, s := range symbols {
+		if s.length == 0 {
+			codes[0].writeSymbol(w, int(s.argb>>8&0xff))
+			codes[1].writeSymbol(w, int(s.argb>>16&0xff))
+			codes[2].writeSymbol(w, int(s.argb&0xff))
+			codes[3].writeSymbol(w, int(s.argb>>24))
+			continue
+		}
+		code, extraBits, extra := vp8lPrefixCode(s.length)
+		codes[0].writeSymbol(w, vp8lNumLiteralCodes+code)
+		w.writeBits(extra, extraBits)
+		code, extraBits, extra = vp8lPrefixCode(s.distCode)
+		codes[4].writeSymbol(w, code)
+		w.writeBits(extra, extraBits)
+	}
+}
+
+// vp8lPrefixCodeTable is a canonical prefix code. The codes are stored bit
+// reversed, ready to be written least significant bit first.
+type vp8lPrefixCodeTable struct {
+	lengths []uint8
+	codes   []uint32
+	// numSymbols is the number of used symbols, and symbols holds them when
+	// there are at most two.
+	numSymbols int
+	symbols    []int
+}
+
+func newVP8LPrefixCodeTable(histogram []uint32, maxLength int) *vp8lPrefixCodeTable {
+	t := &vp8lPrefixCodeTable{
+		lengths: buildCodeLengths(histogram, maxLength),
+		codes:   make([]uint32, len(histogram)),
+	}
+	for symbol, count := range histogram {
+		if count > 0 {
+			t.numSymbols++
+			if t.numSymbols <= 2 {
+				t.symbols = append(t.symbols, symbol)
+			}
+		}
+	}
+	if t.numSymbols <= 1 {
+		// A code with a single symbol uses no bits at all.
+		for i := range t.lengths {
+			t.lengths[i] = 0
+		}
+		return t
+	}
+
+	var count [vp8lMaxCodeLength + 1]uint32
+	for _, l := range t.lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [vp8lMaxCodeLength + 1]uint32
+	code := uint32(0)
+	for l := 1; l <= vp8lMaxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	for symbol, l := range t.lengths {
+		if l > 0 {
+			t.codes[symbol] = reverseBits(next[l], uint(l))
+			next[l]++
+		}
+	}
+	return t
+}
+
+func reverseBits(v uint32, n uint) uint32 {
+	var r uint32
+	for i := uint(0); i < n; i++ {
+		r = r<<1 | (v>>i)&1
+	}
+	return r
+}
+
+func (t *vp8lPrefixCodeTable) writeSymbol(w *vp8lBitWriter, symbol int) {
+	w.writeBits(t.codes[symbol], uint(t.lengths[symbol]))
+}
+
+// write writes the code, as a simple code when it has at most two symbols
+// that fit in 8 bits, or as code lengths otherwise.
+func (t *vp8lPrefixCodeTable) write(w *vp8lBitWriter) {
+	simple := t.numSymbols <= 2
+	for _, s := range t.symbols {
+		if s >= 256 {
+			simple = false
+		}
+	}
+	if simple {
+		symbols := t.symbols
+		if len(symbols) == 0 {
+			symbols = []int{0}
+		}
+		w.writeBits(1, 1)
+		w.writeBits(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			w.writeBits(0, 1)
+			w.writeBits(uint32(symbols[0]), 1)
+		} else {
+			w.writeBits(1, 1)
+			w.writeBits(uint32(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			w.writeBits(uint32(symbols[1]), 8)
+		}
+		return
+	}
+
+	lengths := t.lengths
+	if t.numSymbols == 1 {
+		// A single symbol is coded with a length of one, and then uses no bits.
+		lengths = make([]uint8, len(t.lengths))
+		lengths[t.symbols[0]] = 1
+	}
+
+	tokens := vp8lCodeLengthTokens(lengths)
+	histogram := make([]uint32, vp8lNumCodeLengths)
+	for _, token := range tokens {
+		histogram[token.code]++
+	}
+	lengthCode := newVP8LPrefixCodeTable(histogram, vp8lMaxCodeLengthLength)
+	codeLengthLengths := lengthCode.lengths
+	if lengthCode.numSymbols == 1 {
+		codeLengthLengths = make([]uint8, vp8lNumCodeLengths)
+		codeLengthLengths[lengthCode.symbols[0]] = 1
+	}
+
+	numCodes := 4
+	for i := vp8lNumCodeLengths - 1; i >= 4; i-- {
+		if codeLengthLengths[vp8lCodeLengthOrder[i]] != 0 {
+			numCodes = i + 1
+			break
+		}
+	}
+	w.writeBits(0, 1)
+	w.writeBits(uint32(numCodes-4), 4)
+	for i := 0; i < numCodes; i++ {
+		w.writeBits(uint32(codeLengthLengths[vp8lCodeLengthOrder[i]]), 3)
+	}
+	w.writeBits(0, 1) // The code lengths cover the whole alphabet.
+	for _, token := range tokens {
+		lengthCode.writeSymbol(w, token.code)
+		w.writeBits(token.extra, token.extraBits)
+	}
+}
+
+type vp8lCodeLengthToken struct {
+	code      int
+	extraBits uint
+	extra     uint32
+}
+
+// vp8lCodeLengthTokens run-length codes the code lengths: 16 repeats the
+// previous non-zero length 3 to 6 times, 17 and 18 repeat zeros 3 to 10 and
+// 11 to 138 times.
+func vp8lCodeLengthTokens(lengths []uint8) []vp8lCodeLengthToken {
+	var tokens []vp8lCodeLengthToken
+	prev := uint8(8)
+	for i := 0; i < len(lengths); {
+		l := lengths[i]
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == l {
+			run++
+		}
+		i += run
+
+		if l == 0 {
+			for run >= 3 {
+				if run >= 11 {
+					n := min(run, 138)
+					tokens = append(tokens, vp8lCodeLengthToken{18, 7, uint32(n - 11)})
+					run -= n
+				} else {
+					n := min(run, 10)
+					tokens = append(tokens, vp8lCodeLengthToken{17, 3, uint32(n - 3)})
+					run -= n
+				}
+			}
+			for ; run > 0; run-- {
+				tokens = append(tokens, vp8lCodeLengthToken{code: 0})
+			}
+			continue
+		}
+
+		if l != prev {
+			tokens = append(tokens, vp8lCodeLengthToken{code: int(l)})
+			prev = l
+			run--
+		}
+		for run >= 3 {
+			n := min(run, 6)
+			tokens = append(tokens, vp8lCodeLengthToken{16, 2, uint32(n - 3)})
+			run -= n
+		}
+		for ; run > 0; run-- {
+			tokens = append(tokens, vp8lCodeLengthToken{code: int(l)})
+		}
+	}
+	return tokens
+}
+
+// buildCodeLengths returns the Huffman code lengths of the histogram, limited
+// to maxLength bits by flattening the smallest counts until the tree fits.
+func buildCodeLengths(histogram []uint32, maxLength int) []uint8 {
+	lengths := make([]uint8, len(histogram))
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+	switch len(used) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[used[0]] = 1
+		return lengths
+	}
+
+	type node struct {
+		weight      uint64
+		symbol      int
+		left, right int
+	}
+	for minWeight := uint64(1); ; minWeight *= 2 {
+		nodes := make([]node, 0, 2*len(used))
+		for _, symbol := range used {
+			nodes = append(nodes, node{weight: max(uint64(histogram[symbol]), minWeight), symbol: symbol, left: -1, right: -1})
+		}
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].weight < nodes[j].weight })
+
+		// Merge the two lightest nodes using two queues: the sorted leaves,
+		// and the internal nodes which are created in increasing weight order.
+		leaf, internal := 0, len(nodes)
+		pop := func() int {
+			if leaf < len(used) && (internal >= len(nodes) || nodes[leaf].weight <= nodes[internal].weight) {
+				leaf++
+				return leaf - 1
+			}
+			internal++
+			return internal - 1
+		}
+		for i := 0; i < len(used)-1; i++ {
+			a, b := pop(), pop()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, symbol: -1, left: a, right: b})
+		}
+
+		depths := make([]int, len(nodes))
+		tooLong := false
+		for i := len(nodes) - 1; i >= 0; i-- {
+			n := nodes[i]
+			if n.symbol >= 0 {
+				if depths[i] > maxLength {
+					tooLong = true
+				}
+				lengths[n.symbol] = uint8(depths[i])
+				continue
+			}
+			depths[n.left] = depths[i] + 1
+			depths[n.right] = depths[i] + 1
+		}
+		if !tooLong {
+			return lengths
+		}
+	}
+}
@@ -0,0 +1,660 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"image"
+)
+
+// This file implements a VP8 (lossy WebP) key frame encoder, as specified in
+// RFC 6386. Every macroblock is predicted as a whole with the best of the DC,
+// vertical, horizontal and TrueMotion modes, and its residuals go through the
+// DCT and WHT transforms before being quantized and arithmetic coded.
+
+const (
+	vp8NumPlanes   = 4
+	vp8NumBands    = 8
+	vp8NumContexts = 3
+	vp8NumProbs    = 11
+
+	vp8PlaneYWithY2 = 0
+	vp8PlaneY2      = 1
+	vp8PlaneUV      = 2
+
+	vp8PredDC = 0
+	vp8PredTM = 1
+	vp8PredVE = 2
+	vp8PredHE = 3
+
+	vp8MaxLevel = 2047
+)
+
+var (
+	vp8Bands  = [17]uint8{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+	vp8Zigzag = [16]uint8{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+	vp8Cat3 = []uint8{173, 148, 140}
+	vp8Cat4 = []uint8{176, 155, 140, 135}
+	vp8Cat5 = []uint8{180, 157, 141, 134, 130}
+	vp8Cat6 = []uint8{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129}
+)
+
+// The dequantization tables are specified in section 14.1.
+var (
+	vp8DCTable = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 10, 11, 12, 13, 14, 15, 16, 17, 17,
+		18, 19, 20, 20, 21, 21, 22, 22, 23, 23, 24, 25, 25, 26, 27, 28,
+		29, 30, 31, 32, 33, 34, 35, 36, 37, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58,
+		59, 60, 61, 62, 63, 64, 65, 66, 67, 68, 69, 70, 71, 72, 73, 74,
+		75, 76, 76, 77, 78, 79, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89,
+		91, 93, 95, 96, 98, 100, 101, 102, 104, 106, 108, 110, 112, 114, 116, 118,
+		122, 124, 126, 128, 130, 132, 134, 136, 138, 140, 143, 145, 148, 151, 154, 157,
+	}
+	vp8ACTable = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19,
+		20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35,
+		36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
+		52, 53, 54, 55, 56, 57, 58, 60, 62, 64, 66, 68, 70, 72, 74, 76,
+		78, 80, 82, 84, 86, 88, 90, 92, 94, 96, 98, 100, 102, 104, 106, 108,
+		110, 112, 114, 116, 119, 122, 125, 128, 131, 134, 137, 140, 143, 146, 149, 152,
+		155, 158, 161, 164, 167, 170, 173, 177, 181, 185, 189, 193, 197, 201, 205, 209,
+		213, 217, 221, 225, 229, 234, 239, 245, 249, 254, 259, 264, 269, 274, 279, 284,
+	}
+)
+
+// vp8Matrix holds the DC and AC quantizer steps of a kind of block.
+type vp8Matrix [2]int32
+
+func (m vp8Matrix) step(i int) int32 {
+	if i == 0 {
+		return m[0]
+	}
+	return m[1]
+}
+
+// vp8BoolEncoder is the boolean entropy encoder specified in section 7.3.
+type vp8BoolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newVP8BoolEncoder() *vp8BoolEncoder {
+	return &vp8BoolEncoder{rng: 255, bitCount: 24}
+}
+
+func (e *vp8BoolEncoder) addOne() {
+	i := len(e.buf) - 1
+	for i >= 0 && e.buf[i] == 255 {
+		e.buf[i] = 0
+		i--
+	}
+	if i >= 0 {
+		e.buf[i]++
+	}
+}
+
+func (e *vp8BoolEncoder) putBit(bit bool, prob uint8) bool {
+	split := 1 + ((e.rng-1)*uint32(prob))>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.addOne()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.bitCount = 8
+		}
+	}
+	return bit
+}
+
+// putLiteral writes the n low bits of v, most significant first.
+func (e *vp8BoolEncoder) putLiteral(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(v>>n&1 != 0, 128)
+	}
+}
+
+func (e *vp8BoolEncoder) bytes() []byte {
+	c := e.bitCount
+	v := e.bottom
+	if v&(1<<(32-c)) != 0 {
+		e.addOne()
+	}
+	v <<= c & 7
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		e.buf = append(e.buf, byte(v>>24))
+		v <<= 8
+	}
+	return e.buf
+}
+
+// vp8Context holds the non-zero flags of the blocks along a macroblock edge:
+// 4 luma, 2 + 2 chroma and the Y2 block.
+type vp8Context struct {
+	y    [4]uint8
+	u, v [2]uint8
+	y2   uint8
+}
+
+type vp8Encoder struct {
+	mbw, mbh int
+	// The source and the reconstructed planes, padded to whole macroblocks.
+	y, u, v    []uint8
+	ry, ru, rv []uint8
+	yStride    int
+	uvStride   int
+
+	y1, y2, uv vp8Matrix
+	// qi is the quantizer index.
+	qi int
+
+	header *vp8BoolEncoder
+	tokens *vp8BoolEncoder
+	top    []vp8Context
+	left   vp8Context
+}
+
+// vp8QualityToIndex maps a quality between 0 and 100 to a quantizer index.
+func vp8QualityToIndex(quality int) int {
+	quality = min(max(quality, 0), 100)
+	return (100 - quality) * 127 / 100
+}
+
+// encodeVP8 encodes img as a VP8 key frame with the given quality, ignoring its alpha channel.
+func encodeVP8(img image.Image, quality int) []byte {
+	src := toNRGBA(img)
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+
+	e := &vp8Encoder{
+		mbw: (width + 15) >> 4,
+		mbh: (height + 15) >> 4,
+		qi:  vp8QualityToIndex(quality),
+	}
+	e.yStride, e.uvStride = 16*e.mbw, 8*e.mbw
+	e.y, e.u, e.v = rgbToYUV420(src, e.mbw, e.mbh)
+	e.ry = make([]uint8, len(e.y))
+	e.ru = make([]uint8, len(e.u))
+	e.rv = make([]uint8, len(e.v))
+
+	e.y1 = vp8Matrix{vp8DCTable[e.qi], vp8ACTable[e.qi]}
+	e.y2 = vp8Matrix{vp8DCTable[e.qi] * 2, max(vp8ACTable[e.qi]*155/100, 8)}
+	e.uv = vp8Matrix{vp8DCTable[min(e.qi, 117)], vp8ACTable[e.qi]}
+
+	e.header = newVP8BoolEncoder()
+	e.tokens = newVP8BoolEncoder()
+	e.top = make([]vp8Context, e.mbw)
+	e.writeHeader()
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = vp8Context{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	firstPartition := e.header.bytes()
+	tokens := e.tokens.bytes()
+	out := make([]byte, 0, 10+len(firstPartition)+len(tokens))
+	tag := uint32(len(firstPartition))<<5 | 1<<4 // Key frame, version 0, shown.
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(width), byte(width>>8), byte(height), byte(height>>8))
+	out = append(out, firstPartition...)
+	return append(out, tokens...)
+}
+
+// rgbToYUV420 converts src to BT.601 limited range Y'CbCr planes with 2x2
+// subsampled chroma, padded to whole macroblocks by repeating the edges.
+func rgbToYUV420(src *image.NRGBA, mbw, mbh int) (y, u, v []uint8) {
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+	yStride, uvStride := 16*mbw, 8*mbw
+	y = make([]uint8, yStride*16*mbh)
+	u = make([]uint8, uvStride*8*mbh)
+	v = make([]uint8, uvStride*8*mbh)
+
+	rgb := func(px, py int) (int32, int32, int32) {
+		i := min(py, height-1)*src.Stride + 4*min(px, width-1)
+		return int32(src.Pix[i]), int32(src.Pix[i+1]), int32(src.Pix[i+2])
+	}
+	for py := 0; py < 16*mbh; py++ {
+		for px := 0; px < yStride; px++ {
+			r, g, b := rgb(px, py)
+			y[py*yStride+px] = uint8((16839*r + 33059*g + 6420*b + 1<<15 + 16<<16) >> 16)
+		}
+	}
+	for py := 0; py < 8*mbh; py++ {
+		for px := 0; px < uvStride; px++ {
+			var r, g, b int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgb(2*px+d[0], 2*py+d[1])
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			u[py*uvStride+px] = clipUV(-9719*r - 19081*g + 28800*b)
+			v[py*uvStride+px] = clipUV(28800*r - 24116*g - 4684*b)
+		}
+	}
+	return y, u, v
+}
+
+// clipUV scales down a chroma value computed from the sum of 4 pixels.
+func clipUV(c int32) uint8 {
+	return clip8((c + 1<<17 + 128<<18) >> 18)
+}
+
+func clip8(v int32) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+func (e *vp8Encoder) writeHeader() {
+	h := e.header
+	h.putBit(false, 128) // Color space.
+	h.putBit(false, 128) // Clamping type.
+	h.putBit(false, 128) // No segmentation.
+	h.putBit(false, 128) // Normal loop filter.
+	h.putLiteral(uint32(min(e.y1[1]/4, 63)), 6)
+	h.putLiteral(0, 3)   // Sharpness.
+	h.putBit(false, 128) // No loop filter adjustments.
+	h.putLiteral(0, 2)   // A single token partition.
+	h.putLiteral(uint32(e.qi), 7)
+	for i := 0; i < 5; i++ {
+		h.putBit(false, 128) // No quantizer deltas.
+	}
+	h.putBit(false, 128) // Refresh entropy probabilities.
+	for i := range vp8TokenProbUpdateProb {
+		for j := range vp8TokenProbUpdateProb[i] {
+			for k := range vp8TokenProbUpdateProb[i][j] {
+				for _, p := range vp8TokenProbUpdateProb[i][j][k] {
+					h.putBit(false, p)
+				}
+			}
+		}
+	}
+	h.putBit(false, 128) // No macroblock skipping.
+}
+
+// predictBlock fills pred with the prediction of a size x size block of a
+// reconstructed plane, following the edge rules of section 12.2.
+func predictBlock(pred []uint8, plane []uint8, stride, x, y, size, mode int) {
+	top := make([]int32, size)
+	left := make([]int32, size)
+	for i := 0; i < size; i++ {
+		top[i], left[i] = 127, 129
+		if y > 0 {
+			top[i] = int32(plane[(y-1)*stride+x+i])
+		}
+		if x > 0 {
+			left[i] = int32(plane[(y+i)*stride+x-1])
+		}
+	}
+	corner := int32(127)
+	switch {
+	case y > 0 && x > 0:
+		corner = int32(plane[(y-1)*stride+x-1])
+	case y > 0:
+		corner = 129
+	}
+
+	for j := 0; j < size; j++ {
+		for i := 0; i < size; i++ {
+			var p int32
+			switch mode {
+			case vp8PredTM:
+				p = left[j] + top[i] - corner
+			case vp8PredVE:
+				p = top[i]
+			case vp8PredHE:
+				p = left[j]
+			}
+			pred[j*size+i] = clip8(p)
+		}
+	}
+	if mode != vp8PredDC {
+		return
+	}
+
+	// The DC mode averages the edges which are inside the image.
+	sum, n := int32(0), int32(0)
+	if y > 0 {
+		for _, t := range top {
+			sum += t
+		}
+		n += int32(size)
+	}
+	if x > 0 {
+		for _, l := range left {
+			sum += l
+		}
+		n += int32(size)
+	}
+	dc := uint8(128)
+	if n > 0 {
+		dc = uint8((sum + n/2) / n)
+	}
+	for i := range pred[:size*size] {
+		pred[i] = dc
+	}
+}
+
+// bestPrediction returns the mode whose prediction is closest to the source
+// blocks, along with the predictions.
+func bestPrediction(planes, recs [][]uint8, stride, x, y, size int) (int, [][]uint8) {
+	bestMode, bestCost := 0, int32(-1)
+	var best [][]uint8
+	for _, mode := range []int{vp8PredDC, vp8PredVE, vp8PredHE, vp8PredTM} {
+		preds := make([][]uint8, len(planes))
+		cost := int32(0)
+		for p := range planes {
+			preds[p] = make([]uint8, size*size)
+			predictBlock(preds[p], recs[p], stride, x, y, size, mode)
+			for j := 0; j < size; j++ {
+				for i := 0; i < size; i++ {
+					cost += abs32(int32(planes[p][(y+j)*stride+x+i]) - int32(preds[p][j*size+i]))
+				}
+			}
+		}
+		if bestCost < 0 || cost < bestCost {
+			bestMode, bestCost, best = mode, cost, preds
+		}
+	}
+	return bestMode, best
+}
+
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	yMode, yPred := bestPrediction([][]uint8{e.y}, [][]uint8{e.ry}, e.yStride, 16*mbx, 16*mby, 16)
+	uvMode, uvPred := bestPrediction([][]uint8{e.u, e.v}, [][]uint8{e.ru, e.rv}, e.uvStride, 8*mbx, 8*mby, 8)
+
+	h := e.header
+	h.putBit(true, 145) // 16x16 luma prediction.
+	switch yMode {
+	case vp8PredDC:
+		h.putBit(false, 156)
+		h.putBit(false, 163)
+	case vp8PredVE:
+		h.putBit(false, 156)
+		h.putBit(true, 163)
+	case vp8PredHE:
+		h.putBit(true, 156)
+		h.putBit(false, 128)
+	case vp8PredTM:
+		h.putBit(true, 156)
+		h.putBit(true, 128)
+	}
+	if h.putBit(uvMode != vp8PredDC, 142) && h.putBit(uvMode != vp8PredVE, 114) {
+		h.putBit(uvMode != vp8PredHE, 183)
+	}
+
+	// Luma: the DC coefficients of the 16 blocks go through the WHT.
+	var yCoeffs [16][16]int32
+	var dcs [16]int32
+	for n := 0; n < 16; n++ {
+		bx, by := 16*mbx+4*(n%4), 16*mby+4*(n/4)
+		yCoeffs[n] = forwardDCT(e.y, e.yStride, bx, by, yPred[0][4*(n/4)*16+4*(n%4):], 16)
+		dcs[n] = yCoeffs[n][0]
+	}
+	y2Levels, y2Dequant := quantizeBlock(forwardWHT(dcs), e.y2, 0)
+	nz := writeCoeffs(e.tokens, vp8PlaneY2, e.left.y2+e.top[mbx].y2, y2Levels, 0)
+	e.left.y2, e.top[mbx].y2 = nz, nz
+	dcs = inverseWHT(y2Dequant)
+
+	for n := 0; n < 16; n++ {
+		col, row := n%4, n/4
+		levels, dequant := quantizeBlock(yCoeffs[n], e.y1, 1)
+		nz := writeCoeffs(e.tokens, vp8PlaneYWithY2, e.left.y[row]+e.top[mbx].y[col], levels, 1)
+		e.left.y[row], e.top[mbx].y[col] = nz, nz
+		dequant[0] = dcs[n]
+		reconstructBlock(e.ry, e.yStride, 16*mbx+4*col, 16*mby+4*row, yPred[0][4*row*16+4*col:], 16, dequant)
+	}
+
+	// Chroma: 4 blocks for U, then 4 for V.
+	for p, plane := range [][]uint8{e.u, e.v} {
+		rec := [][]uint8{e.ru, e.rv}[p]
+		left, top := &e.left.u, &e.top[mbx].u
+		if p == 1 {
+			left, top = &e.left.v, &e.top[mbx].v
+		}
+		for n := 0; n < 4; n++ {
+			col, row := n%2, n/2
+			bx, by := 8*mbx+4*col, 8*mby+4*row
+			pred := uvPred[p][4*row*8+4*col:]
+			levels, dequant := quantizeBlock(forwardDCT(plane, e.uvStride, bx, by, pred, 8), e.uv, 0)
+			nz := writeCoeffs(e.tokens, vp8PlaneUV, left[row]+top[col], levels, 0)
+			left[row], top[col] = nz, nz
+			reconstructBlock(rec, e.uvStride, bx, by, pred, 8, dequant)
+		}
+	}
+}
+
+// forwardDCT returns the DCT of the difference between a 4x4 block of plane
+// and its prediction, whose rows are predStride apart.
+func forwardDCT(plane []uint8, stride, x, y int, pred []uint8, predStride int) [16]int32 {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		src := plane[(y+i)*stride+x:]
+		ref := pred[i*predStride:]
+		d0 := int32(src[0]) - int32(ref[0])
+		d1 := int32(src[1]) - int32(ref[1])
+		d2 := int32(src[2]) - int32(ref[2])
+		d3 := int32(src[3]) - int32(ref[3])
+		a0, a1, a2, a3 := d0+d3, d1+d2, d1-d2, d0-d3
+		tmp[0+i*4] = (a0 + a1) * 8
+		tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[2+i*4] = (a0 - a1) * 8
+		tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	var out [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+		a2, a3 := tmp[4+i]-tmp[8+i], tmp[0+i]-tmp[12+i]
+		out[0+i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217 + a3*5352 + 12000) >> 16
+		if a3 != 0 {
+			out[4+i]++
+		}
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// forwardWHT returns the Walsh-Hadamard transform of the DC coefficients of
+// the 16 luma blocks.
+func forwardWHT(dcs [16]int32) [16]int32 {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		in := dcs[4*i:]
+		a0, a1 := in[0]+in[2], in[1]+in[3]
+		a2, a3 := in[1]-in[3], in[0]-in[2]
+		tmp[0+i*4] = a0 + a1
+		tmp[1+i*4] = a3 + a2
+		tmp[2+i*4] = a3 - a2
+		tmp[3+i*4] = a0 - a1
+	}
+	var out [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+		a2, a3 := tmp[4+i]-tmp[12+i], tmp[0+i]-tmp[8+i]
+		out[0+i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+	return out
+}
+
+// inverseWHT returns the DC coefficients of the 16 luma blocks, as computed by
+// decoders from the dequantized Y2 coefficients.
+func inverseWHT(in [16]int32) [16]int32 {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := in[0+i]+in[12+i], in[4+i]+in[8+i]
+		a2, a3 := in[4+i]-in[8+i], in[0+i]-in[12+i]
+		m[0+i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	var out [16]int32
+	for i := 0; i < 4; i++ {
+		dc := m[0+i*4] + 3
+		a0, a1 := dc+m[3+i*4], m[1+i*4]+m[2+i*4]
+		a2, a3 := m[1+i*4]-m[2+i*4], dc-m[3+i*4]
+		out[4*i+0] = (a0 + a1) >> 3
+		out[4*i+1] = (a3 + a2) >> 3
+		out[4*i+2] = (a0 - a1) >> 3
+		out[4*i+3] = (a3 - a2) >> 3
+	}
+	return out
+}
+
+// quantizeBlock quantizes the coefficients from position first in zigzag
+// order, and returns the levels in zigzag order along with the dequantized
+// coefficients in raster order.
+func quantizeBlock(coeffs [16]int32, m vp8Matrix, first int) (levels [16]int32, dequant [16]int32) {
+	for n := first; n < 16; n++ {
+		j := int(vp8Zigzag[n])
+		q := m.step(j)
+		c := coeffs[j]
+		level := abs32(c)
+		// Round the DC coefficient to nearest, and the AC coefficients
+		// towards zero to save bits on the noise.
+		if j == 0 {
+			level = (level + q/2) / q
+		} else {
+			level = (level + q/3) / q
+		}
+		level = min(level, vp8MaxLevel)
+		if c < 0 {
+			level = -level
+		}
+		levels[n] = level
+		dequant[j] = level * q
+	}
+	return levels, dequant
+}
+
+// reconstructBlock adds the inverse DCT of the dequantized coefficients to
+// the prediction of a 4x4 block, and stores the result in the plane.
+func reconstructBlock(plane []uint8, stride, x, y int, pred []uint8, predStride int, coeffs [16]int32) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2).
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2).
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := coeffs[i] + coeffs[8+i]
+		b := coeffs[i] - coeffs[8+i]
+		c := (coeffs[4+i]*c2)>>16 - (coeffs[12+i]*c1)>>16
+		d := (coeffs[4+i]*c1)>>16 + (coeffs[12+i]*c2)>>16
+		m[i][0] = a + d
+		m[i][1] = b + c
+		m[i][2] = b - c
+		m[i][3] = a - d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := plane[(y+j)*stride+x:]
+		p := pred[j*predStride:]
+		row[0] = clip8(int32(p[0]) + (a+d)>>3)
+		row[1] = clip8(int32(p[1]) + (b+c)>>3)
+		row[2] = clip8(int32(p[2]) + (b-c)>>3)
+		row[3] = clip8(int32(p[3]) + (a-d)>>3)
+	}
+}
+
+// writeCoeffs writes the levels of a block from position first, as specified
+// in section 13, and returns 1 if some of them are non-zero.
+func writeCoeffs(e *vp8BoolEncoder, plane int, ctx uint8, levels [16]int32, first int) uint8 {
+	last := -1
+	for n := 15; n >= first; n-- {
+		if levels[n] != 0 {
+			last = n
+			break
+		}
+	}
+
+	probs := &vp8DefaultTokenProb[plane]
+	n := first
+	p := probs[vp8Bands[n]][ctx]
+	if !e.putBit(last >= 0, p[0]) {
+		return 0
+	}
+	for n < 16 {
+		c := levels[n]
+		n++
+		v := abs32(c)
+		if !e.putBit(v != 0, p[1]) {
+			p = probs[vp8Bands[n]][0]
+			continue
+		}
+		if !e.putBit(v > 1, p[2]) {
+			p = probs[vp8Bands[n]][1]
+		} else {
+			if !e.putBit(v > 4, p[3]) {
+				if e.putBit(v != 2, p[4]) {
+					e.putBit(v == 4, p[5])
+				}
+			} else if !e.putBit(v > 10, p[6]) {
+				if !e.putBit(v > 6, p[7]) {
+					e.putBit(v == 6, 159)
+				} else {
+					e.putBit(v >= 9, 165)
+					e.putBit(v&1 == 0, 145)
+				}
+			} else {
+				var tab []uint8
+				switch {
+				case v < 3+8<<1:
+					e.putBit(false, p[8])
+					e.putBit(false, p[9])
+					v -= 3 + 8<<0
+					tab = vp8Cat3
+				case v < 3+8<<2:
+					e.putBit(false, p[8])
+					e.putBit(true, p[9])
+					v -= 3 + 8<<1
+					tab = vp8Cat4
+				case v < 3+8<<3:
+					e.putBit(true, p[8])
+					e.putBit(false, p[10])
+					v -= 3 + 8<<2
+					tab = vp8Cat5
+				default:
+					e.putBit(true, p[8])
+					e.putBit(true, p[10])
+					v -= 3 + 8<<3
+					tab = vp8Cat6
+				}
+				for i, prob := range tab {
+					e.putBit(v>>(len(tab)-1-i)&1 != 0, prob)
+				}
+			}
+			p = probs[vp8Bands[n]][2]
+		}
+		e.putBit(c < 0, 128)
+		if n == 16 || !e.putBit(n <= last, p[0]) {
+			return 1
+		}
+	}
+	return 1
+}
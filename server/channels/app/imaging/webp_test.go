@@ -0,0 +1,358 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func testWebPImage(width, height int, withAlpha bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	rnd := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBA{R: uint8(x * 4), G: uint8(y * 3), B: uint8((x + y) * 2), A: 255}
+			// A flat area, and some noise.
+			if x > width/2 && y > height/2 {
+				c = color.NRGBA{R: 40, G: 120, B: 200, A: 255}
+			} else if x < 8 {
+				c.G = uint8(rnd.Intn(256))
+			}
+			if withAlpha {
+				c.A = uint8(x * 255 / width)
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// webpChunks returns the chunks of a RIFF WebP file.
+func webpChunks(t *testing.T, data []byte) map[string][][]byte {
+	t.Helper()
+
+	require.GreaterOrEqual(t, len(data), 12)
+	require.Equal(t, "RIFF", string(data[:4]))
+	require.Equal(t, "WEBP", string(data[8:12]))
+	require.EqualValues(t, len(data)-8, binary.LittleEndian.Uint32(data[4:]))
+
+	chunks := map[string][][]byte{}
+	for data = data[12:]; len(data) > 0; {
+		require.GreaterOrEqual(t, len(data), 8)
+		size := int(binary.LittleEndian.Uint32(data[4:]))
+		require.LessOrEqual(t, 8+size, len(data))
+		chunks[string(data[:4])] = append(chunks[string(data[:4])], data[8:8+size])
+		data = data[8+size+size%2:]
+	}
+	return chunks
+}
+
+func TestEncodeWebPLossless(t *testing.T) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(t, err)
+
+	for name, img := range map[string]*image.NRGBA{
+		"opaque":      testWebPImage(67, 45, false),
+		"transparent": testWebPImage(64, 33, true),
+		"single pixel": func() *image.NRGBA {
+			img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+			img.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+			return img
+		}(),
+		"uniform": image.NewNRGBA(image.Rect(0, 0, 300, 20)),
+		"long runs": func() *image.NRGBA {
+			img := image.NewNRGBA(image.Rect(0, 0, 1000, 40))
+			for i := range img.Pix {
+				img.Pix[i] = uint8(i / 4 % 7 * 30)
+			}
+			return img
+		}(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Lossless: true}))
+			require.Contains(t, webpChunks(t, buf.Bytes()), "VP8L")
+
+			decoded, err := webp.Decode(&buf)
+			require.NoError(t, err)
+			require.Equal(t, img.Bounds(), decoded.Bounds())
+			for y := 0; y < img.Bounds().Dy(); y++ {
+				for x := 0; x < img.Bounds().Dx(); x++ {
+					require.Equal(t, img.NRGBAAt(x, y), color.NRGBAModel.Convert(decoded.At(x, y)), "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPLossy(t *testing.T) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(t, err)
+
+	t.Run("opaque", func(t *testing.T) {
+		img := testWebPImage(83, 50, false)
+
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Quality: 90}))
+		chunks := webpChunks(t, buf.Bytes())
+		require.Contains(t, chunks, "VP8 ")
+		require.NotContains(t, chunks, "ALPH")
+
+		decoded, err := webp.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, img.Bounds(), decoded.Bounds())
+		ycbcr, ok := decoded.(*image.YCbCr)
+		require.True(t, ok)
+
+		// Compare the luma with the limited range conversion of the source.
+		var diff, n int
+		for y := 0; y < 50; y++ {
+			for x := 0; x < 83; x++ {
+				c := img.NRGBAAt(x, y)
+				want := (16839*int(c.R) + 33059*int(c.G) + 6420*int(c.B) + 1<<15 + 16<<16) >> 16
+				got := int(ycbcr.Y[ycbcr.YOffset(x, y)])
+				if x >= 8 {
+					diff += max(got-want, want-got)
+					n++
+				}
+			}
+		}
+		assert.Less(t, float64(diff)/float64(n), 3.0)
+	})
+
+	t.Run("smaller at lower quality", func(t *testing.T) {
+		img := testWebPImage(128, 128, false)
+
+		var high, low bytes.Buffer
+		require.NoError(t, e.EncodeWebP(&high, img, WebPOptions{Quality: 95}))
+		require.NoError(t, e.EncodeWebP(&low, img, WebPOptions{Quality: 20}))
+		assert.Less(t, low.Len(), high.Len())
+
+		_, err := webp.Decode(&low)
+		require.NoError(t, err)
+	})
+
+	t.Run("transparent", func(t *testing.T) {
+		img := testWebPImage(40, 37, true)
+
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Quality: 80}))
+		chunks := webpChunks(t, buf.Bytes())
+		require.Contains(t, chunks, "VP8X")
+		require.Contains(t, chunks, "ALPH")
+		assert.Equal(t, byte(webpFlagAlpha), chunks["VP8X"][0][0])
+
+		decoded, err := webp.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, img.Bounds(), decoded.Bounds())
+		for y := 0; y < 37; y++ {
+			for x := 0; x < 40; x++ {
+				_, _, _, a := decoded.At(x, y).RGBA()
+				require.Equal(t, img.NRGBAAt(x, y).A, uint8(a>>8), "alpha %d,%d", x, y)
+			}
+		}
+	})
+
+	t.Run("invalid dimensions", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Error(t, e.EncodeWebP(&buf, image.NewNRGBA(image.Rect(0, 0, 0, 10)), WebPOptions{}))
+		require.Error(t, e.EncodeWebP(&buf, image.NewNRGBA(image.Rect(0, 0, webpMaxDimension+1, 1)), WebPOptions{}))
+	})
+}
+
+func testGIF(t *testing.T) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Transparent, color.NRGBA{R: 255, A: 255}, color.NRGBA{G: 255, A: 255}}
+	g := &gif.GIF{LoopCount: 2}
+	for i := 0; i < 3; i++ {
+		frame := image.NewPaletted(image.Rect(i*4, 0, i*4+8, 8), palette)
+		for j := range frame.Pix {
+			frame.Pix[j] = uint8(1 + i%2)
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 5*(i+1))
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	g.Disposal[1] = gif.DisposalBackground
+	g.Config = image.Config{ColorModel: palette, Width: 16, Height: 8}
+
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, g))
+	return buf.Bytes()
+}
+
+func TestDecodeGIFAnimation(t *testing.T) {
+	t.Run("frames are composited", func(t *testing.T) {
+		anim, err := DecodeGIFAnimation(bytes.NewReader(testGIF(t)), 1<<20)
+		require.NoError(t, err)
+		require.Len(t, anim.Frames, 3)
+		assert.Equal(t, []int{50, 100, 150}, anim.Durations)
+		assert.Equal(t, 3, anim.LoopCount)
+
+		red, green := color.NRGBA{R: 255, A: 255}, color.NRGBA{G: 255, A: 255}
+		at := func(frame, x int) color.Color {
+			return color.NRGBAModel.Convert(anim.Frames[frame].At(x, 0))
+		}
+		assert.Equal(t, red, at(0, 0))
+		assert.Equal(t, color.NRGBA{}, at(0, 12))
+		assert.Equal(t, red, at(1, 0))
+		assert.Equal(t, green, at(1, 4))
+		// The second frame was disposed to the background.
+		assert.Equal(t, color.NRGBA{}, at(2, 4))
+		assert.Equal(t, red, at(2, 8))
+		assert.Equal(t, red, at(2, 15))
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := DecodeGIFAnimation(bytes.NewReader(testGIF(t)), 16*8*3-1)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := DecodeGIFAnimation(bytes.NewReader([]byte("GIF89a")), 1<<20)
+		require.Error(t, err)
+	})
+}
+
+func TestEncodeAnimatedWebP(t *testing.T) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(t, err)
+
+	anim, err := DecodeGIFAnimation(bytes.NewReader(testGIF(t)), 1<<20)
+	require.NoError(t, err)
+
+	for _, opts := range []WebPOptions{{Lossless: true}, {Quality: 80}} {
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeAnimatedWebP(&buf, anim, opts))
+
+		chunks := webpChunks(t, buf.Bytes())
+		require.Len(t, chunks["VP8X"], 1)
+		assert.Equal(t, byte(webpFlagAnimation|webpFlagAlpha), chunks["VP8X"][0][0])
+		require.Len(t, chunks["ANIM"], 1)
+		assert.EqualValues(t, 3, binary.LittleEndian.Uint16(chunks["ANIM"][0][4:]))
+		require.Len(t, chunks["ANMF"], 3)
+
+		for i, frame := range chunks["ANMF"] {
+			duration := int(frame[12]) | int(frame[13])<<8 | int(frame[14])<<16
+			assert.Equal(t, anim.Durations[i], duration)
+
+			// Every frame is a still image of the canvas size.
+			still := appendChunk(nil, "VP8X", webpHeader(webpFlagAlpha, 16, 8))
+			still = append(still, frame[16:]...)
+			if opts.Lossless {
+				still = frame[16:]
+			}
+			var file bytes.Buffer
+			require.NoError(t, writeRIFF(&file, still))
+			decoded, err := webp.Decode(&file)
+			require.NoError(t, err)
+			require.Equal(t, image.Rect(0, 0, 16, 8), decoded.Bounds())
+			if opts.Lossless {
+				assert.Equal(t, color.NRGBAModel.Convert(anim.Frames[i].At(0, 0)), color.NRGBAModel.Convert(decoded.At(0, 0)))
+			}
+		}
+	}
+
+	t.Run("invalid animation", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Error(t, e.EncodeAnimatedWebP(&buf, &Animation{}, WebPOptions{}))
+		require.Error(t, e.EncodeAnimatedWebP(&buf, &Animation{
+			Frames:    []image.Image{image.NewNRGBA(image.Rect(0, 0, 2, 2)), image.NewNRGBA(image.Rect(0, 0, 3, 2))},
+			Durations: []int{10, 10},
+		}, WebPOptions{}))
+	})
+}
+
+func TestIsLosslessWebP(t *testing.T) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(t, err)
+	img := testWebPImage(20, 10, true)
+
+	var lossless, lossy bytes.Buffer
+	require.NoError(t, e.EncodeWebP(&lossless, img, WebPOptions{Lossless: true}))
+	require.NoError(t, e.EncodeWebP(&lossy, img, WebPOptions{Quality: 80}))
+
+	assert.True(t, IsLosslessWebP(lossless.Bytes()))
+	assert.False(t, IsLosslessWebP(lossy.Bytes()))
+	assert.False(t, IsLosslessWebP([]byte("RIFF")))
+	assert.False(t, IsLosslessWebP(nil))
+}
+
+// fuzzWebPImage returns an image of up to 64x64 pixels whose pixels repeat pix.
+func fuzzWebPImage(width, height uint8, pix []byte) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, int(width%64)+1, int(height%64)+1))
+	if len(pix) > 0 {
+		for i := range img.Pix {
+			img.Pix[i] = pix[i%len(pix)]
+		}
+	}
+	return img
+}
+
+func FuzzEncodeWebPLossless(f *testing.F) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(f, err)
+
+	f.Add(uint8(0), uint8(0), []byte{1, 2, 3, 4})
+	f.Add(uint8(63), uint8(2), []byte{})
+	f.Add(uint8(20), uint8(30), []byte{0, 0, 0, 255, 255, 255, 255, 0, 7})
+	f.Add(uint8(40), uint8(40), testWebPImage(40, 40, true).Pix)
+
+	f.Fuzz(func(t *testing.T, width, height uint8, pix []byte) {
+		img := fuzzWebPImage(width, height, pix)
+
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Lossless: true}))
+		require.True(t, IsLosslessWebP(buf.Bytes()))
+
+		decoded, err := webp.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, img.Bounds(), decoded.Bounds())
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				require.Equal(t, img.NRGBAAt(x, y), color.NRGBAModel.Convert(decoded.At(x, y)), "pixel %d,%d", x, y)
+			}
+		}
+	})
+}
+
+func FuzzEncodeWebPLossy(f *testing.F) {
+	e, err := NewEncoder(EncoderOptions{})
+	require.NoError(f, err)
+
+	f.Add(uint8(0), uint8(0), uint8(0), []byte{1, 2, 3, 4})
+	f.Add(uint8(63), uint8(2), uint8(100), []byte{})
+	f.Add(uint8(20), uint8(30), uint8(50), []byte{0, 0, 0, 255, 255, 255, 255, 0, 7})
+	f.Add(uint8(40), uint8(40), uint8(90), testWebPImage(40, 40, true).Pix)
+
+	f.Fuzz(func(t *testing.T, width, height, quality uint8, pix []byte) {
+		img := fuzzWebPImage(width, height, pix)
+
+		var buf bytes.Buffer
+		require.NoError(t, e.EncodeWebP(&buf, img, WebPOptions{Quality: int(quality % 101)}))
+		require.False(t, IsLosslessWebP(buf.Bytes()))
+
+		decoded, err := webp.Decode(&buf)
+		require.NoError(t, err)
+		require.Equal(t, img.Bounds(), decoded.Bounds())
+		// The alpha channel is always compressed losslessly.
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			for x := 0; x < img.Bounds().Dx(); x++ {
+				_, _, _, a := decoded.At(x, y).RGBA()
+				require.Equal(t, img.NRGBAAt(x, y).A, uint8(a>>8), "alpha %d,%d", x, y)
+			}
+		}
+	})
+}
@@ -1360,6 +1360,69 @@ func (a *App) importReaction(data *imports.ReactionImportData, post *model.Post)
 	return nil
 }
 
+// importPoll saves the poll of a post and its votes. The poll is only saved when first
+// imported, importing it again updates its votes.
+func (a *App) importPoll(data *imports.PollImportData, post *model.Post) *model.AppError {
+	poll, nErr := a.Srv().Store().Poll().Get(post.Id)
+	if nErr != nil {
+		var nfErr *store.ErrNotFound
+		if !errors.As(nErr, &nfErr) {
+			return model.NewAppError("importPoll", "app.poll.get.app_error", nil, "post_id="+post.Id, http.StatusInternalServerError).Wrap(nErr)
+		}
+
+		poll = &model.Poll{
+			PostId:         post.Id,
+			Question:       *data.Question,
+			Anonymous:      model.SafeDereference(data.Anonymous),
+			MultipleChoice: model.SafeDereference(data.MultipleChoice),
+			CreateAt:       post.CreateAt,
+			ClosedAt:       model.SafeDereference(data.ClosedAt),
+		}
+		for _, option := range *data.Options {
+			poll.Options = append(poll.Options, &model.PollOption{Text: *option.Text})
+		}
+
+		if poll, nErr = a.Srv().Store().Poll().Save(poll); nErr != nil {
+			var appErr *model.AppError
+			switch {
+			case errors.As(nErr, &appErr):
+				return appErr
+			default:
+				return model.NewAppError("importPoll", "app.poll.save.app_error", nil, "", http.StatusInternalServerError).Wrap(nErr)
+			}
+		}
+	}
+
+	optionIDs := make(map[string]string, len(poll.Options))
+	for _, option := range poll.Options {
+		optionIDs[option.Text] = option.Id
+	}
+
+	votes := map[string][]string{}
+	for _, option := range *data.Options {
+		optionID, ok := optionIDs[*option.Text]
+		if !ok || option.Voters == nil {
+			continue
+		}
+		for _, username := range *option.Voters {
+			votes[username] = append(votes[username], optionID)
+		}
+	}
+
+	for username, votedOptionIDs := range votes {
+		user, nErr := a.Srv().Store().User().GetByUsername(username)
+		if nErr != nil {
+			return model.NewAppError("BulkImport", "app.import.import_post.user_not_found.error", map[string]any{"Username": username}, "", http.StatusBadRequest).Wrap(nErr)
+		}
+
+		if nErr := a.Srv().Store().Poll().ImportVotes(post.Id, user.Id, votedOptionIDs); nErr != nil {
+			return model.NewAppError("importPoll", "app.poll.vote.app_error", nil, "", http.StatusInternalServerError).Wrap(nErr)
+		}
+	}
+
+	return nil
+}
+
 func (a *App) importReplies(rctx request.CTX, data []imports.ReplyImportData, post *model.Post, teamID string, extractContent bool) *model.AppError {
 	var err *model.AppError
 	usernames := []string{}
@@ -1503,6 +1566,12 @@ func (a *App) importReplies(rctx request.CTX, data []imports.ReplyImportData, po
 	for _, postWithData := range postsWithData {
 		a.updateFileInfoWithPostId(rctx, postWithData.post)
 
+		if postWithData.replyData.Poll != nil {
+			if err := a.importPoll(postWithData.replyData.Poll, postWithData.post); err != nil {
+				return err
+			}
+		}
+
 		if postWithData.replyData.FlaggedBy != nil {
 			var preferences model.Preferences
 
@@ -2013,6 +2082,12 @@ func (a *App) importMultiplePostLines(rctx request.CTX, lines []imports.LineImpo
 			}
 		}
 
+		if postWithData.postData.Poll != nil {
+			if err := a.importPoll(postWithData.postData.Poll, postWithData.post); err != nil {
+				return postWithData.lineNumber, err
+			}
+		}
+
 		if postWithData.postData.Replies != nil && len(*postWithData.postData.Replies) > 0 {
 			err := a.importReplies(rctx, *postWithData.postData.Replies, postWithData.post, postWithData.team.Id, extractContent)
 			if err != nil {
@@ -2521,6 +2596,12 @@ func (a *App) importMultipleDirectPostLines(rctx request.CTX, lines []imports.Li
 			}
 		}
 
+		if postWithData.directPostData.Poll != nil {
+			if err := a.importPoll(postWithData.directPostData.Poll, postWithData.post); err != nil {
+				return postWithData.lineNumber, err
+			}
+		}
+
 		if postWithData.directPostData.Replies != nil {
 			if err := a.importReplies(rctx, *postWithData.directPostData.Replies, postWithData.post, "noteam", extractContent); err != nil {
 				return postWithData.lineNumber, err
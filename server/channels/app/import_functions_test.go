@@ -3812,6 +3812,85 @@ func TestImportImportPost(t *testing.T) {
 	})
 }
 
+func TestImportPoll(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	post, nErr := th.App.Srv().Store().Post().Save(th.Context, &model.Post{
+		ChannelId: th.BasicChannel.Id,
+		UserId:    th.BasicUser.Id,
+		Type:      model.PostTypePoll,
+		Message:   "Lunch?",
+	})
+	require.NoError(t, nErr)
+
+	data := &imports.PollImportData{
+		Question:       model.NewPointer("Lunch?"),
+		MultipleChoice: model.NewPointer(true),
+		Options: &[]imports.PollOptionImportData{
+			{Text: model.NewPointer("Pizza"), Voters: &[]string{th.BasicUser.Username, th.BasicUser2.Username}},
+			{Text: model.NewPointer("Salad"), Voters: &[]string{th.BasicUser.Username}},
+		},
+	}
+
+	// Importing the poll again doesn't duplicate it or its votes.
+	for range 2 {
+		require.Nil(t, th.App.importPoll(data, post))
+	}
+
+	poll, appErr := th.App.GetPoll(th.Context, post.Id, th.BasicUser.Id)
+	require.Nil(t, appErr)
+	assert.Equal(t, post.CreateAt, poll.CreateAt)
+	assert.True(t, poll.MultipleChoice)
+	assert.False(t, poll.IsClosed())
+	assert.Equal(t, int64(2), poll.VoterCount)
+	assert.Equal(t, int64(2), poll.Options[0].VoteCount)
+	assert.Equal(t, int64(1), poll.Options[1].VoteCount)
+	assert.ElementsMatch(t, []string{poll.Options[0].Id, poll.Options[1].Id}, poll.MyVotes)
+
+	t.Run("closed poll", func(t *testing.T) {
+		closedPost, nErr := th.App.Srv().Store().Post().Save(th.Context, &model.Post{
+			ChannelId: th.BasicChannel.Id,
+			UserId:    th.BasicUser.Id,
+			Type:      model.PostTypePoll,
+			Message:   "Dinner?",
+		})
+		require.NoError(t, nErr)
+
+		closedData := &imports.PollImportData{
+			Question: model.NewPointer("Dinner?"),
+			ClosedAt: model.NewPointer(closedPost.CreateAt + 1000),
+			Options: &[]imports.PollOptionImportData{
+				{Text: model.NewPointer("Pasta"), Voters: &[]string{th.BasicUser.Username}},
+				{Text: model.NewPointer("Curry"), Voters: &[]string{th.BasicUser2.Username}},
+			},
+		}
+
+		// Closed polls can be imported again too.
+		for range 2 {
+			require.Nil(t, th.App.importPoll(closedData, closedPost))
+		}
+
+		poll, appErr := th.App.GetPoll(th.Context, closedPost.Id, th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.True(t, poll.IsClosed())
+		assert.Equal(t, closedPost.CreateAt+1000, poll.ClosedAt)
+		assert.Equal(t, int64(2), poll.VoterCount)
+		assert.Equal(t, []string{poll.Options[0].Id}, poll.MyVotes)
+	})
+
+	t.Run("unknown voter", func(t *testing.T) {
+		data.Options = &[]imports.PollOptionImportData{
+			{Text: model.NewPointer("Pizza"), Voters: &[]string{model.NewUsername()}},
+			{Text: model.NewPointer("Salad")},
+		}
+		appErr := th.App.importPoll(data, post)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.import.import_post.user_not_found.error", appErr.Id)
+	})
+}
+
 func TestImportImportDirectChannel(t *testing.T) {
 	mainHelper.Parallel(t)
 	th := Setup(t).InitBasic()
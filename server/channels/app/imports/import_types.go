@@ -180,6 +180,21 @@ type ReactionImportData struct {
 	EmojiName *string `json:"emoji_name"`
 }
 
+// PollImportData is the poll of a post of type poll. The votes of its options are imported
+// with their voters, including for anonymous polls.
+type PollImportData struct {
+	Question       *string                 `json:"question"`
+	Options        *[]PollOptionImportData `json:"options"`
+	Anonymous      *bool                   `json:"anonymous,omitempty"`
+	MultipleChoice *bool                   `json:"multiple_choice,omitempty"`
+	ClosedAt       *int64                  `json:"closed_at,omitempty"`
+}
+
+type PollOptionImportData struct {
+	Text   *string   `json:"text"`
+	Voters *[]string `json:"voters,omitempty"`
+}
+
 type ReplyImportData struct {
 	User *string `json:"user"`
 
@@ -193,6 +208,7 @@ type ReplyImportData struct {
 	Reactions   *[]ReactionImportData   `json:"reactions,omitempty"`
 	Attachments *[]AttachmentImportData `json:"attachments,omitempty"`
 	IsPinned    *bool                   `json:"is_pinned,omitempty"`
+	Poll        *PollImportData         `json:"poll,omitempty"`
 }
 
 type PostImportData struct {
@@ -211,6 +227,7 @@ type PostImportData struct {
 	Replies     *[]ReplyImportData      `json:"replies,omitempty"`
 	Attachments *[]AttachmentImportData `json:"attachments,omitempty"`
 	IsPinned    *bool                   `json:"is_pinned,omitempty"`
+	Poll        *PollImportData         `json:"poll,omitempty"`
 
 	ThreadFollowers *[]ThreadFollowerImportData `json:"thread_followers,omitempty"`
 }
@@ -239,6 +256,7 @@ type DirectPostImportData struct {
 	Replies     *[]ReplyImportData      `json:"replies"`
 	Attachments *[]AttachmentImportData `json:"attachments"`
 	IsPinned    *bool                   `json:"is_pinned,omitempty"`
+	Poll        *PollImportData         `json:"poll,omitempty"`
 
 	ThreadFollowers *[]ThreadFollowerImportData `json:"thread_followers,omitempty"`
 }
@@ -464,6 +464,30 @@ func ValidateReactionImportData(data *ReactionImportData, parentCreateAt int64)
 	return nil
 }
 
+func ValidatePollImportData(data *PollImportData, postType *string) *model.AppError {
+	if postType == nil || *postType != model.PostTypePoll {
+		return model.NewAppError("BulkImport", "app.import.validate_poll_import_data.type.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.Question == nil || *data.Question == "" {
+		return model.NewAppError("BulkImport", "app.import.validate_poll_import_data.question_missing.error", nil, "", http.StatusBadRequest)
+	} else if utf8.RuneCountInString(*data.Question) > model.PollQuestionMaxRunes {
+		return model.NewAppError("BulkImport", "app.import.validate_poll_import_data.question_length.error", nil, "", http.StatusBadRequest)
+	}
+
+	if data.Options == nil || len(*data.Options) < model.PollMinOptions || len(*data.Options) > model.PollMaxOptions {
+		return model.NewAppError("BulkImport", "app.import.validate_poll_import_data.options.error", map[string]any{"Min": model.PollMinOptions, "Max": model.PollMaxOptions}, "", http.StatusBadRequest)
+	}
+
+	for _, option := range *data.Options {
+		if option.Text == nil || *option.Text == "" || utf8.RuneCountInString(*option.Text) > model.PollOptionTextMaxRunes {
+			return model.NewAppError("BulkImport", "app.import.validate_poll_import_data.option_text.error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
 func ValidateReplyImportData(data *ReplyImportData, parentCreateAt int64, maxPostSize int) *model.AppError {
 	if data.User == nil {
 		return model.NewAppError("BulkImport", "app.import.validate_reply_import_data.user_missing.error", nil, "", http.StatusBadRequest)
@@ -495,6 +519,12 @@ func ValidateReplyImportData(data *ReplyImportData, parentCreateAt int64, maxPos
 		}
 	}
 
+	if data.Poll != nil {
+		if err := ValidatePollImportData(data.Poll, data.Type); err != nil {
+			return err
+		}
+	}
+
 	if data.Attachments != nil {
 		for _, attachment := range *data.Attachments {
 			if err := ValidateAttachmentImportData(&attachment); err != nil {
@@ -539,6 +569,12 @@ func ValidatePostImportData(data *PostImportData, maxPostSize int) *model.AppErr
 		}
 	}
 
+	if data.Poll != nil {
+		if err := ValidatePollImportData(data.Poll, data.Type); err != nil {
+			return err
+		}
+	}
+
 	if data.Replies != nil {
 		for _, reply := range *data.Replies {
 			if err := ValidateReplyImportData(&reply, *data.CreateAt, maxPostSize); err != nil {
@@ -664,6 +700,12 @@ func ValidateDirectPostImportData(data *DirectPostImportData, maxPostSize int) *
 		}
 	}
 
+	if data.Poll != nil {
+		if err := ValidatePollImportData(data.Poll, data.Type); err != nil {
+			return err
+		}
+	}
+
 	if data.Replies != nil {
 		for _, reply := range *data.Replies {
 			if err := ValidateReplyImportData(&reply, *data.CreateAt, maxPostSize); err != nil {
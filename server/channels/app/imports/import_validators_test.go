@@ -904,6 +904,57 @@ func TestImportValidateReactionImportData(t *testing.T) {
 	require.NotNil(t, err, "Should have failed due parent with newer create-at value.")
 }
 
+func TestImportValidatePollImportData(t *testing.T) {
+	validData := func() PollImportData {
+		return PollImportData{
+			Question: model.NewPointer("Lunch?"),
+			Options: &[]PollOptionImportData{
+				{Text: model.NewPointer("Pizza"), Voters: &[]string{"username"}},
+				{Text: model.NewPointer("Sushi")},
+			},
+		}
+	}
+	postType := model.NewPointer(model.PostTypePoll)
+
+	// Test with minimum required valid properties.
+	data := validData()
+	err := ValidatePollImportData(&data, postType)
+	require.Nil(t, err, "Validation failed but should have been valid.")
+
+	// Test with a post which isn't a poll.
+	err = ValidatePollImportData(&data, nil)
+	require.NotNil(t, err, "Should have failed due to the post type.")
+	err = ValidatePollImportData(&data, model.NewPointer(model.PostTypeDefault))
+	require.NotNil(t, err, "Should have failed due to the post type.")
+
+	// Test with missing required properties.
+	data = validData()
+	data.Question = nil
+	err = ValidatePollImportData(&data, postType)
+	require.NotNil(t, err, "Should have failed due to missing question.")
+
+	data = validData()
+	data.Options = nil
+	err = ValidatePollImportData(&data, postType)
+	require.NotNil(t, err, "Should have failed due to missing options.")
+
+	// Test with invalid properties.
+	data = validData()
+	data.Question = model.NewPointer(strings.Repeat("a", model.PollQuestionMaxRunes+1))
+	err = ValidatePollImportData(&data, postType)
+	require.NotNil(t, err, "Should have failed due to too long question.")
+
+	data = validData()
+	*data.Options = (*data.Options)[:1]
+	err = ValidatePollImportData(&data, postType)
+	require.NotNil(t, err, "Should have failed due to too few options.")
+
+	data = validData()
+	(*data.Options)[1].Text = model.NewPointer("")
+	err = ValidatePollImportData(&data, postType)
+	require.NotNil(t, err, "Should have failed due to empty option.")
+}
+
 func TestImportValidateReplyImportData(t *testing.T) {
 	// Test with minimum required valid properties.
 	parentCreateAt := model.GetMillis() - 100
@@ -159,6 +159,10 @@ func (a *App) SessionHasPermissionToCreateJob(session model.Session, job *model.
 		model.JobTypeExportProcess,
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
+		model.JobTypeFileStorageMigration,
+		model.JobTypeRegenerateFilePreviews,
+		model.JobTypeEmbeddedSearchIndexing,
+		model.JobTypeSearchNgramBackfill,
 		model.JobTypeExtractContent:
 		return a.SessionHasPermissionTo(session, model.PermissionManageJobs), model.PermissionManageJobs
 	case model.JobTypeAccessControlSync:
@@ -210,6 +214,10 @@ func (a *App) SessionHasPermissionToManageJob(session model.Session, job *model.
 		model.JobTypeExportProcess,
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
+		model.JobTypeFileStorageMigration,
+		model.JobTypeRegenerateFilePreviews,
+		model.JobTypeEmbeddedSearchIndexing,
+		model.JobTypeSearchNgramBackfill,
 		model.JobTypeExtractContent:
 		permission = model.PermissionManageJobs
 	case model.JobTypeAccessControlSync:
@@ -247,6 +255,17 @@ func (a *App) SessionHasPermissionToReadJob(session model.Session, jobType strin
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
 		model.JobTypeMobileSessionMetadata,
+		model.JobTypeOutgoingWebhookRetry,
+		model.JobTypeEmailBatching,
+		model.JobTypeEmailDigest,
+		model.JobTypeFileEncryptionRewrap,
+		model.JobTypeFileDeduplication,
+		model.JobTypeFileStorageMigration,
+		model.JobTypeRegenerateFilePreviews,
+		model.JobTypeEmbeddedSearchIndexing,
+		model.JobTypeSearchNgramBackfill,
+		model.JobTypeSavedSearchWatch,
+		model.JobTypePostEmbedding,
 		model.JobTypeExtractContent:
 		return a.SessionHasPermissionTo(session, model.PermissionReadJobs), model.PermissionReadJobs
 	case model.JobTypeAccessControlSync:
@@ -15,6 +15,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
+	"github.com/mattermost/mattermost/server/public/shared/markdown"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	email "github.com/mattermost/mattermost/server/v8/channels/app/email"
@@ -97,7 +98,7 @@ func (a *App) buildEmailNotification(
 	var messageHTML, messageText string
 	if emailNotificationContentsType == model.EmailNotificationContentsFull {
 		messageHTML = a.GetMessageForNotification(post, team.Name, a.GetSiteURL(), translateFunc)
-		messageText = post.Message
+		messageText = markdown.RenderPlainText(post.Message)
 	}
 
 	landingURL := a.GetSiteURL() + "/landing#/" + team.Name
@@ -254,8 +255,15 @@ func (a *App) sendNotificationEmail(rctx request.CTX, notification *PostNotifica
 		references = referencesVal
 	}
 
+	// Replies to the email are posted to the thread when replying by email is enabled
+	rootID := post.RootId
+	if rootID == "" {
+		rootID = post.Id
+	}
+	replyToAddress := a.ReplyByEmailAddress(user.Id, rootID)
+
 	a.Srv().Go(func() {
-		if nErr := a.Srv().EmailService.SendMailWithEmbeddedFiles(user.Email, html.UnescapeString(emailNotification.Subject), bodyText, embeddedFiles, messageID, inReplyTo, references, "Notification"); nErr != nil {
+		if nErr := a.Srv().EmailService.SendMailWithEmbeddedFilesAndCustomReplyTo(user.Email, html.UnescapeString(emailNotification.Subject), bodyText, replyToAddress, embeddedFiles, messageID, inReplyTo, references, "Notification"); nErr != nil {
 			rctx.Logger().Error("Error while sending the email", mlog.String("user_email", user.Email), mlog.Err(nErr))
 		}
 	})
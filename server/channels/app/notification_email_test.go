@@ -1247,3 +1247,35 @@ func TestMarkdownConversion(t *testing.T) {
 		})
 	}
 }
+
+func TestNotificationEmailMessageText(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := SetupWithStoreMock(t)
+	defer th.TearDown()
+
+	recipient := buildTestUser("test-recipient-id", "recipient", "Recipient User", true)
+	post := &model.Post{
+		Id:      "test-post-id",
+		Message: "**Heads up** @recipient, see [the docs](https://example.com) and `make run`\n- one\n- two",
+	}
+	channel := &model.Channel{
+		Id:          "test-channel-id",
+		Name:        "testchannel",
+		DisplayName: "ChannelName",
+		Type:        model.ChannelTypeOpen,
+	}
+	sender := buildTestUser("test-sender-id", "sender", "sender", true)
+	team := buildTestTeam("test-team-id", "testteam", "testteam")
+
+	storeMock := th.App.Srv().Store().(*mocks.Store)
+	teamStoreMock := mocks.TeamStore{}
+	teamStoreMock.On("GetByName", "testteam").Return(&model.Team{Name: "testteam"}, nil)
+	storeMock.On("Team").Return(&teamStoreMock)
+
+	setupPreferenceMocks(th, recipient.Id, true)
+
+	notification := buildTestPostNotification(post, channel, sender)
+	emailNotification := th.App.buildEmailNotification(th.Context, notification, recipient, team)
+	assert.Equal(t, "Heads up @recipient, see the docs and make run\n- one\n- two", emailNotification.MessageText)
+	assert.Contains(t, emailNotification.MessageHTML, "<strong>Heads up</strong>")
+}
@@ -19,9 +19,9 @@ import (
 
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
+	"github.com/mattermost/mattermost/server/public/shared/markdown"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/public/shared/request"
-	"github.com/mattermost/mattermost/server/v8/channels/utils"
 )
 
 type notificationType string
@@ -35,6 +35,7 @@ type pushJWTClaims struct {
 const (
 	notificationTypeClear       notificationType = "clear"
 	notificationTypeMessage     notificationType = "message"
+	notificationTypeSavedSearch notificationType = "saved_search"
 	notificationTypeUpdateBadge notificationType = "update_badge"
 	notificationTypeDummy       notificationType = "dummy"
 
@@ -65,6 +66,7 @@ type PushNotification struct {
 	explicitMention    bool
 	channelWideMention bool
 	replyToThreadType  string
+	savedSearchName    string
 }
 
 func (a *App) sendPushNotificationSync(rctx request.CTX, post *model.Post, user *model.User, channel *model.Channel, channelName string, senderName string,
@@ -255,6 +257,56 @@ func (a *App) sendPushNotification(notification *PostNotification, user *model.U
 	}
 }
 
+// sendSavedSearchPushNotification notifies the user that a post matched one of their watched
+// saved searches. Unlike a mention, the message tells which saved search matched.
+func (a *App) sendSavedSearchPushNotification(notification *PostNotification, user *model.User, savedSearchName string) {
+	nameFormat := a.GetNotificationNameFormat(user)
+
+	select {
+	case a.Srv().PushNotificationsHub.notificationsChan <- PushNotification{
+		notificationType: notificationTypeSavedSearch,
+		post:             notification.Post,
+		user:             user,
+		channel:          notification.Channel,
+		senderName:       notification.GetSenderName(nameFormat, *a.Config().ServiceSettings.EnablePostUsernameOverride),
+		channelName:      notification.GetChannelName(nameFormat, user.Id),
+		savedSearchName:  savedSearchName,
+	}:
+	case <-a.Srv().PushNotificationsHub.stopChan:
+		return
+	}
+}
+
+func (a *App) sendSavedSearchPushNotificationSync(rctx request.CTX, post *model.Post, user *model.User, channel *model.Channel, channelName string, senderName string,
+	savedSearchName string,
+) *model.AppError {
+	contentsConfig := *a.Config().EmailSettings.PushNotificationContents
+	msg, appErr := a.BuildPushNotificationMessage(rctx, contentsConfig, post, user, channel, channelName, senderName, false, false, "")
+	if appErr != nil {
+		return appErr
+	}
+
+	// The messages of ID-loaded notifications are fetched by the device.
+	if !msg.IsIdLoaded {
+		msg.Message = getSavedSearchPushNotificationMessage(contentsConfig, msg.Message, msg.SenderName, savedSearchName, i18n.GetUserTranslations(user.Locale))
+	}
+
+	return a.sendPushNotificationToAllSessions(rctx, msg, user.Id, "")
+}
+
+// getSavedSearchPushNotificationMessage tells which saved search a post matched, followed by the
+// message of the post when the notifications hold the full contents.
+func getSavedSearchPushNotificationMessage(contentsConfig, message, senderName, savedSearchName string, userLocale i18n.TranslateFunc) string {
+	matched := userLocale("api.push_notification.saved_search_matched", map[string]any{
+		"SenderName":      senderName,
+		"SavedSearchName": savedSearchName,
+	})
+	if contentsConfig == model.FullNotification {
+		return matched + "\n" + message
+	}
+	return matched
+}
+
 func (a *App) getPushNotificationMessage(contentsConfig, postMessage string, explicitMention, channelWideMention,
 	hasFiles bool, senderName string, channelType model.ChannelType, replyToThreadType string, userLocale i18n.TranslateFunc,
 ) string {
@@ -445,6 +497,16 @@ func (hub *PushNotificationsHub) start(rctx request.CTX) {
 						notification.channelWideMention,
 						notification.replyToThreadType,
 					)
+				case notificationTypeSavedSearch:
+					err = hub.app.sendSavedSearchPushNotificationSync(
+						rctx,
+						notification.post,
+						notification.user,
+						notification.channel,
+						notification.channelName,
+						notification.senderName,
+						notification.savedSearchName,
+					)
 				case notificationTypeUpdateBadge:
 					err = hub.app.updateMobileAppBadgeSync(rctx, notification.userID)
 				default:
@@ -834,13 +896,7 @@ func (a *App) buildFullPushNotificationMessage(rctx request.CTX, contentsConfig
 		msg.FromWebhook = fw
 	}
 
-	postMessage := post.Message
-	stripped, err := utils.StripMarkdown(postMessage)
-	if err != nil {
-		rctx.Logger().Warn("Failed parse to markdown", mlog.String("post_id", post.Id), mlog.Err(err))
-	} else {
-		postMessage = stripped
-	}
+	postMessage := markdown.RenderPlainText(post.Message)
 	for _, attachment := range post.Attachments() {
 		if attachment.Fallback != "" {
 			postMessage += "\n" + attachment.Fallback
@@ -1654,6 +1654,32 @@ func TestPushNotificationAttachment(t *testing.T) {
 	})
 }
 
+func TestPushNotificationMarkdown(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t)
+	defer th.TearDown()
+
+	post := &model.Post{
+		Message: "**Heads up** @user, see [the docs](https://example.com) and `make run`\n- one\n- two",
+	}
+	user := &model.User{}
+	ch := &model.Channel{}
+
+	pn := th.App.buildFullPushNotificationMessage(th.Context, "full", post, user, ch, ch.Name, "test", false, false, "")
+	assert.Equal(t, "test: Heads up @user, see the docs and make run\n- one\n- two", pn.Message)
+}
+
+func TestGetSavedSearchPushNotificationMessage(t *testing.T) {
+	userLocale := i18n.GetUserTranslations("en")
+
+	assert.Equal(t, `sender posted a message matching your saved search "Deploys".`,
+		getSavedSearchPushNotificationMessage(model.GenericNotification, "sender posted a message.", "sender", "Deploys", userLocale))
+	assert.Equal(t, `sender posted a message matching your saved search "Deploys".`,
+		getSavedSearchPushNotificationMessage(model.GenericNoChannelNotification, "sender posted a message.", "sender", "Deploys", userLocale))
+	assert.Equal(t, "sender posted a message matching your saved search \"Deploys\".\nsender: deploy done",
+		getSavedSearchPushNotificationMessage(model.FullNotification, "sender: deploy done", "sender", "Deploys", userLocale))
+}
+
 // Run it with | grep -v '{"level"' to prevent spamming the console.
 func BenchmarkPushNotificationThroughput(b *testing.B) {
 	th := SetupWithStoreMock(b)
@@ -0,0 +1,196 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const (
+	outgoingWebhookDeliveryRetryBatchSize   = 200
+	outgoingWebhookDeliveryRetryConcurrency = 10
+	outgoingWebhookDeliveryCleanupBatchSize = 1000
+)
+
+var (
+	errOutgoingWebhookDeleted            = errors.New("outgoing webhook no longer exists")
+	errOutgoingWebhookCallbackURLRemoved = errors.New("callback URL no longer belongs to the outgoing webhook")
+	errOutgoingWebhookRejected           = errors.New("outgoing webhook request was rejected")
+)
+
+// queueOutgoingWebhookDelivery stores a failed outgoing webhook request so that it can be retried later.
+func (a *App) queueOutgoingWebhookDelivery(rctx request.CTX, hook *model.OutgoingWebhook, channel *model.Channel, postID, url, contentType string, body []byte, sendErr error) {
+	delivery := &model.OutgoingWebhookDelivery{
+		HookId:      hook.Id,
+		TeamId:      hook.TeamId,
+		ChannelId:   channel.Id,
+		PostId:      postID,
+		CallbackURL: url,
+		ContentType: contentType,
+		Payload:     string(body),
+	}
+	delivery.RecordFailure(sendErr, time.Now())
+
+	if _, err := a.Srv().Store().OutgoingWebhookDelivery().Save(delivery); err != nil {
+		rctx.Logger().Error("Failed to queue outgoing webhook delivery for retry",
+			mlog.String("outgoing_webhook_id", hook.Id),
+			mlog.String("post_id", postID),
+			mlog.Err(err),
+		)
+	}
+}
+
+// attemptOutgoingWebhookDelivery sends a queued delivery again and records the outcome.
+func (a *App) attemptOutgoingWebhookDelivery(rctx request.CTX, delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, error) {
+	sendErr := a.resendOutgoingWebhookDelivery(rctx, delivery)
+	switch {
+	case errors.Is(sendErr, errOutgoingWebhookDeleted), errors.Is(sendErr, errOutgoingWebhookCallbackURLRemoved), errors.Is(sendErr, errOutgoingWebhookRejected):
+		delivery.Attempts++
+		delivery.LastError = sendErr.Error()
+		delivery.Status = model.OutgoingWebhookDeliveryStatusDead
+		delivery.NextAttemptAt = 0
+	case sendErr != nil:
+		delivery.RecordFailure(sendErr, time.Now())
+	default:
+		delivery.RecordSuccess()
+	}
+
+	return a.Srv().Store().OutgoingWebhookDelivery().Update(delivery)
+}
+
+func (a *App) resendOutgoingWebhookDelivery(rctx request.CTX, delivery *model.OutgoingWebhookDelivery) error {
+	// Always use the current hook so that a rotated signing secret or a deleted hook is honoured.
+	hook, err := a.Srv().Store().Webhook().GetOutgoing(delivery.HookId)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if errors.As(err, &nfErr) {
+			return errOutgoingWebhookDeleted
+		}
+		return err
+	}
+
+	// The payload must not be sent to a URL removed from the hook since it was queued.
+	if !slices.Contains(hook.CallbackURLs, delivery.CallbackURL) {
+		return errOutgoingWebhookCallbackURLRemoved
+	}
+
+	channel, err := a.Srv().Store().Channel().Get(delivery.ChannelId, true)
+	if err != nil {
+		return err
+	}
+
+	if err := a.sendOutgoingWebhook(rctx, hook, channel, delivery.PostId, delivery.CallbackURL, delivery.ContentType, []byte(delivery.Payload)); err != nil {
+		// The receiver got the payload when it answered otherwise, so it must not be sent again.
+		var deliveryErr *outgoingWebhookDeliveryError
+		if !errors.As(err, &deliveryErr) {
+			return fmt.Errorf("%w: %w", errOutgoingWebhookRejected, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// RetryOutgoingWebhookDeliveries retries the failed outgoing webhook deliveries that are due and
+// removes finished deliveries past their retention period.
+func (a *App) RetryOutgoingWebhookDeliveries() error {
+	rctx := request.EmptyContext(a.Log())
+
+	deliveries, err := a.Srv().Store().OutgoingWebhookDelivery().GetDue(model.GetMillis(), outgoingWebhookDeliveryRetryBatchSize)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, outgoingWebhookDeliveryRetryConcurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			updated, err := a.attemptOutgoingWebhookDelivery(rctx, delivery)
+			if err != nil {
+				rctx.Logger().Error("Failed to update outgoing webhook delivery", mlog.String("delivery_id", delivery.Id), mlog.Err(err))
+				return
+			}
+
+			if updated.Status == model.OutgoingWebhookDeliveryStatusDead {
+				rctx.Logger().Warn("Outgoing webhook delivery exhausted its retries",
+					mlog.String("delivery_id", updated.Id),
+					mlog.String("outgoing_webhook_id", updated.HookId),
+					mlog.Int("attempts", updated.Attempts),
+					mlog.String("last_error", updated.LastError),
+				)
+			}
+		}()
+	}
+	wg.Wait()
+
+	cutoff := model.GetMillisForTime(time.Now().Add(-model.OutgoingWebhookDeliveryRetention))
+	if _, err := a.Srv().Store().OutgoingWebhookDelivery().PermanentDeleteFinishedOlderThan(cutoff, outgoingWebhookDeliveryCleanupBatchSize); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *App) GetOutgoingWebhookDeliveries(hookID, status string, page, perPage int) ([]*model.OutgoingWebhookDelivery, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("GetOutgoingWebhookDeliveries", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	deliveries, err := a.Srv().Store().OutgoingWebhookDelivery().GetForHook(hookID, status, page*perPage, perPage)
+	if err != nil {
+		return nil, model.NewAppError("GetOutgoingWebhookDeliveries", "app.webhooks.get_outgoing_deliveries.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return deliveries, nil
+}
+
+func (a *App) GetOutgoingWebhookDelivery(deliveryID string) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("GetOutgoingWebhookDelivery", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	delivery, err := a.Srv().Store().OutgoingWebhookDelivery().Get(deliveryID)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, model.NewAppError("GetOutgoingWebhookDelivery", "app.webhooks.get_outgoing_delivery.app_error", nil, "", http.StatusNotFound).Wrap(err)
+		default:
+			return nil, model.NewAppError("GetOutgoingWebhookDelivery", "app.webhooks.get_outgoing_delivery.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return delivery, nil
+}
+
+// RedeliverOutgoingWebhookDelivery immediately sends a queued delivery again, regardless of its
+// status or next scheduled attempt, and returns the delivery with the recorded outcome.
+func (a *App) RedeliverOutgoingWebhookDelivery(rctx request.CTX, delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("RedeliverOutgoingWebhookDelivery", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	updated, err := a.attemptOutgoingWebhookDelivery(rctx, delivery)
+	if err != nil {
+		return nil, model.NewAppError("RedeliverOutgoingWebhookDelivery", "app.webhooks.update_outgoing_delivery.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestRetryOutgoingWebhookDeliveries(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableOutgoingWebhooks = true
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "localhost,127.0.0.1"
+	})
+
+	var failing atomic.Bool
+	failing.Store(true)
+	var received atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	createHook := func(t *testing.T) (*model.OutgoingWebhook, *model.Channel) {
+		channel := th.CreateChannel(th.Context, th.BasicTeam)
+		hook, appErr := th.App.CreateOutgoingWebhook(&model.OutgoingWebhook{
+			ChannelId:    channel.Id,
+			TeamId:       channel.TeamId,
+			CallbackURLs: []string{ts.URL},
+			CreatorId:    th.BasicUser.Id,
+			TriggerWords: []string{"Abracadabra"},
+			ContentType:  "application/json",
+		})
+		require.Nil(t, appErr)
+		return hook, channel
+	}
+
+	triggerAndWaitForDelivery := func(t *testing.T, hook *model.OutgoingWebhook, channel *model.Channel) *model.OutgoingWebhookDelivery {
+		payload := &model.OutgoingWebhookPayload{
+			Token:     hook.Token,
+			TeamId:    hook.TeamId,
+			ChannelId: channel.Id,
+			PostId:    th.BasicPost.Id,
+			Text:      "Abracadabra",
+		}
+		th.App.TriggerWebhook(th.Context, payload, hook, th.BasicPost, channel)
+
+		var delivery *model.OutgoingWebhookDelivery
+		require.Eventually(t, func() bool {
+			deliveries, appErr := th.App.GetOutgoingWebhookDeliveries(hook.Id, "", 0, 10)
+			require.Nil(t, appErr)
+			if len(deliveries) != 1 {
+				return false
+			}
+			delivery = deliveries[0]
+			return true
+		}, 5*time.Second, 50*time.Millisecond)
+
+		return delivery
+	}
+
+	makeDue := func(t *testing.T, delivery *model.OutgoingWebhookDelivery) {
+		delivery.NextAttemptAt = 1
+		_, err := th.App.Srv().Store().OutgoingWebhookDelivery().Update(delivery)
+		require.NoError(t, err)
+	}
+
+	t.Run("failed delivery is queued and retried", func(t *testing.T) {
+		failing.Store(true)
+		hook, channel := createHook(t)
+
+		delivery := triggerAndWaitForDelivery(t, hook, channel)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, channel.Id, delivery.ChannelId)
+		assert.Equal(t, ts.URL, delivery.CallbackURL)
+		assert.NotEmpty(t, delivery.LastError)
+		assert.Greater(t, delivery.NextAttemptAt, model.GetMillis())
+
+		// Not due yet, so it must not be retried.
+		before := received.Load()
+		require.NoError(t, th.App.RetryOutgoingWebhookDeliveries())
+		assert.Equal(t, before, received.Load())
+
+		failing.Store(false)
+		makeDue(t, delivery)
+		require.NoError(t, th.App.RetryOutgoingWebhookDeliveries())
+
+		delivery, appErr := th.App.GetOutgoingWebhookDelivery(delivery.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusDelivered, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Empty(t, delivery.LastError)
+	})
+
+	t.Run("delivery of a deleted hook is dead lettered", func(t *testing.T) {
+		failing.Store(true)
+		hook, channel := createHook(t)
+
+		delivery := triggerAndWaitForDelivery(t, hook, channel)
+		require.Nil(t, th.App.DeleteOutgoingWebhook(hook.Id))
+
+		makeDue(t, delivery)
+		require.NoError(t, th.App.RetryOutgoingWebhookDeliveries())
+
+		delivery, appErr := th.App.GetOutgoingWebhookDelivery(delivery.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusDead, delivery.Status)
+	})
+
+	t.Run("delivery to a removed callback URL is dead lettered", func(t *testing.T) {
+		failing.Store(true)
+		hook, channel := createHook(t)
+
+		delivery := triggerAndWaitForDelivery(t, hook, channel)
+
+		updatedHook := *hook
+		updatedHook.CallbackURLs = []string{ts.URL + "/other"}
+		_, appErr := th.App.UpdateOutgoingWebhook(th.Context, hook, &updatedHook)
+		require.Nil(t, appErr)
+
+		before := received.Load()
+		makeDue(t, delivery)
+		require.NoError(t, th.App.RetryOutgoingWebhookDeliveries())
+		assert.Equal(t, before, received.Load())
+
+		delivery, appErr = th.App.GetOutgoingWebhookDelivery(delivery.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusDead, delivery.Status)
+	})
+
+	t.Run("delivered request answered with plain text isn't queued", func(t *testing.T) {
+		var plainReceived atomic.Int32
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			plainReceived.Add(1)
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer plain.Close()
+
+		hook, channel := createHook(t)
+		updatedHook := *hook
+		updatedHook.CallbackURLs = []string{plain.URL}
+		hook, appErr := th.App.UpdateOutgoingWebhook(th.Context, hook, &updatedHook)
+		require.Nil(t, appErr)
+
+		payload := &model.OutgoingWebhookPayload{
+			Token:     hook.Token,
+			TeamId:    hook.TeamId,
+			ChannelId: channel.Id,
+			PostId:    th.BasicPost.Id,
+			Text:      "Abracadabra",
+		}
+		th.App.TriggerWebhook(th.Context, payload, hook, th.BasicPost, channel)
+		assert.EqualValues(t, 1, plainReceived.Load())
+
+		deliveries, appErr := th.App.GetOutgoingWebhookDeliveries(hook.Id, "", 0, 10)
+		require.Nil(t, appErr)
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		failing.Store(true)
+		hook, channel := createHook(t)
+
+		delivery := triggerAndWaitForDelivery(t, hook, channel)
+
+		delivery, appErr := th.App.RedeliverOutgoingWebhookDelivery(th.Context, delivery)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+
+		failing.Store(false)
+		delivery, appErr = th.App.RedeliverOutgoingWebhookDelivery(th.Context, delivery)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.OutgoingWebhookDeliveryStatusDelivered, delivery.Status)
+	})
+}
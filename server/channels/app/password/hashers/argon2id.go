@@ -0,0 +1,236 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package hashers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/mattermost/mattermost/server/v8/channels/app/password/phcparser"
+)
+
+const (
+	// Argon2idFunctionId is the name of the Argon2id hasher.
+	Argon2idFunctionId string = "argon2id"
+)
+
+const (
+	// Default parameter values, following the OWASP recommendations:
+	// https://cheatsheetseries.owasp.org/cheatsheets/Password_Storage_Cheat_Sheet.html#argon2id
+	defaultArgon2idMemory      = 19 * 1024
+	defaultArgon2idIterations  = 2
+	defaultArgon2idParallelism = 1
+	defaultArgon2idKeyLength   = 32
+
+	// Minimum amount of memory, in KiB, per thread required by Argon2
+	argon2idMinMemoryPerThread = 8
+)
+
+var (
+	argon2idVersion = strconv.Itoa(argon2.Version)
+)
+
+// Argon2id implements the [PasswordHasher] interface using the Argon2id variant
+// of [golang.org/x/crypto/argon2] as the hashing method.
+//
+// It is parametrized by:
+//   - The memory: the amount of memory, in KiB, used during hashing.
+//   - The iterations: the number of passes performed over the memory.
+//   - The parallelism: the number of threads used during hashing.
+//
+// The key length is always set to 32 bytes.
+//
+// Its PHC string is of the form:
+//
+//	$argon2id$v=19$m=<M>,t=<T>,p=<P>$<salt>$<hash>
+//
+// Where:
+//   - <M> is an integer specifying the memory in KiB (defaults to 19456).
+//   - <T> is an integer specifying the iterations (defaults to 2).
+//   - <P> is an integer specifying the parallelism (defaults to 1).
+//   - <salt> is the base64-encoded salt.
+//   - <hash> is the base64-encoded hash.
+type Argon2id struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	keyLength   uint32
+
+	phcHeader string
+}
+
+// DefaultArgon2id returns an [Argon2id] already initialized with the following
+// parameters:
+//   - Memory: 19 MiB
+//   - Iterations: 2
+//   - Parallelism: 1
+func DefaultArgon2id() Argon2id {
+	hasher, err := NewArgon2id(defaultArgon2idMemory, defaultArgon2idIterations, defaultArgon2idParallelism)
+	if err != nil {
+		panic("DefaultArgon2id implementation is incorrect")
+	}
+	return hasher
+}
+
+// NewArgon2id returns an [Argon2id] initialized with the provided parameters.
+func NewArgon2id(memory uint32, iterations uint32, parallelism uint8) (Argon2id, error) {
+	return newArgon2id(memory, iterations, parallelism, defaultArgon2idKeyLength)
+}
+
+func newArgon2id(memory uint32, iterations uint32, parallelism uint8, keyLength uint32) (Argon2id, error) {
+	if iterations == 0 {
+		return Argon2id{}, fmt.Errorf("iterations must be strictly positive")
+	}
+
+	if parallelism == 0 {
+		return Argon2id{}, fmt.Errorf("parallelism must be strictly positive")
+	}
+
+	if memory < argon2idMinMemoryPerThread*uint32(parallelism) {
+		return Argon2id{}, fmt.Errorf("memory must be at least %d KiB per thread", argon2idMinMemoryPerThread)
+	}
+
+	if keyLength == 0 {
+		return Argon2id{}, fmt.Errorf("key length must be strictly positive")
+	}
+
+	// Precompute and store the PHC header, since it is common to every hashed
+	// password; it will be something like:
+	// $argon2id$v=19$m=19456,t=2,p=1$
+	phcHeader := new(strings.Builder)
+
+	// First, the function ID and version
+	phcHeader.WriteRune('$')
+	phcHeader.WriteString(Argon2idFunctionId)
+	phcHeader.WriteString("$v=")
+	phcHeader.WriteString(argon2idVersion)
+
+	// Then, the parameters
+	phcHeader.WriteString("$m=")
+	phcHeader.WriteString(strconv.FormatUint(uint64(memory), 10))
+	phcHeader.WriteString(",t=")
+	phcHeader.WriteString(strconv.FormatUint(uint64(iterations), 10))
+	phcHeader.WriteString(",p=")
+	phcHeader.WriteString(strconv.FormatUint(uint64(parallelism), 10))
+
+	// Finish with the '$' that will mark the start of the salt
+	phcHeader.WriteRune('$')
+
+	return Argon2id{
+		memory:      memory,
+		iterations:  iterations,
+		parallelism: parallelism,
+		keyLength:   keyLength,
+		phcHeader:   phcHeader.String(),
+	}, nil
+}
+
+// NewArgon2idFromPHC returns an [Argon2id] that conforms to the provided parsed
+// PHC, using the same parameters (if valid) present there. The key length is
+// inferred from the length of the stored hash.
+func NewArgon2idFromPHC(phc phcparser.PHC) (Argon2id, error) {
+	if phc.Version != argon2idVersion {
+		return Argon2id{}, fmt.Errorf("unsupported version 'v=%s'", phc.Version)
+	}
+
+	memory, err := strconv.ParseUint(phc.Params["m"], 10, 32)
+	if err != nil {
+		return Argon2id{}, fmt.Errorf("invalid memory parameter 'm=%s'", phc.Params["m"])
+	}
+
+	iterations, err := strconv.ParseUint(phc.Params["t"], 10, 32)
+	if err != nil {
+		return Argon2id{}, fmt.Errorf("invalid iterations parameter 't=%s'", phc.Params["t"])
+	}
+
+	parallelism, err := strconv.ParseUint(phc.Params["p"], 10, 8)
+	if err != nil {
+		return Argon2id{}, fmt.Errorf("invalid parallelism parameter 'p=%s'", phc.Params["p"])
+	}
+
+	keyLength := base64.RawStdEncoding.DecodedLen(len(phc.Hash))
+
+	return newArgon2id(uint32(memory), uint32(iterations), uint8(parallelism), uint32(keyLength))
+}
+
+// hashWithSalt calls argon2.IDKey with the provided salt and the stored
+// parameters.
+func (a Argon2id) hashWithSalt(password string, salt []byte) string {
+	hash := argon2.IDKey([]byte(password), salt, a.iterations, a.memory, a.parallelism, a.keyLength)
+	return base64.RawStdEncoding.EncodeToString(hash)
+}
+
+// Hash hashes the provided password using the Argon2id algorithm with the
+// stored parameters, returning a PHC-compliant string.
+//
+// The salt is generated randomly and stored in the returned PHC string. If the
+// provided password is longer than [PasswordMaxLengthBytes], [ErrPasswordTooLong]
+// is returned.
+func (a Argon2id) Hash(password string) (string, error) {
+	// Enforce a maximum length, even if Argon2id can accept much longer inputs
+	if len(password) > PasswordMaxLengthBytes {
+		return "", ErrPasswordTooLong
+	}
+
+	// Create random salt
+	salt := make([]byte, saltLenBytes)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", fmt.Errorf("unable to generate salt for user: %w", err)
+	}
+
+	phcString := new(strings.Builder)
+	phcString.WriteString(a.phcHeader)
+	phcString.WriteString(base64.RawStdEncoding.EncodeToString(salt))
+	phcString.WriteRune('$')
+	phcString.WriteString(a.hashWithSalt(password, salt))
+
+	return phcString.String(), nil
+}
+
+// CompareHashAndPassword compares the provided [phcparser.PHC] with the plain-text
+// password.
+//
+// The provided [phcparser.PHC] is validated to double-check it was generated with
+// this hasher and parameters.
+func (a Argon2id) CompareHashAndPassword(hash phcparser.PHC, password string) error {
+	// Validate parameters
+	if !a.IsPHCValid(hash) {
+		return fmt.Errorf("the stored password does not comply with the Argon2id parser's PHC serialization")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(hash.Salt)
+	if err != nil {
+		return fmt.Errorf("failed decoding hash's salt: %w", err)
+	}
+
+	// Hash the new password with the stored hash's salt and compare both hashes
+	newHash := a.hashWithSalt(password, salt)
+	if subtle.ConstantTimeCompare([]byte(hash.Hash), []byte(newHash)) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+
+	return nil
+}
+
+// IsPHCValid validates that the provided [phcparser.PHC] is valid, meaning:
+//   - The function used to generate it was [Argon2idFunctionId], with the
+//     version implemented by [golang.org/x/crypto/argon2].
+//   - The parameters and key length used to generate it were the same as the
+//     ones used to create this hasher.
+func (a Argon2id) IsPHCValid(phc phcparser.PHC) bool {
+	return phc.Id == Argon2idFunctionId &&
+		phc.Version == argon2idVersion &&
+		len(phc.Params) == 3 &&
+		phc.Params["m"] == strconv.FormatUint(uint64(a.memory), 10) &&
+		phc.Params["t"] == strconv.FormatUint(uint64(a.iterations), 10) &&
+		phc.Params["p"] == strconv.FormatUint(uint64(a.parallelism), 10) &&
+		base64.RawStdEncoding.DecodedLen(len(phc.Hash)) == int(a.keyLength)
+}
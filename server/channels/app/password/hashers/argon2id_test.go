@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package hashers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/v8/channels/app/password/phcparser"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+)
+
+func TestArgon2idHash(t *testing.T) {
+	password := "^a v3ery c0mp_ex Passw∙rd$"
+
+	hasher, err := NewArgon2id(1024, 3, 2)
+	require.NoError(t, err)
+
+	str, err := hasher.Hash(password)
+	require.NoError(t, err)
+
+	phc, err := phcparser.New(strings.NewReader(str)).Parse()
+	require.NoError(t, err)
+	require.Equal(t, "argon2id", phc.Id)
+	require.Equal(t, "19", phc.Version)
+	require.Equal(t, map[string]string{
+		"m": "1024",
+		"t": "3",
+		"p": "2",
+	}, phc.Params)
+
+	salt, err := base64.RawStdEncoding.DecodeString(phc.Salt)
+	require.NoError(t, err)
+
+	hash := argon2.IDKey([]byte(password), salt, 3, 1024, 2, 32)
+
+	expectedHash := base64.RawStdEncoding.EncodeToString(hash)
+	require.Equal(t, expectedHash, phc.Hash)
+
+	_, err = hasher.Hash(strings.Repeat("a", PasswordMaxLengthBytes+1))
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+}
+
+func TestNewArgon2id(t *testing.T) {
+	testCases := []struct {
+		testName    string
+		memory      uint32
+		iterations  uint32
+		parallelism uint8
+		expectedErr bool
+	}{
+		{"valid", 1024, 1, 1, false},
+		{"no iterations", 1024, 0, 1, true},
+		{"no parallelism", 1024, 1, 0, true},
+		{"not enough memory per thread", 31, 1, 4, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			_, err := NewArgon2id(tc.memory, tc.iterations, tc.parallelism)
+			if tc.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestArgon2idCompareHashAndPassword(t *testing.T) {
+	testCases := []struct {
+		testName    string
+		storedPwd   string
+		inputPwd    string
+		expectedErr error
+	}{
+		{
+			"empty password",
+			"",
+			"",
+			nil,
+		},
+		{
+			"same password",
+			"one password",
+			"one password",
+			nil,
+		},
+		{
+			"different password",
+			"one password",
+			"another password",
+			ErrMismatchedHashAndPassword,
+		},
+	}
+
+	hasher, err := NewArgon2id(1024, 1, 1)
+	require.NoError(t, err)
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			storedPHCStr, err := hasher.Hash(tc.storedPwd)
+			require.NoError(t, err)
+
+			storedPHC, err := phcparser.New(strings.NewReader(storedPHCStr)).Parse()
+			require.NoError(t, err)
+
+			err = hasher.CompareHashAndPassword(storedPHC, tc.inputPwd)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("different parameters", func(t *testing.T) {
+		other, err := NewArgon2id(2048, 1, 1)
+		require.NoError(t, err)
+
+		storedPHCStr, err := other.Hash("one password")
+		require.NoError(t, err)
+
+		storedPHC, err := phcparser.New(strings.NewReader(storedPHCStr)).Parse()
+		require.NoError(t, err)
+
+		err = hasher.CompareHashAndPassword(storedPHC, "one password")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrMismatchedHashAndPassword)
+	})
+}
@@ -28,6 +28,9 @@
 // is needed. Simply update the [latestHasher] varible with the new parameter,
 // and [IsPHCValid] will detect the difference in the parameter.
 //
+// The server can hash new and migrated passwords with another hasher, e.g.
+// [Argon2id], chosen in the password settings; see [Server.configurePasswordHasher].
+//
 // Note that the migration happens in [App.migratePassword], which is triggered
 // whenever the user enters their password and an old hashing method is
 // identified when parsing their stored hashed password.
@@ -126,6 +129,12 @@ func GetHasherFromPHCString(phcString string) (PasswordHasher, phcparser.PHC, er
 			return PBKDF2{}, phcparser.PHC{}, fmt.Errorf("the provided PHC string is PBKDF2, but is not valid: %w", err)
 		}
 		return pbkdf2, phc, nil
+	case Argon2idFunctionId:
+		argon2id, err := NewArgon2idFromPHC(phc)
+		if err != nil {
+			return Argon2id{}, phcparser.PHC{}, fmt.Errorf("the provided PHC string is Argon2id, but is not valid: %w", err)
+		}
+		return argon2id, phc, nil
 	// If the function ID is unknown, return the original hasher
 	default:
 		bcrypt, phc := getOriginalHasher(phcString)
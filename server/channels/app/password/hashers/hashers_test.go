@@ -56,6 +56,36 @@ func TestGetHasherFromPHCString(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			testName: "valid Argon2id",
+			input:    "$argon2id$v=19$m=19456,t=2,p=1$5Zq8TvET7nMrXof49Rp4Sw$d0Mx8467kv+3ylbGrkyu4jTd8O8SP51k4s1RuWb9S/o",
+			expectedHasher: Argon2id{
+				memory:      19456,
+				iterations:  2,
+				parallelism: 1,
+				keyLength:   32,
+				phcHeader:   "$argon2id$v=19$m=19456,t=2,p=1$",
+			},
+			expectedPHC: phcparser.PHC{
+				Id:      "argon2id",
+				Version: "19",
+				Params: map[string]string{
+					"m": "19456",
+					"t": "2",
+					"p": "1",
+				},
+				Salt: "5Zq8TvET7nMrXof49Rp4Sw",
+				Hash: "d0Mx8467kv+3ylbGrkyu4jTd8O8SP51k4s1RuWb9S/o",
+			},
+			expectedErr: false,
+		},
+		{
+			testName:       "Argon2id with unsupported version",
+			input:          "$argon2id$v=16$m=19456,t=2,p=1$5Zq8TvET7nMrXof49Rp4Sw$d0Mx8467kv+3ylbGrkyu4jTd8O8SP51k4s1RuWb9S/o",
+			expectedHasher: Argon2id{},
+			expectedPHC:    phcparser.PHC{},
+			expectedErr:    true,
+		},
 		{
 			testName:       "valid bcrypt",
 			input:          "$2a$10$z0OlN1MpiLVlLTyE1xtEjOJ6/xV95RAwwIUaYKQBAqoeyvPgLEnUa",
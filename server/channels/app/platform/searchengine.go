@@ -17,6 +17,14 @@ func (ps *PlatformService) StartSearchEngine() (string, string) {
 		})
 	}
 
+	if ps.SearchEngine.EmbeddedEngine != nil && ps.SearchEngine.EmbeddedEngine.IsEnabled() {
+		ps.Go(func() {
+			if err := ps.SearchEngine.EmbeddedEngine.Start(); err != nil {
+				ps.Log().Error(err.Error())
+			}
+		})
+	}
+
 	configListenerId := ps.AddConfigListener(func(oldConfig *model.Config, newConfig *model.Config) {
 		if ps.SearchEngine == nil {
 			return
@@ -26,6 +34,24 @@ func (ps *PlatformService) StartSearchEngine() (string, string) {
 			ps.Log().Error("Failed to update search engine config", mlog.Err(err))
 		}
 
+		if ps.SearchEngine.EmbeddedEngine != nil {
+			oldSettings, newSettings := oldConfig.EmbeddedSearchSettings, newConfig.EmbeddedSearchSettings
+			if *oldSettings.EnableIndexing != *newSettings.EnableIndexing || *oldSettings.IndexDir != *newSettings.IndexDir {
+				ps.Go(func() {
+					if *oldSettings.EnableIndexing {
+						if err := ps.SearchEngine.EmbeddedEngine.Stop(); err != nil {
+							ps.Log().Error(err.Error())
+						}
+					}
+					if *newSettings.EnableIndexing {
+						if err := ps.SearchEngine.EmbeddedEngine.Start(); err != nil {
+							ps.Log().Error(err.Error())
+						}
+					}
+				})
+			}
+		}
+
 		if ps.SearchEngine.ElasticsearchEngine != nil && !*oldConfig.ElasticsearchSettings.EnableIndexing && *newConfig.ElasticsearchSettings.EnableIndexing {
 			ps.Go(func() {
 				if err := ps.SearchEngine.ElasticsearchEngine.Start(); err != nil {
@@ -86,4 +112,9 @@ func (ps *PlatformService) StopSearchEngine() {
 			ps.Log().Error("Failed to stop Elasticsearch engine", mlog.Err(err))
 		}
 	}
+	if ps.SearchEngine != nil && ps.SearchEngine.EmbeddedEngine != nil && ps.SearchEngine.EmbeddedEngine.IsActive() {
+		if err := ps.SearchEngine.EmbeddedEngine.Stop(); err != nil {
+			ps.Log().Error("Failed to stop the embedded search engine", mlog.Err(err))
+		}
+	}
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
 	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
@@ -216,6 +217,19 @@ func New(sc ServiceConfig, options ...Option) (*PlatformService, error) {
 				DisableCache:     *cacheConfig.DisableClientCache,
 			},
 		)
+	} else if *cacheConfig.CacheType == model.CacheTypeHybrid {
+		ps.cacheProvider, err = cache.NewHybridProvider(
+			&cache.HybridOptions{
+				RedisOptions: cache.RedisOptions{
+					RedisAddr:        *cacheConfig.RedisAddress,
+					RedisPassword:    *cacheConfig.RedisPassword,
+					RedisDB:          *cacheConfig.RedisDB,
+					RedisCachePrefix: *cacheConfig.RedisCachePrefix,
+				},
+				LocalCachePercentage: *cacheConfig.HybridLocalCachePercentage,
+				Logger:               ps.Log(),
+			},
+		)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("unable to create cache provider: %w", err)
@@ -230,6 +244,8 @@ func New(sc ServiceConfig, options ...Option) (*PlatformService, error) {
 
 	// Step 3: Search Engine
 	searchEngine := searchengine.NewBroker(ps.Config())
+	embeddedEngine := embeddedengine.NewEmbeddedEngine(ps)
+	searchEngine.RegisterEmbeddedEngine(embeddedEngine)
 	ps.SearchEngine = searchEngine
 
 	// Step 4: Init Enterprise
@@ -338,7 +354,8 @@ func New(sc ServiceConfig, options ...Option) (*PlatformService, error) {
 	// if the license didn't have clustering. But there's an intricate deadlock
 	// where license cannot be loaded before store, and store cannot be loaded before
 	// cache. So loading license before loading cache is an uphill battle.
-	if (license == nil || !*license.Features.Cluster) && *cacheConfig.CacheType == model.CacheTypeRedis && !ps.forceEnableRedis {
+	usesRedis := *cacheConfig.CacheType == model.CacheTypeRedis || *cacheConfig.CacheType == model.CacheTypeHybrid
+	if (license == nil || !*license.Features.Cluster) && usesRedis && !ps.forceEnableRedis {
 		return nil, fmt.Errorf("Redis cannot be used in an instance without a license or a license without clustering")
 	}
 
@@ -350,6 +367,15 @@ func New(sc ServiceConfig, options ...Option) (*PlatformService, error) {
 			return nil, fmt.Errorf("failed to initialize filebackend: %w", err2)
 		}
 
+		if *ps.Config().FileSettings.EnableMigrationCutover {
+			mlog.Info("Setting up the file storage migration cutover", mlog.String("driver_name", *ps.Config().FileSettings.MigrationDriverName))
+			target, errTarget := filestore.NewFileBackend(filestore.NewMigrationFileBackendSettingsFromConfig(&ps.Config().FileSettings, license != nil && *license.Features.Compliance, insecure != nil && *insecure))
+			if errTarget != nil {
+				return nil, fmt.Errorf("failed to initialize migration target filebackend: %w", errTarget)
+			}
+			backend = filestore.NewDualReadFileBackend(target, backend)
+		}
+
 		ps.filestore = backend
 	}
 
@@ -0,0 +1,231 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+// CreatePoll posts a poll as the user, and saves it keyed by the ID of its post. The message
+// of the post is the question of the poll, so that the poll is searched and notified as the
+// other posts.
+func (a *App) CreatePoll(rctx request.CTX, pollRequest *model.PollRequest, userID string, setOnline bool) (*model.Poll, *model.AppError) {
+	poll := pollRequest.ToPoll()
+
+	// The ID of the post is only known once posted, the poll is checked with a stand-in.
+	poll.PostId = model.NewId()
+	poll.PreSave()
+	if appErr := poll.IsValid(); appErr != nil {
+		return nil, appErr
+	}
+
+	post, appErr := a.CreatePostAsUser(rctx, &model.Post{
+		UserId:    userID,
+		ChannelId: pollRequest.ChannelId,
+		RootId:    pollRequest.RootId,
+		Type:      model.PostTypePoll,
+		Message:   poll.Question,
+	}, rctx.Session().Id, setOnline)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	poll.PostId = post.Id
+	poll.CreateAt = post.CreateAt
+	poll, err := a.Srv().Store().Poll().Save(poll)
+	if err != nil {
+		if _, appErr := a.DeletePost(rctx, post.Id, userID); appErr != nil {
+			rctx.Logger().Warn("Failed to delete the post of a poll which couldn't be saved", mlog.String("post_id", post.Id), mlog.Err(appErr))
+		}
+
+		var appErr *model.AppError
+		switch {
+		case errors.As(err, &appErr):
+			return nil, appErr
+		default:
+			return nil, model.NewAppError("CreatePoll", "app.poll.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	poll.SetTally(nil, userID)
+	a.sendPollUpdatedEvent(rctx, poll, post.ChannelId)
+
+	return poll, nil
+}
+
+// GetPoll returns the poll of a post with its tally, and the options voted for by the user.
+func (a *App) GetPoll(rctx request.CTX, postID, userID string) (*model.Poll, *model.AppError) {
+	poll, _, appErr := a.getPollForPost(rctx, postID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := a.tallyPoll(poll, userID); appErr != nil {
+		return nil, appErr
+	}
+
+	return poll, nil
+}
+
+// VotePoll replaces the votes of the user for the poll of a post.
+func (a *App) VotePoll(rctx request.CTX, postID, userID string, optionIDs []string) (*model.Poll, *model.AppError) {
+	poll, post, appErr := a.getOpenPollForPost(rctx, postID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := poll.IsValidVote(optionIDs); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := a.Srv().Store().Poll().SaveVotes(postID, userID, optionIDs); err != nil {
+		// The poll may have been closed since it was read.
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, model.NewAppError("VotePoll", "app.poll.closed.app_error", nil, "post_id="+postID, http.StatusBadRequest)
+		default:
+			return nil, model.NewAppError("VotePoll", "app.poll.vote.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return a.pollUpdated(rctx, poll, post, userID)
+}
+
+// RetractPollVotes removes the votes of the user for the poll of a post.
+func (a *App) RetractPollVotes(rctx request.CTX, postID, userID string) (*model.Poll, *model.AppError) {
+	poll, post, appErr := a.getOpenPollForPost(rctx, postID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if err := a.Srv().Store().Poll().DeleteVotes(postID, userID); err != nil {
+		// The poll may have been closed since it was read.
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, model.NewAppError("RetractPollVotes", "app.poll.closed.app_error", nil, "post_id="+postID, http.StatusBadRequest)
+		default:
+			return nil, model.NewAppError("RetractPollVotes", "app.poll.retract.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return a.pollUpdated(rctx, poll, post, userID)
+}
+
+// ClosePoll closes the poll of a post to new votes. Its votes are kept.
+func (a *App) ClosePoll(rctx request.CTX, postID, userID string) (*model.Poll, *model.AppError) {
+	poll, post, appErr := a.getOpenPollForPost(rctx, postID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	poll.ClosedAt = model.GetMillis()
+	if err := a.Srv().Store().Poll().Close(postID, poll.ClosedAt); err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, model.NewAppError("ClosePoll", "app.poll.closed.app_error", nil, "", http.StatusBadRequest)
+		default:
+			return nil, model.NewAppError("ClosePoll", "app.poll.close.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+	poll.UpdateAt = poll.ClosedAt
+
+	return a.pollUpdated(rctx, poll, post, userID)
+}
+
+// getPollForPost returns the poll of a post which isn't deleted, and the post.
+func (a *App) getPollForPost(rctx request.CTX, postID string) (*model.Poll, *model.Post, *model.AppError) {
+	post, appErr := a.GetSinglePost(rctx, postID, false)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if post.Type != model.PostTypePoll {
+		return nil, nil, model.NewAppError("getPollForPost", "app.poll.get.app_error", nil, "post_id="+postID, http.StatusNotFound)
+	}
+
+	poll, err := a.Srv().Store().Poll().Get(postID)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, nil, model.NewAppError("getPollForPost", "app.poll.get.app_error", nil, "post_id="+postID, http.StatusNotFound).Wrap(err)
+		default:
+			return nil, nil, model.NewAppError("getPollForPost", "app.poll.get.app_error", nil, "post_id="+postID, http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return poll, post, nil
+}
+
+// getOpenPollForPost returns the poll of a post, checking that it can still be voted for.
+func (a *App) getOpenPollForPost(rctx request.CTX, postID string) (*model.Poll, *model.Post, *model.AppError) {
+	poll, post, appErr := a.getPollForPost(rctx, postID)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if poll.IsClosed() {
+		return nil, nil, model.NewAppError("getOpenPollForPost", "app.poll.closed.app_error", nil, "post_id="+postID, http.StatusBadRequest)
+	}
+
+	channel, appErr := a.GetChannel(rctx, post.ChannelId)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	if channel.DeleteAt > 0 {
+		return nil, nil, model.NewAppError("getOpenPollForPost", "app.poll.archived_channel.app_error", nil, "post_id="+postID, http.StatusForbidden)
+	}
+
+	return poll, post, nil
+}
+
+func (a *App) tallyPoll(poll *model.Poll, userID string) *model.AppError {
+	votes, err := a.Srv().Store().Poll().GetVotes(poll.PostId)
+	if err != nil {
+		return model.NewAppError("tallyPoll", "app.poll.get_votes.app_error", nil, "post_id="+poll.PostId, http.StatusInternalServerError).Wrap(err)
+	}
+
+	poll.SetTally(votes, userID)
+	return nil
+}
+
+// pollUpdated tallies the updated poll, sends it to the members of the channel and returns
+// it to the user.
+func (a *App) pollUpdated(rctx request.CTX, poll *model.Poll, post *model.Post, userID string) (*model.Poll, *model.AppError) {
+	if appErr := a.tallyPoll(poll, userID); appErr != nil {
+		return nil, appErr
+	}
+
+	a.sendPollUpdatedEvent(rctx, poll, post.ChannelId)
+
+	return poll, nil
+}
+
+// sendPollUpdatedEvent sends the tally of the poll to the members of the channel, without
+// the votes of the user it was tallied for.
+func (a *App) sendPollUpdatedEvent(rctx request.CTX, poll *model.Poll, channelID string) {
+	tally := poll.Clone()
+	tally.MyVotes = nil
+
+	pollJSON, err := json.Marshal(tally)
+	if err != nil {
+		rctx.Logger().Warn("Failed to encode poll to JSON", mlog.Err(err))
+		return
+	}
+
+	message := model.NewWebSocketEvent(model.WebsocketEventPollUpdated, "", channelID, "", nil, "")
+	message.Add("poll", string(pollJSON))
+	a.Publish(message)
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestCreatePoll(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	t.Run("posts the poll", func(t *testing.T) {
+		poll, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+			ChannelId: th.BasicChannel.Id,
+			Question:  " Lunch? ",
+			Options:   []string{"Pizza", "Salad"},
+		}, th.BasicUser.Id, false)
+		require.Nil(t, appErr)
+		assert.Equal(t, "Lunch?", poll.Question)
+		require.Len(t, poll.Options, 2)
+		assert.Equal(t, "Pizza", poll.Options[0].Text)
+
+		post, appErr := th.App.GetSinglePost(th.Context, poll.PostId, false)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.PostTypePoll, post.Type)
+		assert.Equal(t, "Lunch?", post.Message)
+		assert.Equal(t, post.CreateAt, poll.CreateAt)
+	})
+
+	t.Run("invalid poll isn't posted", func(t *testing.T) {
+		postCount, err := th.App.Srv().Store().Post().AnalyticsPostCount(&model.PostCountOptions{TeamId: th.BasicTeam.Id})
+		require.NoError(t, err)
+
+		_, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+			ChannelId: th.BasicChannel.Id,
+			Question:  "Lunch?",
+			Options:   []string{"Pizza"},
+		}, th.BasicUser.Id, false)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "model.poll.is_valid.options.app_error", appErr.Id)
+
+		newPostCount, err := th.App.Srv().Store().Post().AnalyticsPostCount(&model.PostCountOptions{TeamId: th.BasicTeam.Id})
+		require.NoError(t, err)
+		assert.Equal(t, postCount, newPostCount)
+	})
+}
+
+func TestVotePoll(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	createPoll := func(anonymous, multipleChoice bool) *model.Poll {
+		t.Helper()
+		poll, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+			ChannelId:      th.BasicChannel.Id,
+			Question:       "Lunch?",
+			Options:        []string{"Pizza", "Salad", "Soup"},
+			Anonymous:      anonymous,
+			MultipleChoice: multipleChoice,
+		}, th.BasicUser.Id, false)
+		require.Nil(t, appErr)
+		return poll
+	}
+
+	t.Run("vote, change vote and retract", func(t *testing.T) {
+		poll := createPoll(false, false)
+		pizza, salad := poll.Options[0].Id, poll.Options[1].Id
+
+		poll, appErr := th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{pizza})
+		require.Nil(t, appErr)
+		_, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser2.Id, []string{pizza})
+		require.Nil(t, appErr)
+
+		poll, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{salad})
+		require.Nil(t, appErr)
+		assert.Equal(t, int64(2), poll.VoterCount)
+		assert.Equal(t, []string{th.BasicUser2.Id}, poll.Options[0].Voters)
+		assert.Equal(t, []string{th.BasicUser.Id}, poll.Options[1].Voters)
+		assert.Equal(t, []string{salad}, poll.MyVotes)
+
+		poll, appErr = th.App.RetractPollVotes(th.Context, poll.PostId, th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, int64(1), poll.VoterCount)
+		assert.Zero(t, poll.Options[1].VoteCount)
+		assert.Empty(t, poll.MyVotes)
+
+		poll, appErr = th.App.GetPoll(th.Context, poll.PostId, th.BasicUser2.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, []string{pizza}, poll.MyVotes)
+	})
+
+	t.Run("single choice", func(t *testing.T) {
+		poll := createPoll(false, false)
+
+		_, appErr := th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[0].Id, poll.Options[1].Id})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "model.poll.is_valid_vote.count.app_error", appErr.Id)
+
+		_, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{model.NewId()})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "model.poll.is_valid_vote.option.app_error", appErr.Id)
+	})
+
+	t.Run("multiple choice", func(t *testing.T) {
+		poll := createPoll(false, true)
+
+		poll, appErr := th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[0].Id, poll.Options[2].Id})
+		require.Nil(t, appErr)
+		assert.Equal(t, int64(1), poll.VoterCount)
+		assert.Equal(t, int64(1), poll.Options[0].VoteCount)
+		assert.Zero(t, poll.Options[1].VoteCount)
+		assert.Equal(t, int64(1), poll.Options[2].VoteCount)
+		assert.ElementsMatch(t, []string{poll.Options[0].Id, poll.Options[2].Id}, poll.MyVotes)
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		poll := createPoll(true, false)
+
+		poll, appErr := th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[0].Id})
+		require.Nil(t, appErr)
+		assert.Equal(t, int64(1), poll.Options[0].VoteCount)
+		assert.Empty(t, poll.Options[0].Voters)
+		assert.Equal(t, []string{poll.Options[0].Id}, poll.MyVotes)
+	})
+
+	t.Run("not a poll", func(t *testing.T) {
+		post := th.CreatePost(th.BasicChannel)
+
+		_, appErr := th.App.VotePoll(th.Context, post.Id, th.BasicUser.Id, []string{model.NewId()})
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	})
+
+	t.Run("closed poll", func(t *testing.T) {
+		poll := createPoll(false, false)
+		_, appErr := th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[0].Id})
+		require.Nil(t, appErr)
+
+		poll, appErr = th.App.ClosePoll(th.Context, poll.PostId, th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.True(t, poll.IsClosed())
+		assert.Equal(t, int64(1), poll.Options[0].VoteCount)
+
+		_, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser2.Id, []string{poll.Options[0].Id})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.poll.closed.app_error", appErr.Id)
+
+		_, appErr = th.App.RetractPollVotes(th.Context, poll.PostId, th.BasicUser.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.poll.closed.app_error", appErr.Id)
+
+		_, appErr = th.App.ClosePoll(th.Context, poll.PostId, th.BasicUser.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.poll.closed.app_error", appErr.Id)
+	})
+
+	t.Run("archived channel", func(t *testing.T) {
+		channel := th.CreateChannel(th.Context, th.BasicTeam)
+		poll, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+			ChannelId: channel.Id,
+			Question:  "Lunch?",
+			Options:   []string{"Pizza", "Salad"},
+		}, th.BasicUser.Id, false)
+		require.Nil(t, appErr)
+		require.Nil(t, th.App.DeleteChannel(th.Context, channel, th.BasicUser.Id))
+
+		_, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[0].Id})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.poll.archived_channel.app_error", appErr.Id)
+	})
+}
+
+func TestPollUpdatedEvent(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	messages, closeWS := connectFakeWebSocket(t, th, th.BasicUser2.Id, "", []model.WebsocketEventType{model.WebsocketEventPollUpdated})
+	defer closeWS()
+
+	receivePoll := func() *model.Poll {
+		t.Helper()
+		select {
+		case msg := <-messages:
+			assert.Equal(t, th.BasicChannel.Id, msg.GetBroadcast().ChannelId)
+			var poll model.Poll
+			require.NoError(t, json.Unmarshal([]byte(msg.GetData()["poll"].(string)), &poll))
+			return &poll
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "expected a poll update")
+			return nil
+		}
+	}
+
+	poll, appErr := th.App.CreatePoll(th.Context, &model.PollRequest{
+		ChannelId: th.BasicChannel.Id,
+		Question:  "Lunch?",
+		Options:   []string{"Pizza", "Salad"},
+		Anonymous: true,
+	}, th.BasicUser.Id, false)
+	require.Nil(t, appErr)
+	assert.Equal(t, poll.PostId, receivePoll().PostId)
+
+	_, appErr = th.App.VotePoll(th.Context, poll.PostId, th.BasicUser.Id, []string{poll.Options[1].Id})
+	require.Nil(t, appErr)
+
+	tally := receivePoll()
+	assert.Equal(t, int64(1), tally.VoterCount)
+	assert.Equal(t, int64(1), tally.Options[1].VoteCount)
+	// Neither the voters of the anonymous poll nor the votes of the voter are sent.
+	assert.Empty(t, tally.Options[1].Voters)
+	assert.Empty(t, tally.MyVotes)
+}
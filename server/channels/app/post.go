@@ -1725,27 +1725,7 @@ func (a *App) SearchPostsForUser(rctx request.CTX, terms string, userID string,
 		return nil, model.NewAppError("SearchPostsForUser", "store.sql_post.search.disabled", nil, fmt.Sprintf("teamId=%v userId=%v", teamID, userID), http.StatusNotImplemented)
 	}
 
-	finalParamsList := []*model.SearchParams{}
-
-	for _, params := range paramsList {
-		params.OrTerms = isOrSearch
-		params.IncludeDeletedChannels = includeDeletedChannels
-		// Don't allow users to search for "*"
-		if params.Terms != "*" {
-			// TODO: we have to send channel ids
-			// from the front-end. Otherwise it's not possible to distinguish
-			// from just the channel name at a cross-team level.
-			// Convert channel names to channel IDs
-			params.InChannels = a.convertChannelNamesToChannelIds(rctx, params.InChannels, userID, teamID, includeDeletedChannels)
-			params.ExcludedChannels = a.convertChannelNamesToChannelIds(rctx, params.ExcludedChannels, userID, teamID, includeDeletedChannels)
-
-			// Convert usernames to user IDs
-			params.FromUsers = a.convertUserNameToUserIds(rctx, params.FromUsers)
-			params.ExcludedUsers = a.convertUserNameToUserIds(rctx, params.ExcludedUsers)
-
-			finalParamsList = append(finalParamsList, params)
-		}
-	}
+	finalParamsList := a.prepareSearchParamsForUser(rctx, paramsList, userID, teamID, isOrSearch, includeDeletedChannels)
 
 	// If the processed search params are empty, return empty search results.
 	if len(finalParamsList) == 0 {
@@ -1770,6 +1750,34 @@ func (a *App) SearchPostsForUser(rctx request.CTX, terms string, userID string,
 	return postSearchResults, nil
 }
 
+// prepareSearchParamsForUser sets the search options on the parsed search params, and converts
+// their channel names and usernames to IDs. The params searching for "*" are dropped.
+func (a *App) prepareSearchParamsForUser(rctx request.CTX, paramsList []*model.SearchParams, userID, teamID string, isOrSearch, includeDeletedChannels bool) []*model.SearchParams {
+	finalParamsList := []*model.SearchParams{}
+
+	for _, params := range paramsList {
+		params.OrTerms = isOrSearch
+		params.IncludeDeletedChannels = includeDeletedChannels
+		// Don't allow users to search for "*"
+		if params.Terms != "*" {
+			// TODO: we have to send channel ids
+			// from the front-end. Otherwise it's not possible to distinguish
+			// from just the channel name at a cross-team level.
+			// Convert channel names to channel IDs
+			params.InChannels = a.convertChannelNamesToChannelIds(rctx, params.InChannels, userID, teamID, includeDeletedChannels)
+			params.ExcludedChannels = a.convertChannelNamesToChannelIds(rctx, params.ExcludedChannels, userID, teamID, includeDeletedChannels)
+
+			// Convert usernames to user IDs
+			params.FromUsers = a.convertUserNameToUserIds(rctx, params.FromUsers)
+			params.ExcludedUsers = a.convertUserNameToUserIds(rctx, params.ExcludedUsers)
+
+			finalParamsList = append(finalParamsList, params)
+		}
+	}
+
+	return finalParamsList
+}
+
 func (a *App) GetFileInfosForPostWithMigration(rctx request.CTX, postID string, includeDeleted bool) ([]*model.FileInfo, *model.AppError) {
 	pchan := make(chan store.StoreResult[*model.Post], 1)
 	go func() {
@@ -0,0 +1,353 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/services/embeddings"
+)
+
+const (
+	// postEmbeddingMaxBatches bounds the batches of posts embedded by a run of the job, so
+	// that enabling the semantic search on a large database is spread over several runs.
+	postEmbeddingMaxBatches = 50
+
+	// hybridSearchRankConstant dampens the weight of the top ranks when merging the rankings
+	// of the full text and semantic searches, as is usual for reciprocal rank fusion.
+	hybridSearchRankConstant = 60
+)
+
+// searchTermsReplacer removes the quotes and wildcards of the search terms, which don't mean
+// anything to the embedding models.
+var searchTermsReplacer = strings.NewReplacer(`"`, "", "*", "", "#", "")
+
+func (a *App) embeddingProvider() (embeddings.EmbeddingProvider, error) {
+	settings := a.Config().SemanticSearchSettings
+	return embeddings.New(embeddings.Settings{
+		Provider:   *settings.EmbeddingProvider,
+		URL:        *settings.EmbeddingURL,
+		APIKey:     *settings.EmbeddingAPIKey,
+		Model:      *settings.EmbeddingModel,
+		Dimensions: *settings.EmbeddingDimensions,
+		Timeout:    time.Duration(*settings.RequestTimeoutMs) * time.Millisecond,
+	})
+}
+
+// IndexPostEmbeddings embeds the posts created or edited since the last run, and removes the
+// embeddings of the deleted posts. The first run embeds the existing posts, over as many
+// runs as needed.
+func (a *App) IndexPostEmbeddings() error {
+	rctx := request.EmptyContext(a.Log().With(mlog.String("component", "post_embedding")))
+
+	provider, err := a.embeddingProvider()
+	if err != nil {
+		return err
+	}
+
+	if err := a.setPostEmbeddingDimensions(rctx, provider.Dimensions()); err != nil {
+		return err
+	}
+
+	cursor, err := a.getPostEmbeddingCursor()
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if !errors.As(err, &nfErr) {
+			return err
+		}
+	}
+
+	batchSize := *a.Config().SemanticSearchSettings.BatchSize
+	for range postEmbeddingMaxBatches {
+		posts, nextCursor, err := a.Srv().Store().Post().GetPostsSinceForSync(model.GetPostsSinceForSyncOptions{IncludeDeleted: true}, cursor, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get posts to embed: %w", err)
+		}
+		if len(posts) == 0 {
+			return nil
+		}
+
+		if err := a.embedPosts(rctx, provider, posts); err != nil {
+			return err
+		}
+
+		cursor = nextCursor
+		if err := a.savePostEmbeddingCursor(cursor); err != nil {
+			return err
+		}
+
+		if len(posts) < batchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// embedPosts saves the embeddings of the posts whose message changed since they were
+// embedded, and removes those of the posts which aren't searched.
+func (a *App) embedPosts(rctx request.CTX, provider embeddings.EmbeddingProvider, posts []*model.Post) error {
+	postIDs := make([]string, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.Id
+	}
+	existing, err := a.Srv().Store().PostEmbedding().GetForPosts(postIDs, provider.Model())
+	if err != nil {
+		return fmt.Errorf("failed to get the embeddings of the posts: %w", err)
+	}
+	embedded := make(map[string]*model.PostEmbedding, len(existing))
+	for _, embedding := range existing {
+		embedded[embedding.PostId] = embedding
+	}
+
+	var removed []string
+	var toEmbed []*model.Post
+	var texts []string
+	for _, post := range posts {
+		if post.DeleteAt != 0 || post.IsSystemMessage() || strings.TrimSpace(post.Message) == "" {
+			if embedded[post.Id] != nil {
+				removed = append(removed, post.Id)
+			}
+			continue
+		}
+
+		// Posts are also updated by their reactions and replies, which don't change their
+		// embedding. Moved posts are saved again for their new channel.
+		if embedding := embedded[post.Id]; embedding != nil && embedding.PostEditAt == post.EditAt && embedding.ChannelId == post.ChannelId {
+			continue
+		}
+
+		toEmbed = append(toEmbed, post)
+		texts = append(texts, post.Message)
+	}
+
+	if err := a.Srv().Store().PostEmbedding().Delete(removed); err != nil {
+		return fmt.Errorf("failed to delete the embeddings of the posts: %w", err)
+	}
+
+	if len(toEmbed) == 0 {
+		return nil
+	}
+
+	vectors, err := provider.Embed(context.Background(), texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed %d posts: %w", len(texts), err)
+	}
+
+	postEmbeddings := make([]*model.PostEmbedding, len(toEmbed))
+	for i, post := range toEmbed {
+		postEmbeddings[i] = &model.PostEmbedding{
+			PostId:     post.Id,
+			ChannelId:  post.ChannelId,
+			Model:      provider.Model(),
+			PostEditAt: post.EditAt,
+			Embedding:  vectors[i],
+		}
+	}
+	if err := a.Srv().Store().PostEmbedding().Save(postEmbeddings); err != nil {
+		return fmt.Errorf("failed to save the embeddings of the posts: %w", err)
+	}
+
+	rctx.Logger().Debug("Embedded posts", mlog.Int("count", len(postEmbeddings)), mlog.Int("removed", len(removed)))
+	return nil
+}
+
+// setPostEmbeddingDimensions types and indexes the embeddings with the dimensions of the
+// provider when they change. The embeddings of other dimensions are deleted, so the posts are
+// embedded again from the start.
+func (a *App) setPostEmbeddingDimensions(rctx request.CTX, dimensions int) error {
+	value := strconv.Itoa(dimensions)
+	system, err := a.Srv().Store().System().GetByName(model.SystemPostEmbeddingDimensions)
+	if err == nil && system.Value == value {
+		return nil
+	}
+	var nfErr *store.ErrNotFound
+	if err != nil && !errors.As(err, &nfErr) {
+		return fmt.Errorf("failed to get the post embedding dimensions: %w", err)
+	}
+
+	rctx.Logger().Info("Indexing the post embeddings", mlog.Int("dimensions", dimensions))
+	if err := a.Srv().Store().PostEmbedding().SetDimensions(dimensions); err != nil {
+		return fmt.Errorf("failed to set the post embedding dimensions: %w", err)
+	}
+
+	if _, err := a.Srv().Store().System().PermanentDeleteByName(model.SystemPostEmbeddingCursor); err != nil {
+		return fmt.Errorf("failed to reset the post embedding cursor: %w", err)
+	}
+
+	if err := a.Srv().Store().System().SaveOrUpdate(&model.System{Name: model.SystemPostEmbeddingDimensions, Value: value}); err != nil {
+		return fmt.Errorf("failed to save the post embedding dimensions: %w", err)
+	}
+	return nil
+}
+
+func (a *App) getPostEmbeddingCursor() (model.GetPostsSinceForSyncCursor, error) {
+	var cursor model.GetPostsSinceForSyncCursor
+
+	system, err := a.Srv().Store().System().GetByName(model.SystemPostEmbeddingCursor)
+	if err != nil {
+		return cursor, err
+	}
+
+	updateAt, postID, _ := strings.Cut(system.Value, ":")
+	cursor.LastPostUpdateAt, err = strconv.ParseInt(updateAt, 10, 64)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid post embedding cursor %q: %w", system.Value, err)
+	}
+	cursor.LastPostUpdateID = postID
+
+	return cursor, nil
+}
+
+func (a *App) savePostEmbeddingCursor(cursor model.GetPostsSinceForSyncCursor) error {
+	if err := a.Srv().Store().System().SaveOrUpdate(&model.System{
+		Name:  model.SystemPostEmbeddingCursor,
+		Value: strconv.FormatInt(cursor.LastPostUpdateAt, 10) + ":" + cursor.LastPostUpdateID,
+	}); err != nil {
+		return fmt.Errorf("failed to save the post embedding cursor: %w", err)
+	}
+	return nil
+}
+
+// SearchPostsForUserHybrid searches the posts as SearchPostsForUser does, and ranks them by
+// merging the full text search results with the posts whose embeddings are the most similar
+// to the search terms. The similar posts are filtered by the channel, user, date and attribute
+// modifiers of the search, and only searched in the channels of the user. The full text
+// results are returned as is when the terms can't be embedded.
+func (a *App) SearchPostsForUserHybrid(rctx request.CTX, terms string, userID string, teamID string, isOrSearch bool, includeDeletedChannels bool, timeZoneOffset int, page, perPage int) (*model.PostSearchResults, *model.AppError) {
+	settings := a.Config().SemanticSearchSettings
+	if !*settings.Enable {
+		return nil, model.NewAppError("SearchPostsForUserHybrid", "app.post.search_hybrid.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	candidates := *settings.MaxVectorCandidates
+	fullTextResults, appErr := a.SearchPostsForUser(rctx, terms, userID, teamID, isOrSearch, includeDeletedChannels, timeZoneOffset, 0, candidates)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	paramsList := a.prepareSearchParamsForUser(rctx, model.ParseSearchParams(strings.TrimSpace(terms), timeZoneOffset), userID, teamID, isOrSearch, includeDeletedChannels)
+	var semanticTerms []string
+	for _, params := range paramsList {
+		if text := strings.TrimSpace(searchTermsReplacer.Replace(params.Terms)); text != "" {
+			semanticTerms = append(semanticTerms, text)
+		}
+	}
+
+	var similarPosts []*model.Post
+	if len(semanticTerms) > 0 {
+		var err error
+		similarPosts, err = a.searchSimilarPosts(rctx, strings.Join(semanticTerms, " "), userID, teamID, includeDeletedChannels, candidates)
+		if err != nil {
+			rctx.Logger().Warn("Failed to search similar posts, only returning the full text search results", mlog.Err(err))
+		}
+	}
+
+	scores := map[string]float64{}
+	posts := map[string]*model.Post{}
+	for i, postID := range fullTextResults.Order {
+		scores[postID] += 1.0 / float64(hybridSearchRankConstant+i+1)
+		posts[postID] = fullTextResults.Posts[postID]
+	}
+	rank := 0
+	for _, post := range similarPosts {
+		matchesFilters := false
+		for _, params := range paramsList {
+			if params.MatchesPostFilters(post) {
+				matchesFilters = true
+				break
+			}
+		}
+		if !matchesFilters {
+			continue
+		}
+
+		rank++
+		scores[post.Id] += 1.0 / float64(hybridSearchRankConstant+rank)
+		if posts[post.Id] == nil {
+			posts[post.Id] = post
+		}
+	}
+
+	order := make([]string, 0, len(scores))
+	for postID := range scores {
+		order = append(order, postID)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if scores[order[i]] != scores[order[j]] {
+			return scores[order[i]] > scores[order[j]]
+		}
+		return posts[order[i]].CreateAt > posts[order[j]].CreateAt
+	})
+
+	postList := model.NewPostList()
+	matches := model.PostSearchMatches{}
+	start := min(page*perPage, len(order))
+	end := min(start+perPage, len(order))
+	for _, postID := range order[start:end] {
+		postList.AddPost(posts[postID])
+		postList.AddOrder(postID)
+		if postMatches, ok := fullTextResults.Matches[postID]; ok {
+			matches[postID] = postMatches
+		}
+	}
+	postList.NextPostId = ""
+	postList.HasNext = model.NewPointer(end < len(order))
+
+	if appErr := a.filterInaccessiblePosts(postList, filterPostOptions{}); appErr != nil {
+		return nil, appErr
+	}
+
+	return model.MakePostSearchResults(postList, matches), nil
+}
+
+// searchSimilarPosts returns the undeleted posts of the channels of the user whose embeddings
+// are the most similar to the text, most similar first.
+func (a *App) searchSimilarPosts(rctx request.CTX, text, userID, teamID string, includeDeletedChannels bool, limit int) ([]*model.Post, error) {
+	provider, err := a.embeddingProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	vectors, err := provider.Embed(rctx.Context(), []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed the search terms: %w", err)
+	}
+
+	postIDs, err := a.Srv().Store().PostEmbedding().Search(userID, teamID, vectors[0], provider.Model(), includeDeletedChannels, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search the post embeddings: %w", err)
+	}
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	posts, err := a.Srv().Store().Post().GetPostsByIds(postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the similar posts: %w", err)
+	}
+	postsByID := make(map[string]*model.Post, len(posts))
+	for _, post := range posts {
+		postsByID[post.Id] = post
+	}
+
+	similarPosts := make([]*model.Post, 0, len(postIDs))
+	for _, postID := range postIDs {
+		if post := postsByID[postID]; post != nil && post.DeleteAt == 0 {
+			similarPosts = append(similarPosts, post)
+		}
+	}
+	return similarPosts, nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/services/embeddings"
+)
+
+func TestSearchPostsForUserHybrid(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	t.Run("disabled", func(t *testing.T) {
+		_, appErr := th.App.SearchPostsForUserHybrid(th.Context, "server", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotImplemented, appErr.StatusCode)
+	})
+
+	// The embeddings table only exists when the vector extension is available.
+	var exists bool
+	require.NoError(t, th.SQLStore.GetMaster().Get(&exists, "SELECT to_regclass('postembeddings') IS NOT NULL"))
+	if !exists {
+		t.Skip("the vector extension isn't available")
+	}
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.SemanticSearchSettings.Enable = model.NewPointer(true)
+		cfg.SemanticSearchSettings.EmbeddingProvider = model.NewPointer(model.SemanticSearchEmbeddingProviderLocal)
+	})
+
+	privateChannel := th.CreatePrivateChannel(th.Context, th.BasicTeam)
+	th.RemoveUserFromChannel(th.BasicUser, privateChannel)
+
+	createPost := func(channel *model.Channel, message string) *model.Post {
+		post, appErr := th.App.CreatePost(th.Context, &model.Post{
+			UserId:    th.BasicUser2.Id,
+			ChannelId: channel.Id,
+			Message:   message,
+		}, channel, model.CreatePostFlags{SetOnline: true})
+		require.Nil(t, appErr)
+		return post
+	}
+
+	exact := createPost(th.BasicChannel, "the deployment failed")
+	similar := createPost(th.BasicChannel, "deployments failing again")
+	createPost(th.BasicChannel, "what is for lunch")
+	private := createPost(privateChannel, "the deployment failed in private")
+
+	require.NoError(t, th.App.IndexPostEmbeddings())
+
+	// The embeddings are typed with the dimensions of the provider when first indexed.
+	var columnType string
+	require.NoError(t, th.SQLStore.GetMaster().Get(&columnType, "SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = 'postembeddings'::regclass AND attname = 'embedding'"))
+	assert.Equal(t, fmt.Sprintf("vector(%d)", embeddings.NewLocalProvider().Dimensions()), columnType)
+
+	results, appErr := th.App.SearchPostsForUserHybrid(th.Context, "deployment failed", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+	require.Nil(t, appErr)
+	require.NotEmpty(t, results.Order)
+	assert.Equal(t, exact.Id, results.Order[0])
+	assert.Contains(t, results.Order, similar.Id)
+	assert.NotContains(t, results.Order, private.Id)
+	assert.Contains(t, results.Matches, exact.Id)
+
+	t.Run("filters apply to similar posts", func(t *testing.T) {
+		results, appErr := th.App.SearchPostsForUserHybrid(th.Context, "deployment failed from:"+th.BasicUser.Username, th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.Nil(t, appErr)
+		assert.Empty(t, results.Order)
+	})
+
+	t.Run("deleted posts are removed", func(t *testing.T) {
+		_, appErr := th.App.DeletePost(th.Context, similar.Id, th.BasicUser2.Id)
+		require.Nil(t, appErr)
+		require.NoError(t, th.App.IndexPostEmbeddings())
+
+		embeddings, err := th.App.Srv().Store().PostEmbedding().GetForPosts([]string{exact.Id, similar.Id}, embeddings.NewLocalProvider().Model())
+		require.NoError(t, err)
+		require.Len(t, embeddings, 1)
+		assert.Equal(t, exact.Id, embeddings[0].PostId)
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
+)
+
+// replyByEmailSignatureLength is the number of bytes of the HMAC kept in reply
+// addresses, chosen so that the local part stays well below the 64 character limit.
+const replyByEmailSignatureLength = 10
+
+// replyByEmailSignature signs the pair of user and thread a reply address is issued for.
+func (a *App) replyByEmailSignature(userID, rootID string) []byte {
+	mac := hmac.New(sha256.New, []byte(*a.Config().EmailSettings.ReplyByEmailSigningKey))
+	mac.Write([]byte(userID + ":" + rootID))
+	return mac.Sum(nil)[:replyByEmailSignatureLength]
+}
+
+// ReplyByEmailAddress returns the address that lets the given user reply by email to the
+// thread starting with rootID, in the form <local part>+<root id>.<signature>@<domain>.
+// An empty string is returned when replying by email is disabled.
+func (a *App) ReplyByEmailAddress(userID, rootID string) string {
+	settings := a.Config().EmailSettings
+	if !*settings.EnableReplyByEmail || *settings.ReplyByEmailSigningKey == "" {
+		return ""
+	}
+
+	localPart, domain, found := strings.Cut(*settings.ReplyByEmailAddress, "@")
+	if !found {
+		return ""
+	}
+
+	return localPart + "+" + rootID + "." + hex.EncodeToString(a.replyByEmailSignature(userID, rootID)) + "@" + domain
+}
+
+// parseReplyByEmailAddress extracts the thread and signature from a reply address. The
+// signature can only be checked once the sender is known.
+func (a *App) parseReplyByEmailAddress(address string) (rootID string, signature []byte, ok bool) {
+	localPart, domain, found := strings.Cut(*a.Config().EmailSettings.ReplyByEmailAddress, "@")
+	if !found {
+		return "", nil, false
+	}
+
+	at := strings.LastIndex(address, "@")
+	if at == -1 || !strings.EqualFold(address[at+1:], domain) {
+		return "", nil, false
+	}
+
+	prefix := localPart + "+"
+	if len(address[:at]) <= len(prefix) || !strings.EqualFold(address[:len(prefix)], prefix) {
+		return "", nil, false
+	}
+
+	rootID, encodedSignature, found := strings.Cut(address[len(prefix):at], ".")
+	if !found || !model.IsValidId(rootID) {
+		return "", nil, false
+	}
+
+	signature, err := hex.DecodeString(encodedSignature)
+	if err != nil || len(signature) != replyByEmailSignatureLength {
+		return "", nil, false
+	}
+
+	return rootID, signature, true
+}
+
+// ProcessInboundEmail posts a reply received by email to the thread its reply address
+// was issued for. The sender must be the user the address was signed for and still be
+// allowed to post in the channel. Messages sent automatically, such as out of office
+// replies, are dropped and nil is returned without an error.
+func (a *App) ProcessInboundEmail(rctx request.CTX, r io.Reader) (*model.Post, *model.AppError) {
+	if !*a.Config().EmailSettings.EnableReplyByEmail {
+		return nil, model.NewAppError("ProcessInboundEmail", "app.reply_by_email.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	msg, err := mail.ParseInboundMessage(r)
+	if err != nil {
+		return nil, model.NewAppError("ProcessInboundEmail", "app.reply_by_email.parse.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	}
+
+	if msg.AutoSubmitted {
+		rctx.Logger().Debug("Ignoring automatically submitted inbound email", mlog.String("message_id", msg.MessageID))
+		return nil, nil
+	}
+
+	var rootID string
+	var signature []byte
+	found := false
+	for _, recipient := range msg.Recipients {
+		if rootID, signature, found = a.parseReplyByEmailAddress(recipient); found {
+			break
+		}
+	}
+	if !found {
+		return nil, model.NewAppError("ProcessInboundEmail", "app.reply_by_email.invalid_address.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	// The error is the same whether the sender is unknown or the signature doesn't match,
+	// so that the endpoint can't be used to look up accounts.
+	unauthorizedErr := model.NewAppError("ProcessInboundEmail", "app.reply_by_email.unauthorized.app_error", nil, "", http.StatusForbidden)
+
+	user, appErr := a.GetUserByEmail(msg.From)
+	if appErr != nil {
+		return nil, unauthorizedErr.Wrap(appErr)
+	}
+
+	if !hmac.Equal(signature, a.replyByEmailSignature(user.Id, rootID)) {
+		return nil, unauthorizedErr
+	}
+
+	if user.DeleteAt != 0 {
+		return nil, unauthorizedErr
+	}
+
+	rootPost, appErr := a.GetSinglePost(rctx, rootID, false)
+	if appErr != nil {
+		return nil, model.NewAppError("ProcessInboundEmail", "app.reply_by_email.thread_not_found.app_error", nil, "", http.StatusNotFound).Wrap(appErr)
+	}
+
+	if !a.HasPermissionToChannel(rctx, user.Id, rootPost.ChannelId, model.PermissionCreatePost) {
+		return nil, unauthorizedErr
+	}
+
+	message := mail.StripReply(msg.TextBody)
+	if message == "" {
+		return nil, model.NewAppError("ProcessInboundEmail", "app.reply_by_email.empty_body.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	post := &model.Post{
+		ChannelId: rootPost.ChannelId,
+		UserId:    user.Id,
+		RootId:    rootPost.Id,
+		Message:   message,
+	}
+
+	// Let MTA retries of the same message be deduplicated
+	if msg.MessageID != "" {
+		hash := sha256.Sum256([]byte(msg.MessageID))
+		post.PendingPostId = user.Id + ":" + hex.EncodeToString(hash[:8])
+	}
+
+	return a.CreatePostAsUser(rctx, post, "", false)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"fmt"
+	"net/http"
+	netmail "net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
+)
+
+func enableReplyByEmail(th *TestHelper) {
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.EmailSettings.EnableReplyByEmail = true
+		*cfg.EmailSettings.ReplyByEmailAddress = "reply@example.com"
+		*cfg.EmailSettings.ReplyByEmailSigningKey = model.NewRandomString(32)
+	})
+}
+
+func inboundReply(from, to, body string) *strings.Reader {
+	return strings.NewReader(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: Re: Notification\r\nMessage-ID: <%s@example.com>\r\n\r\n%s", from, to, model.NewId(), body))
+}
+
+func TestReplyByEmailAddress(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t)
+	defer th.TearDown()
+
+	userID := model.NewId()
+	rootID := model.NewId()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.EmailSettings.EnableReplyByEmail = false })
+	assert.Empty(t, th.App.ReplyByEmailAddress(userID, rootID))
+
+	enableReplyByEmail(th)
+
+	address := th.App.ReplyByEmailAddress(userID, rootID)
+	require.True(t, strings.HasPrefix(address, "reply+"+rootID+"."), address)
+	require.True(t, strings.HasSuffix(address, "@example.com"), address)
+	assert.LessOrEqual(t, strings.Index(address, "@"), 64, "the local part must fit the RFC 5321 limit")
+
+	parsedRootID, signature, ok := th.App.parseReplyByEmailAddress(strings.ToUpper(address))
+	require.True(t, ok)
+	assert.Equal(t, rootID, parsedRootID)
+	assert.Equal(t, th.App.replyByEmailSignature(userID, rootID), signature)
+
+	assert.NotEqual(t, address, th.App.ReplyByEmailAddress(model.NewId(), rootID), "addresses are per user")
+	assert.NotEqual(t, address, th.App.ReplyByEmailAddress(userID, model.NewId()), "addresses are per thread")
+
+	for _, invalid := range []string{
+		"reply@example.com",
+		"reply+" + rootID + "@example.com",
+		"reply+" + rootID + ".zz@example.com",
+		"other+" + rootID + ".00112233445566778899@example.com",
+		"reply+" + rootID + ".00112233445566778899@example.org",
+	} {
+		_, _, ok := th.App.parseReplyByEmailAddress(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestProcessInboundEmail(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	rootPost := th.CreatePost(th.BasicChannel)
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.EmailSettings.EnableReplyByEmail = false })
+
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, "reply@example.com", "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotImplemented, appErr.StatusCode)
+	})
+
+	enableReplyByEmail(th)
+	address := th.App.ReplyByEmailAddress(th.BasicUser.Id, rootPost.Id)
+
+	t.Run("reply is posted to the thread", func(t *testing.T) {
+		body := "Sounds good\r\n\r\nOn Mon, Jan 2, 2006 at 3:04 PM Someone <someone@example.com> wrote:\r\n> " + rootPost.Message + "\r\n"
+
+		post, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, address, body))
+		require.Nil(t, appErr)
+		require.NotNil(t, post)
+		assert.Equal(t, th.BasicUser.Id, post.UserId)
+		assert.Equal(t, th.BasicChannel.Id, post.ChannelId)
+		assert.Equal(t, rootPost.Id, post.RootId)
+		assert.Equal(t, "Sounds good", post.Message)
+	})
+
+	t.Run("address issued to another user", func(t *testing.T) {
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser2.Email, address, "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.unauthorized.app_error", appErr.Id)
+	})
+
+	t.Run("unknown sender", func(t *testing.T) {
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply("unknown"+model.NewId()+"@example.com", address, "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.unauthorized.app_error", appErr.Id)
+	})
+
+	t.Run("tampered thread", func(t *testing.T) {
+		otherRoot := th.CreatePost(th.BasicChannel)
+		tampered := strings.Replace(address, rootPost.Id, otherRoot.Id, 1)
+
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, tampered, "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.unauthorized.app_error", appErr.Id)
+	})
+
+	t.Run("not a reply address", func(t *testing.T) {
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, "reply@example.com", "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.invalid_address.app_error", appErr.Id)
+	})
+
+	t.Run("only quoted text", func(t *testing.T) {
+		_, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, address, "> "+rootPost.Message+"\r\n"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.empty_body.app_error", appErr.Id)
+	})
+
+	t.Run("automatic replies are ignored", func(t *testing.T) {
+		raw := "From: " + th.BasicUser.Email + "\r\nTo: " + address + "\r\nAuto-Submitted: auto-replied\r\n\r\nI am out of the office\r\n"
+
+		post, appErr := th.App.ProcessInboundEmail(th.Context, strings.NewReader(raw))
+		require.Nil(t, appErr)
+		assert.Nil(t, post)
+	})
+
+	t.Run("sender left the channel", func(t *testing.T) {
+		channel := th.CreatePrivateChannel(th.Context, th.BasicTeam)
+		th.AddUserToChannel(th.BasicUser2, channel)
+		root := th.CreatePost(channel)
+		address := th.App.ReplyByEmailAddress(th.BasicUser2.Id, root.Id)
+
+		appErr := th.App.RemoveUserFromChannel(th.Context, th.BasicUser2.Id, th.BasicUser.Id, channel)
+		require.Nil(t, appErr)
+
+		_, appErr = th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser2.Email, address, "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.unauthorized.app_error", appErr.Id)
+	})
+
+	t.Run("deleted thread", func(t *testing.T) {
+		root := th.CreatePost(th.BasicChannel)
+		address := th.App.ReplyByEmailAddress(th.BasicUser.Id, root.Id)
+
+		_, appErr := th.App.DeletePost(th.Context, root.Id, th.BasicUser.Id)
+		require.Nil(t, appErr)
+
+		_, appErr = th.App.ProcessInboundEmail(th.Context, inboundReply(th.BasicUser.Email, address, "Hello"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.reply_by_email.thread_not_found.app_error", appErr.Id)
+	})
+}
+
+func TestReplyToNotificationEmail(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.ConfigureInbucketMail()
+	enableReplyByEmail(th)
+
+	recipient := th.CreateUser()
+	th.LinkUserToTeam(recipient, th.BasicTeam)
+	th.AddUserToChannel(recipient, th.BasicChannel)
+
+	err := mail.DeleteMailBox(recipient.Email)
+	require.NoError(t, err)
+
+	rootPost := th.CreatePost(th.BasicChannel)
+	notification := &PostNotification{
+		Post:       rootPost,
+		Channel:    th.BasicChannel,
+		ProfileMap: map[string]*model.User{recipient.Id: recipient},
+		Sender:     th.BasicUser,
+	}
+
+	_, err = th.App.sendNotificationEmail(th.Context, notification, recipient, th.BasicTeam, nil)
+	require.NoError(t, err)
+
+	var mailbox mail.JSONMessageHeaderInbucket
+	err = mail.RetryInbucket(5, func() error {
+		mailbox, err = mail.GetMailBox(recipient.Email)
+		if err == nil && len(mailbox) == 0 {
+			return fmt.Errorf("no email received")
+		}
+		return err
+	})
+	if err != nil {
+		t.Skip("No email was received, maybe due load on the server:", err)
+	}
+
+	message, err := mail.GetMessageFromMailbox(recipient.Email, mailbox[0].ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, message.Header["Reply-To"])
+
+	replyTo, err := netmail.ParseAddress(message.Header["Reply-To"][0])
+	require.NoError(t, err)
+	require.Equal(t, th.App.ReplyByEmailAddress(recipient.Id, rootPost.Id), replyTo.Address)
+
+	body := "Replying from my inbox\r\n\r\nOn Mon, Jan 2, 2006 at 3:04 PM " + message.From + " wrote:\r\n> " + rootPost.Message + "\r\n"
+	post, appErr := th.App.ProcessInboundEmail(th.Context, inboundReply(recipient.Email, replyTo.Address, body))
+	require.Nil(t, appErr)
+	assert.Equal(t, rootPost.Id, post.RootId)
+	assert.Equal(t, recipient.Id, post.UserId)
+	assert.Equal(t, "Replying from my inbox", post.Message)
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+const (
+	savedSearchWatchPostsBatchSize = 500
+	savedSearchWatchPageSize       = 1000
+
+	// savedSearchWatchMaxAge bounds how far back new posts are matched, so that the first
+	// run after the job was stopped for a while doesn't notify users of old posts.
+	savedSearchWatchMaxAge = time.Hour
+)
+
+func savedSearchStoreError(where string, err error) *model.AppError {
+	var appErr *model.AppError
+	var nfErr *store.ErrNotFound
+	var invErr *store.ErrInvalidInput
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.As(err, &nfErr):
+		return model.NewAppError(where, "app.saved_search.get.app_error", nil, "", http.StatusNotFound).Wrap(err)
+	case errors.As(err, &invErr):
+		return model.NewAppError(where, "app.saved_search.save.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	default:
+		return model.NewAppError(where, "app.saved_search.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+}
+
+func (a *App) CreateSavedSearch(savedSearch *model.SavedSearch) (*model.SavedSearch, *model.AppError) {
+	count, err := a.Srv().Store().SavedSearch().CountForUser(savedSearch.UserId)
+	if err != nil {
+		return nil, model.NewAppError("CreateSavedSearch", "app.saved_search.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if count >= model.SavedSearchMaxPerUser {
+		return nil, model.NewAppError("CreateSavedSearch", "app.saved_search.create.limit.app_error", map[string]any{"Max": model.SavedSearchMaxPerUser}, "", http.StatusBadRequest)
+	}
+
+	savedSearch.Id = ""
+	saved, err := a.Srv().Store().SavedSearch().Save(savedSearch)
+	if err != nil {
+		return nil, savedSearchStoreError("CreateSavedSearch", err)
+	}
+
+	return saved, nil
+}
+
+func (a *App) GetSavedSearch(id string) (*model.SavedSearch, *model.AppError) {
+	savedSearch, err := a.Srv().Store().SavedSearch().Get(id)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if errors.As(err, &nfErr) {
+			return nil, model.NewAppError("GetSavedSearch", "app.saved_search.get.app_error", nil, "", http.StatusNotFound).Wrap(err)
+		}
+		return nil, model.NewAppError("GetSavedSearch", "app.saved_search.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return savedSearch, nil
+}
+
+func (a *App) GetSavedSearchesForUser(userID string) ([]*model.SavedSearch, *model.AppError) {
+	savedSearches, err := a.Srv().Store().SavedSearch().GetForUser(userID)
+	if err != nil {
+		return nil, model.NewAppError("GetSavedSearchesForUser", "app.saved_search.get_for_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return savedSearches, nil
+}
+
+func (a *App) PatchSavedSearch(savedSearch *model.SavedSearch, patch *model.SavedSearchPatch) (*model.SavedSearch, *model.AppError) {
+	savedSearch.Patch(patch)
+
+	updated, err := a.Srv().Store().SavedSearch().Update(savedSearch)
+	if err != nil {
+		return nil, savedSearchStoreError("PatchSavedSearch", err)
+	}
+
+	return updated, nil
+}
+
+func (a *App) DeleteSavedSearch(id string) *model.AppError {
+	if err := a.Srv().Store().SavedSearch().Delete(id); err != nil {
+		return model.NewAppError("DeleteSavedSearch", "app.saved_search.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return nil
+}
+
+// savedSearchWatcher is a watched saved search with its params resolved to match posts.
+type savedSearchWatcher struct {
+	savedSearch *model.SavedSearch
+	paramsList  []*model.SearchParams
+}
+
+func (w *savedSearchWatcher) matches(post *model.PostForIndexing) bool {
+	if w.savedSearch.TeamId != "" && post.TeamId != "" && post.TeamId != w.savedSearch.TeamId {
+		return false
+	}
+
+	for _, params := range w.paramsList {
+		// The words are stemmed as the search engines do, so that the watched searches find
+		// the posts the saved searches would.
+		if params.MatchesPost(&post.Post, index.Stem) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifySavedSearchMatches notifies the users watching saved searches of the posts created
+// since the last run that match them.
+func (a *App) NotifySavedSearchMatches() error {
+	rctx := request.EmptyContext(a.Log().With(mlog.String("component", "saved_search_watch")))
+
+	cursorTime, cursorID, err := a.getSavedSearchWatchCursor()
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if !errors.As(err, &nfErr) {
+			return err
+		}
+		// Only the posts created from the first run on are matched.
+		return a.saveSavedSearchWatchCursor(model.GetMillis(), "")
+	}
+
+	if minTime := model.GetMillis() - savedSearchWatchMaxAge.Milliseconds(); cursorTime < minTime {
+		cursorTime, cursorID = minTime, ""
+	}
+
+	watchers, err := a.getSavedSearchWatchers(rctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		posts, err := a.Srv().Store().Post().GetPostsBatchForIndexing(cursorTime, cursorID, savedSearchWatchPostsBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get posts to match against saved searches: %w", err)
+		}
+		if len(posts) == 0 {
+			return nil
+		}
+
+		if len(watchers) > 0 {
+			for _, post := range posts {
+				a.notifySavedSearchMatchesForPost(rctx, post, watchers)
+			}
+		}
+
+		last := posts[len(posts)-1]
+		cursorTime, cursorID = last.CreateAt, last.Id
+		if err := a.saveSavedSearchWatchCursor(cursorTime, cursorID); err != nil {
+			return err
+		}
+
+		if len(posts) < savedSearchWatchPostsBatchSize {
+			return nil
+		}
+	}
+}
+
+func (a *App) getSavedSearchWatchCursor() (int64, string, error) {
+	system, err := a.Srv().Store().System().GetByName(model.SystemSavedSearchWatchCursor)
+	if err != nil {
+		return 0, "", err
+	}
+
+	createAt, postID, _ := strings.Cut(system.Value, ":")
+	cursorTime, err := strconv.ParseInt(createAt, 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid saved search watch cursor %q: %w", system.Value, err)
+	}
+
+	return cursorTime, postID, nil
+}
+
+func (a *App) saveSavedSearchWatchCursor(cursorTime int64, cursorID string) error {
+	if err := a.Srv().Store().System().SaveOrUpdate(&model.System{
+		Name:  model.SystemSavedSearchWatchCursor,
+		Value: strconv.FormatInt(cursorTime, 10) + ":" + cursorID,
+	}); err != nil {
+		return fmt.Errorf("failed to save the saved search watch cursor: %w", err)
+	}
+	return nil
+}
+
+// getSavedSearchWatchers loads the watched saved searches, resolving the channel names and
+// usernames of their params to IDs as searching on behalf of their owner would.
+func (a *App) getSavedSearchWatchers(rctx request.CTX) ([]*savedSearchWatcher, error) {
+	var watchers []*savedSearchWatcher
+
+	afterID := ""
+	for {
+		savedSearches, err := a.Srv().Store().SavedSearch().GetWatched(afterID, savedSearchWatchPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watched saved searches: %w", err)
+		}
+
+		for _, savedSearch := range savedSearches {
+			watcher := &savedSearchWatcher{savedSearch: savedSearch}
+			for _, params := range savedSearch.Params {
+				if params.Terms == "*" {
+					continue
+				}
+				params.InChannels = a.convertChannelNamesToChannelIds(rctx, params.InChannels, savedSearch.UserId, savedSearch.TeamId, false)
+				params.ExcludedChannels = a.convertChannelNamesToChannelIds(rctx, params.ExcludedChannels, savedSearch.UserId, savedSearch.TeamId, false)
+				params.FromUsers = a.convertUserNameToUserIds(rctx, params.FromUsers)
+				params.ExcludedUsers = a.convertUserNameToUserIds(rctx, params.ExcludedUsers)
+				watcher.paramsList = append(watcher.paramsList, params)
+			}
+			if len(watcher.paramsList) > 0 {
+				watchers = append(watchers, watcher)
+			}
+		}
+
+		if len(savedSearches) < savedSearchWatchPageSize {
+			return watchers, nil
+		}
+		afterID = savedSearches[len(savedSearches)-1].Id
+	}
+}
+
+func (a *App) notifySavedSearchMatchesForPost(rctx request.CTX, post *model.PostForIndexing, watchers []*savedSearchWatcher) {
+	if post.DeleteAt != 0 || post.IsSystemMessage() {
+		return
+	}
+
+	// Users are notified once of a post, however many of their saved searches it matches.
+	notified := map[string]bool{}
+	for _, watcher := range watchers {
+		userID := watcher.savedSearch.UserId
+		if userID == post.UserId || notified[userID] || !watcher.matches(post) {
+			continue
+		}
+		notified[userID] = true
+
+		if err := a.sendSavedSearchMatch(rctx, watcher.savedSearch, post); err != nil {
+			rctx.Logger().Warn("Failed to notify of a saved search match",
+				mlog.String("saved_search_id", watcher.savedSearch.Id),
+				mlog.String("post_id", post.Id),
+				mlog.Err(err),
+			)
+		}
+	}
+}
+
+// sendSavedSearchMatch notifies the owner of a saved search of a post it matches, provided
+// they can still read the channel of the post.
+func (a *App) sendSavedSearchMatch(rctx request.CTX, savedSearch *model.SavedSearch, post *model.PostForIndexing) error {
+	user, appErr := a.GetUser(savedSearch.UserId)
+	if appErr != nil {
+		return appErr
+	}
+	if user.DeleteAt != 0 {
+		return nil
+	}
+
+	channel, appErr := a.GetChannel(rctx, post.ChannelId)
+	if appErr != nil {
+		return appErr
+	}
+	if channel.DeleteAt != 0 {
+		return nil
+	}
+
+	member, appErr := a.GetChannelMember(rctx, channel.Id, user.Id)
+	if appErr != nil {
+		if appErr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return appErr
+	}
+	if !a.HasPermissionToChannel(rctx, user.Id, channel.Id, model.PermissionReadChannelContent) {
+		return nil
+	}
+
+	sender, appErr := a.GetUser(post.UserId)
+	if appErr != nil {
+		return appErr
+	}
+
+	profileMap := model.UserMap{sender.Id: sender}
+	if channel.Type == model.ChannelTypeGroup {
+		profiles, err := a.Srv().Store().User().GetAllProfilesInChannel(context.Background(), channel.Id, true)
+		if err != nil {
+			return fmt.Errorf("failed to get the profiles of the channel: %w", err)
+		}
+		profileMap = profiles
+	}
+
+	notification := &PostNotification{
+		Post:       &post.Post,
+		Channel:    channel,
+		ProfileMap: profileMap,
+		Sender:     sender,
+	}
+
+	clientPost := a.PreparePostForClient(rctx, &post.Post, &model.PreparePostForClientOpts{IncludePriority: true})
+	postJSON, err := clientPost.ToJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode post to JSON: %w", err)
+	}
+
+	message := model.NewWebSocketEvent(model.WebsocketEventSavedSearchMatched, post.TeamId, channel.Id, user.Id, nil, "")
+	message.Add("post", postJSON)
+	message.Add("saved_search_id", savedSearch.Id)
+	message.Add("saved_search_name", savedSearch.Name)
+	message.Add("channel_type", channel.Type)
+	message.Add("channel_display_name", notification.GetChannelName(model.ShowUsername, user.Id))
+	message.Add("channel_name", channel.Name)
+	message.Add("sender_name", notification.GetSenderName(model.ShowUsername, *a.Config().ServiceSettings.EnablePostUsernameOverride))
+	message.Add("team_id", post.TeamId)
+	a.Publish(message)
+
+	if a.canSendPushNotifications() {
+		status, appErr := a.GetStatus(user.Id)
+		if appErr != nil {
+			status = &model.Status{UserId: user.Id, Status: model.StatusOffline}
+		}
+
+		// A watched saved search is pushed like a mention, but isn't presented as one.
+		isGM := channel.Type == model.ChannelTypeGroup
+		if a.ShouldSendPushNotification(rctx, user, member.NotifyProps, true, status, &post.Post, isGM) {
+			a.sendSavedSearchPushNotification(notification, user, savedSearch.Name)
+		}
+	}
+
+	return nil
+}
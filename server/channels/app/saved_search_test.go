@@ -0,0 +1,88 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestNotifySavedSearchMatches(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	savedSearch, appErr := th.App.CreateSavedSearch(&model.SavedSearch{
+		UserId: th.BasicUser.Id,
+		Name:   "Outages",
+		Terms:  "outage",
+		Watch:  true,
+	})
+	require.Nil(t, appErr)
+
+	_, appErr = th.App.CreateSavedSearch(&model.SavedSearch{
+		UserId: th.BasicUser.Id,
+		Name:   "Outages again",
+		Terms:  "outage*",
+		Watch:  true,
+	})
+	require.Nil(t, appErr)
+
+	// The first run only starts watching from now on.
+	th.CreatePost(th.BasicChannel)
+	require.NoError(t, th.App.NotifySavedSearchMatches())
+
+	messages, closeWS := connectFakeWebSocket(t, th, th.BasicUser.Id, "", []model.WebsocketEventType{model.WebsocketEventSavedSearchMatched})
+	defer closeWS()
+
+	privateChannel := th.CreatePrivateChannel(th.Context, th.BasicTeam)
+	th.RemoveUserFromChannel(th.BasicUser, privateChannel)
+
+	createPost := func(user *model.User, channel *model.Channel, message string) *model.Post {
+		post, appErr := th.App.CreatePost(th.Context, &model.Post{
+			UserId:    user.Id,
+			ChannelId: channel.Id,
+			Message:   message,
+		}, channel, model.CreatePostFlags{SetOnline: true})
+		require.Nil(t, appErr)
+		return post
+	}
+
+	matching := createPost(th.BasicUser2, th.BasicChannel, "There is an outage in production")
+	createPost(th.BasicUser2, th.BasicChannel, "All good")
+	createPost(th.BasicUser, th.BasicChannel, "My own outage")
+	createPost(th.BasicUser2, privateChannel, "A private outage")
+
+	require.NoError(t, th.App.NotifySavedSearchMatches())
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, savedSearch.Id, msg.GetData()["saved_search_id"])
+		assert.Equal(t, th.BasicChannel.Id, msg.GetBroadcast().ChannelId)
+		assert.Contains(t, msg.GetData()["post"], matching.Id)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "expected a saved search match")
+	}
+
+	select {
+	case msg := <-messages:
+		require.Failf(t, "unexpected saved search match", "%v", msg.GetData())
+	case <-time.After(time.Second):
+	}
+
+	t.Run("posts are only matched once", func(t *testing.T) {
+		require.NoError(t, th.App.NotifySavedSearchMatches())
+
+		select {
+		case msg := <-messages:
+			require.Failf(t, "unexpected saved search match", "%v", msg.GetData())
+		case <-time.After(time.Second):
+		}
+	})
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/public/shared/timezones"
 	"github.com/mattermost/mattermost/server/v8/channels/app/email"
+	"github.com/mattermost/mattermost/server/v8/channels/app/password/hashers"
 	"github.com/mattermost/mattermost/server/v8/channels/app/platform"
 	"github.com/mattermost/mattermost/server/v8/channels/app/properties"
 	"github.com/mattermost/mattermost/server/v8/channels/app/teams"
@@ -44,11 +45,17 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_dms_preferences_migration"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_empty_drafts_migration"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_orphan_drafts_migration"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/email_batching"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/email_digest"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/embedded_search_indexing"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/expirynotify"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_delete"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_process"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_users_to_csv"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/extract_content"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/file_deduplication"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/file_encryption_rewrap"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/file_storage_migration"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/hosted_purchase_screening"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/import_delete"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/import_process"
@@ -57,12 +64,17 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/migrations"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/mobile_session_metadata"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/notify_admin"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/outgoing_webhook_retry"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/plugins"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/post_embedding"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/post_persistent_notifications"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/product_notices"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/refresh_materialized_views"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/regenerate_file_previews"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/resend_invitation_email"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/s3_path_migration"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/saved_search_watch"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/search_ngram_backfill"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
 	"github.com/mattermost/mattermost/server/v8/config"
@@ -70,6 +82,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/platform/services/awsmeter"
 	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
 	"github.com/mattermost/mattermost/server/v8/platform/services/remotecluster"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine"
 	"github.com/mattermost/mattermost/server/v8/platform/services/sharedchannel"
 	"github.com/mattermost/mattermost/server/v8/platform/services/telemetry"
 	"github.com/mattermost/mattermost/server/v8/platform/services/upgrader"
@@ -136,6 +149,10 @@ type Server struct {
 
 	phase2PermissionsMigrationComplete bool
 
+	// passwordHasher hashes the new and migrated passwords, as selected in the password settings.
+	passwordHasherMut sync.RWMutex
+	passwordHasher    hashers.PasswordHasher
+
 	Audit *audit.Audit
 
 	joinCluster  bool
@@ -389,9 +406,10 @@ func NewServer(options ...Option) (*Server, error) {
 		mlog.Error("SiteURL must be set. Some features will operate incorrectly if the SiteURL is not set. See documentation for details: https://mattermost.com/pl/configure-site-url")
 	}
 
-	// Start email batching because it's not like the other jobs
+	// Hash new and migrated passwords with the configured algorithm
+	s.configurePasswordHasher()
 	s.platform.AddConfigListener(func(_, _ *model.Config) {
-		s.EmailService.InitEmailBatching()
+		s.configurePasswordHasher()
 	})
 
 	pwd, _ := os.Getwd()
@@ -674,10 +692,6 @@ func (s *Server) Shutdown() {
 		s.Log().Warn("Failed to stop metrics server", mlog.Err(err))
 	}
 
-	// Stopping email service after HTTP server has stopped to prevent
-	// any stray notifications from being queued.
-	s.EmailService.Stop()
-
 	// This must be done after the cluster is stopped.
 	if s.Jobs != nil {
 		// For simplicity we don't check if workers and schedulers are active
@@ -824,7 +838,10 @@ func (s *Server) Start() error {
 	err := s.FileBackend().TestConnection()
 	if err != nil {
 		if _, ok := err.(*filestore.S3FileBackendNoBucketError); ok {
-			err = s.FileBackend().(*filestore.S3FileBackend).MakeBucket()
+			// The S3 backend may be wrapped, for instance when files are encrypted at rest.
+			if bucketMaker, ok := filestore.UnwrapFileBackend(s.FileBackend()).(interface{ MakeBucket() error }); ok {
+				err = bucketMaker.MakeBucket()
+			}
 		}
 		if err != nil {
 			mlog.Error("Problem with file storage settings", mlog.Err(err))
@@ -1605,6 +1622,74 @@ func (s *Server) initJobs() {
 		delete_dms_preferences_migration.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
 		nil)
 
+	s.Jobs.RegisterJobType(
+		model.JobTypeOutgoingWebhookRetry,
+		outgoing_webhook_retry.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		outgoing_webhook_retry.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeEmailBatching,
+		email_batching.MakeWorker(s.Jobs, s.EmailService),
+		email_batching.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeEmailDigest,
+		email_digest.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		email_digest.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeFileEncryptionRewrap,
+		file_encryption_rewrap.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		file_encryption_rewrap.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeFileDeduplication,
+		file_deduplication.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		file_deduplication.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeFileStorageMigration,
+		file_storage_migration.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
+		nil,
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeRegenerateFilePreviews,
+		regenerate_file_previews.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
+		nil,
+	)
+
+	if engine, ok := s.platform.SearchEngine.EmbeddedEngine.(*embeddedengine.EmbeddedEngine); ok {
+		s.Jobs.RegisterJobType(
+			model.JobTypeEmbeddedSearchIndexing,
+			embedded_search_indexing.MakeWorker(s.Jobs, s.Store(), engine),
+			nil,
+		)
+	}
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeSearchNgramBackfill,
+		search_ngram_backfill.MakeWorker(s.Jobs, s.Store()),
+		nil,
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeSavedSearchWatch,
+		saved_search_watch.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		saved_search_watch.MakeScheduler(s.Jobs),
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypePostEmbedding,
+		post_embedding.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		post_embedding.MakeScheduler(s.Jobs),
+	)
+
 	s.platform.Jobs = s.Jobs
 }
 
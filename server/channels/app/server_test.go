@@ -102,44 +102,68 @@ func TestStartServerNoS3Bucket(t *testing.T) {
 	}
 
 	s3Endpoint := fmt.Sprintf("%s:%s", s3Host, s3Port)
-	configStore, _ := config.NewFileStore("config.json", true)
-	store, _ := config.NewStoreFromBacking(configStore, nil, false)
 
-	cfg := store.Get()
-	cfg.FileSettings = model.FileSettings{
-		DriverName:              model.NewPointer(model.ImageDriverS3),
-		AmazonS3AccessKeyId:     model.NewPointer(model.MinioAccessKey),
-		AmazonS3SecretAccessKey: model.NewPointer(model.MinioSecretKey),
-		AmazonS3Bucket:          model.NewPointer("nosuchbucket"),
-		AmazonS3Endpoint:        model.NewPointer(s3Endpoint),
-		AmazonS3Region:          model.NewPointer(""),
-		AmazonS3PathPrefix:      model.NewPointer(""),
-		AmazonS3SSL:             model.NewPointer(false),
+	startServer := func(t *testing.T, bucket string, encrypted bool) *Server {
+		configStore, _ := config.NewFileStore("config.json", true)
+		store, _ := config.NewStoreFromBacking(configStore, nil, false)
+
+		cfg := store.Get()
+		cfg.FileSettings = model.FileSettings{
+			DriverName:              model.NewPointer(model.ImageDriverS3),
+			AmazonS3AccessKeyId:     model.NewPointer(model.MinioAccessKey),
+			AmazonS3SecretAccessKey: model.NewPointer(model.MinioSecretKey),
+			AmazonS3Bucket:          model.NewPointer(bucket),
+			AmazonS3Endpoint:        model.NewPointer(s3Endpoint),
+			AmazonS3Region:          model.NewPointer(""),
+			AmazonS3PathPrefix:      model.NewPointer(""),
+			AmazonS3SSL:             model.NewPointer(false),
+		}
+		if encrypted {
+			cfg.FileSettings.EnableEncryptionAtRest = model.NewPointer(true)
+			cfg.FileSettings.EncryptionMasterKey = model.NewPointer("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+		}
+		*cfg.ServiceSettings.ListenAddress = "localhost:0"
+		*cfg.AnnouncementSettings.AdminNoticesEnabled = false
+		*cfg.AnnouncementSettings.UserNoticesEnabled = false
+		cfg.SqlSettings = *mainHelper.GetSQLSettings()
+		_, _, err := store.Set(cfg)
+		require.NoError(t, err)
+
+		s, err := NewServer(func(server *Server) error {
+			var err2 error
+			server.platform, err2 = platform.New(platform.ServiceConfig{}, platform.ConfigStore(store))
+			require.NoError(t, err2)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Start())
+		return s
 	}
-	*cfg.ServiceSettings.ListenAddress = "localhost:0"
-	*cfg.AnnouncementSettings.AdminNoticesEnabled = false
-	*cfg.AnnouncementSettings.UserNoticesEnabled = false
-	cfg.SqlSettings = *mainHelper.GetSQLSettings()
-	_, _, err := store.Set(cfg)
-	require.NoError(t, err)
 
-	s, err := NewServer(func(server *Server) error {
-		var err2 error
-		server.platform, err2 = platform.New(platform.ServiceConfig{}, platform.ConfigStore(store))
-		require.NoError(t, err2)
+	t.Run("unencrypted", func(t *testing.T) {
+		s := startServer(t, "nosuchbucket", false)
+		defer s.Shutdown()
+
+		// ensure that a new bucket was created
+		require.IsType(t, &filestore.S3FileBackend{}, s.FileBackend())
 
-		return nil
+		err := s.FileBackend().(*filestore.S3FileBackend).TestConnection()
+		require.NoError(t, err)
 	})
-	require.NoError(t, err)
 
-	require.NoError(t, s.Start())
-	defer s.Shutdown()
+	t.Run("encrypted at rest", func(t *testing.T) {
+		s := startServer(t, "nosuchencryptedbucket", true)
+		defer s.Shutdown()
 
-	// ensure that a new bucket was created
-	require.IsType(t, &filestore.S3FileBackend{}, s.FileBackend())
+		// ensure that a new bucket was created under the encrypted backend
+		require.IsType(t, &filestore.EncryptedFileBackend{}, s.FileBackend())
+		require.IsType(t, &filestore.S3FileBackend{}, filestore.UnwrapFileBackend(s.FileBackend()))
 
-	err = s.FileBackend().(*filestore.S3FileBackend).TestConnection()
-	require.NoError(t, err)
+		err := s.FileBackend().TestConnection()
+		require.NoError(t, err)
+	})
 }
 
 func TestStartServerTLSSuccess(t *testing.T) {
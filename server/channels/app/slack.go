@@ -35,10 +35,14 @@ func (a *App) SlackImport(rctx request.CTX, fileData multipart.File, fileSize in
 		DoUploadFile: func(now time.Time, rawTeamId string, rawChannelId string, rawUserId string, rawFilename string, data []byte) (*model.FileInfo, *model.AppError) {
 			return a.DoUploadFile(rctx, now, rawTeamId, rawChannelId, rawUserId, rawFilename, data, true)
 		},
-		GenerateThumbnailImage: a.generateThumbnailImage,
-		GeneratePreviewImage:   a.generatePreviewImage,
-		InvalidateAllCaches:    func() *model.AppError { return a.ch.srv.platform.InvalidateAllCaches() },
-		MaxPostSize:            func() int { return a.ch.srv.platform.MaxPostSize() },
+		GenerateThumbnailImage: func(rctx request.CTX, img image.Image, imgType string, thumbnailPath string) {
+			a.generateThumbnailImage(rctx, img, nil, imgType, thumbnailPath)
+		},
+		GeneratePreviewImage: func(rctx request.CTX, img image.Image, imgType string, previewPath string) {
+			a.generatePreviewImage(rctx, img, nil, imgType, previewPath)
+		},
+		InvalidateAllCaches: func() *model.AppError { return a.ch.srv.platform.InvalidateAllCaches() },
+		MaxPostSize:         func() int { return a.ch.srv.platform.MaxPostSize() },
 		PrepareImage: func(fileData []byte) (image.Image, string, func(), error) {
 			img, imgType, release, err := prepareImage(rctx, a.ch.imgDecoder, bytes.NewReader(fileData))
 			if err != nil {
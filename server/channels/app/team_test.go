@@ -1553,7 +1553,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1578,7 +1577,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(email.SendMailError)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1607,7 +1605,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return([]*model.EmailInviteWithError{}, nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1632,7 +1629,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1666,7 +1662,6 @@ func TestInviteGuestsToChannelsGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteGuestsToChannelsGracefully(th.Context, th.BasicTeam.Id, &model.GuestsInvite{
@@ -1693,7 +1688,6 @@ func TestInviteGuestsToChannelsGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(email.SendMailError)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteGuestsToChannelsGracefully(th.Context, th.BasicTeam.Id, &model.GuestsInvite{
@@ -154,6 +154,10 @@ func (a *App) CreateUploadSession(rctx request.CTX, us *model.UploadSession) (*m
 			err := model.NewAppError("CreateUploadSession", "app.upload.create.cannot_upload_to_restricted_dm.error", nil, "", http.StatusBadRequest)
 			return nil, err
 		}
+
+		if err := a.CheckFileStorageQuota(rctx, us.UserId, us.ChannelId, us.FileSize); err != nil {
+			return nil, err
+		}
 	}
 
 	us, storeErr := a.Srv().Store().UploadSession().Save(us)
@@ -225,6 +229,13 @@ func (a *App) UploadData(rctx request.CTX, us *model.UploadSession, rd io.Reader
 		uploadPath += model.IncompleteUploadSuffix
 	}
 
+	// the quota may have been used up by other uploads since the session was created.
+	if us.Type == model.UploadTypeAttachment && us.FileOffset == 0 {
+		if err := a.CheckFileStorageQuota(rctx, us.UserId, us.ChannelId, us.FileSize); err != nil {
+			return nil, err
+		}
+	}
+
 	// make sure it's not possible to upload more data than what is expected.
 	lr := &io.LimitedReader{
 		R: rd,
@@ -296,6 +307,12 @@ func (a *App) UploadData(rctx request.CTX, us *model.UploadSession, rd io.Reader
 		info.Id = us.ReqFileId
 	}
 
+	if us.Type == model.UploadTypeAttachment {
+		if err := a.scanUploadedFile(rctx, info); err != nil {
+			return nil, err
+		}
+	}
+
 	// run plugins upload hook
 	if err := a.runPluginsHook(rctx, info, file); err != nil {
 		return nil, err
@@ -311,6 +328,11 @@ func (a *App) UploadData(rctx request.CTX, us *model.UploadSession, rd io.Reader
 		nameWithoutExtension := info.Name[:strings.LastIndex(info.Name, ".")]
 		info.PreviewPath = filepath.Dir(info.Path) + "/" + nameWithoutExtension + "_preview." + getFileExtFromMimeType(info.MimeType)
 		info.ThumbnailPath = filepath.Dir(info.Path) + "/" + nameWithoutExtension + "_thumb." + getFileExtFromMimeType(info.MimeType)
+		if us.Type == model.UploadTypeAttachment {
+			if _, err := a.stripImageMetadata(rctx, info); err != nil {
+				return nil, err
+			}
+		}
 		imgData, fileErr := a.ReadFile(uploadPath)
 		if fileErr != nil {
 			return nil, fileErr
@@ -324,6 +346,10 @@ func (a *App) UploadData(rctx request.CTX, us *model.UploadSession, rd io.Reader
 		}
 	}
 
+	if us.Type == model.UploadTypeAttachment {
+		a.deduplicateFile(rctx, info)
+	}
+
 	var storeErr error
 	if info, storeErr = a.Srv().Store().FileInfo().Save(rctx, info); storeErr != nil {
 		var appErr *model.AppError
@@ -25,7 +25,6 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/app/email"
 	"github.com/mattermost/mattermost/server/v8/channels/app/imaging"
-	"github.com/mattermost/mattermost/server/v8/channels/app/password/hashers"
 	"github.com/mattermost/mattermost/server/v8/channels/app/users"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
@@ -38,6 +37,8 @@ const (
 	TokenTypeTeamInvitation    = "team_invitation"
 	TokenTypeGuestInvitation   = "guest_invitation"
 	TokenTypeCWSAccess         = "cws_access_token"
+	TokenTypeWebAuthnRegister  = "webauthn_register"
+	TokenTypeWebAuthnLogin     = "webauthn_login"
 	PasswordRecoverExpiryTime  = 1000 * 60 * 60 * 24 // 24 hours
 	InvitationExpiryTime       = 1000 * 60 * 60 * 48 // 48 hours
 	ImageProfilePixelDimension = 128
@@ -803,32 +804,73 @@ func (a *App) GenerateMfaSecret(userID string) (*model.MfaSecret, *model.AppErro
 	return mfaSecret, nil
 }
 
-func (a *App) ActivateMfa(userID, token string) *model.AppError {
+// ActivateMfa activates MFA for the user and returns the recovery codes that can each be
+// used once in place of a token.
+func (a *App) ActivateMfa(userID, token string) ([]string, *model.AppError) {
 	user, appErr := a.GetUser(userID)
 	if appErr != nil {
-		return appErr
+		return nil, appErr
 	}
 
 	if user.AuthService != "" && user.AuthService != model.UserAuthServiceLdap {
-		return model.NewAppError("ActivateMfa", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
+		return nil, model.NewAppError("ActivateMfa", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
 	}
 
 	if !*a.Config().ServiceSettings.EnableMultifactorAuthentication {
-		return model.NewAppError("ActivateMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
+		return nil, model.NewAppError("ActivateMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	if err := a.ch.srv.userService.ActivateMfa(user, token); err != nil {
+	recoveryCodes, err := a.ch.srv.userService.ActivateMfa(user, token)
+	if err != nil {
 		switch {
 		case errors.Is(err, mfa.InvalidToken):
-			return model.NewAppError("ActivateMfa", "mfa.activate.bad_token.app_error", nil, "", http.StatusUnauthorized)
+			return nil, model.NewAppError("ActivateMfa", "mfa.activate.bad_token.app_error", nil, "", http.StatusUnauthorized)
 		default:
-			return model.NewAppError("ActivateMfa", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+			return nil, model.NewAppError("ActivateMfa", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 		}
 	}
 
 	// Make sure old MFA status is not cached locally or in cluster nodes.
 	a.InvalidateCacheForUser(userID)
 
+	return recoveryCodes, nil
+}
+
+// GenerateMfaRecoveryCodes replaces the MFA recovery codes of the user with a new set.
+func (a *App) GenerateMfaRecoveryCodes(userID string) ([]string, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableMultifactorAuthentication {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !user.MfaActive {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "api.user.generate_mfa_recovery_codes.not_active.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	recoveryCodes, err := a.ch.srv.userService.GenerateMfaRecoveryCodes(user)
+	if err != nil {
+		return nil, model.NewAppError("GenerateMfaRecoveryCodes", "mfa.generate_recovery_codes.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return recoveryCodes, nil
+}
+
+// RevokeMfaRecoveryCodes removes the MFA recovery codes of the user. The user can generate
+// a new set after re-authenticating.
+func (a *App) RevokeMfaRecoveryCodes(userID string) *model.AppError {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := a.ch.srv.userService.RevokeMfaRecoveryCodes(user); err != nil {
+		return model.NewAppError("RevokeMfaRecoveryCodes", "mfa.revoke_recovery_codes.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
 	return nil
 }
 
@@ -842,6 +884,10 @@ func (a *App) DeactivateMfa(userID string) *model.AppError {
 		return model.NewAppError("DeactivateMfa", "mfa.deactivate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	if err := a.Srv().Store().WebAuthnCredential().PermanentDeleteByUser(userID); err != nil {
+		return model.NewAppError("DeactivateMfa", "app.webauthn.permanent_delete_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
 	// Make sure old MFA status is not cached locally or in cluster nodes.
 	a.InvalidateCacheForUser(userID)
 
@@ -1455,14 +1501,18 @@ func (a *App) updateUserNotifyProps(userID string, props map[string]string) *mod
 	return nil
 }
 
-func (a *App) UpdateMfa(rctx request.CTX, activate bool, userID, token string) *model.AppError {
+// UpdateMfa activates or deactivates MFA for the user. When activating, it returns the
+// recovery codes generated for the user.
+func (a *App) UpdateMfa(rctx request.CTX, activate bool, userID, token string) ([]string, *model.AppError) {
+	var recoveryCodes []string
 	if activate {
-		if err := a.ActivateMfa(userID, token); err != nil {
-			return err
+		var appErr *model.AppError
+		if recoveryCodes, appErr = a.ActivateMfa(userID, token); appErr != nil {
+			return nil, appErr
 		}
 	} else {
 		if err := a.DeactivateMfa(userID); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -1478,7 +1528,7 @@ func (a *App) UpdateMfa(rctx request.CTX, activate bool, userID, token string) *
 		}
 	})
 
-	return nil
+	return recoveryCodes, nil
 }
 
 func (a *App) UpdatePasswordByUserIdSendEmail(rctx request.CTX, userID, newPassword, method string) *model.AppError {
@@ -1500,7 +1550,7 @@ func (a *App) UpdatePassword(rctx request.CTX, user *model.User, newPassword str
 		return model.NewAppError("UpdatePassword", "api.user.update_password.failed.app_error", nil, "", http.StatusInternalServerError)
 	}
 
-	hashedPassword, err := hashers.Hash(newPassword)
+	hashedPassword, err := a.Srv().PasswordHasher().Hash(newPassword)
 	if err != nil {
 		// can't be password length (checked in IsPasswordValid)
 		return model.NewAppError("UpdatePassword", "api.user.update_password.password_hash.app_error", nil, "user_id="+user.Id, http.StatusInternalServerError).Wrap(err)
@@ -1913,6 +1963,14 @@ func (a *App) PermanentDeleteUser(rctx request.CTX, user *model.User) *model.App
 		return model.NewAppError("PermanentDeleteUser", "app.drafts.permanent_delete_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	if err := a.Srv().Store().WebAuthnCredential().PermanentDeleteByUser(user.Id); err != nil {
+		return model.NewAppError("PermanentDeleteUser", "app.webauthn.permanent_delete_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	if err := a.Srv().Store().SavedSearch().PermanentDeleteByUser(user.Id); err != nil {
+		return model.NewAppError("PermanentDeleteUser", "app.saved_search.permanent_delete_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
 	if err := a.Srv().Store().Bot().PermanentDelete(user.Id); err != nil {
 		var invErr *store.ErrInvalidInput
 		switch {
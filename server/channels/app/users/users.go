@@ -242,10 +242,18 @@ func (us *UserService) GenerateMfaSecret(user *model.User) (*model.MfaSecret, er
 	return mfaSecret, nil
 }
 
-func (us *UserService) ActivateMfa(user *model.User, token string) error {
+func (us *UserService) ActivateMfa(user *model.User, token string) ([]string, error) {
 	return mfa.New(us.store).Activate(user.MfaSecret, user.Id, token)
 }
 
+func (us *UserService) GenerateMfaRecoveryCodes(user *model.User) ([]string, error) {
+	return mfa.New(us.store).GenerateRecoveryCodes(user.Id)
+}
+
+func (us *UserService) RevokeMfaRecoveryCodes(user *model.User) error {
+	return mfa.New(us.store).RevokeRecoveryCodes(user.Id)
+}
+
 func (us *UserService) DeactivateMfa(user *model.User) error {
 	return mfa.New(us.store).Deactivate(user.Id)
 }
@@ -0,0 +1,456 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
+)
+
+func (a *App) isWebAuthnEnabled() bool {
+	return *a.Config().ServiceSettings.EnableMultifactorAuthentication && *a.Config().ServiceSettings.EnableWebAuthn
+}
+
+// isWebAuthnEnforced reports whether users must use WebAuthn, rather than TOTP, as their second factor.
+func (a *App) isWebAuthnEnforced() bool {
+	license := a.Channels().License()
+	return license != nil && *license.Features.MFA && a.isWebAuthnEnabled() && *a.Config().ServiceSettings.EnforceWebAuthn
+}
+
+func (a *App) webAuthnRelyingParty() (*mfa.WebAuthnRelyingParty, *model.AppError) {
+	rp, err := mfa.NewWebAuthnRelyingParty(a.GetSiteURL())
+	if err != nil {
+		return nil, model.NewAppError("webAuthnRelyingParty", "app.webauthn.site_url.app_error", nil, "", http.StatusNotImplemented).Wrap(err)
+	}
+	return rp, nil
+}
+
+// consumeWebAuthnChallenge returns the challenge issued to the user that the client data claims
+// to answer. Challenges are single use, so it is deleted whether or not the response verifies.
+func (a *App) consumeWebAuthnChallenge(tokenType, userID string, clientDataJSON []byte) ([]byte, error) {
+	challenge, err := mfa.WebAuthnClientDataChallenge(clientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := a.Srv().Store().Token().GetByToken(string(challenge))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.Srv().Store().Token().Delete(token.Token); err != nil {
+		return nil, err
+	}
+
+	if token.Type != tokenType || token.Extra != userID || model.GetMillis()-token.CreateAt >= model.WebAuthnChallengeTimeout {
+		return nil, errors.New("invalid webauthn challenge")
+	}
+
+	return challenge, nil
+}
+
+func (a *App) createWebAuthnChallenge(tokenType, userID string) ([]byte, *model.AppError) {
+	token := model.NewToken(tokenType, userID)
+	if err := a.Srv().Store().Token().Save(token); err != nil {
+		return nil, model.NewAppError("createWebAuthnChallenge", "app.webauthn.save_challenge.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return []byte(token.Token), nil
+}
+
+// webAuthnLoginChallengeNonceSize is the size of the random part of a login challenge, which is
+// followed by its expiry time and a MAC.
+const webAuthnLoginChallengeNonceSize = 32
+
+func (a *App) webAuthnLoginChallengeMAC(userID string, data []byte) []byte {
+	mac := hmac.New(sha256.New, a.PostActionCookieSecret())
+	mac.Write([]byte("webauthn_login_challenge:" + userID + ":"))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// createWebAuthnLoginChallenge returns a challenge signed for the user. Login challenges are
+// handed out before the user is authenticated, so they are not stored.
+func (a *App) createWebAuthnLoginChallenge(userID string) ([]byte, *model.AppError) {
+	challenge := make([]byte, webAuthnLoginChallengeNonceSize, webAuthnLoginChallengeNonceSize+8+sha256.Size)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, model.NewAppError("createWebAuthnLoginChallenge", "app.webauthn.save_challenge.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	challenge = binary.BigEndian.AppendUint64(challenge, uint64(model.GetMillis()+model.WebAuthnChallengeTimeout))
+	return append(challenge, a.webAuthnLoginChallengeMAC(userID, challenge)...), nil
+}
+
+// checkWebAuthnLoginChallenge returns the login challenge that the client data claims to answer
+// if it was signed for the user and has not expired.
+func (a *App) checkWebAuthnLoginChallenge(userID string, clientDataJSON []byte) ([]byte, error) {
+	challenge, err := mfa.WebAuthnClientDataChallenge(clientDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(challenge) != webAuthnLoginChallengeNonceSize+8+sha256.Size {
+		return nil, errors.New("invalid webauthn challenge")
+	}
+
+	data, mac := challenge[:webAuthnLoginChallengeNonceSize+8], challenge[webAuthnLoginChallengeNonceSize+8:]
+	if !hmac.Equal(mac, a.webAuthnLoginChallengeMAC(userID, data)) {
+		return nil, errors.New("invalid webauthn challenge")
+	}
+
+	if int64(binary.BigEndian.Uint64(data[webAuthnLoginChallengeNonceSize:])) < model.GetMillis() {
+		return nil, errors.New("expired webauthn challenge")
+	}
+
+	return challenge, nil
+}
+
+// useWebAuthnLoginChallenge records that a login challenge has been answered, failing if it
+// already was, so that an assertion can't be replayed while its challenge is valid.
+func (a *App) useWebAuthnLoginChallenge(userID string, challenge []byte) error {
+	hash := sha256.Sum256(challenge)
+	return a.Srv().Store().Token().Save(&model.Token{
+		Token:    hex.EncodeToString(hash[:]),
+		CreateAt: model.GetMillis(),
+		Type:     TokenTypeWebAuthnLogin,
+		Extra:    userID,
+	})
+}
+
+func webAuthnCredentialDescriptors(credentials []*model.WebAuthnCredential) []model.WebAuthnCredentialDescriptor {
+	descriptors := make([]model.WebAuthnCredentialDescriptor, 0, len(credentials))
+	for _, credential := range credentials {
+		id, err := base64.RawURLEncoding.DecodeString(credential.CredentialId)
+		if err != nil {
+			continue
+		}
+		descriptors = append(descriptors, model.WebAuthnCredentialDescriptor{Type: model.WebAuthnCredentialTypePublicKey, Id: id})
+	}
+	return descriptors
+}
+
+// StartWebAuthnRegistration begins the registration of a new WebAuthn credential for the user
+// and returns the options to pass to navigator.credentials.create().
+func (a *App) StartWebAuthnRegistration(userID string) (*model.WebAuthnCreationOptions, *model.AppError) {
+	if !a.isWebAuthnEnabled() {
+		return nil, model.NewAppError("StartWebAuthnRegistration", "app.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if user.AuthService != "" && user.AuthService != model.UserAuthServiceLdap {
+		return nil, model.NewAppError("StartWebAuthnRegistration", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	rp, appErr := a.webAuthnRelyingParty()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	credentials, err := a.Srv().Store().WebAuthnCredential().GetForUser(userID)
+	if err != nil {
+		return nil, model.NewAppError("StartWebAuthnRegistration", "app.webauthn.get_for_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	challenge, appErr := a.createWebAuthnChallenge(TokenTypeWebAuthnRegister, userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	params := make([]model.WebAuthnCredentialParameter, 0, len(mfa.WebAuthnSupportedAlgorithms))
+	for _, alg := range mfa.WebAuthnSupportedAlgorithms {
+		params = append(params, model.WebAuthnCredentialParameter{Type: model.WebAuthnCredentialTypePublicKey, Alg: alg})
+	}
+
+	return &model.WebAuthnCreationOptions{
+		Challenge: challenge,
+		RelyingParty: model.WebAuthnRelyingPartyEntity{
+			Id:   rp.ID,
+			Name: *a.Config().TeamSettings.SiteName,
+		},
+		User: model.WebAuthnUserEntity{
+			Id:          []byte(user.Id),
+			Name:        user.Username,
+			DisplayName: user.GetDisplayName(model.ShowFullName),
+		},
+		PubKeyCredParams:   params,
+		Timeout:            model.WebAuthnChallengeTimeout,
+		ExcludeCredentials: webAuthnCredentialDescriptors(credentials),
+		AuthenticatorSelection: model.WebAuthnAuthenticatorSelection{
+			UserVerification: model.WebAuthnUserVerificationRequired,
+		},
+		Attestation: model.WebAuthnAttestationNone,
+	}, nil
+}
+
+// FinishWebAuthnRegistration verifies the response to a registration started with
+// StartWebAuthnRegistration and stores the new credential. Registering the first
+// credential activates MFA for the user.
+func (a *App) FinishWebAuthnRegistration(rctx request.CTX, userID string, response *model.WebAuthnAttestationResponse) (*model.WebAuthnCredential, *model.AppError) {
+	if !a.isWebAuthnEnabled() {
+		return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	name := strings.TrimSpace(response.Name)
+	if name == "" || utf8.RuneCountInString(name) > model.WebAuthnCredentialNameMaxRunes {
+		return nil, model.NewAppError("FinishWebAuthnRegistration", "model.webauthn_credential.is_valid.name.app_error", map[string]any{"MaxLength": model.WebAuthnCredentialNameMaxRunes}, "", http.StatusBadRequest)
+	}
+
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	rp, appErr := a.webAuthnRelyingParty()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	challenge, err := a.consumeWebAuthnChallenge(TokenTypeWebAuthnRegister, userID, response.Response.ClientDataJSON)
+	if err != nil {
+		return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.invalid_challenge.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	}
+
+	verified, err := rp.VerifyRegistration(challenge, response.Response.ClientDataJSON, response.Response.AttestationObject)
+	if err != nil {
+		return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.invalid_registration.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	}
+
+	credential, err := a.Srv().Store().WebAuthnCredential().Save(&model.WebAuthnCredential{
+		UserId:       userID,
+		Name:         name,
+		CredentialId: base64.RawURLEncoding.EncodeToString(verified.ID),
+		PublicKey:    verified.PublicKey,
+		AAGUID:       hex.EncodeToString(verified.AAGUID),
+		SignCount:    int64(verified.SignCount),
+	})
+	if err != nil {
+		var appErr *model.AppError
+		var cErr *store.ErrConflict
+		switch {
+		case errors.As(err, &appErr):
+			return nil, appErr
+		case errors.As(err, &cErr):
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.credential_exists.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+		default:
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "app.webauthn.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	if !user.MfaActive {
+		if err := a.Srv().Store().User().UpdateMfaActive(userID, true); err != nil {
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+
+		// Make sure old MFA status is not cached locally or in cluster nodes.
+		a.InvalidateCacheForUser(userID)
+
+		a.Srv().Go(func() {
+			if err := a.Srv().EmailService.SendMfaChangeEmail(user.Email, true, user.Locale, a.GetSiteURL()); err != nil {
+				rctx.Logger().Error("Failed to send mfa change email", mlog.Err(err))
+			}
+		})
+	}
+
+	return credential, nil
+}
+
+func (a *App) GetWebAuthnCredentials(userID string) ([]*model.WebAuthnCredential, *model.AppError) {
+	credentials, err := a.Srv().Store().WebAuthnCredential().GetForUser(userID)
+	if err != nil {
+		return nil, model.NewAppError("GetWebAuthnCredentials", "app.webauthn.get_for_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return credentials, nil
+}
+
+// DeleteWebAuthnCredential removes one of the user's WebAuthn credentials. MFA is deactivated
+// when the last credential is removed and the user has no TOTP secret.
+func (a *App) DeleteWebAuthnCredential(userID, credentialID string) *model.AppError {
+	credential, err := a.Srv().Store().WebAuthnCredential().Get(credentialID)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn.get.app_error", nil, "", http.StatusNotFound).Wrap(err)
+		default:
+			return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	if credential.UserId != userID {
+		return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn.get.app_error", nil, "", http.StatusNotFound)
+	}
+
+	if err := a.Srv().Store().WebAuthnCredential().Delete(credential.Id); err != nil {
+		return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	remaining, appErr := a.GetWebAuthnCredentials(userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	if len(remaining) == 0 && user.MfaActive && user.MfaSecret == "" {
+		if err := a.Srv().Store().User().UpdateMfaActive(userID, false); err != nil {
+			return model.NewAppError("DeleteWebAuthnCredential", "mfa.deactivate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+
+		// Make sure old MFA status is not cached locally or in cluster nodes.
+		a.InvalidateCacheForUser(userID)
+	}
+
+	return nil
+}
+
+// fakeWebAuthnCredentialDescriptors derives credential descriptors for a login id without any
+// WebAuthn credentials, so that its login options can't be told apart from the ones of a user who
+// registered some. The same login id always gets the same descriptors.
+func (a *App) fakeWebAuthnCredentialDescriptors(loginID string) []model.WebAuthnCredentialDescriptor {
+	mac := hmac.New(sha256.New, a.PostActionCookieSecret())
+	mac.Write([]byte("webauthn_login:" + strings.ToLower(loginID)))
+	seed := mac.Sum(nil)
+
+	count := 1 + int(seed[0]%2)
+	descriptors := make([]model.WebAuthnCredentialDescriptor, 0, count)
+	for i := range count {
+		mac := hmac.New(sha256.New, seed)
+		mac.Write([]byte{byte(i)})
+		descriptors = append(descriptors, model.WebAuthnCredentialDescriptor{Type: model.WebAuthnCredentialTypePublicKey, Id: mac.Sum(nil)})
+	}
+	return descriptors
+}
+
+// StartWebAuthnLogin returns the options to pass to navigator.credentials.get() to produce the
+// assertion sent as the MFA token when logging in. To avoid revealing which accounts exist and
+// which of them use WebAuthn, a login id without credentials gets options of the same shape,
+// listing credentials that no authenticator has.
+func (a *App) StartWebAuthnLogin(rctx request.CTX, loginID string) (*model.WebAuthnRequestOptions, *model.AppError) {
+	if !a.isWebAuthnEnabled() {
+		return nil, model.NewAppError("StartWebAuthnLogin", "app.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	rp, appErr := a.webAuthnRelyingParty()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var userID string
+	var descriptors []model.WebAuthnCredentialDescriptor
+	if user, appErr := a.GetUserForLogin(rctx, "", loginID); appErr == nil {
+		credentials, appErr := a.GetWebAuthnCredentials(user.Id)
+		if appErr != nil {
+			return nil, appErr
+		}
+		userID = user.Id
+		descriptors = webAuthnCredentialDescriptors(credentials)
+	}
+	if len(descriptors) == 0 {
+		descriptors = a.fakeWebAuthnCredentialDescriptors(loginID)
+	}
+
+	challenge, appErr := a.createWebAuthnLoginChallenge(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &model.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		RelyingPartyId:   rp.ID,
+		AllowCredentials: descriptors,
+		Timeout:          model.WebAuthnChallengeTimeout,
+		UserVerification: model.WebAuthnUserVerificationRequired,
+	}, nil
+}
+
+// checkUserWebAuthnAssertion verifies a WebAuthn assertion sent as the user's MFA token.
+func (a *App) checkUserWebAuthnAssertion(rctx request.CTX, user *model.User, token string) *model.AppError {
+	if !a.isWebAuthnEnabled() {
+		return model.NewAppError("checkUserWebAuthnAssertion", "app.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	badCode := model.NewAppError("checkUserWebAuthnAssertion", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+
+	assertion, err := model.WebAuthnAssertionFromToken(token)
+	if err != nil {
+		return badCode.Wrap(err)
+	}
+
+	rp, appErr := a.webAuthnRelyingParty()
+	if appErr != nil {
+		return appErr
+	}
+
+	credentialID := assertion.Id
+	if len(assertion.RawId) > 0 {
+		credentialID = base64.RawURLEncoding.EncodeToString(assertion.RawId)
+	}
+
+	credential, err := a.Srv().Store().WebAuthnCredential().GetByCredentialId(credentialID)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if errors.As(err, &nfErr) {
+			return badCode.Wrap(err)
+		}
+		return model.NewAppError("checkUserWebAuthnAssertion", "app.webauthn.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	if credential.UserId != user.Id {
+		return badCode
+	}
+
+	challenge, err := a.checkWebAuthnLoginChallenge(user.Id, assertion.Response.ClientDataJSON)
+	if err != nil {
+		return badCode.Wrap(err)
+	}
+
+	rawCredentialID, err := base64.RawURLEncoding.DecodeString(credential.CredentialId)
+	if err != nil {
+		return badCode.Wrap(err)
+	}
+
+	signCount, err := rp.VerifyAssertion(challenge, rawCredentialID, assertion.Response.ClientDataJSON, assertion.Response.AuthenticatorData, assertion.Response.Signature, credential.PublicKey, uint32(credential.SignCount))
+	if err != nil {
+		rctx.Logger().Warn("Rejected WebAuthn assertion", mlog.String("user_id", user.Id), mlog.String("credential_id", credential.Id), mlog.Err(err))
+		return badCode.Wrap(err)
+	}
+
+	if err := a.useWebAuthnLoginChallenge(user.Id, challenge); err != nil {
+		return badCode.Wrap(err)
+	}
+
+	if err := a.Srv().Store().WebAuthnCredential().UpdateLastUsed(credential.Id, int64(signCount), model.GetMillis()); err != nil {
+		return model.NewAppError("checkUserWebAuthnAssertion", "app.webauthn.update_last_used.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return nil
+}
+
+// hasWebAuthnCredentials reports whether the user has registered at least one WebAuthn credential.
+func (a *App) hasWebAuthnCredentials(userID string) (bool, *model.AppError) {
+	credentials, appErr := a.GetWebAuthnCredentials(userID)
+	if appErr != nil {
+		return false, appErr
+	}
+	return len(credentials) > 0, nil
+}
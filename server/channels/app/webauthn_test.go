@@ -0,0 +1,318 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dgryski/dgoogauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
+)
+
+// webAuthnTestCBOR encodes the few CBOR items needed to build a "none" attestation for an
+// ES256 key. Maps are given as alternating keys and values to keep the encoding deterministic.
+func webAuthnTestCBOR(v any) []byte {
+	head := func(major byte, n int) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		default:
+			return []byte{major<<5 | 25, byte(n >> 8), byte(n)}
+		}
+	}
+
+	switch val := v.(type) {
+	case int:
+		if val < 0 {
+			return head(1, -1-val)
+		}
+		return head(0, val)
+	case []byte:
+		return append(head(2, len(val)), val...)
+	case string:
+		return append(head(3, len(val)), val...)
+	case []any:
+		out := head(5, len(val)/2)
+		for _, item := range val {
+			out = append(out, webAuthnTestCBOR(item)...)
+		}
+		return out
+	}
+	panic("unsupported cbor value")
+}
+
+type webAuthnTestAuthenticator struct {
+	t            *testing.T
+	rpID         string
+	origin       string
+	credentialID []byte
+	key          *ecdsa.PrivateKey
+	signCount    uint32
+}
+
+func newWebAuthnTestAuthenticator(t *testing.T, siteURL string) *webAuthnTestAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 32)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	rp, err := mfa.NewWebAuthnRelyingParty(siteURL)
+	require.NoError(t, err)
+
+	return &webAuthnTestAuthenticator{t: t, rpID: rp.ID, origin: rp.Origin, credentialID: credentialID, key: key}
+}
+
+func (a *webAuthnTestAuthenticator) authData(flags byte, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.key.X.FillBytes(x)
+		a.key.Y.FillBytes(y)
+
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, webAuthnTestCBOR([]any{1, 2, 3, -7, -1, 1, -2, x, -3, y})...)
+	}
+	return data
+}
+
+func (a *webAuthnTestAuthenticator) clientData(typ string, challenge []byte) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	require.NoError(a.t, err)
+	return data
+}
+
+func (a *webAuthnTestAuthenticator) register(options *model.WebAuthnCreationOptions, name string) *model.WebAuthnAttestationResponse {
+	a.signCount++
+	return &model.WebAuthnAttestationResponse{
+		Id:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawId: a.credentialID,
+		Type:  model.WebAuthnCredentialTypePublicKey,
+		Response: model.WebAuthnAttestationResponseData{
+			ClientDataJSON: a.clientData("webauthn.create", options.Challenge),
+			AttestationObject: webAuthnTestCBOR([]any{
+				"fmt", "none",
+				"attStmt", []any{},
+				"authData", a.authData(0x45, true),
+			}),
+		},
+		Name: name,
+	}
+}
+
+func (a *webAuthnTestAuthenticator) assert(options *model.WebAuthnRequestOptions) string {
+	a.signCount++
+	clientDataJSON := a.clientData("webauthn.get", options.Challenge)
+	authData := a.authData(0x05, false)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(a.t, err)
+
+	token, err := json.Marshal(&model.WebAuthnAssertionResponse{
+		Id:    base64.RawURLEncoding.EncodeToString(a.credentialID),
+		RawId: a.credentialID,
+		Type:  model.WebAuthnCredentialTypePublicKey,
+		Response: model.WebAuthnAssertionResponseData{
+			ClientDataJSON:    clientDataJSON,
+			AuthenticatorData: authData,
+			Signature:         signature,
+		},
+	})
+	require.NoError(a.t, err)
+	return string(token)
+}
+
+func TestWebAuthn(t *testing.T) {
+	mainHelper.Parallel(t)
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	const siteURL = "https://chat.example.com"
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.SiteURL = siteURL
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = true
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableWebAuthn = false })
+		defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableWebAuthn = true })
+
+		_, appErr := th.App.StartWebAuthnRegistration(th.BasicUser.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.webauthn.disabled.app_error", appErr.Id)
+	})
+
+	user := th.CreateUser()
+	authenticator := newWebAuthnTestAuthenticator(t, siteURL)
+
+	options, appErr := th.App.StartWebAuthnRegistration(user.Id)
+	require.Nil(t, appErr)
+	assert.Equal(t, "chat.example.com", options.RelyingParty.Id)
+	assert.Equal(t, []byte(user.Id), []byte(options.User.Id))
+	assert.Empty(t, options.ExcludeCredentials)
+
+	t.Run("registration with an unknown challenge fails", func(t *testing.T) {
+		other := &model.WebAuthnCreationOptions{Challenge: []byte(model.NewRandomString(model.TokenSize))}
+		_, appErr := th.App.FinishWebAuthnRegistration(th.Context, user.Id, authenticator.register(other, "Key"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.webauthn.invalid_challenge.app_error", appErr.Id)
+	})
+
+	credential, appErr := th.App.FinishWebAuthnRegistration(th.Context, user.Id, authenticator.register(options, "Security key"))
+	require.Nil(t, appErr)
+	assert.Equal(t, "Security key", credential.Name)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(authenticator.credentialID), credential.CredentialId)
+
+	user, appErr = th.App.GetUser(user.Id)
+	require.Nil(t, appErr)
+	require.True(t, user.MfaActive, "registering the first credential should activate MFA")
+
+	t.Run("challenges are single use", func(t *testing.T) {
+		_, appErr := th.App.FinishWebAuthnRegistration(th.Context, user.Id, authenticator.register(options, "Again"))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.webauthn.invalid_challenge.app_error", appErr.Id)
+	})
+
+	t.Run("registered credentials are excluded", func(t *testing.T) {
+		options, appErr := th.App.StartWebAuthnRegistration(user.Id)
+		require.Nil(t, appErr)
+		require.Len(t, options.ExcludeCredentials, 1)
+		assert.Equal(t, authenticator.credentialID, []byte(options.ExcludeCredentials[0].Id))
+	})
+
+	t.Run("login with an assertion", func(t *testing.T) {
+		options, appErr := th.App.StartWebAuthnLogin(th.Context, user.Username)
+		require.Nil(t, appErr)
+		require.Len(t, options.AllowCredentials, 1)
+
+		token := authenticator.assert(options)
+		require.Nil(t, th.App.CheckUserMfa(th.Context, user, token))
+
+		appErr = th.App.CheckUserMfa(th.Context, user, token)
+		require.NotNil(t, appErr, "assertions cannot be replayed")
+		assert.Equal(t, "api.user.check_user_mfa.bad_code.app_error", appErr.Id)
+
+		credentials, appErr := th.App.GetWebAuthnCredentials(user.Id)
+		require.Nil(t, appErr)
+		require.Len(t, credentials, 1)
+		assert.NotZero(t, credentials[0].LastUsedAt)
+	})
+
+	t.Run("assertion for another user fails", func(t *testing.T) {
+		options, appErr := th.App.StartWebAuthnLogin(th.Context, user.Username)
+		require.Nil(t, appErr)
+
+		other := th.CreateUser()
+		err := th.App.Srv().Store().User().UpdateMfaActive(other.Id, true)
+		require.NoError(t, err)
+		other.MfaActive = true
+
+		appErr = th.App.CheckUserMfa(th.Context, other, authenticator.assert(options))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "api.user.check_user_mfa.bad_code.app_error", appErr.Id)
+	})
+
+	t.Run("unknown login id gets options of the same shape", func(t *testing.T) {
+		loginID := "unknown" + model.NewId()
+		options, appErr := th.App.StartWebAuthnLogin(th.Context, loginID)
+		require.Nil(t, appErr)
+		assert.NotEmpty(t, options.Challenge)
+		require.NotEmpty(t, options.AllowCredentials)
+		for _, descriptor := range options.AllowCredentials {
+			assert.Equal(t, model.WebAuthnCredentialTypePublicKey, descriptor.Type)
+			assert.NotEqual(t, authenticator.credentialID, []byte(descriptor.Id))
+		}
+
+		again, appErr := th.App.StartWebAuthnLogin(th.Context, loginID)
+		require.Nil(t, appErr)
+		assert.Equal(t, options.AllowCredentials, again.AllowCredentials, "the same login id gets the same credentials")
+		assert.NotEqual(t, options.Challenge, again.Challenge)
+	})
+
+	t.Run("user without credentials gets options of the same shape", func(t *testing.T) {
+		other := th.CreateUser()
+		options, appErr := th.App.StartWebAuthnLogin(th.Context, other.Username)
+		require.Nil(t, appErr)
+		assert.NotEmpty(t, options.Challenge)
+		assert.NotEmpty(t, options.AllowCredentials)
+	})
+
+	t.Run("login with a forged challenge fails", func(t *testing.T) {
+		options, appErr := th.App.StartWebAuthnLogin(th.Context, user.Username)
+		require.Nil(t, appErr)
+		options.Challenge[0] ^= 0xff
+
+		appErr = th.App.CheckUserMfa(th.Context, user, authenticator.assert(options))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "api.user.check_user_mfa.bad_code.app_error", appErr.Id)
+	})
+
+	t.Run("totp cannot be used without a secret", func(t *testing.T) {
+		appErr := th.App.CheckUserMfa(th.Context, user, "123456")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "api.user.check_user_mfa.bad_code.app_error", appErr.Id)
+	})
+
+	t.Run("enforced webauthn rejects totp", func(t *testing.T) {
+		th.App.Srv().SetLicense(model.NewTestLicense("mfa"))
+		defer th.App.Srv().SetLicense(nil)
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnforceWebAuthn = true })
+		defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnforceWebAuthn = false })
+
+		secret, appErr := th.App.GenerateMfaSecret(user.Id)
+		require.Nil(t, appErr)
+		user, appErr := th.App.GetUser(user.Id)
+		require.Nil(t, appErr)
+
+		code := dgoogauth.ComputeCode(secret.Secret, time.Now().UTC().Unix()/30)
+		appErr = th.App.CheckUserMfa(th.Context, user, fmt.Sprintf("%06d", code))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "api.user.check_user_mfa.webauthn_required.app_error", appErr.Id)
+
+		err := th.App.Srv().Store().User().UpdateMfaSecret(user.Id, "")
+		require.NoError(t, err)
+	})
+
+	t.Run("deleting the last credential deactivates mfa", func(t *testing.T) {
+		appErr := th.App.DeleteWebAuthnCredential(th.BasicUser.Id, credential.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+
+		appErr = th.App.DeleteWebAuthnCredential(user.Id, credential.Id)
+		require.Nil(t, appErr)
+
+		user, appErr := th.App.GetUser(user.Id)
+		require.Nil(t, appErr)
+		require.False(t, user.MfaActive)
+	})
+}
@@ -8,10 +8,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"maps"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +36,20 @@ const (
 
 var linkWithTextRegex = regexp.MustCompile(`<([^\n<\|>]+)\|([^\|\n>]+)>`)
 
+// outgoingWebhookDeliveryError is returned when an outgoing webhook request couldn't be delivered,
+// or the receiver asked to send it again later, so that it can be retried.
+type outgoingWebhookDeliveryError struct {
+	err error
+}
+
+func (e *outgoingWebhookDeliveryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *outgoingWebhookDeliveryError) Unwrap() error {
+	return e.err
+}
+
 func (a *App) handleWebhookEvents(rctx request.CTX, post *model.Post, team *model.Team, channel *model.Channel, user *model.User) *model.AppError {
 	if !*a.Config().ServiceSettings.EnableOutgoingWebhooks {
 		return nil
@@ -99,27 +115,23 @@ func (a *App) handleWebhookEvents(rctx request.CTX, post *model.Post, team *mode
 func (a *App) TriggerWebhook(rctx request.CTX, payload *model.OutgoingWebhookPayload, hook *model.OutgoingWebhook, post *model.Post, channel *model.Channel) {
 	logger := rctx.Logger().With(mlog.String("outgoing_webhook_id", hook.Id), mlog.String("post_id", post.Id), mlog.String("channel_id", channel.Id), mlog.String("content_type", hook.ContentType))
 
-	var jsonBytes []byte
-	var err error
+	var body []byte
 	contentType := "application/x-www-form-urlencoded"
 	if hook.ContentType == "application/json" {
 		contentType = "application/json"
-		jsonBytes, err = json.Marshal(payload)
+		jsonBytes, err := json.Marshal(payload)
 		if err != nil {
 			logger.Warn("Failed to encode to JSON", mlog.Err(err))
 			return
 		}
+		body = jsonBytes
+	} else {
+		body = []byte(payload.ToFormValues())
 	}
 
 	var wg sync.WaitGroup
 
 	for i := range hook.CallbackURLs {
-		var body io.Reader
-		if hook.ContentType == "application/json" {
-			body = bytes.NewReader(jsonBytes)
-		} else {
-			body = strings.NewReader(payload.ToFormValues())
-		}
 		wg.Add(1)
 
 		// Get the callback URL by index to properly capture it for the go func
@@ -128,63 +140,17 @@ func (a *App) TriggerWebhook(rctx request.CTX, payload *model.OutgoingWebhookPay
 		go func() {
 			defer wg.Done()
 
-			var accessToken *model.OutgoingOAuthConnectionToken
-
-			// Retrieve an access token from a connection if one exists to use for the webhook request
-			if a.Config().ServiceSettings.EnableOutgoingOAuthConnections != nil && *a.Config().ServiceSettings.EnableOutgoingOAuthConnections && a.OutgoingOAuthConnections() != nil {
-				connection, err := a.OutgoingOAuthConnections().GetConnectionForAudience(rctx, url)
-				if err != nil {
-					logger.Error("Failed to find an outgoing oauth connection for the webhook", mlog.Err(err))
-					return
-				}
-
-				if connection != nil {
-					accessToken, err = a.OutgoingOAuthConnections().RetrieveTokenForConnection(rctx, connection)
-					if err != nil {
-						logger.Error("Failed to retrieve token for outgoing oauth connection", mlog.Err(err))
-						return
-					}
-				}
-			}
-
-			webhookResp, err := a.doOutgoingWebhookRequest(url, body, contentType, accessToken)
-			if err != nil {
+			if err := a.sendOutgoingWebhook(rctx, hook, channel, post.Id, url, contentType, body); err != nil {
 				if errors.Is(err, context.DeadlineExceeded) {
 					logger.Error("Outgoing Webhook POST timed out. Consider increasing ServiceSettings.OutgoingIntegrationRequestsTimeout.", mlog.Err(err))
 				} else {
 					logger.Error("Outgoing Webhook POST failed", mlog.Err(err))
 				}
-				return
-			}
 
-			if webhookResp != nil && (webhookResp.Text != nil || len(webhookResp.Attachments) > 0) {
-				postRootId := ""
-				if webhookResp.ResponseType == model.OutgoingHookResponseTypeComment {
-					postRootId = post.Id
-				}
-				if len(webhookResp.Props) == 0 {
-					webhookResp.Props = make(model.StringInterface)
-				}
-				webhookResp.Props[model.PostPropsWebhookDisplayName] = hook.DisplayName
-
-				text := ""
-				if webhookResp.Text != nil {
-					text = a.ProcessSlackText(rctx, *webhookResp.Text)
-				}
-				webhookResp.Attachments = a.ProcessSlackAttachments(rctx, webhookResp.Attachments)
-				// attachments is in here for slack compatibility
-				if len(webhookResp.Attachments) > 0 {
-					webhookResp.Props[model.PostPropsAttachments] = webhookResp.Attachments
-				}
-				if *a.Config().ServiceSettings.EnablePostUsernameOverride && hook.Username != "" && webhookResp.Username == "" {
-					webhookResp.Username = hook.Username
-				}
-
-				if *a.Config().ServiceSettings.EnablePostIconOverride && hook.IconURL != "" && webhookResp.IconURL == "" {
-					webhookResp.IconURL = hook.IconURL
-				}
-				if _, err := a.CreateWebhookPost(rctx, hook.CreatorId, channel, text, webhookResp.Username, webhookResp.IconURL, "", webhookResp.Props, webhookResp.Type, postRootId, webhookResp.Priority); err != nil {
-					logger.Error("Failed to create response post.", mlog.Err(err))
+				// The receiver got the payload when it answered otherwise, so it isn't sent again.
+				var deliveryErr *outgoingWebhookDeliveryError
+				if errors.As(err, &deliveryErr) {
+					a.queueOutgoingWebhookDelivery(rctx, hook, channel, post.Id, url, contentType, body, err)
 				}
 			}
 		}()
@@ -192,11 +158,73 @@ func (a *App) TriggerWebhook(rctx request.CTX, payload *model.OutgoingWebhookPay
 	wg.Wait()
 }
 
-func (a *App) doOutgoingWebhookRequest(url string, body io.Reader, contentType string, accessToken *model.OutgoingOAuthConnectionToken) (*model.OutgoingWebhookResponse, error) {
+// sendOutgoingWebhook posts the given body to a single callback URL of the hook and creates
+// the response post, if any. Failures to deliver the request are returned as an
+// [outgoingWebhookDeliveryError], while other errors mean the receiver rejected the request.
+func (a *App) sendOutgoingWebhook(rctx request.CTX, hook *model.OutgoingWebhook, channel *model.Channel, postID, url, contentType string, body []byte) error {
+	logger := rctx.Logger().With(mlog.String("outgoing_webhook_id", hook.Id), mlog.String("post_id", postID), mlog.String("channel_id", channel.Id), mlog.String("content_type", hook.ContentType))
+
+	var accessToken *model.OutgoingOAuthConnectionToken
+
+	// Retrieve an access token from a connection if one exists to use for the webhook request
+	if a.Config().ServiceSettings.EnableOutgoingOAuthConnections != nil && *a.Config().ServiceSettings.EnableOutgoingOAuthConnections && a.OutgoingOAuthConnections() != nil {
+		connection, err := a.OutgoingOAuthConnections().GetConnectionForAudience(rctx, url)
+		if err != nil {
+			return &outgoingWebhookDeliveryError{fmt.Errorf("failed to find an outgoing oauth connection for the webhook: %w", err)}
+		}
+
+		if connection != nil {
+			accessToken, err = a.OutgoingOAuthConnections().RetrieveTokenForConnection(rctx, connection)
+			if err != nil {
+				return &outgoingWebhookDeliveryError{fmt.Errorf("failed to retrieve token for outgoing oauth connection: %w", err)}
+			}
+		}
+	}
+
+	webhookResp, err := a.doOutgoingWebhookRequest(url, body, contentType, hook.SigningSecret, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if webhookResp != nil && (webhookResp.Text != nil || len(webhookResp.Attachments) > 0) {
+		postRootId := ""
+		if webhookResp.ResponseType == model.OutgoingHookResponseTypeComment {
+			postRootId = postID
+		}
+		if len(webhookResp.Props) == 0 {
+			webhookResp.Props = make(model.StringInterface)
+		}
+		webhookResp.Props[model.PostPropsWebhookDisplayName] = hook.DisplayName
+
+		text := ""
+		if webhookResp.Text != nil {
+			text = a.ProcessSlackText(rctx, *webhookResp.Text)
+		}
+		webhookResp.Attachments = a.ProcessSlackAttachments(rctx, webhookResp.Attachments)
+		// attachments is in here for slack compatibility
+		if len(webhookResp.Attachments) > 0 {
+			webhookResp.Props[model.PostPropsAttachments] = webhookResp.Attachments
+		}
+		if *a.Config().ServiceSettings.EnablePostUsernameOverride && hook.Username != "" && webhookResp.Username == "" {
+			webhookResp.Username = hook.Username
+		}
+
+		if *a.Config().ServiceSettings.EnablePostIconOverride && hook.IconURL != "" && webhookResp.IconURL == "" {
+			webhookResp.IconURL = hook.IconURL
+		}
+		if _, err := a.CreateWebhookPost(rctx, hook.CreatorId, channel, text, webhookResp.Username, webhookResp.IconURL, "", webhookResp.Props, webhookResp.Type, postRootId, webhookResp.Priority); err != nil {
+			logger.Error("Failed to create response post.", mlog.Err(err))
+		}
+	}
+
+	return nil
+}
+
+func (a *App) doOutgoingWebhookRequest(url string, body []byte, contentType string, signingSecret string, accessToken *model.OutgoingOAuthConnectionToken) (*model.OutgoingWebhookResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*a.Config().ServiceSettings.OutgoingIntegrationRequestsTimeout)*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
@@ -204,22 +232,38 @@ func (a *App) doOutgoingWebhookRequest(url string, body io.Reader, contentType s
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Accept", "application/json")
 
+	timestamp := time.Now().Unix()
+	req.Header.Set(model.OutgoingWebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(model.OutgoingWebhookSignatureHeader, model.SignOutgoingWebhookPayload(signingSecret, timestamp, body))
+
 	if accessToken != nil {
 		req.Header.Add("Authorization", accessToken.AsHeaderValue())
 	}
 
 	resp, err := a.Srv().outgoingWebhookClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &outgoingWebhookDeliveryError{err}
 	}
 
 	defer resp.Body.Close()
 
+	// Server errors usually mean the receiver is temporarily unavailable, so treat them
+	// as failed deliveries that can be retried.
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &outgoingWebhookDeliveryError{fmt.Errorf("outgoing webhook request returned status code %d", resp.StatusCode)}
+	}
+
 	var hookResp model.OutgoingWebhookResponse
 	if jsonErr := json.NewDecoder(io.LimitReader(resp.Body, MaxIntegrationResponseSize)).Decode(&hookResp); jsonErr != nil {
 		if jsonErr == io.EOF {
 			return nil, nil
 		}
+		// Receivers often acknowledge the request with a plain text body, which doesn't make
+		// the request any less delivered.
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			a.Log().Debug("Ignoring the response to an outgoing webhook request that isn't a valid JSON", mlog.String("url", url), mlog.Err(jsonErr))
+			return nil, nil
+		}
 		return nil, model.NewAppError("doOutgoingWebhookRequest", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(jsonErr)
 	}
 
@@ -625,6 +669,7 @@ func (a *App) UpdateOutgoingWebhook(rctx request.CTX, oldHook, updatedHook *mode
 	updatedHook.CreateAt = oldHook.CreateAt
 	updatedHook.DeleteAt = oldHook.DeleteAt
 	updatedHook.TeamId = oldHook.TeamId
+	updatedHook.SigningSecret = oldHook.SigningSecret
 	updatedHook.UpdateAt = model.GetMillis()
 
 	webhook, err := a.Srv().Store().Webhook().UpdateOutgoing(updatedHook)
@@ -728,6 +773,21 @@ func (a *App) RegenOutgoingWebhookToken(hook *model.OutgoingWebhook) (*model.Out
 	return webhook, nil
 }
 
+func (a *App) RegenOutgoingWebhookSigningSecret(hook *model.OutgoingWebhook) (*model.OutgoingWebhook, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableOutgoingWebhooks {
+		return nil, model.NewAppError("RegenOutgoingWebhookSigningSecret", "api.outgoing_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	hook.SigningSecret = model.NewOutgoingWebhookSigningSecret()
+
+	webhook, err := a.Srv().Store().Webhook().UpdateOutgoing(hook)
+	if err != nil {
+		return nil, model.NewAppError("RegenOutgoingWebhookSigningSecret", "app.webhooks.update_outgoing.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return webhook, nil
+}
+
 func (a *App) HandleIncomingWebhook(rctx request.CTX, hookID string, req *model.IncomingWebhookRequest) *model.AppError {
 	if !*a.Config().ServiceSettings.EnableIncomingWebhooks {
 		return model.NewAppError("HandleIncomingWebhook", "web.incoming_webhook.disabled.app_error", nil, "", http.StatusNotImplemented)
@@ -1064,7 +1064,7 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		resp, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
 		require.NoError(t, err)
 
 		require.NotNil(t, resp)
@@ -1079,11 +1079,38 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		_, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
+		// The request was still delivered.
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
+		require.NoError(t, err)
+		require.Nil(t, resp)
+	})
+
+	t.Run("with an invalid error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, err := io.Copy(w, strings.NewReader("<html>Not Found</html>"))
+			require.NoError(t, err)
+		}))
+		defer server.Close()
+
+		_, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
 		require.Error(t, err)
 		require.Equal(t, "api.unmarshal_error", err.(*model.AppError).Id)
 	})
 
+	for name, status := range map[string]int{"server error": http.StatusBadGateway, "rate limited": http.StatusTooManyRequests} {
+		t.Run("with a "+name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			_, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
+			var deliveryErr *outgoingWebhookDeliveryError
+			require.ErrorAs(t, err, &deliveryErr)
+		})
+	}
+
 	t.Run("with a large, valid response", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Don't check the error here as the client may disconnect after hitting
@@ -1092,9 +1119,9 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		_, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
-		require.Error(t, err)
-		require.Equal(t, "api.unmarshal_error", err.(*model.AppError).Id)
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
+		require.NoError(t, err)
+		require.Nil(t, resp)
 	})
 
 	t.Run("with a large, invalid response", func(t *testing.T) {
@@ -1105,9 +1132,9 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		_, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
-		require.Error(t, err)
-		require.Equal(t, "api.unmarshal_error", err.(*model.AppError).Id)
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
+		require.NoError(t, err)
+		require.Nil(t, resp)
 	})
 
 	t.Run("with a slow response", func(t *testing.T) {
@@ -1124,9 +1151,11 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 			cfg.ServiceSettings.OutgoingIntegrationRequestsTimeout = model.NewPointer(int64(1))
 		})
 
-		_, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
-		require.Error(t, err)
-		require.IsType(t, &url.Error{}, err)
+		_, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
+		var deliveryErr *outgoingWebhookDeliveryError
+		require.ErrorAs(t, err, &deliveryErr)
+		var urlErr *url.Error
+		require.ErrorAs(t, err, &urlErr)
 	})
 
 	t.Run("with a slow response, long timeout configured", func(t *testing.T) {
@@ -1142,7 +1171,7 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 			cfg.ServiceSettings.OutgoingIntegrationRequestsTimeout = model.NewPointer(int64(2))
 		})
 
-		resp, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
 		require.NoError(t, err)
 		require.NotNil(t, resp)
 		assert.NotNil(t, resp.Text)
@@ -1154,7 +1183,7 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		resp, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", nil)
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", nil)
 		require.NoError(t, err)
 		require.Nil(t, resp)
 	})
@@ -1166,11 +1195,27 @@ func TestDoOutgoingWebhookRequest(t *testing.T) {
 		}))
 		defer server.Close()
 
-		resp, err := th.App.doOutgoingWebhookRequest(server.URL, strings.NewReader(""), "application/json", &model.OutgoingOAuthConnectionToken{
+		resp, err := th.App.doOutgoingWebhookRequest(server.URL, nil, "application/json", "", &model.OutgoingOAuthConnectionToken{
 			AccessToken: "test",
 			TokenType:   "Bearer",
 		})
 		require.NoError(t, err)
 		require.Equal(t, `Bearer test`, *resp.Text)
 	})
+	t.Run("signed request", func(t *testing.T) {
+		secret := model.NewOutgoingWebhookSigningSecret()
+		body := []byte(`{"text":"signed"}`)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Equal(t, body, received)
+			assert.NoError(t, model.VerifyOutgoingWebhookSignature(secret, r.Header.Get(model.OutgoingWebhookSignatureHeader), r.Header.Get(model.OutgoingWebhookTimestampHeader), received, 0))
+			assert.ErrorIs(t, model.VerifyOutgoingWebhookSignature("othersecret", r.Header.Get(model.OutgoingWebhookSignatureHeader), r.Header.Get(model.OutgoingWebhookTimestampHeader), received, 0), model.ErrOutgoingWebhookSignatureMismatch)
+		}))
+		defer server.Close()
+
+		_, err := th.App.doOutgoingWebhookRequest(server.URL, body, "application/json", secret, nil)
+		require.NoError(t, err)
+	})
 }
@@ -0,0 +1,2 @@
+ALTER TABLE outgoingwebhooks ADD COLUMN IF NOT EXISTS signingsecret varchar(64) DEFAULT '';
+UPDATE outgoingwebhooks SET signingsecret = replace(gen_random_uuid()::text, '-', '') WHERE signingsecret = '';
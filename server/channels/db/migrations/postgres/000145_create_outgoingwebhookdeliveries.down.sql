@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_outgoingwebhookdeliveries_hookid_createat;
+DROP INDEX IF EXISTS idx_outgoingwebhookdeliveries_status_nextattemptat;
+DROP TABLE IF EXISTS OutgoingWebhookDeliveries;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS OutgoingWebhookDeliveries (
+    Id varchar(26) PRIMARY KEY,
+    HookId varchar(26) NOT NULL,
+    TeamId varchar(26) NOT NULL,
+    ChannelId varchar(26) NOT NULL DEFAULT '',
+    PostId varchar(26) NOT NULL DEFAULT '',
+    CallbackURL varchar(1024) NOT NULL,
+    ContentType varchar(128) NOT NULL DEFAULT '',
+    Payload text,
+    Status varchar(32) NOT NULL,
+    Attempts integer NOT NULL DEFAULT 0,
+    LastError varchar(1024) NOT NULL DEFAULT '',
+    NextAttemptAt bigint NOT NULL DEFAULT 0,
+    CreateAt bigint NOT NULL,
+    UpdateAt bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outgoingwebhookdeliveries_status_nextattemptat ON OutgoingWebhookDeliveries(Status, NextAttemptAt);
+CREATE INDEX IF NOT EXISTS idx_outgoingwebhookdeliveries_hookid_createat ON OutgoingWebhookDeliveries(HookId, CreateAt);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webauthncredentials_userid;
+DROP INDEX IF EXISTS idx_webauthncredentials_credentialid;
+DROP TABLE IF EXISTS WebAuthnCredentials;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS WebAuthnCredentials (
+    Id varchar(26) PRIMARY KEY,
+    UserId varchar(26) NOT NULL,
+    Name varchar(64) NOT NULL,
+    CredentialId varchar(1024) NOT NULL,
+    PublicKey bytea NOT NULL,
+    AAGUID varchar(64) NOT NULL DEFAULT '',
+    SignCount bigint NOT NULL DEFAULT 0,
+    CreateAt bigint NOT NULL,
+    LastUsedAt bigint NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webauthncredentials_credentialid ON WebAuthnCredentials(CredentialId);
+CREATE INDEX IF NOT EXISTS idx_webauthncredentials_userid ON WebAuthnCredentials(UserId);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pendingemailnotifications_userid_createat;
+DROP TABLE IF EXISTS PendingEmailNotifications;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS PendingEmailNotifications (
+    Id varchar(26) PRIMARY KEY,
+    UserId varchar(26) NOT NULL,
+    PostId varchar(26) NOT NULL,
+    TeamName varchar(64) NOT NULL,
+    CreateAt bigint NOT NULL,
+    ClaimedAt bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_pendingemailnotifications_userid_createat ON PendingEmailNotifications(UserId, CreateAt);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_fileblobs_unreferenced_updateat;
+DROP TABLE IF EXISTS FileBlobs;
+
+ALTER TABLE FileInfo DROP COLUMN IF EXISTS ContentHash;
@@ -0,0 +1,11 @@
+ALTER TABLE FileInfo ADD COLUMN IF NOT EXISTS ContentHash varchar(64) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS FileBlobs (
+    Hash varchar(64) PRIMARY KEY,
+    Size bigint NOT NULL,
+    RefCount bigint NOT NULL,
+    CreateAt bigint NOT NULL,
+    UpdateAt bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_fileblobs_unreferenced_updateat ON FileBlobs(UpdateAt) WHERE RefCount <= 0;
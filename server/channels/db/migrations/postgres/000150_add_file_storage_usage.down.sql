@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_filestorageusage_ownertype_usedbytes;
+DROP TABLE IF EXISTS FileStorageUsage;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS FileStorageUsage (
+    OwnerId varchar(26) NOT NULL,
+    OwnerType varchar(8) NOT NULL,
+    UsedBytes bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (OwnerType, OwnerId)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filestorageusage_ownertype_usedbytes ON FileStorageUsage(OwnerType, UsedBytes);
+
+INSERT INTO FileStorageUsage (OwnerId, OwnerType, UsedBytes)
+SELECT CreatorId, 'user', SUM(Size)
+FROM FileInfo
+WHERE CreatorId != ''
+GROUP BY CreatorId
+ON CONFLICT DO NOTHING;
+
+INSERT INTO FileStorageUsage (OwnerId, OwnerType, UsedBytes)
+SELECT Channels.TeamId, 'team', SUM(FileInfo.Size)
+FROM FileInfo
+JOIN Channels ON Channels.Id = FileInfo.ChannelId
+WHERE Channels.TeamId != ''
+GROUP BY Channels.TeamId
+ON CONFLICT DO NOTHING;
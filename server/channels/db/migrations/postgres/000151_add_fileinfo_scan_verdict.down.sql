@@ -0,0 +1,2 @@
+ALTER TABLE FileInfo DROP COLUMN IF EXISTS ScanSignature;
+ALTER TABLE FileInfo DROP COLUMN IF EXISTS ScanVerdict;
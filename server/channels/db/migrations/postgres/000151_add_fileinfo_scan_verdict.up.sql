@@ -0,0 +1,2 @@
+ALTER TABLE FileInfo ADD COLUMN IF NOT EXISTS ScanVerdict varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE FileInfo ADD COLUMN IF NOT EXISTS ScanSignature varchar(256) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- morph:nontransactional
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_fileinfo_infected_createat ON FileInfo (CreateAt) WHERE ScanVerdict = 'infected';
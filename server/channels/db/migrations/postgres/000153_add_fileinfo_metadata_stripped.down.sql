@@ -0,0 +1,2 @@
+ALTER TABLE FileInfo DROP COLUMN IF EXISTS OriginalPath;
+ALTER TABLE FileInfo DROP COLUMN IF EXISTS MetadataStripped;
@@ -0,0 +1,2 @@
+ALTER TABLE FileInfo ADD COLUMN IF NOT EXISTS MetadataStripped boolean NOT NULL DEFAULT false;
+ALTER TABLE FileInfo ADD COLUMN IF NOT EXISTS OriginalPath varchar(512) NOT NULL DEFAULT '';
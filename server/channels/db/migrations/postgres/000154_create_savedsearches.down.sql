@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_savedsearches_watch;
+DROP INDEX IF EXISTS idx_savedsearches_userid;
+DROP TABLE IF EXISTS SavedSearches;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS SavedSearches (
+    Id varchar(26) PRIMARY KEY,
+    UserId varchar(26) NOT NULL,
+    TeamId varchar(26) NOT NULL DEFAULT '',
+    Name varchar(64) NOT NULL,
+    Terms varchar(1024) NOT NULL,
+    IsOrSearch boolean NOT NULL DEFAULT false,
+    TimeZoneOffset integer NOT NULL DEFAULT 0,
+    Params jsonb NOT NULL,
+    Watch boolean NOT NULL DEFAULT false,
+    CreateAt bigint NOT NULL,
+    UpdateAt bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_savedsearches_userid ON SavedSearches(UserId);
+CREATE INDEX IF NOT EXISTS idx_savedsearches_watch ON SavedSearches(Id) WHERE Watch;
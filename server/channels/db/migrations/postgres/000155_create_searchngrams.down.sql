@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_searchngrams_ngrams;
+DROP TABLE IF EXISTS SearchNgrams;
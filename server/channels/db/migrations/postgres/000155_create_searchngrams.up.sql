@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS SearchNgrams (
+    ObjectType varchar(16) NOT NULL,
+    ObjectId varchar(26) NOT NULL,
+    Ngrams text[] NOT NULL,
+    PRIMARY KEY (ObjectId, ObjectType)
+);
+
+CREATE INDEX IF NOT EXISTS idx_searchngrams_ngrams ON SearchNgrams USING gin (Ngrams);
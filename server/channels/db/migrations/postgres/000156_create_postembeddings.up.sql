@@ -0,0 +1,30 @@
+-- The embeddings are stored with pgvector, so the table is only created when the vector
+-- extension is available. The semantic search can't be enabled otherwise.
+DO $$
+DECLARE
+    vector_available boolean := false;
+BEGIN
+SELECT count(*) != 0 INTO vector_available
+    FROM pg_available_extensions
+    WHERE name = 'vector';
+IF vector_available THEN
+    BEGIN
+        CREATE EXTENSION IF NOT EXISTS vector;
+    EXCEPTION WHEN insufficient_privilege THEN
+        RAISE WARNING 'The vector extension must be created by a superuser to enable the semantic search';
+        RETURN;
+    END;
+
+    -- The size of the embeddings depends on the configured model, so the column is typed
+    -- and given its HNSW index by the embedding job, once the size is known.
+    CREATE TABLE IF NOT EXISTS PostEmbeddings (
+        PostId varchar(26) PRIMARY KEY,
+        ChannelId varchar(26) NOT NULL,
+        Model varchar(128) NOT NULL,
+        PostEditAt bigint NOT NULL DEFAULT 0,
+        Embedding vector NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_postembeddings_channelid_model ON PostEmbeddings (ChannelId, Model);
+END IF;
+END $$;
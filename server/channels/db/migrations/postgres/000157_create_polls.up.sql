@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS Polls (
+    PostId varchar(26) PRIMARY KEY,
+    Question varchar(1000) NOT NULL,
+    Options jsonb NOT NULL,
+    Anonymous boolean NOT NULL DEFAULT false,
+    MultipleChoice boolean NOT NULL DEFAULT false,
+    CreateAt bigint NOT NULL,
+    UpdateAt bigint NOT NULL,
+    ClosedAt bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS PollVotes (
+    PostId varchar(26) NOT NULL,
+    OptionId varchar(26) NOT NULL,
+    UserId varchar(26) NOT NULL,
+    CreateAt bigint NOT NULL,
+    PRIMARY KEY (PostId, UserId, OptionId)
+);
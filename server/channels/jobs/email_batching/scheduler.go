@@ -0,0 +1,27 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package email_batching
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+type Scheduler struct {
+	*jobs.PeriodicScheduler
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, _ time.Time, _ bool, _ *model.Job) *time.Time {
+	nextTime := time.Now().Add(time.Duration(*cfg.EmailSettings.EmailBatchingInterval) * time.Second)
+	return &nextTime
+}
+
+func MakeScheduler(jobServer *jobs.JobServer) *Scheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.EmailSettings.EnableEmailBatching
+	}
+	return &Scheduler{jobs.NewPeriodicScheduler(jobServer, model.JobTypeEmailBatching, 0, isEnabled)}
+}
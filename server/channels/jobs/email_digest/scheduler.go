@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package email_digest
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// Digests are due at a given hour in each user's timezone, so the job runs several
+// times an hour and only sends the digests that became due since they were last sent.
+const schedFreq = 15 * time.Minute
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.EmailSettings.EnableEmailDigest && *cfg.EmailSettings.SendEmailNotifications
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypeEmailDigest, schedFreq, isEnabled)
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embedded_search_indexing
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const timeBetweenBatches = 100 * time.Millisecond
+
+// Engine is the part of the embedded search engine indexing the batches of the job.
+type Engine interface {
+	IsActive() bool
+	IndexPostsBatch(posts []*model.PostForIndexing) *model.AppError
+	IndexChannelsBatch(channels []*model.Channel, userIDs, teamMemberIDs map[string][]string) *model.AppError
+	IndexUsersBatch(users []*model.UserForIndexing) *model.AppError
+	IndexFilesBatch(files []*model.FileForIndexing) *model.AppError
+	Commit() *model.AppError
+}
+
+// batch describes the entities indexed by a batch: their number, and the creation time and
+// ID of the last one, from which the next batch starts.
+type batch struct {
+	count        int
+	lastCreateAt int64
+	lastID       string
+}
+
+// entity is a kind of entity indexed by the job. Its progress is kept in the job data, under
+// the index_<name>, start_<idKey>_id, done_<name>_count and done_<name> keys.
+type entity struct {
+	name  string
+	idKey string
+	index func(rctx request.CTX, store store.Store, engine Engine, startTime int64, startID string, limit int) (batch, *model.AppError)
+}
+
+// entities are indexed in order, every entity from the original start time of the job.
+var entities = []entity{
+	{name: "posts", idKey: "post", index: indexPosts},
+	{name: "channels", idKey: "channel", index: indexChannels},
+	{name: "users", idKey: "user", index: indexUsers},
+	{name: "files", idKey: "file", index: indexFiles},
+}
+
+// MakeWorker creates a worker indexing the posts, channels, users and files in the embedded
+// search engine. Stopped jobs resume from the last completed batch.
+func MakeWorker(jobServer *jobs.JobServer, store store.Store, engine Engine) *jobs.BatchWorker {
+	doBatch := func(rctx request.CTX, job *model.Job) bool {
+		done, appErr := indexBatch(rctx, jobServer, store, engine, job)
+		if appErr != nil {
+			rctx.Logger().Error("Failed to index a batch for the embedded search engine", mlog.Err(appErr))
+			if err := jobServer.SetJobError(job, appErr); err != nil {
+				rctx.Logger().Error("Worker: Failed to set job error", mlog.Err(err))
+			}
+			return true
+		}
+
+		if appErr := jobServer.SetJobProgress(job, progress(job)); appErr != nil {
+			rctx.Logger().Error("Worker: Failed to update progress for job", mlog.Err(appErr))
+			return true
+		}
+
+		if done {
+			if appErr := engine.Commit(); appErr != nil {
+				rctx.Logger().Warn("Failed to commit the embedded search indexes", mlog.Err(appErr))
+			}
+			if appErr := jobServer.SetJobSuccess(job); appErr != nil {
+				rctx.Logger().Error("Worker: Failed to set success for job", mlog.Err(appErr))
+			}
+			return true
+		}
+		return false
+	}
+	return jobs.MakeBatchWorker(jobServer, store, timeBetweenBatches, doBatch)
+}
+
+// initJobData sets the entities to index, all of them unless specified otherwise, and the
+// time range of the entities to index.
+func initJobData(store store.Store, job *model.Job) *model.AppError {
+	if _, ok := job.Data["end_time"]; ok {
+		return nil
+	}
+
+	for _, e := range entities {
+		raw, ok := job.Data["index_"+e.name]
+		job.Data["index_"+e.name] = strconv.FormatBool(!ok || raw == "true")
+	}
+
+	startTime, ok := job.Data["start_time"]
+	if !ok {
+		oldest, err := store.Post().GetOldestEntityCreationTime()
+		if err != nil {
+			return model.NewAppError("initJobData", "app.job.embedded_search_indexing.oldest_entity.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		startTime = strconv.FormatInt(oldest, 10)
+	}
+	job.Data["start_time"] = startTime
+	job.Data["original_start_time"] = startTime
+	job.Data["end_time"] = strconv.FormatInt(model.GetMillis(), 10)
+
+	return nil
+}
+
+// indexBatch indexes the next batch of the job, returning whether there is nothing left to
+// index.
+func indexBatch(rctx request.CTX, jobServer *jobs.JobServer, store store.Store, engine Engine, job *model.Job) (bool, *model.AppError) {
+	if !engine.IsActive() {
+		return false, model.NewAppError("indexBatch", "embedded_search.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if appErr := initJobData(store, job); appErr != nil {
+		return false, appErr
+	}
+
+	startTime, err := strconv.ParseInt(job.Data["start_time"], 10, 64)
+	if err != nil {
+		return false, model.NewAppError("indexBatch", "app.job.embedded_search_indexing.parse_time.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	endTime, err := strconv.ParseInt(job.Data["end_time"], 10, 64)
+	if err != nil {
+		return false, model.NewAppError("indexBatch", "app.job.embedded_search_indexing.parse_time.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	for _, e := range entities {
+		if job.Data["index_"+e.name] != "true" || job.Data["done_"+e.name] == "true" {
+			continue
+		}
+
+		b, appErr := e.index(rctx, store, engine, startTime, job.Data["start_"+e.idKey+"_id"], *jobServer.Config().EmbeddedSearchSettings.BatchSize)
+		if appErr != nil {
+			return false, appErr
+		}
+
+		doneCount, _ := strconv.ParseInt(job.Data["done_"+e.name+"_count"], 10, 64)
+		job.Data["done_"+e.name+"_count"] = strconv.FormatInt(doneCount+int64(b.count), 10)
+
+		if b.count == 0 || b.lastCreateAt >= endTime {
+			// The next entity starts from the beginning.
+			job.Data["done_"+e.name] = "true"
+			job.Data["start_time"] = job.Data["original_start_time"]
+			delete(job.Data, "start_"+e.idKey+"_id")
+		} else {
+			job.Data["start_time"] = strconv.FormatInt(b.lastCreateAt, 10)
+			job.Data["start_"+e.idKey+"_id"] = b.lastID
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// progress returns the percentage of the entities to index which are done.
+func progress(job *model.Job) int64 {
+	var enabled, done int64
+	for _, e := range entities {
+		if job.Data["index_"+e.name] != "true" {
+			continue
+		}
+		enabled++
+		if job.Data["done_"+e.name] == "true" {
+			done++
+		}
+	}
+	if enabled == 0 {
+		return 100
+	}
+	return done * 100 / enabled
+}
+
+func indexPosts(rctx request.CTX, store store.Store, engine Engine, startTime int64, startID string, limit int) (batch, *model.AppError) {
+	posts, err := store.Post().GetPostsBatchForIndexing(startTime, startID, limit)
+	if err != nil {
+		return batch{}, model.NewAppError("indexPosts", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if len(posts) == 0 {
+		return batch{}, nil
+	}
+
+	if appErr := engine.IndexPostsBatch(posts); appErr != nil {
+		return batch{}, appErr
+	}
+
+	last := posts[len(posts)-1]
+	return batch{count: len(posts), lastCreateAt: last.CreateAt, lastID: last.Id}, nil
+}
+
+func indexChannels(rctx request.CTX, store store.Store, engine Engine, startTime int64, startID string, limit int) (batch, *model.AppError) {
+	channels, err := store.Channel().GetChannelsBatchForIndexing(startTime, startID, limit)
+	if err != nil {
+		return batch{}, model.NewAppError("indexChannels", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if len(channels) == 0 {
+		return batch{}, nil
+	}
+
+	userIDs := make(map[string][]string, len(channels))
+	teamMemberIDs := make(map[string][]string, len(channels))
+	for _, channel := range channels {
+		// Only the members of the private channels are needed to search them.
+		if channel.Type == model.ChannelTypePrivate {
+			if userIDs[channel.Id], err = store.Channel().GetAllChannelMemberIdsByChannelId(channel.Id); err != nil {
+				return batch{}, model.NewAppError("indexChannels", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+			}
+		}
+		if teamMemberIDs[channel.Id], err = store.Channel().GetTeamMembersForChannel(rctx, channel.Id); err != nil {
+			return batch{}, model.NewAppError("indexChannels", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	if appErr := engine.IndexChannelsBatch(channels, userIDs, teamMemberIDs); appErr != nil {
+		return batch{}, appErr
+	}
+
+	last := channels[len(channels)-1]
+	return batch{count: len(channels), lastCreateAt: last.CreateAt, lastID: last.Id}, nil
+}
+
+func indexUsers(rctx request.CTX, store store.Store, engine Engine, startTime int64, startID string, limit int) (batch, *model.AppError) {
+	users, err := store.User().GetUsersBatchForIndexing(startTime, startID, limit)
+	if err != nil {
+		return batch{}, model.NewAppError("indexUsers", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if len(users) == 0 {
+		return batch{}, nil
+	}
+
+	if appErr := engine.IndexUsersBatch(users); appErr != nil {
+		return batch{}, appErr
+	}
+
+	last := users[len(users)-1]
+	return batch{count: len(users), lastCreateAt: last.CreateAt, lastID: last.Id}, nil
+}
+
+func indexFiles(rctx request.CTX, store store.Store, engine Engine, startTime int64, startID string, limit int) (batch, *model.AppError) {
+	files, err := store.FileInfo().GetFilesBatchForIndexing(startTime, startID, true, limit)
+	if err != nil {
+		return batch{}, model.NewAppError("indexFiles", "app.job.embedded_search_indexing.get_batch.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if len(files) == 0 {
+		return batch{}, nil
+	}
+
+	if appErr := engine.IndexFilesBatch(files); appErr != nil {
+		return batch{}, appErr
+	}
+
+	last := files[len(files)-1]
+	return batch{count: len(files), lastCreateAt: last.CreateAt, lastID: last.Id}, nil
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_deduplication
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// Files uploaded before deduplication was enabled only need to be migrated once, but the
+// job also removes the blobs left without references, which only happens once a day.
+const schedFreq = 24 * time.Hour
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableContentDeduplication
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypeFileDeduplication, schedFreq, isEnabled)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_deduplication
+
+import (
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+const jobName = "FileDeduplication"
+
+type AppIface interface {
+	DeduplicateFiles(rctx request.CTX) (int, int, error)
+}
+
+func MakeWorker(jobServer *jobs.JobServer, app AppIface) *jobs.SimpleWorker {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableContentDeduplication
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		deduplicated, removed, err := app.DeduplicateFiles(request.EmptyContext(logger))
+		if job.Data == nil {
+			job.Data = make(model.StringMap)
+		}
+		job.Data["deduplicated_files"] = strconv.Itoa(deduplicated)
+		job.Data["removed_blobs"] = strconv.Itoa(removed)
+		if appErr := jobServer.UpdateInProgressJobData(job); appErr != nil {
+			logger.Warn("Failed to update the job data", mlog.Err(appErr))
+		}
+		return err
+	}
+	worker := jobs.NewSimpleWorker(jobName, jobServer, execute, isEnabled)
+	return worker
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_encryption_rewrap
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// Data keys only need to be re-wrapped after the master key was rotated, and the job returns
+// right away while no retired master key is configured.
+const schedFreq = 24 * time.Hour
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableEncryptionAtRest
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypeFileEncryptionRewrap, schedFreq, isEnabled)
+}
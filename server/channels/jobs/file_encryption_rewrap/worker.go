@@ -0,0 +1,39 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_encryption_rewrap
+
+import (
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+const jobName = "FileEncryptionRewrap"
+
+type AppIface interface {
+	RewrapFileEncryptionKeys(logger mlog.LoggerIFace) (int, error)
+}
+
+func MakeWorker(jobServer *jobs.JobServer, app AppIface) *jobs.SimpleWorker {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableEncryptionAtRest
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		rewrapped, err := app.RewrapFileEncryptionKeys(logger)
+		if job.Data == nil {
+			job.Data = make(model.StringMap)
+		}
+		job.Data["rewrapped_files"] = strconv.Itoa(rewrapped)
+		if appErr := jobServer.UpdateInProgressJobData(job); appErr != nil {
+			logger.Warn("Failed to update the job data", mlog.Err(appErr))
+		}
+		return err
+	}
+	worker := jobs.NewSimpleWorker(jobName, jobServer, execute, isEnabled)
+	return worker
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_storage_migration
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const timeBetweenBatches = 100 * time.Millisecond
+
+type AppIface interface {
+	MigrateFileStorageBatch(rctx request.CTX, data model.StringMap) (bool, int64, error)
+}
+
+// MakeWorker creates a worker copying the stored files to the file storage migration target
+// in batches. Stopped jobs resume from the last completed batch.
+func MakeWorker(jobServer *jobs.JobServer, store store.Store, app AppIface) *jobs.BatchWorker {
+	doBatch := func(rctx request.CTX, job *model.Job) bool {
+		done, progress, err := app.MigrateFileStorageBatch(rctx, job.Data)
+		if err != nil {
+			rctx.Logger().Error("Failed to migrate the file storage", mlog.Err(err))
+			if appErr := jobServer.SetJobError(job, model.NewAppError("doBatch", model.NoTranslation, nil, "", http.StatusInternalServerError).Wrap(err)); appErr != nil {
+				rctx.Logger().Error("Worker: Failed to set job error", mlog.Err(appErr))
+			}
+			return true
+		}
+
+		if appErr := jobServer.SetJobProgress(job, progress); appErr != nil {
+			rctx.Logger().Error("Worker: Failed to update progress for job", mlog.Err(appErr))
+			return true
+		}
+
+		if done {
+			if appErr := jobServer.SetJobSuccess(job); appErr != nil {
+				rctx.Logger().Error("Worker: Failed to set success for job", mlog.Err(appErr))
+			}
+			return true
+		}
+		return false
+	}
+	return jobs.MakeBatchWorker(jobServer, store, timeBetweenBatches, doBatch)
+}
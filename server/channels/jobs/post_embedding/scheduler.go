@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package post_embedding
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// New and edited posts are embedded in batches, so the job runs often enough for them to be
+// found by the semantic search shortly after being posted.
+const schedFreq = 1 * time.Minute
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.SemanticSearchSettings.Enable
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypePostEmbedding, schedFreq, isEnabled)
+}
@@ -34,9 +34,10 @@ type S3PathMigrationWorker struct {
 }
 
 func MakeWorker(jobServer *jobs.JobServer, store store.Store, fileBackend filestore.FileBackend) *S3PathMigrationWorker {
+	// The S3 backend may be wrapped, for instance when files are encrypted at rest.
 	// If the type cast fails, it will be nil
 	// which is checked later.
-	s3Backend, _ := fileBackend.(*filestore.S3FileBackend)
+	s3Backend, _ := filestore.UnwrapFileBackend(fileBackend).(*filestore.S3FileBackend)
 	const workerName = "S3PathMigration"
 	worker := &S3PathMigrationWorker{
 		name:        workerName,
@@ -115,7 +116,7 @@ func (worker *S3PathMigrationWorker) DoJob(job *model.Job) {
 	}
 
 	if worker.fileBackend == nil {
-		err := errors.New("no S3 file backend found")
+		err := errors.New("no S3 file backend found, the paths of the files can only be migrated on S3")
 		logger.Error("S3PathMigrationWorker: ", mlog.Err(err))
 		worker.setJobError(logger, job, model.NewAppError("DoJob", model.NoTranslation, nil, "", http.StatusInternalServerError).Wrap(err))
 		return
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package saved_search_watch
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// New posts are matched against the watched saved searches in batches rather than when
+// they are created, so the job runs often enough for the notifications to stay timely.
+const schedFreq = 1 * time.Minute
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.ServiceSettings.EnableSavedSearchWatch
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypeSavedSearchWatch, schedFreq, isEnabled)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 )
 
@@ -86,6 +87,24 @@ func (s LocalCacheFileInfoStore) GetByIds(ids []string, includeDeleted, allowFro
 	return fileInfos, nil
 }
 
+func (s LocalCacheFileInfoStore) SetContentHashForPath(rctx request.CTX, path, hash, blobPath string, size int64) ([]*model.FileInfo, error) {
+	infos, err := s.FileInfoStore.SetContentHashForPath(rctx, path, hash, blobPath, size)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		s.rootStore.doInvalidateCacheCluster(s.rootStore.fileInfoCache, fmt.Sprintf("%s_%t", info.Id, true), nil)
+		s.rootStore.doInvalidateCacheCluster(s.rootStore.fileInfoCache, fmt.Sprintf("%s_%t", info.Id, false), nil)
+		if info.PostId != "" {
+			s.InvalidateFileInfosForPostCache(info.PostId, true)
+			s.InvalidateFileInfosForPostCache(info.PostId, false)
+		}
+	}
+
+	return infos, nil
+}
+
 func (s LocalCacheFileInfoStore) ClearCaches() {
 	s.rootStore.fileInfoCache.Purge()
 	if s.rootStore.metrics != nil {
@@ -68,6 +68,13 @@ func genStore() *mocks.Store {
 	mock.On("AccessControlPolicy").Return(&mocks.AccessControlPolicyStore{})
 	mock.On("Attributes").Return(&mocks.AttributesStore{})
 	mock.On("ContentFlagging").Return(&mocks.ContentFlaggingStore{})
+	mock.On("OutgoingWebhookDelivery").Return(&mocks.OutgoingWebhookDeliveryStore{})
+	mock.On("WebAuthnCredential").Return(&mocks.WebAuthnCredentialStore{})
+	mock.On("PendingEmailNotification").Return(&mocks.PendingEmailNotificationStore{})
+	mock.On("SavedSearch").Return(&mocks.SavedSearchStore{})
+	mock.On("SearchNgram").Return(&mocks.SearchNgramStore{})
+	mock.On("PostEmbedding").Return(&mocks.PostEmbeddingStore{})
+	mock.On("Poll").Return(&mocks.PollStore{})
 	return mock
 }
 
@@ -185,8 +185,17 @@ func (s SearchPostStore) searchPostsForUserByEngine(engine searchengine.SearchEn
 }
 
 func (s SearchPostStore) SearchPostsForUser(rctx request.CTX, paramsList []*model.SearchParams, userId, teamId string, page, perPage int) (*model.PostSearchResults, error) {
+	// The search engines don't index the reactions of the posts, which are added without
+	// updating the posts, so has:reactions searches always run on the database.
+	filtersOnReactions := false
+	for _, params := range paramsList {
+		if params.HasReactions != nil {
+			filtersOnReactions = true
+		}
+	}
+
 	for _, engine := range s.rootStore.searchEngine.GetActiveEngines() {
-		if engine.IsSearchEnabled() {
+		if engine.IsSearchEnabled() && !filtersOnReactions {
 			results, err := s.searchPostsForUserByEngine(engine, paramsList, userId, teamId, page, perPage)
 			if err != nil {
 				rctx.Logger().Warn("Encountered error on SearchPostsInTeamForUser.", mlog.String("search_engine", engine.GetName()), mlog.Err(err))
@@ -30,6 +30,16 @@ var searchPostStoreTests = []searchTest{
 		Fn:   testSearchReturnPinnedAndUnpinned,
 		Tags: []string{EngineAll},
 	},
+	{
+		Name: "Should be able to filter posts by their attributes",
+		Fn:   testSearchPostsByAttributes,
+		Tags: []string{EngineAll},
+	},
+	{
+		Name: "Should be able to filter posts by their reactions",
+		Fn:   testSearchPostsByReactions,
+		Tags: []string{EnginePostgres},
+	},
 	{
 		Name: "Should be able to search for quoted patterns with AND OR combinations",
 		Fn:   testSearchANDORQuotesCombinations,
@@ -369,6 +379,111 @@ func testSearchReturnPinnedAndUnpinned(t *testing.T, th *SearchTestHelper) {
 	th.checkPostInSearchResults(t, p2.Id, results.Posts)
 }
 
+func testSearchPostsByAttributes(t *testing.T, th *SearchTestHelper) {
+	bot, err := th.createBot("attributesbot", "Attributes Bot", th.User.Id)
+	require.NoError(t, err)
+	defer th.deleteBotUser(bot.UserId)
+	err = th.addUserToTeams(model.UserFromBot(bot), []string{th.Team.Id})
+	require.NoError(t, err)
+
+	p1, err := th.createPost(th.User.Id, th.ChannelBasic.Id, "release pinned", "", model.PostTypeDefault, 0, true)
+	require.NoError(t, err)
+	fileModel := th.createPostModel(th.User.Id, th.ChannelBasic.Id, "release with a file", "", model.PostTypeDefault, 1000001, false)
+	fileModel.FileIds = model.StringArray{model.NewId()}
+	p2, err := th.Store.Post().Save(th.Context, fileModel)
+	require.NoError(t, err)
+	p3, err := th.createReply(th.User.Id, "release notes at https://example.com", "", p1, 1000002, false)
+	require.NoError(t, err)
+	botModel := th.createPostModel(bot.UserId, th.ChannelBasic.Id, "release from a bot", "", model.PostTypeDefault, 1000003, false)
+	botModel.AddProp(model.PostPropsFromBot, "true")
+	p4, err := th.Store.Post().Save(th.Context, botModel)
+	require.NoError(t, err)
+	defer th.deleteUserPosts(th.User.Id)
+	defer th.deleteUserPosts(bot.UserId)
+
+	testCases := []struct {
+		name        string
+		params      *model.SearchParams
+		expectedIDs []string
+	}{
+		{
+			name:        "pinned",
+			params:      &model.SearchParams{Terms: "release", IsPinned: model.NewPointer(true)},
+			expectedIDs: []string{p1.Id},
+		},
+		{
+			name:        "not pinned",
+			params:      &model.SearchParams{Terms: "release", IsPinned: model.NewPointer(false)},
+			expectedIDs: []string{p2.Id, p3.Id, p4.Id},
+		},
+		{
+			name:        "with a file",
+			params:      &model.SearchParams{Terms: "release", HasFile: model.NewPointer(true)},
+			expectedIDs: []string{p2.Id},
+		},
+		{
+			name:        "with a link",
+			params:      &model.SearchParams{Terms: "release", HasLink: model.NewPointer(true)},
+			expectedIDs: []string{p3.Id},
+		},
+		{
+			name:        "in a thread",
+			params:      &model.SearchParams{Terms: "release", InThread: model.NewPointer(true)},
+			expectedIDs: []string{p3.Id},
+		},
+		{
+			name:        "not in a thread",
+			params:      &model.SearchParams{Terms: "release", InThread: model.NewPointer(false)},
+			expectedIDs: []string{p1.Id, p2.Id, p4.Id},
+		},
+		{
+			name:        "from a bot without terms",
+			params:      &model.SearchParams{FromBots: model.NewPointer(true)},
+			expectedIDs: []string{p4.Id},
+		},
+		{
+			name:        "not from a bot with a file",
+			params:      &model.SearchParams{Terms: "release", FromBots: model.NewPointer(false), HasFile: model.NewPointer(true)},
+			expectedIDs: []string{p2.Id},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := th.Store.Post().SearchPostsForUser(th.Context, []*model.SearchParams{tc.params}, th.User.Id, th.Team.Id, 0, 20)
+			require.NoError(t, err)
+
+			require.Len(t, results.Posts, len(tc.expectedIDs))
+			for _, id := range tc.expectedIDs {
+				th.checkPostInSearchResults(t, id, results.Posts)
+			}
+		})
+	}
+}
+
+func testSearchPostsByReactions(t *testing.T, th *SearchTestHelper) {
+	p1, err := th.createPost(th.User.Id, th.ChannelBasic.Id, "release with reactions", "", model.PostTypeDefault, 0, false)
+	require.NoError(t, err)
+	p2, err := th.createPost(th.User.Id, th.ChannelBasic.Id, "release without reactions", "", model.PostTypeDefault, 0, false)
+	require.NoError(t, err)
+	defer th.deleteUserPosts(th.User.Id)
+
+	_, err = th.Store.Reaction().Save(&model.Reaction{UserId: th.User.Id, PostId: p1.Id, EmojiName: "smile", ChannelId: p1.ChannelId})
+	require.NoError(t, err)
+
+	params := &model.SearchParams{Terms: "release", HasReactions: model.NewPointer(true)}
+	results, err := th.Store.Post().SearchPostsForUser(th.Context, []*model.SearchParams{params}, th.User.Id, th.Team.Id, 0, 20)
+	require.NoError(t, err)
+	require.Len(t, results.Posts, 1)
+	th.checkPostInSearchResults(t, p1.Id, results.Posts)
+
+	params = &model.SearchParams{Terms: "release", HasReactions: model.NewPointer(false)}
+	results, err = th.Store.Post().SearchPostsForUser(th.Context, []*model.SearchParams{params}, th.User.Id, th.Team.Id, 0, 20)
+	require.NoError(t, err)
+	require.Len(t, results.Posts, 1)
+	th.checkPostInSearchResults(t, p2.Id, results.Posts)
+}
+
 func testSearchANDORQuotesCombinations(t *testing.T, th *SearchTestHelper) {
 	p1, err := th.createPost(th.User.Id, th.ChannelBasic.Id, "one two three four", "", model.PostTypeDefault, 0, false)
 	require.NoError(t, err)
@@ -615,9 +615,14 @@ func (s SqlChannelStore) Save(rctx request.CTX, channel *model.Channel, maxChann
 		return nil, errors.Wrap(err, "upsert_public_channel")
 	}
 
+	if err = s.indexSearchNgrams(transaction, searchNgramObjectTypeChannel, channelNgramTexts([]*model.Channel{newChannel})); err != nil {
+		return nil, err
+	}
+
 	if err = transaction.Commit(); err != nil {
 		return nil, errors.Wrap(err, "commit_transaction")
 	}
+
 	// There are cases when in case of conflict, the original channel value is returned.
 	// So we return both and let the caller do the checks.
 	return newChannel, err
@@ -767,9 +772,14 @@ func (s SqlChannelStore) Update(rctx request.CTX, channel *model.Channel) (_ *mo
 		return nil, errors.Wrap(err, "upsertPublicChannelT: failed to upsert channel")
 	}
 
+	if err := s.indexSearchNgrams(transaction, searchNgramObjectTypeChannel, channelNgramTexts([]*model.Channel{updatedChannel})); err != nil {
+		return nil, err
+	}
+
 	if err := transaction.Commit(); err != nil {
 		return nil, errors.Wrap(err, "commit_transaction")
 	}
+
 	return updatedChannel, nil
 }
 
@@ -1005,10 +1015,15 @@ func (s SqlChannelStore) PermanentDeleteByTeam(teamId string) (err error) {
 }
 
 func (s SqlChannelStore) permanentDeleteByTeamtT(transaction *sqlxTxWrapper, teamId string) error {
-	if _, err := transaction.Exec("DELETE FROM Channels WHERE TeamId = ?", teamId); err != nil {
+	var ids []string
+	if err := transaction.Select(&ids, "DELETE FROM Channels WHERE TeamId = ? RETURNING Id", teamId); err != nil {
 		return errors.Wrapf(err, "failed to delete channel by team with teamId=%s", teamId)
 	}
 
+	if err := s.deleteSearchNgrams(transaction, searchNgramObjectTypeChannel, ids); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1046,6 +1061,10 @@ func (s SqlChannelStore) permanentDeleteT(transaction *sqlxTxWrapper, channelId
 		return errors.Wrapf(err, "failed to delete channel with id=%s", channelId)
 	}
 
+	if err := s.deleteSearchNgrams(transaction, searchNgramObjectTypeChannel, []string{channelId}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -3592,7 +3611,27 @@ func (s SqlChannelStore) buildLIKEClauseX(term string, searchColumns ...string)
 
 const spaceFulltextSearchChars = "<>+-()~:*\"!@&"
 
+// buildNgramClause builds the clause of the channels whose n-grams contain those of all the
+// words of the term, the last one being a prefix. It replaces the full text clause when the
+// n-gram search is enabled, and takes a single argument as the full text clause does.
+func (s SqlChannelStore) buildNgramClause(term string, searchColumn string) (ngramClause, ngramTerm string) {
+	idColumn := "Id"
+	if table, _, found := strings.Cut(searchColumn, "."); found {
+		idColumn = table + ".Id"
+	}
+
+	ngramClause = fmt.Sprintf("%s IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = '%s' AND Ngrams @> string_to_array(NULLIF(?, ''), ' '))", idColumn, searchNgramObjectTypeChannel)
+	ngramTerm = strings.Join(searchNgramQueryTokens(term, true), " ")
+	return
+}
+
 func (s SqlChannelStore) buildFulltextClause(term string, searchColumns string) (fulltextClause, fulltextTerm string) {
+	if s.ngramSearchEnabled() {
+		fulltextClause, fulltextTerm = s.buildNgramClause(term, searchColumns)
+		fulltextClause = strings.ReplaceAll(fulltextClause, "?", ":FulltextTerm")
+		return
+	}
+
 	// Copy the terms as we will need to prepare them differently for each search type.
 	fulltextTerm = term
 
@@ -3620,6 +3659,11 @@ func (s SqlChannelStore) buildFulltextClause(term string, searchColumns string)
 }
 
 func (s SqlChannelStore) buildFulltextClauseX(term string, searchColumns ...string) sq.Sqlizer {
+	if s.ngramSearchEnabled() && len(searchColumns) > 0 {
+		ngramClause, ngramTerm := s.buildNgramClause(term, searchColumns[0])
+		return sq.Expr(ngramClause, ngramTerm)
+	}
+
 	// Copy the terms as we will need to prepare them differently for each search type.
 	fulltextTerm := term
 
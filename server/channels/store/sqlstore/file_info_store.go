@@ -21,51 +21,61 @@ import (
 )
 
 type fileInfoWithChannelID struct {
-	Id              string
-	CreatorId       string
-	PostId          string
-	ChannelId       string
-	CreateAt        int64
-	UpdateAt        int64
-	DeleteAt        int64
-	Path            string
-	ThumbnailPath   string
-	PreviewPath     string
-	Name            string
-	Extension       string
-	Size            int64
-	MimeType        string
-	Width           int
-	Height          int
-	HasPreviewImage bool
-	MiniPreview     *[]byte
-	Content         string
-	RemoteId        *string
-	Archived        bool
+	Id               string
+	CreatorId        string
+	PostId           string
+	ChannelId        string
+	CreateAt         int64
+	UpdateAt         int64
+	DeleteAt         int64
+	Path             string
+	ThumbnailPath    string
+	PreviewPath      string
+	ContentHash      string
+	Name             string
+	Extension        string
+	Size             int64
+	MimeType         string
+	Width            int
+	Height           int
+	HasPreviewImage  bool
+	MiniPreview      *[]byte
+	Content          string
+	RemoteId         *string
+	Archived         bool
+	ScanVerdict      string
+	ScanSignature    string
+	MetadataStripped bool
+	OriginalPath     string
 }
 
 func (fi fileInfoWithChannelID) ToModel() *model.FileInfo {
 	return &model.FileInfo{
-		Id:              fi.Id,
-		CreatorId:       fi.CreatorId,
-		PostId:          fi.PostId,
-		ChannelId:       fi.ChannelId,
-		CreateAt:        fi.CreateAt,
-		UpdateAt:        fi.UpdateAt,
-		DeleteAt:        fi.DeleteAt,
-		Path:            fi.Path,
-		ThumbnailPath:   fi.ThumbnailPath,
-		PreviewPath:     fi.PreviewPath,
-		Name:            fi.Name,
-		Extension:       fi.Extension,
-		Size:            fi.Size,
-		MimeType:        fi.MimeType,
-		Width:           fi.Width,
-		Height:          fi.Height,
-		HasPreviewImage: fi.HasPreviewImage,
-		MiniPreview:     fi.MiniPreview,
-		Content:         fi.Content,
-		RemoteId:        fi.RemoteId,
+		Id:               fi.Id,
+		CreatorId:        fi.CreatorId,
+		PostId:           fi.PostId,
+		ChannelId:        fi.ChannelId,
+		CreateAt:         fi.CreateAt,
+		UpdateAt:         fi.UpdateAt,
+		DeleteAt:         fi.DeleteAt,
+		Path:             fi.Path,
+		ThumbnailPath:    fi.ThumbnailPath,
+		PreviewPath:      fi.PreviewPath,
+		ContentHash:      fi.ContentHash,
+		Name:             fi.Name,
+		Extension:        fi.Extension,
+		Size:             fi.Size,
+		MimeType:         fi.MimeType,
+		Width:            fi.Width,
+		Height:           fi.Height,
+		HasPreviewImage:  fi.HasPreviewImage,
+		MiniPreview:      fi.MiniPreview,
+		Content:          fi.Content,
+		RemoteId:         fi.RemoteId,
+		ScanVerdict:      fi.ScanVerdict,
+		ScanSignature:    fi.ScanSignature,
+		MetadataStripped: fi.MetadataStripped,
+		OriginalPath:     fi.OriginalPath,
 	}
 }
 
@@ -95,6 +105,7 @@ func newSqlFileInfoStore(sqlStore *SqlStore, metrics einterfaces.MetricsInterfac
 		"FileInfo.Path",
 		"FileInfo.ThumbnailPath",
 		"FileInfo.PreviewPath",
+		"FileInfo.ContentHash",
 		"FileInfo.Name",
 		"FileInfo.Extension",
 		"FileInfo.Size",
@@ -106,32 +117,201 @@ func newSqlFileInfoStore(sqlStore *SqlStore, metrics einterfaces.MetricsInterfac
 		"Coalesce(FileInfo.Content, '') AS Content",
 		"Coalesce(FileInfo.RemoteId, '') AS RemoteId",
 		"FileInfo.Archived",
+		"FileInfo.ScanVerdict",
+		"FileInfo.ScanSignature",
+		"FileInfo.MetadataStripped",
+		"FileInfo.OriginalPath",
 	}
 
 	return s
 }
 
-func (fs SqlFileInfoStore) Save(rctx request.CTX, info *model.FileInfo) (*model.FileInfo, error) {
+func (fs SqlFileInfoStore) Save(rctx request.CTX, info *model.FileInfo) (_ *model.FileInfo, err error) {
 	info.PreSave()
-	if err := info.IsValid(); err != nil {
+	if err = info.IsValid(); err != nil {
 		return nil, err
 	}
 
 	query := `
 		INSERT INTO FileInfo
-		(Id, CreatorId, PostId, ChannelId, CreateAt, UpdateAt, DeleteAt, Path, ThumbnailPath, PreviewPath,
-			Name, Extension, Size, MimeType, Width, Height, HasPreviewImage, MiniPreview, Content, RemoteId)
+		(Id, CreatorId, PostId, ChannelId, CreateAt, UpdateAt, DeleteAt, Path, ThumbnailPath, PreviewPath, ContentHash,
+			Name, Extension, Size, MimeType, Width, Height, HasPreviewImage, MiniPreview, Content, RemoteId, ScanVerdict, ScanSignature,
+			MetadataStripped, OriginalPath)
 		VALUES
-		(:Id, :CreatorId, :PostId, :ChannelId, :CreateAt, :UpdateAt, :DeleteAt, :Path, :ThumbnailPath, :PreviewPath,
-			:Name, :Extension, :Size, :MimeType, :Width, :Height, :HasPreviewImage, :MiniPreview, :Content, :RemoteId)
+		(:Id, :CreatorId, :PostId, :ChannelId, :CreateAt, :UpdateAt, :DeleteAt, :Path, :ThumbnailPath, :PreviewPath, :ContentHash,
+			:Name, :Extension, :Size, :MimeType, :Width, :Height, :HasPreviewImage, :MiniPreview, :Content, :RemoteId, :ScanVerdict, :ScanSignature,
+			:MetadataStripped, :OriginalPath)
 	`
 
-	if _, err := fs.GetMaster().NamedExec(query, info); err != nil {
+	tx, err := fs.GetMaster().Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(tx, &err)
+
+	if _, err = tx.NamedExec(query, info); err != nil {
 		return nil, errors.Wrap(err, "failed to save FileInfo")
 	}
+
+	if info.ContentHash != "" {
+		if err = addFileBlobReferencesTx(tx, info.ContentHash, info.Size, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = addFileStorageUsageTx(tx, info.CreatorId, info.ChannelId, info.Size); err != nil {
+		return nil, err
+	}
+
+	if err = fs.indexSearchNgrams(tx, searchNgramObjectTypeFile, fileNgramTexts([]*model.FileInfo{info})); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit_transaction")
+	}
+
 	return info, nil
 }
 
+// addFileBlobReferencesTx adds count references to the blob identified by hash,
+// creating its row when this is the first one.
+func addFileBlobReferencesTx(tx *sqlxTxWrapper, hash string, size, count int64) error {
+	now := model.GetMillis()
+	if _, err := tx.Exec(`
+		INSERT INTO FileBlobs (Hash, Size, RefCount, CreateAt, UpdateAt)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (Hash) DO UPDATE SET RefCount = FileBlobs.RefCount + excluded.RefCount, UpdateAt = excluded.UpdateAt`,
+		hash, size, count, now, now); err != nil {
+		return errors.Wrapf(err, "failed to add references to FileBlob with hash=%s", hash)
+	}
+	return nil
+}
+
+// addFileStorageUsageTx adds size to the storage used by the creator of a file and by
+// the team of its channel. Files in direct and group messages don't count for any team.
+func addFileStorageUsageTx(tx *sqlxTxWrapper, creatorID, channelID string, size int64) error {
+	if size == 0 {
+		return nil
+	}
+
+	if creatorID != "" {
+		if _, err := tx.Exec(`
+			INSERT INTO FileStorageUsage (OwnerId, OwnerType, UsedBytes)
+			VALUES (?, ?, ?)
+			ON CONFLICT (OwnerType, OwnerId) DO UPDATE SET UsedBytes = FileStorageUsage.UsedBytes + excluded.UsedBytes`,
+			creatorID, model.FileStorageUsageOwnerTypeUser, size); err != nil {
+			return errors.Wrapf(err, "failed to update the storage usage of user with id=%s", creatorID)
+		}
+	}
+
+	if channelID != "" {
+		if _, err := tx.Exec(`
+			INSERT INTO FileStorageUsage (OwnerId, OwnerType, UsedBytes)
+			SELECT TeamId, ?, ? FROM Channels WHERE Id = ? AND TeamId != ''
+			ON CONFLICT (OwnerType, OwnerId) DO UPDATE SET UsedBytes = FileStorageUsage.UsedBytes + excluded.UsedBytes`,
+			model.FileStorageUsageOwnerTypeTeam, size, channelID); err != nil {
+			return errors.Wrapf(err, "failed to update the storage usage of the team of channel with id=%s", channelID)
+		}
+	}
+
+	return nil
+}
+
+// releaseFileStorageUsageTx subtracts the size of deleted files from the storage used by
+// their creators and by the teams of their channels.
+func releaseFileStorageUsageTx(tx *sqlxTxWrapper, deleted []deletedFileInfo) error {
+	byCreator := map[string]int64{}
+	byChannel := map[string]int64{}
+	for _, info := range deleted {
+		if info.Size == 0 {
+			continue
+		}
+		if info.CreatorId != "" {
+			byCreator[info.CreatorId] += info.Size
+		}
+		if info.ChannelId != "" {
+			byChannel[info.ChannelId] += info.Size
+		}
+	}
+
+	for creatorID, size := range byCreator {
+		if _, err := tx.Exec(`
+			UPDATE FileStorageUsage SET UsedBytes = GREATEST(UsedBytes - ?, 0)
+			WHERE OwnerType = ? AND OwnerId = ?`,
+			size, model.FileStorageUsageOwnerTypeUser, creatorID); err != nil {
+			return errors.Wrapf(err, "failed to update the storage usage of user with id=%s", creatorID)
+		}
+	}
+
+	for channelID, size := range byChannel {
+		if _, err := tx.Exec(`
+			UPDATE FileStorageUsage SET UsedBytes = GREATEST(FileStorageUsage.UsedBytes - ?, 0)
+			FROM Channels
+			WHERE Channels.Id = ? AND FileStorageUsage.OwnerType = ? AND FileStorageUsage.OwnerId = Channels.TeamId`,
+			size, channelID, model.FileStorageUsageOwnerTypeTeam); err != nil {
+			return errors.Wrapf(err, "failed to update the storage usage of the team of channel with id=%s", channelID)
+		}
+	}
+
+	return nil
+}
+
+type deletedFileInfo struct {
+	Id          string
+	CreatorId   string
+	ChannelId   string
+	Size        int64
+	ContentHash string
+}
+
+// permanentDeleteTx deletes the FileInfos matched by the given condition,
+// releases their references to deduplicated blobs and their storage usage, and removes
+// them from the n-gram search index.
+// Unreferenced blobs are only removed later by DeleteUnreferencedBlobs.
+func (fs SqlFileInfoStore) permanentDeleteTx(where string, args ...any) (_ int64, err error) {
+	tx, err := fs.GetMaster().Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(tx, &err)
+
+	var deleted []deletedFileInfo
+	if err = tx.Select(&deleted, "DELETE FROM FileInfo WHERE "+where+" RETURNING Id, CreatorId, ChannelId, Size, ContentHash", args...); err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(deleted))
+	refs := map[string]int64{}
+	for _, info := range deleted {
+		ids = append(ids, info.Id)
+		if info.ContentHash != "" {
+			refs[info.ContentHash]++
+		}
+	}
+
+	now := model.GetMillis()
+	for hash, count := range refs {
+		if _, err = tx.Exec("UPDATE FileBlobs SET RefCount = RefCount - ?, UpdateAt = ? WHERE Hash = ?", count, now, hash); err != nil {
+			return 0, errors.Wrapf(err, "failed to release references to FileBlob with hash=%s", hash)
+		}
+	}
+
+	if err = releaseFileStorageUsageTx(tx, deleted); err != nil {
+		return 0, err
+	}
+
+	if err = fs.deleteSearchNgrams(tx, searchNgramObjectTypeFile, ids); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit_transaction")
+	}
+
+	return int64(len(deleted)), nil
+}
+
 func (fs SqlFileInfoStore) GetByIds(ids []string, includeDeleted, allowFromCache bool) ([]*model.FileInfo, error) {
 	query := fs.getQueryBuilder().
 		Select(fs.queryFields...).
@@ -174,21 +354,23 @@ func (fs SqlFileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*mode
 	queryString, args, err := fs.getQueryBuilder().
 		Update("FileInfo").
 		SetMap(map[string]any{
-			"UpdateAt":        info.UpdateAt,
-			"DeleteAt":        info.DeleteAt,
-			"Path":            info.Path,
-			"ThumbnailPath":   info.ThumbnailPath,
-			"PreviewPath":     info.PreviewPath,
-			"Name":            info.Name,
-			"Extension":       info.Extension,
-			"Size":            info.Size,
-			"MimeType":        info.MimeType,
-			"Width":           info.Width,
-			"Height":          info.Height,
-			"HasPreviewImage": info.HasPreviewImage,
-			"MiniPreview":     info.MiniPreview,
-			"Content":         info.Content,
-			"RemoteId":        info.RemoteId,
+			"UpdateAt":         info.UpdateAt,
+			"DeleteAt":         info.DeleteAt,
+			"Path":             info.Path,
+			"ThumbnailPath":    info.ThumbnailPath,
+			"PreviewPath":      info.PreviewPath,
+			"Name":             info.Name,
+			"Extension":        info.Extension,
+			"Size":             info.Size,
+			"MimeType":         info.MimeType,
+			"Width":            info.Width,
+			"Height":           info.Height,
+			"HasPreviewImage":  info.HasPreviewImage,
+			"MiniPreview":      info.MiniPreview,
+			"Content":          info.Content,
+			"RemoteId":         info.RemoteId,
+			"MetadataStripped": info.MetadataStripped,
+			"OriginalPath":     info.OriginalPath,
 		}).
 		Where(sq.Eq{"Id": info.Id}).
 		ToSql()
@@ -208,6 +390,11 @@ func (fs SqlFileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*mode
 	if count == 0 {
 		return fs.Save(rctx, info)
 	}
+
+	if err := fs.indexSearchNgrams(fs.GetMaster(), searchNgramObjectTypeFile, fileNgramTexts([]*model.FileInfo{info})); err != nil {
+		return nil, err
+	}
+
 	return info, nil
 }
 
@@ -442,6 +629,16 @@ func (fs SqlFileInfoStore) SetContent(rctx request.CTX, fileId, content string)
 		return errors.Wrapf(err, "failed to update FileInfo content with id=%s", fileId)
 	}
 
+	if fs.ngramSearchEnabled() {
+		var name string
+		if err := fs.GetMaster().Get(&name, "SELECT Name FROM FileInfo WHERE Id = ?", fileId); err != nil {
+			return errors.Wrapf(err, "failed to get FileInfo name with id=%s", fileId)
+		}
+		if err := fs.indexSearchNgrams(fs.GetMaster(), searchNgramObjectTypeFile, fileNgramTexts([]*model.FileInfo{{Id: fileId, Name: name, Content: content}})); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -480,53 +677,34 @@ func (fs SqlFileInfoStore) DeleteForPostByIds(rctx request.CTX, postId string, f
 }
 
 func (fs SqlFileInfoStore) PermanentDeleteForPost(rctx request.CTX, postID string) error {
-	if _, err := fs.GetMaster().Exec(`DELETE FROM FileInfo WHERE PostId = ?`, postID); err != nil {
+	if _, err := fs.permanentDeleteTx("PostId = ?", postID); err != nil {
 		return errors.Wrapf(err, "failed to delete FileInfo with PostId=%s", postID)
 	}
 	return nil
 }
 
 func (fs SqlFileInfoStore) PermanentDelete(rctx request.CTX, fileId string) error {
-	if _, err := fs.GetMaster().Exec(`DELETE FROM FileInfo WHERE Id = ?`, fileId); err != nil {
+	if _, err := fs.permanentDeleteTx("Id = ?", fileId); err != nil {
 		return errors.Wrapf(err, "failed to delete FileInfo with id=%s", fileId)
 	}
 	return nil
 }
 
 func (fs SqlFileInfoStore) PermanentDeleteBatch(rctx request.CTX, endTime int64, limit int64) (int64, error) {
-	var query string
-	if fs.DriverName() == "postgres" {
-		query = "DELETE from FileInfo WHERE Id = any (array (SELECT Id FROM FileInfo WHERE CreateAt < ? AND CreatorId != ? LIMIT ?))"
-	} else {
-		query = "DELETE from FileInfo WHERE CreateAt < ? AND CreatorId != ? LIMIT ?"
-	}
-
-	sqlResult, err := fs.GetMaster().Exec(query, endTime, model.BookmarkFileOwner, limit)
+	rowsAffected, err := fs.permanentDeleteTx("Id = any (array (SELECT Id FROM FileInfo WHERE CreateAt < ? AND CreatorId != ? LIMIT ?))", endTime, model.BookmarkFileOwner, limit)
 	if err != nil {
 		return 0, errors.Wrap(err, "failed to delete FileInfos in batch")
 	}
 
-	rowsAffected, err := sqlResult.RowsAffected()
-	if err != nil {
-		return 0, errors.Wrapf(err, "unable to retrieve rows affected")
-	}
-
 	return rowsAffected, nil
 }
 
 func (fs SqlFileInfoStore) PermanentDeleteByUser(rctx request.CTX, userId string) (int64, error) {
-	query := "DELETE from FileInfo WHERE CreatorId = ?"
-
-	sqlResult, err := fs.GetMaster().Exec(query, userId)
+	rowsAffected, err := fs.permanentDeleteTx("CreatorId = ?", userId)
 	if err != nil {
 		return 0, errors.Wrapf(err, "failed to delete FileInfo with creatorId=%s", userId)
 	}
 
-	rowsAffected, err := sqlResult.RowsAffected()
-	if err != nil {
-		return 0, errors.Wrapf(err, "unable to retrieve rows affected")
-	}
-
 	return rowsAffected, nil
 }
 
@@ -631,6 +809,12 @@ func (fs SqlFileInfoStore) Search(rctx request.CTX, paramsList []*model.SearchPa
 
 		if terms == "" && excludedTerms == "" {
 			// we've already confirmed that we have a channel or user to search for
+		} else if fs.ngramSearchEnabled() {
+			searchClause, err := buildNgramSearchClause(searchNgramObjectTypeFile, "FileInfo.Id", "(FileInfo.Name || ' ' || COALESCE(FileInfo.Content, ''))", terms, excludedTerms, params.OrTerms)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to build n-gram search clause")
+			}
+			query = query.Where(searchClause)
 		} else if fs.DriverName() == model.DatabaseDriverPostgres {
 			// Parse text for wildcards
 			if wildcard, err := regexp.Compile(`\*($| )`); err == nil {
@@ -824,3 +1008,161 @@ func (fs SqlFileInfoStore) RefreshFileStats() error {
 
 	return nil
 }
+
+// ReserveBlob makes sure a row exists for the blob identified by hash and
+// returns its current reference count. Reserving a blob refreshes its UpdateAt
+// so that DeleteUnreferencedBlobs leaves it alone while the caller stores it.
+func (fs SqlFileInfoStore) ReserveBlob(hash string, size int64) (int64, error) {
+	now := model.GetMillis()
+
+	var refCount int64
+	if err := fs.GetMaster().Get(&refCount, `
+		INSERT INTO FileBlobs (Hash, Size, RefCount, CreateAt, UpdateAt)
+		VALUES (?, ?, 0, ?, ?)
+		ON CONFLICT (Hash) DO UPDATE SET UpdateAt = excluded.UpdateAt
+		RETURNING RefCount`,
+		hash, size, now, now); err != nil {
+		return 0, errors.Wrapf(err, "failed to reserve FileBlob with hash=%s", hash)
+	}
+
+	return refCount, nil
+}
+
+func (fs SqlFileInfoStore) GetBlob(hash string) (*model.FileBlob, error) {
+	query := fs.getQueryBuilder().
+		Select("Hash", "Size", "RefCount", "CreateAt", "UpdateAt").
+		From("FileBlobs").
+		Where(sq.Eq{"Hash": hash})
+
+	var blob model.FileBlob
+	if err := fs.GetMaster().GetBuilder(&blob, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("FileBlob", hash)
+		}
+		return nil, errors.Wrapf(err, "failed to get FileBlob with hash=%s", hash)
+	}
+
+	return &blob, nil
+}
+
+// DeleteUnreferencedBlobs deletes up to limit blobs without references that
+// haven't been updated since updatedBefore, and returns their hashes so that
+// the caller can remove them from the file store.
+func (fs SqlFileInfoStore) DeleteUnreferencedBlobs(updatedBefore int64, limit int) ([]string, error) {
+	hashes := []string{}
+	if err := fs.GetMaster().Select(&hashes, `
+		DELETE FROM FileBlobs
+		WHERE Hash = any (array (SELECT Hash FROM FileBlobs WHERE RefCount <= 0 AND UpdateAt < ? LIMIT ?))
+		AND RefCount <= 0 AND UpdateAt < ?
+		RETURNING Hash`,
+		updatedBefore, limit, updatedBefore); err != nil {
+		return nil, errors.Wrap(err, "failed to delete unreferenced FileBlobs")
+	}
+
+	return hashes, nil
+}
+
+// GetBatchWithoutContentHash returns up to limit FileInfos, deleted or not,
+// whose content hasn't been deduplicated yet, ordered by Id and starting after afterId.
+func (fs SqlFileInfoStore) GetBatchWithoutContentHash(afterId string, limit int) ([]*model.FileInfo, error) {
+	query := fs.getQueryBuilder().
+		Select(fs.queryFields...).
+		From("FileInfo").
+		Where(sq.Eq{"FileInfo.ContentHash": ""}).
+		Where(sq.NotEq{"FileInfo.Path": ""}).
+		Where(sq.Gt{"FileInfo.Id": afterId}).
+		OrderBy("FileInfo.Id").
+		Limit(uint64(limit))
+
+	infos := []*model.FileInfo{}
+	if err := fs.GetMaster().SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find FileInfos without content hash")
+	}
+
+	return infos, nil
+}
+
+// SetContentHashForPath points every FileInfo stored at path to the
+// deduplicated blob identified by hash, adding a blob reference for each of
+// them, and returns the updated FileInfos.
+func (fs SqlFileInfoStore) SetContentHashForPath(rctx request.CTX, path, hash, blobPath string, size int64) (_ []*model.FileInfo, err error) {
+	tx, err := fs.GetMaster().Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(tx, &err)
+
+	query := fs.getQueryBuilder().
+		Update("FileInfo").
+		Set("ContentHash", hash).
+		Set("Path", blobPath).
+		Where(sq.Eq{"Path": path, "ContentHash": ""}).
+		Suffix("RETURNING " + strings.Join(fs.queryFields, ", "))
+
+	infos := []*model.FileInfo{}
+	if err = tx.SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to set content hash of FileInfos with path=%s", path)
+	}
+
+	if len(infos) > 0 {
+		if err = addFileBlobReferencesTx(tx, hash, size, int64(len(infos))); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit_transaction")
+	}
+
+	return infos, nil
+}
+
+func (fs SqlFileInfoStore) GetFileStorageUsage(ownerType, ownerID string) (int64, error) {
+	query := fs.getQueryBuilder().
+		Select("UsedBytes").
+		From("FileStorageUsage").
+		Where(sq.Eq{"OwnerType": ownerType, "OwnerId": ownerID})
+
+	var usedBytes []int64
+	if err := fs.GetMaster().SelectBuilder(&usedBytes, query); err != nil {
+		return 0, errors.Wrapf(err, "failed to get the storage usage of %s with id=%s", ownerType, ownerID)
+	}
+	if len(usedBytes) == 0 {
+		return 0, nil
+	}
+	return usedBytes[0], nil
+}
+
+func (fs SqlFileInfoStore) GetFileStorageUsagePage(ownerType string, offset, limit int) ([]*model.FileStorageUsage, error) {
+	query := fs.getQueryBuilder().
+		Select("OwnerId", "OwnerType", "UsedBytes").
+		From("FileStorageUsage").
+		Where(sq.Eq{"OwnerType": ownerType}).
+		Where(sq.Gt{"UsedBytes": 0}).
+		OrderBy("UsedBytes DESC", "OwnerId").
+		Offset(uint64(offset)).
+		Limit(uint64(limit))
+
+	usages := []*model.FileStorageUsage{}
+	if err := fs.GetReplica().SelectBuilder(&usages, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to get the storage usage of %ss", ownerType)
+	}
+	return usages, nil
+}
+
+// GetQuarantined returns the infected files kept in quarantine, most recent first.
+func (fs SqlFileInfoStore) GetQuarantined(offset, limit int) ([]*model.FileInfo, error) {
+	query := fs.getQueryBuilder().
+		Select(fs.queryFields...).
+		From("FileInfo").
+		Where(sq.Eq{"FileInfo.ScanVerdict": model.FileScanVerdictInfected}).
+		OrderBy("FileInfo.CreateAt DESC", "FileInfo.Id").
+		Offset(uint64(offset)).
+		Limit(uint64(limit))
+
+	infos := []*model.FileInfo{}
+	if err := fs.GetReplica().SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find quarantined FileInfos")
+	}
+	return infos, nil
+}
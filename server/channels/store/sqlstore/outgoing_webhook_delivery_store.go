@@ -0,0 +1,185 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlOutgoingWebhookDeliveryStore struct {
+	*SqlStore
+
+	deliverySelectQuery sq.SelectBuilder
+}
+
+func newSqlOutgoingWebhookDeliveryStore(sqlStore *SqlStore) store.OutgoingWebhookDeliveryStore {
+	s := &SqlOutgoingWebhookDeliveryStore{
+		SqlStore: sqlStore,
+	}
+
+	s.deliverySelectQuery = s.getQueryBuilder().
+		Select(outgoingWebhookDeliveryColumns...).
+		From("OutgoingWebhookDeliveries")
+
+	return s
+}
+
+var outgoingWebhookDeliveryColumns = []string{
+	"Id",
+	"HookId",
+	"TeamId",
+	"ChannelId",
+	"PostId",
+	"CallbackURL",
+	"ContentType",
+	"Payload",
+	"Status",
+	"Attempts",
+	"LastError",
+	"NextAttemptAt",
+	"CreateAt",
+	"UpdateAt",
+}
+
+func outgoingWebhookDeliveryToSlice(delivery *model.OutgoingWebhookDelivery) []any {
+	return []any{
+		delivery.Id,
+		delivery.HookId,
+		delivery.TeamId,
+		delivery.ChannelId,
+		delivery.PostId,
+		delivery.CallbackURL,
+		delivery.ContentType,
+		delivery.Payload,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastError,
+		delivery.NextAttemptAt,
+		delivery.CreateAt,
+		delivery.UpdateAt,
+	}
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) Save(delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, error) {
+	if delivery.Id != "" {
+		return nil, store.NewErrInvalidInput("OutgoingWebhookDelivery", "id", delivery.Id)
+	}
+
+	delivery.PreSave()
+	if err := delivery.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("OutgoingWebhookDeliveries").
+		Columns(outgoingWebhookDeliveryColumns...).
+		Values(outgoingWebhookDeliveryToSlice(delivery)...)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return nil, errors.Wrapf(err, "failed to save OutgoingWebhookDelivery with id=%s", delivery.Id)
+	}
+
+	return delivery, nil
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) Get(id string) (*model.OutgoingWebhookDelivery, error) {
+	var delivery model.OutgoingWebhookDelivery
+
+	query := s.deliverySelectQuery.Where(sq.Eq{"Id": id})
+
+	if err := s.GetReplica().GetBuilder(&delivery, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("OutgoingWebhookDelivery", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get OutgoingWebhookDelivery with id=%s", id)
+	}
+
+	return &delivery, nil
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) Update(delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, error) {
+	delivery.PreUpdate()
+	if err := delivery.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Update("OutgoingWebhookDeliveries").
+		SetMap(map[string]any{
+			"Status":        delivery.Status,
+			"Attempts":      delivery.Attempts,
+			"LastError":     delivery.LastError,
+			"NextAttemptAt": delivery.NextAttemptAt,
+			"UpdateAt":      delivery.UpdateAt,
+		}).
+		Where(sq.Eq{"Id": delivery.Id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return nil, errors.Wrapf(err, "failed to update OutgoingWebhookDelivery with id=%s", delivery.Id)
+	}
+
+	return delivery, nil
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) GetForHook(hookID string, status string, offset, limit int) ([]*model.OutgoingWebhookDelivery, error) {
+	deliveries := []*model.OutgoingWebhookDelivery{}
+
+	query := s.deliverySelectQuery.
+		Where(sq.Eq{"HookId": hookID}).
+		OrderBy("CreateAt DESC", "Id").
+		Limit(uint64(limit)).
+		Offset(uint64(offset))
+
+	if status != "" {
+		query = query.Where(sq.Eq{"Status": status})
+	}
+
+	if err := s.GetReplica().SelectBuilder(&deliveries, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find OutgoingWebhookDeliveries with hookId=%s", hookID)
+	}
+
+	return deliveries, nil
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) GetDue(now int64, limit int) ([]*model.OutgoingWebhookDelivery, error) {
+	deliveries := []*model.OutgoingWebhookDelivery{}
+
+	query := s.deliverySelectQuery.
+		Where(sq.And{
+			sq.Eq{"Status": model.OutgoingWebhookDeliveryStatusPending},
+			sq.LtOrEq{"NextAttemptAt": now},
+		}).
+		OrderBy("NextAttemptAt", "Id").
+		Limit(uint64(limit))
+
+	// Read from master so that deliveries updated by the previous batch are not picked up again.
+	if err := s.GetMaster().SelectBuilder(&deliveries, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find due OutgoingWebhookDeliveries")
+	}
+
+	return deliveries, nil
+}
+
+func (s *SqlOutgoingWebhookDeliveryStore) PermanentDeleteFinishedOlderThan(olderThan int64, limit int64) (int64, error) {
+	query := `DELETE FROM OutgoingWebhookDeliveries WHERE Id = any (array (
+		SELECT Id FROM OutgoingWebhookDeliveries WHERE Status != ? AND UpdateAt < ? ORDER BY UpdateAt LIMIT ?))`
+
+	result, err := s.GetMaster().Exec(query, model.OutgoingWebhookDeliveryStatusPending, olderThan, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete OutgoingWebhookDeliveries")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to get rows affected")
+	}
+
+	return rowsAffected, nil
+}
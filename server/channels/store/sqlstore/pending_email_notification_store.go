@@ -0,0 +1,161 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlPendingEmailNotificationStore struct {
+	*SqlStore
+}
+
+func newSqlPendingEmailNotificationStore(sqlStore *SqlStore) store.PendingEmailNotificationStore {
+	return &SqlPendingEmailNotificationStore{
+		SqlStore: sqlStore,
+	}
+}
+
+func (s *SqlPendingEmailNotificationStore) Save(notification *model.PendingEmailNotification) (*model.PendingEmailNotification, error) {
+	if notification.Id != "" {
+		return nil, store.NewErrInvalidInput("PendingEmailNotification", "id", notification.Id)
+	}
+
+	notification.PreSave()
+	if err := notification.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("PendingEmailNotifications").
+		Columns("Id", "UserId", "PostId", "TeamName", "CreateAt").
+		Values(notification.Id, notification.UserId, notification.PostId, notification.TeamName, notification.CreateAt)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return nil, errors.Wrapf(err, "failed to save PendingEmailNotification with id=%s", notification.Id)
+	}
+
+	return notification, nil
+}
+
+// GetUserIds returns, in order, the ids of the users with pending notifications that
+// sort after afterUserId.
+func (s *SqlPendingEmailNotificationStore) GetUserIds(afterUserId string, limit int) ([]string, error) {
+	userIds := []string{}
+
+	query := s.getQueryBuilder().
+		Select("DISTINCT UserId").
+		From("PendingEmailNotifications").
+		Where(sq.Gt{"UserId": afterUserId}).
+		OrderBy("UserId").
+		Limit(uint64(limit))
+
+	if err := s.GetMaster().SelectBuilder(&userIds, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find users with PendingEmailNotifications")
+	}
+
+	return userIds, nil
+}
+
+func (s *SqlPendingEmailNotificationStore) GetForUser(userID string) ([]*model.PendingEmailNotification, error) {
+	notifications := []*model.PendingEmailNotification{}
+
+	query := s.getQueryBuilder().
+		Select("Id", "UserId", "PostId", "TeamName", "CreateAt", "ClaimedAt").
+		From("PendingEmailNotifications").
+		Where(sq.Eq{"UserId": userID}).
+		OrderBy("CreateAt", "Id")
+
+	// Read from master so that notifications deleted by a previous run are not picked up again.
+	if err := s.GetMaster().SelectBuilder(&notifications, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find PendingEmailNotifications with userId=%s", userID)
+	}
+
+	return notifications, nil
+}
+
+func (s *SqlPendingEmailNotificationStore) Claim(ids []string, claimedAt, leaseExpiredAt int64) (claimed bool, err error) {
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return false, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	query := s.getQueryBuilder().
+		Update("PendingEmailNotifications").
+		Set("ClaimedAt", claimedAt).
+		Where(sq.Eq{"Id": ids}).
+		Where(sq.LtOrEq{"ClaimedAt": leaseExpiredAt})
+
+	result, err := transaction.ExecBuilder(query)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to claim PendingEmailNotifications")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "unable to get rows affected")
+	}
+
+	// Some of the notifications were deleted or are claimed by another run: leave them all
+	// to it rather than sending part of the batch.
+	if rowsAffected != int64(len(ids)) {
+		return false, nil
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return false, errors.Wrap(err, "commit_transaction")
+	}
+
+	return true, nil
+}
+
+// Release clears the claim on the notifications, so that the next run sends them again
+// without waiting for the claim to expire.
+func (s *SqlPendingEmailNotificationStore) Release(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder().
+		Update("PendingEmailNotifications").
+		Set("ClaimedAt", 0).
+		Where(sq.Eq{"Id": ids})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrap(err, "failed to release PendingEmailNotifications")
+	}
+
+	return nil
+}
+
+func (s *SqlPendingEmailNotificationStore) Delete(ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := s.getQueryBuilder().
+		Delete("PendingEmailNotifications").
+		Where(sq.Eq{"Id": ids})
+
+	result, err := s.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete PendingEmailNotifications")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to get rows affected")
+	}
+
+	return rowsAffected, nil
+}
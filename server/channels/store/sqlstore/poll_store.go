@@ -0,0 +1,266 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlPollStore struct {
+	*SqlStore
+}
+
+func newSqlPollStore(sqlStore *SqlStore) store.PollStore {
+	return &SqlPollStore{sqlStore}
+}
+
+var pollColumns = []string{
+	"PostId",
+	"Question",
+	"Options",
+	"Anonymous",
+	"MultipleChoice",
+	"CreateAt",
+	"UpdateAt",
+	"ClosedAt",
+}
+
+func (s *SqlPollStore) Save(poll *model.Poll) (*model.Poll, error) {
+	poll.PreSave()
+	if err := poll.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("Polls").
+		Columns(pollColumns...).
+		Values(
+			poll.PostId,
+			poll.Question,
+			poll.Options,
+			poll.Anonymous,
+			poll.MultipleChoice,
+			poll.CreateAt,
+			poll.UpdateAt,
+			poll.ClosedAt,
+		)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		if IsUniqueConstraintError(err, []string{"polls_pkey", "PRIMARY"}) {
+			return nil, store.NewErrInvalidInput("Poll", "post_id", poll.PostId)
+		}
+		return nil, errors.Wrapf(err, "failed to save Poll with postId=%s", poll.PostId)
+	}
+
+	return poll, nil
+}
+
+func (s *SqlPollStore) Get(postID string) (*model.Poll, error) {
+	query := s.getQueryBuilder().
+		Select(pollColumns...).
+		From("Polls").
+		Where(sq.Eq{"PostId": postID})
+
+	// Polls are read right after being voted for, so they are read from the master.
+	var poll model.Poll
+	if err := s.GetMaster().GetBuilder(&poll, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("Poll", postID)
+		}
+		return nil, errors.Wrapf(err, "failed to get Poll with postId=%s", postID)
+	}
+
+	return &poll, nil
+}
+
+func (s *SqlPollStore) Close(postID string, closedAt int64) (err error) {
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	query := s.getQueryBuilder().
+		Update("Polls").
+		Set("ClosedAt", closedAt).
+		Set("UpdateAt", closedAt).
+		Where(sq.Eq{"PostId": postID, "ClosedAt": 0})
+
+	result, err := transaction.ExecBuilder(query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to close Poll with postId=%s", postID)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "unable to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return store.NewErrNotFound("Poll", postID)
+	}
+
+	if err = s.touchPollPost(transaction, postID, closedAt); err != nil {
+		return err
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return errors.Wrap(err, "commit_transaction")
+	}
+
+	return nil
+}
+
+func (s *SqlPollStore) SaveVotes(postID, userID string, optionIDs []string) error {
+	return s.saveVotes(postID, userID, optionIDs, false)
+}
+
+func (s *SqlPollStore) ImportVotes(postID, userID string, optionIDs []string) error {
+	return s.saveVotes(postID, userID, optionIDs, true)
+}
+
+func (s *SqlPollStore) saveVotes(postID, userID string, optionIDs []string, allowClosed bool) (err error) {
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	if !allowClosed {
+		if err = s.lockOpenPoll(transaction, postID); err != nil {
+			return err
+		}
+	}
+
+	if err = s.deleteVotes(transaction, postID, userID); err != nil {
+		return err
+	}
+
+	now := model.GetMillis()
+	query := s.getQueryBuilder().
+		Insert("PollVotes").
+		Columns("PostId", "OptionId", "UserId", "CreateAt")
+	for _, optionID := range optionIDs {
+		query = query.Values(postID, optionID, userID, now)
+	}
+
+	if _, err = transaction.ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to save PollVotes with postId=%s userId=%s", postID, userID)
+	}
+
+	if err = s.touchPollPost(transaction, postID, now); err != nil {
+		return err
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return errors.Wrap(err, "commit_transaction")
+	}
+
+	return nil
+}
+
+func (s *SqlPollStore) DeleteVotes(postID, userID string) (err error) {
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	if err = s.lockOpenPoll(transaction, postID); err != nil {
+		return err
+	}
+
+	if err = s.deleteVotes(transaction, postID, userID); err != nil {
+		return err
+	}
+
+	if err = s.touchPollPost(transaction, postID, model.GetMillis()); err != nil {
+		return err
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return errors.Wrap(err, "commit_transaction")
+	}
+
+	return nil
+}
+
+func (s *SqlPollStore) GetVotes(postID string) ([]*model.PollVote, error) {
+	query := s.getQueryBuilder().
+		Select("PostId", "OptionId", "UserId", "CreateAt").
+		From("PollVotes").
+		Where(sq.Eq{"PostId": postID}).
+		OrderBy("CreateAt", "UserId", "OptionId")
+
+	votes := []*model.PollVote{}
+	if err := s.GetMaster().SelectBuilder(&votes, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find PollVotes with postId=%s", postID)
+	}
+
+	return votes, nil
+}
+
+// lockOpenPoll locks the poll until the end of the transaction, so that it isn't closed while
+// it is voted for. It returns a not found error if the poll is closed.
+func (s *SqlPollStore) lockOpenPoll(transaction *sqlxTxWrapper, postID string) error {
+	query := s.getQueryBuilder().
+		Select("ClosedAt").
+		From("Polls").
+		Where(sq.Eq{"PostId": postID}).
+		Suffix("FOR UPDATE")
+
+	var closedAt int64
+	if err := transaction.GetBuilder(&closedAt, query); err != nil {
+		if err == sql.ErrNoRows {
+			return store.NewErrNotFound("Poll", postID)
+		}
+		return errors.Wrapf(err, "failed to lock Poll with postId=%s", postID)
+	}
+	if closedAt != 0 {
+		return store.NewErrNotFound("Poll", postID)
+	}
+
+	return nil
+}
+
+func (s *SqlPollStore) deleteVotes(transaction *sqlxTxWrapper, postID, userID string) error {
+	query := s.getQueryBuilder().
+		Delete("PollVotes").
+		Where(sq.Eq{"PostId": postID, "UserId": userID})
+
+	if _, err := transaction.ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete PollVotes with postId=%s userId=%s", postID, userID)
+	}
+
+	return nil
+}
+
+// touchPollPost updates the poll and its post, so that the clients and the compliance export
+// pick up the new tally, as they do for reactions.
+func (s *SqlPollStore) touchPollPost(transaction *sqlxTxWrapper, postID string, updateAt int64) error {
+	pollQuery := s.getQueryBuilder().
+		Update("Polls").
+		Set("UpdateAt", updateAt).
+		Where(sq.Eq{"PostId": postID})
+
+	if _, err := transaction.ExecBuilder(pollQuery); err != nil {
+		return errors.Wrapf(err, "failed to update Poll with postId=%s", postID)
+	}
+
+	postQuery := s.getQueryBuilder().
+		Update("Posts").
+		Set("UpdateAt", updateAt).
+		Where(sq.Eq{"Id": postID})
+
+	if _, err := transaction.ExecBuilder(postQuery); err != nil {
+		return errors.Wrapf(err, "failed to update Post with id=%s", postID)
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const (
+	// hnswDefaultEfSearch and hnswMaxEfSearch bound the hnsw.ef_search setting of pgvector.
+	hnswDefaultEfSearch = 40
+	hnswMaxEfSearch     = 1000
+
+	// postEmbeddingExactSearchMax is the most embeddings in the channels of a user that are all
+	// compared to the searched one instead of being found through the HNSW index.
+	postEmbeddingExactSearchMax = 50000
+)
+
+type SqlPostEmbeddingStore struct {
+	*SqlStore
+}
+
+func newSqlPostEmbeddingStore(sqlStore *SqlStore) store.PostEmbeddingStore {
+	return &SqlPostEmbeddingStore{sqlStore}
+}
+
+// vectorLiteral formats an embedding as the text representation of a pgvector vector, so
+// that it can be passed as a parameter cast to vector.
+func vectorLiteral(embedding []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, v := range embedding {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+func (s *SqlPostEmbeddingStore) Save(embeddings []*model.PostEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder().
+		Insert("PostEmbeddings").
+		Columns("PostId", "ChannelId", "Model", "PostEditAt", "Embedding")
+	for _, embedding := range embeddings {
+		query = query.Values(embedding.PostId, embedding.ChannelId, embedding.Model, embedding.PostEditAt, sq.Expr("?::vector", vectorLiteral(embedding.Embedding)))
+	}
+	query = query.Suffix("ON CONFLICT (PostId) DO UPDATE SET ChannelId = EXCLUDED.ChannelId, Model = EXCLUDED.Model, PostEditAt = EXCLUDED.PostEditAt, Embedding = EXCLUDED.Embedding")
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrap(err, "failed to save PostEmbeddings")
+	}
+
+	return nil
+}
+
+func (s *SqlPostEmbeddingStore) Delete(postIDs []string) error {
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder().
+		Delete("PostEmbeddings").
+		Where(sq.Eq{"PostId": postIDs})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrap(err, "failed to delete PostEmbeddings")
+	}
+
+	return nil
+}
+
+func (s *SqlPostEmbeddingStore) GetForPosts(postIDs []string, embeddingModel string) ([]*model.PostEmbedding, error) {
+	embeddings := []*model.PostEmbedding{}
+	if len(postIDs) == 0 {
+		return embeddings, nil
+	}
+
+	query := s.getQueryBuilder().
+		Select("PostId", "ChannelId", "Model", "PostEditAt").
+		From("PostEmbeddings").
+		Where(sq.Eq{"PostId": postIDs, "Model": embeddingModel})
+
+	if err := s.GetReplica().SelectBuilder(&embeddings, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get PostEmbeddings")
+	}
+
+	return embeddings, nil
+}
+
+func (s *SqlPostEmbeddingStore) SetDimensions(dimensions int) (err error) {
+	var columnType string
+	if err = s.GetMaster().Get(&columnType, "SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = 'postembeddings'::regclass AND attname = 'embedding'"); err != nil {
+		return errors.Wrap(err, "failed to get the type of the PostEmbeddings embeddings")
+	}
+
+	// The column is typed and indexed in the same transaction, so the index exists once the
+	// column has the type.
+	vectorType := fmt.Sprintf("vector(%d)", dimensions)
+	if columnType == vectorType {
+		return nil
+	}
+
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	if _, err = transaction.Exec("DROP INDEX IF EXISTS idx_postembeddings_embedding"); err != nil {
+		return errors.Wrap(err, "failed to drop the PostEmbeddings embedding index")
+	}
+
+	if _, err = transaction.Exec("DELETE FROM PostEmbeddings WHERE vector_dims(Embedding) != ?", dimensions); err != nil {
+		return errors.Wrapf(err, "failed to delete the PostEmbeddings of other dimensions than %d", dimensions)
+	}
+
+	if _, err = transaction.ExecNoTimeout("ALTER TABLE PostEmbeddings ALTER COLUMN Embedding TYPE " + vectorType); err != nil {
+		return errors.Wrapf(err, "failed to change the type of the PostEmbeddings embeddings to %s", vectorType)
+	}
+
+	// The embeddings are compared by their cosine distance, as the models normalize them.
+	if _, err = transaction.ExecNoTimeout("CREATE INDEX idx_postembeddings_embedding ON PostEmbeddings USING hnsw (Embedding vector_cosine_ops)"); err != nil {
+		return errors.Wrap(err, "failed to create the PostEmbeddings embedding index")
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return errors.Wrap(err, "commit_transaction")
+	}
+
+	return nil
+}
+
+func (s *SqlPostEmbeddingStore) Search(userID, teamID string, embedding []float32, embeddingModel string, includeDeletedChannels bool, limit int) (_ []string, err error) {
+	candidates := s.getQueryBuilder().
+		Select("pe.PostId").
+		From("PostEmbeddings pe").
+		// Posts moved to other channels are only searched once embedded again.
+		Join("Posts p ON p.Id = pe.PostId AND p.ChannelId = pe.ChannelId").
+		Join("Channels c ON c.Id = pe.ChannelId").
+		Join("ChannelMembers cm ON cm.ChannelId = pe.ChannelId").
+		Where(sq.Eq{
+			"cm.UserId":  userID,
+			"pe.Model":   embeddingModel,
+			"p.DeleteAt": 0,
+		})
+
+	if teamID != "" {
+		// Direct and group messages don't belong to any team.
+		candidates = candidates.Where(sq.Or{sq.Eq{"c.TeamId": teamID}, sq.Eq{"c.TeamId": ""}})
+	}
+
+	if !includeDeletedChannels {
+		candidates = candidates.Where(sq.Eq{"c.DeleteAt": 0})
+	}
+
+	transaction, err := s.GetSearchReplicaX().Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	// The HNSW index finds the embeddings closest to the searched one before they are filtered by
+	// the channels of the user, which leaves few of them, if any, when the user is only a member of
+	// a small part of the channels. So the embeddings of the channels of the user are all compared
+	// when there aren't too many of them, rather than looked up in the index.
+	countQuery := s.getQueryBuilder().
+		Select("count(*)").
+		FromSelect(candidates.Limit(postEmbeddingExactSearchMax+1), "candidates")
+
+	var count int
+	if err = transaction.GetBuilder(&count, countQuery); err != nil {
+		return nil, errors.Wrapf(err, "failed to count PostEmbeddings for userId=%s", userID)
+	}
+
+	if count <= postEmbeddingExactSearchMax {
+		// HNSW indexes can't be scanned as bitmaps, so this leaves the channel index to find the
+		// embeddings to compare.
+		if _, err = transaction.Exec("SET LOCAL enable_indexscan = off"); err != nil {
+			return nil, errors.Wrap(err, "failed to set enable_indexscan")
+		}
+	} else {
+		efSearch := min(max(limit, hnswDefaultEfSearch), hnswMaxEfSearch)
+		if _, err = transaction.Exec(fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", efSearch)); err != nil {
+			return nil, errors.Wrap(err, "failed to set hnsw.ef_search")
+		}
+
+		// Since pgvector 0.8.0, the index scan goes on until enough embeddings pass the filters
+		// instead of stopping after hnsw.ef_search of them.
+		var iterativeScan bool
+		if err = transaction.Get(&iterativeScan, "SELECT COALESCE((SELECT string_to_array(extversion, '.')::int[] >= '{0,8}' FROM pg_extension WHERE extname = 'vector'), false)"); err != nil {
+			return nil, errors.Wrap(err, "failed to get the version of the vector extension")
+		}
+		if iterativeScan {
+			if _, err = transaction.Exec("SET LOCAL hnsw.iterative_scan = strict_order"); err != nil {
+				return nil, errors.Wrap(err, "failed to set hnsw.iterative_scan")
+			}
+		}
+	}
+
+	query := candidates.
+		OrderByClause("pe.Embedding <=> ?::vector", vectorLiteral(embedding)).
+		Limit(uint64(limit))
+
+	postIDs := []string{}
+	if err = transaction.SelectBuilder(&postIDs, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to search PostEmbeddings for userId=%s", userID)
+	}
+
+	return postIDs, nil
+}
@@ -28,6 +28,9 @@ import (
 var quotedStringsRegex = regexp.MustCompile(`("[^"]*")`)
 var wildCardRegex = regexp.MustCompile(`\*($| )`)
 
+// searchLinkPattern matches the messages containing a link, for the has:link search filter.
+const searchLinkPattern = `(https?|ftp)://|www\.`
+
 type SqlPostStore struct {
 	*SqlStore
 	metrics           einterfaces.MetricsInterface
@@ -241,6 +244,10 @@ func (s *SqlPostStore) SaveMultiple(rctx request.CTX, posts []*model.Post) ([]*m
 		return nil, -1, errors.Wrap(err, "failed to save posts persistent notifications")
 	}
 
+	if err = s.indexSearchNgrams(transaction, searchNgramObjectTypePost, postNgramTexts(posts)); err != nil {
+		return nil, -1, err
+	}
+
 	if err = transaction.Commit(); err != nil {
 		// don't need to rollback here since the transaction is already closed
 		return posts, -1, errors.Wrap(err, "commit_transaction")
@@ -341,7 +348,7 @@ func (s *SqlPostStore) populateReplyCount(posts []*model.Post) error {
 	return nil
 }
 
-func (s *SqlPostStore) Update(rctx request.CTX, newPost *model.Post, oldPost *model.Post) (*model.Post, error) {
+func (s *SqlPostStore) Update(rctx request.CTX, newPost *model.Post, oldPost *model.Post) (_ *model.Post, err error) {
 	newPost.UpdateAt = model.GetMillis()
 	newPost.PreCommit()
 
@@ -358,7 +365,13 @@ func (s *SqlPostStore) Update(rctx request.CTX, newPost *model.Post, oldPost *mo
 	}
 	newPost.ValidateProps(rctx.Logger())
 
-	if _, err := s.GetMaster().NamedExec(`UPDATE Posts
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	if _, err = transaction.NamedExec(`UPDATE Posts
 		SET CreateAt=:CreateAt,
 			UpdateAt=:UpdateAt,
 			EditAt=:EditAt,
@@ -382,6 +395,14 @@ func (s *SqlPostStore) Update(rctx request.CTX, newPost *model.Post, oldPost *mo
 		return nil, errors.Wrapf(err, "failed to update Post with id=%s", newPost.Id)
 	}
 
+	if err = s.indexSearchNgrams(transaction, searchNgramObjectTypePost, postNgramTexts([]*model.Post{newPost})); err != nil {
+		return nil, err
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit_transaction")
+	}
+
 	time := model.GetMillis()
 	if _, err := s.GetMaster().Exec("UPDATE Channels SET LastPostAt = ?  WHERE Id = ? AND LastPostAt < ?", time, newPost.ChannelId, time); err != nil {
 		return nil, errors.Wrap(err, "failed to update lastpostat of channels")
@@ -458,6 +479,11 @@ func (s *SqlPostStore) OverwriteMultiple(rctx request.CTX, posts []*model.Post)
 			}
 		}
 	}
+
+	if err = s.indexSearchNgrams(tx, searchNgramObjectTypePost, postNgramTexts(posts)); err != nil {
+		return nil, -1, err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		return nil, -1, errors.Wrap(err, "commit_transaction")
@@ -1023,11 +1049,17 @@ func (s *SqlPostStore) permanentDelete(postIds []string) (err error) {
 				sq.Eq{"Id": postIds},
 				sq.Eq{"RootId": postIds},
 			},
-		)
-	if _, err = transaction.ExecBuilder(query); err != nil {
+		).
+		Suffix("RETURNING Id")
+	var deletedIds []string
+	if err = transaction.SelectBuilder(&deletedIds, query); err != nil {
 		return errors.Wrap(err, "failed to delete Posts")
 	}
 
+	if err = s.deleteSearchNgrams(transaction, searchNgramObjectTypePost, deletedIds); err != nil {
+		return err
+	}
+
 	if err = transaction.Commit(); err != nil {
 		return errors.Wrap(err, "commit_transaction")
 	}
@@ -1072,6 +1104,10 @@ func (s *SqlPostStore) permanentDeleteAllCommentByUser(userId string) (err error
 		return err
 	}
 
+	if err = s.deleteSearchNgrams(transaction, searchNgramObjectTypePost, postIds); err != nil {
+		return err
+	}
+
 	if err = transaction.Commit(); err != nil {
 		return errors.Wrap(err, "commit_transaction")
 	}
@@ -1162,6 +1198,10 @@ func (s *SqlPostStore) PermanentDeleteByChannel(rctx request.CTX, channelId stri
 			return errors.Wrap(err, "failed to delete Posts")
 		}
 		time.Sleep(10 * time.Millisecond)
+
+		if err = s.deleteSearchNgrams(transaction, searchNgramObjectTypePost, ids); err != nil {
+			return err
+		}
 	}
 
 	if err = transaction.Commit(); err != nil {
@@ -2022,6 +2062,53 @@ func (s *SqlPostStore) buildSearchPostFilterClause(teamID string, fromUsers []st
 	return builder.Where("UserId IN ("+subQuery+")", subQueryArgs...), nil
 }
 
+// buildSearchPostAttributeFilterClause handles the is:pinned, has:file, has:link,
+// has:reactions, in:thread and from:bot filters.
+func (s *SqlPostStore) buildSearchPostAttributeFilterClause(params *model.SearchParams, builder sq.SelectBuilder) sq.SelectBuilder {
+	if params.IsPinned != nil {
+		builder = builder.Where(sq.Eq{"IsPinned": *params.IsPinned})
+	}
+
+	if params.HasFile != nil {
+		hasFile := sq.Or{sq.NotEq{"FileIds": "[]"}, sq.NotEq{"Filenames": "[]"}}
+		if *params.HasFile {
+			builder = builder.Where(hasFile)
+		} else {
+			builder = builder.Where(sq.Eq{"FileIds": "[]", "Filenames": "[]"})
+		}
+	}
+
+	if params.HasLink != nil {
+		if *params.HasLink {
+			builder = builder.Where("Message ~* ?", searchLinkPattern)
+		} else {
+			builder = builder.Where("Message !~* ?", searchLinkPattern)
+		}
+	}
+
+	if params.HasReactions != nil {
+		builder = builder.Where(sq.Eq{"HasReactions": *params.HasReactions})
+	}
+
+	if params.InThread != nil {
+		if *params.InThread {
+			builder = builder.Where(sq.NotEq{"RootId": ""})
+		} else {
+			builder = builder.Where(sq.Eq{"RootId": ""})
+		}
+	}
+
+	if params.FromBots != nil {
+		if *params.FromBots {
+			builder = builder.Where("UserId IN (SELECT UserId FROM Bots)")
+		} else {
+			builder = builder.Where("UserId NOT IN (SELECT UserId FROM Bots)")
+		}
+	}
+
+	return builder
+}
+
 func (s *SqlPostStore) Search(teamId string, userId string, params *model.SearchParams) (*model.PostList, error) {
 	return s.search(teamId, userId, params, true, true)
 }
@@ -2031,7 +2118,8 @@ func (s *SqlPostStore) search(teamId string, userId string, params *model.Search
 	if params.Terms == "" && params.ExcludedTerms == "" &&
 		len(params.InChannels) == 0 && len(params.ExcludedChannels) == 0 &&
 		len(params.FromUsers) == 0 && len(params.ExcludedUsers) == 0 &&
-		params.OnDate == "" && params.AfterDate == "" && params.BeforeDate == "" {
+		params.OnDate == "" && params.AfterDate == "" && params.BeforeDate == "" &&
+		!params.HasPostAttributeFilters() {
 		return list, nil
 	}
 
@@ -2050,6 +2138,7 @@ func (s *SqlPostStore) search(teamId string, userId string, params *model.Search
 		return nil, errors.Wrap(err, "failed to build search post filter clause")
 	}
 	baseQuery = s.buildCreateDateFilterClause(params, baseQuery)
+	baseQuery = s.buildSearchPostAttributeFilterClause(params, baseQuery)
 
 	termMap := map[string]bool{}
 	terms := params.Terms
@@ -2072,6 +2161,12 @@ func (s *SqlPostStore) search(teamId string, userId string, params *model.Search
 
 	if terms == "" && excludedTerms == "" {
 		// we've already confirmed that we have a channel or user to search for
+	} else if s.ngramSearchEnabled() && !params.IsHashtag {
+		searchClause, err := buildNgramSearchClause(searchNgramObjectTypePost, "q2.Id", "q2.Message", terms, excludedTerms, params.OrTerms)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build n-gram search clause")
+		}
+		baseQuery = baseQuery.Where(searchClause)
 	} else {
 		// Parse text for wildcards
 		terms = wildCardRegex.ReplaceAllLiteralString(terms, ":* ")
@@ -2538,36 +2633,40 @@ func (s *SqlPostStore) PermanentDeleteBatchForRetentionPolicies(retentionPolicyB
 	}
 
 	return genericPermanentDeleteBatchForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
-		BaseBuilder:         builder,
-		Table:               "Posts",
-		TimeColumn:          "CreateAt",
-		PrimaryKeys:         []string{"Id"},
-		ChannelIDTable:      "Posts",
-		NowMillis:           retentionPolicyBatchConfigs.Now,
-		GlobalPolicyEndTime: retentionPolicyBatchConfigs.GlobalPolicyEndTime,
-		Limit:               retentionPolicyBatchConfigs.Limit,
-		StoreDeletedIds:     true,
+		BaseBuilder:           builder,
+		Table:                 "Posts",
+		TimeColumn:            "CreateAt",
+		PrimaryKeys:           []string{"Id"},
+		ChannelIDTable:        "Posts",
+		NowMillis:             retentionPolicyBatchConfigs.Now,
+		GlobalPolicyEndTime:   retentionPolicyBatchConfigs.GlobalPolicyEndTime,
+		Limit:                 retentionPolicyBatchConfigs.Limit,
+		StoreDeletedIds:       true,
+		SearchNgramObjectType: searchNgramObjectTypePost,
 	}, s.SqlStore, cursor)
 }
 
-func (s *SqlPostStore) PermanentDeleteBatch(endTime int64, limit int64) (int64, error) {
-	var query string
-	if s.DriverName() == model.DatabaseDriverPostgres {
-		query = "DELETE from Posts WHERE Id = any (array (SELECT Id FROM Posts WHERE CreateAt < ? LIMIT ?))"
-	} else {
-		query = "DELETE from Posts WHERE CreateAt < ? LIMIT ?"
+func (s *SqlPostStore) PermanentDeleteBatch(endTime int64, limit int64) (_ int64, err error) {
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "begin_transaction")
 	}
+	defer finalizeTransactionX(transaction, &err)
 
-	sqlResult, err := s.GetMaster().Exec(query, endTime, limit)
-	if err != nil {
+	var ids []string
+	if err = transaction.Select(&ids, "DELETE from Posts WHERE Id = any (array (SELECT Id FROM Posts WHERE CreateAt < ? LIMIT ?)) RETURNING Id", endTime, limit); err != nil {
 		return 0, errors.Wrap(err, "failed to delete Posts")
 	}
 
-	rowsAffected, err := sqlResult.RowsAffected()
-	if err != nil {
-		return 0, errors.Wrap(err, "failed to delete Posts")
+	if err = s.deleteSearchNgrams(transaction, searchNgramObjectTypePost, ids); err != nil {
+		return 0, err
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return 0, errors.Wrap(err, "commit_transaction")
 	}
-	return rowsAffected, nil
+
+	return int64(len(ids)), nil
 }
 
 func (s *SqlPostStore) GetOldest() (*model.Post, error) {
@@ -2838,6 +2937,10 @@ func (s *SqlPostStore) SearchPostsForUser(rctx request.CTX, paramsList []*model.
 
 	posts.SortByCreateAt()
 
+	if s.ngramSearchEnabled() {
+		return model.MakePostSearchResults(posts, ngramSearchMatches(posts, paramsList)), nil
+	}
+
 	return model.MakePostSearchResults(posts, nil), nil
 }
 
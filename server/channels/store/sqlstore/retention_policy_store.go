@@ -922,16 +922,20 @@ func insertRetentionIdsForDeletion(txn *sqlxTxWrapper, row *model.RetentionIdsFo
 // will be deleted by the global policy if it does not fall under a granular policy.
 // To disable the granular policies, set `NowMillis` to 0.
 // To disable the global policy, set `GlobalPolicyEndTime` to 0.
+// `SearchNgramObjectType` is the type of the records in the SearchNgrams table, if they
+// are searchable, so that they are removed along with the records. It requires
+// `StoreDeletedIds`.
 type RetentionPolicyBatchDeletionInfo struct {
-	BaseBuilder         sq.SelectBuilder
-	Table               string
-	TimeColumn          string
-	PrimaryKeys         []string
-	ChannelIDTable      string
-	NowMillis           int64
-	GlobalPolicyEndTime int64
-	Limit               int64
-	StoreDeletedIds     bool
+	BaseBuilder           sq.SelectBuilder
+	Table                 string
+	TimeColumn            string
+	PrimaryKeys           []string
+	ChannelIDTable        string
+	NowMillis             int64
+	GlobalPolicyEndTime   int64
+	Limit                 int64
+	StoreDeletedIds       bool
+	SearchNgramObjectType string
 }
 
 // genericPermanentDeleteBatchForRetentionPolicies is a helper function for tables
@@ -1076,6 +1080,12 @@ func genericRetentionPoliciesDeletion(
 		}
 		rowsAffected = int64(len(ids))
 
+		if r.SearchNgramObjectType != "" {
+			if err = s.deleteSearchNgrams(txn, r.SearchNgramObjectType, ids); err != nil {
+				return 0, err
+			}
+		}
+
 		if len(ids) > 0 {
 			retentionIdsRow := model.RetentionIdsForDeletion{
 				TableName: r.Table,
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlSavedSearchStore struct {
+	*SqlStore
+
+	savedSearchSelectQuery sq.SelectBuilder
+}
+
+func newSqlSavedSearchStore(sqlStore *SqlStore) store.SavedSearchStore {
+	s := &SqlSavedSearchStore{
+		SqlStore: sqlStore,
+	}
+
+	s.savedSearchSelectQuery = s.getQueryBuilder().
+		Select(savedSearchColumns...).
+		From("SavedSearches")
+
+	return s
+}
+
+var savedSearchColumns = []string{
+	"Id",
+	"UserId",
+	"TeamId",
+	"Name",
+	"Terms",
+	"IsOrSearch",
+	"TimeZoneOffset",
+	"Params",
+	"Watch",
+	"CreateAt",
+	"UpdateAt",
+}
+
+func (s *SqlSavedSearchStore) Save(savedSearch *model.SavedSearch) (*model.SavedSearch, error) {
+	if savedSearch.Id != "" {
+		return nil, store.NewErrInvalidInput("SavedSearch", "id", savedSearch.Id)
+	}
+
+	savedSearch.PreSave()
+	if err := savedSearch.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("SavedSearches").
+		Columns(savedSearchColumns...).
+		Values(
+			savedSearch.Id,
+			savedSearch.UserId,
+			savedSearch.TeamId,
+			savedSearch.Name,
+			savedSearch.Terms,
+			savedSearch.IsOrSearch,
+			savedSearch.TimeZoneOffset,
+			savedSearch.Params,
+			savedSearch.Watch,
+			savedSearch.CreateAt,
+			savedSearch.UpdateAt,
+		)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return nil, errors.Wrapf(err, "failed to save SavedSearch with id=%s", savedSearch.Id)
+	}
+
+	return savedSearch, nil
+}
+
+func (s *SqlSavedSearchStore) Get(id string) (*model.SavedSearch, error) {
+	var savedSearch model.SavedSearch
+
+	if err := s.GetReplica().GetBuilder(&savedSearch, s.savedSearchSelectQuery.Where(sq.Eq{"Id": id})); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("SavedSearch", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get SavedSearch with id=%s", id)
+	}
+
+	return &savedSearch, nil
+}
+
+func (s *SqlSavedSearchStore) GetForUser(userID string) ([]*model.SavedSearch, error) {
+	savedSearches := []*model.SavedSearch{}
+
+	query := s.savedSearchSelectQuery.
+		Where(sq.Eq{"UserId": userID}).
+		OrderBy("CreateAt", "Id")
+
+	if err := s.GetReplica().SelectBuilder(&savedSearches, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find SavedSearches with userId=%s", userID)
+	}
+
+	return savedSearches, nil
+}
+
+func (s *SqlSavedSearchStore) CountForUser(userID string) (int64, error) {
+	query := s.getQueryBuilder().
+		Select("COUNT(*)").
+		From("SavedSearches").
+		Where(sq.Eq{"UserId": userID})
+
+	var count int64
+	if err := s.GetMaster().GetBuilder(&count, query); err != nil {
+		return 0, errors.Wrapf(err, "failed to count SavedSearches with userId=%s", userID)
+	}
+
+	return count, nil
+}
+
+func (s *SqlSavedSearchStore) GetWatched(afterId string, limit int) ([]*model.SavedSearch, error) {
+	savedSearches := []*model.SavedSearch{}
+
+	query := s.savedSearchSelectQuery.
+		Where(sq.Eq{"Watch": true}).
+		Where(sq.Gt{"Id": afterId}).
+		OrderBy("Id").
+		Limit(uint64(limit))
+
+	if err := s.GetReplica().SelectBuilder(&savedSearches, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find watched SavedSearches")
+	}
+
+	return savedSearches, nil
+}
+
+func (s *SqlSavedSearchStore) Update(savedSearch *model.SavedSearch) (*model.SavedSearch, error) {
+	savedSearch.PreUpdate()
+	if err := savedSearch.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Update("SavedSearches").
+		Set("TeamId", savedSearch.TeamId).
+		Set("Name", savedSearch.Name).
+		Set("Terms", savedSearch.Terms).
+		Set("IsOrSearch", savedSearch.IsOrSearch).
+		Set("TimeZoneOffset", savedSearch.TimeZoneOffset).
+		Set("Params", savedSearch.Params).
+		Set("Watch", savedSearch.Watch).
+		Set("UpdateAt", savedSearch.UpdateAt).
+		Where(sq.Eq{"Id": savedSearch.Id})
+
+	result, err := s.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to update SavedSearch with id=%s", savedSearch.Id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return nil, store.NewErrNotFound("SavedSearch", savedSearch.Id)
+	}
+
+	return savedSearch, nil
+}
+
+func (s *SqlSavedSearchStore) Delete(id string) error {
+	query := s.getQueryBuilder().
+		Delete("SavedSearches").
+		Where(sq.Eq{"Id": id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete SavedSearch with id=%s", id)
+	}
+
+	return nil
+}
+
+func (s *SqlSavedSearchStore) PermanentDeleteByUser(userID string) error {
+	query := s.getQueryBuilder().
+		Delete("SavedSearches").
+		Where(sq.Eq{"UserId": userID})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete SavedSearches with userId=%s", userID)
+	}
+
+	return nil
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const (
+	searchNgramObjectTypePost    = "post"
+	searchNgramObjectTypeFile    = "file"
+	searchNgramObjectTypeChannel = "channel"
+
+	// searchNgramMaxTokenBytes is the length of the longest token that is indexed. Postgres
+	// rejects the GIN index entries larger than about a third of a page, so longer words are
+	// left out of the index, as to_tsvector does, and are only found by their text.
+	searchNgramMaxTokenBytes = 2047
+)
+
+// searchNgramTermsRegex splits search terms into quoted phrases and words.
+var searchNgramTermsRegex = regexp.MustCompile(`"[^"]*"|\S+`)
+
+type SqlSearchNgramStore struct {
+	*SqlStore
+}
+
+func newSqlSearchNgramStore(sqlStore *SqlStore) store.SearchNgramStore {
+	return &SqlSearchNgramStore{sqlStore}
+}
+
+func (s *SqlSearchNgramStore) IndexPosts(posts []*model.Post) error {
+	return s.saveSearchNgrams(s.GetMaster(), searchNgramObjectTypePost, postNgramTexts(posts))
+}
+
+func (s *SqlSearchNgramStore) IndexFiles(files []*model.FileInfo) error {
+	return s.saveSearchNgrams(s.GetMaster(), searchNgramObjectTypeFile, fileNgramTexts(files))
+}
+
+func (s *SqlSearchNgramStore) IndexChannels(channels []*model.Channel) error {
+	return s.saveSearchNgrams(s.GetMaster(), searchNgramObjectTypeChannel, channelNgramTexts(channels))
+}
+
+func postNgramTexts(posts []*model.Post) map[string]string {
+	texts := make(map[string]string, len(posts))
+	for _, post := range posts {
+		texts[post.Id] = post.Message
+	}
+	return texts
+}
+
+func fileNgramTexts(files []*model.FileInfo) map[string]string {
+	texts := make(map[string]string, len(files))
+	for _, file := range files {
+		texts[file.Id] = file.Name + " " + file.Content
+	}
+	return texts
+}
+
+func channelNgramTexts(channels []*model.Channel) map[string]string {
+	texts := make(map[string]string, len(channels))
+	for _, channel := range channels {
+		// The names of direct messages are made of user IDs, they aren't searched.
+		if channel.Type == model.ChannelTypeDirect {
+			continue
+		}
+		texts[channel.Id] = channel.Name + " " + channel.DisplayName + " " + channel.Purpose
+	}
+	return texts
+}
+
+// ngramSearchEnabled returns whether the database search uses the n-gram index instead of
+// the Postgres full text search. Changing the mode requires a restart.
+func (ss *SqlStore) ngramSearchEnabled() bool {
+	return ss.settings.DatabaseSearchMode != nil && *ss.settings.DatabaseSearchMode == model.DatabaseSearchModeNgram
+}
+
+// saveSearchNgrams saves the n-grams of the texts of the given objects, keyed by their ID.
+func (ss *SqlStore) saveSearchNgrams(db sqlxExecutor, objectType string, texts map[string]string) error {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	query := ss.getQueryBuilder().
+		Insert("SearchNgrams").
+		Columns("ObjectType", "ObjectId", "Ngrams")
+	for id, text := range texts {
+		query = query.Values(objectType, id, sq.Expr("string_to_array(?, ' ')", strings.Join(searchNgrams(text), " ")))
+	}
+	query = query.Suffix("ON CONFLICT (ObjectId, ObjectType) DO UPDATE SET Ngrams = EXCLUDED.Ngrams")
+
+	if _, err := db.ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to save SearchNgrams with objectType=%s", objectType)
+	}
+
+	return nil
+}
+
+// indexSearchNgrams saves the n-grams of the texts of the given objects when the n-gram
+// search is enabled. It's called with the transaction writing the objects, so that they are
+// never missing from the index.
+func (ss *SqlStore) indexSearchNgrams(db sqlxExecutor, objectType string, texts map[string]string) error {
+	if !ss.ngramSearchEnabled() {
+		return nil
+	}
+
+	return ss.saveSearchNgrams(db, objectType, texts)
+}
+
+// deleteSearchNgrams deletes the n-grams of the given objects, so that they can't be found
+// once permanently deleted. It runs whatever the search mode is, since the index may have been
+// built before the mode was changed.
+func (ss *SqlStore) deleteSearchNgrams(db sqlxExecutor, objectType string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := ss.getQueryBuilder().
+		Delete("SearchNgrams").
+		Where(sq.Eq{"ObjectType": objectType, "ObjectId": ids})
+
+	if _, err := db.ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete SearchNgrams with objectType=%s", objectType)
+	}
+
+	return nil
+}
+
+// isNgramRune returns whether r is written without spaces between words, and is therefore
+// indexed by n-grams rather than by words.
+func isNgramRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// searchNgrams returns the distinct lowercase tokens of a text: its words, and the single
+// characters and bigrams of its Chinese, Japanese and Korean runs.
+func searchNgrams(text string) []string {
+	var tokens []string
+	seen := map[string]bool{}
+	add := func(token string) {
+		if len(token) > searchNgramMaxTokenBytes {
+			return
+		}
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+
+	var word []rune
+	var ngramRun []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			add(strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushNgramRun := func() {
+		for i := range ngramRun {
+			add(string(ngramRun[i]))
+			if i+1 < len(ngramRun) {
+				add(string(ngramRun[i : i+2]))
+			}
+		}
+		ngramRun = ngramRun[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isNgramRune(r):
+			flushWord()
+			ngramRun = append(ngramRun, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushNgramRun()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushNgramRun()
+		}
+	}
+	flushWord()
+	flushNgramRun()
+
+	return tokens
+}
+
+// searchNgramQueryTokens returns the tokens an object must have to match a term: the bigrams
+// of its Chinese, Japanese and Korean runs, or their single character when alone, and its
+// words. The last word of a prefix term is incomplete, so it isn't required, and the words
+// too long to be indexed aren't either.
+func searchNgramQueryTokens(term string, prefix bool) []string {
+	var tokens []string
+	addWord := func(word []rune) {
+		if token := strings.ToLower(string(word)); len(token) <= searchNgramMaxTokenBytes {
+			tokens = append(tokens, token)
+		}
+	}
+	var run []rune
+	flushRun := func() {
+		switch len(run) {
+		case 0:
+		case 1:
+			tokens = append(tokens, string(run))
+		default:
+			for i := 0; i+1 < len(run); i++ {
+				tokens = append(tokens, string(run[i:i+2]))
+			}
+		}
+		run = run[:0]
+	}
+
+	var word []rune
+	for _, r := range term + " " {
+		switch {
+		case isNgramRune(r):
+			if len(word) > 0 {
+				addWord(word)
+				word = word[:0]
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushRun()
+			word = append(word, r)
+		default:
+			flushRun()
+			if len(word) > 0 {
+				addWord(word)
+				word = word[:0]
+			}
+		}
+	}
+
+	if prefix && len(tokens) > 0 && !strings.ContainsFunc(tokens[len(tokens)-1], isNgramRune) {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return slices.Compact(tokens)
+}
+
+// splitNgramSearchTerms splits search terms into their words and quoted phrases, returning
+// each without its quotes and wildcard, and whether it is a prefix.
+func splitNgramSearchTerms(terms string) (phrases []string, prefixes []bool) {
+	for _, term := range searchNgramTermsRegex.FindAllString(terms, -1) {
+		prefix := false
+		if strings.HasPrefix(term, `"`) {
+			term = strings.Trim(term, `"`)
+		} else {
+			term, prefix = strings.CutSuffix(strings.TrimRight(term, `"`), "*")
+			term = strings.TrimLeft(term, `"`)
+		}
+
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		phrases = append(phrases, term)
+		prefixes = append(prefixes, prefix)
+	}
+	return phrases, prefixes
+}
+
+// buildNgramTermClause builds the clause of the objects matching a search term. The n-gram
+// index narrows down the candidates, and the texts of the objects are checked to contain
+// the term when the index alone can't tell, as for phrases, prefixes, bigrams and the words
+// too long to be indexed.
+func buildNgramTermClause(objectType, idColumn, textExpr, term string, prefix bool) sq.Sqlizer {
+	tokens := searchNgramQueryTokens(term, prefix)
+
+	clause := sq.And{}
+	if len(tokens) > 0 {
+		clause = append(clause, sq.Expr(
+			fmt.Sprintf("%s IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = '%s' AND Ngrams @> string_to_array(?, ' '))", idColumn, objectType),
+			strings.Join(tokens, " "),
+		))
+	}
+
+	if prefix || len(tokens) != 1 || len(term) > searchNgramMaxTokenBytes || strings.ContainsFunc(term, isNgramRune) {
+		likeTerm := sanitizeSearchTerm(term, "*")
+		if likeTerm == "" {
+			return nil
+		}
+		clause = append(clause, sq.Expr(fmt.Sprintf("%s ILIKE ? ESCAPE '*'", textExpr), wildcardSearchTerm(likeTerm)))
+	}
+
+	return clause
+}
+
+// buildNgramSearchClause builds the clause of the objects matching the search terms, and
+// none of the excluded terms, using the n-gram index.
+func buildNgramSearchClause(objectType, idColumn, textExpr, terms, excludedTerms string, orTerms bool) (sq.Sqlizer, error) {
+	var included []sq.Sqlizer
+	phrases, prefixes := splitNgramSearchTerms(terms)
+	for i, phrase := range phrases {
+		if clause := buildNgramTermClause(objectType, idColumn, textExpr, phrase, prefixes[i]); clause != nil {
+			included = append(included, clause)
+		}
+	}
+
+	clause := sq.And{}
+	if len(included) > 0 {
+		if orTerms {
+			clause = append(clause, sq.Or(included))
+		} else {
+			clause = append(clause, included...)
+		}
+	}
+
+	phrases, prefixes = splitNgramSearchTerms(excludedTerms)
+	for i, phrase := range phrases {
+		excluded := buildNgramTermClause(objectType, idColumn, textExpr, phrase, prefixes[i])
+		if excluded == nil {
+			continue
+		}
+		excludedSQL, excludedArgs, err := excluded.ToSql()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build excluded term clause")
+		}
+		clause = append(clause, sq.Expr("NOT ("+excludedSQL+")", excludedArgs...))
+	}
+
+	if len(clause) == 0 {
+		// Nothing searchable is left in the terms, so nothing matches.
+		return sq.Expr("FALSE"), nil
+	}
+
+	return clause, nil
+}
+
+// ngramSearchMatches returns the parts of the messages of the posts matching the terms of the
+// search params, so that clients can highlight them as with the other search engines.
+func ngramSearchMatches(posts *model.PostList, paramsList []*model.SearchParams) model.PostSearchMatches {
+	var matchers []*regexp.Regexp
+	for _, params := range paramsList {
+		if params.IsHashtag {
+			continue
+		}
+		phrases, prefixes := splitNgramSearchTerms(params.Terms)
+		for i, phrase := range phrases {
+			expr := "(?i)" + regexp.QuoteMeta(phrase)
+			if prefixes[i] {
+				expr += `[\p{L}\p{N}]*`
+			}
+			matchers = append(matchers, regexp.MustCompile(expr))
+		}
+	}
+
+	matches := model.PostSearchMatches{}
+	if len(matchers) == 0 {
+		return matches
+	}
+
+	for id, post := range posts.Posts {
+		var postMatches []string
+		for _, matcher := range matchers {
+			for _, match := range matcher.FindAllString(post.Message, -1) {
+				if !slices.Contains(postMatches, match) {
+					postMatches = append(postMatches, match)
+				}
+			}
+		}
+		if len(postMatches) > 0 {
+			matches[id] = postMatches
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+)
+
+// TestSearchNgramStore runs the store tests of the n-gram search against a store of its own,
+// since the search mode is read from the settings of the store.
+func TestSearchNgramStore(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	settings, err := makeSqlSettings(model.DatabaseDriverPostgres)
+	if err != nil {
+		t.Skip(err)
+	}
+	settings.DatabaseSearchMode = model.NewPointer(model.DatabaseSearchModeNgram)
+
+	ss, err := New(*settings, mlog.CreateTestLogger(t), nil)
+	require.NoError(t, err)
+	defer func() {
+		ss.Close()
+		storetest.CleanupSqlSettings(settings)
+	}()
+
+	storetest.TestSearchNgramStore(t, request.TestContext(t), ss, &StoreTestWrapper{ss})
+}
+
+func TestSearchNgrams(t *testing.T) {
+	if enableFullyParallelTests {
+		t.Parallel()
+	}
+
+	t.Run("words", func(t *testing.T) {
+		assert.Equal(t, []string{"deploy", "the", "api", "v2"}, searchNgrams("Deploy the API, v2 the API"))
+	})
+
+	t.Run("chinese, japanese and korean", func(t *testing.T) {
+		assert.Equal(t, []string{"東", "東京", "京"}, searchNgrams("東京"))
+		assert.Equal(t, []string{"会", "会議", "議", "議は", "は"}, searchNgrams("会議は"))
+		assert.Equal(t, []string{"회", "회의", "의"}, searchNgrams("회의"))
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		assert.Equal(t, []string{"tokyo", "東", "東京", "京", "office"}, searchNgrams("Tokyo東京 office"))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, searchNgrams(" ,. "))
+	})
+
+	t.Run("words too long to be indexed", func(t *testing.T) {
+		longWord := strings.Repeat("a", searchNgramMaxTokenBytes+1)
+		assert.Equal(t, []string{"before", "after"}, searchNgrams("before "+longWord+" after"))
+		assert.Equal(t, []string{strings.Repeat("a", searchNgramMaxTokenBytes)}, searchNgrams(strings.Repeat("A", searchNgramMaxTokenBytes)))
+	})
+}
+
+func TestSearchNgramQueryTokens(t *testing.T) {
+	if enableFullyParallelTests {
+		t.Parallel()
+	}
+
+	assert.Equal(t, []string{"deploy"}, searchNgramQueryTokens("Deploy", false))
+	assert.Empty(t, searchNgramQueryTokens("Deploy", true))
+	assert.Equal(t, []string{"東京", "京都"}, searchNgramQueryTokens("東京都", false))
+	assert.Equal(t, []string{"東京", "京都"}, searchNgramQueryTokens("東京都", true))
+	assert.Equal(t, []string{"東"}, searchNgramQueryTokens("東", false))
+	assert.Equal(t, []string{"new", "東京"}, searchNgramQueryTokens("new 東京 off", true))
+	assert.Equal(t, []string{"deploy"}, searchNgramQueryTokens("deploy "+strings.Repeat("a", searchNgramMaxTokenBytes+1), false))
+}
+
+func TestSplitNgramSearchTerms(t *testing.T) {
+	if enableFullyParallelTests {
+		t.Parallel()
+	}
+
+	phrases, prefixes := splitNgramSearchTerms(`会議 "tokyo office" deplo* ""`)
+	assert.Equal(t, []string{"会議", "tokyo office", "deplo"}, phrases)
+	assert.Equal(t, []bool{false, false, true}, prefixes)
+}
+
+func TestBuildNgramSearchClause(t *testing.T) {
+	if enableFullyParallelTests {
+		t.Parallel()
+	}
+
+	t.Run("single word", func(t *testing.T) {
+		clause, err := buildNgramSearchClause(searchNgramObjectTypePost, "Id", "Message", "deploy", "", false)
+		require.NoError(t, err)
+
+		sql, args, err := clause.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "((Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' '))))", sql)
+		assert.Equal(t, []any{"deploy"}, args)
+	})
+
+	t.Run("phrases are checked against the text", func(t *testing.T) {
+		clause, err := buildNgramSearchClause(searchNgramObjectTypePost, "Id", "Message", "東京都", "", false)
+		require.NoError(t, err)
+
+		sql, args, err := clause.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "((Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' ')) AND Message ILIKE ? ESCAPE '*'))", sql)
+		assert.Equal(t, []any{"東京 京都", "%東京都%"}, args)
+	})
+
+	t.Run("or and excluded terms", func(t *testing.T) {
+		clause, err := buildNgramSearchClause(searchNgramObjectTypePost, "Id", "Message", "deploy release", "rollback", true)
+		require.NoError(t, err)
+
+		sql, args, err := clause.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "(((Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' '))) OR (Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' ')))) AND NOT ((Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' ')))))", sql)
+		assert.Equal(t, []any{"deploy", "release", "rollback"}, args)
+	})
+
+	t.Run("words too long to be indexed are checked against the text", func(t *testing.T) {
+		longWord := strings.Repeat("a", searchNgramMaxTokenBytes+1)
+		clause, err := buildNgramSearchClause(searchNgramObjectTypePost, "Id", "Message", `"deploy `+longWord+`"`, "", false)
+		require.NoError(t, err)
+
+		sql, args, err := clause.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "((Id IN (SELECT ObjectId FROM SearchNgrams WHERE ObjectType = 'post' AND Ngrams @> string_to_array(?, ' ')) AND Message ILIKE ? ESCAPE '*'))", sql)
+		assert.Equal(t, []any{"deploy", "%deploy " + longWord + "%"}, args)
+	})
+
+	t.Run("nothing searchable", func(t *testing.T) {
+		clause, err := buildNgramSearchClause(searchNgramObjectTypePost, "Id", "Message", `""`, "", false)
+		require.NoError(t, err)
+
+		sql, _, err := clause.ToSql()
+		require.NoError(t, err)
+		assert.Equal(t, "FALSE", sql)
+	})
+}
+
+func TestNgramSearchMatches(t *testing.T) {
+	if enableFullyParallelTests {
+		t.Parallel()
+	}
+
+	posts := model.NewPostList()
+	posts.AddPost(&model.Post{Id: "post1", Message: "明日の会議は東京オフィスで Deployment"})
+	posts.AddPost(&model.Post{Id: "post2", Message: "nothing to see"})
+
+	matches := ngramSearchMatches(posts, []*model.SearchParams{
+		{Terms: "会議 deploy*"},
+		{Terms: "#hashtag", IsHashtag: true},
+	})
+	assert.Equal(t, model.PostSearchMatches{"post1": {"会議", "Deployment"}}, matches)
+}
@@ -111,6 +111,13 @@ type SqlStoreStores struct {
 	accessControlPolicy        store.AccessControlPolicyStore
 	Attributes                 store.AttributesStore
 	ContentFlagging            store.ContentFlaggingStore
+	outgoingWebhookDelivery    store.OutgoingWebhookDeliveryStore
+	webAuthnCredential         store.WebAuthnCredentialStore
+	pendingEmailNotification   store.PendingEmailNotificationStore
+	savedSearch                store.SavedSearchStore
+	searchNgram                store.SearchNgramStore
+	postEmbedding              store.PostEmbeddingStore
+	poll                       store.PollStore
 }
 
 type SqlStore struct {
@@ -261,6 +268,13 @@ func New(settings model.SqlSettings, logger mlog.LoggerIFace, metrics einterface
 	store.stores.accessControlPolicy = newSqlAccessControlPolicyStore(store, metrics)
 	store.stores.Attributes = newSqlAttributesStore(store, metrics)
 	store.stores.ContentFlagging = newContentFlaggingStore(store)
+	store.stores.outgoingWebhookDelivery = newSqlOutgoingWebhookDeliveryStore(store)
+	store.stores.webAuthnCredential = newSqlWebAuthnCredentialStore(store)
+	store.stores.pendingEmailNotification = newSqlPendingEmailNotificationStore(store)
+	store.stores.savedSearch = newSqlSavedSearchStore(store)
+	store.stores.searchNgram = newSqlSearchNgramStore(store)
+	store.stores.postEmbedding = newSqlPostEmbeddingStore(store)
+	store.stores.poll = newSqlPollStore(store)
 
 	store.stores.preference.(*SqlPreferenceStore).deleteUnusedFeatures()
 
@@ -1061,3 +1075,31 @@ func (ss *SqlStore) ScheduledPost() store.ScheduledPostStore {
 func (ss *SqlStore) ContentFlagging() store.ContentFlaggingStore {
 	return ss.stores.ContentFlagging
 }
+
+func (ss *SqlStore) OutgoingWebhookDelivery() store.OutgoingWebhookDeliveryStore {
+	return ss.stores.outgoingWebhookDelivery
+}
+
+func (ss *SqlStore) WebAuthnCredential() store.WebAuthnCredentialStore {
+	return ss.stores.webAuthnCredential
+}
+
+func (ss *SqlStore) PendingEmailNotification() store.PendingEmailNotificationStore {
+	return ss.stores.pendingEmailNotification
+}
+
+func (ss *SqlStore) SavedSearch() store.SavedSearchStore {
+	return ss.stores.savedSearch
+}
+
+func (ss *SqlStore) SearchNgram() store.SearchNgramStore {
+	return ss.stores.searchNgram
+}
+
+func (ss *SqlStore) PostEmbedding() store.PostEmbeddingStore {
+	return ss.stores.postEmbedding
+}
+
+func (ss *SqlStore) Poll() store.PollStore {
+	return ss.stores.poll
+}
@@ -514,6 +514,31 @@ func (us SqlUserStore) GetMfaUsedTimestamps(userId string) ([]int, error) {
 	return ts, nil
 }
 
+func (us SqlUserStore) UpdateMfaRecoveryCodes(userId string, hashedCodes []string) error {
+	updateAt := model.GetMillis()
+	if _, err := us.GetMaster().Exec("UPDATE Users SET MfaRecoveryCodes = ?, UpdateAt = ? WHERE Id = ?", model.StringArray(hashedCodes), updateAt, userId); err != nil {
+		return errors.Wrapf(err, "failed to update User with userId=%s", userId)
+	}
+	return nil
+}
+
+func (us SqlUserStore) UseMfaRecoveryCode(userId, hashedCode string) (bool, error) {
+	// Removing the code in the same statement that checks for it ensures it can only be used once.
+	res, err := us.GetMaster().Exec(`UPDATE Users
+		SET MfaRecoveryCodes = MfaRecoveryCodes - ?::text, UpdateAt = ?
+		WHERE Id = ? AND MfaRecoveryCodes @> jsonb_build_array(?::text)`, hashedCode, model.GetMillis(), userId, hashedCode)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to use MFA recovery code for user with ID %s", userId)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "unable to get rows affected")
+	}
+
+	return rows == 1, nil
+}
+
 // GetMany returns a list of users for the provided list of ids
 func (us SqlUserStore) GetMany(rctx request.CTX, ids []string) ([]*model.User, error) {
 	query := us.usersQuery.Where(sq.Eq{"Id": ids})
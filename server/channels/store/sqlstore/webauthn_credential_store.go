@@ -0,0 +1,158 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlWebAuthnCredentialStore struct {
+	*SqlStore
+
+	credentialSelectQuery sq.SelectBuilder
+}
+
+func newSqlWebAuthnCredentialStore(sqlStore *SqlStore) store.WebAuthnCredentialStore {
+	s := &SqlWebAuthnCredentialStore{
+		SqlStore: sqlStore,
+	}
+
+	s.credentialSelectQuery = s.getQueryBuilder().
+		Select(webAuthnCredentialColumns...).
+		From("WebAuthnCredentials")
+
+	return s
+}
+
+var webAuthnCredentialColumns = []string{
+	"Id",
+	"UserId",
+	"Name",
+	"CredentialId",
+	"PublicKey",
+	"AAGUID",
+	"SignCount",
+	"CreateAt",
+	"LastUsedAt",
+}
+
+func (s *SqlWebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	if credential.Id != "" {
+		return nil, store.NewErrInvalidInput("WebAuthnCredential", "id", credential.Id)
+	}
+
+	credential.PreSave()
+	if err := credential.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("WebAuthnCredentials").
+		Columns(webAuthnCredentialColumns...).
+		Values(
+			credential.Id,
+			credential.UserId,
+			credential.Name,
+			credential.CredentialId,
+			credential.PublicKey,
+			credential.AAGUID,
+			credential.SignCount,
+			credential.CreateAt,
+			credential.LastUsedAt,
+		)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		if IsUniqueConstraintError(err, []string{"CredentialId", "idx_webauthncredentials_credentialid"}) {
+			return nil, store.NewErrConflict("WebAuthnCredential", err, "credentialId="+credential.CredentialId)
+		}
+		return nil, errors.Wrapf(err, "failed to save WebAuthnCredential with id=%s", credential.Id)
+	}
+
+	return credential, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) Get(id string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+
+	if err := s.GetReplica().GetBuilder(&credential, s.credentialSelectQuery.Where(sq.Eq{"Id": id})); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("WebAuthnCredential", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get WebAuthnCredential with id=%s", id)
+	}
+
+	return &credential, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) GetByCredentialId(credentialID string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+
+	// Read from master as the signature counter must never go backwards.
+	if err := s.GetMaster().GetBuilder(&credential, s.credentialSelectQuery.Where(sq.Eq{"CredentialId": credentialID})); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("WebAuthnCredential", "credentialId="+credentialID)
+		}
+		return nil, errors.Wrapf(err, "failed to get WebAuthnCredential with credentialId=%s", credentialID)
+	}
+
+	return &credential, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+	credentials := []*model.WebAuthnCredential{}
+
+	query := s.credentialSelectQuery.
+		Where(sq.Eq{"UserId": userID}).
+		OrderBy("CreateAt", "Id")
+
+	if err := s.GetMaster().SelectBuilder(&credentials, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find WebAuthnCredentials with userId=%s", userID)
+	}
+
+	return credentials, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) UpdateLastUsed(id string, signCount int64, lastUsedAt int64) error {
+	query := s.getQueryBuilder().
+		Update("WebAuthnCredentials").
+		Set("SignCount", signCount).
+		Set("LastUsedAt", lastUsedAt).
+		Where(sq.Eq{"Id": id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to update WebAuthnCredential with id=%s", id)
+	}
+
+	return nil
+}
+
+func (s *SqlWebAuthnCredentialStore) Delete(id string) error {
+	query := s.getQueryBuilder().
+		Delete("WebAuthnCredentials").
+		Where(sq.Eq{"Id": id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete WebAuthnCredential with id=%s", id)
+	}
+
+	return nil
+}
+
+func (s *SqlWebAuthnCredentialStore) PermanentDeleteByUser(userID string) error {
+	query := s.getQueryBuilder().
+		Delete("WebAuthnCredentials").
+		Where(sq.Eq{"UserId": userID})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete WebAuthnCredentials with userId=%s", userID)
+	}
+
+	return nil
+}
@@ -97,6 +97,13 @@ type Store interface {
 	Attributes() AttributesStore
 	GetSchemaDefinition() (*model.SupportPacketDatabaseSchema, error)
 	ContentFlagging() ContentFlaggingStore
+	OutgoingWebhookDelivery() OutgoingWebhookDeliveryStore
+	WebAuthnCredential() WebAuthnCredentialStore
+	PendingEmailNotification() PendingEmailNotificationStore
+	SavedSearch() SavedSearchStore
+	SearchNgram() SearchNgramStore
+	PostEmbedding() PostEmbeddingStore
+	Poll() PollStore
 }
 
 type RetentionPolicyStore interface {
@@ -437,6 +444,9 @@ type UserStore interface {
 	UpdateMfaActive(userID string, active bool) error
 	StoreMfaUsedTimestamps(userID string, ts []int) error
 	GetMfaUsedTimestamps(userID string) ([]int, error)
+	UpdateMfaRecoveryCodes(userID string, hashedCodes []string) error
+	// UseMfaRecoveryCode removes the recovery code from the user, reporting whether it was present.
+	UseMfaRecoveryCode(userID, hashedCode string) (bool, error)
 	Get(ctx context.Context, id string) (*model.User, error)
 	GetMany(rctx request.CTX, ids []string) ([]*model.User, error)
 	GetAll() ([]*model.User, error)
@@ -759,6 +769,18 @@ type FileInfoStore interface {
 	GetUptoNSizeFileTime(n int64) (int64, error)
 	// RefreshFileStats recomputes the fileinfo materialized views.
 	RefreshFileStats() error
+	// ReserveBlob creates the row of a deduplicated blob if needed and returns its reference count.
+	ReserveBlob(hash string, size int64) (int64, error)
+	GetBlob(hash string) (*model.FileBlob, error)
+	// DeleteUnreferencedBlobs deletes the rows of blobs without references and returns their hashes.
+	DeleteUnreferencedBlobs(updatedBefore int64, limit int) ([]string, error)
+	GetBatchWithoutContentHash(afterID string, limit int) ([]*model.FileInfo, error)
+	// SetContentHashForPath moves every FileInfo stored at path to a deduplicated blob.
+	SetContentHashForPath(rctx request.CTX, path, hash, blobPath string, size int64) ([]*model.FileInfo, error)
+	GetFileStorageUsage(ownerType, ownerID string) (int64, error)
+	GetFileStorageUsagePage(ownerType string, offset, limit int) ([]*model.FileStorageUsage, error)
+	// GetQuarantined returns the infected files kept in quarantine, most recent first.
+	GetQuarantined(offset, limit int) ([]*model.FileInfo, error)
 }
 
 type UploadSessionStore interface {
@@ -1153,6 +1175,90 @@ type ContentFlaggingStore interface {
 	ClearCaches()
 }
 
+type OutgoingWebhookDeliveryStore interface {
+	Save(delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, error)
+	Get(id string) (*model.OutgoingWebhookDelivery, error)
+	Update(delivery *model.OutgoingWebhookDelivery) (*model.OutgoingWebhookDelivery, error)
+	GetForHook(hookID string, status string, offset, limit int) ([]*model.OutgoingWebhookDelivery, error)
+	GetDue(now int64, limit int) ([]*model.OutgoingWebhookDelivery, error)
+	PermanentDeleteFinishedOlderThan(olderThan int64, limit int64) (int64, error)
+}
+
+type PendingEmailNotificationStore interface {
+	Save(notification *model.PendingEmailNotification) (*model.PendingEmailNotification, error)
+	GetUserIds(afterUserId string, limit int) ([]string, error)
+	GetForUser(userID string) ([]*model.PendingEmailNotification, error)
+	// Claim marks the notifications as claimed at claimedAt, unless any of them holds a claim
+	// made after leaseExpiredAt. It reports whether all the notifications were claimed.
+	Claim(ids []string, claimedAt, leaseExpiredAt int64) (bool, error)
+	Release(ids []string) error
+	Delete(ids []string) (int64, error)
+}
+
+type WebAuthnCredentialStore interface {
+	Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error)
+	Get(id string) (*model.WebAuthnCredential, error)
+	GetByCredentialId(credentialID string) (*model.WebAuthnCredential, error)
+	GetForUser(userID string) ([]*model.WebAuthnCredential, error)
+	UpdateLastUsed(id string, signCount int64, lastUsedAt int64) error
+	Delete(id string) error
+	PermanentDeleteByUser(userID string) error
+}
+
+type SavedSearchStore interface {
+	Save(savedSearch *model.SavedSearch) (*model.SavedSearch, error)
+	Get(id string) (*model.SavedSearch, error)
+	GetForUser(userID string) ([]*model.SavedSearch, error)
+	CountForUser(userID string) (int64, error)
+	// GetWatched returns, in order, the watched saved searches whose ids sort after afterId.
+	GetWatched(afterId string, limit int) ([]*model.SavedSearch, error)
+	Update(savedSearch *model.SavedSearch) (*model.SavedSearch, error)
+	Delete(id string) error
+	PermanentDeleteByUser(userID string) error
+}
+
+// SearchNgramStore indexes the n-grams used by the database search in the n-gram mode.
+type SearchNgramStore interface {
+	IndexPosts(posts []*model.Post) error
+	IndexFiles(files []*model.FileInfo) error
+	IndexChannels(channels []*model.Channel) error
+}
+
+// PostEmbeddingStore stores the embeddings of the posts searched by the semantic search.
+type PostEmbeddingStore interface {
+	Save(embeddings []*model.PostEmbedding) error
+	Delete(postIDs []string) error
+	// GetForPosts returns the embeddings of the posts computed with the given model, without
+	// their vectors.
+	GetForPosts(postIDs []string, embeddingModel string) ([]*model.PostEmbedding, error)
+	// SetDimensions types the embeddings with the number of dimensions of the embedding model,
+	// and indexes them for the similarity search. The embeddings of other dimensions are
+	// deleted.
+	SetDimensions(dimensions int) error
+	// Search returns the IDs of the posts of the channels of the user whose embeddings are the
+	// most similar to the given one, most similar first. Deleted posts are skipped. An empty
+	// teamID searches all the teams of the user.
+	Search(userID, teamID string, embedding []float32, embeddingModel string, includeDeletedChannels bool, limit int) ([]string, error)
+}
+
+// PollStore stores the polls of the posts of type poll, and their votes.
+type PollStore interface {
+	Save(poll *model.Poll) (*model.Poll, error)
+	Get(postID string) (*model.Poll, error)
+	// Close closes the poll to new votes, it returns a not found error if it is already closed.
+	Close(postID string, closedAt int64) error
+	// SaveVotes replaces the votes of the user for the poll, it returns a not found error if the
+	// poll is closed.
+	SaveVotes(postID, userID string, optionIDs []string) error
+	// DeleteVotes removes the votes of the user for the poll, it returns a not found error if the
+	// poll is closed.
+	DeleteVotes(postID, userID string) error
+	GetVotes(postID string) ([]*model.PollVote, error)
+	// ImportVotes replaces the votes of the user for the poll even if the poll is closed, so that
+	// the bulk import can restore the votes of closed polls.
+	ImportVotes(postID, userID string, optionIDs []string) error
+}
+
 // ChannelSearchOpts contains options for searching channels.
 //
 // NotAssociatedToGroup will exclude channels that have associated, active GroupChannels records.
@@ -4,6 +4,8 @@
 package storetest
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"testing"
@@ -21,6 +23,8 @@ import (
 func TestFileInfoStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
 	t.Cleanup(func() {
 		s.GetMaster().Exec("TRUNCATE FileInfo")
+		s.GetMaster().Exec("TRUNCATE FileBlobs")
+		s.GetMaster().Exec("TRUNCATE FileStorageUsage")
 	})
 	t.Run("FileInfoSaveGet", func(t *testing.T) { testFileInfoSaveGet(t, rctx, ss) })
 	t.Run("FileInfoSaveGetByPath", func(t *testing.T) { testFileInfoSaveGetByPath(t, rctx, ss) })
@@ -41,6 +45,12 @@ func TestFileInfoStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStor
 	t.Run("FileInfoGetByIds", func(t *testing.T) { testGetByIds(t, rctx, ss) })
 	t.Run("FileInfoDeleteForPostByIds", func(t *testing.T) { testDeleteForPostByIds(t, rctx, ss) })
 	t.Run("FileInfoRestoreForPostByIds", func(t *testing.T) { testRestoreUndeleteForPostByIds(t, rctx, ss) })
+	t.Run("FileInfoBlobReferences", func(t *testing.T) { testFileInfoBlobReferences(t, rctx, ss) })
+	t.Run("FileInfoDeleteUnreferencedBlobs", func(t *testing.T) { testFileInfoDeleteUnreferencedBlobs(t, rctx, ss) })
+	t.Run("FileInfoSetContentHashForPath", func(t *testing.T) { testFileInfoSetContentHashForPath(t, rctx, ss) })
+	t.Run("FileInfoFileStorageUsage", func(t *testing.T) { testFileInfoFileStorageUsage(t, rctx, ss) })
+	t.Run("FileInfoGetQuarantined", func(t *testing.T) { testFileInfoGetQuarantined(t, rctx, ss) })
+	t.Run("FileInfoMetadataStripped", func(t *testing.T) { testFileInfoMetadataStripped(t, rctx, ss) })
 }
 
 func testFileInfoSaveGet(t *testing.T, rctx request.CTX, ss store.Store) {
@@ -1539,3 +1549,327 @@ func testRestoreUndeleteForPostByIds(t *testing.T, rctx request.CTX, ss store.St
 		}
 	})
 }
+
+func newTestContentHash() string {
+	hash := sha256.Sum256([]byte(model.NewId()))
+	return hex.EncodeToString(hash[:])
+}
+
+func testFileInfoBlobReferences(t *testing.T, rctx request.CTX, ss store.Store) {
+	hash := newTestContentHash()
+
+	_, err := ss.FileInfo().GetBlob(hash)
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+
+	refCount, err := ss.FileInfo().ReserveBlob(hash, 10)
+	require.NoError(t, err)
+	assert.Zero(t, refCount)
+
+	postId := model.NewId()
+	var infos []*model.FileInfo
+	for range 3 {
+		info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+			CreatorId:   model.NewId(),
+			PostId:      postId,
+			Path:        "blobs/" + hash,
+			ContentHash: hash,
+			Size:        10,
+		})
+		require.NoError(t, err)
+		infos = append(infos, info)
+	}
+
+	refCount, err = ss.FileInfo().ReserveBlob(hash, 10)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, refCount)
+
+	rinfo, err := ss.FileInfo().Get(infos[0].Id)
+	require.NoError(t, err)
+	assert.Equal(t, hash, rinfo.ContentHash)
+
+	require.NoError(t, ss.FileInfo().PermanentDelete(rctx, infos[0].Id))
+	blob, err := ss.FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, blob.RefCount)
+	assert.EqualValues(t, 10, blob.Size)
+
+	require.NoError(t, ss.FileInfo().PermanentDeleteForPost(rctx, postId))
+	blob, err = ss.FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.Zero(t, blob.RefCount)
+
+	userId := model.NewId()
+	_, err = ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:   userId,
+		Path:        "blobs/" + hash,
+		ContentHash: hash,
+	})
+	require.NoError(t, err)
+	_, err = ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: userId,
+		Path:      "file.txt",
+	})
+	require.NoError(t, err)
+
+	deleted, err := ss.FileInfo().PermanentDeleteByUser(rctx, userId)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+	blob, err = ss.FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.Zero(t, blob.RefCount)
+}
+
+func testFileInfoDeleteUnreferencedBlobs(t *testing.T, rctx request.CTX, ss store.Store) {
+	referenced := newTestContentHash()
+	unreferenced := newTestContentHash()
+
+	_, err := ss.FileInfo().ReserveBlob(unreferenced, 10)
+	require.NoError(t, err)
+	_, err = ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:   model.NewId(),
+		Path:        "blobs/" + referenced,
+		ContentHash: referenced,
+	})
+	require.NoError(t, err)
+
+	hashes, err := ss.FileInfo().DeleteUnreferencedBlobs(model.GetMillis()-time.Hour.Milliseconds(), 10)
+	require.NoError(t, err)
+	assert.NotContains(t, hashes, unreferenced, "recently reserved blobs are kept")
+
+	hashes, err = ss.FileInfo().DeleteUnreferencedBlobs(model.GetMillis()+1, 10)
+	require.NoError(t, err)
+	assert.Contains(t, hashes, unreferenced)
+	assert.NotContains(t, hashes, referenced)
+
+	_, err = ss.FileInfo().GetBlob(unreferenced)
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+
+	_, err = ss.FileInfo().GetBlob(referenced)
+	require.NoError(t, err)
+}
+
+func testFileInfoSetContentHashForPath(t *testing.T, rctx request.CTX, ss store.Store) {
+	path := "20250101/teams/noteam/channels/" + model.NewId() + "/file.txt"
+	other, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: model.NewId(),
+		Path:      "other.txt",
+	})
+	require.NoError(t, err)
+
+	var ids []string
+	for _, deleteAt := range []int64{0, 0, 123} {
+		info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+			CreatorId: model.NewId(),
+			Path:      path,
+			DeleteAt:  deleteAt,
+			Size:      20,
+		})
+		require.NoError(t, err)
+		ids = append(ids, info.Id)
+	}
+
+	infos, err := ss.FileInfo().GetBatchWithoutContentHash("", 1000)
+	require.NoError(t, err)
+	var found []string
+	for _, info := range infos {
+		found = append(found, info.Id)
+	}
+	assert.Subset(t, found, append(ids, other.Id))
+	assert.True(t, sort.SliceIsSorted(infos, func(i, j int) bool { return infos[i].Id < infos[j].Id }))
+
+	infos, err = ss.FileInfo().GetBatchWithoutContentHash(infos[0].Id, 1)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, found[1], infos[0].Id)
+
+	hash := newTestContentHash()
+	updated, err := ss.FileInfo().SetContentHashForPath(rctx, path, hash, "blobs/"+hash, 20)
+	require.NoError(t, err)
+	assert.Len(t, updated, 3)
+	for _, info := range updated {
+		assert.Contains(t, ids, info.Id)
+		assert.Equal(t, hash, info.ContentHash)
+		assert.Equal(t, "blobs/"+hash, info.Path)
+	}
+
+	blob, err := ss.FileInfo().GetBlob(hash)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, blob.RefCount)
+
+	infos, err = ss.FileInfo().GetBatchWithoutContentHash("", 1000)
+	require.NoError(t, err)
+	for _, info := range infos {
+		assert.NotContains(t, ids, info.Id)
+	}
+
+	updated, err = ss.FileInfo().SetContentHashForPath(rctx, path, hash, "blobs/"+hash, 20)
+	require.NoError(t, err)
+	assert.Empty(t, updated)
+}
+
+func testFileInfoFileStorageUsage(t *testing.T, rctx request.CTX, ss store.Store) {
+	teamId := model.NewId()
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Storage usage",
+		Name:        NewTestID(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+
+	userId := model.NewId()
+	otherUserId := model.NewId()
+	save := func(creatorId, channelId string, size int64) *model.FileInfo {
+		t.Helper()
+		info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+			CreatorId: creatorId,
+			PostId:    model.NewId(),
+			ChannelId: channelId,
+			Path:      "file.txt",
+			Size:      size,
+		})
+		require.NoError(t, err)
+		return info
+	}
+
+	info1 := save(userId, channel.Id, 100)
+	save(userId, channel.Id, 50)
+	save(otherUserId, channel.Id, 300)
+	save(userId, model.NewId(), 25)
+
+	usage, err := ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeUser, userId)
+	require.NoError(t, err)
+	assert.EqualValues(t, 175, usage)
+
+	usage, err = ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeTeam, teamId)
+	require.NoError(t, err)
+	assert.EqualValues(t, 450, usage)
+
+	usage, err = ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeUser, model.NewId())
+	require.NoError(t, err)
+	assert.Zero(t, usage)
+
+	t.Run("soft deleted files still count", func(t *testing.T) {
+		_, err := ss.FileInfo().DeleteForPost(rctx, info1.PostId)
+		require.NoError(t, err)
+
+		usage, err := ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeUser, userId)
+		require.NoError(t, err)
+		assert.EqualValues(t, 175, usage)
+	})
+
+	t.Run("permanently deleted files are released", func(t *testing.T) {
+		require.NoError(t, ss.FileInfo().PermanentDelete(rctx, info1.Id))
+
+		usage, err := ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeUser, userId)
+		require.NoError(t, err)
+		assert.EqualValues(t, 75, usage)
+
+		usage, err = ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeTeam, teamId)
+		require.NoError(t, err)
+		assert.EqualValues(t, 350, usage)
+
+		_, err = ss.FileInfo().PermanentDeleteByUser(rctx, otherUserId)
+		require.NoError(t, err)
+
+		usage, err = ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeUser, otherUserId)
+		require.NoError(t, err)
+		assert.Zero(t, usage)
+
+		usage, err = ss.FileInfo().GetFileStorageUsage(model.FileStorageUsageOwnerTypeTeam, teamId)
+		require.NoError(t, err)
+		assert.EqualValues(t, 50, usage)
+	})
+
+	t.Run("page", func(t *testing.T) {
+		// Other tests leave files behind, so the largest usage must be larger than theirs.
+		bigUserId := model.NewId()
+		save(bigUserId, "", 1<<40)
+
+		usages, err := ss.FileInfo().GetFileStorageUsagePage(model.FileStorageUsageOwnerTypeUser, 0, 100)
+		require.NoError(t, err)
+		require.NotEmpty(t, usages)
+		assert.Equal(t, bigUserId, usages[0].OwnerId)
+		assert.EqualValues(t, 1<<40, usages[0].UsedBytes)
+		assert.Equal(t, model.FileStorageUsageOwnerTypeUser, usages[0].OwnerType)
+		for i, usage := range usages {
+			assert.NotEqual(t, otherUserId, usage.OwnerId, "users without stored files are left out")
+			if i > 0 {
+				assert.LessOrEqual(t, usage.UsedBytes, usages[i-1].UsedBytes)
+			}
+		}
+
+		usages, err = ss.FileInfo().GetFileStorageUsagePage(model.FileStorageUsageOwnerTypeUser, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, usages, 1)
+		assert.NotEqual(t, bigUserId, usages[0].OwnerId)
+	})
+}
+
+func testFileInfoGetQuarantined(t *testing.T, rctx request.CTX, ss store.Store) {
+	clean, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:   model.NewId(),
+		Path:        "clean.txt",
+		ScanVerdict: model.FileScanVerdictClean,
+	})
+	require.NoError(t, err)
+
+	createAt := model.GetMillis()
+	infected := make([]*model.FileInfo, 0, 2)
+	for i := range 2 {
+		info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+			CreatorId:     model.NewId(),
+			Path:          "quarantine/infected.exe",
+			CreateAt:      createAt + int64(i),
+			DeleteAt:      createAt,
+			ScanVerdict:   model.FileScanVerdictInfected,
+			ScanSignature: "Eicar-Signature",
+		})
+		require.NoError(t, err)
+		infected = append(infected, info)
+	}
+
+	infos, err := ss.FileInfo().GetQuarantined(0, 10)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, infected[1].Id, infos[0].Id, "most recent first")
+	assert.Equal(t, infected[0].Id, infos[1].Id)
+	assert.Equal(t, model.FileScanVerdictInfected, infos[0].ScanVerdict)
+	assert.Equal(t, "Eicar-Signature", infos[0].ScanSignature)
+
+	infos, err = ss.FileInfo().GetQuarantined(1, 10)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, infected[0].Id, infos[0].Id)
+
+	info, err := ss.FileInfo().Get(clean.Id)
+	require.NoError(t, err)
+	assert.Equal(t, model.FileScanVerdictClean, info.ScanVerdict)
+}
+
+func testFileInfoMetadataStripped(t *testing.T, rctx request.CTX, ss store.Store) {
+	info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:        model.NewId(),
+		Path:             "photo.jpg",
+		MetadataStripped: true,
+		OriginalPath:     "original/photo.jpg",
+	})
+	require.NoError(t, err)
+
+	saved, err := ss.FileInfo().Get(info.Id)
+	require.NoError(t, err)
+	assert.True(t, saved.MetadataStripped)
+	assert.Equal(t, "original/photo.jpg", saved.OriginalPath)
+
+	saved.MetadataStripped = false
+	saved.OriginalPath = ""
+	_, err = ss.FileInfo().Upsert(rctx, saved)
+	require.NoError(t, err)
+
+	saved, err = ss.FileInfo().Get(info.Id)
+	require.NoError(t, err)
+	assert.False(t, saved.MetadataStripped)
+	assert.Empty(t, saved.OriginalPath)
+}
@@ -112,6 +112,36 @@ func (_m *FileInfoStore) DeleteForPostByIds(rctx request.CTX, postId string, fil
 	return r0
 }
 
+// DeleteUnreferencedBlobs provides a mock function with given fields: updatedBefore, limit
+func (_m *FileInfoStore) DeleteUnreferencedBlobs(updatedBefore int64, limit int) ([]string, error) {
+	ret := _m.Called(updatedBefore, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUnreferencedBlobs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int) ([]string, error)); ok {
+		return rf(updatedBefore, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int) []string); ok {
+		r0 = rf(updatedBefore, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int) error); ok {
+		r1 = rf(updatedBefore, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: id
 func (_m *FileInfoStore) Get(id string) (*model.FileInfo, error) {
 	ret := _m.Called(id)
@@ -142,6 +172,66 @@ func (_m *FileInfoStore) Get(id string) (*model.FileInfo, error) {
 	return r0, r1
 }
 
+// GetBatchWithoutContentHash provides a mock function with given fields: afterID, limit
+func (_m *FileInfoStore) GetBatchWithoutContentHash(afterID string, limit int) ([]*model.FileInfo, error) {
+	ret := _m.Called(afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBatchWithoutContentHash")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]*model.FileInfo, error)); ok {
+		return rf(afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []*model.FileInfo); ok {
+		r0 = rf(afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlob provides a mock function with given fields: hash
+func (_m *FileInfoStore) GetBlob(hash string) (*model.FileBlob, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlob")
+	}
+
+	var r0 *model.FileBlob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.FileBlob, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.FileBlob); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.FileBlob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByIds provides a mock function with given fields: ids, includeDeleted, allowFromCache
 func (_m *FileInfoStore) GetByIds(ids []string, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
 	ret := _m.Called(ids, includeDeleted, allowFromCache)
@@ -202,6 +292,64 @@ func (_m *FileInfoStore) GetByPath(path string) (*model.FileInfo, error) {
 	return r0, r1
 }
 
+// GetFileStorageUsage provides a mock function with given fields: ownerType, ownerID
+func (_m *FileInfoStore) GetFileStorageUsage(ownerType string, ownerID string) (int64, error) {
+	ret := _m.Called(ownerType, ownerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileStorageUsage")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (int64, error)); ok {
+		return rf(ownerType, ownerID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
+		r0 = rf(ownerType, ownerID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(ownerType, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFileStorageUsagePage provides a mock function with given fields: ownerType, offset, limit
+func (_m *FileInfoStore) GetFileStorageUsagePage(ownerType string, offset int, limit int) ([]*model.FileStorageUsage, error) {
+	ret := _m.Called(ownerType, offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFileStorageUsagePage")
+	}
+
+	var r0 []*model.FileStorageUsage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int, int) ([]*model.FileStorageUsage, error)); ok {
+		return rf(ownerType, offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int, int) []*model.FileStorageUsage); ok {
+		r0 = rf(ownerType, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileStorageUsage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(ownerType, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFilesBatchForIndexing provides a mock function with given fields: startTime, startFileID, includeDeleted, limit
 func (_m *FileInfoStore) GetFilesBatchForIndexing(startTime int64, startFileID string, includeDeleted bool, limit int) ([]*model.FileForIndexing, error) {
 	ret := _m.Called(startTime, startFileID, includeDeleted, limit)
@@ -322,6 +470,36 @@ func (_m *FileInfoStore) GetFromMaster(id string) (*model.FileInfo, error) {
 	return r0, r1
 }
 
+// GetQuarantined provides a mock function with given fields: offset, limit
+func (_m *FileInfoStore) GetQuarantined(offset int, limit int) ([]*model.FileInfo, error) {
+	ret := _m.Called(offset, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetQuarantined")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, int) ([]*model.FileInfo, error)); ok {
+		return rf(offset, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int, int) []*model.FileInfo); ok {
+		r0 = rf(offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetStorageUsage provides a mock function with given fields: allowFromCache, includeDeleted
 func (_m *FileInfoStore) GetStorageUsage(allowFromCache bool, includeDeleted bool) (int64, error) {
 	ret := _m.Called(allowFromCache, includeDeleted)
@@ -523,6 +701,34 @@ func (_m *FileInfoStore) RefreshFileStats() error {
 	return r0
 }
 
+// ReserveBlob provides a mock function with given fields: hash, size
+func (_m *FileInfoStore) ReserveBlob(hash string, size int64) (int64, error) {
+	ret := _m.Called(hash, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveBlob")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (int64, error)); ok {
+		return rf(hash, size)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) int64); ok {
+		r0 = rf(hash, size)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(hash, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RestoreForPostByIds provides a mock function with given fields: rctx, postId, fileIDs
 func (_m *FileInfoStore) RestoreForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
 	ret := _m.Called(rctx, postId, fileIDs)
@@ -619,6 +825,36 @@ func (_m *FileInfoStore) SetContent(rctx request.CTX, fileID string, content str
 	return r0
 }
 
+// SetContentHashForPath provides a mock function with given fields: rctx, path, hash, blobPath, size
+func (_m *FileInfoStore) SetContentHashForPath(rctx request.CTX, path string, hash string, blobPath string, size int64) ([]*model.FileInfo, error) {
+	ret := _m.Called(rctx, path, hash, blobPath, size)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetContentHashForPath")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(request.CTX, string, string, string, int64) ([]*model.FileInfo, error)); ok {
+		return rf(rctx, path, hash, blobPath, size)
+	}
+	if rf, ok := ret.Get(0).(func(request.CTX, string, string, string, int64) []*model.FileInfo); ok {
+		r0 = rf(rctx, path, hash, blobPath, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(request.CTX, string, string, string, int64) error); ok {
+		r1 = rf(rctx, path, hash, blobPath, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Upsert provides a mock function with given fields: rctx, info
 func (_m *FileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*model.FileInfo, error) {
 	ret := _m.Called(rctx, info)
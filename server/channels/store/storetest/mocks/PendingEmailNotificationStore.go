@@ -0,0 +1,193 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import (
+	model "github.com/mattermost/mattermost/server/public/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PendingEmailNotificationStore is an autogenerated mock type for the PendingEmailNotificationStore type
+type PendingEmailNotificationStore struct {
+	mock.Mock
+}
+
+// Claim provides a mock function with given fields: ids, claimedAt, leaseExpiredAt
+func (_m *PendingEmailNotificationStore) Claim(ids []string, claimedAt int64, leaseExpiredAt int64) (bool, error) {
+	ret := _m.Called(ids, claimedAt, leaseExpiredAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Claim")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string, int64, int64) (bool, error)); ok {
+		return rf(ids, claimedAt, leaseExpiredAt)
+	}
+	if rf, ok := ret.Get(0).(func([]string, int64, int64) bool); ok {
+		r0 = rf(ids, claimedAt, leaseExpiredAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, int64, int64) error); ok {
+		r1 = rf(ids, claimedAt, leaseExpiredAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: ids
+func (_m *PendingEmailNotificationStore) Delete(ids []string) (int64, error) {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) (int64, error)); ok {
+		return rf(ids)
+	}
+	if rf, ok := ret.Get(0).(func([]string) int64); ok {
+		r0 = rf(ids)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetForUser provides a mock function with given fields: userID
+func (_m *PendingEmailNotificationStore) GetForUser(userID string) ([]*model.PendingEmailNotification, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetForUser")
+	}
+
+	var r0 []*model.PendingEmailNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*model.PendingEmailNotification, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*model.PendingEmailNotification); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PendingEmailNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserIds provides a mock function with given fields: afterUserId, limit
+func (_m *PendingEmailNotificationStore) GetUserIds(afterUserId string, limit int) ([]string, error) {
+	ret := _m.Called(afterUserId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserIds")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]string, error)); ok {
+		return rf(afterUserId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []string); ok {
+		r0 = rf(afterUserId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(afterUserId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Release provides a mock function with given fields: ids
+func (_m *PendingEmailNotificationStore) Release(ids []string) error {
+	ret := _m.Called(ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: notification
+func (_m *PendingEmailNotificationStore) Save(notification *model.PendingEmailNotification) (*model.PendingEmailNotification, error) {
+	ret := _m.Called(notification)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 *model.PendingEmailNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.PendingEmailNotification) (*model.PendingEmailNotification, error)); ok {
+		return rf(notification)
+	}
+	if rf, ok := ret.Get(0).(func(*model.PendingEmailNotification) *model.PendingEmailNotification); ok {
+		r0 = rf(notification)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PendingEmailNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.PendingEmailNotification) error); ok {
+		r1 = rf(notification)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPendingEmailNotificationStore creates a new instance of PendingEmailNotificationStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPendingEmailNotificationStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PendingEmailNotificationStore {
+	mock := &PendingEmailNotificationStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
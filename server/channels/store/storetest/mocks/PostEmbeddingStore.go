@@ -0,0 +1,143 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import (
+	model "github.com/mattermost/mattermost/server/public/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PostEmbeddingStore is an autogenerated mock type for the PostEmbeddingStore type
+type PostEmbeddingStore struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: postIDs
+func (_m *PostEmbeddingStore) Delete(postIDs []string) error {
+	ret := _m.Called(postIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]string) error); ok {
+		r0 = rf(postIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetForPosts provides a mock function with given fields: postIDs, embeddingModel
+func (_m *PostEmbeddingStore) GetForPosts(postIDs []string, embeddingModel string) ([]*model.PostEmbedding, error) {
+	ret := _m.Called(postIDs, embeddingModel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetForPosts")
+	}
+
+	var r0 []*model.PostEmbedding
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string, string) ([]*model.PostEmbedding, error)); ok {
+		return rf(postIDs, embeddingModel)
+	}
+	if rf, ok := ret.Get(0).(func([]string, string) []*model.PostEmbedding); ok {
+		r0 = rf(postIDs, embeddingModel)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PostEmbedding)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string, string) error); ok {
+		r1 = rf(postIDs, embeddingModel)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: embeddings
+func (_m *PostEmbeddingStore) Save(embeddings []*model.PostEmbedding) error {
+	ret := _m.Called(embeddings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*model.PostEmbedding) error); ok {
+		r0 = rf(embeddings)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Search provides a mock function with given fields: userID, teamID, embedding, embeddingModel, includeDeletedChannels, limit
+func (_m *PostEmbeddingStore) Search(userID string, teamID string, embedding []float32, embeddingModel string, includeDeletedChannels bool, limit int) ([]string, error) {
+	ret := _m.Called(userID, teamID, embedding, embeddingModel, includeDeletedChannels, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, []float32, string, bool, int) ([]string, error)); ok {
+		return rf(userID, teamID, embedding, embeddingModel, includeDeletedChannels, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, []float32, string, bool, int) []string); ok {
+		r0 = rf(userID, teamID, embedding, embeddingModel, includeDeletedChannels, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, []float32, string, bool, int) error); ok {
+		r1 = rf(userID, teamID, embedding, embeddingModel, includeDeletedChannels, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetDimensions provides a mock function with given fields: dimensions
+func (_m *PostEmbeddingStore) SetDimensions(dimensions int) error {
+	ret := _m.Called(dimensions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetDimensions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int) error); ok {
+		r0 = rf(dimensions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPostEmbeddingStore creates a new instance of PostEmbeddingStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPostEmbeddingStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PostEmbeddingStore {
+	mock := &PostEmbeddingStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
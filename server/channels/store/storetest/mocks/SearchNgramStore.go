@@ -0,0 +1,83 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import (
+	model "github.com/mattermost/mattermost/server/public/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SearchNgramStore is an autogenerated mock type for the SearchNgramStore type
+type SearchNgramStore struct {
+	mock.Mock
+}
+
+// IndexChannels provides a mock function with given fields: channels
+func (_m *SearchNgramStore) IndexChannels(channels []*model.Channel) error {
+	ret := _m.Called(channels)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexChannels")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*model.Channel) error); ok {
+		r0 = rf(channels)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IndexFiles provides a mock function with given fields: files
+func (_m *SearchNgramStore) IndexFiles(files []*model.FileInfo) error {
+	ret := _m.Called(files)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexFiles")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*model.FileInfo) error); ok {
+		r0 = rf(files)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IndexPosts provides a mock function with given fields: posts
+func (_m *SearchNgramStore) IndexPosts(posts []*model.Post) error {
+	ret := _m.Called(posts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IndexPosts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*model.Post) error); ok {
+		r0 = rf(posts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSearchNgramStore creates a new instance of SearchNgramStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSearchNgramStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SearchNgramStore {
+	mock := &SearchNgramStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -726,6 +726,46 @@ func (_m *Store) OutgoingOAuthConnection() store.OutgoingOAuthConnectionStore {
 	return r0
 }
 
+// OutgoingWebhookDelivery provides a mock function with no fields
+func (_m *Store) OutgoingWebhookDelivery() store.OutgoingWebhookDeliveryStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for OutgoingWebhookDelivery")
+	}
+
+	var r0 store.OutgoingWebhookDeliveryStore
+	if rf, ok := ret.Get(0).(func() store.OutgoingWebhookDeliveryStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.OutgoingWebhookDeliveryStore)
+		}
+	}
+
+	return r0
+}
+
+// PendingEmailNotification provides a mock function with no fields
+func (_m *Store) PendingEmailNotification() store.PendingEmailNotificationStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PendingEmailNotification")
+	}
+
+	var r0 store.PendingEmailNotificationStore
+	if rf, ok := ret.Get(0).(func() store.PendingEmailNotificationStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PendingEmailNotificationStore)
+		}
+	}
+
+	return r0
+}
+
 // Plugin provides a mock function with no fields
 func (_m *Store) Plugin() store.PluginStore {
 	ret := _m.Called()
@@ -746,6 +786,26 @@ func (_m *Store) Plugin() store.PluginStore {
 	return r0
 }
 
+// Poll provides a mock function with no fields
+func (_m *Store) Poll() store.PollStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Poll")
+	}
+
+	var r0 store.PollStore
+	if rf, ok := ret.Get(0).(func() store.PollStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PollStore)
+		}
+	}
+
+	return r0
+}
+
 // Post provides a mock function with no fields
 func (_m *Store) Post() store.PostStore {
 	ret := _m.Called()
@@ -786,6 +846,26 @@ func (_m *Store) PostAcknowledgement() store.PostAcknowledgementStore {
 	return r0
 }
 
+// PostEmbedding provides a mock function with no fields
+func (_m *Store) PostEmbedding() store.PostEmbeddingStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostEmbedding")
+	}
+
+	var r0 store.PostEmbeddingStore
+	if rf, ok := ret.Get(0).(func() store.PostEmbeddingStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PostEmbeddingStore)
+		}
+	}
+
+	return r0
+}
+
 // PostPersistentNotification provides a mock function with no fields
 func (_m *Store) PostPersistentNotification() store.PostPersistentNotificationStore {
 	ret := _m.Called()
@@ -1047,6 +1127,26 @@ func (_m *Store) Role() store.RoleStore {
 	return r0
 }
 
+// SavedSearch provides a mock function with no fields
+func (_m *Store) SavedSearch() store.SavedSearchStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavedSearch")
+	}
+
+	var r0 store.SavedSearchStore
+	if rf, ok := ret.Get(0).(func() store.SavedSearchStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.SavedSearchStore)
+		}
+	}
+
+	return r0
+}
+
 // ScheduledPost provides a mock function with no fields
 func (_m *Store) ScheduledPost() store.ScheduledPostStore {
 	ret := _m.Called()
@@ -1087,6 +1187,26 @@ func (_m *Store) Scheme() store.SchemeStore {
 	return r0
 }
 
+// SearchNgram provides a mock function with no fields
+func (_m *Store) SearchNgram() store.SearchNgramStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchNgram")
+	}
+
+	var r0 store.SearchNgramStore
+	if rf, ok := ret.Get(0).(func() store.SearchNgramStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.SearchNgramStore)
+		}
+	}
+
+	return r0
+}
+
 // Session provides a mock function with no fields
 func (_m *Store) Session() store.SessionStore {
 	ret := _m.Called()
@@ -1386,6 +1506,26 @@ func (_m *Store) UserTermsOfService() store.UserTermsOfServiceStore {
 	return r0
 }
 
+// WebAuthnCredential provides a mock function with no fields
+func (_m *Store) WebAuthnCredential() store.WebAuthnCredentialStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for WebAuthnCredential")
+	}
+
+	var r0 store.WebAuthnCredentialStore
+	if rf, ok := ret.Get(0).(func() store.WebAuthnCredentialStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.WebAuthnCredentialStore)
+		}
+	}
+
+	return r0
+}
+
 // Webhook provides a mock function with no fields
 func (_m *Store) Webhook() store.WebhookStore {
 	ret := _m.Called()
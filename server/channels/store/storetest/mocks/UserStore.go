@@ -2122,6 +2122,24 @@ func (_m *UserStore) UpdateMfaActive(userID string, active bool) error {
 	return r0
 }
 
+// UpdateMfaRecoveryCodes provides a mock function with given fields: userID, hashedCodes
+func (_m *UserStore) UpdateMfaRecoveryCodes(userID string, hashedCodes []string) error {
+	ret := _m.Called(userID, hashedCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateMfaRecoveryCodes")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []string) error); ok {
+		r0 = rf(userID, hashedCodes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateMfaSecret provides a mock function with given fields: userID, secret
 func (_m *UserStore) UpdateMfaSecret(userID string, secret string) error {
 	ret := _m.Called(userID, secret)
@@ -2204,6 +2222,34 @@ func (_m *UserStore) UpdateUpdateAt(userID string) (int64, error) {
 	return r0, r1
 }
 
+// UseMfaRecoveryCode provides a mock function with given fields: userID, hashedCode
+func (_m *UserStore) UseMfaRecoveryCode(userID string, hashedCode string) (bool, error) {
+	ret := _m.Called(userID, hashedCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseMfaRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
+		return rf(userID, hashedCode)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
+		r0 = rf(userID, hashedCode)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, hashedCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyEmail provides a mock function with given fields: userID, email
 func (_m *UserStore) VerifyEmail(userID string, email string) (string, error) {
 	ret := _m.Called(userID, email)
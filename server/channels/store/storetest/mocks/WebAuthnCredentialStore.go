@@ -0,0 +1,203 @@
+// Code generated by mockery v2.53.4. DO NOT EDIT.
+
+// Regenerate this file using `make store-mocks`.
+
+package mocks
+
+import (
+	model "github.com/mattermost/mattermost/server/public/model"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebAuthnCredentialStore is an autogenerated mock type for the WebAuthnCredentialStore type
+type WebAuthnCredentialStore struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *WebAuthnCredentialStore) Delete(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: id
+func (_m *WebAuthnCredentialStore) Get(id string) (*model.WebAuthnCredential, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.WebAuthnCredential, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.WebAuthnCredential); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByCredentialId provides a mock function with given fields: credentialID
+func (_m *WebAuthnCredentialStore) GetByCredentialId(credentialID string) (*model.WebAuthnCredential, error) {
+	ret := _m.Called(credentialID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCredentialId")
+	}
+
+	var r0 *model.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*model.WebAuthnCredential, error)); ok {
+		return rf(credentialID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *model.WebAuthnCredential); ok {
+		r0 = rf(credentialID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(credentialID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetForUser provides a mock function with given fields: userID
+func (_m *WebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetForUser")
+	}
+
+	var r0 []*model.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*model.WebAuthnCredential, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*model.WebAuthnCredential); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PermanentDeleteByUser provides a mock function with given fields: userID
+func (_m *WebAuthnCredentialStore) PermanentDeleteByUser(userID string) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PermanentDeleteByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Save provides a mock function with given fields: credential
+func (_m *WebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	ret := _m.Called(credential)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 *model.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*model.WebAuthnCredential) (*model.WebAuthnCredential, error)); ok {
+		return rf(credential)
+	}
+	if rf, ok := ret.Get(0).(func(*model.WebAuthnCredential) *model.WebAuthnCredential); ok {
+		r0 = rf(credential)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.WebAuthnCredential) error); ok {
+		r1 = rf(credential)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateLastUsed provides a mock function with given fields: id, signCount, lastUsedAt
+func (_m *WebAuthnCredentialStore) UpdateLastUsed(id string, signCount int64, lastUsedAt int64) error {
+	ret := _m.Called(id, signCount, lastUsedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateLastUsed")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64, int64) error); ok {
+		r0 = rf(id, signCount, lastUsedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebAuthnCredentialStore creates a new instance of WebAuthnCredentialStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebAuthnCredentialStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebAuthnCredentialStore {
+	mock := &WebAuthnCredentialStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
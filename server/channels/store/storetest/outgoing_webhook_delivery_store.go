@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestOutgoingWebhookDeliveryStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveAndGet", func(t *testing.T) { testOutgoingWebhookDeliveryStoreSaveAndGet(t, rctx, ss) })
+	t.Run("Update", func(t *testing.T) { testOutgoingWebhookDeliveryStoreUpdate(t, rctx, ss) })
+	t.Run("GetForHook", func(t *testing.T) { testOutgoingWebhookDeliveryStoreGetForHook(t, rctx, ss) })
+	t.Run("GetDue", func(t *testing.T) { testOutgoingWebhookDeliveryStoreGetDue(t, rctx, ss) })
+	t.Run("PermanentDeleteFinishedOlderThan", func(t *testing.T) { testOutgoingWebhookDeliveryStorePermanentDeleteFinishedOlderThan(t, rctx, ss) })
+}
+
+func buildOutgoingWebhookDelivery(hookID string) *model.OutgoingWebhookDelivery {
+	return &model.OutgoingWebhookDelivery{
+		HookId:        hookID,
+		TeamId:        model.NewId(),
+		ChannelId:     model.NewId(),
+		PostId:        model.NewId(),
+		CallbackURL:   "http://nowhere.com/",
+		ContentType:   "application/json",
+		Payload:       `{"text":"hello"}`,
+		Attempts:      1,
+		LastError:     "connection refused",
+		NextAttemptAt: model.GetMillis(),
+	}
+}
+
+func testOutgoingWebhookDeliveryStoreSaveAndGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	delivery, err := ss.OutgoingWebhookDelivery().Save(buildOutgoingWebhookDelivery(model.NewId()))
+	require.NoError(t, err)
+	require.NotEmpty(t, delivery.Id)
+	require.Equal(t, model.OutgoingWebhookDeliveryStatusPending, delivery.Status)
+
+	_, err = ss.OutgoingWebhookDelivery().Save(delivery)
+	require.Error(t, err, "shouldn't be able to update from save")
+
+	invalid := buildOutgoingWebhookDelivery("junk")
+	_, err = ss.OutgoingWebhookDelivery().Save(invalid)
+	require.Error(t, err)
+
+	fetched, err := ss.OutgoingWebhookDelivery().Get(delivery.Id)
+	require.NoError(t, err)
+	assert.Equal(t, delivery, fetched)
+
+	_, err = ss.OutgoingWebhookDelivery().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	require.True(t, errors.As(err, &nfErr))
+}
+
+func testOutgoingWebhookDeliveryStoreUpdate(t *testing.T, rctx request.CTX, ss store.Store) {
+	delivery, err := ss.OutgoingWebhookDelivery().Save(buildOutgoingWebhookDelivery(model.NewId()))
+	require.NoError(t, err)
+
+	delivery.RecordFailure(errors.New("timeout"), time.Now())
+	_, err = ss.OutgoingWebhookDelivery().Update(delivery)
+	require.NoError(t, err)
+
+	fetched, err := ss.OutgoingWebhookDelivery().Get(delivery.Id)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched.Attempts)
+	assert.Equal(t, "timeout", fetched.LastError)
+	assert.Equal(t, delivery.NextAttemptAt, fetched.NextAttemptAt)
+
+	fetched.Status = "invalid"
+	_, err = ss.OutgoingWebhookDelivery().Update(fetched)
+	require.Error(t, err)
+}
+
+func testOutgoingWebhookDeliveryStoreGetForHook(t *testing.T, rctx request.CTX, ss store.Store) {
+	hookID := model.NewId()
+
+	d1, err := ss.OutgoingWebhookDelivery().Save(buildOutgoingWebhookDelivery(hookID))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	d2 := buildOutgoingWebhookDelivery(hookID)
+	d2.Status = model.OutgoingWebhookDeliveryStatusDead
+	d2, err = ss.OutgoingWebhookDelivery().Save(d2)
+	require.NoError(t, err)
+
+	_, err = ss.OutgoingWebhookDelivery().Save(buildOutgoingWebhookDelivery(model.NewId()))
+	require.NoError(t, err)
+
+	t.Run("all statuses", func(t *testing.T) {
+		deliveries, err := ss.OutgoingWebhookDelivery().GetForHook(hookID, "", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, d2.Id, deliveries[0].Id)
+		assert.Equal(t, d1.Id, deliveries[1].Id)
+	})
+
+	t.Run("filtered by status", func(t *testing.T) {
+		deliveries, err := ss.OutgoingWebhookDelivery().GetForHook(hookID, model.OutgoingWebhookDeliveryStatusDead, 0, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, d2.Id, deliveries[0].Id)
+	})
+
+	t.Run("paginated", func(t *testing.T) {
+		deliveries, err := ss.OutgoingWebhookDelivery().GetForHook(hookID, "", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, d1.Id, deliveries[0].Id)
+	})
+}
+
+func testOutgoingWebhookDeliveryStoreGetDue(t *testing.T, rctx request.CTX, ss store.Store) {
+	now := model.GetMillis()
+
+	due := buildOutgoingWebhookDelivery(model.NewId())
+	due.NextAttemptAt = now - 1000
+	due, err := ss.OutgoingWebhookDelivery().Save(due)
+	require.NoError(t, err)
+
+	notYet := buildOutgoingWebhookDelivery(model.NewId())
+	notYet.NextAttemptAt = now + 60000
+	notYet, err = ss.OutgoingWebhookDelivery().Save(notYet)
+	require.NoError(t, err)
+
+	dead := buildOutgoingWebhookDelivery(model.NewId())
+	dead.Status = model.OutgoingWebhookDeliveryStatusDead
+	dead.NextAttemptAt = now - 1000
+	dead, err = ss.OutgoingWebhookDelivery().Save(dead)
+	require.NoError(t, err)
+
+	deliveries, err := ss.OutgoingWebhookDelivery().GetDue(now, 1000)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.Id)
+	}
+	assert.Contains(t, ids, due.Id)
+	assert.NotContains(t, ids, notYet.Id)
+	assert.NotContains(t, ids, dead.Id)
+}
+
+func testOutgoingWebhookDeliveryStorePermanentDeleteFinishedOlderThan(t *testing.T, rctx request.CTX, ss store.Store) {
+	pending, err := ss.OutgoingWebhookDelivery().Save(buildOutgoingWebhookDelivery(model.NewId()))
+	require.NoError(t, err)
+
+	delivered := buildOutgoingWebhookDelivery(model.NewId())
+	delivered.Status = model.OutgoingWebhookDeliveryStatusDelivered
+	delivered, err = ss.OutgoingWebhookDelivery().Save(delivered)
+	require.NoError(t, err)
+
+	dead := buildOutgoingWebhookDelivery(model.NewId())
+	dead.Status = model.OutgoingWebhookDeliveryStatusDead
+	dead, err = ss.OutgoingWebhookDelivery().Save(dead)
+	require.NoError(t, err)
+
+	deleted, err := ss.OutgoingWebhookDelivery().PermanentDeleteFinishedOlderThan(model.GetMillis()+1, 1000)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(2))
+
+	_, err = ss.OutgoingWebhookDelivery().Get(pending.Id)
+	require.NoError(t, err)
+
+	_, err = ss.OutgoingWebhookDelivery().Get(delivered.Id)
+	require.Error(t, err)
+
+	_, err = ss.OutgoingWebhookDelivery().Get(dead.Id)
+	require.Error(t, err)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestPendingEmailNotificationStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("Save", func(t *testing.T) { testPendingEmailNotificationStoreSave(t, rctx, ss) })
+	t.Run("GetUserIds", func(t *testing.T) { testPendingEmailNotificationStoreGetUserIds(t, rctx, ss) })
+	t.Run("GetForUserAndDelete", func(t *testing.T) { testPendingEmailNotificationStoreGetForUserAndDelete(t, rctx, ss) })
+	t.Run("ClaimAndRelease", func(t *testing.T) { testPendingEmailNotificationStoreClaimAndRelease(t, rctx, ss) })
+}
+
+func savePendingEmailNotification(t *testing.T, ss store.Store, userID string, createAt int64) *model.PendingEmailNotification {
+	t.Helper()
+
+	notification, err := ss.PendingEmailNotification().Save(&model.PendingEmailNotification{
+		UserId:   userID,
+		PostId:   model.NewId(),
+		TeamName: "team",
+		CreateAt: createAt,
+	})
+	require.NoError(t, err)
+	return notification
+}
+
+func testPendingEmailNotificationStoreSave(t *testing.T, rctx request.CTX, ss store.Store) {
+	notification := savePendingEmailNotification(t, ss, model.NewId(), 0)
+	require.NotEmpty(t, notification.Id)
+	require.NotZero(t, notification.CreateAt)
+
+	_, err := ss.PendingEmailNotification().Save(notification)
+	require.Error(t, err, "shouldn't be able to save twice")
+
+	_, err = ss.PendingEmailNotification().Save(&model.PendingEmailNotification{UserId: "junk", PostId: model.NewId(), TeamName: "team"})
+	require.Error(t, err)
+}
+
+func testPendingEmailNotificationStoreGetUserIds(t *testing.T, rctx request.CTX, ss store.Store) {
+	userIds := []string{model.NewId(), model.NewId(), model.NewId()}
+	for _, userID := range userIds {
+		savePendingEmailNotification(t, ss, userID, 0)
+		savePendingEmailNotification(t, ss, userID, 0)
+	}
+	sort.Strings(userIds)
+
+	var all []string
+	afterUserId := ""
+	for {
+		page, err := ss.PendingEmailNotification().GetUserIds(afterUserId, 2)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		require.LessOrEqual(t, len(page), 2)
+		all = append(all, page...)
+		afterUserId = page[len(page)-1]
+	}
+
+	require.True(t, sort.StringsAreSorted(all))
+	for _, userID := range userIds {
+		assert.Contains(t, all, userID)
+	}
+
+	seen := map[string]bool{}
+	for _, userID := range all {
+		require.False(t, seen[userID], "user ids should be distinct")
+		seen[userID] = true
+	}
+}
+
+func testPendingEmailNotificationStoreGetForUserAndDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	second := savePendingEmailNotification(t, ss, userID, 2000)
+	first := savePendingEmailNotification(t, ss, userID, 1000)
+	other := savePendingEmailNotification(t, ss, model.NewId(), 1000)
+
+	notifications, err := ss.PendingEmailNotification().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, first, notifications[0])
+	assert.Equal(t, second, notifications[1])
+
+	deleted, err := ss.PendingEmailNotification().Delete([]string{first.Id, second.Id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	deleted, err = ss.PendingEmailNotification().Delete([]string{first.Id, second.Id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted, "notifications can only be deleted once")
+
+	notifications, err = ss.PendingEmailNotification().GetForUser(userID)
+	require.NoError(t, err)
+	assert.Empty(t, notifications)
+
+	notifications, err = ss.PendingEmailNotification().GetForUser(other.UserId)
+	require.NoError(t, err)
+	assert.Len(t, notifications, 1)
+
+	deleted, err = ss.PendingEmailNotification().Delete(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+}
+
+func testPendingEmailNotificationStoreClaimAndRelease(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	first := savePendingEmailNotification(t, ss, userID, 1000)
+	second := savePendingEmailNotification(t, ss, userID, 2000)
+	ids := []string{first.Id, second.Id}
+
+	claimed, err := ss.PendingEmailNotification().Claim(ids, 5000, 0)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	notifications, err := ss.PendingEmailNotification().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, int64(5000), notifications[0].ClaimedAt)
+
+	claimed, err = ss.PendingEmailNotification().Claim(ids, 6000, 4000)
+	require.NoError(t, err)
+	assert.False(t, claimed, "notifications can't be claimed while their claim holds")
+
+	claimed, err = ss.PendingEmailNotification().Claim([]string{second.Id, model.NewId()}, 6000, 5000)
+	require.NoError(t, err)
+	assert.False(t, claimed, "notifications are only claimed together")
+
+	notifications, err = ss.PendingEmailNotification().GetForUser(userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5000), notifications[1].ClaimedAt, "a failed claim shouldn't change any notification")
+
+	claimed, err = ss.PendingEmailNotification().Claim(ids, 6000, 5000)
+	require.NoError(t, err)
+	assert.True(t, claimed, "notifications can be claimed again once their claim expired")
+
+	require.NoError(t, ss.PendingEmailNotification().Release(ids))
+
+	notifications, err = ss.PendingEmailNotification().GetForUser(userID)
+	require.NoError(t, err)
+	assert.Zero(t, notifications[0].ClaimedAt)
+	assert.Zero(t, notifications[1].ClaimedAt)
+
+	require.NoError(t, ss.PendingEmailNotification().Release(nil))
+}
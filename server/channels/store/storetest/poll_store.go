@@ -0,0 +1,142 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestPollStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveAndGet", func(t *testing.T) { testPollStoreSaveAndGet(t, rctx, ss) })
+	t.Run("Close", func(t *testing.T) { testPollStoreClose(t, rctx, ss) })
+	t.Run("Votes", func(t *testing.T) { testPollStoreVotes(t, rctx, ss) })
+}
+
+func savePoll(t *testing.T, rctx request.CTX, ss store.Store) *model.Poll {
+	t.Helper()
+
+	post, err := ss.Post().Save(rctx, &model.Post{
+		ChannelId: model.NewId(),
+		UserId:    model.NewId(),
+		Type:      model.PostTypePoll,
+		Message:   "Lunch?",
+	})
+	require.NoError(t, err)
+
+	poll := (&model.PollRequest{Question: "Lunch?", Options: []string{"Pizza", "Sushi", "Salad"}, MultipleChoice: true}).ToPoll()
+	poll.PostId = post.Id
+	poll, err = ss.Poll().Save(poll)
+	require.NoError(t, err)
+	return poll
+}
+
+func testPollStoreSaveAndGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	poll := savePoll(t, rctx, ss)
+
+	_, err := ss.Poll().Save(poll)
+	require.Error(t, err, "shouldn't be able to save twice")
+
+	_, err = ss.Poll().Save(&model.Poll{PostId: model.NewId(), Question: "Lunch?"})
+	require.Error(t, err)
+
+	got, err := ss.Poll().Get(poll.PostId)
+	require.NoError(t, err)
+	assert.Equal(t, poll.Question, got.Question)
+	assert.True(t, got.MultipleChoice)
+	assert.False(t, got.Anonymous)
+	assert.Zero(t, got.ClosedAt)
+	require.Len(t, got.Options, 3)
+	assert.Equal(t, poll.Options[0].Id, got.Options[0].Id)
+	assert.Equal(t, "Sushi", got.Options[1].Text)
+
+	_, err = ss.Poll().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+}
+
+func testPollStoreClose(t *testing.T, rctx request.CTX, ss store.Store) {
+	poll := savePoll(t, rctx, ss)
+
+	closedAt := model.GetMillis()
+	require.NoError(t, ss.Poll().Close(poll.PostId, closedAt))
+
+	got, err := ss.Poll().Get(poll.PostId)
+	require.NoError(t, err)
+	assert.Equal(t, closedAt, got.ClosedAt)
+	assert.True(t, got.IsClosed())
+
+	post, err := ss.Post().GetSingle(rctx, poll.PostId, false)
+	require.NoError(t, err)
+	assert.Equal(t, closedAt, post.UpdateAt)
+
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, ss.Poll().Close(poll.PostId, closedAt+1), &nfErr, "shouldn't be able to close twice")
+}
+
+func testPollStoreVotes(t *testing.T, rctx request.CTX, ss store.Store) {
+	poll := savePoll(t, rctx, ss)
+	pizza, sushi, salad := poll.Options[0].Id, poll.Options[1].Id, poll.Options[2].Id
+	user1, user2 := model.NewId(), model.NewId()
+
+	require.NoError(t, ss.Poll().SaveVotes(poll.PostId, user1, []string{pizza, sushi}))
+	require.NoError(t, ss.Poll().SaveVotes(poll.PostId, user2, []string{pizza}))
+
+	votes, err := ss.Poll().GetVotes(poll.PostId)
+	require.NoError(t, err)
+	require.Len(t, votes, 3)
+
+	// Voting again replaces the votes of the user.
+	require.NoError(t, ss.Poll().SaveVotes(poll.PostId, user1, []string{salad}))
+
+	votes, err = ss.Poll().GetVotes(poll.PostId)
+	require.NoError(t, err)
+	require.Len(t, votes, 2)
+	poll.SetTally(votes, user1)
+	assert.Equal(t, []string{salad}, poll.MyVotes)
+	assert.Equal(t, int64(1), poll.Options[0].VoteCount)
+	assert.Zero(t, poll.Options[1].VoteCount)
+
+	post, err := ss.Post().GetSingle(rctx, poll.PostId, false)
+	require.NoError(t, err)
+	assert.Greater(t, post.UpdateAt, post.CreateAt)
+
+	require.NoError(t, ss.Poll().DeleteVotes(poll.PostId, user1))
+
+	votes, err = ss.Poll().GetVotes(poll.PostId)
+	require.NoError(t, err)
+	require.Len(t, votes, 1)
+	assert.Equal(t, user2, votes[0].UserId)
+
+	votes, err = ss.Poll().GetVotes(model.NewId())
+	require.NoError(t, err)
+	assert.Empty(t, votes)
+
+	// The votes of a closed poll aren't changed.
+	require.NoError(t, ss.Poll().Close(poll.PostId, model.GetMillis()))
+
+	var nfErr *store.ErrNotFound
+	err = ss.Poll().SaveVotes(poll.PostId, user1, []string{pizza})
+	require.ErrorAs(t, err, &nfErr)
+	err = ss.Poll().DeleteVotes(poll.PostId, user2)
+	require.ErrorAs(t, err, &nfErr)
+
+	votes, err = ss.Poll().GetVotes(poll.PostId)
+	require.NoError(t, err)
+	require.Len(t, votes, 1)
+	assert.Equal(t, user2, votes[0].UserId)
+
+	// Imported votes are saved even though the poll is closed.
+	require.NoError(t, ss.Poll().ImportVotes(poll.PostId, user1, []string{pizza, salad}))
+
+	votes, err = ss.Poll().GetVotes(poll.PostId)
+	require.NoError(t, err)
+	require.Len(t, votes, 3)
+}
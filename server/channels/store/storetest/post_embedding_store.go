@@ -0,0 +1,203 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestPostEmbeddingStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
+	// The embeddings table only exists when the vector extension is available.
+	var exists bool
+	require.NoError(t, s.GetMaster().Get(&exists, "SELECT to_regclass('postembeddings') IS NOT NULL"))
+	if !exists {
+		t.Skip("the vector extension isn't available")
+	}
+
+	// The embeddings of the following tests are typed with their dimensions.
+	t.Run("SetDimensions", func(t *testing.T) { testPostEmbeddingStoreSetDimensions(t, rctx, ss, s) })
+	t.Run("SaveAndGetForPosts", func(t *testing.T) { testPostEmbeddingStoreSaveAndGetForPosts(t, rctx, ss) })
+	t.Run("Delete", func(t *testing.T) { testPostEmbeddingStoreDelete(t, rctx, ss) })
+	t.Run("Search", func(t *testing.T) { testPostEmbeddingStoreSearch(t, rctx, ss) })
+}
+
+func testPostEmbeddingStoreSetDimensions(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
+	postID, otherPostID := model.NewId(), model.NewId()
+	require.NoError(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: postID, ChannelId: model.NewId(), Model: "model", Embedding: []float32{1, 0}},
+		{PostId: otherPostID, ChannelId: model.NewId(), Model: "other-model", Embedding: []float32{1, 0, 0}},
+	}))
+
+	require.NoError(t, ss.PostEmbedding().SetDimensions(2))
+	// Setting the same dimensions again doesn't change anything.
+	require.NoError(t, ss.PostEmbedding().SetDimensions(2))
+
+	var columnType string
+	require.NoError(t, s.GetMaster().Get(&columnType, "SELECT format_type(atttypid, atttypmod) FROM pg_attribute WHERE attrelid = 'postembeddings'::regclass AND attname = 'embedding'"))
+	assert.Equal(t, "vector(2)", columnType)
+
+	var indexed bool
+	require.NoError(t, s.GetMaster().Get(&indexed, "SELECT to_regclass('idx_postembeddings_embedding') IS NOT NULL"))
+	assert.True(t, indexed)
+
+	// The embeddings of other dimensions are deleted.
+	embeddings, err := ss.PostEmbedding().GetForPosts([]string{postID}, "model")
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 1)
+	embeddings, err = ss.PostEmbedding().GetForPosts([]string{otherPostID}, "other-model")
+	require.NoError(t, err)
+	assert.Empty(t, embeddings)
+
+	require.Error(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: otherPostID, ChannelId: model.NewId(), Model: "other-model", Embedding: []float32{1, 0, 0}},
+	}))
+}
+
+func testPostEmbeddingStoreSaveAndGetForPosts(t *testing.T, rctx request.CTX, ss store.Store) {
+	postID, otherPostID, channelID := model.NewId(), model.NewId(), model.NewId()
+	require.NoError(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: postID, ChannelId: channelID, Model: "model", Embedding: []float32{1, 0}},
+		{PostId: otherPostID, ChannelId: channelID, Model: "other-model", Embedding: []float32{0, 1}},
+	}))
+
+	embeddings, err := ss.PostEmbedding().GetForPosts([]string{postID, otherPostID, model.NewId()}, "model")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.PostEmbedding{{PostId: postID, ChannelId: channelID, Model: "model"}}, embeddings)
+
+	// Saving again replaces the embedding.
+	require.NoError(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: otherPostID, ChannelId: channelID, Model: "model", PostEditAt: 1234, Embedding: []float32{0.5, 0.5}},
+	}))
+
+	embeddings, err = ss.PostEmbedding().GetForPosts([]string{otherPostID}, "model")
+	require.NoError(t, err)
+	require.Len(t, embeddings, 1)
+	assert.Equal(t, int64(1234), embeddings[0].PostEditAt)
+}
+
+func testPostEmbeddingStoreDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	postID := model.NewId()
+	require.NoError(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: postID, ChannelId: model.NewId(), Model: "model", Embedding: []float32{1, 0}},
+	}))
+
+	require.NoError(t, ss.PostEmbedding().Delete([]string{postID}))
+
+	embeddings, err := ss.PostEmbedding().GetForPosts([]string{postID}, "model")
+	require.NoError(t, err)
+	assert.Empty(t, embeddings)
+}
+
+func testPostEmbeddingStoreSearch(t *testing.T, rctx request.CTX, ss store.Store) {
+	teamID, userID := model.NewId(), model.NewId()
+
+	saveChannel := func(teamID string, member bool) *model.Channel {
+		t.Helper()
+		channel, err := ss.Channel().Save(rctx, &model.Channel{
+			TeamId:      teamID,
+			DisplayName: "Embeddings",
+			Name:        NewTestID(),
+			Type:        model.ChannelTypeOpen,
+		}, -1)
+		require.NoError(t, err)
+
+		if member {
+			_, err = ss.Channel().SaveMember(rctx, &model.ChannelMember{
+				ChannelId:   channel.Id,
+				UserId:      userID,
+				NotifyProps: model.GetDefaultChannelNotifyProps(),
+			})
+			require.NoError(t, err)
+		}
+		return channel
+	}
+
+	channel := saveChannel(teamID, true)
+	otherTeamChannel := saveChannel(model.NewId(), true)
+	notMemberChannel := saveChannel(teamID, false)
+	deletedChannel := saveChannel(teamID, true)
+	require.NoError(t, ss.Channel().Delete(deletedChannel.Id, model.GetMillis()))
+
+	savePost := func(channel *model.Channel) string {
+		t.Helper()
+		post, err := ss.Post().Save(rctx, &model.Post{
+			ChannelId: channel.Id,
+			UserId:    model.NewId(),
+			Message:   "embedded",
+		})
+		require.NoError(t, err)
+		return post.Id
+	}
+
+	closest, near, far := savePost(channel), savePost(channel), savePost(channel)
+	otherTeam, notMember, deleted := savePost(otherTeamChannel), savePost(notMemberChannel), savePost(deletedChannel)
+	deletedPost, movedPost, otherModel := savePost(channel), savePost(notMemberChannel), savePost(channel)
+	require.NoError(t, ss.Post().Delete(rctx, deletedPost, model.GetMillis(), userID))
+
+	require.NoError(t, ss.PostEmbedding().Save([]*model.PostEmbedding{
+		{PostId: far, ChannelId: channel.Id, Model: "model", Embedding: []float32{0, 1}},
+		{PostId: closest, ChannelId: channel.Id, Model: "model", Embedding: []float32{1, 0.1}},
+		{PostId: near, ChannelId: channel.Id, Model: "model", Embedding: []float32{1, 0.5}},
+		{PostId: otherTeam, ChannelId: otherTeamChannel.Id, Model: "model", Embedding: []float32{1, 0}},
+		{PostId: notMember, ChannelId: notMemberChannel.Id, Model: "model", Embedding: []float32{1, 0}},
+		{PostId: deleted, ChannelId: deletedChannel.Id, Model: "model", Embedding: []float32{1, 0}},
+		{PostId: deletedPost, ChannelId: channel.Id, Model: "model", Embedding: []float32{1, 0}},
+		// The post was moved out of the channel since it was embedded.
+		{PostId: movedPost, ChannelId: channel.Id, Model: "model", Embedding: []float32{1, 0}},
+		{PostId: otherModel, ChannelId: channel.Id, Model: "other-model", Embedding: []float32{1, 0}},
+	}))
+
+	t.Run("most similar first", func(t *testing.T) {
+		postIDs, err := ss.PostEmbedding().Search(userID, teamID, []float32{1, 0}, "model", false, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{closest, near, far}, postIDs)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		postIDs, err := ss.PostEmbedding().Search(userID, teamID, []float32{1, 0}, "model", false, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{closest, near}, postIDs)
+	})
+
+	t.Run("all teams", func(t *testing.T) {
+		postIDs, err := ss.PostEmbedding().Search(userID, "", []float32{1, 0}, "model", false, 10)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{closest, near, far, otherTeam}, postIDs)
+	})
+
+	t.Run("deleted channels", func(t *testing.T) {
+		postIDs, err := ss.PostEmbedding().Search(userID, teamID, []float32{1, 0}, "model", true, 10)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{closest, near, far, deleted}, postIDs)
+	})
+
+	t.Run("member of a small part of the channels", func(t *testing.T) {
+		// The embeddings of the channels the user isn't a member of are all closer to the searched
+		// one, and there are more of them than the HNSW index returns by default.
+		var embeddings []*model.PostEmbedding
+		for range 100 {
+			embeddings = append(embeddings, &model.PostEmbedding{PostId: savePost(notMemberChannel), ChannelId: notMemberChannel.Id, Model: "far-model", Embedding: []float32{1, 0}})
+		}
+		farthest := savePost(channel)
+		embeddings = append(embeddings, &model.PostEmbedding{PostId: farthest, ChannelId: channel.Id, Model: "far-model", Embedding: []float32{-1, 0.1}})
+		require.NoError(t, ss.PostEmbedding().Save(embeddings))
+
+		postIDs, err := ss.PostEmbedding().Search(userID, teamID, []float32{1, 0}, "far-model", false, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{farthest}, postIDs)
+	})
+
+	t.Run("other model", func(t *testing.T) {
+		postIDs, err := ss.PostEmbedding().Search(userID, teamID, []float32{1, 0}, "other-model", false, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{otherModel}, postIDs)
+	})
+}
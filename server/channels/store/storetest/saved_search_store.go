@@ -0,0 +1,161 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestSavedSearchStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveAndGet", func(t *testing.T) { testSavedSearchStoreSaveAndGet(t, rctx, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testSavedSearchStoreGetForUser(t, rctx, ss) })
+	t.Run("GetWatched", func(t *testing.T) { testSavedSearchStoreGetWatched(t, rctx, ss) })
+	t.Run("Update", func(t *testing.T) { testSavedSearchStoreUpdate(t, rctx, ss) })
+	t.Run("Delete", func(t *testing.T) { testSavedSearchStoreDelete(t, rctx, ss) })
+}
+
+func saveSavedSearch(t *testing.T, ss store.Store, userID string, watch bool) *model.SavedSearch {
+	t.Helper()
+
+	savedSearch, err := ss.SavedSearch().Save(&model.SavedSearch{
+		UserId: userID,
+		Name:   "Outages",
+		Terms:  "outage is:pinned #prod",
+		Watch:  watch,
+	})
+	require.NoError(t, err)
+	return savedSearch
+}
+
+func testSavedSearchStoreSaveAndGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	savedSearch := saveSavedSearch(t, ss, model.NewId(), true)
+	require.NotEmpty(t, savedSearch.Id)
+
+	_, err := ss.SavedSearch().Save(savedSearch)
+	require.Error(t, err, "shouldn't be able to save twice")
+
+	_, err = ss.SavedSearch().Save(&model.SavedSearch{UserId: model.NewId(), Name: "Empty", Terms: " "})
+	require.Error(t, err)
+
+	got, err := ss.SavedSearch().Get(savedSearch.Id)
+	require.NoError(t, err)
+	assert.Equal(t, savedSearch.UserId, got.UserId)
+	assert.Equal(t, savedSearch.Name, got.Name)
+	assert.Equal(t, savedSearch.Terms, got.Terms)
+	assert.True(t, got.Watch)
+	require.Len(t, got.Params, 2)
+	assert.Equal(t, "#prod", got.Params[1].Terms)
+	assert.Equal(t, model.NewPointer(true), got.Params[0].IsPinned)
+
+	_, err = ss.SavedSearch().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+}
+
+func testSavedSearchStoreGetForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	first := saveSavedSearch(t, ss, userID, false)
+	second := saveSavedSearch(t, ss, userID, true)
+	saveSavedSearch(t, ss, model.NewId(), false)
+
+	savedSearches, err := ss.SavedSearch().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, savedSearches, 2)
+	assert.ElementsMatch(t, []string{first.Id, second.Id}, []string{savedSearches[0].Id, savedSearches[1].Id})
+
+	count, err := ss.SavedSearch().CountForUser(userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	savedSearches, err = ss.SavedSearch().GetForUser(model.NewId())
+	require.NoError(t, err)
+	assert.Empty(t, savedSearches)
+}
+
+func testSavedSearchStoreGetWatched(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	var watched []string
+	for range 3 {
+		watched = append(watched, saveSavedSearch(t, ss, userID, true).Id)
+	}
+	notWatched := saveSavedSearch(t, ss, userID, false)
+	sort.Strings(watched)
+
+	var all []string
+	afterId := ""
+	for {
+		page, err := ss.SavedSearch().GetWatched(afterId, 2)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, savedSearch := range page {
+			assert.True(t, savedSearch.Watch)
+			all = append(all, savedSearch.Id)
+		}
+		afterId = page[len(page)-1].Id
+	}
+
+	for _, id := range watched {
+		assert.Contains(t, all, id)
+	}
+	assert.NotContains(t, all, notWatched.Id)
+	assert.True(t, sort.StringsAreSorted(all))
+}
+
+func testSavedSearchStoreUpdate(t *testing.T, rctx request.CTX, ss store.Store) {
+	savedSearch := saveSavedSearch(t, ss, model.NewId(), false)
+
+	savedSearch.Patch(&model.SavedSearchPatch{
+		Name:  model.NewPointer("Customer"),
+		Terms: model.NewPointer("acme from:bot"),
+		Watch: model.NewPointer(true),
+	})
+	updated, err := ss.SavedSearch().Update(savedSearch)
+	require.NoError(t, err)
+	require.Len(t, updated.Params, 1)
+	assert.Equal(t, "acme", updated.Params[0].Terms)
+
+	got, err := ss.SavedSearch().Get(savedSearch.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "Customer", got.Name)
+	assert.True(t, got.Watch)
+	assert.Equal(t, model.NewPointer(true), got.Params[0].FromBots)
+
+	updated.Name = ""
+	_, err = ss.SavedSearch().Update(updated)
+	require.Error(t, err)
+
+	missing := &model.SavedSearch{Id: model.NewId(), UserId: model.NewId(), Name: "Missing", Terms: "missing", CreateAt: 1}
+	_, err = ss.SavedSearch().Update(missing)
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+}
+
+func testSavedSearchStoreDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	savedSearch := saveSavedSearch(t, ss, userID, false)
+	saveSavedSearch(t, ss, userID, true)
+	other := saveSavedSearch(t, ss, model.NewId(), true)
+
+	require.NoError(t, ss.SavedSearch().Delete(savedSearch.Id))
+	_, err := ss.SavedSearch().Get(savedSearch.Id)
+	require.Error(t, err)
+
+	require.NoError(t, ss.SavedSearch().PermanentDeleteByUser(userID))
+	savedSearches, err := ss.SavedSearch().GetForUser(userID)
+	require.NoError(t, err)
+	assert.Empty(t, savedSearches)
+
+	_, err = ss.SavedSearch().Get(other.Id)
+	require.NoError(t, err)
+}
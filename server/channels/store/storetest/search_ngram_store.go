@@ -0,0 +1,224 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"strings"
+	"testing"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+// TestSearchNgramStore runs against a store whose database search is in the n-gram mode.
+func TestSearchNgramStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "Ngram team",
+		Name:        NewTestID(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+
+	user, err := ss.User().Save(rctx, &model.User{Username: model.NewUsername(), Email: MakeEmail()})
+	require.NoError(t, err)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Quarterly budget review",
+		Name:        NewTestID(),
+		Purpose:     "東京 office planning",
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+
+	_, err = ss.Channel().SaveMember(rctx, &model.ChannelMember{
+		ChannelId:   channel.Id,
+		UserId:      user.Id,
+		NotifyProps: model.GetDefaultChannelNotifyProps(),
+	})
+	require.NoError(t, err)
+
+	t.Run("SearchPosts", func(t *testing.T) { testSearchNgramStoreSearchPosts(t, rctx, ss, s, team, user, channel) })
+	t.Run("SearchFiles", func(t *testing.T) { testSearchNgramStoreSearchFiles(t, rctx, ss, s, team, user, channel) })
+	t.Run("SearchChannels", func(t *testing.T) { testSearchNgramStoreSearchChannels(t, rctx, ss, s, team) })
+}
+
+func searchNgramsCount(t *testing.T, s SqlStore, objectType string, ids ...string) int {
+	t.Helper()
+
+	query, args, err := sq.Select("COUNT(*)").
+		From("SearchNgrams").
+		Where(sq.Eq{"ObjectType": objectType, "ObjectId": ids}).
+		PlaceholderFormat(s.GetQueryPlaceholder()).
+		ToSql()
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, s.GetMaster().Get(&count, query, args...))
+	return count
+}
+
+func testSearchNgramStoreSearchPosts(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore, team *model.Team, user *model.User, channel *model.Channel) {
+	longWord := strings.Repeat("a", 3000)
+
+	root, err := ss.Post().Save(rctx, &model.Post{
+		ChannelId: channel.Id,
+		UserId:    user.Id,
+		Message:   "Deploying the release to 東京都 tonight",
+		CreateAt:  1000,
+	})
+	require.NoError(t, err)
+
+	reply, err := ss.Post().Save(rctx, &model.Post{
+		ChannelId: channel.Id,
+		UserId:    user.Id,
+		RootId:    root.Id,
+		Message:   "the release notes are ready",
+		CreateAt:  2000,
+	})
+	require.NoError(t, err)
+
+	// Words too long to be indexed don't prevent the post from being saved.
+	long, err := ss.Post().Save(rctx, &model.Post{
+		ChannelId: channel.Id,
+		UserId:    user.Id,
+		Message:   "rollback " + longWord,
+		CreateAt:  3000,
+	})
+	require.NoError(t, err)
+
+	search := func(terms string) []string {
+		t.Helper()
+
+		results, err := ss.Post().SearchPostsForUser(rctx, []*model.SearchParams{{Terms: terms}}, user.Id, team.Id, 0, 20)
+		require.NoError(t, err)
+		return results.Order
+	}
+
+	t.Run("words", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{root.Id, reply.Id}, search("release"))
+		assert.ElementsMatch(t, []string{root.Id}, search("deploying release"))
+		assert.Empty(t, search("deploy"))
+	})
+
+	t.Run("prefixes", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{root.Id}, search("deplo*"))
+	})
+
+	t.Run("chinese, japanese and korean", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{root.Id}, search("東京"))
+		assert.Empty(t, search("京東"))
+	})
+
+	t.Run("words too long to be indexed", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{long.Id}, search("rollback"))
+		assert.ElementsMatch(t, []string{long.Id}, search(longWord))
+	})
+
+	t.Run("permanent delete", func(t *testing.T) {
+		require.Equal(t, 2, searchNgramsCount(t, s, "post", root.Id, reply.Id))
+
+		require.NoError(t, ss.Post().PermanentDelete(rctx, root.Id))
+		assert.Empty(t, search("release"))
+		assert.Zero(t, searchNgramsCount(t, s, "post", root.Id, reply.Id))
+	})
+
+	t.Run("permanent delete batch", func(t *testing.T) {
+		require.Equal(t, 1, searchNgramsCount(t, s, "post", long.Id))
+
+		_, err := ss.Post().PermanentDeleteBatch(4000, 1000)
+		require.NoError(t, err)
+		assert.Zero(t, searchNgramsCount(t, s, "post", long.Id))
+	})
+
+	t.Run("data retention", func(t *testing.T) {
+		post, err := ss.Post().Save(rctx, &model.Post{
+			ChannelId: channel.Id,
+			UserId:    user.Id,
+			Message:   "expired message",
+			CreateAt:  5000,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, searchNgramsCount(t, s, "post", post.Id))
+
+		_, _, err = ss.Post().PermanentDeleteBatchForRetentionPolicies(model.RetentionPolicyBatchConfigs{
+			GlobalPolicyEndTime: 6000,
+			Limit:               1000,
+		}, model.RetentionPolicyCursor{})
+		require.NoError(t, err)
+		assert.Empty(t, search("expired"))
+		assert.Zero(t, searchNgramsCount(t, s, "post", post.Id))
+	})
+}
+
+func testSearchNgramStoreSearchFiles(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore, team *model.Team, user *model.User, channel *model.Channel) {
+	longWord := strings.Repeat("b", 3000)
+
+	// Words too long to be indexed don't prevent the file from being saved.
+	info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		ChannelId: channel.Id,
+		PostId:    model.NewId(),
+		CreatorId: user.Id,
+		Path:      "budget.pdf",
+		Name:      "budget.pdf",
+		Content:   "Quarterly forecast for the 会議室 " + longWord,
+	})
+	require.NoError(t, err)
+
+	search := func(terms string) []string {
+		t.Helper()
+
+		results, err := ss.FileInfo().Search(rctx, []*model.SearchParams{{Terms: terms}}, user.Id, team.Id, 0, 20)
+		require.NoError(t, err)
+		return results.Order
+	}
+
+	assert.Equal(t, []string{info.Id}, search("forecast"))
+	assert.Equal(t, []string{info.Id}, search("budget"))
+	assert.Equal(t, []string{info.Id}, search("会議"))
+	assert.Equal(t, []string{info.Id}, search(longWord))
+	assert.Empty(t, search("forecasts"))
+
+	require.NoError(t, ss.FileInfo().PermanentDelete(rctx, info.Id))
+	assert.Empty(t, search("forecast"))
+	assert.Zero(t, searchNgramsCount(t, s, "file", info.Id))
+}
+
+func testSearchNgramStoreSearchChannels(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore, team *model.Team) {
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "Incident handling",
+		Name:        NewTestID(),
+		Purpose:     "On call rotation",
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+
+	search := func(term string) []string {
+		t.Helper()
+
+		channels, err := ss.Channel().SearchInTeam(team.Id, term, false)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(channels))
+		for _, channel := range channels {
+			ids = append(ids, channel.Id)
+		}
+		return ids
+	}
+
+	// The words are found in any order and field, the last one being a prefix.
+	assert.Equal(t, []string{channel.Id}, search("handling incid"))
+	assert.Equal(t, []string{channel.Id}, search("rotation handl"))
+	assert.Empty(t, search("outage handl"))
+
+	require.NoError(t, ss.Channel().PermanentDelete(rctx, channel.Id))
+	assert.Empty(t, search("handling incid"))
+	assert.Zero(t, searchNgramsCount(t, s, "channel", channel.Id))
+}
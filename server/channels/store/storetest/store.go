@@ -70,6 +70,13 @@ type Store struct {
 	AccessControlPolicyStore        mocks.AccessControlPolicyStore
 	AttributesStore                 mocks.AttributesStore
 	ContentFlaggingStore            mocks.ContentFlaggingStore
+	OutgoingWebhookDeliveryStore    mocks.OutgoingWebhookDeliveryStore
+	WebAuthnCredentialStore         mocks.WebAuthnCredentialStore
+	PendingEmailNotificationStore   mocks.PendingEmailNotificationStore
+	SavedSearchStore                mocks.SavedSearchStore
+	SearchNgramStore                mocks.SearchNgramStore
+	PostEmbeddingStore              mocks.PostEmbeddingStore
+	PollStore                       mocks.PollStore
 }
 
 func (s *Store) Logger() mlog.LoggerIFace                      { return s.logger }
@@ -162,6 +169,27 @@ func (s *Store) Attributes() store.AttributesStore {
 func (s *Store) ContentFlagging() store.ContentFlaggingStore {
 	return &s.ContentFlaggingStore
 }
+func (s *Store) OutgoingWebhookDelivery() store.OutgoingWebhookDeliveryStore {
+	return &s.OutgoingWebhookDeliveryStore
+}
+func (s *Store) WebAuthnCredential() store.WebAuthnCredentialStore {
+	return &s.WebAuthnCredentialStore
+}
+func (s *Store) PendingEmailNotification() store.PendingEmailNotificationStore {
+	return &s.PendingEmailNotificationStore
+}
+func (s *Store) SavedSearch() store.SavedSearchStore {
+	return &s.SavedSearchStore
+}
+func (s *Store) SearchNgram() store.SearchNgramStore {
+	return &s.SearchNgramStore
+}
+func (s *Store) PostEmbedding() store.PostEmbeddingStore {
+	return &s.PostEmbeddingStore
+}
+func (s *Store) Poll() store.PollStore {
+	return &s.PollStore
+}
 
 func (s *Store) GetSchemaDefinition() (*model.SupportPacketDatabaseSchema, error) {
 	return &model.SupportPacketDatabaseSchema{
@@ -214,5 +242,12 @@ func (s *Store) AssertExpectations(t mock.TestingT) bool {
 		&s.AccessControlPolicyStore,
 		&s.AttributesStore,
 		&s.ContentFlaggingStore,
+		&s.OutgoingWebhookDeliveryStore,
+		&s.WebAuthnCredentialStore,
+		&s.PendingEmailNotificationStore,
+		&s.SavedSearchStore,
+		&s.SearchNgramStore,
+		&s.PostEmbeddingStore,
+		&s.PollStore,
 	)
 }
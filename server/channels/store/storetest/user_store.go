@@ -104,6 +104,7 @@ func TestUserStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
 	t.Run("UpdateLastLogin", func(t *testing.T) { testUpdateLastLogin(t, rctx, ss) })
 	t.Run("GetUserReport", func(t *testing.T) { testGetUserReport(t, rctx, ss, s) })
 	t.Run("MfaUsedTimestamps", func(t *testing.T) { testMfaUsedTimestamps(t, rctx, ss) })
+	t.Run("MfaRecoveryCodes", func(t *testing.T) { testMfaRecoveryCodes(t, rctx, ss) })
 }
 
 func testUserStoreSave(t *testing.T, rctx request.CTX, ss store.Store) {
@@ -6680,6 +6681,41 @@ func testMfaUsedTimestamps(t *testing.T, rctx request.CTX, ss store.Store) {
 	require.Equal(t, []int{1, 2, 3}, tss)
 }
 
+func testMfaRecoveryCodes(t *testing.T, rctx request.CTX, ss store.Store) {
+	u1, err := ss.User().Save(rctx, &model.User{
+		Email:    MakeEmail(),
+		Username: "u1" + model.NewId(),
+	})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, ss.User().PermanentDelete(rctx, u1.Id)) }()
+
+	used, err := ss.User().UseMfaRecoveryCode(u1.Id, "code1")
+	require.NoError(t, err)
+	require.False(t, used, "no codes have been stored")
+
+	err = ss.User().UpdateMfaRecoveryCodes(u1.Id, []string{"code1", "code2"})
+	require.NoError(t, err)
+
+	used, err = ss.User().UseMfaRecoveryCode(u1.Id, "code1")
+	require.NoError(t, err)
+	require.True(t, used)
+
+	used, err = ss.User().UseMfaRecoveryCode(u1.Id, "code1")
+	require.NoError(t, err)
+	require.False(t, used, "codes can only be used once")
+
+	used, err = ss.User().UseMfaRecoveryCode(u1.Id, "unknown")
+	require.NoError(t, err)
+	require.False(t, used)
+
+	err = ss.User().UpdateMfaRecoveryCodes(u1.Id, []string{})
+	require.NoError(t, err)
+
+	used, err = ss.User().UseMfaRecoveryCode(u1.Id, "code2")
+	require.NoError(t, err)
+	require.False(t, used, "replaced codes can't be used")
+}
+
 func testUserStoreSearchCommonContentFlaggingReviewers(t *testing.T, rctx request.CTX, ss store.Store) {
 	ss.ContentFlagging().ClearCaches()
 
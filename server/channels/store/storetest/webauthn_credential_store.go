@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestWebAuthnCredentialStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveAndGet", func(t *testing.T) { testWebAuthnCredentialStoreSaveAndGet(t, rctx, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testWebAuthnCredentialStoreGetForUser(t, rctx, ss) })
+	t.Run("UpdateLastUsed", func(t *testing.T) { testWebAuthnCredentialStoreUpdateLastUsed(t, rctx, ss) })
+	t.Run("Delete", func(t *testing.T) { testWebAuthnCredentialStoreDelete(t, rctx, ss) })
+}
+
+func buildWebAuthnCredential(userID string) *model.WebAuthnCredential {
+	return &model.WebAuthnCredential{
+		UserId:       userID,
+		Name:         "Security key",
+		CredentialId: model.NewId() + model.NewId(),
+		PublicKey:    []byte{0xa5, 0x01, 0x02},
+		SignCount:    1,
+	}
+}
+
+func testWebAuthnCredentialStoreSaveAndGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	credential, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+	require.NotEmpty(t, credential.Id)
+
+	_, err = ss.WebAuthnCredential().Save(credential)
+	require.Error(t, err, "shouldn't be able to update from save")
+
+	_, err = ss.WebAuthnCredential().Save(buildWebAuthnCredential("junk"))
+	require.Error(t, err)
+
+	duplicate := buildWebAuthnCredential(model.NewId())
+	duplicate.CredentialId = credential.CredentialId
+	_, err = ss.WebAuthnCredential().Save(duplicate)
+	var cErr *store.ErrConflict
+	require.True(t, errors.As(err, &cErr), "credential ids must be unique")
+
+	fetched, err := ss.WebAuthnCredential().Get(credential.Id)
+	require.NoError(t, err)
+	assert.Equal(t, credential, fetched)
+
+	fetched, err = ss.WebAuthnCredential().GetByCredentialId(credential.CredentialId)
+	require.NoError(t, err)
+	assert.Equal(t, credential, fetched)
+
+	var nfErr *store.ErrNotFound
+	_, err = ss.WebAuthnCredential().Get(model.NewId())
+	require.True(t, errors.As(err, &nfErr))
+
+	_, err = ss.WebAuthnCredential().GetByCredentialId(model.NewId())
+	require.True(t, errors.As(err, &nfErr))
+}
+
+func testWebAuthnCredentialStoreGetForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+
+	first, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(userID))
+	require.NoError(t, err)
+	second, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(userID))
+	require.NoError(t, err)
+	_, err = ss.WebAuthnCredential().Save(buildWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	credentials, err := ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	assert.ElementsMatch(t, []string{first.Id, second.Id}, []string{credentials[0].Id, credentials[1].Id})
+
+	credentials, err = ss.WebAuthnCredential().GetForUser(model.NewId())
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+}
+
+func testWebAuthnCredentialStoreUpdateLastUsed(t *testing.T, rctx request.CTX, ss store.Store) {
+	credential, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	now := model.GetMillis()
+	require.NoError(t, ss.WebAuthnCredential().UpdateLastUsed(credential.Id, 42, now))
+
+	fetched, err := ss.WebAuthnCredential().Get(credential.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), fetched.SignCount)
+	assert.Equal(t, now, fetched.LastUsedAt)
+}
+
+func testWebAuthnCredentialStoreDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+
+	first, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(userID))
+	require.NoError(t, err)
+	_, err = ss.WebAuthnCredential().Save(buildWebAuthnCredential(userID))
+	require.NoError(t, err)
+	other, err := ss.WebAuthnCredential().Save(buildWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	require.NoError(t, ss.WebAuthnCredential().Delete(first.Id))
+	credentials, err := ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+
+	require.NoError(t, ss.WebAuthnCredential().PermanentDeleteByUser(userID))
+	credentials, err = ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+
+	_, err = ss.WebAuthnCredential().Get(other.Id)
+	require.NoError(t, err)
+}
@@ -305,6 +305,8 @@ func testWebhookStoreGetOutgoing(t *testing.T, rctx request.CTX, ss store.Store)
 	webhook, err := ss.Webhook().GetOutgoing(o1.Id)
 	require.NoError(t, err)
 	require.Equal(t, webhook.CreateAt, o1.CreateAt, "invalid returned webhook")
+	require.NotEmpty(t, webhook.SigningSecret)
+	require.Equal(t, o1.SigningSecret, webhook.SigningSecret, "invalid returned signing secret")
 
 	_, err = ss.Webhook().GetOutgoing("123")
 	require.Error(t, err, "Missing id should have failed")
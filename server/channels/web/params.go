@@ -42,6 +42,9 @@ type Params struct {
 	PluginId                           string
 	CommandId                          string
 	HookId                             string
+	DeliveryId                         string
+	WebAuthnCredentialId               string
+	SavedSearchId                      string
 	ReportId                           string
 	EmojiId                            string
 	AppId                              string
@@ -156,6 +159,9 @@ func ParamsFromRequest(r *http.Request) *Params {
 	}
 	params.CommandId = props["command_id"]
 	params.HookId = props["hook_id"]
+	params.DeliveryId = props["delivery_id"]
+	params.WebAuthnCredentialId = props["webauthn_credential_id"]
+	params.SavedSearchId = props["saved_search_id"]
 	params.ReportId = props["report_id"]
 	params.EmojiId = props["emoji_id"]
 	params.AppId = props["app_id"]
@@ -129,6 +129,8 @@ type Client interface {
 	GetUpload(ctx context.Context, uploadID string) (*model.UploadSession, *model.Response, error)
 	GetUploadsForUser(ctx context.Context, userID string) ([]*model.UploadSession, *model.Response, error)
 	UploadData(ctx context.Context, uploadID string, data io.Reader) (*model.FileInfo, *model.Response, error)
+	GetUsersFileStorageUsage(ctx context.Context, page, perPage int) ([]*model.FileStorageUsage, *model.Response, error)
+	GetTeamsFileStorageUsage(ctx context.Context, page, perPage int) ([]*model.FileStorageUsage, *model.Response, error)
 	ListImports(ctx context.Context) ([]string, *model.Response, error)
 	DeleteImport(ctx context.Context, name string) (*model.Response, error)
 	GetJob(ctx context.Context, id string) (*model.Job, *model.Response, error)
@@ -150,6 +152,9 @@ type Client interface {
 	GetOutgoingWebhooksForChannel(ctx context.Context, channelID string, page int, perPage int, etag string) ([]*model.OutgoingWebhook, *model.Response, error)
 	GetOutgoingWebhooksForTeam(ctx context.Context, teamID string, page int, perPage int, etag string) ([]*model.OutgoingWebhook, *model.Response, error)
 	RegenOutgoingHookToken(ctx context.Context, hookID string) (*model.OutgoingWebhook, *model.Response, error)
+	GetOutgoingWebhookDeliveries(ctx context.Context, hookID string, status string, page int, perPage int) ([]*model.OutgoingWebhookDelivery, *model.Response, error)
+	GetOutgoingWebhookDelivery(ctx context.Context, hookID, deliveryID string) (*model.OutgoingWebhookDelivery, *model.Response, error)
+	RedeliverOutgoingWebhookDelivery(ctx context.Context, hookID, deliveryID string) (*model.OutgoingWebhookDelivery, *model.Response, error)
 	DeleteOutgoingWebhook(ctx context.Context, hookID string) (*model.Response, error)
 	ListExports(ctx context.Context) ([]string, *model.Response, error)
 	DeleteExport(ctx context.Context, name string) (*model.Response, error)
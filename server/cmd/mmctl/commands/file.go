@@ -0,0 +1,222 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/client"
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/spf13/cobra"
+)
+
+var FileCmd = &cobra.Command{
+	Use:   "file",
+	Short: "Management of the file storage",
+}
+
+var FileMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the stored files to another backend",
+	Long: `Start a job copying every stored file to the backend configured in the FileSettings.Migration* settings, checking the size and checksum of every copy.
+The job can be stopped and resumed. Once it is done, enable FileSettings.EnableMigrationCutover so that new files are written to the target backend while files are still read from the current one, and run the migration again to copy the files stored in the meantime.`,
+	Example: "  file migrate",
+	Args:    cobra.NoArgs,
+	RunE:    withClient(fileMigrateCmdF),
+}
+
+var FileMigrateJobCmd = &cobra.Command{
+	Use:   "job",
+	Short: "List and show file storage migration jobs",
+}
+
+var FileMigrateJobListCmd = &cobra.Command{
+	Use:     "list",
+	Example: "  file migrate job list",
+	Short:   "List file storage migration jobs",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE:    withClient(fileMigrateJobListCmdF),
+}
+
+var FileMigrateJobShowCmd = &cobra.Command{
+	Use:     "show [migrationJobID]",
+	Example: "  file migrate job show f3d68qkkm7n8xgsfxwuo498rah",
+	Short:   "Show file storage migration job",
+	Args:    cobra.ExactArgs(1),
+	RunE:    withClient(fileMigrateJobShowCmdF),
+}
+
+var FileUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report the file storage used by users and teams",
+}
+
+var FileUsageUsersCmd = &cobra.Command{
+	Use:     "users",
+	Example: "  file usage users --per-page 20",
+	Short:   "List the users using the most file storage",
+	Args:    cobra.NoArgs,
+	RunE:    withClient(fileUsageUsersCmdF),
+}
+
+var FileUsageTeamsCmd = &cobra.Command{
+	Use:     "teams",
+	Example: "  file usage teams --per-page 20",
+	Short:   "List the teams using the most file storage",
+	Args:    cobra.NoArgs,
+	RunE:    withClient(fileUsageTeamsCmdF),
+}
+
+func init() {
+	FileMigrateJobListCmd.Flags().Int("page", 0, "Page number to fetch for the list of migration jobs")
+	FileMigrateJobListCmd.Flags().Int("per-page", DefaultPageSize, "Number of migration jobs to be fetched")
+	FileMigrateJobListCmd.Flags().Bool("all", false, "Fetch all migration jobs. --page flag will be ignore if provided")
+	FileMigrateJobCmd.AddCommand(
+		FileMigrateJobListCmd,
+		FileMigrateJobShowCmd,
+	)
+	FileMigrateCmd.AddCommand(
+		FileMigrateJobCmd,
+	)
+	for _, cmd := range []*cobra.Command{FileUsageUsersCmd, FileUsageTeamsCmd} {
+		cmd.Flags().Int("page", 0, "Page number to fetch")
+		cmd.Flags().Int("per-page", DefaultPageSize, "Number of entries to be fetched")
+	}
+	FileUsageCmd.AddCommand(
+		FileUsageUsersCmd,
+		FileUsageTeamsCmd,
+	)
+	FileCmd.AddCommand(
+		FileMigrateCmd,
+		FileUsageCmd,
+	)
+	RootCmd.AddCommand(FileCmd)
+}
+
+func fileMigrateCmdF(c client.Client, command *cobra.Command, args []string) error {
+	job, _, err := c.CreateJob(context.TODO(), &model.Job{
+		Type: model.JobTypeFileStorageMigration,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create file storage migration job: %w", err)
+	}
+
+	printer.PrintT("File storage migration job successfully created, ID: {{.Id}}", job)
+
+	return nil
+}
+
+func fileMigrateJobShowCmdF(c client.Client, command *cobra.Command, args []string) error {
+	job, _, err := c.GetJob(context.TODO(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get file storage migration job: %w", err)
+	}
+	printFileMigrationJob(job)
+	return nil
+}
+
+func fileMigrateJobListCmdF(c client.Client, command *cobra.Command, args []string) error {
+	return jobListCmdF(c, command, model.JobTypeFileStorageMigration, "")
+}
+
+func printFileMigrationJob(job *model.Job) {
+	if job.StartAt > 0 {
+		printer.PrintT(fmt.Sprintf("  ID: {{.Id}}\n  Status: {{.Status}}\n  Progress: {{.Progress}}%%\n  Created: %s\n  Started: %s\n  Phase: %s\n  Copied: %s files, %s bytes\n  Verified: %s\n  Kept: %s\n  Missing: %s\n  Failed: %s\n",
+			time.Unix(job.CreateAt/1000, 0), time.Unix(job.StartAt/1000, 0), job.Data["phase"], valueOrZero(job.Data["copied_files"]), valueOrZero(job.Data["copied_bytes"]),
+			valueOrZero(job.Data["verified_files"]), valueOrZero(job.Data["kept_files"]), valueOrZero(job.Data["missing_files"]), valueOrZero(job.Data["failed_files"])), job)
+	} else {
+		printer.PrintT(fmt.Sprintf("  ID: {{.Id}}\n  Status: {{.Status}}\n  Created: %s\n\n",
+			time.Unix(job.CreateAt/1000, 0)), job)
+	}
+}
+
+func valueOrZero(value string) string {
+	if value == "" {
+		return "0"
+	}
+	return value
+}
+
+func fileUsageUsersCmdF(c client.Client, command *cobra.Command, args []string) error {
+	page, err := command.Flags().GetInt("page")
+	if err != nil {
+		return err
+	}
+	perPage, err := command.Flags().GetInt("per-page")
+	if err != nil {
+		return err
+	}
+
+	usages, _, err := c.GetUsersFileStorageUsage(context.TODO(), page, perPage)
+	if err != nil {
+		return fmt.Errorf("failed to get the file storage usage of users: %w", err)
+	}
+	if len(usages) == 0 {
+		printer.Print("No file storage usage found")
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(usages))
+	for _, usage := range usages {
+		userIDs = append(userIDs, usage.OwnerId)
+	}
+	users, _, err := c.GetUsersByIds(context.TODO(), userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+	usernames := make(map[string]string, len(users))
+	for _, user := range users {
+		usernames[user.Id] = user.Username
+	}
+
+	for _, usage := range usages {
+		printFileStorageUsage(usage, usernames[usage.OwnerId])
+	}
+	return nil
+}
+
+func fileUsageTeamsCmdF(c client.Client, command *cobra.Command, args []string) error {
+	page, err := command.Flags().GetInt("page")
+	if err != nil {
+		return err
+	}
+	perPage, err := command.Flags().GetInt("per-page")
+	if err != nil {
+		return err
+	}
+
+	usages, _, err := c.GetTeamsFileStorageUsage(context.TODO(), page, perPage)
+	if err != nil {
+		return fmt.Errorf("failed to get the file storage usage of teams: %w", err)
+	}
+	if len(usages) == 0 {
+		printer.Print("No file storage usage found")
+		return nil
+	}
+
+	for _, usage := range usages {
+		name := ""
+		if team, _, err := c.GetTeam(context.TODO(), usage.OwnerId, ""); err == nil {
+			name = team.Name
+		}
+		printFileStorageUsage(usage, name)
+	}
+	return nil
+}
+
+func printFileStorageUsage(usage *model.FileStorageUsage, name string) {
+	if name == "" {
+		name = usage.OwnerId
+	}
+	quota := "unlimited"
+	if usage.QuotaBytes > 0 {
+		quota = fmt.Sprintf("%d bytes", usage.QuotaBytes)
+	}
+	printer.PrintT(fmt.Sprintf("%s: {{.UsedBytes}} bytes used, quota: %s", name, quota), usage)
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/spf13/cobra"
+)
+
+func (s *MmctlUnitTestSuite) TestFileMigrateCmdF() {
+	s.Run("create migration job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Type: model.JobTypeFileStorageMigration,
+		}
+
+		s.client.
+			EXPECT().
+			CreateJob(context.TODO(), mockJob).
+			Return(mockJob, &model.Response{}, nil).
+			Times(1)
+
+		err := fileMigrateCmdF(s.client, &cobra.Command{}, nil)
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 1)
+		s.Empty(printer.GetErrorLines())
+		s.Equal(mockJob, printer.GetLines()[0].(*model.Job))
+	})
+
+	s.Run("fail to create migration job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Type: model.JobTypeFileStorageMigration,
+		}
+
+		s.client.
+			EXPECT().
+			CreateJob(context.TODO(), mockJob).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := fileMigrateCmdF(s.client, &cobra.Command{}, nil)
+		s.Require().EqualError(err, "failed to create file storage migration job: mock error")
+		s.Empty(printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestFileMigrateJobShowCmdF() {
+	s.Run("show migration job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Id:       model.NewId(),
+			Type:     model.JobTypeFileStorageMigration,
+			CreateAt: model.GetMillis(),
+			StartAt:  model.GetMillis(),
+			Status:   model.JobStatusInProgress,
+			Data: map[string]string{
+				"phase":        "file_infos",
+				"copied_files": "12",
+			},
+		}
+
+		s.client.
+			EXPECT().
+			GetJob(context.TODO(), mockJob.Id).
+			Return(mockJob, &model.Response{}, nil).
+			Times(1)
+
+		err := fileMigrateJobShowCmdF(s.client, &cobra.Command{}, []string{mockJob.Id})
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 1)
+		s.Empty(printer.GetErrorLines())
+		s.Equal(mockJob, printer.GetLines()[0].(*model.Job))
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestFileMigrateJobListCmdF() {
+	s.Run("list migration jobs", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Id:   model.NewId(),
+			Type: model.JobTypeFileStorageMigration,
+		}
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("page", 0, "")
+		cmd.Flags().Int("per-page", 200, "")
+		cmd.Flags().Bool("all", false, "")
+
+		s.client.
+			EXPECT().
+			GetJobs(context.TODO(), model.JobTypeFileStorageMigration, "", 0, 200).
+			Return([]*model.Job{mockJob}, &model.Response{}, nil).
+			Times(1)
+
+		err := fileMigrateJobListCmdF(s.client, cmd, nil)
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 1)
+		s.Empty(printer.GetErrorLines())
+		s.Equal(mockJob, printer.GetLines()[0].(*model.Job))
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestFileUsageCmdF() {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("page", 0, "")
+		cmd.Flags().Int("per-page", 200, "")
+		return cmd
+	}
+
+	s.Run("list users", func() {
+		printer.Clean()
+		user := &model.User{Id: model.NewId(), Username: "alice"}
+		usage := &model.FileStorageUsage{
+			OwnerId:    user.Id,
+			OwnerType:  model.FileStorageUsageOwnerTypeUser,
+			UsedBytes:  2048,
+			QuotaBytes: 4096,
+		}
+
+		s.client.
+			EXPECT().
+			GetUsersFileStorageUsage(context.TODO(), 0, 200).
+			Return([]*model.FileStorageUsage{usage}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetUsersByIds(context.TODO(), []string{user.Id}).
+			Return([]*model.User{user}, &model.Response{}, nil).
+			Times(1)
+
+		err := fileUsageUsersCmdF(s.client, newCmd(), nil)
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Empty(printer.GetErrorLines())
+		s.Equal(usage, printer.GetLines()[0].(*model.FileStorageUsage))
+	})
+
+	s.Run("list teams", func() {
+		printer.Clean()
+		team := &model.Team{Id: model.NewId(), Name: "storage-hogs"}
+		usage := &model.FileStorageUsage{
+			OwnerId:   team.Id,
+			OwnerType: model.FileStorageUsageOwnerTypeTeam,
+			UsedBytes: 2048,
+		}
+
+		s.client.
+			EXPECT().
+			GetTeamsFileStorageUsage(context.TODO(), 0, 200).
+			Return([]*model.FileStorageUsage{usage}, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeam(context.TODO(), team.Id, "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+
+		err := fileUsageTeamsCmdF(s.client, newCmd(), nil)
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Equal(usage, printer.GetLines()[0].(*model.FileStorageUsage))
+	})
+
+	s.Run("no usage", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetTeamsFileStorageUsage(context.TODO(), 0, 200).
+			Return([]*model.FileStorageUsage{}, &model.Response{}, nil).
+			Times(1)
+
+		err := fileUsageTeamsCmdF(s.client, newCmd(), nil)
+		s.Require().Nil(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Equal("No file storage usage found", printer.GetLines()[0])
+	})
+
+	s.Run("fail to get usage", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetUsersFileStorageUsage(context.TODO(), 0, 200).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := fileUsageUsersCmdF(s.client, newCmd(), nil)
+		s.Require().EqualError(err, "failed to get the file storage usage of users: mock error")
+	})
+}
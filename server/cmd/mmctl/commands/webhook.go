@@ -86,6 +86,33 @@ var DeleteWebhookCmd = &cobra.Command{
 	RunE:    withClient(deleteWebhookCmdF),
 }
 
+var ListOutgoingWebhookDeliveriesCmd = &cobra.Command{
+	Use:     "list-deliveries [webhookId]",
+	Short:   "List outgoing webhook deliveries",
+	Long:    "List the failed deliveries of the outgoing webhook specified by [webhookId] that were queued for retry",
+	Args:    cobra.ExactArgs(1),
+	Example: "  webhook list-deliveries w16zb5tu3n1zkqo18goqry1je --status dead",
+	RunE:    withClient(listOutgoingWebhookDeliveriesCmdF),
+}
+
+var ShowOutgoingWebhookDeliveryCmd = &cobra.Command{
+	Use:     "show-delivery [webhookId] [deliveryId]",
+	Short:   "Show an outgoing webhook delivery",
+	Long:    "Show the delivery specified by [deliveryId] of the outgoing webhook specified by [webhookId]",
+	Args:    cobra.ExactArgs(2),
+	Example: "  webhook show-delivery w16zb5tu3n1zkqo18goqry1je 4tdqhkmmbpyfxkxz8ntjagd8ae",
+	RunE:    withClient(showOutgoingWebhookDeliveryCmdF),
+}
+
+var RedeliverOutgoingWebhookDeliveryCmd = &cobra.Command{
+	Use:     "redeliver [webhookId] [deliveryId]",
+	Short:   "Redeliver an outgoing webhook delivery",
+	Long:    "Immediately send again the delivery specified by [deliveryId] of the outgoing webhook specified by [webhookId]",
+	Args:    cobra.ExactArgs(2),
+	Example: "  webhook redeliver w16zb5tu3n1zkqo18goqry1je 4tdqhkmmbpyfxkxz8ntjagd8ae",
+	RunE:    withClient(redeliverOutgoingWebhookDeliveryCmdF),
+}
+
 func listWebhookCmdF(c client.Client, command *cobra.Command, args []string) error {
 	var teams []*model.Team
 
@@ -426,6 +453,50 @@ func showWebhookCmdF(c client.Client, command *cobra.Command, args []string) err
 	return errors.New("Webhook with id '" + webhookID + "' not found")
 }
 
+func listOutgoingWebhookDeliveriesCmdF(c client.Client, command *cobra.Command, args []string) error {
+	status, _ := command.Flags().GetString("status")
+	if status != "" && !model.IsValidOutgoingWebhookDeliveryStatus(status) {
+		return errors.New("Invalid status '" + status + "'. Must be one of pending, delivered or dead")
+	}
+	page, _ := command.Flags().GetInt("page")
+	perPage, _ := command.Flags().GetInt("per-page")
+
+	deliveries, _, err := c.GetOutgoingWebhookDeliveries(context.TODO(), args[0], status, page, perPage)
+	if err != nil {
+		return errors.Wrap(err, "Unable to list deliveries for webhook '"+args[0]+"'")
+	}
+
+	for _, delivery := range deliveries {
+		printer.PrintT("{{.Id}}\t{{.Status}}\tattempts: {{.Attempts}}\t{{.CallbackURL}}", delivery)
+	}
+
+	return nil
+}
+
+func showOutgoingWebhookDeliveryCmdF(c client.Client, command *cobra.Command, args []string) error {
+	printer.SetSingle(true)
+
+	delivery, _, err := c.GetOutgoingWebhookDelivery(context.TODO(), args[0], args[1])
+	if err != nil {
+		return errors.Wrap(err, "Unable to find delivery '"+args[1]+"'")
+	}
+
+	printer.Print(*delivery)
+	return nil
+}
+
+func redeliverOutgoingWebhookDeliveryCmdF(c client.Client, command *cobra.Command, args []string) error {
+	printer.SetSingle(true)
+
+	delivery, _, err := c.RedeliverOutgoingWebhookDelivery(context.TODO(), args[0], args[1])
+	if err != nil {
+		return errors.Wrap(err, "Unable to redeliver delivery '"+args[1]+"'")
+	}
+
+	printer.PrintT("Delivery {{.Id}} redelivered, status: {{.Status}}", delivery)
+	return nil
+}
+
 func init() {
 	CreateIncomingWebhookCmd.Flags().String("channel", "", "Channel ID (required)")
 	_ = CreateIncomingWebhookCmd.MarkFlagRequired("channel")
@@ -467,6 +538,10 @@ func init() {
 	ModifyOutgoingWebhookCmd.Flags().StringArray("url", []string{}, "Callback URL")
 	ModifyOutgoingWebhookCmd.Flags().String("content-type", "", "Content-type")
 
+	ListOutgoingWebhookDeliveriesCmd.Flags().String("status", "", "Only list deliveries with this status (pending, delivered or dead)")
+	ListOutgoingWebhookDeliveriesCmd.Flags().Int("page", 0, "Page number to fetch for the list of deliveries")
+	ListOutgoingWebhookDeliveriesCmd.Flags().Int("per-page", DefaultPageSize, "Number of deliveries to be fetched")
+
 	WebhookCmd.AddCommand(
 		ListWebhookCmd,
 		CreateIncomingWebhookCmd,
@@ -475,6 +550,9 @@ func init() {
 		ModifyOutgoingWebhookCmd,
 		DeleteWebhookCmd,
 		ShowWebhookCmd,
+		ListOutgoingWebhookDeliveriesCmd,
+		ShowOutgoingWebhookDeliveryCmd,
+		RedeliverOutgoingWebhookDeliveryCmd,
 	)
 
 	RootCmd.AddCommand(WebhookCmd)
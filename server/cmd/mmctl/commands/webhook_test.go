@@ -788,3 +788,133 @@ func (s *MmctlUnitTestSuite) TestShowWebhookCmd() {
 		s.Require().Equal("Webhook with id '"+nonExistentID+"' not found", err.Error())
 	})
 }
+
+func (s *MmctlUnitTestSuite) TestListOutgoingWebhookDeliveriesCmd() {
+	outgoingWebhookID := model.NewId()
+
+	s.Run("Successfully list deliveries", func() {
+		printer.Clean()
+
+		mockDeliveries := []*model.OutgoingWebhookDelivery{
+			{Id: model.NewId(), HookId: outgoingWebhookID, Status: model.OutgoingWebhookDeliveryStatusDead},
+			{Id: model.NewId(), HookId: outgoingWebhookID, Status: model.OutgoingWebhookDeliveryStatusDead},
+		}
+
+		cmd := &cobra.Command{}
+		cmd.Flags().String("status", model.OutgoingWebhookDeliveryStatusDead, "")
+		cmd.Flags().Int("page", 0, "")
+		cmd.Flags().Int("per-page", 10, "")
+
+		s.client.
+			EXPECT().
+			GetOutgoingWebhookDeliveries(context.TODO(), outgoingWebhookID, model.OutgoingWebhookDeliveryStatusDead, 0, 10).
+			Return(mockDeliveries, &model.Response{}, nil).
+			Times(1)
+
+		err := listOutgoingWebhookDeliveriesCmdF(s.client, cmd, []string{outgoingWebhookID})
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 2)
+		s.Len(printer.GetErrorLines(), 0)
+		s.Require().Equal(mockDeliveries[0], printer.GetLines()[0])
+	})
+
+	s.Run("Invalid status", func() {
+		printer.Clean()
+
+		cmd := &cobra.Command{}
+		cmd.Flags().String("status", "unknown", "")
+
+		err := listOutgoingWebhookDeliveriesCmdF(s.client, cmd, []string{outgoingWebhookID})
+		s.Require().Error(err)
+		s.Len(printer.GetLines(), 0)
+	})
+
+	s.Run("List deliveries error", func() {
+		printer.Clean()
+
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("per-page", 10, "")
+
+		s.client.
+			EXPECT().
+			GetOutgoingWebhookDeliveries(context.TODO(), outgoingWebhookID, "", 0, 10).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := listOutgoingWebhookDeliveriesCmdF(s.client, cmd, []string{outgoingWebhookID})
+		s.Require().Error(err)
+		s.Len(printer.GetLines(), 0)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestShowOutgoingWebhookDeliveryCmd() {
+	outgoingWebhookID := model.NewId()
+	deliveryID := model.NewId()
+
+	s.Run("Successfully show delivery", func() {
+		printer.Clean()
+
+		mockDelivery := model.OutgoingWebhookDelivery{Id: deliveryID, HookId: outgoingWebhookID}
+
+		s.client.
+			EXPECT().
+			GetOutgoingWebhookDelivery(context.TODO(), outgoingWebhookID, deliveryID).
+			Return(&mockDelivery, &model.Response{}, nil).
+			Times(1)
+
+		err := showOutgoingWebhookDeliveryCmdF(s.client, &cobra.Command{}, []string{outgoingWebhookID, deliveryID})
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 1)
+		s.Require().Equal(mockDelivery, printer.GetLines()[0])
+	})
+
+	s.Run("Show delivery error", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetOutgoingWebhookDelivery(context.TODO(), outgoingWebhookID, deliveryID).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := showOutgoingWebhookDeliveryCmdF(s.client, &cobra.Command{}, []string{outgoingWebhookID, deliveryID})
+		s.Require().Error(err)
+		s.Len(printer.GetLines(), 0)
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestRedeliverOutgoingWebhookDeliveryCmd() {
+	outgoingWebhookID := model.NewId()
+	deliveryID := model.NewId()
+
+	s.Run("Successfully redeliver", func() {
+		printer.Clean()
+
+		mockDelivery := &model.OutgoingWebhookDelivery{Id: deliveryID, HookId: outgoingWebhookID, Status: model.OutgoingWebhookDeliveryStatusDelivered}
+
+		s.client.
+			EXPECT().
+			RedeliverOutgoingWebhookDelivery(context.TODO(), outgoingWebhookID, deliveryID).
+			Return(mockDelivery, &model.Response{}, nil).
+			Times(1)
+
+		err := redeliverOutgoingWebhookDeliveryCmdF(s.client, &cobra.Command{}, []string{outgoingWebhookID, deliveryID})
+		s.Require().Nil(err)
+		s.Len(printer.GetLines(), 1)
+		s.Require().Equal(mockDelivery, printer.GetLines()[0])
+	})
+
+	s.Run("Redeliver error", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			RedeliverOutgoingWebhookDelivery(context.TODO(), outgoingWebhookID, deliveryID).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := redeliverOutgoingWebhookDeliveryCmdF(s.client, &cobra.Command{}, []string{outgoingWebhookID, deliveryID})
+		s.Require().Error(err)
+		s.Len(printer.GetLines(), 0)
+	})
+}
@@ -33,6 +33,9 @@ func init() {
 		FileSettings: model.FileSettings{
 			PublicLinkSalt: model.NewPointer("abcdefghijklmnopqrstuvwxyz0123456789"),
 		},
+		EmailSettings: model.EmailSettings{
+			ReplyByEmailSigningKey: model.NewPointer("abcdefghijklmnopqrstuvwxyz0123456789"),
+		},
 		LocalizationSettings: model.LocalizationSettings{
 			DefaultServerLocale: model.NewPointer("en"),
 			DefaultClientLocale: model.NewPointer("en"),
@@ -61,6 +64,9 @@ func init() {
 			Directory:      model.NewPointer("/path/to/directory"),
 			PublicLinkSalt: model.NewPointer("abcdefghijklmnopqrstuvwxyz0123456789"),
 		},
+		EmailSettings: model.EmailSettings{
+			ReplyByEmailSigningKey: model.NewPointer("abcdefghijklmnopqrstuvwxyz0123456789"),
+		},
 		LocalizationSettings: model.LocalizationSettings{
 			DefaultServerLocale: model.NewPointer("garbage"),
 			DefaultClientLocale: model.NewPointer("garbage"),
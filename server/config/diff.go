@@ -40,11 +40,14 @@ var configSensitivePaths = map[string]bool{
 	"LdapSettings.BindPassword":                              true,
 	"FileSettings.PublicLinkSalt":                            true,
 	"FileSettings.AmazonS3SecretAccessKey":                   true,
+	"FileSettings.EncryptionMasterKey":                       true,
+	"FileSettings.MigrationAmazonS3SecretAccessKey":          true,
 	"SqlSettings.DataSource":                                 true,
 	"SqlSettings.AtRestEncryptKey":                           true,
 	"SqlSettings.DataSourceReplicas":                         true,
 	"SqlSettings.DataSourceSearchReplicas":                   true,
 	"EmailSettings.SMTPPassword":                             true,
+	"EmailSettings.ReplyByEmailSigningKey":                   true,
 	"GitLabSettings.Secret":                                  true,
 	"GoogleSettings.Secret":                                  true,
 	"Office365Settings.Secret":                               true,
@@ -196,6 +196,48 @@ func TestDiffSanitized(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"sensitive FileSettings.EncryptionMasterKey",
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.FileSettings.EncryptionMasterKey = model.NewPointer("base")
+				return cfg
+			}(),
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.FileSettings.EncryptionMasterKey = model.NewPointer("actual")
+				return cfg
+			}(),
+			ConfigDiffs{
+				{
+					Path:      "FileSettings.EncryptionMasterKey",
+					BaseVal:   model.FakeSetting,
+					ActualVal: model.FakeSetting,
+				},
+			},
+			"",
+		},
+		{
+			"sensitive FileSettings.MigrationAmazonS3SecretAccessKey",
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.FileSettings.MigrationAmazonS3SecretAccessKey = model.NewPointer("base")
+				return cfg
+			}(),
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.FileSettings.MigrationAmazonS3SecretAccessKey = model.NewPointer("actual")
+				return cfg
+			}(),
+			ConfigDiffs{
+				{
+					Path:      "FileSettings.MigrationAmazonS3SecretAccessKey",
+					BaseVal:   model.FakeSetting,
+					ActualVal: model.FakeSetting,
+				},
+			},
+			"",
+		},
 		{
 			"sensitive SqlSettings.DataSource",
 			func() *model.Config {
@@ -315,6 +357,27 @@ func TestDiffSanitized(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"sensitive EmailSettings.ReplyByEmailSigningKey",
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.EmailSettings.ReplyByEmailSigningKey = model.NewPointer("base")
+				return cfg
+			}(),
+			func() *model.Config {
+				cfg := defaultConfigGen()
+				cfg.EmailSettings.ReplyByEmailSigningKey = model.NewPointer("actual")
+				return cfg
+			}(),
+			ConfigDiffs{
+				{
+					Path:      "EmailSettings.ReplyByEmailSigningKey",
+					BaseVal:   model.FakeSetting,
+					ActualVal: model.FakeSetting,
+				},
+			},
+			"",
+		},
 		{
 			"sensitive GitLabSettings.Secret",
 			func() *model.Config {
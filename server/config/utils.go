@@ -34,6 +34,14 @@ func desanitize(actual, target *model.Config) {
 		target.FileSettings.AmazonS3SecretAccessKey = actual.FileSettings.AmazonS3SecretAccessKey
 	}
 
+	if *target.FileSettings.EncryptionMasterKey == model.FakeSetting {
+		target.FileSettings.EncryptionMasterKey = actual.FileSettings.EncryptionMasterKey
+	}
+
+	if *target.FileSettings.MigrationAmazonS3SecretAccessKey == model.FakeSetting {
+		target.FileSettings.MigrationAmazonS3SecretAccessKey = actual.FileSettings.MigrationAmazonS3SecretAccessKey
+	}
+
 	if *target.EmailSettings.SMTPPassword == model.FakeSetting {
 		target.EmailSettings.SMTPPassword = actual.EmailSettings.SMTPPassword
 	}
@@ -61,6 +69,10 @@ func desanitize(actual, target *model.Config) {
 		target.SqlSettings.AtRestEncryptKey = actual.SqlSettings.AtRestEncryptKey
 	}
 
+	if *target.EmailSettings.ReplyByEmailSigningKey == model.FakeSetting {
+		target.EmailSettings.ReplyByEmailSigningKey = actual.EmailSettings.ReplyByEmailSigningKey
+	}
+
 	if *target.ElasticsearchSettings.Password == model.FakeSetting {
 		*target.ElasticsearchSettings.Password = *actual.ElasticsearchSettings.Password
 	}
@@ -89,6 +101,10 @@ func desanitize(actual, target *model.Config) {
 		*target.ServiceSettings.SplitKey = *actual.ServiceSettings.SplitKey
 	}
 
+	if *target.SemanticSearchSettings.EmbeddingAPIKey == model.FakeSetting {
+		target.SemanticSearchSettings.EmbeddingAPIKey = actual.SemanticSearchSettings.EmbeddingAPIKey
+	}
+
 	for id, settings := range target.PluginSettings.Plugins {
 		for k, v := range settings {
 			if v == model.FakeSetting {
@@ -38,6 +38,7 @@ type MetricsInterface interface {
 	IncrementMemCacheHitCounter(cacheName string)
 	IncrementMemCacheMissCounter(cacheName string)
 	IncrementMemCacheInvalidationCounter(cacheName string)
+	IncrementHybridCacheRequestCounter(cacheName, result string)
 	IncrementMemCacheMissCounterSession()
 	IncrementMemCacheHitCounterSession()
 	IncrementMemCacheInvalidationCounterSession()
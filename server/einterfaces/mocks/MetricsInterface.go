@@ -133,6 +133,11 @@ func (_m *MetricsInterface) IncrementHTTPWebSockets(originClient string) {
 	_m.Called(originClient)
 }
 
+// IncrementHybridCacheRequestCounter provides a mock function with given fields: cacheName, result
+func (_m *MetricsInterface) IncrementHybridCacheRequestCounter(cacheName string, result string) {
+	_m.Called(cacheName, result)
+}
+
 // IncrementJobActive provides a mock function with given fields: jobType
 func (_m *MetricsInterface) IncrementJobActive(jobType string) {
 	_m.Called(jobType)
@@ -13,6 +13,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
@@ -61,6 +63,10 @@ type ESPost struct {
 	Hashtags    []string `json:"hashtags"`
 	Attachments string   `json:"attachments"`
 	URLs        []string `json:"urls"`
+	RootId      string   `json:"root_id"`
+	IsPinned    bool     `json:"is_pinned"`
+	HasFile     bool     `json:"has_file"`
+	FromBot     bool     `json:"from_bot"`
 }
 
 type ESFile struct {
@@ -115,6 +121,10 @@ func ESPostFromPostForIndexing(post *model.PostForIndexing) *ESPost {
 		Message:   post.Message,
 		Type:      post.Type,
 		Hashtags:  strings.Fields(post.Hashtags),
+		RootId:    post.RootId,
+		IsPinned:  post.IsPinned,
+		HasFile:   len(post.FileIds) > 0 || len(post.Filenames) > 0,
+		FromBot:   post.GetProp(model.PostPropsFromBot) == "true",
 	}
 
 	var searchAttachments []string
@@ -334,6 +344,43 @@ func ReadFileSafely(fb filestore.FileBackend, path string) ([]byte, error) {
 	}
 }
 
+// PostAttributeFilters returns the filters, and the negated filters, of the is:pinned,
+// has:file, has:link, in:thread and from:bot search modifiers. The posts indexed before these
+// fields were added to the index don't match them until they are reindexed.
+func PostAttributeFilters(params *model.SearchParams) (filters, notFilters []types.Query) {
+	boolFilter := func(field string, value *bool) {
+		if value != nil {
+			filters = append(filters, types.Query{
+				Term: map[string]types.TermQuery{field: {Value: *value}},
+			})
+		}
+	}
+	boolFilter("is_pinned", params.IsPinned)
+	boolFilter("has_file", params.HasFile)
+	boolFilter("from_bot", params.FromBots)
+
+	if params.HasLink != nil {
+		hasLink := types.Query{Exists: &types.ExistsQuery{Field: "urls"}}
+		if *params.HasLink {
+			filters = append(filters, hasLink)
+		} else {
+			notFilters = append(notFilters, hasLink)
+		}
+	}
+
+	if params.InThread != nil {
+		isRoot := types.Query{Term: map[string]types.TermQuery{"root_id": {Value: ""}}}
+		if *params.InThread {
+			filters = append(filters, types.Query{Exists: &types.ExistsQuery{Field: "root_id"}})
+			notFilters = append(notFilters, isRoot)
+		} else {
+			filters = append(filters, isRoot)
+		}
+	}
+
+	return filters, notFilters
+}
+
 func GetMatchesForHit(highlights map[string][]string) ([]string, error) {
 	matchMap := make(map[string]bool)
 
@@ -70,6 +70,10 @@ func TestESPostFromPostForIndexing(t *testing.T) {
 	assert.Equal(t, "default", espost1.Type)
 	assert.Empty(t, espost1.Hashtags)
 	assert.Equal(t, "text 1", espost1.Attachments)
+	assert.Empty(t, espost1.RootId)
+	assert.False(t, espost1.IsPinned)
+	assert.False(t, espost1.HasFile)
+	assert.False(t, espost1.FromBot)
 
 	// Create one with attachments in model.SlackAttachment form.
 
@@ -82,6 +86,9 @@ func TestESPostFromPostForIndexing(t *testing.T) {
 			UserId:    model.NewId(),
 			CreateAt:  model.GetMillis(),
 			Message:   "message",
+			RootId:    model.NewId(),
+			IsPinned:  true,
+			FileIds:   model.StringArray{model.NewId()},
 			Type:      "slack_attachment",
 			Hashtags:  "#buh #boh",
 			Props: map[string]any{
@@ -90,6 +97,7 @@ func TestESPostFromPostForIndexing(t *testing.T) {
 						Text: "text 2",
 					},
 				},
+				model.PostPropsFromBot: "true",
 			},
 		},
 	}
@@ -105,6 +113,47 @@ func TestESPostFromPostForIndexing(t *testing.T) {
 	assert.Equal(t, "slack_attachment", espost2.Type)
 	assert.Len(t, espost2.Hashtags, 2)
 	assert.Equal(t, "text 2", espost2.Attachments)
+	assert.Equal(t, post2.RootId, espost2.RootId)
+	assert.True(t, espost2.IsPinned)
+	assert.True(t, espost2.HasFile)
+	assert.True(t, espost2.FromBot)
+}
+
+func TestPostAttributeFilters(t *testing.T) {
+	t.Run("no filters", func(t *testing.T) {
+		filters, notFilters := PostAttributeFilters(&model.SearchParams{Terms: "test"})
+		assert.Empty(t, filters)
+		assert.Empty(t, notFilters)
+	})
+
+	t.Run("required attributes", func(t *testing.T) {
+		filters, notFilters := PostAttributeFilters(&model.SearchParams{
+			IsPinned: model.NewPointer(true),
+			HasLink:  model.NewPointer(true),
+			InThread: model.NewPointer(true),
+		})
+		require.Len(t, filters, 3)
+		assert.Equal(t, true, filters[0].Term["is_pinned"].Value)
+		assert.Equal(t, "urls", filters[1].Exists.Field)
+		assert.Equal(t, "root_id", filters[2].Exists.Field)
+		require.Len(t, notFilters, 1)
+		assert.Equal(t, "", notFilters[0].Term["root_id"].Value)
+	})
+
+	t.Run("excluded attributes", func(t *testing.T) {
+		filters, notFilters := PostAttributeFilters(&model.SearchParams{
+			HasFile:  model.NewPointer(false),
+			FromBots: model.NewPointer(false),
+			HasLink:  model.NewPointer(false),
+			InThread: model.NewPointer(false),
+		})
+		require.Len(t, filters, 3)
+		assert.Equal(t, false, filters[0].Term["has_file"].Value)
+		assert.Equal(t, false, filters[1].Term["from_bot"].Value)
+		assert.Equal(t, "", filters[2].Term["root_id"].Value)
+		require.Len(t, notFilters, 1)
+		assert.Equal(t, "urls", notFilters[0].Exists.Field)
+	})
 }
 
 func TestGetMatchesForHit(t *testing.T) {
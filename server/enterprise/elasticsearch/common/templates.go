@@ -31,6 +31,18 @@ func GetPostTemplate(cfg *model.Config) *putindextemplate.Request {
 				Normalizer: model.NewPointer("mm_hashtag"),
 				Store:      model.NewPointer(true),
 			},
+			"root_id": types.KeywordProperty{
+				Type: "keyword",
+			},
+			"is_pinned": types.BooleanProperty{
+				Type: "boolean",
+			},
+			"has_file": types.BooleanProperty{
+				Type: "boolean",
+			},
+			"from_bot": types.BooleanProperty{
+				Type: "boolean",
+			},
 		},
 	}
 
@@ -356,6 +356,10 @@ func (es *ElasticsearchInterfaceImpl) SearchPosts(channels model.ChannelList, se
 				})
 			}
 
+			attributeFilters, attributeNotFilters := common.PostAttributeFilters(params)
+			filters = append(filters, attributeFilters...)
+			notFilters = append(notFilters, attributeNotFilters...)
+
 			if params.OnDate != "" {
 				before, after := params.GetOnDateMillis()
 				filters = append(filters, types.Query{
@@ -373,6 +373,10 @@ func (os *OpensearchInterfaceImpl) SearchPosts(channels model.ChannelList, searc
 				})
 			}
 
+			attributeFilters, attributeNotFilters := common.PostAttributeFilters(params)
+			filters = append(filters, attributeFilters...)
+			notFilters = append(notFilters, attributeNotFilters...)
+
 			if params.OnDate != "" {
 				before, after := params.GetOnDateMillis()
 				filters = append(filters, types.Query{
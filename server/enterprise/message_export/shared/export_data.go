@@ -6,7 +6,10 @@ package shared
 import (
 	"sort"
 
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
 )
 
 type UserType string
@@ -42,6 +45,7 @@ type PostExport struct {
 	AttachmentCreates []*FileUploadStartExport // the post's attachments that were uploaded this export period
 	AttachmentDeletes []PostExport             // the post's attachments that were deleted
 	FileInfo          *model.FileInfo          // if this was a file PostExport, FileInfo will contain that info. Otherwise, nil.
+	Poll              *model.Poll              // if this is a poll post, the poll with its tally at the time of the export. Otherwise, nil.
 }
 
 type FileUploadStartExport struct {
@@ -180,6 +184,12 @@ func GetGenericExportData(p ExportParams) (GenericExportData, error) {
 		var postExport PostExport
 		postExport, results = getPostExport(post, results)
 
+		poll, err := postToPoll(post, p.Db)
+		if err != nil {
+			return GenericExportData{}, err
+		}
+		postExport.Poll = poll
+
 		if err := processPostAttachments(post, postExport, false); err != nil {
 			return GenericExportData{}, err
 		}
@@ -274,6 +284,7 @@ func postToAttachmentsEntries(post *model.MessageExport, db MessageExportStore,
 	if err != nil {
 		return
 	}
+	uploadedFiles = withOriginalContent(uploadedFiles)
 
 	for _, fileInfo := range uploadedFiles {
 		if fileInfo.DeleteAt > 0 && !ignoreDeleted {
@@ -309,6 +320,36 @@ func postToAttachmentsEntries(post *model.MessageExport, db MessageExportStore,
 	return
 }
 
+// postToPoll returns the poll of a poll post with its votes tallied, or nil for other posts.
+// Votes are export-worthy whether or not the poll is anonymous, so the voters are always set.
+func postToPoll(post *model.MessageExport, db MessageExportStore) (*model.Poll, error) {
+	if model.SafeDereference(post.PostType) != model.PostTypePoll || IsDeletedMsg(post) {
+		return nil, nil
+	}
+
+	poll, err := db.Poll().Get(*post.PostId)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		if errors.As(err, &nfErr) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get poll for post %s", *post.PostId)
+	}
+
+	votes, err := db.Poll().GetVotes(*post.PostId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get poll votes for post %s", *post.PostId)
+	}
+
+	// Tally as a poll that isn't anonymous, so that the voters are recorded.
+	anonymous := poll.Anonymous
+	poll.Anonymous = false
+	poll.SetTally(votes, "")
+	poll.Anonymous = anonymous
+
+	return poll, nil
+}
+
 func getPostExport(post *model.MessageExport, results RunExportResults) (PostExport, RunExportResults) {
 	// We have three "kinds" of posts:
 	// (using "1" and "2" for simplicity)
@@ -16,6 +16,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/v8/channels/api4"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
 )
 
@@ -389,6 +390,63 @@ func TestPostToAttachmentsEntries(t *testing.T) {
 	}
 }
 
+func TestPostToPoll(t *testing.T) {
+	postID := model.NewId()
+	post := model.MessageExport{
+		PostId:       model.NewPointer(postID),
+		PostCreateAt: model.NewPointer(int64(1)),
+		PostType:     model.NewPointer(model.PostTypePoll),
+	}
+
+	t.Run("not a poll", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		defer mockStore.AssertExpectations(t)
+
+		poll, err := postToPoll(&model.MessageExport{PostId: model.NewPointer(postID), PostType: model.NewPointer("")}, NewMessageExportStore(mockStore))
+		require.NoError(t, err)
+		assert.Nil(t, poll)
+	})
+
+	t.Run("poll not found", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		defer mockStore.AssertExpectations(t)
+
+		mockStore.PollStore.On("Get", postID).Return(nil, store.NewErrNotFound("Poll", postID))
+
+		poll, err := postToPoll(&post, NewMessageExportStore(mockStore))
+		require.NoError(t, err)
+		assert.Nil(t, poll)
+	})
+
+	t.Run("anonymous poll is exported with its voters", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		defer mockStore.AssertExpectations(t)
+
+		optionID, otherOptionID, userID := model.NewId(), model.NewId(), model.NewId()
+		mockStore.PollStore.On("Get", postID).Return(&model.Poll{
+			PostId:    postID,
+			Question:  "Lunch?",
+			Anonymous: true,
+			Options: model.PollOptionList{
+				{Id: optionID, Text: "Pizza"},
+				{Id: otherOptionID, Text: "Salad"},
+			},
+		}, nil)
+		mockStore.PollStore.On("GetVotes", postID).Return([]*model.PollVote{
+			{PostId: postID, OptionId: optionID, UserId: userID},
+		}, nil)
+
+		poll, err := postToPoll(&post, NewMessageExportStore(mockStore))
+		require.NoError(t, err)
+		require.NotNil(t, poll)
+		assert.True(t, poll.Anonymous)
+		assert.Equal(t, int64(1), poll.VoterCount)
+		assert.Equal(t, int64(1), poll.Options[0].VoteCount)
+		assert.Equal(t, []string{userID}, poll.Options[0].Voters)
+		assert.Zero(t, poll.Options[1].VoteCount)
+	})
+}
+
 func TestGetJoinLeavePosts(t *testing.T) {
 	mockStore := &storetest.Store{}
 	defer mockStore.AssertExpectations(t)
@@ -540,7 +540,20 @@ func GetPostAttachments(db MessageExportStore, post *model.MessageExport) ([]*mo
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info for a post: %w", err)
 	}
-	return attachments, nil
+	return withOriginalContent(attachments), nil
+}
+
+// withOriginalContent points the attachments to their content as uploaded. Images whose
+// metadata were stripped keep the original upload for compliance export.
+func withOriginalContent(attachments []*model.FileInfo) []*model.FileInfo {
+	for i, attachment := range attachments {
+		if attachment.OriginalPath != "" {
+			original := *attachment
+			original.Path = attachment.OriginalPath
+			attachments[i] = &original
+		}
+	}
+	return attachments
 }
 
 func ChannelTypeDisplayName(channelType model.ChannelType) string {
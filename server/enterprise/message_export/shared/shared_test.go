@@ -284,3 +284,15 @@ func TestJobDataToStringMap_and_StringMapToJobData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, JobData{}, emptyJd)
 }
+
+func TestWithOriginalContent(t *testing.T) {
+	stripped := &model.FileInfo{Id: "1", Path: "20240101/1/photo.jpg", OriginalPath: "20240101/1/original/photo.jpg", MetadataStripped: true}
+	plain := &model.FileInfo{Id: "2", Path: "20240101/2/notes.txt"}
+
+	attachments := withOriginalContent([]*model.FileInfo{stripped, plain})
+	assert.Equal(t, "20240101/1/original/photo.jpg", attachments[0].Path)
+	assert.Equal(t, "20240101/2/notes.txt", attachments[1].Path)
+
+	// The stored file info is left untouched.
+	assert.Equal(t, "20240101/1/photo.jpg", stripped.Path)
+}
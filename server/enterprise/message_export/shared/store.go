@@ -14,6 +14,7 @@ type MessageExportStore interface {
 	Channel() store.ChannelStore
 	Compliance() store.ComplianceStore
 	FileInfo() MEFileInfoStore
+	Poll() store.PollStore
 }
 
 type MEFileInfoStore interface {
@@ -90,6 +90,7 @@ type MetricsInterfaceImpl struct {
 	MemCacheMissCounters         *prometheus.CounterVec
 	MemCacheHitCounters          *prometheus.CounterVec
 	MemCacheInvalidationCounters *prometheus.CounterVec
+	HybridCacheRequestCounters   *prometheus.CounterVec
 
 	MemCacheHitCounterSession          prometheus.Counter
 	MemCacheMissCounterSession         prometheus.Counter
@@ -618,6 +619,18 @@ func New(ps *platform.PlatformService, driver, dataSource string) *MetricsInterf
 	m.Registry.MustRegister(m.MemCacheInvalidationCounters)
 	m.MemCacheInvalidationCounterSession = m.MemCacheInvalidationCounters.With(prometheus.Labels{"name": "Session"})
 
+	m.HybridCacheRequestCounters = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   MetricsNamespace,
+			Subsystem:   MetricsSubsystemCaching,
+			Name:        "hybrid_requests_total",
+			Help:        "Total number of hybrid cache reads, by result: local hit, remote hit or miss",
+			ConstLabels: additionalLabels,
+		},
+		[]string{"name", "result"},
+	)
+	m.Registry.MustRegister(m.HybridCacheRequestCounters)
+
 	// Websocket Subsystem
 
 	m.WebSocketBroadcastCounters = prometheus.NewCounterVec(
@@ -1797,6 +1810,10 @@ func (mi *MetricsInterfaceImpl) IncrementMemCacheInvalidationCounter(cacheName s
 	mi.MemCacheInvalidationCounters.With(prometheus.Labels{"name": cacheName}).Inc()
 }
 
+func (mi *MetricsInterfaceImpl) IncrementHybridCacheRequestCounter(cacheName, result string) {
+	mi.HybridCacheRequestCounters.With(prometheus.Labels{"name": cacheName, "result": result}).Inc()
+}
+
 func (mi *MetricsInterfaceImpl) IncrementMemCacheMissCounterSession() {
 	mi.MemCacheMissCounterSession.Inc()
 }
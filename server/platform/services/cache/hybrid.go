@@ -0,0 +1,230 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+)
+
+// hybridLocalMaxExpiry bounds the time an entry stays in the local tier of a hybrid cache,
+// so that an invalidation missed while the subscription was down doesn't keep serving a
+// stale value for long.
+const hybridLocalMaxExpiry = time.Minute
+
+// The results of the reads of hybrid caches, reported by the metrics.
+const (
+	HybridCacheLocalHit  = "local_hit"
+	HybridCacheRemoteHit = "remote_hit"
+	HybridCacheMiss      = "miss"
+)
+
+// hybridInvalidation is the message broadcast to the other nodes when entries of a hybrid
+// cache change.
+type hybridInvalidation struct {
+	NodeID string   `json:"node_id"`
+	Cache  string   `json:"cache"`
+	Keys   []string `json:"keys,omitempty"`
+	Purge  bool     `json:"purge,omitempty"`
+}
+
+// Hybrid is a cache made of a small in-process LRU in front of a remote cache. Changes are
+// written through to the remote cache, and the other nodes are told to drop their local copy.
+type Hybrid struct {
+	local   *LRU
+	remote  ExternalCache
+	publish func(msg *hybridInvalidation) error
+	metrics einterfaces.MetricsInterface
+}
+
+func newHybrid(local *LRU, remote ExternalCache, publish func(msg *hybridInvalidation) error) *Hybrid {
+	return &Hybrid{
+		local:   local,
+		remote:  remote,
+		publish: publish,
+	}
+}
+
+// localExpiry returns the expiry of the local copy of an entry with the given expiry.
+func localExpiry(ttl time.Duration) time.Duration {
+	if ttl <= 0 || ttl > hybridLocalMaxExpiry {
+		return hybridLocalMaxExpiry
+	}
+	return ttl
+}
+
+func (h *Hybrid) observe(result string) {
+	if h.metrics != nil {
+		h.metrics.IncrementHybridCacheRequestCounter(h.Name(), result)
+	}
+}
+
+// invalidate drops the local copy of the given keys on the other nodes.
+func (h *Hybrid) invalidate(keys []string, purge bool) error {
+	return h.publish(&hybridInvalidation{
+		Cache: h.Name(),
+		Keys:  keys,
+		Purge: purge,
+	})
+}
+
+// Purge is used to completely clear the cache.
+func (h *Hybrid) Purge() error {
+	if err := h.remote.Purge(); err != nil {
+		return err
+	}
+	if err := h.local.Purge(); err != nil {
+		return err
+	}
+	return h.invalidate(nil, true)
+}
+
+// SetWithDefaultExpiry adds the given key and value to the store with the default expiry. If
+// the key already exists, it will overwrite the previous value
+func (h *Hybrid) SetWithDefaultExpiry(key string, value any) error {
+	return h.SetWithExpiry(key, value, h.local.defaultExpiry)
+}
+
+// SetWithExpiry adds the given key and value to the cache with the given expiry. If the key
+// already exists, it will overwrite the previous value
+func (h *Hybrid) SetWithExpiry(key string, value any, ttl time.Duration) error {
+	if err := h.remote.SetWithExpiry(key, value, ttl); err != nil {
+		return err
+	}
+	if err := h.local.SetWithExpiry(key, value, localExpiry(ttl)); err != nil {
+		return err
+	}
+	return h.invalidate([]string{key}, false)
+}
+
+// Increment increments the value of the key by the value.
+func (h *Hybrid) Increment(key string, val int) error {
+	if err := h.remote.Increment(key, val); err != nil {
+		return err
+	}
+	return h.removeLocal([]string{key})
+}
+
+// Decrement decrements the value of the key by the value.
+func (h *Hybrid) Decrement(key string, val int) error {
+	if err := h.remote.Decrement(key, val); err != nil {
+		return err
+	}
+	return h.removeLocal([]string{key})
+}
+
+// Get the content stored in the cache for the given key, and decode it into the value interface.
+// Return ErrKeyNotFound if the key is missing from the cache
+func (h *Hybrid) Get(key string, value any) error {
+	if err := h.local.Get(key, value); err == nil {
+		h.observe(HybridCacheLocalHit)
+		return nil
+	}
+
+	if err := h.remote.Get(key, value); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			h.observe(HybridCacheMiss)
+		}
+		return err
+	}
+	h.observe(HybridCacheRemoteHit)
+
+	// The remote expiry isn't known, the local copy is short lived anyway.
+	return h.local.SetWithExpiry(key, value, localExpiry(h.local.defaultExpiry))
+}
+
+// GetMulti returns values for multiple keys in a single operation, reading the entries
+// missing from the local tier from the remote cache.
+func (h *Hybrid) GetMulti(keys []string, values []any) []error {
+	errs := h.local.GetMulti(keys, values)
+
+	var (
+		remoteKeys    []string
+		remoteValues  []any
+		remoteIndexes []int
+	)
+	for i, err := range errs {
+		if err == nil {
+			h.observe(HybridCacheLocalHit)
+			continue
+		}
+		remoteKeys = append(remoteKeys, keys[i])
+		remoteValues = append(remoteValues, values[i])
+		remoteIndexes = append(remoteIndexes, i)
+	}
+	if len(remoteKeys) == 0 {
+		return errs
+	}
+
+	for j, err := range h.remote.GetMulti(remoteKeys, remoteValues) {
+		errs[remoteIndexes[j]] = err
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				h.observe(HybridCacheMiss)
+			}
+			continue
+		}
+		h.observe(HybridCacheRemoteHit)
+		// A failure to keep a local copy doesn't fail the read.
+		_ = h.local.SetWithExpiry(remoteKeys[j], remoteValues[j], localExpiry(h.local.defaultExpiry))
+	}
+	return errs
+}
+
+// Remove deletes the value for a given key.
+func (h *Hybrid) Remove(key string) error {
+	if err := h.remote.Remove(key); err != nil {
+		return err
+	}
+	return h.removeLocal([]string{key})
+}
+
+// RemoveMulti deletes multiple keys in a single operation.
+func (h *Hybrid) RemoveMulti(keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := h.remote.RemoveMulti(keys); err != nil {
+		return err
+	}
+	return h.removeLocal(keys)
+}
+
+func (h *Hybrid) removeLocal(keys []string) error {
+	if err := h.local.RemoveMulti(keys); err != nil {
+		return err
+	}
+	return h.invalidate(keys, false)
+}
+
+// Scan iterates over the keys of the remote cache, which holds all the entries.
+func (h *Hybrid) Scan(f func([]string) error) error {
+	return h.remote.Scan(f)
+}
+
+// GetInvalidateClusterEvent returns ClusterEventNone, as the local entries of the other
+// nodes are invalidated through the remote cache.
+func (h *Hybrid) GetInvalidateClusterEvent() model.ClusterEvent {
+	return model.ClusterEventNone
+}
+
+// Name returns the name of the cache
+func (h *Hybrid) Name() string {
+	return h.remote.Name()
+}
+
+// handleInvalidation drops the local copy of the entries changed by another node.
+func (h *Hybrid) handleInvalidation(msg *hybridInvalidation) {
+	if msg.Purge {
+		_ = h.local.Purge()
+	} else {
+		_ = h.local.RemoveMulti(msg.Keys)
+	}
+	if h.metrics != nil {
+		h.metrics.IncrementMemCacheInvalidationCounter(h.Name())
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/einterfaces/mocks"
+)
+
+// testRemoteCache is an in-memory ExternalCache standing for Redis.
+type testRemoteCache struct {
+	*LRU
+	gets int
+}
+
+func newTestRemoteCache(name string) *testRemoteCache {
+	return &testRemoteCache{LRU: NewLRU(&CacheOptions{Name: name, Size: 100}).(*LRU)}
+}
+
+func (c *testRemoteCache) Get(key string, value any) error {
+	c.gets++
+	return c.LRU.Get(key, value)
+}
+
+func (c *testRemoteCache) GetMulti(keys []string, values []any) []error {
+	c.gets += len(keys)
+	return c.LRU.GetMulti(keys, values)
+}
+
+func (c *testRemoteCache) Increment(key string, val int) error {
+	var n int64
+	if err := c.LRU.Get(key, &n); err != nil && err != ErrKeyNotFound {
+		return err
+	}
+	return c.LRU.SetWithExpiry(key, n+int64(val), 0)
+}
+
+func (c *testRemoteCache) Decrement(key string, val int) error {
+	return c.Increment(key, -val)
+}
+
+// newTestHybridCaches returns two hybrid caches sharing the same remote cache, as if they
+// were running on two nodes.
+func newTestHybridCaches(t *testing.T) (*Hybrid, *Hybrid, *testRemoteCache) {
+	remote := newTestRemoteCache("test")
+	var node1, node2 *Hybrid
+	newNode := func(other **Hybrid) *Hybrid {
+		return newHybrid(NewLRU(&CacheOptions{Name: "test", Size: 10}).(*LRU), remote, func(msg *hybridInvalidation) error {
+			// Invalidations go through JSON, as they do through Redis.
+			payload, err := json.Marshal(msg)
+			require.NoError(t, err)
+			var received hybridInvalidation
+			require.NoError(t, json.Unmarshal(payload, &received))
+			(*other).handleInvalidation(&received)
+			return nil
+		})
+	}
+	node1 = newNode(&node2)
+	node2 = newNode(&node1)
+	return node1, node2, remote
+}
+
+func TestHybrid(t *testing.T) {
+	t.Run("reads are served by the local tier", func(t *testing.T) {
+		node1, node2, remote := newTestHybridCaches(t)
+
+		require.NoError(t, node1.SetWithDefaultExpiry("key", "value"))
+
+		var value string
+		require.NoError(t, node1.Get("key", &value))
+		assert.Equal(t, "value", value)
+		assert.Zero(t, remote.gets)
+
+		// The other node reads the remote cache once.
+		for range 2 {
+			value = ""
+			require.NoError(t, node2.Get("key", &value))
+			assert.Equal(t, "value", value)
+		}
+		assert.Equal(t, 1, remote.gets)
+	})
+
+	t.Run("changes invalidate the other nodes", func(t *testing.T) {
+		node1, node2, _ := newTestHybridCaches(t)
+
+		require.NoError(t, node1.SetWithDefaultExpiry("key", "value"))
+		var value string
+		require.NoError(t, node2.Get("key", &value))
+
+		require.NoError(t, node1.SetWithDefaultExpiry("key", "updated"))
+		require.NoError(t, node2.Get("key", &value))
+		assert.Equal(t, "updated", value)
+
+		require.NoError(t, node1.Remove("key"))
+		require.ErrorIs(t, node2.Get("key", &value), ErrKeyNotFound)
+
+		require.NoError(t, node2.SetWithDefaultExpiry("key", "value"))
+		require.NoError(t, node1.Get("key", &value))
+		require.NoError(t, node2.Purge())
+		require.ErrorIs(t, node1.Get("key", &value), ErrKeyNotFound)
+	})
+
+	t.Run("counters", func(t *testing.T) {
+		node1, node2, _ := newTestHybridCaches(t)
+
+		require.NoError(t, node1.Increment("count", 2))
+		var count int64
+		require.NoError(t, node2.Get("count", &count))
+		assert.EqualValues(t, 2, count)
+
+		require.NoError(t, node1.Decrement("count", 1))
+		require.NoError(t, node2.Get("count", &count))
+		assert.EqualValues(t, 1, count)
+	})
+
+	t.Run("multiple keys", func(t *testing.T) {
+		node1, node2, remote := newTestHybridCaches(t)
+
+		require.NoError(t, node1.SetWithDefaultExpiry("key1", "value1"))
+		require.NoError(t, node1.SetWithDefaultExpiry("key2", "value2"))
+		var value string
+		require.NoError(t, node2.Get("key1", &value))
+		remote.gets = 0
+
+		var value1, value2, value3 string
+		errs := node2.GetMulti([]string{"key1", "key2", "key3"}, []any{&value1, &value2, &value3})
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.ErrorIs(t, errs[2], ErrKeyNotFound)
+		assert.Equal(t, "value1", value1)
+		assert.Equal(t, "value2", value2)
+		// Only the keys missing from the local tier are read from the remote cache.
+		assert.Equal(t, 2, remote.gets)
+
+		require.NoError(t, node1.RemoveMulti([]string{"key1", "key2"}))
+		errs = node2.GetMulti([]string{"key1", "key2"}, []any{&value1, &value2})
+		require.ErrorIs(t, errs[0], ErrKeyNotFound)
+		require.ErrorIs(t, errs[1], ErrKeyNotFound)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		node1, node2, _ := newTestHybridCaches(t)
+		metrics := &mocks.MetricsInterface{}
+		metrics.On("IncrementHybridCacheRequestCounter", "test", HybridCacheRemoteHit).Once()
+		metrics.On("IncrementHybridCacheRequestCounter", "test", HybridCacheLocalHit).Once()
+		metrics.On("IncrementHybridCacheRequestCounter", "test", HybridCacheMiss).Once()
+		metrics.On("IncrementMemCacheInvalidationCounter", "test").Once()
+		node2.metrics = metrics
+
+		require.NoError(t, node1.SetWithDefaultExpiry("key", "value"))
+		var value string
+		require.NoError(t, node2.Get("key", &value))
+		require.NoError(t, node2.Get("key", &value))
+		require.ErrorIs(t, node2.Get("missing", &value), ErrKeyNotFound)
+		metrics.AssertExpectations(t)
+	})
+
+	t.Run("local entries are short lived", func(t *testing.T) {
+		assert.Equal(t, hybridLocalMaxExpiry, localExpiry(0))
+		assert.Equal(t, hybridLocalMaxExpiry, localExpiry(time.Hour))
+		assert.Equal(t, time.Second, localExpiry(time.Second))
+	})
+}
+
+func TestHybridProviderHandleMessage(t *testing.T) {
+	p := &hybridProvider{
+		nodeID: "node1",
+		logger: mlog.CreateConsoleTestLogger(t),
+		caches: map[string]*Hybrid{},
+	}
+	hc := newHybrid(NewLRU(&CacheOptions{Name: "test", Size: 10}).(*LRU), newTestRemoteCache("test"), func(*hybridInvalidation) error { return nil })
+	p.caches["test"] = hc
+	metrics := &mocks.MetricsInterface{}
+	metrics.On("IncrementMemCacheInvalidationCounter", mock.Anything)
+	hc.metrics = metrics
+
+	require.NoError(t, hc.local.SetWithDefaultExpiry("key", "value"))
+
+	// The invalidations sent by the node itself are ignored.
+	p.handleMessage(`{"node_id":"node1","cache":"test","keys":["key"]}`)
+	var value string
+	require.NoError(t, hc.local.Get("key", &value))
+
+	p.handleMessage(`not json`)
+	p.handleMessage(`{"node_id":"node2","cache":"other","keys":["key"]}`)
+	require.NoError(t, hc.local.Get("key", &value))
+
+	p.handleMessage(`{"node_id":"node2","cache":"test","keys":["key"]}`)
+	require.ErrorIs(t, hc.local.Get("key", &value), ErrKeyNotFound)
+}
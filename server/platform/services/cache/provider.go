@@ -5,10 +5,13 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
 	"github.com/redis/rueidis"
 )
@@ -144,3 +147,162 @@ func (r *redisProvider) Close() error {
 	r.client.Close()
 	return nil
 }
+
+// hybridSubscribeRetryDelay is the delay before subscribing again to the invalidations of
+// hybrid caches once the subscription is lost.
+const hybridSubscribeRetryDelay = time.Second
+
+type hybridProvider struct {
+	*redisProvider
+	nodeID          string
+	channel         string
+	localPercentage int
+	logger          mlog.LoggerIFace
+
+	mut    sync.RWMutex
+	caches map[string]*Hybrid
+
+	subscribeOnce sync.Once
+	ctx           context.Context
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+type HybridOptions struct {
+	RedisOptions
+	// LocalCachePercentage is the size of the local tier of the caches, as a percentage of
+	// their size.
+	LocalCachePercentage int
+	Logger               mlog.LoggerIFace
+}
+
+// NewHybridProvider creates a new CacheProvider whose caches hold a local LRU tier in front
+// of Redis. The local entries are invalidated through Redis pub/sub.
+func NewHybridProvider(opts *HybridOptions) (Provider, error) {
+	redisOpts := opts.RedisOptions
+	// The local tier replaces the client side caching of the Redis client.
+	redisOpts.DisableCache = true
+	rp, err := NewRedisProvider(&redisOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	channel := "invalidations"
+	if opts.RedisCachePrefix != "" {
+		channel = opts.RedisCachePrefix + ":" + channel
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &hybridProvider{
+		redisProvider:   rp.(*redisProvider),
+		nodeID:          model.NewId(),
+		channel:         channel,
+		localPercentage: opts.LocalCachePercentage,
+		logger:          opts.Logger,
+		caches:          map[string]*Hybrid{},
+		ctx:             ctx,
+		cancel:          cancel,
+		done:            make(chan struct{}),
+	}, nil
+}
+
+// NewCache creates a new cache with given opts
+func (h *hybridProvider) NewCache(opts *CacheOptions) (Cache, error) {
+	remote, err := h.redisProvider.NewCache(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	localOpts := *opts
+	localOpts.Size = max(opts.Size*h.localPercentage/100, 1)
+	hc := newHybrid(NewLRU(&localOpts).(*LRU), remote.(ExternalCache), h.publish)
+	hc.metrics = h.metrics
+
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.caches[hc.Name()] = hc
+	return hc, nil
+}
+
+// Connect opens a new connection to the cache using specific provider parameters, and
+// subscribes to the invalidations of the local entries.
+func (h *hybridProvider) Connect() (string, error) {
+	res, err := h.redisProvider.Connect()
+	if err != nil {
+		return "", err
+	}
+	h.subscribeOnce.Do(func() {
+		go h.subscribe()
+	})
+	return res, nil
+}
+
+func (h *hybridProvider) subscribe() {
+	defer close(h.done)
+	for {
+		err := h.client.Receive(h.ctx, h.client.B().Subscribe().Channel(h.channel).Build(), func(msg rueidis.PubSubMessage) {
+			h.handleMessage(msg.Message)
+		})
+		if h.ctx.Err() != nil {
+			return
+		}
+
+		// Invalidations may have been missed while the subscription was down.
+		h.logger.Warn("Lost the subscription to the cache invalidations", mlog.Err(err))
+		h.purgeLocal()
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-time.After(hybridSubscribeRetryDelay):
+		}
+	}
+}
+
+func (h *hybridProvider) publish(msg *hybridInvalidation) error {
+	msg.NodeID = h.nodeID
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return h.client.Do(context.Background(), h.client.B().Publish().Channel(h.channel).Message(string(payload)).Build()).Error()
+}
+
+func (h *hybridProvider) handleMessage(payload string) {
+	var msg hybridInvalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		h.logger.Warn("Invalid cache invalidation message", mlog.Err(err))
+		return
+	}
+	if msg.NodeID == h.nodeID {
+		return
+	}
+
+	h.mut.RLock()
+	hc, ok := h.caches[msg.Cache]
+	h.mut.RUnlock()
+	if ok {
+		hc.handleInvalidation(&msg)
+	}
+}
+
+func (h *hybridProvider) purgeLocal() {
+	h.mut.RLock()
+	defer h.mut.RUnlock()
+	for _, hc := range h.caches {
+		_ = hc.local.Purge()
+	}
+}
+
+func (h *hybridProvider) Type() string {
+	return model.CacheTypeHybrid
+}
+
+// Close releases any resources used by the cache provider.
+func (h *hybridProvider) Close() error {
+	h.cancel()
+	// Nothing to wait for when the subscription never started.
+	h.subscribeOnce.Do(func() {
+		close(h.done)
+	})
+	<-h.done
+	return h.redisProvider.Close()
+}
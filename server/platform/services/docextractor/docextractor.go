@@ -5,6 +5,7 @@ package docextractor
 
 import (
 	"io"
+	"time"
 
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
@@ -14,6 +15,12 @@ type ExtractSettings struct {
 	ArchiveRecursion bool
 	MMPreviewURL     string
 	MMPreviewSecret  string
+	// MaxFileSize is the size of the largest document whose text is extracted, or 0 for no limit.
+	MaxFileSize int64
+	// MaxContentSize is the size the extracted text is truncated to, or 0 for no limit.
+	MaxContentSize int
+	// Timeout bounds the time spent extracting the text of a document, or 0 for no limit.
+	Timeout time.Duration
 }
 
 // Extract extract the text from a document using the system default extractors
@@ -29,8 +36,14 @@ func ExtractWithExtraExtractors(logger mlog.LoggerIFace, filename string, r io.R
 	for _, extraExtractor := range extraExtractors {
 		enabledExtractors.Add(extraExtractor)
 	}
+	limits := newExtractLimits(settings)
 	enabledExtractors.Add(&documentExtractor{})
 	enabledExtractors.Add(&pdfExtractor{})
+	enabledExtractors.Add(&spreadsheetExtractor{limits: limits})
+	enabledExtractors.Add(&openDocumentExtractor{limits: limits})
+	enabledExtractors.Add(&emailExtractor{limits: limits})
+	enabledExtractors.Add(&outlookMessageExtractor{limits: limits})
+	enabledExtractors.Add(&epubExtractor{limits: limits})
 
 	if settings.ArchiveRecursion {
 		enabledExtractors.Add(&archiveExtractor{SubExtractor: enabledExtractors})
@@ -43,8 +56,24 @@ func ExtractWithExtraExtractors(logger mlog.LoggerIFace, filename string, r io.R
 	}
 	enabledExtractors.Add(&plainExtractor{})
 
-	if enabledExtractors.Match(filename) {
-		return enabledExtractors.Extract(filename, r)
+	if !enabledExtractors.Match(filename) {
+		return "", nil
 	}
-	return "", nil
+
+	if settings.MaxFileSize > 0 {
+		size, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return "", err
+		}
+		if size > settings.MaxFileSize {
+			logger.Debug("Skipping the text extraction of a large file", mlog.String("file_name", filename), mlog.Int("size", size))
+			return "", nil
+		}
+	}
+
+	text, err := enabledExtractors.Extract(filename, r)
+	if err != nil {
+		return "", err
+	}
+	return truncateText(text, settings.MaxContentSize), nil
 }
@@ -116,6 +116,14 @@ func TestExtract(t *testing.T) {
 			[]string{},
 			false,
 		},
+		{
+			"Odp file",
+			"sample-doc.odp",
+			ExtractSettings{},
+			[]string{"simple", "document", "contains"},
+			[]string{},
+			false,
+		},
 		{
 			"Pptx file",
 			"sample-doc.pptx",
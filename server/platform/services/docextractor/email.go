@@ -0,0 +1,187 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxEmailPartDepth bounds the nesting of the MIME parts of the e-mails read.
+const maxEmailPartDepth = 8
+
+var emailHeaderDecoder = &mime.WordDecoder{
+	CharsetReader: charset.NewReaderLabel,
+}
+
+// emailExtractor extracts the headers, the text and the attachment names of MIME e-mails.
+type emailExtractor struct {
+	limits extractLimits
+}
+
+func (ee *emailExtractor) Name() string {
+	return "emailExtractor"
+}
+
+func (ee *emailExtractor) Match(filename string) bool {
+	return strings.ToLower(path.Ext(filename)) == ".eml"
+}
+
+func (ee *emailExtractor) Extract(filename string, r io.ReadSeeker) (string, error) {
+	data, err := ee.limits.readAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	text := ee.limits.newTextBuilder()
+	return text.text(writeEmailText(text, bytes.NewReader(data), 0))
+}
+
+// writeEmailText writes the headers and the content of an e-mail. Forwarded messages are
+// written after the content of the e-mail that holds them.
+func writeEmailText(text *textBuilder, r io.Reader, depth int) error {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"Subject", "From", "To", "Cc"} {
+		value := strings.TrimSpace(decodeEmailHeader(msg.Header.Get(key)))
+		if value == "" {
+			continue
+		}
+		if err = text.WriteString(key + ": " + value + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return writeEmailPartText(text, textproto.MIMEHeader(msg.Header), msg.Body, depth)
+}
+
+func decodeEmailHeader(value string) string {
+	decoded, err := emailHeaderDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+func writeEmailPartText(text *textBuilder, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxEmailPartDepth {
+		return errors.New("e-mail parts are nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", nil
+	}
+
+	// Attachments are only indexed by name.
+	if name := emailPartFileName(header, params); name != "" && mediaType != "message/rfc822" {
+		return text.WriteString(name + "\n")
+	}
+
+	body = decodeEmailTransfer(header.Get("Content-Transfer-Encoding"), body)
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return writeMultipartText(text, mediaType, params["boundary"], body, depth)
+	case mediaType == "message/rfc822":
+		return writeEmailText(text, body, depth+1)
+	case mediaType == "text/plain", mediaType == "text/html":
+		data, err := readEmailText(body, params["charset"])
+		if err != nil {
+			return err
+		}
+		if mediaType == "text/html" {
+			return writeHTMLText(text, data)
+		}
+		return text.WriteString(strings.TrimSpace(string(data)) + "\n")
+	}
+	return nil
+}
+
+// writeMultipartText writes the text of the parts of a multipart body. Only one of the
+// versions of an alternative body is written, plain text being preferred.
+func writeMultipartText(text *textBuilder, mediaType, boundary string, body io.Reader, depth int) error {
+	if boundary == "" {
+		return errors.New("multipart e-mail part without boundary")
+	}
+
+	type part struct {
+		header textproto.MIMEHeader
+		body   []byte
+	}
+	var parts []part
+
+	mr := multipart.NewReader(body, boundary)
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(io.LimitReader(p, maxDocumentPartSize))
+		if err != nil {
+			return err
+		}
+		parts = append(parts, part{header: p.Header, body: data})
+	}
+
+	if mediaType == "multipart/alternative" && len(parts) > 0 {
+		preferred := parts[len(parts)-1]
+		for _, p := range parts {
+			if mt, _, _ := mime.ParseMediaType(p.header.Get("Content-Type")); mt == "text/plain" {
+				preferred = p
+				break
+			}
+		}
+		parts = []part{preferred}
+	}
+
+	for _, p := range parts {
+		if err := writeEmailPartText(text, p.header, bytes.NewReader(p.body), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func emailPartFileName(header textproto.MIMEHeader, params map[string]string) string {
+	if _, dispositionParams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil && dispositionParams["filename"] != "" {
+		return decodeEmailHeader(dispositionParams["filename"])
+	}
+	return decodeEmailHeader(params["name"])
+}
+
+func decodeEmailTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+func readEmailText(body io.Reader, charsetLabel string) ([]byte, error) {
+	if charsetLabel != "" {
+		if r, err := charset.NewReaderLabel(charsetLabel, body); err == nil {
+			body = r
+		}
+	}
+	return io.ReadAll(io.LimitReader(body, maxDocumentPartSize))
+}
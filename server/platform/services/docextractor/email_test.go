@@ -0,0 +1,78 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailExtractor(t *testing.T) {
+	extractor := emailExtractor{}
+	require.True(t, extractor.Match("message.eml"))
+	require.False(t, extractor.Match("message.msg"))
+
+	t.Run("plain text", func(t *testing.T) {
+		eml := "From: =?UTF-8?Q?Ren=C3=A9e?= <renee@example.com>\r\n" +
+			"To: team@example.com\r\n" +
+			"Subject: =?ISO-8859-1?Q?Caf=E9?= meeting\r\n" +
+			"Content-Type: text/plain; charset=utf-8\r\n" +
+			"Content-Transfer-Encoding: quoted-printable\r\n" +
+			"\r\n" +
+			"Let's meet at the caf=C3=A9 at noon.\r\n"
+
+		text, err := extractor.Extract("message.eml", strings.NewReader(eml))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Café meeting\nFrom: Renée <renee@example.com>\nTo: team@example.com\nLet's meet at the café at noon.", text)
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		eml := "From: alice@example.com\r\n" +
+			"Subject: Quarterly report\r\n" +
+			"MIME-Version: 1.0\r\n" +
+			"Content-Type: multipart/mixed; boundary=outer\r\n" +
+			"\r\n" +
+			"--outer\r\n" +
+			"Content-Type: multipart/alternative; boundary=inner\r\n" +
+			"\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/html; charset=utf-8\r\n" +
+			"\r\n" +
+			"<p>The <b>html</b> version</p>\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/plain; charset=utf-8\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"\r\n" +
+			"VGhlIHJlcG9ydCBp\r\ncyBhdHRhY2hlZC4=\r\n" +
+			"--inner--\r\n" +
+			"--outer\r\n" +
+			"Content-Type: application/pdf; name=\"report.pdf\"\r\n" +
+			"Content-Disposition: attachment; filename=\"report-q3.pdf\"\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"\r\n" +
+			"JVBERi0xLjQK\r\n" +
+			"--outer\r\n" +
+			"Content-Type: message/rfc822\r\n" +
+			"\r\n" +
+			"From: bob@example.com\r\n" +
+			"Subject: Forwarded numbers\r\n" +
+			"Content-Type: text/html\r\n" +
+			"\r\n" +
+			"<div>Revenue grew</div>\r\n" +
+			"--outer--\r\n"
+
+		text, err := extractor.Extract("message.eml", strings.NewReader(eml))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Quarterly report\nFrom: alice@example.com\nThe report is attached.\nreport-q3.pdf\nSubject: Forwarded numbers\nFrom: bob@example.com\nRevenue grew", text)
+		assert.NotContains(t, text, "html")
+	})
+
+	t.Run("not an e-mail", func(t *testing.T) {
+		_, err := extractor.Extract("message.eml", strings.NewReader("not an e-mail"))
+		require.Error(t, err)
+	})
+}
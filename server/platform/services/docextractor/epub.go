@@ -0,0 +1,112 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// epubExtractor extracts the title, the authors and the text of the chapters of EPUB books.
+type epubExtractor struct {
+	limits extractLimits
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Titles   []string `xml:"metadata>title"`
+	Creators []string `xml:"metadata>creator"`
+	Manifest []struct {
+		ID   string `xml:"id,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+func (ee *epubExtractor) Name() string {
+	return "epubExtractor"
+}
+
+func (ee *epubExtractor) Match(filename string) bool {
+	return strings.ToLower(path.Ext(filename)) == ".epub"
+}
+
+func (ee *epubExtractor) Extract(filename string, r io.ReadSeeker) (string, error) {
+	zr, err := ee.limits.openZip(r)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return "", err
+	}
+	var container epubContainer
+	if err = newXMLDecoder(data).Decode(&container); err != nil {
+		return "", err
+	}
+	if len(container.Rootfiles) == 0 {
+		return "", errors.New("epub has no package document")
+	}
+
+	packagePath := container.Rootfiles[0].FullPath
+	data, err = readZipFile(zr, packagePath)
+	if err != nil {
+		return "", err
+	}
+	var pkg epubPackage
+	if err = newXMLDecoder(data).Decode(&pkg); err != nil {
+		return "", err
+	}
+
+	text := ee.limits.newTextBuilder()
+	return text.text(writeEPUBText(text, zr, path.Dir(packagePath), &pkg))
+}
+
+// writeEPUBText writes the metadata of a book, then the text of its chapters in reading order.
+func writeEPUBText(text *textBuilder, zr *zip.Reader, dir string, pkg *epubPackage) error {
+	for _, s := range append(pkg.Titles, pkg.Creators...) {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if err := text.WriteString(s + "\n"); err != nil {
+			return err
+		}
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		hrefs[item.ID] = item.Href
+	}
+
+	for _, itemRef := range pkg.Spine {
+		href, ok := hrefs[itemRef.IDRef]
+		if !ok {
+			continue
+		}
+		// Manifest references are URLs relative to the package document.
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		data, err := readZipFile(zr, path.Join(dir, href))
+		if err != nil {
+			return err
+		}
+		if err := writeHTMLText(text, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
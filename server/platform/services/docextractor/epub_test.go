@@ -0,0 +1,42 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEPUBExtractor(t *testing.T) {
+	data := createTestZip(t,
+		"mimetype", "application/epub+zip",
+		"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`,
+		"OEBPS/content.opf", `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:title>The Handbook</dc:title><dc:creator>Jane Doe</dc:creator></metadata>
+<manifest>
+<item id="ch2" href="text/chapter%202.xhtml" media-type="application/xhtml+xml"/>
+<item id="ch1" href="text/chapter1.xhtml" media-type="application/xhtml+xml"/>
+<item id="css" href="style.css" media-type="text/css"/>
+</manifest>
+<spine><itemref idref="ch1"/><itemref idref="ch2"/></spine>
+</package>`,
+		"OEBPS/text/chapter1.xhtml", `<html><head><title>Ignored</title><style>p { color: red; }</style></head>
+<body><h1>Getting started</h1><p>Welcome to <em>the</em> handbook &amp; guide.</p></body></html>`,
+		"OEBPS/text/chapter 2.xhtml", `<html><body><h1>Next steps</h1><script>alert("ignored")</script><ul><li>One</li><li>Two</li></ul></body></html>`,
+	)
+
+	extractor := epubExtractor{}
+	require.True(t, extractor.Match("handbook.epub"))
+
+	text, err := extractor.Extract("handbook.epub", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "The Handbook\nJane Doe\nGetting started\nWelcome to the handbook & guide.\nNext steps\nOne\nTwo", text)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlBlockElements are the elements whose content starts on a new line.
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Br: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Figcaption: true, atom.Footer: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true,
+	atom.Li: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Td: true, atom.Th: true, atom.Tr: true, atom.Ul: true,
+}
+
+// writeHTMLText writes the text of an HTML or XHTML document, skipping its scripts and
+// styles.
+func writeHTMLText(text *textBuilder, data []byte) error {
+	var (
+		line      strings.Builder
+		skipDepth int
+	)
+
+	endLine := func() error {
+		s := strings.Join(strings.Fields(line.String()), " ")
+		line.Reset()
+		if s == "" {
+			return nil
+		}
+		return text.WriteString(s + "\n")
+	}
+
+	z := html.NewTokenizer(bytes.NewReader(data))
+	for {
+		switch tt := z.Next(); tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return endLine()
+			}
+			return z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := z.TagName()
+			tag := atom.Lookup(name)
+			if tag == atom.Script || tag == atom.Style || tag == atom.Head {
+				if tt == html.StartTagToken {
+					skipDepth++
+				} else if tt == html.EndTagToken && skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if htmlBlockElements[tag] {
+				if err := endLine(); err != nil {
+					return err
+				}
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				line.Write(z.Text())
+			}
+		}
+	}
+}
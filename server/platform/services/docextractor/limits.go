@@ -0,0 +1,161 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDocumentPartSize bounds the decompressed size of the parts read from zip based
+// documents, so that zip bombs don't exhaust the memory.
+const maxDocumentPartSize = 64 * 1024 * 1024
+
+var (
+	errContentSizeLimit = errors.New("extracted text size limit reached")
+	errExtractTimeout   = errors.New("text extraction timed out")
+)
+
+// extractLimits holds the limits the extractors of structured documents honour.
+type extractLimits struct {
+	maxFileSize    int64
+	maxContentSize int
+	timeout        time.Duration
+}
+
+func newExtractLimits(settings ExtractSettings) extractLimits {
+	return extractLimits{
+		maxFileSize:    settings.MaxFileSize,
+		maxContentSize: settings.MaxContentSize,
+		timeout:        settings.Timeout,
+	}
+}
+
+// newTextBuilder returns a builder for the text of a document whose extraction starts now.
+func (l extractLimits) newTextBuilder() *textBuilder {
+	b := &textBuilder{maxSize: l.maxContentSize}
+	if l.timeout > 0 {
+		b.deadline = time.Now().Add(l.timeout)
+	}
+	return b
+}
+
+// readAll reads a whole document, failing when it is larger than the size limit.
+func (l extractLimits) readAll(r io.Reader) ([]byte, error) {
+	if l.maxFileSize <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, l.maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > l.maxFileSize {
+		return nil, fmt.Errorf("document is larger than %d bytes", l.maxFileSize)
+	}
+	return data, nil
+}
+
+// openZip opens a zip based document.
+func (l extractLimits) openZip(r io.Reader) (*zip.Reader, error) {
+	data, err := l.readAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// textBuilder accumulates the text extracted from a document. Writes fail once the text
+// reaches its size limit, or once the extraction deadline is passed.
+type textBuilder struct {
+	buf      strings.Builder
+	maxSize  int
+	deadline time.Time
+}
+
+// check fails when the extraction deadline is passed.
+func (b *textBuilder) check() error {
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return errExtractTimeout
+	}
+	return nil
+}
+
+func (b *textBuilder) WriteString(s string) error {
+	if err := b.check(); err != nil {
+		return err
+	}
+	if b.maxSize > 0 && b.buf.Len()+len(s) > b.maxSize {
+		b.buf.WriteString(truncateText(s, b.maxSize-b.buf.Len()))
+		return errContentSizeLimit
+	}
+	b.buf.WriteString(s)
+	return nil
+}
+
+// text returns the text extracted until err stopped the extraction. Reaching the size
+// limit isn't an error, the text is just truncated.
+func (b *textBuilder) text(err error) (string, error) {
+	if err != nil && !errors.Is(err, errContentSizeLimit) {
+		return "", err
+	}
+	return strings.TrimSpace(b.buf.String()), nil
+}
+
+// truncateText truncates s to at most maxSize bytes without splitting a character. A
+// maxSize of 0 means no limit.
+func truncateText(s string, maxSize int) string {
+	if maxSize <= 0 || len(s) <= maxSize {
+		return s
+	}
+	for maxSize > 0 && !utf8.RuneStart(s[maxSize]) {
+		maxSize--
+	}
+	return s[:maxSize]
+}
+
+// readZipFile reads the file with the given name from a zip based document.
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return readZipEntry(f)
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", name, fs.ErrNotExist)
+}
+
+func readZipEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxDocumentPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentPartSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", f.Name, maxDocumentPartSize)
+	}
+	return data, nil
+}
+
+// newXMLDecoder returns a decoder of the XML parts of documents, which don't always
+// declare their encoding properly.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	d.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return d
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// createTestZip returns a zip archive holding the given files, in the given order.
+func createTestZip(t *testing.T, files ...string) []byte {
+	t.Helper()
+	require.Zero(t, len(files)%2, "files must be given as name and content pairs")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(files[i+1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "hello", truncateText("hello", 0))
+	assert.Equal(t, "hello", truncateText("hello", 10))
+	assert.Equal(t, "hel", truncateText("hello", 3))
+	// Characters aren't split.
+	assert.Equal(t, "h", truncateText("hé", 2))
+}
+
+func TestTextBuilder(t *testing.T) {
+	t.Run("size limit", func(t *testing.T) {
+		b := extractLimits{maxContentSize: 8}.newTextBuilder()
+		require.NoError(t, b.WriteString("hello "))
+		err := b.WriteString("world")
+		require.ErrorIs(t, err, errContentSizeLimit)
+
+		text, err := b.text(err)
+		require.NoError(t, err)
+		assert.Equal(t, "hello wo", text)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		b := extractLimits{timeout: time.Nanosecond}.newTextBuilder()
+		time.Sleep(time.Millisecond)
+		err := b.WriteString("hello")
+		require.ErrorIs(t, err, errExtractTimeout)
+
+		_, err = b.text(err)
+		require.ErrorIs(t, err, errExtractTimeout)
+	})
+}
+
+func TestExtractLimits(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	content := strings.Repeat("some searchable text\n", 100)
+
+	t.Run("files larger than the size limit are skipped", func(t *testing.T) {
+		text, err := Extract(logger, "test.txt", strings.NewReader(content), ExtractSettings{MaxFileSize: 100})
+		require.NoError(t, err)
+		assert.Empty(t, text)
+	})
+
+	t.Run("extracted text is truncated", func(t *testing.T) {
+		text, err := Extract(logger, "test.txt", strings.NewReader(content), ExtractSettings{MaxContentSize: 100})
+		require.NoError(t, err)
+		assert.Equal(t, content[:100], text)
+	})
+
+	t.Run("zip based documents are bounded too", func(t *testing.T) {
+		data := createTestZip(t, "content.xml", `<office:document-content><text:p>`+content+`</text:p></office:document-content>`)
+		text, err := Extract(logger, "test.ods", bytes.NewReader(data), ExtractSettings{MaxContentSize: 100})
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(text), 100)
+		assert.True(t, strings.HasPrefix(content, text))
+	})
+}
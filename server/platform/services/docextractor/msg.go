@@ -0,0 +1,192 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// The property streams of Outlook messages are named after the property tag, made of its
+// identifier and its type.
+const (
+	msgPropertyStreamPrefix = "__substg1.0_"
+	msgRecipientPrefix      = "__recip_version1.0_"
+	msgAttachmentPrefix     = "__attach_version1.0_"
+
+	msgTypeString8  = "001E"
+	msgTypeUnicode  = "001F"
+	msgTypeBinary   = "0102"
+	msgSubject      = "0037"
+	msgSenderName   = "0C1A"
+	msgDisplayTo    = "0E04"
+	msgDisplayCc    = "0E03"
+	msgBody         = "1000"
+	msgHTMLBody     = "1013"
+	msgRecipAddress = "39FE"
+	msgAttachName   = "3707"
+	msgAttachShort  = "3704"
+)
+
+// outlookMessageExtractor extracts the headers, the text and the attachment names of
+// Outlook .msg files.
+type outlookMessageExtractor struct {
+	limits extractLimits
+}
+
+// outlookMessage holds the properties of an Outlook message which are extracted.
+type outlookMessage struct {
+	properties  map[string]string
+	recipients  []string
+	attachments map[string]map[string]string
+}
+
+func (oe *outlookMessageExtractor) Name() string {
+	return "outlookMessageExtractor"
+}
+
+func (oe *outlookMessageExtractor) Match(filename string) bool {
+	return strings.ToLower(path.Ext(filename)) == ".msg"
+}
+
+func (oe *outlookMessageExtractor) Extract(filename string, r io.ReadSeeker) (string, error) {
+	data, err := oe.limits.readAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	msg, err := readOutlookMessage(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	text := oe.limits.newTextBuilder()
+	return text.text(msg.writeText(text))
+}
+
+func readOutlookMessage(r io.ReaderAt) (*outlookMessage, error) {
+	doc, err := mscfb.New(r)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &outlookMessage{
+		properties:  map[string]string{},
+		attachments: map[string]map[string]string{},
+	}
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		if !strings.HasPrefix(entry.Name, msgPropertyStreamPrefix) || len(entry.Name) != len(msgPropertyStreamPrefix)+8 {
+			continue
+		}
+		id, typ := entry.Name[12:16], entry.Name[16:20]
+		if typ != msgTypeUnicode && typ != msgTypeString8 && typ != msgTypeBinary {
+			continue
+		}
+
+		// Properties of embedded messages are nested deeper, and are skipped.
+		switch {
+		case len(entry.Path) == 0:
+			if _, ok := msg.properties[id]; ok {
+				continue
+			}
+		case len(entry.Path) == 1 && strings.HasPrefix(entry.Path[0], msgRecipientPrefix):
+			if id != msgRecipAddress {
+				continue
+			}
+		case len(entry.Path) == 1 && strings.HasPrefix(entry.Path[0], msgAttachmentPrefix):
+			if id != msgAttachName && id != msgAttachShort {
+				continue
+			}
+		default:
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(entry, maxDocumentPartSize))
+		if err != nil {
+			return nil, err
+		}
+		value := decodeMsgString(typ, data)
+
+		switch {
+		case len(entry.Path) == 0:
+			msg.properties[id] = value
+		case strings.HasPrefix(entry.Path[0], msgRecipientPrefix):
+			msg.recipients = append(msg.recipients, value)
+		default:
+			if msg.attachments[entry.Path[0]] == nil {
+				msg.attachments[entry.Path[0]] = map[string]string{}
+			}
+			msg.attachments[entry.Path[0]][id] = value
+		}
+	}
+	return msg, nil
+}
+
+// decodeMsgString decodes a string property. Binary properties, like the HTML body, hold
+// text in the encoding of the message, which is assumed to be compatible with UTF-8.
+func decodeMsgString(typ string, data []byte) string {
+	if typ != msgTypeUnicode {
+		return strings.TrimRight(string(data), "\x00")
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+}
+
+func (m *outlookMessage) writeText(text *textBuilder) error {
+	headers := []struct{ key, id string }{
+		{"Subject", msgSubject},
+		{"From", msgSenderName},
+		{"To", msgDisplayTo},
+		{"Cc", msgDisplayCc},
+	}
+	for _, header := range headers {
+		if value := strings.TrimSpace(m.properties[header.id]); value != "" {
+			if err := text.WriteString(header.key + ": " + value + "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	if len(m.recipients) > 0 {
+		if err := text.WriteString(strings.Join(m.recipients, " ") + "\n"); err != nil {
+			return err
+		}
+	}
+
+	if body := strings.TrimSpace(m.properties[msgBody]); body != "" {
+		if err := text.WriteString(body + "\n"); err != nil {
+			return err
+		}
+	} else if html := m.properties[msgHTMLBody]; html != "" {
+		if err := writeHTMLText(text, []byte(html)); err != nil {
+			return err
+		}
+	}
+
+	for _, storage := range slices.Sorted(maps.Keys(m.attachments)) {
+		attachment := m.attachments[storage]
+		name := attachment[msgAttachName]
+		if name == "" {
+			name = attachment[msgAttachShort]
+		}
+		if name == "" {
+			continue
+		}
+		if err := text.WriteString(name + "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
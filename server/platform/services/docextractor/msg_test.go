@@ -0,0 +1,216 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	cfbSectorSize     = 512
+	cfbMiniSectorSize = 64
+	cfbNoStream       = 0xFFFFFFFF
+	cfbEndOfChain     = 0xFFFFFFFE
+	cfbFATSector      = 0xFFFFFFFD
+)
+
+// testCFBEntry is a storage or a stream of a compound file written by createTestCFB.
+type testCFBEntry struct {
+	name   string
+	parent int // The index of the parent storage in the entries, or -1 for the root.
+	data   []byte
+	isDir  bool
+}
+
+// createTestCFB writes a version 3 compound file holding the given entries. Streams must be
+// small enough to be stored in the mini stream.
+func createTestCFB(t *testing.T, entries []testCFBEntry) []byte {
+	t.Helper()
+
+	// The mini stream holds the streams, padded to mini sectors.
+	var miniStream []byte
+	var miniFAT []uint32
+	starts := make([]uint32, len(entries))
+	for i, e := range entries {
+		if e.isDir {
+			continue
+		}
+		require.Less(t, len(e.data), 4096)
+		starts[i] = uint32(len(miniFAT))
+		n := max((len(e.data)+cfbMiniSectorSize-1)/cfbMiniSectorSize, 1)
+		for j := range n {
+			if j == n-1 {
+				miniFAT = append(miniFAT, cfbEndOfChain)
+			} else {
+				miniFAT = append(miniFAT, uint32(len(miniFAT)+1))
+			}
+		}
+		padded := make([]byte, n*cfbMiniSectorSize)
+		copy(padded, e.data)
+		miniStream = append(miniStream, padded...)
+	}
+	require.LessOrEqual(t, len(miniFAT), cfbSectorSize/4)
+
+	// Sector 0 holds the FAT, followed by the directory, the mini FAT and the mini stream.
+	dirSectors := (len(entries) + 1 + 3) / 4
+	miniFATSector := 1 + dirSectors
+	miniStreamSectors := (len(miniStream) + cfbSectorSize - 1) / cfbSectorSize
+	totalSectors := miniFATSector + 1 + miniStreamSectors
+	require.LessOrEqual(t, totalSectors, cfbSectorSize/4)
+
+	chain := func(fat []uint32, first, n int) {
+		for i := first; i < first+n; i++ {
+			fat[i] = uint32(i + 1)
+		}
+		fat[first+n-1] = cfbEndOfChain
+	}
+	fat := make([]uint32, cfbSectorSize/4)
+	for i := range fat {
+		fat[i] = cfbNoStream
+	}
+	fat[0] = cfbFATSector
+	chain(fat, 1, dirSectors)
+	chain(fat, miniFATSector, 1)
+	if miniStreamSectors > 0 {
+		chain(fat, miniFATSector+1, miniStreamSectors)
+	}
+
+	out := make([]byte, cfbSectorSize*(1+totalSectors))
+	le := binary.LittleEndian
+	sector := func(n int) []byte { return out[cfbSectorSize*(n+1) : cfbSectorSize*(n+2)] }
+
+	header := out[:cfbSectorSize]
+	le.PutUint64(header[0:], 0xE11AB1A1E011CFD0)
+	le.PutUint16(header[24:], 0x003E)
+	le.PutUint16(header[26:], 3)
+	le.PutUint16(header[28:], 0xFFFE)
+	le.PutUint16(header[30:], 9)
+	le.PutUint16(header[32:], 6)
+	le.PutUint32(header[44:], 1)
+	le.PutUint32(header[48:], 1)
+	le.PutUint32(header[56:], 4096)
+	le.PutUint32(header[60:], uint32(miniFATSector))
+	le.PutUint32(header[64:], 1)
+	le.PutUint32(header[68:], cfbEndOfChain)
+	for i := 76; i < cfbSectorSize; i += 4 {
+		le.PutUint32(header[i:], cfbNoStream)
+	}
+	le.PutUint32(header[76:], 0)
+
+	for i, v := range fat {
+		le.PutUint32(sector(0)[i*4:], v)
+	}
+	for i := range cfbSectorSize / 4 {
+		v := uint32(cfbNoStream)
+		if i < len(miniFAT) {
+			v = miniFAT[i]
+		}
+		le.PutUint32(sector(miniFATSector)[i*4:], v)
+	}
+	for i := range miniStreamSectors {
+		copy(sector(miniFATSector+1+i), miniStream[i*cfbSectorSize:])
+	}
+
+	// The children of a storage are chained through their right siblings.
+	dir := out[cfbSectorSize*2 : cfbSectorSize*(2+dirSectors)]
+	writeEntry := func(index int, name string, objectType byte, start uint32, size int) {
+		b := dir[index*128 : (index+1)*128]
+		units := utf16.Encode([]rune(name))
+		for i, u := range units {
+			le.PutUint16(b[i*2:], u)
+		}
+		le.PutUint16(b[64:], uint16((len(units)+1)*2))
+		b[66] = objectType
+		b[67] = 1
+		le.PutUint32(b[68:], cfbNoStream)
+		le.PutUint32(b[72:], cfbNoStream)
+		le.PutUint32(b[76:], cfbNoStream)
+		le.PutUint32(b[116:], start)
+		le.PutUint32(b[120:], uint32(size))
+	}
+	rootStart := uint32(cfbEndOfChain)
+	if miniStreamSectors > 0 {
+		rootStart = uint32(miniFATSector + 1)
+	}
+	writeEntry(0, "Root Entry", 5, rootStart, len(miniStream))
+	lastChild := map[int]int{}
+	for i, e := range entries {
+		if e.isDir {
+			writeEntry(i+1, e.name, 1, 0, 0)
+		} else {
+			writeEntry(i+1, e.name, 2, starts[i], len(e.data))
+		}
+
+		parent := e.parent + 1
+		if prev, ok := lastChild[parent]; ok {
+			le.PutUint32(dir[prev*128+72:], uint32(i+1))
+		} else {
+			le.PutUint32(dir[parent*128+76:], uint32(i+1))
+		}
+		lastChild[parent] = i + 1
+	}
+	for i := len(entries) + 1; i < dirSectors*4; i++ {
+		b := dir[i*128 : (i+1)*128]
+		le.PutUint32(b[68:], cfbNoStream)
+		le.PutUint32(b[72:], cfbNoStream)
+		le.PutUint32(b[76:], cfbNoStream)
+	}
+
+	return out
+}
+
+func utf16LE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, len(units)*2)
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[i*2:], u)
+	}
+	return b
+}
+
+func TestOutlookMessageExtractor(t *testing.T) {
+	extractor := outlookMessageExtractor{}
+	require.True(t, extractor.Match("message.msg"))
+
+	t.Run("plain text body", func(t *testing.T) {
+		data := createTestCFB(t, []testCFBEntry{
+			{name: "__substg1.0_0037001F", parent: -1, data: utf16LE("Launch plan")},
+			{name: "__substg1.0_0C1A001F", parent: -1, data: utf16LE("Jane Doe")},
+			{name: "__substg1.0_0E04001F", parent: -1, data: utf16LE("John Smith")},
+			{name: "__substg1.0_1000001F", parent: -1, data: utf16LE("The launch is on Monday.")},
+			{name: "__substg1.0_1013001E", parent: -1, data: []byte("<p>ignored</p>")},
+			{name: "__recip_version1.0_#00000000", parent: -1, isDir: true},
+			{name: "__substg1.0_39FE001F", parent: 5, data: utf16LE("john@example.com")},
+			{name: "__attach_version1.0_#00000000", parent: -1, isDir: true},
+			{name: "__substg1.0_3704001F", parent: 7, data: utf16LE("PLAN~1.PDF")},
+			{name: "__substg1.0_3707001F", parent: 7, data: utf16LE("plan.pdf")},
+		})
+
+		text, err := extractor.Extract("message.msg", bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Launch plan\nFrom: Jane Doe\nTo: John Smith\njohn@example.com\nThe launch is on Monday.\nplan.pdf", text)
+	})
+
+	t.Run("html body", func(t *testing.T) {
+		data := createTestCFB(t, []testCFBEntry{
+			{name: "__substg1.0_0037001E", parent: -1, data: []byte("Launch plan\x00")},
+			{name: "__substg1.0_10130102", parent: -1, data: []byte("<html><body><p>The launch is on <b>Monday</b>.</p></body></html>")},
+		})
+
+		text, err := extractor.Extract("message.msg", bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "Subject: Launch plan\nThe launch is on Monday.", text)
+	})
+
+	t.Run("not a compound file", func(t *testing.T) {
+		_, err := extractor.Extract("message.msg", bytes.NewReader([]byte("not a message")))
+		require.Error(t, err)
+	})
+}
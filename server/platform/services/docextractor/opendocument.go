@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// openDocumentExtractor extracts the text of OpenDocument spreadsheets and presentations.
+type openDocumentExtractor struct {
+	limits extractLimits
+}
+
+func (oe *openDocumentExtractor) Name() string {
+	return "openDocumentExtractor"
+}
+
+func (oe *openDocumentExtractor) Match(filename string) bool {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".ods", ".odp":
+		return true
+	}
+	return false
+}
+
+func (oe *openDocumentExtractor) Extract(filename string, r io.ReadSeeker) (string, error) {
+	zr, err := oe.limits.openZip(r)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := readZipFile(zr, "content.xml")
+	if err != nil {
+		return "", err
+	}
+
+	text := oe.limits.newTextBuilder()
+	return text.text(writeOpenDocumentText(text, data))
+}
+
+// writeOpenDocumentText writes the text of the content of an OpenDocument file. Paragraphs
+// are written one per line, and the cells of table rows are separated by tabs.
+func writeOpenDocumentText(text *textBuilder, data []byte) error {
+	var (
+		paragraph strings.Builder
+		rowCells  []string
+		cellDepth int
+	)
+
+	endParagraph := func() error {
+		s := strings.TrimSpace(paragraph.String())
+		paragraph.Reset()
+		if s == "" {
+			return nil
+		}
+		if cellDepth > 0 {
+			rowCells = append(rowCells, s)
+			return nil
+		}
+		return text.WriteString(s + "\n")
+	}
+
+	d := newXMLDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return endParagraph()
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "table-row":
+				rowCells = rowCells[:0]
+			case "table-cell", "covered-table-cell":
+				cellDepth++
+			case "s":
+				paragraph.WriteByte(' ')
+			case "tab":
+				paragraph.WriteByte('\t')
+			case "line-break":
+				paragraph.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "h":
+				if err := endParagraph(); err != nil {
+					return err
+				}
+			case "table-cell", "covered-table-cell":
+				cellDepth--
+			case "table-row":
+				if len(rowCells) == 0 || cellDepth > 0 {
+					continue
+				}
+				if err := text.WriteString(strings.Join(rowCells, "\t") + "\n"); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			paragraph.Write(t)
+		}
+	}
+}
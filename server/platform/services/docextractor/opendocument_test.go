@@ -0,0 +1,50 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDocumentExtractor(t *testing.T) {
+	extractor := openDocumentExtractor{}
+	require.True(t, extractor.Match("sheet.ods"))
+	require.True(t, extractor.Match("slides.odp"))
+	require.False(t, extractor.Match("text.odt"))
+
+	t.Run("spreadsheet", func(t *testing.T) {
+		data := createTestZip(t, "content.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet><table:table table:name="Sheet1">
+<table:table-row><table:table-cell><text:p>Name</text:p></table:table-cell><table:table-cell><text:p>Team</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell><text:p>Jane<text:s/>Doe</text:p></table:table-cell><table:table-cell table:number-columns-repeated="3"/><table:table-cell><text:p>Platform</text:p></table:table-cell></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`)
+
+		text, err := extractor.Extract("sheet.ods", bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "Name\tTeam\nJane Doe\tPlatform", text)
+	})
+
+	t.Run("presentation", func(t *testing.T) {
+		data := createTestZip(t, "content.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:draw="urn:oasis:names:tc:opendocument:xmlns:drawing:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:presentation>
+<draw:page draw:name="page1"><draw:frame><draw:text-box><text:h>Roadmap</text:h><text:p>First<text:tab/>quarter<text:line-break/>goals</text:p></draw:text-box></draw:frame></draw:page>
+</office:presentation></office:body></office:document-content>`)
+
+		text, err := extractor.Extract("slides.odp", bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "Roadmap\nFirst\tquarter\ngoals", text)
+	})
+
+	t.Run("missing content", func(t *testing.T) {
+		data := createTestZip(t, "mimetype", "application/vnd.oasis.opendocument.spreadsheet")
+		_, err := extractor.Extract("sheet.ods", bytes.NewReader(data))
+		require.Error(t, err)
+	})
+}
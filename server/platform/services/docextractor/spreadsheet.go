@@ -0,0 +1,232 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// spreadsheetExtractor extracts the text of the cells of Office Open XML workbooks.
+type spreadsheetExtractor struct {
+	limits extractLimits
+}
+
+func (se *spreadsheetExtractor) Name() string {
+	return "spreadsheetExtractor"
+}
+
+func (se *spreadsheetExtractor) Match(filename string) bool {
+	return strings.ToLower(path.Ext(filename)) == ".xlsx"
+}
+
+func (se *spreadsheetExtractor) Extract(filename string, r io.ReadSeeker) (string, error) {
+	zr, err := se.limits.openZip(r)
+	if err != nil {
+		return "", err
+	}
+
+	// Workbooks without any text cell have no shared strings.
+	var sharedStrings []string
+	if data, err := readZipFile(zr, "xl/sharedStrings.xml"); err == nil {
+		if sharedStrings, err = readSharedStrings(data); err != nil {
+			return "", err
+		}
+	}
+
+	text := se.limits.newTextBuilder()
+	return text.text(writeWorkbookText(text, zr, sharedStrings))
+}
+
+func writeWorkbookText(text *textBuilder, zr *zip.Reader, sharedStrings []string) error {
+	if data, err := readZipFile(zr, "xl/workbook.xml"); err == nil {
+		if err := writeSheetNames(text, data); err != nil {
+			return err
+		}
+	}
+
+	for _, sheet := range worksheets(zr) {
+		data, err := readZipEntry(sheet)
+		if err != nil {
+			return err
+		}
+		if err := writeSheetText(text, data, sharedStrings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// worksheets returns the worksheets of a workbook, in the order of their numbers.
+func worksheets(zr *zip.Reader) []*zip.File {
+	sheetNumber := func(f *zip.File) int {
+		n, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(f.Name, "xl/worksheets/sheet"), ".xml"))
+		return n
+	}
+
+	var sheets []*zip.File
+	for _, f := range zr.File {
+		if path.Dir(f.Name) == "xl/worksheets" && strings.HasPrefix(path.Base(f.Name), "sheet") && path.Ext(f.Name) == ".xml" {
+			sheets = append(sheets, f)
+		}
+	}
+	sort.SliceStable(sheets, func(i, j int) bool {
+		return sheetNumber(sheets[i]) < sheetNumber(sheets[j])
+	})
+	return sheets
+}
+
+// readSharedStrings reads the shared strings table of a workbook. Phonetic runs are
+// skipped, as they repeat the text they annotate.
+func readSharedStrings(data []byte) ([]string, error) {
+	var (
+		sharedStrings []string
+		current       strings.Builder
+		inText        bool
+		phoneticDepth int
+	)
+
+	d := newXMLDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return sharedStrings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "rPh":
+				phoneticDepth++
+			case "t":
+				inText = phoneticDepth == 0
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				sharedStrings = append(sharedStrings, current.String())
+			case "rPh":
+				phoneticDepth--
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+	}
+}
+
+func writeSheetNames(text *textBuilder, data []byte) error {
+	d := newXMLDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if t, ok := tok.(xml.StartElement); ok && t.Name.Local == "sheet" {
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "name" {
+					if err := text.WriteString(attr.Value + "\n"); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+}
+
+// writeSheetText writes the values of the cells of a worksheet, separated by tabs, one row
+// per line. Formulas are skipped in favour of their cached values.
+func writeSheetText(text *textBuilder, data []byte, sharedStrings []string) error {
+	var (
+		cellType  string
+		value     strings.Builder
+		inValue   bool
+		rowCells  []string
+		inlineStr bool
+	)
+
+	d := newXMLDecoder(data)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				rowCells = rowCells[:0]
+			case "c":
+				cellType = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+				value.Reset()
+			case "is":
+				inlineStr = true
+			case "v":
+				inValue = true
+			case "t":
+				inValue = inlineStr
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "c":
+				if cell := cellText(cellType, value.String(), sharedStrings); cell != "" {
+					rowCells = append(rowCells, cell)
+				}
+			case "is":
+				inlineStr = false
+			case "v", "t":
+				inValue = false
+			case "row":
+				if len(rowCells) == 0 {
+					continue
+				}
+				if err := text.WriteString(strings.Join(rowCells, "\t") + "\n"); err != nil {
+					return err
+				}
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+func cellText(cellType, value string, sharedStrings []string) string {
+	if cellType != "s" {
+		return strings.TrimSpace(value)
+	}
+
+	index, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || index < 0 || index >= len(sharedStrings) {
+		return ""
+	}
+	return strings.TrimSpace(sharedStrings[index])
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package docextractor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpreadsheetExtractor(t *testing.T) {
+	data := createTestZip(t,
+		"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets>
+<sheet name="Budget" sheetId="1"/><sheet name="Notes" sheetId="2"/>
+</sheets></workbook>`,
+		"xl/sharedStrings.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="3" uniqueCount="3">
+<si><t>Item</t></si>
+<si><t>Cost</t></si>
+<si><r><t>Quarterly </t></r><r><t>review</t></r><rPh sb="0" eb="1"><t>ignored</t></rPh></si>
+</sst>`,
+		"xl/worksheets/sheet10.xml", `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>last sheet</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/sheet1.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2"><f>SUM(C1:C9)</f><v>42.5</v></c></row>
+<row r="3"/>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml", `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>inline note</t></is></c></row></sheetData></worksheet>`,
+	)
+
+	extractor := spreadsheetExtractor{}
+	require.True(t, extractor.Match("budget.XLSX"))
+	require.False(t, extractor.Match("budget.xls"))
+
+	text, err := extractor.Extract("budget.xlsx", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "Budget\nNotes\nItem\tCost\nQuarterly review\t42.5\ninline note\nlast sheet", text)
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddings
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderLocal  = "local"
+)
+
+// EmbeddingProvider computes the embeddings of texts: vectors which are the more similar as
+// the meanings of the texts are.
+type EmbeddingProvider interface {
+	// Embed returns the embeddings of the texts, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model returns the name of the model computing the embeddings. The embeddings of
+	// different models can't be compared.
+	Model() string
+	// Dimensions returns the number of dimensions of the embeddings.
+	Dimensions() int
+}
+
+// Settings configure the provider returned by New.
+type Settings struct {
+	Provider   string
+	URL        string
+	APIKey     string
+	Model      string
+	Dimensions int
+	Timeout    time.Duration
+}
+
+// New returns the embedding provider for the given settings.
+func New(settings Settings) (EmbeddingProvider, error) {
+	switch settings.Provider {
+	case ProviderOpenAI:
+		return NewOpenAIProvider(settings.URL, settings.APIKey, settings.Model, settings.Dimensions, settings.Timeout), nil
+	case ProviderLocal:
+		return NewLocalProvider(), nil
+	}
+	return nil, errors.Errorf("unknown embedding provider %q", settings.Provider)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddings
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	localModel      = "local-hashing-256"
+	localDimensions = 256
+)
+
+// LocalProvider computes embeddings without any language model, by hashing the words of the
+// texts and their trigrams into the dimensions of the vectors. The texts sharing words are
+// similar, whatever their meaning, so it is only meant as a deterministic stand-in for tests
+// and development.
+type LocalProvider struct{}
+
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{}
+}
+
+func (p *LocalProvider) Model() string {
+	return localModel
+}
+
+func (p *LocalProvider) Dimensions() int {
+	return localDimensions
+}
+
+func (p *LocalProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = localEmbedding(text)
+	}
+	return vectors, nil
+}
+
+func localEmbedding(text string) []float32 {
+	vector := make([]float32, localDimensions)
+	add := func(feature string, weight float32) {
+		h := fnv.New32a()
+		h.Write([]byte(feature))
+		sum := h.Sum32()
+		if sum&1 == 0 {
+			weight = -weight
+		}
+		vector[(sum>>1)%localDimensions] += weight
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		add(word, 1)
+
+		// Trigrams make the words sharing a stem, such as deploy and deployment, similar.
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			add(string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
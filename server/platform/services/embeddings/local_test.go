@@ -0,0 +1,61 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cosineSimilarity(a, b []float32) float32 {
+	var dot float32
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+func TestLocalProvider(t *testing.T) {
+	provider := NewLocalProvider()
+
+	vectors, err := provider.Embed(context.Background(), []string{
+		"The deployment of the billing service failed",
+		"Billing service deploy failure",
+		"Lunch is served on the rooftop",
+		"",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+
+	for _, vector := range vectors {
+		assert.Len(t, vector, localDimensions)
+	}
+	assert.InDelta(t, 1, cosineSimilarity(vectors[0], vectors[0]), 0.0001)
+	assert.Greater(t, cosineSimilarity(vectors[0], vectors[1]), cosineSimilarity(vectors[0], vectors[2]))
+	assert.Equal(t, make([]float32, localDimensions), vectors[3])
+
+	t.Run("deterministic", func(t *testing.T) {
+		again, err := NewLocalProvider().Embed(context.Background(), []string{"The deployment of the billing service failed"})
+		require.NoError(t, err)
+		assert.Equal(t, vectors[0], again[0])
+	})
+}
+
+func TestNew(t *testing.T) {
+	provider, err := New(Settings{Provider: ProviderLocal})
+	require.NoError(t, err)
+	assert.Equal(t, localModel, provider.Model())
+	assert.Equal(t, localDimensions, provider.Dimensions())
+
+	provider, err = New(Settings{Provider: ProviderOpenAI, URL: "http://localhost/v1/embeddings", Model: "test-model", Dimensions: 1536})
+	require.NoError(t, err)
+	assert.Equal(t, "test-model", provider.Model())
+	assert.Equal(t, 1536, provider.Dimensions())
+
+	_, err = New(Settings{Provider: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxErrorBodySize is the size of the error responses read to report their message.
+const maxErrorBodySize = 4 * 1024
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// OpenAIProvider computes embeddings through an endpoint compatible with the embeddings API
+// of OpenAI, as served by OpenAI itself, Azure OpenAI, Ollama or vLLM.
+type OpenAIProvider struct {
+	url        string
+	apiKey     string
+	model      string
+	dimensions int
+	client     *http.Client
+}
+
+// NewOpenAIProvider returns a provider posting to the embeddings endpoint at url, such as
+// https://api.openai.com/v1/embeddings. The API key is optional for self-hosted endpoints.
+// The dimensions are those of the embeddings of the model, which are checked as they are
+// stored in a column of that size.
+func NewOpenAIProvider(url, apiKey, model string, dimensions int, timeout time.Duration) *OpenAIProvider {
+	return &OpenAIProvider{
+		url:        url,
+		apiKey:     apiKey,
+		model:      model,
+		dimensions: dimensions,
+		client:     &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *OpenAIProvider) Model() string {
+	return p.model
+}
+
+func (p *OpenAIProvider) Dimensions() int {
+	return p.dimensions
+}
+
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(openAIEmbeddingRequest{Model: p.model, Input: texts})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the embedding request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the embedding request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to request the embeddings")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp openAIEmbeddingResponse
+		if data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize)); json.Unmarshal(data, &errResp) == nil && errResp.Error != nil {
+			return nil, errors.Errorf("embedding request failed with status %d: %s", resp.StatusCode, errResp.Error.Message)
+		}
+		return nil, errors.Errorf("embedding request failed with status %d", resp.StatusCode)
+	}
+
+	var embeddingResp openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the embedding response")
+	}
+	if len(embeddingResp.Data) != len(texts) {
+		return nil, errors.Errorf("got %d embeddings for %d texts", len(embeddingResp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, data := range embeddingResp.Data {
+		if data.Index < 0 || data.Index >= len(texts) || vectors[data.Index] != nil {
+			return nil, errors.Errorf("invalid embedding index %d", data.Index)
+		}
+		if len(data.Embedding) != p.dimensions {
+			return nil, errors.Errorf("got an embedding of %d dimensions for index %d, the model %q is configured with %d", len(data.Embedding), data.Index, p.model, p.dimensions)
+		}
+		vectors[data.Index] = data.Embedding
+	}
+
+	return vectors, nil
+}
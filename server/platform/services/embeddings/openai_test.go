@@ -0,0 +1,99 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider(t *testing.T) {
+	t.Run("embeddings are returned in the order of the texts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+			var req openAIEmbeddingRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, "test-model", req.Model)
+			assert.Equal(t, []string{"first", "second"}, req.Input)
+
+			w.Write([]byte(`{"data": [
+				{"index": 1, "embedding": [0.3, 0.4]},
+				{"index": 0, "embedding": [0.1, 0.2]}
+			]}`))
+		}))
+		defer server.Close()
+
+		provider := NewOpenAIProvider(server.URL, "secret", "test-model", 2, time.Second)
+		assert.Equal(t, "test-model", provider.Model())
+		assert.Equal(t, 2, provider.Dimensions())
+
+		vectors, err := provider.Embed(context.Background(), []string{"first", "second"})
+		require.NoError(t, err)
+		assert.Equal(t, [][]float32{{0.1, 0.2}, {0.3, 0.4}}, vectors)
+	})
+
+	t.Run("no API key", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.Header.Get("Authorization"))
+			w.Write([]byte(`{"data": [{"index": 0, "embedding": [1]}]}`))
+		}))
+		defer server.Close()
+
+		_, err := NewOpenAIProvider(server.URL, "", "test-model", 1, time.Second).Embed(context.Background(), []string{"text"})
+		require.NoError(t, err)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "invalid api key"}}`))
+		}))
+		defer server.Close()
+
+		_, err := NewOpenAIProvider(server.URL, "wrong", "test-model", 1, time.Second).Embed(context.Background(), []string{"text"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid api key")
+	})
+
+	t.Run("missing embeddings", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": [{"index": 0, "embedding": [1]}]}`))
+		}))
+		defer server.Close()
+
+		_, err := NewOpenAIProvider(server.URL, "", "test-model", 1, time.Second).Embed(context.Background(), []string{"first", "second"})
+		require.Error(t, err)
+	})
+
+	t.Run("embeddings of other dimensions", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"data": [{"index": 0, "embedding": [0.1, 0.2, 0.3]}]}`))
+		}))
+		defer server.Close()
+
+		_, err := NewOpenAIProvider(server.URL, "", "test-model", 2, time.Second).Embed(context.Background(), []string{"text"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "3 dimensions")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-done
+		}))
+		defer server.Close()
+		defer close(done)
+
+		_, err := NewOpenAIProvider(server.URL, "", "test-model", 1, 50*time.Millisecond).Embed(context.Background(), []string{"text"})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package malwarescan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ClamdScanner scans files with a clamd daemon, streaming them with the INSTREAM command.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner returns a scanner for the clamd daemon listening at address, which is either
+// tcp://host:port or unix:///path/to/clamd.sock.
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid clamd address %q", address)
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		return &ClamdScanner{network: "tcp", address: u.Host, timeout: timeout}, nil
+	case "unix":
+		return &ClamdScanner{network: "unix", address: u.Path, timeout: timeout}, nil
+	}
+	return nil, errors.Errorf("invalid clamd address %q, the scheme must be tcp or unix", address)
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*Verdict, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to clamd")
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, errors.Wrap(err, "unable to set the clamd connection deadline")
+		}
+	}
+
+	if err = clamdInstream(conn, r); err != nil {
+		return nil, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(errors.Is(err, io.EOF) && reply != "") {
+		return nil, errors.Wrap(err, "unable to read the clamd reply")
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// clamdInstream sends the content of r to clamd, in chunks prefixed with their length and
+// followed by an empty chunk.
+func clamdInstream(w io.Writer, r io.Reader) error {
+	if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+		return errors.Wrap(err, "unable to send the clamd command")
+	}
+
+	buf := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, werr := w.Write(buf[:4+n]); werr != nil {
+				return errors.Wrap(werr, "unable to stream the file to clamd")
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "unable to read the file to scan")
+		}
+	}
+
+	if _, err := w.Write([]byte{0, 0, 0, 0}); err != nil {
+		return errors.Wrap(err, "unable to stream the file to clamd")
+	}
+	return nil
+}
+
+// parseClamdReply parses replies such as "stream: OK" or "stream: Eicar-Signature FOUND".
+func parseClamdReply(reply string) (*Verdict, error) {
+	result := reply
+	if i := strings.Index(reply, ": "); i >= 0 {
+		result = reply[i+2:]
+	}
+
+	switch {
+	case result == "OK":
+		return &Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &Verdict{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	}
+	return nil, errors.Errorf("clamd failed to scan the file: %s", reply)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package malwarescan
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts a single INSTREAM connection, and replies with the reply function of the streamed data.
+func fakeClamd(t *testing.T, reply func(data []byte) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err = io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			return
+		}
+
+		var data bytes.Buffer
+		for {
+			var size uint32
+			if err = binary.Read(conn, binary.BigEndian, &size); err != nil {
+				return
+			}
+			if size == 0 {
+				break
+			}
+			if _, err = io.CopyN(&data, conn, int64(size)); err != nil {
+				return
+			}
+		}
+		conn.Write([]byte(reply(data.Bytes()) + "\x00"))
+	}()
+
+	return "tcp://" + listener.Addr().String()
+}
+
+func clamdReply(data []byte) string {
+	if bytes.Contains(data, []byte("EICAR")) {
+		return "stream: Eicar-Signature FOUND"
+	}
+	return "stream: OK"
+}
+
+func TestClamdScanner(t *testing.T) {
+	t.Run("clean file", func(t *testing.T) {
+		scanner, err := NewClamdScanner(fakeClamd(t, clamdReply), time.Second)
+		require.NoError(t, err)
+
+		verdict, err := scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.NoError(t, err)
+		assert.False(t, verdict.Infected)
+		assert.Empty(t, verdict.Signature)
+	})
+
+	t.Run("infected file spanning several chunks", func(t *testing.T) {
+		var streamed int
+		scanner, err := NewClamdScanner(fakeClamd(t, func(data []byte) string {
+			streamed = len(data)
+			return clamdReply(data)
+		}), time.Second)
+		require.NoError(t, err)
+
+		data := append(bytes.Repeat([]byte{'a'}, 3*chunkSize), []byte("EICAR")...)
+		verdict, err := scanner.Scan(context.Background(), bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.True(t, verdict.Infected)
+		assert.Equal(t, "Eicar-Signature", verdict.Signature)
+		assert.Equal(t, len(data), streamed)
+	})
+
+	t.Run("scan error", func(t *testing.T) {
+		scanner, err := NewClamdScanner(fakeClamd(t, func([]byte) string {
+			return "INSTREAM size limit exceeded. ERROR"
+		}), time.Second)
+		require.NoError(t, err)
+
+		_, err = scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.Error(t, err)
+	})
+
+	t.Run("unreachable daemon", func(t *testing.T) {
+		scanner, err := NewClamdScanner("unix:///nonexistent/clamd.sock", time.Second)
+		require.NoError(t, err)
+
+		_, err = scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := NewClamdScanner("localhost:3310", time.Second)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package malwarescan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	icapDefaultPort = "1344"
+
+	// icapResponseHeaders are the HTTP response headers encapsulated in the RESPMOD requests.
+	icapResponseHeaders = "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nTransfer-Encoding: chunked\r\n\r\n"
+)
+
+// ICAPScanner scans files with an ICAP server (RFC 3507), sending them as the body of a RESPMOD request.
+type ICAPScanner struct {
+	host    string
+	url     string
+	timeout time.Duration
+}
+
+// NewICAPScanner returns a scanner for the ICAP service at address, such as icap://host:1344/avscan.
+func NewICAPScanner(address string, timeout time.Duration) (*ICAPScanner, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ICAP address %q", address)
+	}
+	if u.Scheme != "icap" || u.Hostname() == "" {
+		return nil, errors.Errorf("invalid ICAP address %q, it must look like icap://host:port/service", address)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), icapDefaultPort)
+	}
+	return &ICAPScanner{host: host, url: u.String(), timeout: timeout}, nil
+}
+
+func (s *ICAPScanner) Scan(ctx context.Context, r io.Reader) (*Verdict, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.host)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to connect to the ICAP server")
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, errors.Wrap(err, "unable to set the ICAP connection deadline")
+		}
+	}
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", s.url)
+	fmt.Fprintf(w, "Host: %s\r\n", s.host)
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Connection: close\r\n")
+	fmt.Fprintf(w, "Encapsulated: res-hdr=0, res-body=%d\r\n\r\n", len(icapResponseHeaders))
+	w.WriteString(icapResponseHeaders)
+	if err = icapWriteChunks(w, r); err != nil {
+		return nil, err
+	}
+	if err = w.Flush(); err != nil {
+		return nil, errors.Wrap(err, "unable to send the file to the ICAP server")
+	}
+
+	return readICAPResponse(bufio.NewReader(conn))
+}
+
+// icapWriteChunks writes the content of r with the HTTP chunked transfer encoding.
+func icapWriteChunks(w *bufio.Writer, r io.Reader) error {
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			fmt.Fprintf(w, "%x\r\n", n)
+			w.Write(buf[:n])
+			if _, werr := w.WriteString("\r\n"); werr != nil {
+				return errors.Wrap(werr, "unable to send the file to the ICAP server")
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "unable to read the file to scan")
+		}
+	}
+
+	_, err := w.WriteString("0\r\n\r\n")
+	return errors.Wrap(err, "unable to send the file to the ICAP server")
+}
+
+// readICAPResponse reads the status and headers of an ICAP response. A 204 means the file is
+// clean, while a 200 means the server replaced the file, which it only does when it's infected
+// since the request allows 204 responses.
+func readICAPResponse(r *bufio.Reader) (*Verdict, error) {
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the ICAP response")
+	}
+
+	proto, status, _ := strings.Cut(line, " ")
+	if !strings.HasPrefix(proto, "ICAP/") {
+		return nil, errors.Errorf("malformed ICAP response: %s", line)
+	}
+	codeString, _, _ := strings.Cut(status, " ")
+	code, err := strconv.Atoi(codeString)
+	if err != nil {
+		return nil, errors.Errorf("malformed ICAP response: %s", line)
+	}
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the ICAP response headers")
+	}
+
+	switch code {
+	case 204:
+		return &Verdict{}, nil
+	case 200:
+		return &Verdict{Infected: true, Signature: icapSignature(header)}, nil
+	}
+	return nil, errors.Errorf("the ICAP server failed to scan the file: %s", line)
+}
+
+// icapSignature returns the name of the threat from the headers the most common ICAP servers use to report it.
+func icapSignature(header textproto.MIMEHeader) string {
+	if infection := header.Get("X-Infection-Found"); infection != "" {
+		for _, field := range strings.Split(infection, ";") {
+			if name, value, ok := strings.Cut(strings.TrimSpace(field), "="); ok && name == "Threat" {
+				return value
+			}
+		}
+	}
+	if virus := header.Get("X-Virus-Id"); virus != "" {
+		return virus
+	}
+	return ""
+}
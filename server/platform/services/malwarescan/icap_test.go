@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package malwarescan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeICAP accepts a single RESPMOD request, and replies with the response function of the encapsulated body.
+func fakeICAP(t *testing.T, response func(body []byte) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		tp := textproto.NewReader(r)
+		line, err := tp.ReadLine()
+		if err != nil || !strings.HasPrefix(line, "RESPMOD icap://") {
+			conn.Write([]byte("ICAP/1.0 400 Bad Request\r\n\r\n"))
+			return
+		}
+		if _, err = tp.ReadMIMEHeader(); err != nil {
+			return
+		}
+		// Skip the encapsulated HTTP response headers.
+		if _, err = tp.ReadLine(); err != nil {
+			return
+		}
+		if _, err = tp.ReadMIMEHeader(); err != nil {
+			return
+		}
+		body, err := io.ReadAll(httputil.NewChunkedReader(r))
+		if err != nil {
+			return
+		}
+		conn.Write([]byte(response(body)))
+	}()
+
+	return "icap://" + listener.Addr().String() + "/avscan"
+}
+
+func icapResponse(body []byte) string {
+	if bytes.Contains(body, []byte("EICAR")) {
+		return "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Signature;\r\nEncapsulated: null-body=0\r\n\r\n"
+	}
+	return "ICAP/1.0 204 No Content\r\n\r\n"
+}
+
+func TestICAPScanner(t *testing.T) {
+	t.Run("clean file", func(t *testing.T) {
+		scanner, err := NewICAPScanner(fakeICAP(t, icapResponse), time.Second)
+		require.NoError(t, err)
+
+		verdict, err := scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.NoError(t, err)
+		assert.False(t, verdict.Infected)
+	})
+
+	t.Run("infected file spanning several chunks", func(t *testing.T) {
+		var received int
+		scanner, err := NewICAPScanner(fakeICAP(t, func(body []byte) string {
+			received = len(body)
+			return icapResponse(body)
+		}), time.Second)
+		require.NoError(t, err)
+
+		data := append(bytes.Repeat([]byte{'a'}, 3*chunkSize), []byte("EICAR")...)
+		verdict, err := scanner.Scan(context.Background(), bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.True(t, verdict.Infected)
+		assert.Equal(t, "Eicar-Signature", verdict.Signature)
+		assert.Equal(t, len(data), received)
+	})
+
+	t.Run("virus id header", func(t *testing.T) {
+		scanner, err := NewICAPScanner(fakeICAP(t, func([]byte) string {
+			return "ICAP/1.0 200 OK\r\nX-Virus-ID: Win.Test.EICAR_HDB-1\r\nEncapsulated: null-body=0\r\n\r\n"
+		}), time.Second)
+		require.NoError(t, err)
+
+		verdict, err := scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.NoError(t, err)
+		assert.True(t, verdict.Infected)
+		assert.Equal(t, "Win.Test.EICAR_HDB-1", verdict.Signature)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		scanner, err := NewICAPScanner(fakeICAP(t, func([]byte) string {
+			return "ICAP/1.0 500 Server Error\r\n\r\n"
+		}), time.Second)
+		require.NoError(t, err)
+
+		_, err = scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.Error(t, err)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := NewICAPScanner("http://localhost:1344/avscan", time.Second)
+		require.Error(t, err)
+	})
+}
+
+func TestNew(t *testing.T) {
+	scanner, err := New(Settings{})
+	require.NoError(t, err)
+	assert.Nil(t, scanner)
+
+	scanner, err = New(Settings{Driver: DriverClamd, Address: "tcp://localhost:3310"})
+	require.NoError(t, err)
+	assert.IsType(t, &ClamdScanner{}, scanner)
+
+	scanner, err = New(Settings{Driver: DriverICAP, Address: "icap://localhost/avscan"})
+	require.NoError(t, err)
+	assert.IsType(t, &ICAPScanner{}, scanner)
+
+	_, err = New(Settings{Driver: "unknown"})
+	require.Error(t, err)
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package malwarescan
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DriverClamd = "clamd"
+	DriverICAP  = "icap"
+
+	// chunkSize is the size of the chunks files are streamed to the scanners in.
+	chunkSize = 64 * 1024
+)
+
+// Verdict is the result of scanning a file.
+type Verdict struct {
+	Infected bool
+	// Signature is the name of the malware found in the file, when the scanner reports it.
+	Signature string
+}
+
+// Scanner scans files for malware.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (*Verdict, error)
+}
+
+// Settings configure the scanner returned by New.
+type Settings struct {
+	Driver  string
+	Address string
+	Timeout time.Duration
+}
+
+// New returns the scanner for the given settings, or nil when scanning is disabled.
+func New(settings Settings) (Scanner, error) {
+	switch settings.Driver {
+	case "":
+		return nil, nil
+	case DriverClamd:
+		return NewClamdScanner(settings.Address, settings.Timeout)
+	case DriverICAP:
+		return NewICAPScanner(settings.Address, settings.Timeout)
+	}
+	return nil, errors.Errorf("unknown malware scanner driver %q", settings.Driver)
+}
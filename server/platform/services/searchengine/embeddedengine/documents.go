@@ -0,0 +1,210 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddedengine
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+// The indexes of the engine, each stored in its own directory.
+const (
+	IndexPosts    = "posts"
+	IndexFiles    = "files"
+	IndexChannels = "channels"
+	IndexUsers    = "users"
+)
+
+var allIndexes = []string{IndexPosts, IndexFiles, IndexChannels, IndexUsers}
+
+// postTypeDefault is the type indexed for regular posts, whose type is empty.
+const postTypeDefault = "default"
+
+// The fields of the documents, named after the fields of the Elasticsearch indexes.
+const (
+	fieldTeamID                     = "team_id"
+	fieldChannelID                  = "channel_id"
+	fieldUserID                     = "user_id"
+	fieldCreatorID                  = "creator_id"
+	fieldPostID                     = "post_id"
+	fieldType                       = "type"
+	fieldHashtags                   = "hashtags"
+	fieldMessage                    = "message"
+	fieldAttachments                = "attachments"
+	fieldContent                    = "content"
+	fieldName                       = "name"
+	fieldExtension                  = "extension"
+	fieldUserIDs                    = "user_ids"
+	fieldTeamMemberIDs              = "team_member_ids"
+	fieldNameSuggestions            = "name_suggestions"
+	fieldSuggestionsWithFullname    = "suggestions_with_fullname"
+	fieldSuggestionsWithoutFullname = "suggestions_without_fullname"
+	fieldRoles                      = "roles"
+	fieldCreateAt                   = "create_at"
+	fieldDeleteAt                   = "delete_at"
+	fieldAttributes                 = "attributes"
+)
+
+// The attributes of the posts, filtered by the is:pinned, has:file, has:link, in:thread and
+// from:bot search modifiers.
+const (
+	attributePinned = "pinned"
+	attributeFile   = "file"
+	attributeLink   = "link"
+	attributeThread = "thread"
+	attributeBot    = "bot"
+)
+
+var linkRegex = regexp.MustCompile(`(?i)(https?|ftp)://|www\.`)
+
+func keywords(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func normalizedKeywords(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, index.NormalizeKeyword(v))
+	}
+	return result
+}
+
+func postDocument(post *model.Post, teamID string) *index.Document {
+	postType := post.Type
+	if postType == model.PostTypeDefault {
+		postType = postTypeDefault
+	}
+
+	var attachments []string
+	for _, attachment := range post.Attachments() {
+		if attachment != nil && attachment.Text != "" {
+			attachments = append(attachments, attachment.Text)
+		}
+	}
+
+	var attributes []string
+	if post.IsPinned {
+		attributes = append(attributes, attributePinned)
+	}
+	if len(post.FileIds) > 0 || len(post.Filenames) > 0 {
+		attributes = append(attributes, attributeFile)
+	}
+	if linkRegex.MatchString(post.Message) {
+		attributes = append(attributes, attributeLink)
+	}
+	if post.RootId != "" {
+		attributes = append(attributes, attributeThread)
+	}
+	if post.GetProp(model.PostPropsFromBot) == "true" {
+		attributes = append(attributes, attributeBot)
+	}
+
+	return &index.Document{
+		ID: post.Id,
+		Keywords: map[string][]string{
+			fieldTeamID:     keywords(teamID),
+			fieldChannelID:  keywords(post.ChannelId),
+			fieldUserID:     keywords(post.UserId),
+			fieldType:       keywords(postType),
+			fieldHashtags:   normalizedKeywords(strings.Fields(post.Hashtags)),
+			fieldAttributes: attributes,
+		},
+		Text: map[string]string{
+			fieldMessage:     post.Message,
+			fieldAttachments: strings.Join(attachments, " "),
+		},
+		Numbers: map[string]int64{
+			fieldCreateAt: post.CreateAt,
+		},
+	}
+}
+
+func fileDocument(file *model.FileInfo, channelID, content string) *index.Document {
+	nameWords := strings.NewReplacer("-", " ", ".", " ").Replace(file.Name)
+	return &index.Document{
+		ID: file.Id,
+		Keywords: map[string][]string{
+			fieldChannelID: keywords(channelID),
+			fieldCreatorID: keywords(file.CreatorId),
+			fieldPostID:    keywords(file.PostId),
+			fieldExtension: keywords(strings.ToLower(file.Extension)),
+		},
+		Text: map[string]string{
+			fieldContent: content,
+			fieldName:    file.Name + " " + nameWords,
+		},
+		Numbers: map[string]int64{
+			fieldCreateAt: file.CreateAt,
+		},
+	}
+}
+
+func channelDocument(channel *model.Channel, userIDs, teamMemberIDs []string) *index.Document {
+	suggestions := append(
+		searchengine.GetSuggestionInputsSplitBy(channel.DisplayName, " "),
+		searchengine.GetSuggestionInputsSplitByMultiple(channel.Name, []string{"-", "_"})...,
+	)
+
+	return &index.Document{
+		ID: channel.Id,
+		Keywords: map[string][]string{
+			fieldType:            keywords(string(channel.Type)),
+			fieldTeamID:          keywords(channel.TeamId),
+			fieldUserIDs:         userIDs,
+			fieldTeamMemberIDs:   teamMemberIDs,
+			fieldNameSuggestions: normalizedKeywords(suggestions),
+		},
+		Numbers: map[string]int64{
+			fieldDeleteAt: channel.DeleteAt,
+		},
+	}
+}
+
+func userDocument(user *model.User, teamsIDs, channelsIDs []string) *index.Document {
+	suggestions := searchengine.GetSuggestionInputsSplitByMultiple(user.Username, []string{".", "-", "_"})
+	if user.Nickname != "" {
+		suggestions = append(suggestions, searchengine.GetSuggestionInputsSplitBy(user.Nickname, " ")...)
+	}
+	withoutFullname := normalizedKeywords(suggestions)
+
+	if fullname := strings.TrimSpace(user.FirstName + " " + user.LastName); fullname != "" {
+		suggestions = append(suggestions, searchengine.GetSuggestionInputsSplitBy(fullname, " ")...)
+	}
+
+	return &index.Document{
+		ID: user.Id,
+		Keywords: map[string][]string{
+			fieldSuggestionsWithFullname:    normalizedKeywords(suggestions),
+			fieldSuggestionsWithoutFullname: withoutFullname,
+			fieldRoles:                      user.GetRoles(),
+			fieldTeamID:                     teamsIDs,
+			fieldChannelID:                  channelsIDs,
+		},
+		Numbers: map[string]int64{
+			fieldDeleteAt: user.DeleteAt,
+		},
+	}
+}
+
+func userDocumentForIndexing(user *model.UserForIndexing) *index.Document {
+	return userDocument(&model.User{
+		Id:        user.Id,
+		Username:  user.Username,
+		Nickname:  user.Nickname,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Roles:     user.Roles,
+		DeleteAt:  user.DeleteAt,
+	}, user.TeamsIds, user.ChannelsIds)
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Package embeddedengine implements a search engine storing its indexes on disk, for the
+// deployments without an Elasticsearch or OpenSearch cluster.
+//
+// A single node of a cluster writes the indexes: the one holding the lock of the index
+// directory, which the nodes share. The other nodes read the indexes and save their changes in
+// the index directory, where the writer picks them up. When the writer goes away, another node
+// takes the lock over, along with the changes the previous writer didn't apply.
+package embeddedengine
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+const (
+	engineName = "embedded"
+	lockFile   = "write.lock"
+
+	// refreshInterval is the time between the commits of the writer, and between the
+	// refreshes of the readers. It bounds the time for a change to be searchable.
+	refreshInterval = time.Second
+	// lockRetryInterval is the time between the attempts of a reader to become the writer.
+	lockRetryInterval = 10 * time.Second
+)
+
+// Platform gives access to the services of the server used by the engine.
+type Platform interface {
+	Config() *model.Config
+	Log() mlog.LoggerIFace
+	Metrics() einterfaces.MetricsInterface
+}
+
+type EmbeddedEngine struct {
+	platform Platform
+
+	// lifecycleMutex serializes Start and Stop.
+	lifecycleMutex sync.Mutex
+
+	// mutex guards the indexes and the lock, which change when the engine starts, stops or
+	// becomes the writer.
+	mutex   sync.RWMutex
+	indexes map[string]*index.Index
+	lock    *index.Lock
+	dir     string
+
+	stop  chan struct{}
+	done  chan struct{}
+	ready atomic.Bool
+}
+
+func NewEmbeddedEngine(platform Platform) *EmbeddedEngine {
+	return &EmbeddedEngine{platform: platform}
+}
+
+func (e *EmbeddedEngine) UpdateConfig(cfg *model.Config) {
+	// Not needed, the configuration is always read from the platform.
+}
+
+func (e *EmbeddedEngine) GetName() string {
+	return engineName
+}
+
+func (e *EmbeddedEngine) IsEnabled() bool {
+	return *e.platform.Config().EmbeddedSearchSettings.EnableIndexing
+}
+
+func (e *EmbeddedEngine) IsActive() bool {
+	return *e.platform.Config().EmbeddedSearchSettings.EnableIndexing && e.ready.Load()
+}
+
+func (e *EmbeddedEngine) IsIndexingEnabled() bool {
+	return *e.platform.Config().EmbeddedSearchSettings.EnableIndexing
+}
+
+func (e *EmbeddedEngine) IsSearchEnabled() bool {
+	return *e.platform.Config().EmbeddedSearchSettings.EnableSearching
+}
+
+func (e *EmbeddedEngine) IsAutocompletionEnabled() bool {
+	return *e.platform.Config().EmbeddedSearchSettings.EnableAutocomplete
+}
+
+func (e *EmbeddedEngine) IsIndexingSync() bool {
+	return false
+}
+
+func (e *EmbeddedEngine) GetVersion() int {
+	return index.Version
+}
+
+func (e *EmbeddedEngine) GetFullVersion() string {
+	return ""
+}
+
+func (e *EmbeddedEngine) GetPlugins() []string {
+	return nil
+}
+
+// IsWriter returns whether this node writes the indexes.
+func (e *EmbeddedEngine) IsWriter() bool {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+	return e.ready.Load() && e.lock != nil
+}
+
+func (e *EmbeddedEngine) Start() *model.AppError {
+	if !*e.platform.Config().EmbeddedSearchSettings.EnableIndexing {
+		return nil
+	}
+
+	e.lifecycleMutex.Lock()
+	defer e.lifecycleMutex.Unlock()
+
+	if e.ready.Load() {
+		return nil
+	}
+
+	dir := *e.platform.Config().EmbeddedSearchSettings.IndexDir
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return model.NewAppError("EmbeddedEngine.Start", "embedded_search.start.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	lock, err := index.TryLock(filepath.Join(dir, lockFile))
+	if err != nil {
+		return model.NewAppError("EmbeddedEngine.Start", "embedded_search.start.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	indexes, err := openIndexes(dir, lock != nil)
+	if err != nil {
+		if lock != nil {
+			lock.Unlock()
+		}
+		return model.NewAppError("EmbeddedEngine.Start", "embedded_search.start.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	e.mutex.Lock()
+	e.indexes = indexes
+	e.lock = lock
+	e.dir = dir
+	e.mutex.Unlock()
+
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go e.run(e.stop, e.done)
+
+	e.ready.Store(true)
+	e.platform.Log().Info("Embedded search engine started", mlog.String("index_dir", dir), mlog.Bool("writer", lock != nil))
+
+	return nil
+}
+
+func (e *EmbeddedEngine) Stop() *model.AppError {
+	e.lifecycleMutex.Lock()
+	defer e.lifecycleMutex.Unlock()
+
+	if !e.ready.Load() {
+		return model.NewAppError("EmbeddedEngine.Stop", "embedded_search.stop.already_stopped.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	e.ready.Store(false)
+	close(e.stop)
+	<-e.done
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.lock != nil {
+		// Committing the changes still pending, which would be lost otherwise.
+		e.commitIndexes()
+	}
+	closeIndexes(e.indexes)
+	e.indexes = nil
+	if e.lock != nil {
+		if err := e.lock.Unlock(); err != nil {
+			e.platform.Log().Warn("Failed to release the lock of the embedded search indexes", mlog.Err(err))
+		}
+		e.lock = nil
+	}
+
+	return nil
+}
+
+func openIndexes(dir string, writable bool) (map[string]*index.Index, error) {
+	indexes := make(map[string]*index.Index, len(allIndexes))
+	for _, name := range allIndexes {
+		idx, err := index.Open(filepath.Join(dir, name), writable)
+		if err != nil {
+			closeIndexes(indexes)
+			return nil, err
+		}
+		indexes[name] = idx
+	}
+	return indexes, nil
+}
+
+func closeIndexes(indexes map[string]*index.Index) {
+	for _, idx := range indexes {
+		idx.Close()
+	}
+}
+
+// run commits or refreshes the indexes until the engine stops, and makes the engine the writer
+// when the lock becomes available.
+func (e *EmbeddedEngine) run(stop, done chan struct{}) {
+	defer close(done)
+
+	refreshTicker := time.NewTicker(refreshInterval)
+	defer refreshTicker.Stop()
+	lockTicker := time.NewTicker(lockRetryInterval)
+	defer lockTicker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-refreshTicker.C:
+			e.mutex.RLock()
+			if e.lock != nil {
+				e.commitIndexes()
+			} else {
+				e.refreshIndexes()
+			}
+			e.mutex.RUnlock()
+		case <-lockTicker.C:
+			e.becomeWriter()
+		}
+	}
+}
+
+// commitIndexes applies the changes saved by the other nodes and commits the pending changes
+// of the indexes. The caller must hold the mutex.
+func (e *EmbeddedEngine) commitIndexes() error {
+	applied, pendingErr := e.applyPendingChanges()
+
+	var errs []error
+	for name, idx := range e.indexes {
+		if err := idx.Commit(); err != nil {
+			e.platform.Log().Error("Failed to commit the embedded search index", mlog.String("index", name), mlog.Err(err))
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		// The saved changes are applied again by the next commit.
+		return errors.Join(append(errs, pendingErr)...)
+	}
+
+	for _, path := range applied {
+		if err := os.Remove(path); err != nil {
+			e.platform.Log().Warn("Failed to remove the applied changes of the embedded search indexes", mlog.String("path", path), mlog.Err(err))
+		}
+	}
+	return pendingErr
+}
+
+// applyPendingChanges applies the changes saved by the other nodes, in the order they were
+// made, and returns the paths of those applied. The caller must hold the mutex.
+func (e *EmbeddedEngine) applyPendingChanges() ([]string, error) {
+	paths, err := listPendingChanges(e.dir)
+	if err != nil {
+		e.platform.Log().Error("Failed to list the pending changes of the embedded search indexes", mlog.Err(err))
+		return nil, err
+	}
+
+	applied := make([]string, 0, len(paths))
+	for _, path := range paths {
+		changes, err := readPendingChanges(path)
+		if err != nil {
+			// The changes can never be applied, so they don't hold up the following ones.
+			e.platform.Log().Error("Dropping the pending changes of the embedded search indexes", mlog.String("path", path), mlog.Err(err))
+			applied = append(applied, path)
+			continue
+		}
+
+		idx, ok := e.indexes[changes.Index]
+		if !ok {
+			e.platform.Log().Warn("Dropping the pending changes of an unknown embedded search index", mlog.String("index", changes.Index))
+			applied = append(applied, path)
+			continue
+		}
+		if err := idx.Apply(changes.Operations...); err != nil {
+			// The following changes wait for these ones, so that they are applied in order.
+			e.platform.Log().Error("Failed to apply the pending changes of the embedded search index", mlog.String("index", changes.Index), mlog.Err(err))
+			return applied, err
+		}
+		applied = append(applied, path)
+	}
+
+	return applied, nil
+}
+
+// refreshIndexes loads the last commit of the indexes. The caller must hold the mutex.
+func (e *EmbeddedEngine) refreshIndexes() error {
+	var errs []error
+	for name, idx := range e.indexes {
+		if err := idx.Refresh(); err != nil {
+			e.platform.Log().Warn("Failed to refresh the embedded search index", mlog.String("index", name), mlog.Err(err))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// becomeWriter reopens the indexes for writing when the lock of the writer is available.
+func (e *EmbeddedEngine) becomeWriter() {
+	e.mutex.RLock()
+	isWriter, dir := e.lock != nil, e.dir
+	e.mutex.RUnlock()
+	if isWriter {
+		return
+	}
+
+	lock, err := index.TryLock(filepath.Join(dir, lockFile))
+	if err != nil {
+		e.platform.Log().Warn("Failed to acquire the lock of the embedded search indexes", mlog.Err(err))
+		return
+	}
+	if lock == nil {
+		return
+	}
+
+	indexes, err := openIndexes(dir, true)
+	if err != nil {
+		lock.Unlock()
+		e.platform.Log().Error("Failed to open the embedded search indexes for writing", mlog.Err(err))
+		return
+	}
+
+	e.mutex.Lock()
+	previous := e.indexes
+	e.indexes = indexes
+	e.lock = lock
+	e.mutex.Unlock()
+
+	closeIndexes(previous)
+	e.platform.Log().Info("This node is now writing the embedded search indexes", mlog.String("index_dir", dir))
+}
+
+// apply changes an index: directly on the writer, or by saving the operations for the
+// writer. The caller must hold the mutex.
+func (e *EmbeddedEngine) apply(name string, ops ...*index.Operation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	if e.lock != nil {
+		return e.indexes[name].Apply(ops...)
+	}
+
+	return savePendingChanges(e.dir, &pendingChanges{Index: name, Operations: ops})
+}
+
+// Commit writes the pending changes of the indexes when this node is the writer.
+func (e *EmbeddedEngine) Commit() *model.AppError {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if !e.ready.Load() {
+		return notStartedError("EmbeddedEngine.Commit")
+	}
+	if e.lock == nil {
+		return nil
+	}
+	if err := e.commitIndexes(); err != nil {
+		return model.NewAppError("EmbeddedEngine.Commit", "embedded_search.index.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+func notStartedError(where string) *model.AppError {
+	return model.NewAppError(where, "embedded_search.not_started.app_error", nil, "", http.StatusInternalServerError)
+}
+
+// change applies the operations to an index.
+func (e *EmbeddedEngine) change(where, name string, ops ...*index.Operation) *model.AppError {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if !e.ready.Load() {
+		return notStartedError(where)
+	}
+	if err := e.apply(name, ops...); err != nil {
+		return model.NewAppError(where, "embedded_search.index.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
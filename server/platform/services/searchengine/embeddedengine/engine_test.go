@@ -0,0 +1,410 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddedengine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+)
+
+type testPlatform struct {
+	cfg    *model.Config
+	logger mlog.LoggerIFace
+}
+
+func (p *testPlatform) Config() *model.Config                 { return p.cfg }
+func (p *testPlatform) Log() mlog.LoggerIFace                 { return p.logger }
+func (p *testPlatform) Metrics() einterfaces.MetricsInterface { return nil }
+
+func newTestEngine(t *testing.T, dir string) *EmbeddedEngine {
+	t.Helper()
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	cfg.EmbeddedSearchSettings.EnableIndexing = model.NewPointer(true)
+	cfg.EmbeddedSearchSettings.EnableSearching = model.NewPointer(true)
+	cfg.EmbeddedSearchSettings.IndexDir = model.NewPointer(dir)
+
+	engine := NewEmbeddedEngine(&testPlatform{cfg: cfg, logger: mlog.CreateConsoleTestLogger(t)})
+	require.Nil(t, engine.Start())
+	t.Cleanup(func() {
+		if engine.IsActive() {
+			engine.Stop()
+		}
+	})
+	return engine
+}
+
+func TestEngineLifecycle(t *testing.T) {
+	engine := newTestEngine(t, t.TempDir())
+	assert.True(t, engine.IsActive())
+	assert.True(t, engine.IsWriter())
+	assert.Equal(t, "embedded", engine.GetName())
+
+	require.Nil(t, engine.Stop())
+	assert.False(t, engine.IsActive())
+	assert.NotNil(t, engine.Stop())
+	assert.NotNil(t, engine.IndexPost(&model.Post{Id: model.NewId()}, ""))
+}
+
+func TestSearchPosts(t *testing.T) {
+	rctx := request.TestContext(t)
+	engine := newTestEngine(t, t.TempDir())
+
+	channel := &model.Channel{Id: model.NewId()}
+	otherChannel := &model.Channel{Id: model.NewId()}
+	user1, user2 := model.NewId(), model.NewId()
+	day := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC).UnixMilli()
+
+	posts := map[string]*model.Post{
+		"deploy":   {Id: model.NewId(), ChannelId: channel.Id, UserId: user1, CreateAt: day, Message: "Deploying the new release to production", IsPinned: true, FileIds: model.StringArray{model.NewId()}},
+		"phrase":   {Id: model.NewId(), ChannelId: channel.Id, UserId: user2, CreateAt: day + 1, Message: "The release notes are ready at https://example.com", RootId: model.NewId()},
+		"hashtag":  {Id: model.NewId(), ChannelId: channel.Id, UserId: user1, CreateAt: day + 2, Message: "Notes for #Planning", Hashtags: "#Planning"},
+		"cjk":      {Id: model.NewId(), ChannelId: channel.Id, UserId: user2, CreateAt: day + 3, Message: "明日は東京で会議です"},
+		"other":    {Id: model.NewId(), ChannelId: otherChannel.Id, UserId: user1, CreateAt: day + 4, Message: "Another release elsewhere"},
+		"system":   {Id: model.NewId(), ChannelId: channel.Id, UserId: user1, CreateAt: day + 5, Message: "release joined", Type: model.PostTypeJoinChannel},
+		"old":      {Id: model.NewId(), ChannelId: channel.Id, UserId: user2, CreateAt: day - 48*time.Hour.Milliseconds(), Message: "An old release"},
+		"attached": {Id: model.NewId(), ChannelId: channel.Id, UserId: user2, CreateAt: day + 6, Message: "See attachment"},
+	}
+	posts["attached"].AddProp(model.PostPropsAttachments, []*model.SlackAttachment{{Text: "Quarterly budget"}})
+	posts["old"].AddProp(model.PostPropsFromBot, "true")
+	for _, post := range posts {
+		require.Nil(t, engine.IndexPost(post, model.NewId()))
+	}
+	require.Nil(t, engine.RefreshIndexes(rctx))
+
+	search := func(t *testing.T, params []*model.SearchParams, channels ...*model.Channel) ([]string, model.PostSearchMatches) {
+		t.Helper()
+		if len(channels) == 0 {
+			channels = []*model.Channel{channel}
+		}
+		ids, matches, appErr := engine.SearchPosts(channels, params, 0, 20)
+		require.Nil(t, appErr)
+		return ids, matches
+	}
+
+	t.Run("terms", func(t *testing.T) {
+		ids, matches := search(t, model.ParseSearchParams("release", 0))
+		assert.Equal(t, []string{posts["phrase"].Id, posts["deploy"].Id, posts["old"].Id}, ids)
+		assert.Equal(t, []string{"release"}, matches[posts["deploy"].Id])
+	})
+
+	t.Run("stemmed terms", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("deploy", 0))
+		assert.Equal(t, []string{posts["deploy"].Id}, ids)
+	})
+
+	t.Run("all terms", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("release production", 0))
+		assert.Equal(t, []string{posts["deploy"].Id}, ids)
+	})
+
+	t.Run("any term", func(t *testing.T) {
+		params := model.ParseSearchParams("production notes", 0)
+		params[0].OrTerms = true
+		ids, _ := search(t, params)
+		assert.ElementsMatch(t, []string{posts["deploy"].Id, posts["phrase"].Id, posts["hashtag"].Id}, ids)
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams(`"release notes"`, 0))
+		assert.Equal(t, []string{posts["phrase"].Id}, ids)
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("prod*", 0))
+		assert.Equal(t, []string{posts["deploy"].Id}, ids)
+	})
+
+	t.Run("excluded terms", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("release -production", 0))
+		assert.Equal(t, []string{posts["phrase"].Id, posts["old"].Id}, ids)
+	})
+
+	t.Run("hashtag", func(t *testing.T) {
+		ids, matches := search(t, model.ParseSearchParams("#planning", 0))
+		assert.Equal(t, []string{posts["hashtag"].Id}, ids)
+		assert.Equal(t, []string{"#planning"}, matches[posts["hashtag"].Id])
+	})
+
+	t.Run("cjk", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("東京", 0))
+		assert.Equal(t, []string{posts["cjk"].Id}, ids)
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("budget", 0))
+		assert.Equal(t, []string{posts["attached"].Id}, ids)
+	})
+
+	t.Run("channels", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("release", 0), channel, otherChannel)
+		assert.Len(t, ids, 4)
+
+		params := model.ParseSearchParams("release", 0)
+		params[0].InChannels = []string{otherChannel.Id}
+		ids, _ = search(t, params, channel, otherChannel)
+		assert.Equal(t, []string{posts["other"].Id}, ids)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		params := model.ParseSearchParams("release", 0)
+		params[0].FromUsers = []string{user1}
+		ids, _ := search(t, params)
+		assert.Equal(t, []string{posts["deploy"].Id}, ids)
+
+		params = model.ParseSearchParams("release", 0)
+		params[0].ExcludedUsers = []string{user1}
+		ids, _ = search(t, params)
+		assert.Equal(t, []string{posts["phrase"].Id, posts["old"].Id}, ids)
+	})
+
+	t.Run("dates", func(t *testing.T) {
+		params := model.ParseSearchParams("release on:2024-03-15", 0)
+		ids, _ := search(t, params)
+		assert.Equal(t, []string{posts["phrase"].Id, posts["deploy"].Id}, ids)
+
+		params = model.ParseSearchParams("release before:2024-03-14", 0)
+		ids, _ = search(t, params)
+		assert.Equal(t, []string{posts["old"].Id}, ids)
+	})
+
+	t.Run("post attributes", func(t *testing.T) {
+		ids, _ := search(t, model.ParseSearchParams("release is:pinned has:file", 0))
+		assert.Equal(t, []string{posts["deploy"].Id}, ids)
+
+		ids, _ = search(t, model.ParseSearchParams("release -is:pinned", 0))
+		assert.Equal(t, []string{posts["phrase"].Id, posts["old"].Id}, ids)
+
+		ids, _ = search(t, model.ParseSearchParams("release has:link in:thread", 0))
+		assert.Equal(t, []string{posts["phrase"].Id}, ids)
+
+		ids, _ = search(t, model.ParseSearchParams("release -has:link -in:thread", 0))
+		assert.Equal(t, []string{posts["deploy"].Id, posts["old"].Id}, ids)
+
+		ids, _ = search(t, model.ParseSearchParams("from:bot", 0))
+		assert.Equal(t, []string{posts["old"].Id}, ids)
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		ids, _, appErr := engine.SearchPosts([]*model.Channel{channel}, model.ParseSearchParams("release", 0), 1, 2)
+		require.Nil(t, appErr)
+		assert.Equal(t, []string{posts["old"].Id}, ids)
+	})
+
+	t.Run("deletions", func(t *testing.T) {
+		require.Nil(t, engine.DeletePost(posts["phrase"]))
+		require.Nil(t, engine.DeleteUserPosts(rctx, user1))
+		require.Nil(t, engine.RefreshIndexes(rctx))
+		ids, _ := search(t, model.ParseSearchParams("release", 0), channel, otherChannel)
+		assert.Equal(t, []string{posts["old"].Id}, ids)
+
+		require.Nil(t, engine.DataRetentionDeleteIndexes(rctx, time.UnixMilli(day)))
+		require.Nil(t, engine.RefreshIndexes(rctx))
+		ids, _ = search(t, model.ParseSearchParams("release", 0), channel, otherChannel)
+		assert.Empty(t, ids)
+	})
+}
+
+func TestSearchFiles(t *testing.T) {
+	rctx := request.TestContext(t)
+	engine := newTestEngine(t, t.TempDir())
+
+	channel := &model.Channel{Id: model.NewId()}
+	report := &model.FileInfo{Id: model.NewId(), PostId: model.NewId(), CreatorId: model.NewId(), Name: "annual-report.pdf", Extension: "pdf", CreateAt: 1, Content: "Revenue grew in every region"}
+	sheet := &model.FileInfo{Id: model.NewId(), PostId: model.NewId(), CreatorId: model.NewId(), Name: "revenue.xlsx", Extension: "xlsx", CreateAt: 2}
+	for _, file := range []*model.FileInfo{report, sheet} {
+		require.Nil(t, engine.IndexFile(file, channel.Id))
+	}
+	require.Nil(t, engine.RefreshIndexes(rctx))
+
+	ids, appErr := engine.SearchFiles([]*model.Channel{channel}, model.ParseSearchParams("revenue", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{sheet.Id, report.Id}, ids)
+
+	ids, appErr = engine.SearchFiles([]*model.Channel{channel}, model.ParseSearchParams("report", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{report.Id}, ids)
+
+	params := model.ParseSearchParams("revenue", 0)
+	params[0].Extensions = []string{"PDF"}
+	ids, appErr = engine.SearchFiles([]*model.Channel{channel}, params, 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{report.Id}, ids)
+
+	require.Nil(t, engine.DeletePostFiles(rctx, report.PostId))
+	require.Nil(t, engine.RefreshIndexes(rctx))
+	ids, appErr = engine.SearchFiles([]*model.Channel{channel}, model.ParseSearchParams("revenue", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{sheet.Id}, ids)
+}
+
+func TestSearchChannels(t *testing.T) {
+	rctx := request.TestContext(t)
+	engine := newTestEngine(t, t.TempDir())
+
+	teamID, userID := model.NewId(), model.NewId()
+	open := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypeOpen, Name: "town-square", DisplayName: "Town Square"}
+	private := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypePrivate, Name: "town-hall", DisplayName: "Town Hall"}
+	otherPrivate := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypePrivate, Name: "town-secret", DisplayName: "Town Secret"}
+	deleted := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypeOpen, Name: "town-archive", DisplayName: "Town Archive", DeleteAt: 1}
+	require.Nil(t, engine.IndexChannel(rctx, open, nil, []string{userID}))
+	require.Nil(t, engine.IndexChannel(rctx, private, []string{userID}, []string{userID}))
+	require.Nil(t, engine.IndexChannel(rctx, otherPrivate, []string{model.NewId()}, []string{userID}))
+	require.Nil(t, engine.IndexChannel(rctx, deleted, nil, []string{userID}))
+	require.Nil(t, engine.RefreshIndexes(rctx))
+
+	ids, appErr := engine.SearchChannels(teamID, userID, "Town", false, false)
+	require.Nil(t, appErr)
+	assert.ElementsMatch(t, []string{open.Id, private.Id}, ids)
+
+	ids, appErr = engine.SearchChannels(teamID, userID, "square", false, false)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{open.Id}, ids)
+
+	ids, appErr = engine.SearchChannels(teamID, userID, "town", true, false)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{open.Id}, ids)
+
+	ids, appErr = engine.SearchChannels("", userID, "town", false, true)
+	require.Nil(t, appErr)
+	assert.ElementsMatch(t, []string{open.Id, private.Id, deleted.Id}, ids)
+}
+
+func TestSearchUsers(t *testing.T) {
+	rctx := request.TestContext(t)
+	engine := newTestEngine(t, t.TempDir())
+
+	teamID, channelID := model.NewId(), model.NewId()
+	alice := &model.User{Id: model.NewId(), Username: "alice.smith", FirstName: "Alice", LastName: "Johnson"}
+	bob := &model.User{Id: model.NewId(), Username: "bob", Nickname: "Bobby Tables"}
+	carol := &model.User{Id: model.NewId(), Username: "carol", DeleteAt: 1}
+	require.Nil(t, engine.IndexUser(rctx, alice, []string{teamID}, []string{channelID}))
+	require.Nil(t, engine.IndexUser(rctx, bob, []string{teamID}, nil))
+	require.Nil(t, engine.IndexUser(rctx, carol, []string{teamID}, []string{channelID}))
+	require.Nil(t, engine.RefreshIndexes(rctx))
+
+	options := &model.UserSearchOptions{Limit: 10}
+	inChannel, notInChannel, appErr := engine.SearchUsersInChannel(teamID, channelID, nil, "", options)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{alice.Id}, inChannel)
+	assert.Equal(t, []string{bob.Id}, notInChannel)
+
+	ids, appErr := engine.SearchUsersInTeam(teamID, nil, "smith", options)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{alice.Id}, ids)
+
+	ids, appErr = engine.SearchUsersInTeam(teamID, nil, "johnson", options)
+	require.Nil(t, appErr)
+	assert.Empty(t, ids)
+
+	ids, appErr = engine.SearchUsersInTeam(teamID, nil, "johnson", &model.UserSearchOptions{Limit: 10, AllowFullNames: true})
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{alice.Id}, ids)
+
+	ids, appErr = engine.SearchUsersInTeam(teamID, nil, "tables", options)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{bob.Id}, ids)
+
+	ids, appErr = engine.SearchUsersInTeam(teamID, nil, "carol", &model.UserSearchOptions{Limit: 10, AllowInactive: true})
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{carol.Id}, ids)
+
+	ids, appErr = engine.SearchUsersInTeam(teamID, []string{}, "", options)
+	require.Nil(t, appErr)
+	assert.Empty(t, ids)
+}
+
+func TestPurgeIndexes(t *testing.T) {
+	rctx := request.TestContext(t)
+	engine := newTestEngine(t, t.TempDir())
+
+	channel := &model.Channel{Id: model.NewId()}
+	require.Nil(t, engine.IndexPost(&model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "hello"}, ""))
+	require.Nil(t, engine.RefreshIndexes(rctx))
+
+	appErr := engine.PurgeIndexList(rctx, []string{"unknown"})
+	require.NotNil(t, appErr)
+	assert.Equal(t, "embedded_search.purge_indexes.unknown_index.app_error", appErr.Id)
+
+	require.Nil(t, engine.PurgeIndexes(rctx))
+	require.Nil(t, engine.RefreshIndexes(rctx))
+	ids, _, appErr := engine.SearchPosts([]*model.Channel{channel}, model.ParseSearchParams("hello", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Empty(t, ids)
+}
+
+func TestClusterWriter(t *testing.T) {
+	rctx := request.TestContext(t)
+	dir := t.TempDir()
+
+	writer := newTestEngine(t, dir)
+	reader := newTestEngine(t, dir)
+
+	require.True(t, writer.IsWriter())
+	require.False(t, reader.IsWriter())
+
+	// The changes of the reader are saved for the writer.
+	channel := &model.Channel{Id: model.NewId()}
+	post := &model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "forwarded message"}
+	require.Nil(t, reader.IndexPost(post, ""))
+	paths, err := listPendingChanges(dir)
+	require.NoError(t, err)
+	assert.Len(t, paths, 1)
+
+	require.Nil(t, writer.RefreshIndexes(rctx))
+	require.Nil(t, reader.RefreshIndexes(rctx))
+	ids, _, appErr := reader.SearchPosts([]*model.Channel{channel}, model.ParseSearchParams("forwarded", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{post.Id}, ids)
+
+	// The changes are removed once committed.
+	paths, err = listPendingChanges(dir)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	// The reader takes over once the writer stops.
+	require.Nil(t, writer.Stop())
+	reader.becomeWriter()
+	require.True(t, reader.IsWriter())
+
+	require.Nil(t, reader.DeletePost(post))
+	require.Nil(t, reader.RefreshIndexes(rctx))
+	ids, _, appErr = reader.SearchPosts([]*model.Channel{channel}, model.ParseSearchParams("forwarded", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Empty(t, ids)
+}
+
+func TestNoWriter(t *testing.T) {
+	rctx := request.TestContext(t)
+	dir := t.TempDir()
+
+	writer := newTestEngine(t, dir)
+	reader := newTestEngine(t, dir)
+	require.Nil(t, writer.Stop())
+
+	// The changes made while no node writes the indexes are applied by the next writer.
+	channel := &model.Channel{Id: model.NewId()}
+	first := &model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "first message"}
+	second := &model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "second message"}
+	require.Nil(t, reader.IndexPost(first, ""))
+	require.Nil(t, reader.IndexPost(second, ""))
+	require.Nil(t, reader.DeletePost(first))
+
+	reader.becomeWriter()
+	require.True(t, reader.IsWriter())
+	require.Nil(t, reader.RefreshIndexes(rctx))
+
+	ids, _, appErr := reader.SearchPosts([]*model.Channel{channel}, model.ParseSearchParams("message", 0), 0, 20)
+	require.Nil(t, appErr)
+	assert.Equal(t, []string{second.Id}, ids)
+}
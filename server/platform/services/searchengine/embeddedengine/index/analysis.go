@@ -0,0 +1,162 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"strings"
+	"unicode"
+
+	porterstemmer "github.com/blevesearch/go-porterstemmer"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Token is a term produced by the analysis of a text, along with its position in the text.
+type Token struct {
+	Term     string
+	Position int
+}
+
+// stopWords are the English words too common to be indexed. They still take a position, so
+// that phrases containing them match the exact text.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "if": true, "in": true, "into": true, "is": true,
+	"it": true, "no": true, "not": true, "of": true, "on": true, "or": true, "such": true,
+	"that": true, "the": true, "their": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+type word struct {
+	text string
+	cjk  bool
+}
+
+// Analyze splits a text into the terms stored in the index. Words are normalized, lowercased
+// and stemmed when written in latin script, while Chinese and Japanese text, which doesn't
+// separate its words, is split into overlapping bigrams.
+func Analyze(text string) []Token {
+	var tokens []Token
+	position := 0
+	for _, w := range splitWords(text) {
+		if w.cjk {
+			runes := []rune(w.text)
+			if len(runes) == 1 {
+				tokens = append(tokens, Token{Term: w.text, Position: position})
+				position++
+				continue
+			}
+			for i := 0; i+1 < len(runes); i++ {
+				tokens = append(tokens, Token{Term: string(runes[i : i+2]), Position: position})
+				position++
+			}
+			continue
+		}
+
+		term := normalizeWord(w.text)
+		if term == "" {
+			continue
+		}
+		if !stopWords[term] {
+			tokens = append(tokens, Token{Term: Stem(term), Position: position})
+		}
+		position++
+	}
+	return tokens
+}
+
+// NormalizeKeyword normalizes a text the way the words are before being stemmed, for the
+// values matched by prefix.
+func NormalizeKeyword(text string) string {
+	return strings.ToLower(norm.NFKC.String(text))
+}
+
+// IsCJK returns whether the analysis of the given word splits it into bigrams.
+func IsCJK(text string) bool {
+	for _, r := range text {
+		if !isCJK(r) {
+			return false
+		}
+	}
+	return text != ""
+}
+
+func splitWords(text string) []word {
+	runes := []rune(NormalizeKeyword(text))
+	var words []word
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			words = append(words, word{text: string(runes[i:j]), cjk: true})
+			i = j
+		case isWordRune(r):
+			j := i + 1
+			for j < len(runes) {
+				if isWordRune(runes[j]) && !isCJK(runes[j]) {
+					j++
+					continue
+				}
+				if j+1 < len(runes) && isJoiner(runes[j-1], runes[j], runes[j+1]) {
+					j++
+					continue
+				}
+				break
+			}
+			words = append(words, word{text: string(runes[i:j])})
+			i = j
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+func normalizeWord(w string) string {
+	w = strings.Trim(w, "_")
+	for _, suffix := range []string{"'s", "’s"} {
+		if len(w) > len(suffix) && strings.HasSuffix(w, suffix) {
+			w = strings.TrimSuffix(w, suffix)
+			break
+		}
+	}
+	return w
+}
+
+// Stem reduces lowercase english words to their stem, leaving any other word as is.
+func Stem(term string) string {
+	for i := 0; i < len(term); i++ {
+		if term[i] < 'a' || term[i] > 'z' {
+			return term
+		}
+	}
+	return porterstemmer.StemString(term)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+func isAlphanumeric(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// isJoiner returns whether r joins the characters around it into a single word, as in
+// "don't", "v1.2" or "10,000".
+func isJoiner(prev, r, next rune) bool {
+	switch r {
+	case '.', '\'', '’':
+		return isAlphanumeric(prev) && isAlphanumeric(next)
+	case ',':
+		return unicode.IsDigit(prev) && unicode.IsDigit(next)
+	}
+	return false
+}
+
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
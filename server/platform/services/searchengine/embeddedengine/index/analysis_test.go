@@ -0,0 +1,62 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func terms(tokens []Token) []string {
+	var result []string
+	for _, token := range tokens {
+		result = append(result, token.Term)
+	}
+	return result
+}
+
+func TestAnalyze(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text     string
+		expected []string
+	}{
+		"empty":               {"", nil},
+		"words":               {"Hello World", []string{"hello", "world"}},
+		"stemming":            {"Running deployments", []string{"run", "deploy"}},
+		"stop words":          {"the state of the art", []string{"state", "art"}},
+		"punctuation":         {"foo, bar! (baz)", []string{"foo", "bar", "baz"}},
+		"joined words":        {"v1.2 don't example.com 10,000", []string{"v1.2", "don't", "example.com", "10,000"}},
+		"trailing dots":       {"end. new", []string{"end", "new"}},
+		"possessive":          {"John's laptop", []string{"john", "laptop"}},
+		"underscores":         {"_snake_case_", []string{"snake_case"}},
+		"width normalization": {"ＡＢＣ", []string{"abc"}},
+		"accents":             {"Café", []string{"café"}},
+		"cyrillic":            {"Привет мир", []string{"привет", "мир"}},
+		"chinese bigrams":     {"东京都", []string{"东京", "京都"}},
+		"japanese":            {"テスト", []string{"テス", "スト"}},
+		"single character":    {"东", []string{"东"}},
+		"mixed scripts":       {"deploy到东京", []string{"deploi", "到东", "东京"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, terms(Analyze(tc.text)))
+		})
+	}
+
+	t.Run("positions", func(t *testing.T) {
+		assert.Equal(t, []Token{
+			{Term: "state", Position: 1},
+			{Term: "art", Position: 4},
+		}, Analyze("the state of the art"))
+	})
+}
+
+func TestParseQueryString(t *testing.T) {
+	assert.Equal(t, []Clause{
+		{Text: "hello"},
+		{Text: "exact phrase", Phrase: true},
+		{Text: "deploy", Prefix: true},
+		{Text: "unterminated"},
+	}, ParseQueryString(`  hello "exact phrase" deploy* * ""  "unterminated`))
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"sort"
+
+	"github.com/blevesearch/vellum"
+)
+
+// segmentBuilder collects the documents added by a commit, before they are written to a
+// new segment.
+type segmentBuilder struct {
+	docs  []*Document
+	byID  map[string]int
+	count int
+}
+
+func newSegmentBuilder() *segmentBuilder {
+	return &segmentBuilder{byID: map[string]int{}}
+}
+
+func (b *segmentBuilder) add(doc *Document) {
+	b.delete(doc.ID)
+	b.byID[doc.ID] = len(b.docs)
+	b.docs = append(b.docs, doc)
+	b.count++
+}
+
+func (b *segmentBuilder) delete(id string) {
+	if i, ok := b.byID[id]; ok {
+		b.remove(i)
+	}
+}
+
+func (b *segmentBuilder) remove(i int) {
+	delete(b.byID, b.docs[i].ID)
+	b.docs[i] = nil
+	b.count--
+}
+
+// deleteMatching deletes the documents matching f, up to limit documents when limit is
+// positive, and returns the number of deleted documents.
+func (b *segmentBuilder) deleteMatching(f func(doc *Document) bool, limit int) int {
+	deleted := 0
+	for i, doc := range b.docs {
+		if limit > 0 && deleted >= limit {
+			break
+		}
+		if doc != nil && f(doc) {
+			b.remove(i)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+func (b *segmentBuilder) purge() {
+	b.docs = nil
+	b.byID = map[string]int{}
+	b.count = 0
+}
+
+// write writes the documents to a new segment.
+func (b *segmentBuilder) write(path string) error {
+	var ids []string
+	numbers := map[string][]int64{}
+	terms := map[string][]posting{}
+	for _, doc := range b.docs {
+		if doc == nil {
+			continue
+		}
+		ordinal := uint32(len(ids))
+		ids = append(ids, doc.ID)
+
+		for field, v := range doc.Numbers {
+			if _, ok := numbers[field]; !ok {
+				numbers[field] = make([]int64, ordinal, len(b.docs))
+			}
+			numbers[field] = append(numbers[field], v)
+		}
+		for field, values := range numbers {
+			if len(values) == int(ordinal) {
+				numbers[field] = append(values, 0)
+			}
+		}
+
+		for field, values := range doc.Keywords {
+			seen := map[string]bool{}
+			for _, v := range values {
+				if seen[v] {
+					continue
+				}
+				seen[v] = true
+				key := string(termKey(field, v))
+				terms[key] = append(terms[key], posting{doc: ordinal})
+			}
+		}
+		for field, text := range doc.Text {
+			positions := map[string][]uint32{}
+			var order []string
+			for _, token := range Analyze(text) {
+				if _, ok := positions[token.Term]; !ok {
+					order = append(order, token.Term)
+				}
+				positions[token.Term] = append(positions[token.Term], uint32(token.Position))
+			}
+			for _, term := range order {
+				key := string(termKey(field, term))
+				terms[key] = append(terms[key], posting{doc: ordinal, positions: positions[term]})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(terms))
+	for key := range terms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return writeSegment(path, ids, numbers, func(yield func(key []byte, postings []posting) error) error {
+		for _, key := range keys {
+			if err := yield([]byte(key), terms[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mergeSegments writes the live documents of the given segments to a new segment.
+func mergeSegments(path string, segments []*liveSegment) error {
+	var ids []string
+	remaps := make([][]int64, len(segments))
+	for i, s := range segments {
+		remap := make([]int64, s.docCount)
+		for doc := range remap {
+			if s.deleted.has(uint32(doc)) {
+				remap[doc] = -1
+				continue
+			}
+			id, err := s.id(uint32(doc))
+			if err != nil {
+				return err
+			}
+			remap[doc] = int64(len(ids))
+			ids = append(ids, id)
+		}
+		remaps[i] = remap
+	}
+
+	numbers := map[string][]int64{}
+	for i, s := range segments {
+		for field := range s.numbers {
+			if _, ok := numbers[field]; ok {
+				continue
+			}
+			values := make([]int64, len(ids))
+			for j, other := range segments[i:] {
+				for doc, ordinal := range remaps[i+j] {
+					if ordinal >= 0 {
+						values[ordinal] = other.number(field, uint32(doc))
+					}
+				}
+			}
+			numbers[field] = values
+		}
+	}
+
+	var iterators []vellum.Iterator
+	for _, s := range segments {
+		it, err := s.terms.Iterator(nil, nil)
+		if err == vellum.ErrIteratorDone {
+			continue
+		} else if err != nil {
+			return err
+		}
+		iterators = append(iterators, it)
+	}
+
+	return writeSegment(path, ids, numbers, func(yield func(key []byte, postings []posting) error) error {
+		if len(iterators) == 0 {
+			return nil
+		}
+		merged, err := vellum.NewMergeIterator(iterators, func([]uint64) uint64 { return 0 })
+		var postings []posting
+		for err == nil {
+			key, _ := merged.Current()
+			key = append([]byte(nil), key...)
+			postings = postings[:0]
+			for i, s := range segments {
+				it, err := s.postings(key)
+				if err != nil {
+					return err
+				}
+				if it == nil {
+					continue
+				}
+				for it.next(true) {
+					if ordinal := remaps[i][it.doc]; ordinal >= 0 {
+						postings = append(postings, posting{doc: uint32(ordinal), positions: append([]uint32(nil), it.positions...)})
+					}
+				}
+				if err := it.err(); err != nil {
+					return err
+				}
+			}
+			if err := yield(key, postings); err != nil {
+				return err
+			}
+			err = merged.Next()
+		}
+		if err != vellum.ErrIteratorDone {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import "math/bits"
+
+// docSet is a set of the documents of a segment, identified by their ordinal.
+type docSet []uint64
+
+func newDocSet(size int) docSet {
+	return make(docSet, (size+63)/64)
+}
+
+func (s docSet) add(doc uint32) {
+	s[doc/64] |= 1 << (doc % 64)
+}
+
+func (s docSet) has(doc uint32) bool {
+	return int(doc/64) < len(s) && s[doc/64]&(1<<(doc%64)) != 0
+}
+
+func (s docSet) fill(size int) {
+	for i := range s {
+		s[i] = ^uint64(0)
+	}
+	if rest := size % 64; rest != 0 {
+		s[len(s)-1] = 1<<rest - 1
+	}
+}
+
+func (s docSet) and(other docSet) {
+	for i := range s {
+		s[i] &= other[i]
+	}
+}
+
+func (s docSet) or(other docSet) {
+	for i := range s {
+		s[i] |= other[i]
+	}
+}
+
+func (s docSet) andNot(other docSet) {
+	for i := range s {
+		s[i] &^= other[i]
+	}
+}
+
+func (s docSet) count() int {
+	n := 0
+	for _, w := range s {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+func (s docSet) clone() docSet {
+	return append(docSet(nil), s...)
+}
+
+// forEach calls f with every document of the set, in ascending order.
+func (s docSet) forEach(f func(doc uint32)) {
+	for i, w := range s {
+		for w != 0 {
+			f(uint32(i*64 + bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+// Document is an entry of the index.
+type Document struct {
+	ID string `json:"id"`
+	// Keywords are indexed as is, to filter the documents on exact values.
+	Keywords map[string][]string `json:"keywords,omitempty"`
+	// Text is analyzed to be matched by full text queries.
+	Text map[string]string `json:"text,omitempty"`
+	// Numbers are used by range queries and to sort the results.
+	Numbers map[string]int64 `json:"numbers,omitempty"`
+}
+
+func (d *Document) hasKeyword(field, value string) bool {
+	for _, v := range d.Keywords[field] {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type OperationType string
+
+const (
+	OperationIndex       OperationType = "index"
+	OperationDelete      OperationType = "delete"
+	OperationDeleteTerm  OperationType = "delete_term"
+	OperationDeleteRange OperationType = "delete_range"
+	OperationPurge       OperationType = "purge"
+)
+
+// Operation is a change of the index. Operations are serializable, so that they can be sent
+// to the node writing the index.
+type Operation struct {
+	Type     OperationType `json:"type"`
+	Document *Document     `json:"document,omitempty"`
+	ID       string        `json:"id,omitempty"`
+	Field    string        `json:"field,omitempty"`
+	Value    string        `json:"value,omitempty"`
+	Max      int64         `json:"max,omitempty"`
+	Limit    int           `json:"limit,omitempty"`
+}
+
+// IndexOperation adds a document to the index, replacing any document with the same ID.
+func IndexOperation(doc *Document) *Operation {
+	return &Operation{Type: OperationIndex, Document: doc}
+}
+
+// DeleteOperation deletes the document with the given ID.
+func DeleteOperation(id string) *Operation {
+	return &Operation{Type: OperationDelete, ID: id}
+}
+
+// DeleteTermOperation deletes the documents with the given keyword.
+func DeleteTermOperation(field, value string) *Operation {
+	return &Operation{Type: OperationDeleteTerm, Field: field, Value: value}
+}
+
+// DeleteRangeOperation deletes the documents whose number field is lower than or equal to
+// max, up to limit documents when limit is positive.
+func DeleteRangeOperation(field string, max int64, limit int) *Operation {
+	return &Operation{Type: OperationDeleteRange, Field: field, Max: max, Limit: limit}
+}
+
+// PurgeOperation deletes all the documents.
+func PurgeOperation() *Operation {
+	return &Operation{Type: OperationPurge}
+}
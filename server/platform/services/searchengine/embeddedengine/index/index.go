@@ -0,0 +1,583 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Package index implements an on-disk inverted index, made of immutable segments listed by a
+// manifest. A single process writes the index, while any number of processes can read it and
+// refresh their view when a new generation of the manifest is written.
+package index
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrReadOnly is returned when changing an index opened for reading only.
+var ErrReadOnly = errors.New("the index is open for reading only")
+
+const (
+	// mergeFactor is the number of segments of similar size merged together.
+	mergeFactor = 10
+	// obsoleteFileGracePeriod is the time the files no longer used are kept, for the readers
+	// still using a previous generation of the index.
+	obsoleteFileGracePeriod = 5 * time.Minute
+)
+
+// liveSegment is a segment along with the documents deleted from it.
+type liveSegment struct {
+	*segment
+	deleted      docSet
+	deletions    string
+	deletedCount int
+}
+
+// snapshot is a generation of the index, kept open while it's being searched.
+type snapshot struct {
+	generation uint64
+	segments   []*liveSegment
+	refs       atomic.Int32
+}
+
+func newSnapshot(generation uint64, segments []*liveSegment) *snapshot {
+	s := &snapshot{generation: generation, segments: segments}
+	s.refs.Store(1)
+	for _, seg := range segments {
+		seg.incRef()
+	}
+	return s
+}
+
+func (s *snapshot) decRef() {
+	if s.refs.Add(-1) == 0 {
+		for _, seg := range s.segments {
+			seg.decRef()
+		}
+	}
+}
+
+// Index is an on-disk index.
+type Index struct {
+	dir      string
+	writable bool
+
+	pendingMu sync.Mutex
+	pending   []*Operation
+
+	// commitMu serializes the changes of the manifest, obsolete and closed fields.
+	commitMu sync.Mutex
+	manifest *manifest
+	obsolete map[string]time.Time
+	closed   bool
+
+	snapshotMu sync.RWMutex
+	current    *snapshot
+}
+
+// Open opens the index stored in the given directory. Only one process at a time may open
+// an index for writing.
+func Open(dir string, writable bool) (*Index, error) {
+	if writable {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	i := &Index{
+		dir:      dir,
+		writable: writable,
+		manifest: &manifest{Version: Version},
+		obsolete: map[string]time.Time{},
+		current:  newSnapshot(0, nil),
+	}
+	if err := i.refresh(); err != nil {
+		i.Close()
+		return nil, err
+	}
+	if writable {
+		if err := i.collectUnreferenced(); err != nil {
+			i.Close()
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+// Close releases the resources of the index. The pending operations which haven't been
+// committed are lost.
+func (i *Index) Close() error {
+	i.commitMu.Lock()
+	defer i.commitMu.Unlock()
+	if i.closed {
+		return nil
+	}
+	i.closed = true
+	i.swap(newSnapshot(i.manifest.Generation, nil))
+	return nil
+}
+
+func (i *Index) acquire() *snapshot {
+	i.snapshotMu.RLock()
+	defer i.snapshotMu.RUnlock()
+	i.current.refs.Add(1)
+	return i.current
+}
+
+func (i *Index) swap(s *snapshot) {
+	i.snapshotMu.Lock()
+	old := i.current
+	i.current = s
+	i.snapshotMu.Unlock()
+	old.decRef()
+}
+
+// Generation returns the generation of the index currently searched.
+func (i *Index) Generation() uint64 {
+	s := i.acquire()
+	defer s.decRef()
+	return s.generation
+}
+
+// DocCount returns the number of documents in the index.
+func (i *Index) DocCount() int {
+	s := i.acquire()
+	defer s.decRef()
+	count := 0
+	for _, seg := range s.segments {
+		count += seg.docCount - seg.deletedCount
+	}
+	return count
+}
+
+// Refresh makes the changes committed by the writer of the index visible to a reader.
+func (i *Index) Refresh() error {
+	if i.writable {
+		return nil
+	}
+	i.commitMu.Lock()
+	defer i.commitMu.Unlock()
+	if i.closed {
+		return nil
+	}
+	return i.refresh()
+}
+
+func (i *Index) refresh() error {
+	m, err := readManifest(i.dir)
+	if err != nil {
+		return err
+	}
+	if m.Generation == i.manifest.Generation {
+		return nil
+	}
+
+	current := i.acquire()
+	defer current.decRef()
+	reusable := map[string]*liveSegment{}
+	for _, seg := range current.segments {
+		reusable[seg.name] = seg
+	}
+
+	var (
+		segments []*liveSegment
+		opened   []*segment
+	)
+	err = func() error {
+		for _, ms := range m.Segments {
+			ls := &liveSegment{deletions: ms.Deletions, deletedCount: ms.Deleted}
+			if seg, ok := reusable[ms.Name]; ok {
+				ls.segment = seg.segment
+				if seg.deletions == ms.Deletions {
+					ls.deleted = seg.deleted
+				}
+			} else {
+				seg, err := openSegment(i.dir, ms.Name)
+				if err != nil {
+					return err
+				}
+				opened = append(opened, seg)
+				ls.segment = seg
+			}
+			if ms.Deletions != "" && ls.deleted == nil {
+				deleted, err := readDeletions(filepath.Join(i.dir, ms.Deletions), ls.docCount)
+				if err != nil {
+					return err
+				}
+				ls.deleted = deleted
+			}
+			segments = append(segments, ls)
+		}
+		return nil
+	}()
+	if err != nil {
+		for _, seg := range opened {
+			seg.decRef()
+		}
+		return err
+	}
+
+	i.manifest = m
+	i.swap(newSnapshot(m.Generation, segments))
+	// The snapshot holds its own references.
+	for _, seg := range opened {
+		seg.decRef()
+	}
+	return nil
+}
+
+// Apply queues changes of the index, applied in order by the next commit.
+func (i *Index) Apply(ops ...*Operation) error {
+	if !i.writable {
+		return ErrReadOnly
+	}
+	i.pendingMu.Lock()
+	defer i.pendingMu.Unlock()
+	i.pending = append(i.pending, ops...)
+	return nil
+}
+
+// Pending returns the number of operations waiting for the next commit.
+func (i *Index) Pending() int {
+	i.pendingMu.Lock()
+	defer i.pendingMu.Unlock()
+	return len(i.pending)
+}
+
+// Commit applies the pending operations and writes a new generation of the index.
+func (i *Index) Commit() error {
+	if !i.writable {
+		return ErrReadOnly
+	}
+	i.commitMu.Lock()
+	defer i.commitMu.Unlock()
+	if i.closed {
+		return errors.New("the index is closed")
+	}
+
+	i.pendingMu.Lock()
+	ops := i.pending
+	i.pending = nil
+	i.pendingMu.Unlock()
+
+	if len(ops) > 0 {
+		if err := i.commit(ops); err != nil {
+			// The operations are retried by the next commit.
+			i.pendingMu.Lock()
+			i.pending = append(ops, i.pending...)
+			i.pendingMu.Unlock()
+			return err
+		}
+	}
+	i.collectObsolete()
+	return nil
+}
+
+// pendingDeletions tracks the documents deleted from the segments by a commit.
+type pendingDeletions struct {
+	segments []*liveSegment
+	deleted  []docSet
+}
+
+func (p *pendingDeletions) isDeleted(j int, doc uint32) bool {
+	if p.deleted[j] != nil {
+		return p.deleted[j].has(doc)
+	}
+	return p.segments[j].deleted.has(doc)
+}
+
+func (p *pendingDeletions) delete(j int, doc uint32) bool {
+	if p.isDeleted(j, doc) {
+		return false
+	}
+	if p.deleted[j] == nil {
+		if p.segments[j].deleted != nil {
+			p.deleted[j] = p.segments[j].deleted.clone()
+		} else {
+			p.deleted[j] = newDocSet(p.segments[j].docCount)
+		}
+	}
+	p.deleted[j].add(doc)
+	return true
+}
+
+func (p *pendingDeletions) deleteID(id string) error {
+	for j, seg := range p.segments {
+		doc, ok, err := seg.ordinal(id)
+		if err != nil {
+			return err
+		}
+		if ok {
+			p.delete(j, doc)
+		}
+	}
+	return nil
+}
+
+func (p *pendingDeletions) deleteTerm(field, value string) error {
+	key := termKey(field, value)
+	for j, seg := range p.segments {
+		it, err := seg.postings(key)
+		if err != nil {
+			return err
+		}
+		if it == nil {
+			continue
+		}
+		for it.next(false) {
+			p.delete(j, it.doc)
+		}
+		if err := it.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteRange deletes the documents whose field is lower than or equal to max, up to limit
+// documents when limit is positive, and returns the number of deleted documents.
+func (p *pendingDeletions) deleteRange(field string, max int64, limit int) int {
+	deleted := 0
+	for j, seg := range p.segments {
+		for doc := 0; doc < seg.docCount; doc++ {
+			if limit > 0 && deleted >= limit {
+				return deleted
+			}
+			if seg.number(field, uint32(doc)) <= max && p.delete(j, uint32(doc)) {
+				deleted++
+			}
+		}
+	}
+	return deleted
+}
+
+func (p *pendingDeletions) purge() {
+	for j, seg := range p.segments {
+		p.deleted[j] = newDocSet(seg.docCount)
+		p.deleted[j].fill(seg.docCount)
+	}
+}
+
+func (i *Index) commit(ops []*Operation) error {
+	current := i.acquire()
+	defer current.decRef()
+
+	deletions := &pendingDeletions{segments: current.segments, deleted: make([]docSet, len(current.segments))}
+	builder := newSegmentBuilder()
+	for _, op := range ops {
+		switch op.Type {
+		case OperationIndex:
+			if op.Document == nil || op.Document.ID == "" {
+				return fmt.Errorf("invalid %s operation", op.Type)
+			}
+			if err := deletions.deleteID(op.Document.ID); err != nil {
+				return err
+			}
+			builder.add(op.Document)
+		case OperationDelete:
+			if err := deletions.deleteID(op.ID); err != nil {
+				return err
+			}
+			builder.delete(op.ID)
+		case OperationDeleteTerm:
+			if err := deletions.deleteTerm(op.Field, op.Value); err != nil {
+				return err
+			}
+			builder.deleteMatching(func(doc *Document) bool { return doc.hasKeyword(op.Field, op.Value) }, 0)
+		case OperationDeleteRange:
+			deleted := deletions.deleteRange(op.Field, op.Max, op.Limit)
+			if op.Limit <= 0 || deleted < op.Limit {
+				builder.deleteMatching(func(doc *Document) bool { return doc.Numbers[op.Field] <= op.Max }, op.Limit-deleted)
+			}
+		case OperationPurge:
+			deletions.purge()
+			builder.purge()
+		default:
+			return fmt.Errorf("unknown index operation %q", op.Type)
+		}
+	}
+
+	m := &manifest{
+		Version:     Version,
+		Generation:  i.manifest.Generation + 1,
+		NextSegment: i.manifest.NextSegment,
+	}
+	var (
+		segments []*liveSegment
+		opened   []*segment
+		created  []string
+	)
+	err := func() error {
+		for j, seg := range current.segments {
+			ls := *seg
+			if deleted := deletions.deleted[j]; deleted != nil {
+				ls.deleted = deleted
+				ls.deletedCount = deleted.count()
+				if ls.deletedCount == ls.docCount {
+					continue
+				}
+				ls.deletions = deletionsName(ls.name, m.Generation)
+				created = append(created, ls.deletions)
+				if err := writeDeletions(filepath.Join(i.dir, ls.deletions), deleted); err != nil {
+					return err
+				}
+			}
+			segments = append(segments, &ls)
+		}
+
+		if builder.count > 0 {
+			name := segmentName(m.NextSegment)
+			m.NextSegment++
+			created = append(created, name)
+			if err := builder.write(filepath.Join(i.dir, name)); err != nil {
+				return err
+			}
+			seg, err := openSegment(i.dir, name)
+			if err != nil {
+				return err
+			}
+			opened = append(opened, seg)
+			segments = append(segments, &liveSegment{segment: seg})
+		}
+
+		for group := selectMerge(segments); group != nil; group = selectMerge(segments) {
+			name := segmentName(m.NextSegment)
+			m.NextSegment++
+			merged := make([]*liveSegment, len(group))
+			for k, j := range group {
+				merged[k] = segments[j]
+			}
+			created = append(created, name)
+			if err := mergeSegments(filepath.Join(i.dir, name), merged); err != nil {
+				return err
+			}
+			seg, err := openSegment(i.dir, name)
+			if err != nil {
+				return err
+			}
+			opened = append(opened, seg)
+
+			remaining := segments[:0:0]
+			for j, ls := range segments {
+				if !containsInt(group, j) {
+					remaining = append(remaining, ls)
+				}
+			}
+			segments = append(remaining, &liveSegment{segment: seg})
+		}
+
+		for _, ls := range segments {
+			m.Segments = append(m.Segments, manifestSegment{
+				Name:      ls.name,
+				Docs:      ls.docCount,
+				Deletions: ls.deletions,
+				Deleted:   ls.deletedCount,
+			})
+		}
+		return writeManifest(i.dir, m)
+	}()
+	if err != nil {
+		for _, seg := range opened {
+			seg.decRef()
+		}
+		for _, name := range created {
+			os.Remove(filepath.Join(i.dir, name))
+		}
+		return err
+	}
+
+	used := manifestFiles(m)
+	for name := range manifestFiles(i.manifest) {
+		if !used[name] {
+			i.obsolete[name] = time.Now()
+		}
+	}
+	for _, name := range created {
+		// Segments merged by the same commit are never referenced.
+		if !used[name] {
+			i.obsolete[name] = time.Now()
+		}
+	}
+
+	i.manifest = m
+	i.swap(newSnapshot(m.Generation, segments))
+	for _, seg := range opened {
+		seg.decRef()
+	}
+	return nil
+}
+
+// selectMerge returns the next group of segments to merge: a segment where most documents
+// have been deleted, or mergeFactor segments holding numbers of documents of the same order
+// of magnitude.
+func selectMerge(segments []*liveSegment) []int {
+	levels := map[int][]int{}
+	for j, seg := range segments {
+		if seg.deletedCount*2 > seg.docCount {
+			return []int{j}
+		}
+		level := int(math.Log10(float64(seg.docCount - seg.deletedCount)))
+		levels[level] = append(levels[level], j)
+	}
+	var merge []int
+	for _, group := range levels {
+		// The smallest segments are merged first.
+		if len(group) >= mergeFactor && (merge == nil || segments[group[0]].docCount < segments[merge[0]].docCount) {
+			merge = group
+		}
+	}
+	return merge
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func manifestFiles(m *manifest) map[string]bool {
+	files := map[string]bool{}
+	for _, ms := range m.Segments {
+		files[ms.Name] = true
+		if ms.Deletions != "" {
+			files[ms.Deletions] = true
+		}
+	}
+	return files
+}
+
+// collectUnreferenced marks the files left behind by a previous writer as obsolete.
+func (i *Index) collectUnreferenced() error {
+	entries, err := os.ReadDir(i.dir)
+	if err != nil {
+		return err
+	}
+	used := manifestFiles(i.manifest)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !used[name] && (strings.HasSuffix(name, ".seg") || strings.HasSuffix(name, ".del")) {
+			i.obsolete[name] = time.Now()
+		}
+	}
+	return nil
+}
+
+// collectObsolete removes the obsolete files once no reader is likely to use them anymore.
+func (i *Index) collectObsolete() {
+	for name, since := range i.obsolete {
+		if time.Since(since) < obsoleteFileGracePeriod {
+			continue
+		}
+		err := os.Remove(filepath.Join(i.dir, name))
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			delete(i.obsolete, name)
+		}
+	}
+}
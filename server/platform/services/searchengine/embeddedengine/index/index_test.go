@@ -0,0 +1,305 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument(id, channel, message string, createAt int64) *Document {
+	return &Document{
+		ID:       id,
+		Keywords: map[string][]string{"channel_id": {channel}},
+		Text:     map[string]string{"message": message},
+		Numbers:  map[string]int64{"create_at": createAt},
+	}
+}
+
+func search(t *testing.T, idx *Index, q Query) []string {
+	t.Helper()
+	result, err := idx.Search(&SearchRequest{Query: q})
+	require.NoError(t, err)
+	return result.IDs
+}
+
+func TestIndex(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		idx, err := Open(t.TempDir(), true)
+		require.NoError(t, err)
+		defer idx.Close()
+
+		require.NoError(t, idx.Apply(
+			IndexOperation(testDocument("a", "c1", "the quick brown fox", 1)),
+			IndexOperation(testDocument("b", "c2", "a lazy dog", 2)),
+		))
+		// The operations are only visible once committed.
+		assert.Empty(t, search(t, idx, MatchAllQuery{}))
+		assert.Equal(t, 2, idx.Pending())
+
+		require.NoError(t, idx.Commit())
+		assert.Zero(t, idx.Pending())
+		assert.Equal(t, []string{"a", "b"}, search(t, idx, MatchAllQuery{}))
+		assert.Equal(t, []string{"b"}, search(t, idx, TermQuery{Field: "channel_id", Term: "c2"}))
+		assert.Equal(t, []string{"a"}, search(t, idx, MatchQuery{Field: "message", Text: "Foxes"}))
+		assert.Equal(t, 2, idx.DocCount())
+	})
+
+	t.Run("updates and deletions", func(t *testing.T) {
+		idx, err := Open(t.TempDir(), true)
+		require.NoError(t, err)
+		defer idx.Close()
+
+		require.NoError(t, idx.Apply(
+			IndexOperation(testDocument("a", "c1", "first", 1)),
+			IndexOperation(testDocument("b", "c1", "second", 2)),
+			IndexOperation(testDocument("c", "c2", "third", 3)),
+		))
+		require.NoError(t, idx.Commit())
+
+		require.NoError(t, idx.Apply(
+			IndexOperation(testDocument("a", "c2", "edited", 4)),
+			DeleteOperation("c"),
+			// The operations apply in order, including to the documents of the same commit.
+			IndexOperation(testDocument("d", "c3", "fourth", 5)),
+			DeleteOperation("d"),
+		))
+		require.NoError(t, idx.Commit())
+		assert.Equal(t, []string{"a", "b"}, search(t, idx, MatchAllQuery{}))
+		assert.Empty(t, search(t, idx, MatchQuery{Field: "message", Text: "first"}))
+		assert.Equal(t, []string{"a"}, search(t, idx, MatchQuery{Field: "message", Text: "edited"}))
+
+		require.NoError(t, idx.Apply(DeleteTermOperation("channel_id", "c1")))
+		require.NoError(t, idx.Commit())
+		assert.Equal(t, []string{"a"}, search(t, idx, MatchAllQuery{}))
+
+		require.NoError(t, idx.Apply(PurgeOperation(), IndexOperation(testDocument("e", "c1", "fifth", 6))))
+		require.NoError(t, idx.Commit())
+		assert.Equal(t, []string{"e"}, search(t, idx, MatchAllQuery{}))
+	})
+
+	t.Run("delete range", func(t *testing.T) {
+		idx, err := Open(t.TempDir(), true)
+		require.NoError(t, err)
+		defer idx.Close()
+
+		for i := range 5 {
+			require.NoError(t, idx.Apply(IndexOperation(testDocument(fmt.Sprint(i), "c1", "message", int64(i)))))
+		}
+		require.NoError(t, idx.Commit())
+
+		require.NoError(t, idx.Apply(DeleteRangeOperation("create_at", 3, 2)))
+		require.NoError(t, idx.Commit())
+		assert.Equal(t, 3, idx.DocCount())
+
+		require.NoError(t, idx.Apply(DeleteRangeOperation("create_at", 3, 0)))
+		require.NoError(t, idx.Commit())
+		assert.Equal(t, []string{"4"}, search(t, idx, MatchAllQuery{}))
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		idx, err := Open(dir, true)
+		require.NoError(t, err)
+		require.NoError(t, idx.Apply(
+			IndexOperation(testDocument("a", "c1", "first", 1)),
+			IndexOperation(testDocument("b", "c1", "second", 2)),
+		))
+		require.NoError(t, idx.Commit())
+		require.NoError(t, idx.Apply(DeleteOperation("a")))
+		require.NoError(t, idx.Commit())
+		require.NoError(t, idx.Close())
+
+		idx, err = Open(dir, true)
+		require.NoError(t, err)
+		defer idx.Close()
+		assert.Equal(t, []string{"b"}, search(t, idx, MatchAllQuery{}))
+		assert.Equal(t, uint64(2), idx.Generation())
+	})
+
+	t.Run("readers refresh", func(t *testing.T) {
+		dir := t.TempDir()
+		writer, err := Open(dir, true)
+		require.NoError(t, err)
+		defer writer.Close()
+
+		reader, err := Open(dir, false)
+		require.NoError(t, err)
+		defer reader.Close()
+		assert.ErrorIs(t, reader.Apply(DeleteOperation("a")), ErrReadOnly)
+		assert.ErrorIs(t, reader.Commit(), ErrReadOnly)
+
+		require.NoError(t, writer.Apply(IndexOperation(testDocument("a", "c1", "first", 1))))
+		require.NoError(t, writer.Commit())
+		assert.Empty(t, search(t, reader, MatchAllQuery{}))
+
+		require.NoError(t, reader.Refresh())
+		assert.Equal(t, []string{"a"}, search(t, reader, MatchAllQuery{}))
+
+		require.NoError(t, writer.Apply(IndexOperation(testDocument("b", "c1", "second", 2)), DeleteOperation("a")))
+		require.NoError(t, writer.Commit())
+		require.NoError(t, reader.Refresh())
+		assert.Equal(t, []string{"b"}, search(t, reader, MatchAllQuery{}))
+	})
+
+	t.Run("merges", func(t *testing.T) {
+		dir := t.TempDir()
+		idx, err := Open(dir, true)
+		require.NoError(t, err)
+		defer idx.Close()
+
+		for i := range 25 {
+			require.NoError(t, idx.Apply(IndexOperation(testDocument(fmt.Sprint(i), "c1", fmt.Sprintf("message number%d", i), int64(i)))))
+			require.NoError(t, idx.Commit())
+		}
+		s := idx.acquire()
+		segments := len(s.segments)
+		s.decRef()
+		assert.Less(t, segments, mergeFactor)
+		assert.Equal(t, 25, idx.DocCount())
+		assert.Equal(t, []string{"7"}, search(t, idx, MatchQuery{Field: "message", Text: "number7"}))
+		assert.Equal(t, []string{"7"}, search(t, idx, PhraseQuery{Field: "message", Text: "message number7"}))
+		assert.Len(t, search(t, idx, MatchQuery{Field: "message", Text: "message"}), 25)
+
+		// Deleting most of a segment rewrites it.
+		for i := range 20 {
+			require.NoError(t, idx.Apply(DeleteOperation(fmt.Sprint(i))))
+		}
+		require.NoError(t, idx.Commit())
+		s = idx.acquire()
+		for _, seg := range s.segments {
+			assert.LessOrEqual(t, seg.deletedCount*2, seg.docCount)
+		}
+		s.decRef()
+		assert.Equal(t, 5, idx.DocCount())
+		assert.Equal(t, []string{"20", "21", "22", "23", "24"}, search(t, idx, MatchAllQuery{}))
+	})
+
+	t.Run("obsolete files", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, segmentName(42)), []byte("leftover"), 0600))
+
+		idx, err := Open(dir, true)
+		require.NoError(t, err)
+		defer idx.Close()
+		assert.Contains(t, idx.obsolete, segmentName(42))
+
+		require.NoError(t, idx.Apply(IndexOperation(testDocument("a", "c1", "first", 1))))
+		require.NoError(t, idx.Commit())
+		require.NoError(t, idx.Apply(DeleteOperation("a")))
+		require.NoError(t, idx.Commit())
+		assert.Contains(t, idx.obsolete, segmentName(0))
+
+		for name := range idx.obsolete {
+			idx.obsolete[name] = idx.obsolete[name].Add(-obsoleteFileGracePeriod)
+		}
+		require.NoError(t, idx.Commit())
+		assert.Empty(t, idx.obsolete)
+		assert.NoFileExists(t, filepath.Join(dir, segmentName(0)))
+		assert.NoFileExists(t, filepath.Join(dir, segmentName(42)))
+	})
+
+	t.Run("lock", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "write.lock")
+		lock, err := TryLock(path)
+		require.NoError(t, err)
+		require.NotNil(t, lock)
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		locked, err := tryLockFile(f)
+		require.NoError(t, err)
+		assert.False(t, locked)
+
+		require.NoError(t, lock.Unlock())
+		locked, err = tryLockFile(f)
+		require.NoError(t, err)
+		assert.True(t, locked)
+	})
+}
+
+func TestQueries(t *testing.T) {
+	idx, err := Open(t.TempDir(), true)
+	require.NoError(t, err)
+	defer idx.Close()
+
+	require.NoError(t, idx.Apply(
+		IndexOperation(testDocument("a", "c1", "Deploying the new release to production", 1)),
+		IndexOperation(testDocument("b", "c1", "production is down", 2)),
+		IndexOperation(testDocument("c", "c2", "the release notes are ready", 3)),
+		IndexOperation(testDocument("d", "c2", "明日東京で会議があります", 4)),
+	))
+	require.NoError(t, idx.Commit())
+
+	int64Ptr := func(v int64) *int64 { return &v }
+	for name, tc := range map[string]struct {
+		query    Query
+		expected []string
+	}{
+		"terms":        {TermsQuery{Field: "channel_id", Terms: []string{"c1", "missing"}}, []string{"a", "b"}},
+		"prefix":       {PrefixQuery{Field: "message", Prefix: "produc"}, []string{"a", "b"}},
+		"match all":    {MatchQuery{Field: "message", Text: "production release"}, []string{"a"}},
+		"match any":    {MatchQuery{Field: "message", Text: "production release", Or: true}, []string{"a", "b", "c"}},
+		"stop words":   {MatchQuery{Field: "message", Text: "the"}, nil},
+		"phrase":       {PhraseQuery{Field: "message", Text: "new release"}, []string{"a"}},
+		"phrase order": {PhraseQuery{Field: "message", Text: "release new"}, nil},
+		"phrase gaps":  {PhraseQuery{Field: "message", Text: "release to production"}, []string{"a"}},
+		"cjk phrase":   {PhraseQuery{Field: "message", Text: "東京で会議"}, []string{"d"}},
+		"cjk mismatch": {PhraseQuery{Field: "message", Text: "京都"}, nil},
+		"range":        {RangeQuery{Field: "create_at", Min: int64Ptr(2), Max: int64Ptr(3)}, []string{"b", "c"}},
+		"open range":   {RangeQuery{Field: "create_at", Min: int64Ptr(3)}, []string{"c", "d"}},
+		"match none":   {MatchNoneQuery{}, nil},
+		"bool": {BoolQuery{
+			Must:    []Query{TermQuery{Field: "channel_id", Term: "c1"}},
+			MustNot: []Query{MatchQuery{Field: "message", Text: "down"}},
+		}, []string{"a"}},
+		"bool should": {BoolQuery{
+			Should: []Query{MatchQuery{Field: "message", Text: "down"}, MatchQuery{Field: "message", Text: "notes"}},
+		}, []string{"b", "c"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, search(t, idx, tc.query))
+		})
+	}
+
+	t.Run("query string", func(t *testing.T) {
+		for text, expected := range map[string][]string{
+			`deploy`:        {"a"},
+			`produc*`:       {"a", "b"},
+			`"is down"`:     {"b"},
+			`release-notes`: {"c"},
+			`東京`:            {"d"},
+			`東`:             {"d"},
+			`会議`:            {"d"},
+		} {
+			clauses := ParseQueryString(text)
+			require.Len(t, clauses, 1)
+			assert.Equal(t, expected, search(t, idx, clauses[0].Query("message")), text)
+		}
+		assert.Nil(t, Clause{Text: "the"}.Query("message"))
+	})
+
+	t.Run("sort, paging and matches", func(t *testing.T) {
+		result, err := idx.Search(&SearchRequest{
+			Query:  MatchAllQuery{},
+			SortBy: "create_at",
+			From:   1,
+			Size:   2,
+			Matches: map[string]Query{
+				"release":    MatchQuery{Field: "message", Text: "release"},
+				"production": MatchQuery{Field: "message", Text: "production"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Total)
+		assert.Equal(t, []string{"c", "b"}, result.IDs)
+		assert.Equal(t, map[string][]string{"c": {"release"}, "b": {"production"}}, result.Matches)
+	})
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Version is the version of the on-disk format of the index.
+const Version = 1
+
+const manifestFile = "manifest.json"
+
+// manifest lists the segments of a generation of the index. Writing the manifest is what
+// makes a commit visible.
+type manifest struct {
+	Version     int               `json:"version"`
+	Generation  uint64            `json:"generation"`
+	NextSegment uint64            `json:"next_segment"`
+	Segments    []manifestSegment `json:"segments"`
+}
+
+type manifestSegment struct {
+	Name      string `json:"name"`
+	Docs      int    `json:"docs"`
+	Deletions string `json:"deletions,omitempty"`
+	Deleted   int    `json:"deleted,omitempty"`
+}
+
+// readManifest reads the manifest of the index, returning an empty manifest when the index
+// hasn't been written yet.
+func readManifest(dir string) (*manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &manifest{Version: Version}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode the index manifest: %w", err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("unsupported index version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// writeManifest atomically replaces the manifest of the index.
+func writeManifest(dir string, m *manifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(filepath.Join(dir, manifestFile), data)
+}
+
+// WriteFileAtomic durably writes a file, which is either missing or complete after a crash.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Directories can't be synced on every platform, the rename is durable enough there.
+	_ = d.Sync()
+	return nil
+}
+
+func segmentName(id uint64) string {
+	return fmt.Sprintf("%016x.seg", id)
+}
+
+func deletionsName(segment string, generation uint64) string {
+	return fmt.Sprintf("%s.%016x.del", segment, generation)
+}
+
+func writeDeletions(path string, deleted docSet) error {
+	data := make([]byte, 0, len(deleted)*8)
+	for _, w := range deleted {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return writeFileSync(path, data)
+}
+
+func readDeletions(path string, docCount int) (docSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	deleted := newDocSet(docCount)
+	if len(data) != len(deleted)*8 {
+		return nil, fmt.Errorf("corrupt index deletions %s", filepath.Base(path))
+	}
+	for i := range deleted {
+		deleted[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	return deleted, nil
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"sort"
+)
+
+// maxPrefixExpansions bounds the number of terms matched by a prefix query.
+const maxPrefixExpansions = 16384
+
+// Query selects documents of the index.
+type Query interface {
+	docs(s *segment) (docSet, error)
+}
+
+// MatchAllQuery matches every document.
+type MatchAllQuery struct{}
+
+func (q MatchAllQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	set.fill(s.docCount)
+	return set, nil
+}
+
+// MatchNoneQuery matches no document.
+type MatchNoneQuery struct{}
+
+func (q MatchNoneQuery) docs(s *segment) (docSet, error) {
+	return newDocSet(s.docCount), nil
+}
+
+// TermQuery matches the documents with the given keyword, or the given analyzed term.
+type TermQuery struct {
+	Field string
+	Term  string
+}
+
+func (q TermQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	it, err := s.postings(termKey(q.Field, q.Term))
+	if err != nil || it == nil {
+		return set, err
+	}
+	for it.next(false) {
+		set.add(it.doc)
+	}
+	return set, it.err()
+}
+
+// TermsQuery matches the documents with any of the given keywords.
+type TermsQuery struct {
+	Field string
+	Terms []string
+}
+
+func (q TermsQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	for _, term := range q.Terms {
+		termSet, err := TermQuery{Field: q.Field, Term: term}.docs(s)
+		if err != nil {
+			return nil, err
+		}
+		set.or(termSet)
+	}
+	return set, nil
+}
+
+// PrefixQuery matches the documents with a keyword or term starting with the given prefix.
+type PrefixQuery struct {
+	Field  string
+	Prefix string
+}
+
+func (q PrefixQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	err := s.forEachTerm(termKey(q.Field, q.Prefix), maxPrefixExpansions, func(_ []byte, offset uint64) error {
+		it, err := s.postingsAt(offset)
+		if err != nil {
+			return err
+		}
+		for it.next(false) {
+			set.add(it.doc)
+		}
+		return it.err()
+	})
+	return set, err
+}
+
+// MatchQuery matches the documents whose text contains the terms of the analyzed text: all
+// of them, or any of them when Or is set. A text without any term matches no document.
+type MatchQuery struct {
+	Field string
+	Text  string
+	Or    bool
+}
+
+func (q MatchQuery) docs(s *segment) (docSet, error) {
+	tokens := Analyze(q.Text)
+	if len(tokens) == 0 {
+		return newDocSet(s.docCount), nil
+	}
+	var set docSet
+	for _, token := range tokens {
+		termSet, err := TermQuery{Field: q.Field, Term: token.Term}.docs(s)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case set == nil:
+			set = termSet
+		case q.Or:
+			set.or(termSet)
+		default:
+			set.and(termSet)
+		}
+	}
+	return set, nil
+}
+
+// PhraseQuery matches the documents whose text contains the terms of the analyzed text, in
+// the same order and at the same distance.
+type PhraseQuery struct {
+	Field string
+	Text  string
+}
+
+func (q PhraseQuery) docs(s *segment) (docSet, error) {
+	tokens := Analyze(q.Text)
+	switch len(tokens) {
+	case 0:
+		return newDocSet(s.docCount), nil
+	case 1:
+		return TermQuery{Field: q.Field, Term: tokens[0].Term}.docs(s)
+	}
+
+	// starts holds, for every candidate document, the positions where the phrase may start.
+	var starts map[uint32][]uint32
+	for k, token := range tokens {
+		offset := uint32(token.Position - tokens[0].Position)
+		it, err := s.postings(termKey(q.Field, token.Term))
+		if err != nil {
+			return nil, err
+		}
+		if it == nil {
+			return newDocSet(s.docCount), nil
+		}
+		next := map[uint32][]uint32{}
+		for it.next(true) {
+			if k == 0 {
+				next[it.doc] = append([]uint32(nil), it.positions...)
+				continue
+			}
+			candidates, ok := starts[it.doc]
+			if !ok {
+				continue
+			}
+			var matching []uint32
+			for _, start := range candidates {
+				if containsPosition(it.positions, start+offset) {
+					matching = append(matching, start)
+				}
+			}
+			if len(matching) > 0 {
+				next[it.doc] = matching
+			}
+		}
+		if err := it.err(); err != nil {
+			return nil, err
+		}
+		starts = next
+		if len(starts) == 0 {
+			break
+		}
+	}
+
+	set := newDocSet(s.docCount)
+	for doc := range starts {
+		set.add(doc)
+	}
+	return set, nil
+}
+
+func containsPosition(positions []uint32, position uint32) bool {
+	i := sort.Search(len(positions), func(i int) bool { return positions[i] >= position })
+	return i < len(positions) && positions[i] == position
+}
+
+// RangeQuery matches the documents whose number field is within the given bounds, which
+// are inclusive and ignored when nil.
+type RangeQuery struct {
+	Field string
+	Min   *int64
+	Max   *int64
+}
+
+func (q RangeQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	for doc := 0; doc < s.docCount; doc++ {
+		v := s.number(q.Field, uint32(doc))
+		if (q.Min == nil || v >= *q.Min) && (q.Max == nil || v <= *q.Max) {
+			set.add(uint32(doc))
+		}
+	}
+	return set, nil
+}
+
+// BoolQuery matches the documents matching all the Must queries, at least one of the Should
+// queries when there are any, and none of the MustNot queries.
+type BoolQuery struct {
+	Must    []Query
+	Should  []Query
+	MustNot []Query
+}
+
+func (q BoolQuery) docs(s *segment) (docSet, error) {
+	set := newDocSet(s.docCount)
+	set.fill(s.docCount)
+	for _, must := range q.Must {
+		mustSet, err := must.docs(s)
+		if err != nil {
+			return nil, err
+		}
+		set.and(mustSet)
+	}
+	if len(q.Should) > 0 {
+		shouldSet := newDocSet(s.docCount)
+		for _, should := range q.Should {
+			matching, err := should.docs(s)
+			if err != nil {
+				return nil, err
+			}
+			shouldSet.or(matching)
+		}
+		set.and(shouldSet)
+	}
+	for _, mustNot := range q.MustNot {
+		excluded, err := mustNot.docs(s)
+		if err != nil {
+			return nil, err
+		}
+		set.andNot(excluded)
+	}
+	return set, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Clause is a term typed by a user: a word, a quoted phrase or a word ending with a wildcard.
+type Clause struct {
+	Text   string
+	Phrase bool
+	Prefix bool
+}
+
+// ParseQueryString splits a search typed by a user into clauses.
+func ParseQueryString(text string) []Clause {
+	var clauses []Clause
+	for text != "" {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			break
+		}
+
+		if text[0] == '"' {
+			end := strings.IndexByte(text[1:], '"')
+			if end < 0 {
+				// An unterminated quote is just a word.
+				text = text[1:]
+				continue
+			}
+			if phrase := strings.TrimSpace(text[1 : end+1]); phrase != "" {
+				clauses = append(clauses, Clause{Text: phrase, Phrase: true})
+			}
+			text = text[end+2:]
+			continue
+		}
+
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		}
+		w := text[:end]
+		text = text[end:]
+
+		clause := Clause{Text: w}
+		if strings.HasSuffix(w, "*") {
+			clause.Text = strings.TrimRight(w, "*")
+			clause.Prefix = true
+		}
+		if clause.Text != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// Query returns the query matching the clause in any of the given text fields, or nil when
+// the clause has no term to match, like a word too common to be indexed.
+func (c Clause) Query(fields ...string) Query {
+	var should []Query
+	for _, field := range fields {
+		if q := c.fieldQuery(field); q != nil {
+			should = append(should, q)
+		}
+	}
+	switch len(should) {
+	case 0:
+		return nil
+	case 1:
+		return should[0]
+	}
+	return BoolQuery{Should: should}
+}
+
+func (c Clause) fieldQuery(field string) Query {
+	if c.Prefix {
+		words := splitWords(c.Text)
+		if len(words) == 0 {
+			return nil
+		}
+		last := words[len(words)-1]
+		prefix := PrefixQuery{Field: field, Prefix: normalizeWord(last.text)}
+		if len(words) == 1 {
+			return prefix
+		}
+		rest := make([]string, 0, len(words)-1)
+		for _, w := range words[:len(words)-1] {
+			rest = append(rest, w.text)
+		}
+		return BoolQuery{Must: []Query{PhraseQuery{Field: field, Text: strings.Join(rest, " ")}, prefix}}
+	}
+
+	tokens := Analyze(c.Text)
+	switch {
+	case len(tokens) == 0:
+		return nil
+	case len(tokens) > 1 || c.Phrase:
+		return PhraseQuery{Field: field, Text: c.Text}
+	case IsCJK(tokens[0].Term) && utf8.RuneCountInString(tokens[0].Term) == 1:
+		// A single character is only indexed as part of the bigrams it starts.
+		return BoolQuery{Should: []Query{
+			TermQuery{Field: field, Term: tokens[0].Term},
+			PrefixQuery{Field: field, Prefix: tokens[0].Term},
+		}}
+	}
+	return TermQuery{Field: field, Term: tokens[0].Term}
+}
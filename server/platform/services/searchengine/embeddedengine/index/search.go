@@ -0,0 +1,122 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"sort"
+)
+
+// SearchRequest describes a search of the index.
+type SearchRequest struct {
+	Query Query
+	// SortBy is the number field sorting the results in descending order. The results are
+	// sorted by ID when empty.
+	SortBy string
+	From   int
+	// Size is the maximum number of results, all the results are returned when not positive.
+	Size int
+	// Matches are labelled queries, checked against each result.
+	Matches map[string]Query
+}
+
+// SearchResult holds the results of a search.
+type SearchResult struct {
+	IDs []string
+	// Total is the number of matching documents, regardless of From and Size.
+	Total int
+	// Matches lists, for every result, the labels of the matching queries of the request.
+	Matches map[string][]string
+}
+
+type hit struct {
+	segment *liveSegment
+	doc     uint32
+	id      string
+	sortKey int64
+}
+
+// Search searches the index.
+func (i *Index) Search(req *SearchRequest) (*SearchResult, error) {
+	s := i.acquire()
+	defer s.decRef()
+
+	var hits []hit
+	for _, seg := range s.segments {
+		set, err := req.Query.docs(seg.segment)
+		if err != nil {
+			return nil, err
+		}
+		if seg.deleted != nil {
+			set.andNot(seg.deleted)
+		}
+		var idErr error
+		set.forEach(func(doc uint32) {
+			h := hit{segment: seg, doc: doc}
+			if req.SortBy != "" {
+				h.sortKey = seg.number(req.SortBy, doc)
+			} else if idErr == nil {
+				h.id, idErr = seg.id(doc)
+			}
+			hits = append(hits, h)
+		})
+		if idErr != nil {
+			return nil, idErr
+		}
+	}
+
+	if req.SortBy != "" {
+		sort.SliceStable(hits, func(a, b int) bool { return hits[a].sortKey > hits[b].sortKey })
+	} else {
+		sort.Slice(hits, func(a, b int) bool { return hits[a].id < hits[b].id })
+	}
+
+	result := &SearchResult{Total: len(hits)}
+	from := min(max(req.From, 0), len(hits))
+	hits = hits[from:]
+	if req.Size > 0 && len(hits) > req.Size {
+		hits = hits[:req.Size]
+	}
+
+	matchSets := map[*liveSegment]map[string]docSet{}
+	for _, h := range hits {
+		id := h.id
+		if id == "" {
+			var err error
+			if id, err = h.segment.id(h.doc); err != nil {
+				return nil, err
+			}
+		}
+		result.IDs = append(result.IDs, id)
+
+		if len(req.Matches) == 0 {
+			continue
+		}
+		sets, ok := matchSets[h.segment]
+		if !ok {
+			sets = map[string]docSet{}
+			for label, q := range req.Matches {
+				set, err := q.docs(h.segment.segment)
+				if err != nil {
+					return nil, err
+				}
+				sets[label] = set
+			}
+			matchSets[h.segment] = sets
+		}
+		var labels []string
+		for label, set := range sets {
+			if set.has(h.doc) {
+				labels = append(labels, label)
+			}
+		}
+		if len(labels) > 0 {
+			sort.Strings(labels)
+			if result.Matches == nil {
+				result.Matches = map[string][]string{}
+			}
+			result.Matches[id] = labels
+		}
+	}
+	return result, nil
+}
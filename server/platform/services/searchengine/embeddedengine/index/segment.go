@@ -0,0 +1,434 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/blevesearch/mmap-go"
+	"github.com/blevesearch/vellum"
+)
+
+// A segment is an immutable file holding a set of documents, laid out as:
+//
+//	magic
+//	postings of every term: docFreq, then (docDelta, positionCount, positionDeltas...)
+//	ids of the documents: length, bytes
+//	offsets of the ids, one uint64 per document
+//	number columns: fieldCount, then (nameLength, name, one int64 per document)
+//	terms FST, mapping "field\x00term" to the offset of its postings
+//	ids FST, mapping the ids to the ordinal of their document
+//	footer: docCount, idOffsetsStart, numbersStart, termsFSTStart, idsFSTStart, idsFSTEnd, magic
+const segmentMagic = "MMIDXSG1"
+
+const segmentFooterSize = 6*8 + len(segmentMagic)
+
+var errCorruptSegment = errors.New("corrupt index segment")
+
+type posting struct {
+	doc       uint32
+	positions []uint32
+}
+
+func termKey(field, term string) []byte {
+	key := make([]byte, 0, len(field)+1+len(term))
+	key = append(key, field...)
+	key = append(key, 0)
+	return append(key, term...)
+}
+
+type countingWriter struct {
+	w *bufio.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func (c *countingWriter) writeUvarint(v uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	_, err := c.Write(buf[:binary.PutUvarint(buf[:], v)])
+	return err
+}
+
+func (c *countingWriter) writeUint64(v uint64) error {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	_, err := c.Write(buf[:])
+	return err
+}
+
+func encodePostings(buf []byte, postings []posting) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(postings)))
+	var prevDoc uint32
+	for _, p := range postings {
+		buf = binary.AppendUvarint(buf, uint64(p.doc-prevDoc))
+		prevDoc = p.doc
+		buf = binary.AppendUvarint(buf, uint64(len(p.positions)))
+		var prevPosition uint32
+		for _, position := range p.positions {
+			buf = binary.AppendUvarint(buf, uint64(position-prevPosition))
+			prevPosition = position
+		}
+	}
+	return buf
+}
+
+// termSource calls yield with every term of a segment being written, in ascending order.
+type termSource func(yield func(key []byte, postings []posting) error) error
+
+// writeSegment writes a segment holding the documents with the given ids, numbers and terms.
+func writeSegment(path string, ids []string, numbers map[string][]int64, terms termSource) (err error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(path)
+		}
+	}()
+
+	w := &countingWriter{w: bufio.NewWriterSize(f, 1<<16)}
+	if _, err = w.Write([]byte(segmentMagic)); err != nil {
+		return err
+	}
+
+	var termsFST bytes.Buffer
+	termsBuilder, err := vellum.New(&termsFST, nil)
+	if err != nil {
+		return err
+	}
+	var buf []byte
+	err = terms(func(key []byte, postings []posting) error {
+		if len(postings) == 0 {
+			return nil
+		}
+		offset := w.n
+		buf = encodePostings(buf[:0], postings)
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		return termsBuilder.Insert(key, offset)
+	})
+	if err != nil {
+		return err
+	}
+	if err = termsBuilder.Close(); err != nil {
+		return err
+	}
+
+	idOffsets := make([]uint64, len(ids))
+	for i, id := range ids {
+		idOffsets[i] = w.n
+		if err = w.writeUvarint(uint64(len(id))); err != nil {
+			return err
+		}
+		if _, err = w.Write([]byte(id)); err != nil {
+			return err
+		}
+	}
+	idOffsetsStart := w.n
+	for _, offset := range idOffsets {
+		if err = w.writeUint64(offset); err != nil {
+			return err
+		}
+	}
+
+	numbersStart := w.n
+	fields := make([]string, 0, len(numbers))
+	for field := range numbers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if err = w.writeUvarint(uint64(len(fields))); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err = w.writeUvarint(uint64(len(field))); err != nil {
+			return err
+		}
+		if _, err = w.Write([]byte(field)); err != nil {
+			return err
+		}
+		for _, v := range numbers[field] {
+			if err = w.writeUint64(uint64(v)); err != nil {
+				return err
+			}
+		}
+	}
+
+	termsFSTStart := w.n
+	if _, err = w.Write(termsFST.Bytes()); err != nil {
+		return err
+	}
+
+	idsFSTStart := w.n
+	sortedIDs := make([]int, len(ids))
+	for i := range sortedIDs {
+		sortedIDs[i] = i
+	}
+	sort.Slice(sortedIDs, func(a, b int) bool { return ids[sortedIDs[a]] < ids[sortedIDs[b]] })
+	idsBuilder, err := vellum.New(w, nil)
+	if err != nil {
+		return err
+	}
+	for _, ordinal := range sortedIDs {
+		if err = idsBuilder.Insert([]byte(ids[ordinal]), uint64(ordinal)); err != nil {
+			return err
+		}
+	}
+	if err = idsBuilder.Close(); err != nil {
+		return err
+	}
+	idsFSTEnd := w.n
+
+	for _, v := range []uint64{uint64(len(ids)), idOffsetsStart, numbersStart, termsFSTStart, idsFSTStart, idsFSTEnd} {
+		if err = w.writeUint64(v); err != nil {
+			return err
+		}
+	}
+	if _, err = w.Write([]byte(segmentMagic)); err != nil {
+		return err
+	}
+
+	if err = w.w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// segment is an open segment file, shared by the snapshots using it.
+type segment struct {
+	name     string
+	data     mmap.MMap
+	docCount int
+	idsStart uint64
+	numbers  map[string][]int64
+	terms    *vellum.FST
+	ids      *vellum.FST
+	refs     atomic.Int32
+}
+
+func openSegment(dir, name string) (*segment, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	data, err := mmap.Map(f, mmap.RDONLY, 0)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &segment{name: name, data: data}
+	if err := s.load(); err != nil {
+		data.Unmap()
+		return nil, fmt.Errorf("failed to open segment %s: %w", name, err)
+	}
+	s.refs.Store(1)
+	return s, nil
+}
+
+func (s *segment) load() error {
+	data := s.data
+	if len(data) < len(segmentMagic)+segmentFooterSize || string(data[:len(segmentMagic)]) != segmentMagic || string(data[len(data)-len(segmentMagic):]) != segmentMagic {
+		return errCorruptSegment
+	}
+	footer := data[len(data)-segmentFooterSize:]
+	var values [6]uint64
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(footer[i*8:])
+	}
+	docCount, idOffsetsStart, numbersStart, termsFSTStart, idsFSTStart, idsFSTEnd := values[0], values[1], values[2], values[3], values[4], values[5]
+	if idOffsetsStart+docCount*8 != numbersStart || numbersStart > termsFSTStart || termsFSTStart > idsFSTStart || idsFSTStart > idsFSTEnd || idsFSTEnd > uint64(len(data)-segmentFooterSize) {
+		return errCorruptSegment
+	}
+	s.docCount = int(docCount)
+	s.idsStart = idOffsetsStart
+
+	r := &byteReader{data: data[numbersStart:termsFSTStart]}
+	fieldCount := r.uvarint()
+	s.numbers = make(map[string][]int64)
+	for range fieldCount {
+		field := string(r.bytes(int(r.uvarint())))
+		raw := r.bytes(s.docCount * 8)
+		if r.err != nil {
+			return r.err
+		}
+		values := make([]int64, s.docCount)
+		for i := range values {
+			values[i] = int64(binary.LittleEndian.Uint64(raw[i*8:]))
+		}
+		s.numbers[field] = values
+	}
+	if r.err != nil {
+		return r.err
+	}
+
+	var err error
+	if s.terms, err = vellum.Load(data[termsFSTStart:idsFSTStart]); err != nil {
+		return err
+	}
+	if s.ids, err = vellum.Load(data[idsFSTStart:idsFSTEnd]); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *segment) incRef() {
+	s.refs.Add(1)
+}
+
+func (s *segment) decRef() {
+	if s.refs.Add(-1) == 0 {
+		s.data.Unmap()
+	}
+}
+
+// id returns the id of the document with the given ordinal.
+func (s *segment) id(doc uint32) (string, error) {
+	offsetPos := s.idsStart + uint64(doc)*8
+	r := &byteReader{data: s.data[binary.LittleEndian.Uint64(s.data[offsetPos:]):]}
+	id := string(r.bytes(int(r.uvarint())))
+	return id, r.err
+}
+
+// ordinal returns the ordinal of the document with the given id.
+func (s *segment) ordinal(id string) (uint32, bool, error) {
+	ordinal, ok, err := s.ids.Get([]byte(id))
+	return uint32(ordinal), ok, err
+}
+
+func (s *segment) number(field string, doc uint32) int64 {
+	values, ok := s.numbers[field]
+	if !ok {
+		return 0
+	}
+	return values[doc]
+}
+
+// postings returns an iterator over the postings of the given term, or nil when the term
+// isn't in the segment.
+func (s *segment) postings(key []byte) (*postingsIterator, error) {
+	offset, ok, err := s.terms.Get(key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return s.postingsAt(offset)
+}
+
+func (s *segment) postingsAt(offset uint64) (*postingsIterator, error) {
+	if offset >= uint64(len(s.data)) {
+		return nil, errCorruptSegment
+	}
+	it := &postingsIterator{r: byteReader{data: s.data[offset:]}}
+	it.remaining = it.r.uvarint()
+	return it, it.r.err
+}
+
+// forEachTerm calls f with every term starting with the given prefix, up to limit terms
+// when limit is positive.
+func (s *segment) forEachTerm(prefix []byte, limit int, f func(key []byte, offset uint64) error) error {
+	it, err := s.terms.Iterator(prefix, prefixEnd(prefix))
+	for n := 0; err == nil && (limit <= 0 || n < limit); n++ {
+		key, offset := it.Current()
+		if err := f(key, offset); err != nil {
+			return err
+		}
+		err = it.Next()
+	}
+	if err != nil && err != vellum.ErrIteratorDone {
+		return err
+	}
+	return nil
+}
+
+// prefixEnd returns the smallest key greater than all the keys starting with prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+type postingsIterator struct {
+	r         byteReader
+	remaining uint64
+	doc       uint32
+	positions []uint32
+}
+
+// next moves to the next posting, decoding its positions when requested.
+func (it *postingsIterator) next(withPositions bool) bool {
+	if it.remaining == 0 || it.r.err != nil {
+		return false
+	}
+	it.remaining--
+	it.doc += uint32(it.r.uvarint())
+	count := it.r.uvarint()
+	it.positions = it.positions[:0]
+	var position uint32
+	for range count {
+		position += uint32(it.r.uvarint())
+		if withPositions {
+			it.positions = append(it.positions, position)
+		}
+	}
+	return it.r.err == nil
+}
+
+func (it *postingsIterator) err() error {
+	return it.r.err
+}
+
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+func (r *byteReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errCorruptSegment
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *byteReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = errCorruptSegment
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
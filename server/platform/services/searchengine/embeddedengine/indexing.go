@@ -0,0 +1,251 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddedengine
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+func (e *EmbeddedEngine) IndexPost(post *model.Post, teamId string) *model.AppError {
+	if appErr := e.change("EmbeddedEngine.IndexPost", IndexPosts, index.IndexOperation(postDocument(post, teamId))); appErr != nil {
+		return appErr
+	}
+
+	if metrics := e.platform.Metrics(); metrics != nil {
+		metrics.IncrementPostIndexCounter()
+	}
+
+	return nil
+}
+
+// IndexPostsBatch indexes the posts of a batch of the indexing job.
+func (e *EmbeddedEngine) IndexPostsBatch(posts []*model.PostForIndexing) *model.AppError {
+	ops := make([]*index.Operation, 0, len(posts))
+	for _, post := range posts {
+		if post.DeleteAt > 0 {
+			ops = append(ops, index.DeleteOperation(post.Id))
+		} else {
+			ops = append(ops, index.IndexOperation(postDocument(&post.Post, post.TeamId)))
+		}
+	}
+	return e.change("EmbeddedEngine.IndexPostsBatch", IndexPosts, ops...)
+}
+
+func (e *EmbeddedEngine) DeletePost(post *model.Post) *model.AppError {
+	return e.change("EmbeddedEngine.DeletePost", IndexPosts, index.DeleteOperation(post.Id))
+}
+
+func (e *EmbeddedEngine) DeleteChannelPosts(rctx request.CTX, channelID string) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteChannelPosts", IndexPosts, index.DeleteTermOperation(fieldChannelID, channelID))
+}
+
+func (e *EmbeddedEngine) DeleteUserPosts(rctx request.CTX, userID string) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteUserPosts", IndexPosts, index.DeleteTermOperation(fieldUserID, userID))
+}
+
+func (e *EmbeddedEngine) IndexChannel(rctx request.CTX, channel *model.Channel, userIDs, teamMemberIDs []string) *model.AppError {
+	if appErr := e.change("EmbeddedEngine.IndexChannel", IndexChannels, index.IndexOperation(channelDocument(channel, userIDs, teamMemberIDs))); appErr != nil {
+		return appErr
+	}
+
+	if metrics := e.platform.Metrics(); metrics != nil {
+		metrics.IncrementChannelIndexCounter()
+	}
+
+	return nil
+}
+
+// SyncBulkIndexChannels indexes the channels, and commits the changes before returning when
+// this node is the writer.
+func (e *EmbeddedEngine) SyncBulkIndexChannels(rctx request.CTX, channels []*model.Channel, getUserIDsForChannel func(channel *model.Channel) ([]string, error), teamMemberIDs []string) *model.AppError {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	ops := make([]*index.Operation, 0, len(channels))
+	for _, channel := range channels {
+		userIDs, err := getUserIDsForChannel(channel)
+		if err != nil {
+			return model.NewAppError("EmbeddedEngine.SyncBulkIndexChannels", model.NoTranslation, nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		ops = append(ops, index.IndexOperation(channelDocument(channel, userIDs, teamMemberIDs)))
+	}
+
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if !e.ready.Load() {
+		return notStartedError("EmbeddedEngine.SyncBulkIndexChannels")
+	}
+	if err := e.apply(IndexChannels, ops...); err != nil {
+		return model.NewAppError("EmbeddedEngine.SyncBulkIndexChannels", "embedded_search.index.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if e.lock != nil {
+		if err := e.indexes[IndexChannels].Commit(); err != nil {
+			return model.NewAppError("EmbeddedEngine.SyncBulkIndexChannels", "embedded_search.index.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	if metrics := e.platform.Metrics(); metrics != nil {
+		for range channels {
+			metrics.IncrementChannelIndexCounter()
+		}
+	}
+
+	return nil
+}
+
+// IndexChannelsBatch indexes the channels of a batch of the indexing job, with their members
+// and the members of their teams.
+func (e *EmbeddedEngine) IndexChannelsBatch(channels []*model.Channel, userIDs, teamMemberIDs map[string][]string) *model.AppError {
+	ops := make([]*index.Operation, 0, len(channels))
+	for _, channel := range channels {
+		ops = append(ops, index.IndexOperation(channelDocument(channel, userIDs[channel.Id], teamMemberIDs[channel.Id])))
+	}
+	return e.change("EmbeddedEngine.IndexChannelsBatch", IndexChannels, ops...)
+}
+
+func (e *EmbeddedEngine) DeleteChannel(channel *model.Channel) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteChannel", IndexChannels, index.DeleteOperation(channel.Id))
+}
+
+func (e *EmbeddedEngine) IndexUser(rctx request.CTX, user *model.User, teamsIds, channelsIds []string) *model.AppError {
+	if appErr := e.change("EmbeddedEngine.IndexUser", IndexUsers, index.IndexOperation(userDocument(user, teamsIds, channelsIds))); appErr != nil {
+		return appErr
+	}
+
+	if metrics := e.platform.Metrics(); metrics != nil {
+		metrics.IncrementUserIndexCounter()
+	}
+
+	return nil
+}
+
+// IndexUsersBatch indexes the users of a batch of the indexing job.
+func (e *EmbeddedEngine) IndexUsersBatch(users []*model.UserForIndexing) *model.AppError {
+	ops := make([]*index.Operation, 0, len(users))
+	for _, user := range users {
+		ops = append(ops, index.IndexOperation(userDocumentForIndexing(user)))
+	}
+	return e.change("EmbeddedEngine.IndexUsersBatch", IndexUsers, ops...)
+}
+
+func (e *EmbeddedEngine) DeleteUser(user *model.User) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteUser", IndexUsers, index.DeleteOperation(user.Id))
+}
+
+func (e *EmbeddedEngine) IndexFile(file *model.FileInfo, channelId string) *model.AppError {
+	if appErr := e.change("EmbeddedEngine.IndexFile", IndexFiles, index.IndexOperation(fileDocument(file, channelId, file.Content))); appErr != nil {
+		return appErr
+	}
+
+	if metrics := e.platform.Metrics(); metrics != nil {
+		metrics.IncrementFileIndexCounter()
+	}
+
+	return nil
+}
+
+// IndexFilesBatch indexes the files of a batch of the indexing job, and removes the files
+// which shouldn't be indexed anymore.
+func (e *EmbeddedEngine) IndexFilesBatch(files []*model.FileForIndexing) *model.AppError {
+	ops := make([]*index.Operation, 0, len(files))
+	for _, file := range files {
+		if file.ShouldIndex() {
+			ops = append(ops, index.IndexOperation(fileDocument(&file.FileInfo, file.ChannelId, file.Content)))
+		} else {
+			ops = append(ops, index.DeleteOperation(file.Id))
+		}
+	}
+	return e.change("EmbeddedEngine.IndexFilesBatch", IndexFiles, ops...)
+}
+
+func (e *EmbeddedEngine) DeleteFile(fileID string) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteFile", IndexFiles, index.DeleteOperation(fileID))
+}
+
+func (e *EmbeddedEngine) DeletePostFiles(rctx request.CTX, postID string) *model.AppError {
+	return e.change("EmbeddedEngine.DeletePostFiles", IndexFiles, index.DeleteTermOperation(fieldPostID, postID))
+}
+
+func (e *EmbeddedEngine) DeleteUserFiles(rctx request.CTX, userID string) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteUserFiles", IndexFiles, index.DeleteTermOperation(fieldCreatorID, userID))
+}
+
+func (e *EmbeddedEngine) DeleteFilesBatch(rctx request.CTX, endTime, limit int64) *model.AppError {
+	return e.change("EmbeddedEngine.DeleteFilesBatch", IndexFiles, index.DeleteRangeOperation(fieldCreateAt, endTime, int(limit)))
+}
+
+func (e *EmbeddedEngine) TestConfig(rctx request.CTX, cfg *model.Config) *model.AppError {
+	if !*cfg.EmbeddedSearchSettings.EnableIndexing {
+		return model.NewAppError("EmbeddedEngine.TestConfig", "embedded_search.test_config.indexing_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	dir := *cfg.EmbeddedSearchSettings.IndexDir
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return model.NewAppError("EmbeddedEngine.TestConfig", "embedded_search.test_config.index_dir.app_error", map[string]any{"IndexDir": dir}, "", http.StatusBadRequest).Wrap(err)
+	}
+	f, err := os.CreateTemp(dir, ".test")
+	if err != nil {
+		return model.NewAppError("EmbeddedEngine.TestConfig", "embedded_search.test_config.index_dir.app_error", map[string]any{"IndexDir": dir}, "", http.StatusBadRequest).Wrap(err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return nil
+}
+
+func (e *EmbeddedEngine) PurgeIndexes(rctx request.CTX) *model.AppError {
+	return e.PurgeIndexList(rctx, allIndexes)
+}
+
+func (e *EmbeddedEngine) PurgeIndexList(rctx request.CTX, indexes []string) *model.AppError {
+	for _, name := range indexes {
+		if !slices.Contains(allIndexes, name) {
+			return model.NewAppError("EmbeddedEngine.PurgeIndexList", "embedded_search.purge_indexes.unknown_index.app_error", map[string]any{"unknown_index": name}, "", http.StatusBadRequest)
+		}
+	}
+
+	for _, name := range indexes {
+		if appErr := e.change("EmbeddedEngine.PurgeIndexList", name, index.PurgeOperation()); appErr != nil {
+			return appErr
+		}
+	}
+
+	return nil
+}
+
+// RefreshIndexes makes the changes of the indexes searchable: by committing them on the
+// writer, or by loading the last commit on the other nodes.
+func (e *EmbeddedEngine) RefreshIndexes(rctx request.CTX) *model.AppError {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	if !e.ready.Load() {
+		return notStartedError("EmbeddedEngine.RefreshIndexes")
+	}
+
+	var err error
+	if e.lock != nil {
+		err = e.commitIndexes()
+	} else {
+		err = e.refreshIndexes()
+	}
+	if err != nil {
+		return model.NewAppError("EmbeddedEngine.RefreshIndexes", "embedded_search.index.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return nil
+}
+
+func (e *EmbeddedEngine) DataRetentionDeleteIndexes(rctx request.CTX, cutoff time.Time) *model.AppError {
+	return e.change("EmbeddedEngine.DataRetentionDeleteIndexes", IndexPosts, index.DeleteRangeOperation(fieldCreateAt, cutoff.UnixMilli(), 0))
+}
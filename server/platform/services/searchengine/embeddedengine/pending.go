@@ -0,0 +1,82 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddedengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+// pendingDir is the directory of the index directory where the nodes which don't write the
+// indexes save their changes, until the writer applies them.
+const pendingDir = "pending"
+
+// pendingChanges are the changes of an index saved by a node which doesn't write it.
+type pendingChanges struct {
+	Index      string             `json:"index"`
+	Operations []*index.Operation `json:"operations"`
+}
+
+// savePendingChanges saves the changes of an index for the writer. The changes are kept on
+// disk rather than sent to the writer, so that they aren't lost when the writer goes away
+// before committing them, or when no node is writing the indexes for a while.
+func savePendingChanges(dir string, changes *pendingChanges) error {
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	pendingPath := filepath.Join(dir, pendingDir)
+	if err := os.MkdirAll(pendingPath, 0700); err != nil {
+		return err
+	}
+
+	// The names sort in the order the changes were made, up to the clocks of the nodes.
+	name := fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(), model.NewId())
+	return index.WriteFileAtomic(filepath.Join(pendingPath, name), data)
+}
+
+// listPendingChanges returns the paths of the saved changes, oldest first.
+func listPendingChanges(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, pendingDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		// Skipping the files being written.
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, pendingDir, entry.Name()))
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func readPendingChanges(path string) (*pendingChanges, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes pendingChanges
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode the pending changes: %w", err)
+	}
+	return &changes, nil
+}
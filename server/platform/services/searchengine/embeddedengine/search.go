@@ -0,0 +1,431 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package embeddedengine
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/embeddedengine/index"
+)
+
+// search runs a search on an index. The caller must hold the mutex.
+func (e *EmbeddedEngine) search(where, name string, req *index.SearchRequest) (*index.SearchResult, *model.AppError) {
+	if !e.ready.Load() {
+		return nil, notStartedError(where)
+	}
+	result, err := e.indexes[name].Search(req)
+	if err != nil {
+		return nil, model.NewAppError(where, "embedded_search.search.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return result, nil
+}
+
+// commonFilters returns the filters on channels, users and dates of a posts or files search.
+// They come with every search params, and only need to be processed once.
+func commonFilters(params *model.SearchParams, userField string) (filters, notFilters []index.Query) {
+	if len(params.InChannels) > 0 {
+		filters = append(filters, index.TermsQuery{Field: fieldChannelID, Terms: params.InChannels})
+	}
+	if len(params.ExcludedChannels) > 0 {
+		notFilters = append(notFilters, index.TermsQuery{Field: fieldChannelID, Terms: params.ExcludedChannels})
+	}
+	if len(params.FromUsers) > 0 {
+		filters = append(filters, index.TermsQuery{Field: userField, Terms: params.FromUsers})
+	}
+	if len(params.ExcludedUsers) > 0 {
+		notFilters = append(notFilters, index.TermsQuery{Field: userField, Terms: params.ExcludedUsers})
+	}
+
+	if params.OnDate != "" {
+		before, after := params.GetOnDateMillis()
+		filters = append(filters, index.RangeQuery{Field: fieldCreateAt, Min: &before, Max: &after})
+		return filters, notFilters
+	}
+
+	if params.AfterDate != "" || params.BeforeDate != "" {
+		q := index.RangeQuery{Field: fieldCreateAt}
+		if params.AfterDate != "" {
+			q.Min = model.NewPointer(params.GetAfterDateMillis())
+		}
+		if params.BeforeDate != "" {
+			q.Max = model.NewPointer(params.GetBeforeDateMillis())
+		}
+		filters = append(filters, q)
+	}
+	if params.ExcludedDate != "" {
+		before, after := params.GetExcludedDateMillis()
+		notFilters = append(notFilters, index.RangeQuery{Field: fieldCreateAt, Min: &before, Max: &after})
+	}
+	if params.ExcludedAfterDate != "" {
+		notFilters = append(notFilters, index.RangeQuery{Field: fieldCreateAt, Min: model.NewPointer(params.GetExcludedAfterDateMillis())})
+	}
+	if params.ExcludedBeforeDate != "" {
+		notFilters = append(notFilters, index.RangeQuery{Field: fieldCreateAt, Max: model.NewPointer(params.GetExcludedBeforeDateMillis())})
+	}
+
+	return filters, notFilters
+}
+
+// postAttributeFilters returns the filters of the is:pinned, has:file, has:link, in:thread and
+// from:bot modifiers of a posts search.
+func postAttributeFilters(params *model.SearchParams) (filters, notFilters []index.Query) {
+	for _, f := range []struct {
+		attribute string
+		value     *bool
+	}{
+		{attributePinned, params.IsPinned},
+		{attributeFile, params.HasFile},
+		{attributeLink, params.HasLink},
+		{attributeThread, params.InThread},
+		{attributeBot, params.FromBots},
+	} {
+		if f.value == nil {
+			continue
+		}
+		q := index.TermsQuery{Field: fieldAttributes, Terms: []string{f.attribute}}
+		if *f.value {
+			filters = append(filters, q)
+		} else {
+			notFilters = append(notFilters, q)
+		}
+	}
+	return filters, notFilters
+}
+
+// textQuery returns the query matching the terms typed by a user in any of the given fields,
+// along with the query of each term, labelled by the term.
+func textQuery(terms string, orTerms bool, fields ...string) (index.Query, map[string]index.Query) {
+	var queries []index.Query
+	labelled := map[string]index.Query{}
+	for _, clause := range index.ParseQueryString(terms) {
+		q := clause.Query(fields...)
+		if q == nil {
+			continue
+		}
+		queries = append(queries, q)
+		labelled[clause.Text] = q
+	}
+	return combine(queries, orTerms), labelled
+}
+
+// hashtagsQuery returns the query matching the given hashtags, along with the query of each
+// hashtag, labelled by the hashtag.
+func hashtagsQuery(terms string, orTerms bool) (index.Query, map[string]index.Query) {
+	var queries []index.Query
+	labelled := map[string]index.Query{}
+	for _, hashtag := range strings.Fields(terms) {
+		q := index.TermQuery{Field: fieldHashtags, Term: index.NormalizeKeyword(hashtag)}
+		queries = append(queries, q)
+		labelled[hashtag] = q
+	}
+	return combine(queries, orTerms), labelled
+}
+
+// combine returns the query matching all the given queries, or any of them.
+func combine(queries []index.Query, or bool) index.Query {
+	switch {
+	case len(queries) == 0:
+		return index.MatchNoneQuery{}
+	case len(queries) == 1:
+		return queries[0]
+	case or:
+		return index.BoolQuery{Should: queries}
+	}
+	return index.BoolQuery{Must: queries}
+}
+
+func channelIDs(channels model.ChannelList) []string {
+	ids := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		ids = append(ids, channel.Id)
+	}
+	return ids
+}
+
+func (e *EmbeddedEngine) SearchPosts(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, model.PostSearchMatches, *model.AppError) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	var termQueries, notTermQueries []index.Query
+	var filters, notFilters []index.Query
+	matches := map[string]index.Query{}
+	for i, params := range searchParams {
+		if i == 0 {
+			filters, notFilters = commonFilters(params, fieldUserID)
+			attributeFilters, attributeNotFilters := postAttributeFilters(params)
+			filters = append(filters, attributeFilters...)
+			notFilters = append(notFilters, attributeNotFilters...)
+		}
+
+		if params.IsHashtag {
+			if params.Terms != "" {
+				q, labelled := hashtagsQuery(params.Terms, searchParams[0].OrTerms)
+				termQueries = append(termQueries, q)
+				for label, m := range labelled {
+					matches[label] = m
+				}
+			} else if params.ExcludedTerms != "" {
+				q, _ := hashtagsQuery(params.ExcludedTerms, searchParams[0].OrTerms)
+				notTermQueries = append(notTermQueries, q)
+			}
+			continue
+		}
+
+		if params.Terms != "" {
+			q, labelled := textQuery(params.Terms, searchParams[0].OrTerms, fieldMessage, fieldAttachments)
+			termQueries = append(termQueries, q)
+			for label, m := range labelled {
+				matches[label] = m
+			}
+		}
+		if params.ExcludedTerms != "" {
+			q, _ := textQuery(params.ExcludedTerms, searchParams[0].OrTerms, fieldMessage, fieldAttachments)
+			notTermQueries = append(notTermQueries, q)
+		}
+	}
+
+	allTermsQuery := index.BoolQuery{MustNot: notTermQueries}
+	if len(searchParams) > 0 && searchParams[0].OrTerms {
+		allTermsQuery.Should = termQueries
+	} else {
+		allTermsQuery.Must = termQueries
+	}
+
+	filters = append(filters,
+		index.TermsQuery{Field: fieldChannelID, Terms: channelIDs(channels)},
+		index.TermsQuery{Field: fieldType, Terms: []string{postTypeDefault, model.PostTypeSlackAttachment}},
+		allTermsQuery,
+	)
+
+	result, appErr := e.search("EmbeddedEngine.SearchPosts", IndexPosts, &index.SearchRequest{
+		Query:   index.BoolQuery{Must: filters, MustNot: notFilters},
+		SortBy:  fieldCreateAt,
+		From:    page * perPage,
+		Size:    perPage,
+		Matches: matches,
+	})
+	if appErr != nil {
+		return []string{}, nil, appErr
+	}
+
+	postIds := make([]string, 0, len(result.IDs))
+	postMatches := make(model.PostSearchMatches, len(result.IDs))
+	for _, id := range result.IDs {
+		postIds = append(postIds, id)
+		postMatches[id] = result.Matches[id]
+	}
+
+	return postIds, postMatches, nil
+}
+
+func (e *EmbeddedEngine) SearchFiles(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, *model.AppError) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	var termQueries, notTermQueries []index.Query
+	var filters, notFilters []index.Query
+	for i, params := range searchParams {
+		if i == 0 {
+			filters, notFilters = commonFilters(params, fieldCreatorID)
+			if len(params.Extensions) > 0 {
+				filters = append(filters, index.TermsQuery{Field: fieldExtension, Terms: lowerAll(params.Extensions)})
+			}
+			if len(params.ExcludedExtensions) > 0 {
+				notFilters = append(notFilters, index.TermsQuery{Field: fieldExtension, Terms: lowerAll(params.ExcludedExtensions)})
+			}
+		}
+
+		if params.Terms != "" {
+			q, _ := textQuery(params.Terms, searchParams[0].OrTerms, fieldContent, fieldName)
+			termQueries = append(termQueries, q)
+		}
+		if params.ExcludedTerms != "" {
+			q, _ := textQuery(params.ExcludedTerms, searchParams[0].OrTerms, fieldContent, fieldName)
+			notTermQueries = append(notTermQueries, q)
+		}
+	}
+
+	allTermsQuery := index.BoolQuery{MustNot: notTermQueries}
+	if len(searchParams) > 0 && searchParams[0].OrTerms {
+		allTermsQuery.Should = termQueries
+	} else {
+		allTermsQuery.Must = termQueries
+	}
+
+	filters = append(filters,
+		index.TermsQuery{Field: fieldChannelID, Terms: channelIDs(channels)},
+		allTermsQuery,
+	)
+
+	result, appErr := e.search("EmbeddedEngine.SearchFiles", IndexFiles, &index.SearchRequest{
+		Query:  index.BoolQuery{Must: filters, MustNot: notFilters},
+		SortBy: fieldCreateAt,
+		From:   page * perPage,
+		Size:   perPage,
+	})
+	if appErr != nil {
+		return []string{}, appErr
+	}
+
+	return append([]string{}, result.IDs...), nil
+}
+
+func lowerAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, strings.ToLower(v))
+	}
+	return result
+}
+
+func (e *EmbeddedEngine) SearchChannels(teamId, userID, term string, isGuest, includeDeleted bool) ([]string, *model.AppError) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	query := index.BoolQuery{}
+	if teamId != "" {
+		query.Must = append(query.Must, index.TermQuery{Field: fieldTeamID, Term: teamId})
+	} else {
+		query.Must = append(query.Must, index.TermQuery{Field: fieldTeamMemberIDs, Term: userID})
+	}
+
+	if term != "" {
+		query.Must = append(query.Must, index.PrefixQuery{Field: fieldNameSuggestions, Prefix: index.NormalizeKeyword(term)})
+	}
+
+	private := index.TermQuery{Field: fieldType, Term: string(model.ChannelTypePrivate)}
+	if isGuest {
+		query.MustNot = append(query.MustNot, private)
+	} else {
+		query.Must = append(query.Must, index.BoolQuery{Should: []index.Query{
+			index.BoolQuery{MustNot: []index.Query{private}},
+			index.BoolQuery{Must: []index.Query{private, index.TermQuery{Field: fieldUserIDs, Term: userID}}},
+		}})
+	}
+
+	if !includeDeleted {
+		query.Must = append(query.Must, index.RangeQuery{Field: fieldDeleteAt, Min: model.NewPointer(int64(0)), Max: model.NewPointer(int64(0))})
+	}
+
+	result, appErr := e.search("EmbeddedEngine.SearchChannels", IndexChannels, &index.SearchRequest{
+		Query: query,
+		Size:  model.ChannelSearchDefaultLimit,
+	})
+	if appErr != nil {
+		return []string{}, appErr
+	}
+
+	return append([]string{}, result.IDs...), nil
+}
+
+func suggestionsField(options *model.UserSearchOptions) string {
+	if options.AllowFullNames {
+		return fieldSuggestionsWithFullname
+	}
+	return fieldSuggestionsWithoutFullname
+}
+
+// userFilters returns the filters on the term, status and role shared by the users searches.
+func userFilters(term string, options *model.UserSearchOptions) []index.Query {
+	var filters []index.Query
+	if term != "" {
+		filters = append(filters, index.PrefixQuery{Field: suggestionsField(options), Prefix: index.NormalizeKeyword(term)})
+	}
+	if !options.AllowInactive {
+		filters = append(filters, index.RangeQuery{Field: fieldDeleteAt, Max: model.NewPointer(int64(0))})
+	}
+	if options.Role != "" {
+		filters = append(filters, index.TermQuery{Field: fieldRoles, Term: options.Role})
+	}
+	return filters
+}
+
+func (e *EmbeddedEngine) autocompleteUsers(contextCategory string, categoryIds []string, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	query := index.BoolQuery{Must: userFilters(term, options)}
+
+	var ids []string
+	for _, id := range categoryIds {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		query.Must = append(query.Must, index.TermsQuery{Field: contextCategory, Terms: ids})
+	}
+
+	result, appErr := e.search("EmbeddedEngine.autocompleteUsers", IndexUsers, &index.SearchRequest{
+		Query: query,
+		Size:  options.Limit,
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return result.IDs, nil
+}
+
+func (e *EmbeddedEngine) autocompleteUsersNotInChannel(teamId, channelId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
+	e.mutex.RLock()
+	defer e.mutex.RUnlock()
+
+	query := index.BoolQuery{
+		Must:    append(userFilters(term, options), index.TermQuery{Field: fieldTeamID, Term: teamId}),
+		MustNot: []index.Query{index.TermQuery{Field: fieldChannelID, Term: channelId}},
+	}
+	if len(restrictedToChannels) > 0 {
+		query.Must = append(query.Must, index.TermsQuery{Field: fieldChannelID, Terms: restrictedToChannels})
+	}
+
+	result, appErr := e.search("EmbeddedEngine.autocompleteUsersNotInChannel", IndexUsers, &index.SearchRequest{
+		Query: query,
+		Size:  options.Limit,
+	})
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return result.IDs, nil
+}
+
+func (e *EmbeddedEngine) SearchUsersInChannel(teamId, channelId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, []string, *model.AppError) {
+	if restrictedToChannels != nil && len(restrictedToChannels) == 0 {
+		return []string{}, []string{}, nil
+	}
+
+	uchan, appErr := e.autocompleteUsers(fieldChannelID, []string{channelId}, term, options)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	nuchan, appErr := e.autocompleteUsersNotInChannel(teamId, channelId, restrictedToChannels, term, options)
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+
+	return append([]string{}, uchan...), append([]string{}, nuchan...), nil
+}
+
+func (e *EmbeddedEngine) SearchUsersInTeam(teamId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
+	if restrictedToChannels != nil && len(restrictedToChannels) == 0 {
+		return []string{}, nil
+	}
+
+	var usersIds []string
+	var appErr *model.AppError
+	if restrictedToChannels == nil {
+		usersIds, appErr = e.autocompleteUsers(fieldTeamID, []string{teamId}, term, options)
+	} else {
+		usersIds, appErr = e.autocompleteUsers(fieldChannelID, restrictedToChannels, term, options)
+	}
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return append([]string{}, usersIds...), nil
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DualReadFileBackend is used while files are being migrated from one backend to another. New
+// files are written to the primary backend, the migration target, while files that haven't been
+// migrated yet are still read from the fallback backend, the migration source.
+type DualReadFileBackend struct {
+	primary  FileBackend
+	fallback FileBackend
+}
+
+// NewDualReadFileBackend returns a backend writing to primary and reading from fallback the files
+// missing from primary.
+func NewDualReadFileBackend(primary, fallback FileBackend) *DualReadFileBackend {
+	return &DualReadFileBackend{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+// Primary returns the backend new files are written to.
+func (b *DualReadFileBackend) Primary() FileBackend {
+	return b.primary
+}
+
+// Fallback returns the backend files missing from the primary backend are read from.
+func (b *DualReadFileBackend) Fallback() FileBackend {
+	return b.fallback
+}
+
+// Unwrap returns the primary backend, the one the files are being migrated to.
+func (b *DualReadFileBackend) Unwrap() FileBackend {
+	return b.primary
+}
+
+// backendFor returns the backend holding the file at path, preferring the primary backend.
+func (b *DualReadFileBackend) backendFor(path string) (FileBackend, error) {
+	exists, err := b.primary.FileExists(path)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return b.primary, nil
+	}
+	return b.fallback, nil
+}
+
+func (b *DualReadFileBackend) DriverName() string {
+	return b.primary.DriverName()
+}
+
+func (b *DualReadFileBackend) TestConnection() error {
+	if err := b.primary.TestConnection(); err != nil {
+		return err
+	}
+	if err := b.fallback.TestConnection(); err != nil {
+		return errors.Wrap(err, "unable to connect to the fallback file backend")
+	}
+	return nil
+}
+
+func (b *DualReadFileBackend) Reader(path string) (ReadCloseSeeker, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Reader(path)
+}
+
+func (b *DualReadFileBackend) ReadFile(path string) ([]byte, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return nil, err
+	}
+	return backend.ReadFile(path)
+}
+
+func (b *DualReadFileBackend) FileExists(path string) (bool, error) {
+	exists, err := b.primary.FileExists(path)
+	if err != nil || exists {
+		return exists, err
+	}
+	return b.fallback.FileExists(path)
+}
+
+func (b *DualReadFileBackend) FileSize(path string) (int64, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return 0, err
+	}
+	return backend.FileSize(path)
+}
+
+func (b *DualReadFileBackend) FileModTime(path string) (time.Time, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return backend.FileModTime(path)
+}
+
+// copyFromFallback copies the file at oldPath in the fallback backend to newPath in the
+// primary backend.
+func (b *DualReadFileBackend) copyFromFallback(oldPath, newPath string) error {
+	file, err := b.fallback.Reader(oldPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = b.primary.WriteFile(file, newPath)
+	return err
+}
+
+func (b *DualReadFileBackend) CopyFile(oldPath, newPath string) error {
+	backend, err := b.backendFor(oldPath)
+	if err != nil {
+		return err
+	}
+	if backend == b.primary {
+		return b.primary.CopyFile(oldPath, newPath)
+	}
+	return b.copyFromFallback(oldPath, newPath)
+}
+
+func (b *DualReadFileBackend) MoveFile(oldPath, newPath string) error {
+	backend, err := b.backendFor(oldPath)
+	if err != nil {
+		return err
+	}
+	if backend == b.primary {
+		return b.primary.MoveFile(oldPath, newPath)
+	}
+
+	if err := b.copyFromFallback(oldPath, newPath); err != nil {
+		return err
+	}
+	return b.fallback.RemoveFile(oldPath)
+}
+
+func (b *DualReadFileBackend) WriteFile(fr io.Reader, path string) (int64, error) {
+	return b.primary.WriteFile(fr, path)
+}
+
+func (b *DualReadFileBackend) WriteFileContext(ctx context.Context, fr io.Reader, path string) (int64, error) {
+	return TryWriteFileContext(ctx, b.primary, fr, path)
+}
+
+// AppendFile appends to the file in the primary backend, copying it there first if it hasn't
+// been migrated yet.
+func (b *DualReadFileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return 0, err
+	}
+	if backend == b.fallback {
+		if err := b.copyFromFallback(path, path); err != nil {
+			return 0, err
+		}
+	}
+	return b.primary.AppendFile(fr, path)
+}
+
+// RemoveFile removes the file from both backends, so that it doesn't come back from the
+// fallback backend.
+func (b *DualReadFileBackend) RemoveFile(path string) error {
+	for _, backend := range []FileBackend{b.primary, b.fallback} {
+		exists, err := backend.FileExists(path)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			continue
+		}
+		if err := backend.RemoveFile(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *DualReadFileBackend) RemoveDirectory(path string) error {
+	if err := b.primary.RemoveDirectory(path); err != nil {
+		return err
+	}
+	return b.fallback.RemoveDirectory(path)
+}
+
+func (b *DualReadFileBackend) ListDirectory(path string) ([]string, error) {
+	return b.listBoth(func(backend FileBackend) ([]string, error) {
+		return backend.ListDirectory(path)
+	})
+}
+
+func (b *DualReadFileBackend) ListDirectoryRecursively(path string) ([]string, error) {
+	return b.listBoth(func(backend FileBackend) ([]string, error) {
+		return backend.ListDirectoryRecursively(path)
+	})
+}
+
+// listBoth returns the sorted union of the paths listed from both backends.
+func (b *DualReadFileBackend) listBoth(list func(backend FileBackend) ([]string, error)) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, backend := range []FileBackend{b.primary, b.fallback} {
+		listed, err := list(backend)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range listed {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// ZipReader zips the path from the primary backend, or from the fallback backend if nothing
+// has been written there yet.
+func (b *DualReadFileBackend) ZipReader(path string, deflate bool) (io.ReadCloser, error) {
+	backend, err := b.backendFor(path)
+	if err != nil {
+		return nil, err
+	}
+	if backend == b.fallback {
+		paths, err := b.primary.ListDirectory(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) > 0 {
+			backend = b.primary
+		}
+	}
+	return backend.ZipReader(path, deflate)
+}
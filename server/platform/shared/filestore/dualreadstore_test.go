@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualReadFileBackend(t *testing.T) {
+	setup := func(t *testing.T) (*DualReadFileBackend, FileBackend, FileBackend) {
+		primary := &LocalFileBackend{directory: t.TempDir()}
+		fallback := &LocalFileBackend{directory: t.TempDir()}
+
+		_, err := fallback.WriteFile(bytes.NewReader([]byte("old")), "data/old.txt")
+		require.NoError(t, err)
+		_, err = primary.WriteFile(bytes.NewReader([]byte("new")), "data/new.txt")
+		require.NoError(t, err)
+
+		return NewDualReadFileBackend(primary, fallback), primary, fallback
+	}
+
+	t.Run("reads from both backends", func(t *testing.T) {
+		backend, _, _ := setup(t)
+
+		for path, content := range map[string]string{"data/old.txt": "old", "data/new.txt": "new"} {
+			exists, err := backend.FileExists(path)
+			require.NoError(t, err)
+			assert.True(t, exists)
+
+			data, err := backend.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+
+			size, err := backend.FileSize(path)
+			require.NoError(t, err)
+			assert.EqualValues(t, 3, size)
+		}
+
+		exists, err := backend.FileExists("data/missing.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("prefers the primary backend", func(t *testing.T) {
+		backend, primary, _ := setup(t)
+
+		_, err := primary.WriteFile(bytes.NewReader([]byte("migrated")), "data/old.txt")
+		require.NoError(t, err)
+
+		data, err := backend.ReadFile("data/old.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "migrated", string(data))
+	})
+
+	t.Run("writes to the primary backend", func(t *testing.T) {
+		backend, primary, fallback := setup(t)
+
+		_, err := backend.WriteFile(bytes.NewReader([]byte("written")), "data/written.txt")
+		require.NoError(t, err)
+
+		exists, err := primary.FileExists("data/written.txt")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = fallback.FileExists("data/written.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("moves and copies across backends", func(t *testing.T) {
+		backend, primary, fallback := setup(t)
+
+		require.NoError(t, backend.CopyFile("data/old.txt", "data/copy.txt"))
+		data, err := primary.ReadFile("data/copy.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+
+		require.NoError(t, backend.MoveFile("data/old.txt", "data/moved.txt"))
+		data, err = primary.ReadFile("data/moved.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(data))
+		exists, err := fallback.FileExists("data/old.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("appends to files not migrated yet", func(t *testing.T) {
+		backend, primary, _ := setup(t)
+
+		_, err := backend.AppendFile(bytes.NewReader([]byte("er")), "data/old.txt")
+		require.NoError(t, err)
+
+		data, err := primary.ReadFile("data/old.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "older", string(data))
+	})
+
+	t.Run("removes from both backends", func(t *testing.T) {
+		backend, primary, _ := setup(t)
+
+		_, err := primary.WriteFile(bytes.NewReader([]byte("migrated")), "data/old.txt")
+		require.NoError(t, err)
+
+		require.NoError(t, backend.RemoveFile("data/old.txt"))
+		exists, err := backend.FileExists("data/old.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("lists both backends", func(t *testing.T) {
+		backend, primary, _ := setup(t)
+
+		_, err := primary.WriteFile(bytes.NewReader([]byte("migrated")), "data/old.txt")
+		require.NoError(t, err)
+
+		paths, err := backend.ListDirectory("data")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"data/new.txt", "data/old.txt"}, paths)
+
+		paths, err = backend.ListDirectoryRecursively("")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"data/new.txt", "data/old.txt"}, paths)
+	})
+}
+
+func TestUnwrapFileBackend(t *testing.T) {
+	local := &LocalFileBackend{directory: t.TempDir()}
+	assert.Same(t, local, UnwrapFileBackend(local))
+
+	encrypted, err := NewEncryptedFileBackend(local, [][]byte{make([]byte, encryptionKeySize)})
+	require.NoError(t, err)
+	assert.Same(t, local, UnwrapFileBackend(encrypted))
+
+	dualRead := NewDualReadFileBackend(encrypted, &LocalFileBackend{directory: t.TempDir()})
+	assert.Same(t, local, UnwrapFileBackend(dualRead))
+}
@@ -0,0 +1,746 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// Encrypted files start with a header holding the data key of the file, wrapped with a master
+// key, followed by the content split in chunks that are sealed independently. This allows reading
+// any part of a file without decrypting everything before it.
+//
+// The header is made of the magic string, the format version, the ID of the master key, the nonce
+// and the wrapped data key, and the nonce prefix of the content. Chunks are sealed following the
+// STREAM construction: their nonce is made of the prefix, the index of the chunk and a flag that
+// is only set for the last one, so that reordered or truncated chunks are detected.
+const (
+	encryptedFileMagic   = "MMEF"
+	encryptedFileVersion = 1
+
+	encryptionKeySize         = 32
+	encryptionKeyIDSize       = 8
+	encryptionNonceSize       = 12
+	encryptionNoncePrefixSize = 7
+	encryptionTagSize         = 16
+	encryptionWrappedKeySize  = encryptionKeySize + encryptionTagSize
+
+	encryptionChunkSize       = 64 * 1024
+	encryptedChunkSize        = encryptionChunkSize + encryptionTagSize
+	encryptedFileHeaderSize   = len(encryptedFileMagic) + 1 + encryptionKeyIDSize + encryptionNonceSize + encryptionWrappedKeySize + encryptionNoncePrefixSize
+	encryptedFileVersionIndex = len(encryptedFileMagic)
+)
+
+type encryptionMasterKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// EncryptedFileBackend encrypts files at rest on top of another FileBackend. Each file is
+// encrypted with its own random data key, which is in turn wrapped with a master key and stored
+// in the header of the file. Rotating the master key then only requires re-wrapping the data keys
+// rather than re-encrypting the files.
+//
+// Files written before encryption was enabled are still read as they are.
+type EncryptedFileBackend struct {
+	FileBackend
+
+	masterKeys []encryptionMasterKey
+}
+
+// NewEncryptedFileBackend wraps backend with encryption at rest. The first master key encrypts
+// new files, while the others are retired keys only used to read existing files.
+func NewEncryptedFileBackend(backend FileBackend, masterKeys [][]byte) (*EncryptedFileBackend, error) {
+	if len(masterKeys) == 0 {
+		return nil, errors.New("no encryption master key provided")
+	}
+
+	b := &EncryptedFileBackend{
+		FileBackend: backend,
+	}
+	for _, key := range masterKeys {
+		aead, err := newEncryptionAEAD(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid encryption master key")
+		}
+		id := sha256.Sum256(key)
+		b.masterKeys = append(b.masterKeys, encryptionMasterKey{
+			id:   id[:encryptionKeyIDSize],
+			aead: aead,
+		})
+	}
+	return b, nil
+}
+
+func (settings *FileBackendSettings) encryptionMasterKeys() ([][]byte, error) {
+	keys := settings.EncryptionMasterKey
+	if settings.EncryptionMasterKeyFile != "" {
+		data, err := os.ReadFile(settings.EncryptionMasterKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read the key file %s", settings.EncryptionMasterKeyFile)
+		}
+		keys = string(data)
+	}
+	return model.ParseEncryptionMasterKeys(keys)
+}
+
+func newEncryptionAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != encryptionKeySize {
+		return nil, errors.Errorf("key must be %d bytes long, got %d", encryptionKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Unwrap returns the backend the encrypted files are stored in.
+func (b *EncryptedFileBackend) Unwrap() FileBackend {
+	return b.FileBackend
+}
+
+// HasRetiredMasterKeys returns whether files may still have their data key wrapped with another
+// master key than the active one.
+func (b *EncryptedFileBackend) HasRetiredMasterKeys() bool {
+	return len(b.masterKeys) > 1
+}
+
+type encryptedFileHeader struct {
+	keyID       []byte
+	nonce       []byte
+	wrappedKey  []byte
+	noncePrefix []byte
+}
+
+func (h *encryptedFileHeader) bytes() []byte {
+	buf := make([]byte, 0, encryptedFileHeaderSize)
+	buf = append(buf, encryptedFileMagic...)
+	buf = append(buf, encryptedFileVersion)
+	buf = append(buf, h.keyID...)
+	buf = append(buf, h.nonce...)
+	buf = append(buf, h.wrappedKey...)
+	return append(buf, h.noncePrefix...)
+}
+
+// additionalData authenticates the part of the header preceding the wrapped data key.
+func (h *encryptedFileHeader) additionalData() []byte {
+	return h.bytes()[:len(encryptedFileMagic)+1+encryptionKeyIDSize]
+}
+
+// readEncryptedFileHeader reads the header of an encrypted file. It returns nil if the file isn't
+// encrypted, in which case the position of the reader is undefined.
+func readEncryptedFileHeader(r io.Reader) (*encryptedFileHeader, error) {
+	buf := make([]byte, encryptedFileHeaderSize)
+	if _, err := io.ReadFull(r, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if string(buf[:len(encryptedFileMagic)]) != encryptedFileMagic {
+		return nil, nil
+	}
+	if buf[encryptedFileVersionIndex] != encryptedFileVersion {
+		return nil, errors.Errorf("unsupported encrypted file version %d", buf[encryptedFileVersionIndex])
+	}
+
+	buf = buf[encryptedFileVersionIndex+1:]
+	h := &encryptedFileHeader{}
+	for _, field := range []struct {
+		value *[]byte
+		size  int
+	}{
+		{&h.keyID, encryptionKeyIDSize},
+		{&h.nonce, encryptionNonceSize},
+		{&h.wrappedKey, encryptionWrappedKeySize},
+		{&h.noncePrefix, encryptionNoncePrefixSize},
+	} {
+		*field.value = buf[:field.size]
+		buf = buf[field.size:]
+	}
+	return h, nil
+}
+
+// newEncryptedFileHeader generates a data key and returns it along with the header storing it.
+func (b *EncryptedFileBackend) newEncryptedFileHeader() (*encryptedFileHeader, []byte, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate a data key")
+	}
+
+	h := &encryptedFileHeader{
+		noncePrefix: make([]byte, encryptionNoncePrefixSize),
+	}
+	if _, err := rand.Read(h.noncePrefix); err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate a nonce")
+	}
+	if err := b.wrapDataKey(h, dataKey); err != nil {
+		return nil, nil, err
+	}
+	return h, dataKey, nil
+}
+
+// wrapDataKey encrypts the data key with the active master key.
+func (b *EncryptedFileBackend) wrapDataKey(h *encryptedFileHeader, dataKey []byte) error {
+	masterKey := b.masterKeys[0]
+	h.keyID = masterKey.id
+	h.nonce = make([]byte, encryptionNonceSize)
+	if _, err := rand.Read(h.nonce); err != nil {
+		return errors.Wrap(err, "unable to generate a nonce")
+	}
+	h.wrappedKey = masterKey.aead.Seal(nil, h.nonce, dataKey, h.additionalData())
+	return nil
+}
+
+func (b *EncryptedFileBackend) unwrapDataKey(h *encryptedFileHeader) ([]byte, error) {
+	for _, masterKey := range b.masterKeys {
+		if !bytes.Equal(masterKey.id, h.keyID) {
+			continue
+		}
+		dataKey, err := masterKey.aead.Open(nil, h.nonce, h.wrappedKey, h.additionalData())
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to unwrap the data key")
+		}
+		return dataKey, nil
+	}
+	return nil, errors.Errorf("the master key %x used to encrypt the file isn't configured", h.keyID)
+}
+
+// rewrapHeader reads the header of a file and wraps its data key with the active master key. It
+// returns nil if the file isn't encrypted or already uses the active master key.
+func (b *EncryptedFileBackend) rewrapHeader(source io.Reader) (*encryptedFileHeader, error) {
+	h, err := readEncryptedFileHeader(source)
+	if err != nil || h == nil || bytes.Equal(h.keyID, b.masterKeys[0].id) {
+		return nil, err
+	}
+
+	dataKey, err := b.unwrapDataKey(h)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.wrapDataKey(h, dataKey); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func chunkNonce(noncePrefix []byte, index uint32, last bool) []byte {
+	nonce := make([]byte, encryptionNonceSize)
+	copy(nonce, noncePrefix)
+	binary.BigEndian.PutUint32(nonce[encryptionNoncePrefixSize:], index)
+	if last {
+		nonce[encryptionNonceSize-1] = 1
+	}
+	return nonce
+}
+
+// encryptingReader encrypts the content read from source, starting with the header of the file.
+// It stops with an error once ctx is done.
+type encryptingReader struct {
+	ctx         context.Context
+	source      io.Reader
+	aead        cipher.AEAD
+	noncePrefix []byte
+
+	// plain holds the content of the next chunk along with one more byte, which tells whether
+	// the chunk is the last one.
+	plain    []byte
+	buffered int
+	index    uint32
+	pending  []byte
+	sealed   []byte
+	done     bool
+	read     int64
+}
+
+func (b *EncryptedFileBackend) newEncryptingReader(ctx context.Context, source io.Reader) (*encryptingReader, error) {
+	h, dataKey, err := b.newEncryptedFileHeader()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newEncryptionAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	r := newChunkEncryptingReader(ctx, source, aead, h.noncePrefix, 0, nil)
+	r.pending = h.bytes()
+	return r, nil
+}
+
+// newChunkEncryptingReader returns a reader sealing the chunks of a file from the chunk at index,
+// without the header. The chunk is made of start, the content it already holds, followed by the
+// content read from source.
+func newChunkEncryptingReader(ctx context.Context, source io.Reader, aead cipher.AEAD, noncePrefix []byte, index uint32, start []byte) *encryptingReader {
+	r := &encryptingReader{
+		ctx:         ctx,
+		source:      source,
+		aead:        aead,
+		noncePrefix: noncePrefix,
+		plain:       make([]byte, encryptionChunkSize+1),
+		index:       index,
+		sealed:      make([]byte, 0, encryptedChunkSize),
+	}
+	r.buffered = copy(r.plain, start)
+	return r
+}
+
+func (r *encryptingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.sealNextChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+func (r *encryptingReader) sealNextChunk() error {
+	n, err := io.ReadFull(r.source, r.plain[r.buffered:])
+	if ctxErr := r.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	n += r.buffered
+	last := false
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		last = true
+	} else if err != nil {
+		return err
+	}
+
+	size := min(n, encryptionChunkSize)
+	if !last && r.index == math.MaxUint32 {
+		return errors.New("file is too large to be encrypted")
+	}
+	r.pending = r.aead.Seal(r.sealed[:0], chunkNonce(r.noncePrefix, r.index, last), r.plain[:size], nil)
+	r.read += int64(size)
+
+	if last {
+		r.done = true
+	} else {
+		r.plain[0] = r.plain[encryptionChunkSize]
+		r.buffered = 1
+		r.index++
+	}
+	return nil
+}
+
+// decryptingReader decrypts an encrypted file, one chunk at a time, as it's being read.
+type decryptingReader struct {
+	source      ReadCloseSeeker
+	aead        cipher.AEAD
+	noncePrefix []byte
+
+	size      int64
+	chunks    int64
+	offset    int64
+	sourcePos int64
+
+	chunk      []byte
+	chunkIndex int64
+	sealed     []byte
+}
+
+func (b *EncryptedFileBackend) newDecryptingReader(source ReadCloseSeeker, h *encryptedFileHeader) (*decryptingReader, error) {
+	dataKey, err := b.unwrapDataKey(h)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newEncryptionAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSize, err := source.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get the size of the file")
+	}
+	encryptedSize -= int64(encryptedFileHeaderSize)
+	chunks := (encryptedSize + encryptedChunkSize - 1) / encryptedChunkSize
+	if chunks == 0 || encryptedSize-(chunks-1)*encryptedChunkSize < encryptionTagSize {
+		return nil, errors.New("encrypted file is truncated")
+	}
+
+	return &decryptingReader{
+		source:      source,
+		aead:        aead,
+		noncePrefix: h.noncePrefix,
+		size:        encryptedSize - chunks*encryptionTagSize,
+		chunks:      chunks,
+		sourcePos:   encryptedSize + int64(encryptedFileHeaderSize),
+		chunkIndex:  -1,
+		sealed:      make([]byte, encryptedChunkSize),
+	}, nil
+}
+
+func (r *decryptingReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	index := r.offset / encryptionChunkSize
+	if index != r.chunkIndex {
+		if err := r.openChunk(index); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.chunk[r.offset-index*encryptionChunkSize:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+func (r *decryptingReader) openChunk(index int64) error {
+	pos := int64(encryptedFileHeaderSize) + index*encryptedChunkSize
+	// Seeking may restart the download of the file on some backends, so only do it when reads
+	// aren't sequential.
+	if pos != r.sourcePos {
+		if _, err := r.source.Seek(pos, io.SeekStart); err != nil {
+			return err
+		}
+		r.sourcePos = pos
+	}
+
+	last := index == r.chunks-1
+	size := encryptedChunkSize
+	if last {
+		size = int(r.size - index*encryptionChunkSize + encryptionTagSize)
+	}
+	n, err := io.ReadFull(r.source, r.sealed[:size])
+	r.sourcePos += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "unable to read the encrypted file")
+	}
+
+	r.chunk, err = r.aead.Open(r.chunk[:0], chunkNonce(r.noncePrefix, uint32(index), last), r.sealed[:size], nil)
+	if err != nil {
+		r.chunkIndex = -1
+		return errors.Wrap(err, "unable to decrypt the file")
+	}
+	r.chunkIndex = index
+	return nil
+}
+
+func (r *decryptingReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *decryptingReader) Close() error {
+	return r.source.Close()
+}
+
+// CancelTimeout forwards the cancellation of the timeout to the underlying reader if it has one.
+func (r *decryptingReader) CancelTimeout() bool {
+	if tc, ok := r.source.(interface{ CancelTimeout() bool }); ok {
+		return tc.CancelTimeout()
+	}
+	return true
+}
+
+// Caller must close the first return value
+func (b *EncryptedFileBackend) Reader(path string) (ReadCloseSeeker, error) {
+	source, err := b.FileBackend.Reader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := readEncryptedFileHeader(source)
+	if err != nil {
+		source.Close()
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+
+	if h == nil {
+		if _, err := source.Seek(0, io.SeekStart); err != nil {
+			source.Close()
+			return nil, errors.Wrapf(err, "unable to read file %s", path)
+		}
+		return source, nil
+	}
+
+	r, err := b.newDecryptingReader(source, h)
+	if err != nil {
+		source.Close()
+		return nil, errors.Wrapf(err, "unable to decrypt file %s", path)
+	}
+	return r, nil
+}
+
+func (b *EncryptedFileBackend) ReadFile(path string) ([]byte, error) {
+	r, err := b.Reader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	return data, nil
+}
+
+func (b *EncryptedFileBackend) FileSize(path string) (int64, error) {
+	r, err := b.Reader(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+	defer r.Close()
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+	return size, nil
+}
+
+func (b *EncryptedFileBackend) WriteFile(fr io.Reader, path string) (int64, error) {
+	return b.WriteFileContext(context.Background(), fr, path)
+}
+
+func (b *EncryptedFileBackend) WriteFileContext(ctx context.Context, fr io.Reader, path string) (int64, error) {
+	r, err := b.newEncryptingReader(ctx, fr)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to encrypt the file %s", path)
+	}
+	if _, err := TryWriteFileContext(ctx, b.FileBackend, r, path); err != nil {
+		return r.read, err
+	}
+	return r.read, nil
+}
+
+// fileOverwriter is implemented by the backends that can replace the end of a file without
+// rewriting the rest of it.
+type fileOverwriter interface {
+	// overwriteFileFrom replaces the content of the file at path from offset with the content
+	// read from fr.
+	overwriteFileFrom(fr io.Reader, path string, offset int64) (int64, error)
+}
+
+// AppendFile only seals again the last chunk of the file, along with the appended content, when
+// that chunk is full: it is then sealed as a chunk which isn't the last one, under a nonce never
+// used before. A partial last chunk would have to be sealed again under the same nonce with a
+// different content, which AES-GCM doesn't allow, so the whole file is rewritten with a new data
+// key instead. The file is also rewritten when the underlying backend can't overwrite the end of a
+// file, or when the file isn't encrypted yet.
+func (b *EncryptedFileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
+	overwriter, ok := b.FileBackend.(fileOverwriter)
+	if !ok {
+		return b.rewriteAppendFile(fr, path)
+	}
+
+	source, err := b.FileBackend.Reader(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find the file %s to append the data", path)
+	}
+
+	h, err := readEncryptedFileHeader(source)
+	if err != nil {
+		source.Close()
+		return 0, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	if h == nil {
+		source.Close()
+		return b.rewriteAppendFile(fr, path)
+	}
+
+	r, err := b.newDecryptingReader(source, h)
+	if err != nil {
+		source.Close()
+		return 0, errors.Wrapf(err, "unable to decrypt file %s", path)
+	}
+	lastIndex := r.chunks - 1
+	if r.size-lastIndex*encryptionChunkSize < encryptionChunkSize {
+		r.Close()
+		return b.rewriteAppendFile(fr, path)
+	}
+	err = r.openChunk(lastIndex)
+	r.Close()
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to decrypt file %s", path)
+	}
+
+	lastOffset := int64(encryptedFileHeaderSize) + lastIndex*encryptedChunkSize
+	lastSealed := bytes.Clone(r.sealed[:r.size-lastIndex*encryptionChunkSize+encryptionTagSize])
+	w := newChunkEncryptingReader(context.Background(), fr, r.aead, h.noncePrefix, uint32(lastIndex), r.chunk)
+	if _, err := overwriter.overwriteFileFrom(w, path, lastOffset); err != nil {
+		// Put the last chunk back, so that the file can still be read and appended to.
+		if _, restoreErr := overwriter.overwriteFileFrom(bytes.NewReader(lastSealed), path, lastOffset); restoreErr != nil {
+			return 0, errors.Wrapf(restoreErr, "unable to restore the file %s after failing to append the data: %s", path, err)
+		}
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+	return w.read - int64(len(r.chunk)), nil
+}
+
+// rewriteAppendFile appends to the file at path by writing it again entirely.
+func (b *EncryptedFileBackend) rewriteAppendFile(fr io.Reader, path string) (int64, error) {
+	r, err := b.Reader(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find the file %s to append the data", path)
+	}
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = r.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		r.Close()
+		return 0, errors.Wrapf(err, "unable to read the file %s to append the data", path)
+	}
+
+	// The file can't be overwritten while it's being read from
+	tmpPath := path + ".append"
+	written, err := b.WriteFile(io.MultiReader(r, fr), tmpPath)
+	r.Close()
+	if err != nil {
+		b.FileBackend.RemoveFile(tmpPath)
+		return max(written-size, 0), errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+
+	if err := b.FileBackend.MoveFile(tmpPath, path); err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+	return written - size, nil
+}
+
+// ZipReader will create a zip of path. If path is a single file, it will zip the single file.
+// If deflate is true, the contents will be compressed. It will stream the zip to io.ReadCloser.
+func (b *EncryptedFileBackend) ZipReader(path string, deflate bool) (io.ReadCloser, error) {
+	deflateMethod := zip.Store
+	if deflate {
+		deflateMethod = zip.Deflate
+	}
+
+	// Directories can't be read, so path is a single file if it can be
+	basePath := path
+	var paths []string
+	if r, err := b.Reader(path); err == nil {
+		r.Close()
+		basePath = filepath.Dir(path)
+		paths = []string{path}
+	} else {
+		paths, err = b.ListDirectoryRecursively(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			if exists, _ := b.FileExists(path); !exists {
+				return nil, errors.Errorf("unable to stat path %s", path)
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		zipWriter := zip.NewWriter(pw)
+		defer zipWriter.Close()
+
+		for _, filePath := range paths {
+			if err := b.copyFileToZipWriter(zipWriter, filePath, basePath, deflateMethod); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return pr, nil
+}
+
+func (b *EncryptedFileBackend) copyFileToZipWriter(zipWriter *zip.Writer, filePath, basePath string, deflateMethod uint16) error {
+	modTime, err := b.FileModTime(filePath)
+	if err != nil {
+		return err
+	}
+
+	relPath := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(filePath, basePath)), "/")
+	header := &zip.FileHeader{
+		Name:     relPath,
+		Method:   deflateMethod,
+		Modified: modTime,
+	}
+	header.SetMode(0644) // rw-r--r-- permissions
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create zip entry for %s", relPath)
+	}
+
+	r, err := b.Reader(filePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(writer, r); err != nil {
+		return errors.Wrapf(err, "unable to copy file content for %s", relPath)
+	}
+	return nil
+}
+
+// RewrapFile wraps the data key of an encrypted file with the active master key if it was
+// encrypted with a retired one. The content of the file is copied as it is. It returns whether
+// the file was rewritten.
+func (b *EncryptedFileBackend) RewrapFile(path string) (bool, error) {
+	source, err := b.FileBackend.Reader(path)
+	if err != nil {
+		return false, err
+	}
+
+	h, err := b.rewrapHeader(source)
+	if err != nil || h == nil {
+		source.Close()
+		return false, errors.Wrapf(err, "unable to rewrap the data key of file %s", path)
+	}
+
+	// The file can't be overwritten while it's being read from
+	tmpPath := path + ".rewrap"
+	_, err = b.FileBackend.WriteFile(io.MultiReader(bytes.NewReader(h.bytes()), source), tmpPath)
+	source.Close()
+	if err != nil {
+		b.FileBackend.RemoveFile(tmpPath)
+		return false, err
+	}
+
+	if err := b.FileBackend.MoveFile(tmpPath, path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,369 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryptedFileBackend(t *testing.T, dir string, masterKeys ...[]byte) *EncryptedFileBackend {
+	t.Helper()
+
+	backend, err := NewEncryptedFileBackend(&LocalFileBackend{directory: dir}, masterKeys)
+	require.NoError(t, err)
+	return backend
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestEncryptedFileBackendReadWrite(t *testing.T) {
+	dir := t.TempDir()
+	backend := newTestEncryptedFileBackend(t, dir, randomBytes(t, encryptionKeySize))
+
+	for _, size := range []int{0, 1, encryptionChunkSize - 1, encryptionChunkSize, encryptionChunkSize + 1, 3*encryptionChunkSize + 5} {
+		data := randomBytes(t, size)
+		path := filepath.Join("tests", randomString())
+
+		written, err := backend.WriteFile(bytes.NewReader(data), path)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, written)
+
+		stored, err := os.ReadFile(filepath.Join(dir, path))
+		require.NoError(t, err)
+		chunks := max((size+encryptionChunkSize-1)/encryptionChunkSize, 1)
+		assert.Len(t, stored, encryptedFileHeaderSize+size+chunks*encryptionTagSize)
+		if size > encryptionTagSize {
+			assert.False(t, bytes.Contains(stored, data), "the content must not be stored in clear")
+		}
+
+		read, err := backend.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, read), "size %d", size)
+
+		fileSize, err := backend.FileSize(path)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, fileSize)
+	}
+}
+
+func TestEncryptedFileBackendReaderSeek(t *testing.T) {
+	backend := newTestEncryptedFileBackend(t, t.TempDir(), randomBytes(t, encryptionKeySize))
+
+	data := randomBytes(t, 3*encryptionChunkSize+100)
+	_, err := backend.WriteFile(bytes.NewReader(data), "file")
+	require.NoError(t, err)
+
+	r, err := backend.Reader("file")
+	require.NoError(t, err)
+	defer r.Close()
+
+	for _, tc := range []struct {
+		offset int64
+		whence int
+		length int
+	}{
+		{2 * encryptionChunkSize, io.SeekStart, 10},
+		{-50, io.SeekEnd, 50},
+		{encryptionChunkSize - 10, io.SeekStart, 20},
+		{-encryptionChunkSize, io.SeekCurrent, encryptionChunkSize + 5},
+		{0, io.SeekStart, 1},
+	} {
+		pos, err := r.Seek(tc.offset, tc.whence)
+		require.NoError(t, err)
+
+		buf := make([]byte, tc.length)
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, data[pos:pos+int64(tc.length)], buf)
+	}
+
+	_, err = r.Seek(10, io.SeekEnd)
+	require.NoError(t, err)
+	n, err := r.Read(make([]byte, 1))
+	assert.Zero(t, n)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = r.Seek(-1, io.SeekStart)
+	require.Error(t, err)
+}
+
+func TestEncryptedFileBackendUnencryptedFiles(t *testing.T) {
+	dir := t.TempDir()
+	backend := newTestEncryptedFileBackend(t, dir, randomBytes(t, encryptionKeySize))
+
+	for _, data := range [][]byte{[]byte(""), []byte("short"), randomBytes(t, encryptionChunkSize)} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy"), data, 0600))
+
+		read, err := backend.ReadFile("legacy")
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, read))
+
+		size, err := backend.FileSize("legacy")
+		require.NoError(t, err)
+		assert.EqualValues(t, len(data), size)
+	}
+
+	written, err := backend.AppendFile(bytes.NewReader([]byte(" appended")), "legacy")
+	require.NoError(t, err)
+	assert.EqualValues(t, len(" appended"), written)
+
+	stored, err := os.ReadFile(filepath.Join(dir, "legacy"))
+	require.NoError(t, err)
+	assert.Equal(t, encryptedFileMagic, string(stored[:len(encryptedFileMagic)]), "appending encrypts the file")
+}
+
+func TestEncryptedFileBackendAppendFile(t *testing.T) {
+	dir := t.TempDir()
+	local := &LocalFileBackend{directory: dir}
+	masterKey := randomBytes(t, encryptionKeySize)
+
+	for name, inner := range map[string]FileBackend{
+		"overwriting the last chunk": local,
+		// Embedding the interface hides the ability to overwrite the end of the files.
+		"rewriting the file": struct{ FileBackend }{local},
+	} {
+		t.Run(name, func(t *testing.T) {
+			backend, err := NewEncryptedFileBackend(inner, [][]byte{masterKey})
+			require.NoError(t, err)
+
+			data := randomBytes(t, 2*encryptionChunkSize+10)
+			_, err = backend.WriteFile(bytes.NewReader(data[:encryptionChunkSize-5]), "file")
+			require.NoError(t, err)
+
+			written, err := backend.AppendFile(bytes.NewReader(data[encryptionChunkSize-5:encryptionChunkSize+10]), "file")
+			require.NoError(t, err)
+			assert.EqualValues(t, 15, written)
+
+			written, err = backend.AppendFile(bytes.NewReader(nil), "file")
+			require.NoError(t, err)
+			assert.Zero(t, written)
+
+			written, err = backend.AppendFile(bytes.NewReader(data[encryptionChunkSize+10:]), "file")
+			require.NoError(t, err)
+			assert.EqualValues(t, encryptionChunkSize, written)
+
+			read, err := backend.ReadFile("file")
+			require.NoError(t, err)
+			assert.True(t, bytes.Equal(data, read))
+
+			_, err = backend.AppendFile(bytes.NewReader(data), "missing")
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("only a full last chunk is sealed again", func(t *testing.T) {
+		backend := newTestEncryptedFileBackend(t, dir, masterKey)
+
+		data := randomBytes(t, 3*encryptionChunkSize+10)
+		_, err := backend.WriteFile(bytes.NewReader(data[:2*encryptionChunkSize]), "chunks")
+		require.NoError(t, err)
+		before, err := os.ReadFile(filepath.Join(dir, "chunks"))
+		require.NoError(t, err)
+
+		_, err = backend.AppendFile(bytes.NewReader(data[2*encryptionChunkSize:]), "chunks")
+		require.NoError(t, err)
+		after, err := os.ReadFile(filepath.Join(dir, "chunks"))
+		require.NoError(t, err)
+
+		unchanged := encryptedFileHeaderSize + encryptedChunkSize
+		assert.Equal(t, before[:unchanged], after[:unchanged])
+		assert.NotEqual(t, before[unchanged:], after[unchanged:len(before)])
+
+		read, err := backend.ReadFile("chunks")
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, read))
+	})
+
+	t.Run("appending inside the last chunk never reuses a nonce", func(t *testing.T) {
+		backend := newTestEncryptedFileBackend(t, dir, masterKey)
+
+		readHeader := func() *encryptedFileHeader {
+			t.Helper()
+
+			f, err := os.Open(filepath.Join(dir, "partial"))
+			require.NoError(t, err)
+			defer f.Close()
+			h, err := readEncryptedFileHeader(f)
+			require.NoError(t, err)
+			require.NotNil(t, h)
+			return h
+		}
+
+		data := randomBytes(t, 30)
+		_, err := backend.WriteFile(bytes.NewReader(data[:10]), "partial")
+		require.NoError(t, err)
+
+		_, err = backend.AppendFile(bytes.NewReader(data[10:20]), "partial")
+		require.NoError(t, err)
+		first := readHeader()
+
+		_, err = backend.AppendFile(bytes.NewReader(data[20:]), "partial")
+		require.NoError(t, err)
+		second := readHeader()
+
+		// The single chunk is sealed under chunkNonce(noncePrefix, 0, true) both times.
+		assert.NotEqual(t, chunkNonce(first.noncePrefix, 0, true), chunkNonce(second.noncePrefix, 0, true))
+		assert.NotEqual(t, first.wrappedKey, second.wrappedKey)
+
+		read, err := backend.ReadFile("partial")
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, read))
+	})
+}
+
+func TestEncryptedFileBackendTampering(t *testing.T) {
+	dir := t.TempDir()
+	backend := newTestEncryptedFileBackend(t, dir, randomBytes(t, encryptionKeySize))
+
+	data := randomBytes(t, 2*encryptionChunkSize+10)
+	_, err := backend.WriteFile(bytes.NewReader(data), "file")
+	require.NoError(t, err)
+	stored, err := os.ReadFile(filepath.Join(dir, "file"))
+	require.NoError(t, err)
+
+	for name, tamper := range map[string]func([]byte) []byte{
+		"flipped content byte": func(b []byte) []byte {
+			b[encryptedFileHeaderSize+encryptionChunkSize+1] ^= 1
+			return b
+		},
+		"flipped key id": func(b []byte) []byte {
+			b[len(encryptedFileMagic)+1] ^= 1
+			return b
+		},
+		"flipped wrapped key": func(b []byte) []byte {
+			b[encryptedFileHeaderSize-encryptionNoncePrefixSize-1] ^= 1
+			return b
+		},
+		"truncated last chunk": func(b []byte) []byte {
+			return b[:len(b)-1]
+		},
+		"missing last chunk": func(b []byte) []byte {
+			return b[:encryptedFileHeaderSize+2*encryptedChunkSize]
+		},
+		"swapped chunks": func(b []byte) []byte {
+			first := bytes.Clone(b[encryptedFileHeaderSize : encryptedFileHeaderSize+encryptedChunkSize])
+			copy(b[encryptedFileHeaderSize:], b[encryptedFileHeaderSize+encryptedChunkSize:encryptedFileHeaderSize+2*encryptedChunkSize])
+			copy(b[encryptedFileHeaderSize+encryptedChunkSize:], first)
+			return b
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "tampered"), tamper(bytes.Clone(stored)), 0600))
+
+			_, err := backend.ReadFile("tampered")
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptedFileBackendKeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := randomBytes(t, encryptionKeySize)
+	newKey := randomBytes(t, encryptionKeySize)
+
+	data := randomBytes(t, encryptionChunkSize+10)
+	_, err := newTestEncryptedFileBackend(t, dir, oldKey).WriteFile(bytes.NewReader(data), "data/file")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "legacy"), []byte("legacy"), 0600))
+
+	_, err = newTestEncryptedFileBackend(t, dir, newKey).ReadFile("data/file")
+	require.Error(t, err, "the file can't be read without its master key")
+
+	rotated := newTestEncryptedFileBackend(t, dir, newKey, oldKey)
+	assert.True(t, rotated.HasRetiredMasterKeys())
+
+	read, err := rotated.ReadFile("data/file")
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, read), "files can be read with a retired master key")
+
+	rewrapped, err := rotated.RewrapFile("data/file")
+	require.NoError(t, err)
+	assert.True(t, rewrapped)
+
+	rewrapped, err = rotated.RewrapFile("data/file")
+	require.NoError(t, err)
+	assert.False(t, rewrapped, "the file already uses the active master key")
+
+	rewrapped, err = rotated.RewrapFile("legacy")
+	require.NoError(t, err)
+	assert.False(t, rewrapped, "unencrypted files are left as they are")
+
+	_, err = rotated.RewrapFile("missing")
+	require.Error(t, err)
+
+	paths, err := rotated.ListDirectoryRecursively("")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"data/file", "legacy"}, paths)
+
+	backend := newTestEncryptedFileBackend(t, dir, newKey)
+	assert.False(t, backend.HasRetiredMasterKeys())
+
+	read, err = backend.ReadFile("data/file")
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(data, read), "files can be read once the retired master key is removed")
+}
+
+func TestNewFileBackendWithEncryption(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "master.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=\nICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=\n"), 0600))
+
+	t.Run("master key file", func(t *testing.T) {
+		backend, err := NewFileBackend(FileBackendSettings{
+			DriverName:              driverLocal,
+			Directory:               dir,
+			EnableEncryptionAtRest:  true,
+			EncryptionMasterKeyFile: keyFile,
+		})
+		require.NoError(t, err)
+		require.IsType(t, &EncryptedFileBackend{}, backend)
+		assert.True(t, backend.(*EncryptedFileBackend).HasRetiredMasterKeys())
+		assert.Equal(t, driverLocal, backend.DriverName())
+	})
+
+	t.Run("missing master key file", func(t *testing.T) {
+		_, err := NewFileBackend(FileBackendSettings{
+			DriverName:              driverLocal,
+			Directory:               dir,
+			EnableEncryptionAtRest:  true,
+			EncryptionMasterKeyFile: filepath.Join(dir, "missing.key"),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid master key", func(t *testing.T) {
+		_, err := NewFileBackend(FileBackendSettings{
+			DriverName:             driverLocal,
+			Directory:              dir,
+			EnableEncryptionAtRest: true,
+			EncryptionMasterKey:    "AAECAwQFBgcICQoLDA0ODw==",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		backend, err := NewFileBackend(FileBackendSettings{
+			DriverName:          driverLocal,
+			Directory:           dir,
+			EncryptionMasterKey: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+		})
+		require.NoError(t, err)
+		require.IsType(t, &LocalFileBackend{}, backend)
+	})
+}
@@ -6,6 +6,7 @@ package filestore
 import (
 	"context"
 	"io"
+	"sort"
 	"time"
 
 	"github.com/mattermost/mattermost/server/public/model"
@@ -65,13 +66,19 @@ type FileBackendSettings struct {
 	AmazonS3PresignExpiresSeconds      int64
 	AmazonS3UploadPartSizeBytes        int64
 	AmazonS3StorageClass               string
+	EnableEncryptionAtRest             bool
+	EncryptionMasterKey                string
+	EncryptionMasterKeyFile            string
 }
 
 func NewFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableComplianceFeature bool, skipVerify bool) FileBackendSettings {
 	if *fileSettings.DriverName == model.ImageDriverLocal {
 		return FileBackendSettings{
-			DriverName: *fileSettings.DriverName,
-			Directory:  *fileSettings.Directory,
+			DriverName:              *fileSettings.DriverName,
+			Directory:               *fileSettings.Directory,
+			EnableEncryptionAtRest:  fileSettings.EnableEncryptionAtRest != nil && *fileSettings.EnableEncryptionAtRest,
+			EncryptionMasterKey:     model.SafeDereference(fileSettings.EncryptionMasterKey),
+			EncryptionMasterKeyFile: model.SafeDereference(fileSettings.EncryptionMasterKeyFile),
 		}
 	}
 	return FileBackendSettings{
@@ -90,6 +97,9 @@ func NewFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableCo
 		SkipVerify:                         skipVerify,
 		AmazonS3UploadPartSizeBytes:        *fileSettings.AmazonS3UploadPartSizeBytes,
 		AmazonS3StorageClass:               *fileSettings.AmazonS3StorageClass,
+		EnableEncryptionAtRest:             fileSettings.EnableEncryptionAtRest != nil && *fileSettings.EnableEncryptionAtRest,
+		EncryptionMasterKey:                model.SafeDereference(fileSettings.EncryptionMasterKey),
+		EncryptionMasterKeyFile:            model.SafeDereference(fileSettings.EncryptionMasterKeyFile),
 	}
 }
 
@@ -120,6 +130,40 @@ func NewExportFileBackendSettingsFromConfig(fileSettings *model.FileSettings, en
 	}
 }
 
+// NewMigrationFileBackendSettingsFromConfig returns the settings of the backend files are migrated
+// to. Files are encrypted at rest there the same way they are in the current backend.
+func NewMigrationFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableComplianceFeature bool, skipVerify bool) FileBackendSettings {
+	if *fileSettings.MigrationDriverName == model.ImageDriverLocal {
+		return FileBackendSettings{
+			DriverName:              *fileSettings.MigrationDriverName,
+			Directory:               *fileSettings.MigrationDirectory,
+			EnableEncryptionAtRest:  fileSettings.EnableEncryptionAtRest != nil && *fileSettings.EnableEncryptionAtRest,
+			EncryptionMasterKey:     model.SafeDereference(fileSettings.EncryptionMasterKey),
+			EncryptionMasterKeyFile: model.SafeDereference(fileSettings.EncryptionMasterKeyFile),
+		}
+	}
+	return FileBackendSettings{
+		DriverName:                         *fileSettings.MigrationDriverName,
+		AmazonS3AccessKeyId:                *fileSettings.MigrationAmazonS3AccessKeyId,
+		AmazonS3SecretAccessKey:            *fileSettings.MigrationAmazonS3SecretAccessKey,
+		AmazonS3Bucket:                     *fileSettings.MigrationAmazonS3Bucket,
+		AmazonS3PathPrefix:                 *fileSettings.MigrationAmazonS3PathPrefix,
+		AmazonS3Region:                     *fileSettings.MigrationAmazonS3Region,
+		AmazonS3Endpoint:                   *fileSettings.MigrationAmazonS3Endpoint,
+		AmazonS3SSL:                        fileSettings.MigrationAmazonS3SSL == nil || *fileSettings.MigrationAmazonS3SSL,
+		AmazonS3SignV2:                     fileSettings.MigrationAmazonS3SignV2 != nil && *fileSettings.MigrationAmazonS3SignV2,
+		AmazonS3SSE:                        fileSettings.MigrationAmazonS3SSE != nil && *fileSettings.MigrationAmazonS3SSE && enableComplianceFeature,
+		AmazonS3Trace:                      fileSettings.MigrationAmazonS3Trace != nil && *fileSettings.MigrationAmazonS3Trace,
+		AmazonS3RequestTimeoutMilliseconds: *fileSettings.MigrationAmazonS3RequestTimeoutMilliseconds,
+		SkipVerify:                         skipVerify,
+		AmazonS3UploadPartSizeBytes:        *fileSettings.MigrationAmazonS3UploadPartSizeBytes,
+		AmazonS3StorageClass:               *fileSettings.MigrationAmazonS3StorageClass,
+		EnableEncryptionAtRest:             fileSettings.EnableEncryptionAtRest != nil && *fileSettings.EnableEncryptionAtRest,
+		EncryptionMasterKey:                model.SafeDereference(fileSettings.EncryptionMasterKey),
+		EncryptionMasterKeyFile:            model.SafeDereference(fileSettings.EncryptionMasterKeyFile),
+	}
+}
+
 func (settings *FileBackendSettings) CheckMandatoryS3Fields() error {
 	if settings.AmazonS3Bucket == "" {
 		return errors.New("missing s3 bucket settings")
@@ -144,6 +188,19 @@ func NewExportFileBackend(settings FileBackendSettings) (FileBackend, error) {
 }
 
 func newFileBackend(settings FileBackendSettings, canBeCloud bool) (FileBackend, error) {
+	backend, err := newDriverFileBackend(settings, canBeCloud)
+	if err != nil || !settings.EnableEncryptionAtRest {
+		return backend, err
+	}
+
+	masterKeys, err := settings.encryptionMasterKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load the encryption master keys")
+	}
+	return NewEncryptedFileBackend(backend, masterKeys)
+}
+
+func newDriverFileBackend(settings FileBackendSettings, canBeCloud bool) (FileBackend, error) {
 	switch settings.DriverName {
 	case driverS3:
 		newBackendFn := NewS3FileBackend
@@ -177,3 +234,50 @@ func TryWriteFileContext(ctx context.Context, fb FileBackend, fr io.Reader, path
 
 	return fb.WriteFile(fr, path)
 }
+
+// ListDirectoryRecursivelyAfter lists at most limit files of a directory and its subdirectories,
+// starting after the given path, or from the first file when it's empty. The files are listed
+// in an order specific to the backend, so that a long listing can be resumed from the last path
+// of the previous batch. The backends which can't start a listing from a path list all the files
+// and skip those before it.
+func ListDirectoryRecursivelyAfter(fb FileBackend, path, after string, limit int) ([]string, error) {
+	type CursorLister interface {
+		ListDirectoryRecursivelyAfter(path, after string, limit int) ([]string, error)
+	}
+
+	// The decorating backends store the files under the same paths.
+	if cl, ok := UnwrapFileBackend(fb).(CursorLister); ok {
+		return cl.ListDirectoryRecursivelyAfter(path, after, limit)
+	}
+
+	paths, err := fb.ListDirectoryRecursively(path)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	start := sort.Search(len(paths), func(i int) bool {
+		return after == "" || paths[i] > after
+	})
+	paths = paths[start:]
+	if len(paths) > limit {
+		paths = paths[:limit]
+	}
+	return paths, nil
+}
+
+// UnwrapFileBackend returns the backend storing the files of fb, going through the backends that
+// decorate another one, such as the encrypted backend. It's used to reach the features specific
+// to a driver, like creating the bucket of the S3 backend.
+func UnwrapFileBackend(fb FileBackend) FileBackend {
+	type Unwrapper interface {
+		Unwrap() FileBackend
+	}
+
+	for {
+		u, ok := fb.(Unwrapper)
+		if !ok {
+			return fb
+		}
+		fb = u.Unwrap()
+	}
+}
@@ -81,6 +81,24 @@ func TestLocalFileBackendTestSuite(t *testing.T) {
 	})
 }
 
+func TestLocalFileBackendTestSuiteWithEncryption(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := os.RemoveAll(dir)
+		require.NoError(t, err)
+	})
+
+	suite.Run(t, &FileBackendTestSuite{
+		settings: FileBackendSettings{
+			DriverName:             driverLocal,
+			Directory:              dir,
+			EnableEncryptionAtRest: true,
+			EncryptionMasterKey:    "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=",
+		},
+	})
+}
+
 func TestS3FileBackendTestSuite(t *testing.T) {
 	runBackendTest(t, false)
 }
@@ -479,6 +497,35 @@ func (s *FileBackendTestSuite) TestListDirectoryRecursively() {
 	s.backend.RemoveFile(longPath)
 }
 
+func (s *FileBackendTestSuite) TestListDirectoryRecursivelyAfter() {
+	b := []byte("test")
+	dir := "listafter" + randomString()
+	files := []string{dir + "/a", dir + "/b/c", dir + "/b/d/e", dir + "/b.txt", dir + "/f"}
+	for _, file := range files {
+		_, err := s.backend.WriteFile(bytes.NewReader(b), file)
+		s.Require().NoError(err)
+	}
+	defer s.backend.RemoveDirectory(dir)
+
+	var listed []string
+	after := ""
+	for {
+		paths, err := ListDirectoryRecursivelyAfter(s.backend, dir, after, 2)
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(len(paths), 2)
+		if len(paths) == 0 {
+			break
+		}
+		listed = append(listed, paths...)
+		after = paths[len(paths)-1]
+	}
+	s.ElementsMatch(files, listed)
+
+	paths, err := ListDirectoryRecursivelyAfter(s.backend, "listafter"+randomString(), "", 2)
+	s.NoError(err)
+	s.Empty(paths)
+}
+
 func (s *FileBackendTestSuite) TestRemoveDirectory() {
 	b := []byte("test")
 
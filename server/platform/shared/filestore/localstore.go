@@ -9,6 +9,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -186,6 +188,25 @@ func (b *LocalFileBackend) AppendFile(fr io.Reader, path string) (int64, error)
 	return written, nil
 }
 
+func (b *LocalFileBackend) overwriteFileFrom(fr io.Reader, path string, offset int64) (int64, error) {
+	fw, err := os.OpenFile(filepath.Join(b.directory, path), os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to open the file %s to overwrite the data", path)
+	}
+	defer fw.Close()
+	if err = fw.Truncate(offset); err != nil {
+		return 0, errors.Wrapf(err, "unable to truncate the file %s", path)
+	}
+	if _, err = fw.Seek(offset, io.SeekStart); err != nil {
+		return 0, errors.Wrapf(err, "unable to seek in the file %s", path)
+	}
+	written, err := io.Copy(fw, fr)
+	if err != nil {
+		return written, errors.Wrapf(err, "unable overwrite the data in the file %s", path)
+	}
+	return written, nil
+}
+
 func (b *LocalFileBackend) RemoveFile(path string) error {
 	if err := os.Remove(filepath.Join(b.directory, path)); err != nil {
 		return errors.Wrapf(err, "unable to remove the file %s", path)
@@ -229,6 +250,54 @@ func appendRecursively(basePath, path string, maxDepth int) ([]string, error) {
 	return results, nil
 }
 
+// appendRecursivelyAfter is appendRecursively listing at most limit files after the path after,
+// in the order of the directory entries. The directories listed before it are skipped without
+// being read.
+func appendRecursivelyAfter(basePath, path, after string, limit, maxDepth int, results *[]string) error {
+	dirEntries, err := os.ReadDir(filepath.Join(basePath, path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "unable to list the directory %s", path)
+	}
+	for _, dirEntry := range dirEntries {
+		if len(*results) >= limit {
+			return nil
+		}
+		entryName := dirEntry.Name()
+		entryPath := filepath.Join(path, entryName)
+		if entryName == "." || entryName == ".." || entryPath == path {
+			continue
+		}
+		if dirEntry.IsDir() && maxDepth > 0 {
+			// Every file of the directory is listed before after, unless the directory holds it.
+			if after != "" && comparePathElements(entryPath, after) < 0 && !isPathAncestor(entryPath, after) {
+				continue
+			}
+			if err := appendRecursivelyAfter(basePath, entryPath, after, limit, maxDepth-1, results); err != nil {
+				return err
+			}
+		} else if after == "" || comparePathElements(entryPath, after) > 0 {
+			*results = append(*results, entryPath)
+		}
+	}
+	return nil
+}
+
+// comparePathElements compares paths element by element, which is the order of a recursive
+// listing of sorted directory entries.
+func comparePathElements(a, b string) int {
+	return slices.Compare(strings.Split(filepath.ToSlash(a), "/"), strings.Split(filepath.ToSlash(b), "/"))
+}
+
+// isPathAncestor returns whether the directory dir holds path.
+func isPathAncestor(dir, path string) bool {
+	dirElements := strings.Split(filepath.ToSlash(dir), "/")
+	pathElements := strings.Split(filepath.ToSlash(path), "/")
+	return len(pathElements) > len(dirElements) && slices.Equal(dirElements, pathElements[:len(dirElements)])
+}
+
 func (b *LocalFileBackend) ListDirectory(path string) ([]string, error) {
 	results := []string{}
 	dirEntries, err := os.ReadDir(filepath.Join(b.directory, path))
@@ -252,6 +321,12 @@ func (b *LocalFileBackend) ListDirectoryRecursively(path string) ([]string, erro
 	return appendRecursively(b.directory, path, MaxRecursionDepth)
 }
 
+func (b *LocalFileBackend) ListDirectoryRecursivelyAfter(path, after string, limit int) ([]string, error) {
+	results := []string{}
+	err := appendRecursivelyAfter(b.directory, path, after, limit, MaxRecursionDepth, &results)
+	return results, err
+}
+
 func (b *LocalFileBackend) RemoveDirectory(path string) error {
 	if err := os.RemoveAll(filepath.Join(b.directory, path)); err != nil {
 		return errors.Wrapf(err, "unable to remove the directory %s", path)
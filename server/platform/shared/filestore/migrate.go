@@ -0,0 +1,113 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MigrateFileResult tells what MigrateFile did with a file.
+type MigrateFileResult int
+
+const (
+	// MigrateFileCopied means the file was copied and verified.
+	MigrateFileCopied MigrateFileResult = iota
+	// MigrateFileVerified means the file was already present in the destination with the same content.
+	MigrateFileVerified
+	// MigrateFileKept means the destination holds a different version of the file, which was kept.
+	MigrateFileKept
+	// MigrateFileMissing means the file doesn't exist in the source.
+	MigrateFileMissing
+)
+
+func hashBackendFile(backend FileBackend, path string) ([]byte, int64, error) {
+	file, err := backend.Reader(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, file)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "unable to read the file %s", path)
+	}
+	return hash.Sum(nil), n, nil
+}
+
+// MigrateFile copies the file at path from src to dst, checking that the copy has the same size
+// and checksum as the original. Files already present in dst with the same content are left
+// alone, while files with a different content are overwritten only when overwrite is true. It
+// returns what was done with the file and the number of bytes copied.
+func MigrateFile(src, dst FileBackend, path string, overwrite bool) (MigrateFileResult, int64, error) {
+	exists, err := src.FileExists(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !exists {
+		return MigrateFileMissing, 0, nil
+	}
+
+	size, err := src.FileSize(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	exists, err = dst.FileExists(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if exists {
+		dstSize, err := dst.FileSize(path)
+		if err != nil {
+			return 0, 0, err
+		}
+		if dstSize == size {
+			srcSum, _, err := hashBackendFile(src, path)
+			if err != nil {
+				return 0, 0, err
+			}
+			dstSum, _, err := hashBackendFile(dst, path)
+			if err != nil {
+				return 0, 0, err
+			}
+			if bytes.Equal(srcSum, dstSum) {
+				return MigrateFileVerified, 0, nil
+			}
+		}
+		if !overwrite {
+			return MigrateFileKept, 0, nil
+		}
+	}
+
+	file, err := src.Reader(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	written, err := TryWriteFileContext(context.Background(), dst, io.TeeReader(file, hash), path)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unable to copy the file %s", path)
+	}
+	if written != size {
+		return 0, 0, errors.Errorf("copied %d bytes of the file %s instead of %d", written, path, size)
+	}
+
+	dstSum, dstSize, err := hashBackendFile(dst, path)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "unable to verify the copy of the file %s", path)
+	}
+	if dstSize != size || !bytes.Equal(hash.Sum(nil), dstSum) {
+		return 0, 0, errors.Errorf("the copy of the file %s doesn't match the original", path)
+	}
+
+	return MigrateFileCopied, written, nil
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateFile(t *testing.T) {
+	setup := func(t *testing.T) (FileBackend, FileBackend) {
+		src := &LocalFileBackend{directory: t.TempDir()}
+		dst := newTestEncryptedFileBackend(t, t.TempDir(), randomBytes(t, encryptionKeySize))
+
+		_, err := src.WriteFile(bytes.NewReader([]byte("content")), "data/file.txt")
+		require.NoError(t, err)
+		return src, dst
+	}
+
+	t.Run("copies the file", func(t *testing.T) {
+		src, dst := setup(t)
+
+		result, written, err := MigrateFile(src, dst, "data/file.txt", false)
+		require.NoError(t, err)
+		assert.Equal(t, MigrateFileCopied, result)
+		assert.EqualValues(t, 7, written)
+
+		data, err := dst.ReadFile("data/file.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+
+		result, written, err = MigrateFile(src, dst, "data/file.txt", false)
+		require.NoError(t, err)
+		assert.Equal(t, MigrateFileVerified, result)
+		assert.Zero(t, written)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		src, dst := setup(t)
+
+		result, _, err := MigrateFile(src, dst, "data/missing.txt", false)
+		require.NoError(t, err)
+		assert.Equal(t, MigrateFileMissing, result)
+	})
+
+	t.Run("different file in the destination", func(t *testing.T) {
+		src, dst := setup(t)
+
+		_, err := dst.WriteFile(bytes.NewReader([]byte("updated")), "data/file.txt")
+		require.NoError(t, err)
+
+		result, _, err := MigrateFile(src, dst, "data/file.txt", false)
+		require.NoError(t, err)
+		assert.Equal(t, MigrateFileKept, result)
+		data, err := dst.ReadFile("data/file.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "updated", string(data))
+
+		result, _, err = MigrateFile(src, dst, "data/file.txt", true)
+		require.NoError(t, err)
+		assert.Equal(t, MigrateFileCopied, result)
+		data, err = dst.ReadFile("data/file.txt")
+		require.NoError(t, err)
+		assert.Equal(t, "content", string(data))
+	})
+}
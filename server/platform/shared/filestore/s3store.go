@@ -61,12 +61,16 @@ const (
 	// This is not exported by minio. See: https://github.com/minio/minio-go/issues/1339
 	bucketNotFound = "NoSuchBucket"
 	invalidBucket  = "InvalidBucketName"
+
+	// The minimum size of the parts of a composed object, except for the last one.
+	s3MinPartSize = 5 * 1024 * 1024
 )
 
 var (
 	// Ensure that the ReaderAt interface is implemented.
 	_ io.ReaderAt                  = (*s3WithCancel)(nil)
 	_ FileBackendWithLinkGenerator = (*S3FileBackend)(nil)
+	_ fileOverwriter               = (*S3FileBackend)(nil)
 )
 
 func getContentType(ext string) string {
@@ -540,6 +544,49 @@ func (b *S3FileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
 		return 0, errors.Wrapf(err2, "unable to find the file %s to append the data", path)
 	}
 
+	return b.composeFile(fr, path, fp, s3.CopySrcOptions{
+		Bucket: b.bucket,
+		Object: fp,
+	})
+}
+
+func (b *S3FileBackend) overwriteFileFrom(fr io.Reader, path string, offset int64) (int64, error) {
+	fp, err := b.prefixedPath(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to prefix path %s", path)
+	}
+
+	// Only the last part of a composed object can be smaller than the minimum part size, so the
+	// start of small files is written again along with the new content.
+	if offset < s3MinPartSize {
+		r, err := b.Reader(path)
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to find the file %s to overwrite the data", path)
+		}
+		start, err := io.ReadAll(io.LimitReader(r, offset))
+		r.Close()
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable to read the file %s", path)
+		}
+		if int64(len(start)) != offset {
+			return 0, errors.Errorf("unable to overwrite the file %s past its end", path)
+		}
+
+		written, err := b.WriteFile(io.MultiReader(bytes.NewReader(start), fr), path)
+		return max(written-offset, 0), err
+	}
+
+	return b.composeFile(fr, path, fp, s3.CopySrcOptions{
+		Bucket:     b.bucket,
+		Object:     fp,
+		MatchRange: true,
+		Start:      0,
+		End:        offset - 1,
+	})
+}
+
+// composeFile replaces the file at path with src followed by the content read from fr.
+func (b *S3FileBackend) composeFile(fr io.Reader, path, fp string, src s3.CopySrcOptions) (int64, error) {
 	contentType := getContentType(filepath.Ext(fp))
 
 	options := s3PutOptions(b.encrypt, contentType, b.uploadPartSize, b.storageClass)
@@ -566,10 +613,6 @@ func (b *S3FileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
 		b.client.RemoveObject(ctx4, b.bucket, partName, s3.RemoveObjectOptions{})
 	}()
 
-	src1Opts := s3.CopySrcOptions{
-		Bucket: b.bucket,
-		Object: fp,
-	}
 	src2Opts := s3.CopySrcOptions{
 		Bucket: b.bucket,
 		Object: partName,
@@ -581,7 +624,7 @@ func (b *S3FileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
 	}
 	ctx3, cancel3 := context.WithTimeout(context.Background(), b.timeout)
 	defer cancel3()
-	_, err = b.client.ComposeObject(ctx3, dstOpts, src1Opts, src2Opts)
+	_, err = b.client.ComposeObject(ctx3, dstOpts, src, src2Opts)
 	if err != nil {
 		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
 	}
@@ -651,6 +694,46 @@ func (b *S3FileBackend) ListDirectoryRecursively(path string) ([]string, error)
 	return b.listDirectory(path, true)
 }
 
+// ListDirectoryRecursivelyAfter lists the files in the lexical order of their keys.
+func (b *S3FileBackend) ListDirectoryRecursivelyAfter(path, after string, limit int) ([]string, error) {
+	path, err := b.prefixedPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to prefix path %s", path)
+	}
+	if !strings.HasSuffix(path, "/") && path != "" {
+		path = path + "/"
+	}
+
+	opts := s3.ListObjectsOptions{
+		Prefix:    path,
+		Recursive: true,
+	}
+	if after != "" {
+		if opts.StartAfter, err = b.prefixedPath(after); err != nil {
+			return nil, errors.Wrapf(err, "unable to prefix path %s", after)
+		}
+	}
+
+	paths := []string{}
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	for object := range b.client.ListObjects(ctx, b.bucket, opts) {
+		if object.Err != nil {
+			return nil, errors.Wrapf(object.Err, "unable to list the directory %s", path)
+		}
+		object.Key = strings.TrimPrefix(object.Key, b.pathPrefix)
+		if trimmed := strings.Trim(object.Key, "/"); trimmed != "" {
+			paths = append(paths, trimmed)
+		}
+		// Canceling the context stops the listing.
+		if len(paths) >= limit {
+			break
+		}
+	}
+
+	return paths, nil
+}
+
 func (b *S3FileBackend) RemoveDirectory(path string) error {
 	path, err := b.prefixedPath(path)
 	if err != nil {
@@ -0,0 +1,251 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/jaytaylor/html2text"
+)
+
+// maxInboundMIMEDepth limits how deeply nested multipart bodies are walked.
+const maxInboundMIMEDepth = 5
+
+var ErrNoTextBody = errors.New("message has no text body")
+
+// InboundMessage holds the parts of a received email needed to turn it into a post.
+type InboundMessage struct {
+	From       string
+	Recipients []string
+	Subject    string
+	MessageID  string
+	// AutoSubmitted is set for messages generated automatically, such as vacation
+	// responders and delivery reports, which should never be posted.
+	AutoSubmitted bool
+	TextBody      string
+}
+
+// ParseInboundMessage parses a raw RFC 5322 message, as handed over by an MTA, and
+// extracts its sender, recipients and plain text body. When the message only has an
+// HTML body, it is converted to text.
+func ParseInboundMessage(r io.Reader) (*InboundMessage, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	from, err := msg.Header.AddressList("From")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sender: %w", err)
+	} else if len(from) != 1 {
+		return nil, errors.New("message must have a single sender")
+	}
+
+	inbound := &InboundMessage{
+		From:      strings.ToLower(from[0].Address),
+		MessageID: msg.Header.Get("Message-ID"),
+	}
+
+	decoder := mime.WordDecoder{}
+	if inbound.Subject, err = decoder.DecodeHeader(msg.Header.Get("Subject")); err != nil {
+		inbound.Subject = msg.Header.Get("Subject")
+	}
+
+	// The envelope recipient is only known through the headers added by the MTA, so
+	// gather every address the message may have been delivered to.
+	for _, key := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		addresses, err := msg.Header.AddressList(key)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			inbound.Recipients = append(inbound.Recipients, strings.ToLower(address.Address))
+		}
+	}
+
+	if autoSubmitted := msg.Header.Get("Auto-Submitted"); autoSubmitted != "" && !strings.EqualFold(autoSubmitted, "no") {
+		inbound.AutoSubmitted = true
+	}
+
+	text, html, err := readInboundBody(msg.Header, msg.Body, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case text != "":
+		inbound.TextBody = text
+	case html != "":
+		if inbound.TextBody, err = html2text.FromString(html, html2text.Options{OmitLinks: true}); err != nil {
+			return nil, fmt.Errorf("failed to convert html body: %w", err)
+		}
+	default:
+		return nil, ErrNoTextBody
+	}
+
+	inbound.TextBody = strings.ReplaceAll(inbound.TextBody, "\r\n", "\n")
+
+	return inbound, nil
+}
+
+// readInboundBody returns the first text/plain and text/html bodies found in the given
+// part, walking nested multipart bodies and skipping attachments.
+func readInboundBody(header map[string][]string, body io.Reader, depth int) (string, string, error) {
+	get := func(key string) string {
+		if values := header[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	mediaType, params, err := mime.ParseMediaType(get("Content-Type"))
+	if err != nil {
+		// Messages without a valid content type are plain text by definition
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if disposition, _, _ := mime.ParseMediaType(get("Content-Disposition")); disposition == "attachment" {
+		return "", "", nil
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxInboundMIMEDepth {
+			return "", "", nil
+		}
+
+		var text, html string
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return "", "", fmt.Errorf("failed to read multipart body: %w", err)
+			}
+
+			partText, partHTML, err := readInboundBody(part.Header, part, depth+1)
+			if err != nil {
+				return "", "", err
+			}
+			if text == "" {
+				text = partText
+			}
+			if html == "" {
+				html = partHTML
+			}
+		}
+		return text, html, nil
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return "", "", nil
+	}
+
+	switch strings.ToLower(get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: body})
+	}
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode body: %w", err)
+	}
+
+	decoded := decodeCharset(content, params["charset"])
+	if mediaType == "text/html" {
+		return "", decoded, nil
+	}
+	return decoded, "", nil
+}
+
+// decodeCharset converts the body to UTF-8. Only Latin-1 needs converting, as ASCII is
+// a subset of UTF-8 and other encodings are left as they are with invalid sequences
+// replaced.
+func decodeCharset(content []byte, charset string) string {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1":
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	default:
+		return strings.ToValidUTF8(string(content), "�")
+	}
+}
+
+// base64Cleaner drops the line breaks that split base64 encoded bodies.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+var (
+	// Attribution lines that mail clients write above the quoted message, such as
+	// "On Mon, Jan 2, 2006 at 3:04 PM, Someone <someone@example.com> wrote:".
+	replyAttributionRegexp = regexp.MustCompile(`(?i)^(on\s.+\swrote:|le\s.+\sa écrit\s?:|am\s.+\sschrieb\s.+:)$`)
+	// Separators that Outlook and similar clients put above the quoted message.
+	replySeparatorRegexp = regexp.MustCompile(`(?i)^(-+\s*original message\s*-+|_{10,})$`)
+)
+
+// StripReply returns the text written by the sender of a reply, dropping the quoted
+// message, the attribution line above it and the sender's signature.
+func StripReply(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+
+	kept := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		// The signature delimiter is a line containing only "-- "
+		if line == "--" {
+			break
+		}
+
+		if replySeparatorRegexp.MatchString(trimmed) {
+			break
+		}
+
+		if replyAttributionRegexp.MatchString(trimmed) {
+			break
+		}
+
+		// Some clients wrap long attribution lines, so look at two lines joined
+		if i+1 < len(lines) && replyAttributionRegexp.MatchString(trimmed+" "+strings.TrimSpace(lines[i+1])) {
+			break
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		kept = append(kept, line)
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInboundMessage(t *testing.T) {
+	t.Run("plain text message", func(t *testing.T) {
+		raw := "From: Jane Doe <Jane@Example.com>\r\n" +
+			"To: reply+abc.def@example.com\r\n" +
+			"Cc: Someone <someone@example.com>\r\n" +
+			"Subject: =?UTF-8?Q?Re:_caf=C3=A9?=\r\n" +
+			"Message-ID: <1234@example.com>\r\n" +
+			"\r\n" +
+			"Sounds good\r\n"
+
+		msg, err := ParseInboundMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, "jane@example.com", msg.From)
+		assert.Equal(t, []string{"reply+abc.def@example.com", "someone@example.com"}, msg.Recipients)
+		assert.Equal(t, "Re: café", msg.Subject)
+		assert.Equal(t, "<1234@example.com>", msg.MessageID)
+		assert.False(t, msg.AutoSubmitted)
+		assert.Equal(t, "Sounds good\n", msg.TextBody)
+	})
+
+	t.Run("multipart message prefers the text part", func(t *testing.T) {
+		raw := "From: jane@example.com\r\n" +
+			"To: reply@example.com\r\n" +
+			"Delivered-To: reply+abc.def@example.com\r\n" +
+			"Content-Type: multipart/mixed; boundary=outer\r\n" +
+			"\r\n" +
+			"--outer\r\n" +
+			"Content-Type: multipart/alternative; boundary=inner\r\n" +
+			"\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/html; charset=utf-8\r\n" +
+			"\r\n" +
+			"<p>HTML body</p>\r\n" +
+			"--inner\r\n" +
+			"Content-Type: text/plain; charset=utf-8\r\n" +
+			"Content-Transfer-Encoding: quoted-printable\r\n" +
+			"\r\n" +
+			"Caf=C3=A9 at no=\r\n" +
+			"on\r\n" +
+			"--inner--\r\n" +
+			"--outer\r\n" +
+			"Content-Type: text/plain\r\n" +
+			"Content-Disposition: attachment; filename=notes.txt\r\n" +
+			"\r\n" +
+			"attached notes\r\n" +
+			"--outer--\r\n"
+
+		msg, err := ParseInboundMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"reply@example.com", "reply+abc.def@example.com"}, msg.Recipients)
+		assert.Equal(t, "Café at noon", msg.TextBody)
+	})
+
+	t.Run("base64 encoded latin-1 body", func(t *testing.T) {
+		raw := "From: jane@example.com\r\n" +
+			"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+			"Content-Transfer-Encoding: base64\r\n" +
+			"\r\n" +
+			"Q2Fm6SBh\r\n" +
+			"dCBub29u\r\n"
+
+		msg, err := ParseInboundMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, "Café at noon", msg.TextBody)
+	})
+
+	t.Run("html only message", func(t *testing.T) {
+		raw := "From: jane@example.com\r\n" +
+			"Content-Type: text/html\r\n" +
+			"\r\n" +
+			"<div>Sounds <b>good</b></div>\r\n"
+
+		msg, err := ParseInboundMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.Equal(t, "Sounds *good*", strings.TrimSpace(msg.TextBody))
+	})
+
+	t.Run("auto submitted message", func(t *testing.T) {
+		raw := "From: jane@example.com\r\n" +
+			"Auto-Submitted: auto-replied\r\n" +
+			"\r\n" +
+			"I am out of the office\r\n"
+
+		msg, err := ParseInboundMessage(strings.NewReader(raw))
+		require.NoError(t, err)
+		assert.True(t, msg.AutoSubmitted)
+	})
+
+	t.Run("missing sender", func(t *testing.T) {
+		_, err := ParseInboundMessage(strings.NewReader("To: reply@example.com\r\n\r\nHello\r\n"))
+		require.Error(t, err)
+	})
+
+	t.Run("only attachments", func(t *testing.T) {
+		raw := "From: jane@example.com\r\n" +
+			"Content-Type: multipart/mixed; boundary=outer\r\n" +
+			"\r\n" +
+			"--outer\r\n" +
+			"Content-Type: image/png\r\n" +
+			"\r\n" +
+			"png\r\n" +
+			"--outer--\r\n"
+
+		_, err := ParseInboundMessage(strings.NewReader(raw))
+		require.ErrorIs(t, err, ErrNoTextBody)
+	})
+}
+
+func TestStripReply(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "no quoted text",
+			body:     "Sounds good\n\nSee you tomorrow\n",
+			expected: "Sounds good\n\nSee you tomorrow",
+		},
+		{
+			name:     "gmail attribution",
+			body:     "Sounds good\n\nOn Mon, Jan 2, 2006 at 3:04 PM Someone <someone@example.com> wrote:\n> Are we meeting?\n",
+			expected: "Sounds good",
+		},
+		{
+			name:     "wrapped attribution",
+			body:     "Sounds good\n\nOn Mon, Jan 2, 2006 at 3:04 PM Someone <\nsomeone@example.com> wrote:\n> Are we meeting?\n",
+			expected: "Sounds good",
+		},
+		{
+			name:     "interleaved quotes",
+			body:     "> Are we meeting?\nYes\n> Where?\nHere\n",
+			expected: "Yes\nHere",
+		},
+		{
+			name:     "signature",
+			body:     "Sounds good\n-- \nJane Doe\nExample Inc.\n",
+			expected: "Sounds good",
+		},
+		{
+			name:     "outlook separator",
+			body:     "Sounds good\r\n\r\n-----Original Message-----\r\nFrom: Someone\r\nAre we meeting?\r\n",
+			expected: "Sounds good",
+		},
+		{
+			name:     "outlook underscores",
+			body:     "Sounds good\n\n________________________________\nFrom: Someone\n",
+			expected: "Sounds good",
+		},
+		{
+			name:     "only quoted text",
+			body:     "> Are we meeting?\n",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, StripReply(tc.body))
+		})
+	}
+}
@@ -29,6 +29,8 @@ type Store interface {
 	UpdateMfaSecret(userId, secret string) error
 	StoreMfaUsedTimestamps(userId string, ts []int) error
 	GetMfaUsedTimestamps(userId string) ([]int, error)
+	UpdateMfaRecoveryCodes(userId string, hashedCodes []string) error
+	UseMfaRecoveryCode(userId, hashedCode string) (bool, error)
 }
 
 type MFA struct {
@@ -83,28 +85,29 @@ func (m *MFA) GenerateSecret(siteURL, userEmail, userID string) (string, []byte,
 	return secret, img, nil
 }
 
-// Activate set the mfa as active and store it with the StoreActive function provided
-func (m *MFA) Activate(userMfaSecret, userID string, token string) error {
+// Activate set the mfa as active and store it with the StoreActive function provided.
+// It returns a new set of recovery codes that can each be used once in place of a token.
+func (m *MFA) Activate(userMfaSecret, userID string, token string) ([]string, error) {
 	usedTs, err := m.store.GetMfaUsedTimestamps(userID)
 	if err != nil {
-		return errors.Wrap(err, "unable to retrieve the DisallowReuse slice")
+		return nil, errors.Wrap(err, "unable to retrieve the DisallowReuse slice")
 	}
 
 	otpConfig, err := m.authenticate(userMfaSecret, usedTs, token)
 	if err != nil {
-		return errors.Wrap(err, "unable to authenticate the token")
+		return nil, errors.Wrap(err, "unable to authenticate the token")
 	}
 
 	if err = m.store.UpdateMfaActive(userID, true); err != nil {
-		return errors.Wrap(err, "unable to store mfa active")
+		return nil, errors.Wrap(err, "unable to store mfa active")
 	}
 
 	err = m.store.StoreMfaUsedTimestamps(userID, otpConfig.DisallowReuse)
 	if err != nil {
-		return errors.Wrap(err, "unable to store the DisallowReuse slice")
+		return nil, errors.Wrap(err, "unable to store the DisallowReuse slice")
 	}
 
-	return nil
+	return m.GenerateRecoveryCodes(userID)
 }
 
 // Deactivate set the mfa as deactivated, remove the mfa secret, store it with the StoreActive and StoreSecret functions provided
@@ -117,11 +120,24 @@ func (m *MFA) Deactivate(userId string) error {
 		return errors.Wrap(err, "unable to store mfa secret")
 	}
 
+	if err := m.store.UpdateMfaRecoveryCodes(userId, []string{}); err != nil {
+		return errors.Wrap(err, "unable to store mfa recovery codes")
+	}
+
 	return nil
 }
 
-// Validate the provide token using the secret provided
+// Validate the provide token using the secret provided. A recovery code is accepted in place
+// of a token, and is used up by a successful validation.
 func (m *MFA) ValidateToken(user *model.User, token string) (bool, error) {
+	if IsRecoveryCode(token) {
+		ok, err := m.store.UseMfaRecoveryCode(user.Id, hashRecoveryCode(user.Id, token))
+		if err != nil {
+			return false, errors.Wrap(err, "unable to use the recovery code")
+		}
+		return ok, nil
+	}
+
 	usedTs, err := m.store.GetMfaUsedTimestamps(user.Id)
 	if err != nil {
 		return false, errors.Wrap(err, "unable to retrieve the DisallowReuse slice")
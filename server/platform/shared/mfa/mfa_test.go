@@ -80,7 +80,7 @@ func TestActivate(t *testing.T) {
 		storeMock := mocks.UserStore{}
 		storeMock.On("GetMfaUsedTimestamps", userID).Return([]int{}, nil).Once()
 
-		err := New(&storeMock).Activate(userMfaSecret, userID, "invalid-token")
+		_, err := New(&storeMock).Activate(userMfaSecret, userID, "invalid-token")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unable to parse the token")
 	})
@@ -89,7 +89,7 @@ func TestActivate(t *testing.T) {
 		storeMock := mocks.UserStore{}
 		storeMock.On("GetMfaUsedTimestamps", userID).Return([]int{}, nil).Once()
 
-		err := New(&storeMock).Activate(userMfaSecret, userID, "000000")
+		_, err := New(&storeMock).Activate(userMfaSecret, userID, "000000")
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "invalid mfa token")
 	})
@@ -101,7 +101,7 @@ func TestActivate(t *testing.T) {
 			return errors.New("failed to update mfa active")
 		})
 
-		err := New(&storeMock).Activate(userMfaSecret, userID, fmt.Sprintf("%06d", token))
+		_, err := New(&storeMock).Activate(userMfaSecret, userID, fmt.Sprintf("%06d", token))
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "unable to store mfa active")
 	})
@@ -117,9 +117,12 @@ func TestActivate(t *testing.T) {
 		usMock.On("GetMfaUsedTimestamps", userID).Return([]int{}, nil).Once()
 		usMock.On("UpdateMfaActive", userID, true).Return(nil).Once()
 		usMock.On("StoreMfaUsedTimestamps", userID, mock.AnythingOfType("[]int")).Return(nil).Once()
+		usMock.On("UpdateMfaRecoveryCodes", userID, mock.AnythingOfType("[]string")).Return(nil).Once()
 
-		err := New(&usMock).Activate(secret, userID, code)
+		recoveryCodes, err := New(&usMock).Activate(secret, userID, code)
 		require.NoError(t, err)
+		require.Len(t, recoveryCodes, RecoveryCodeCount)
+		usMock.AssertExpectations(t)
 	})
 
 	t.Run("disallow reuse of totp", func(t *testing.T) {
@@ -132,7 +135,7 @@ func TestActivate(t *testing.T) {
 		usMock := mocks.UserStore{}
 		usMock.On("GetMfaUsedTimestamps", userID).Return([]int{int(t0)}, nil).Once()
 
-		err := New(&usMock).Activate(secret, userID, code)
+		_, err := New(&usMock).Activate(secret, userID, code)
 		require.Error(t, err)
 	})
 }
@@ -168,6 +171,17 @@ func TestDeactivate(t *testing.T) {
 		require.Contains(t, err.Error(), "unable to store mfa secret")
 	})
 
+	t.Run("fail on store UpdateMfaRecoveryCodes action fail", func(t *testing.T) {
+		storeMock := mocks.UserStore{}
+		storeMock.On("UpdateMfaActive", userID, false).Return(nil)
+		storeMock.On("UpdateMfaSecret", userID, "").Return(nil)
+		storeMock.On("UpdateMfaRecoveryCodes", userID, []string{}).Return(errors.New("failed to update mfa recovery codes"))
+
+		err := New(&storeMock).Deactivate(userID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to store mfa recovery codes")
+	})
+
 	t.Run("Successful deactivate", func(t *testing.T) {
 		storeMock := mocks.UserStore{}
 		storeMock.On("UpdateMfaActive", userID, false).Return(func(userId string, active bool) error {
@@ -176,6 +190,7 @@ func TestDeactivate(t *testing.T) {
 		storeMock.On("UpdateMfaSecret", userID, "").Return(func(userId string, secret string) error {
 			return nil
 		})
+		storeMock.On("UpdateMfaRecoveryCodes", userID, []string{}).Return(nil)
 
 		err := New(&storeMock).Deactivate(userID)
 		require.NoError(t, err)
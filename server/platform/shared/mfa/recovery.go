@@ -0,0 +1,104 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RecoveryCodeCount is the number of recovery codes generated at a time.
+	RecoveryCodeCount = 10
+
+	// Recovery codes are made of ten base32 characters, giving 50 bits of entropy each,
+	// and are displayed in two groups of five.
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+)
+
+// GenerateRecoveryCodes replaces the recovery codes of the user with a new set and returns them.
+// Only hashes of the codes are stored.
+func (m *MFA) GenerateRecoveryCodes(userID string) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashedCodes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to generate recovery code")
+		}
+		codes[i] = code
+		hashedCodes[i] = hashRecoveryCode(userID, code)
+	}
+
+	if err := m.store.UpdateMfaRecoveryCodes(userID, hashedCodes); err != nil {
+		return nil, errors.Wrap(err, "unable to store mfa recovery codes")
+	}
+
+	return codes, nil
+}
+
+// RevokeRecoveryCodes removes the recovery codes of the user, without generating new ones.
+func (m *MFA) RevokeRecoveryCodes(userID string) error {
+	if err := m.store.UpdateMfaRecoveryCodes(userID, []string{}); err != nil {
+		return errors.Wrap(err, "unable to revoke mfa recovery codes")
+	}
+
+	return nil
+}
+
+// IsRecoveryCode reports whether token looks like a recovery code rather than a TOTP token.
+func IsRecoveryCode(token string) bool {
+	code := normalizeRecoveryCode(token)
+	if len(code) != recoveryCodeLength {
+		return false
+	}
+
+	for _, c := range code {
+		if !strings.ContainsRune(recoveryCodeAlphabet, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRecoveryCode() (string, error) {
+	data := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, b := range data {
+		if i == recoveryCodeLength/2 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+	}
+
+	return sb.String(), nil
+}
+
+// normalizeRecoveryCode strips the separators and whitespace users may type along with a code.
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', ' ', '\t', '\n', '\r':
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
+
+// hashRecoveryCode returns the stored form of a recovery code. The codes are random with enough
+// entropy that a salted SHA-256 is sufficient, and it allows a code to be looked up directly.
+func hashRecoveryCode(userID, code string) string {
+	sum := sha256.Sum256([]byte(userID + ":" + normalizeRecoveryCode(code)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest/mocks"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("fail on store action fail", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UpdateMfaRecoveryCodes", userID, mock.AnythingOfType("[]string")).Return(errors.New("failed to update recovery codes"))
+
+		_, err := New(&usMock).GenerateRecoveryCodes(userID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to store mfa recovery codes")
+	})
+
+	t.Run("only hashes are stored", func(t *testing.T) {
+		var stored []string
+		usMock := mocks.UserStore{}
+		usMock.On("UpdateMfaRecoveryCodes", userID, mock.AnythingOfType("[]string")).Run(func(args mock.Arguments) {
+			stored = args.Get(1).([]string)
+		}).Return(nil)
+
+		codes, err := New(&usMock).GenerateRecoveryCodes(userID)
+		require.NoError(t, err)
+		require.Len(t, codes, RecoveryCodeCount)
+		require.Len(t, stored, RecoveryCodeCount)
+
+		seen := map[string]bool{}
+		for i, code := range codes {
+			assert.Regexp(t, "^[a-z2-7]{5}-[a-z2-7]{5}$", code)
+			assert.True(t, IsRecoveryCode(code))
+			assert.False(t, seen[code], "codes should be unique")
+			seen[code] = true
+
+			assert.NotContains(t, stored, code)
+			assert.Equal(t, hashRecoveryCode(userID, code), stored[i])
+		}
+	})
+}
+
+func TestRevokeRecoveryCodes(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("fail on store action fail", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UpdateMfaRecoveryCodes", userID, []string{}).Return(errors.New("failed to update recovery codes"))
+
+		err := New(&usMock).RevokeRecoveryCodes(userID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unable to revoke mfa recovery codes")
+	})
+
+	t.Run("codes are cleared", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UpdateMfaRecoveryCodes", userID, []string{}).Return(nil)
+
+		require.NoError(t, New(&usMock).RevokeRecoveryCodes(userID))
+		usMock.AssertExpectations(t)
+	})
+}
+
+func TestIsRecoveryCode(t *testing.T) {
+	for _, token := range []string{"abcde-fghij", "ABCDE-FGHIJ", " abcdefghij ", "abcde fghij", "22345-67abc"} {
+		assert.True(t, IsRecoveryCode(token), token)
+	}
+
+	for _, token := range []string{"", "123456", "invalid-token", "abcde-fghi", "abcde-fghij1", "abcde-fghi1", "abcde-fgh0j"} {
+		assert.False(t, IsRecoveryCode(token), token)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	userID := model.NewId()
+
+	assert.Equal(t, hashRecoveryCode(userID, "abcde-fghij"), hashRecoveryCode(userID, " ABCDEFGHIJ"))
+	assert.NotEqual(t, hashRecoveryCode(userID, "abcde-fghij"), hashRecoveryCode(model.NewId(), "abcde-fghij"), "hashes should be salted with the user id")
+	assert.NotContains(t, hashRecoveryCode(userID, "abcde-fghij"), "abcde")
+}
+
+func TestValidateRecoveryCode(t *testing.T) {
+	u := &model.User{Id: model.NewId(), MfaSecret: newRandomBase32String(mfaSecretSize)}
+	code := "abcde-fghij"
+
+	t.Run("valid code is used", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UseMfaRecoveryCode", u.Id, hashRecoveryCode(u.Id, code)).Return(true, nil).Once()
+
+		ok, err := New(&usMock).ValidateToken(u, strings.ToUpper(code))
+		require.NoError(t, err)
+		require.True(t, ok)
+		usMock.AssertExpectations(t)
+	})
+
+	t.Run("unknown or used code", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UseMfaRecoveryCode", u.Id, hashRecoveryCode(u.Id, code)).Return(false, nil).Once()
+
+		ok, err := New(&usMock).ValidateToken(u, code)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("fail on store action fail", func(t *testing.T) {
+		usMock := mocks.UserStore{}
+		usMock.On("UseMfaRecoveryCode", u.Id, hashRecoveryCode(u.Id, code)).Return(false, errors.New("failed")).Once()
+
+		ok, err := New(&usMock).ValidateToken(u, code)
+		require.Error(t, err)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/pkg/errors"
+)
+
+// InvalidWebAuthnResponse indicates that a WebAuthn registration or assertion could not be verified.
+var InvalidWebAuthnResponse = errors.New("invalid webauthn response")
+
+// COSE algorithm identifiers supported for WebAuthn credentials, in order of preference.
+const (
+	COSEAlgorithmES256 = int64(webauthncose.AlgES256)
+	COSEAlgorithmEdDSA = int64(webauthncose.AlgEdDSA)
+	COSEAlgorithmES384 = int64(webauthncose.AlgES384)
+	COSEAlgorithmES512 = int64(webauthncose.AlgES512)
+	COSEAlgorithmPS256 = int64(webauthncose.AlgPS256)
+	COSEAlgorithmRS256 = int64(webauthncose.AlgRS256)
+)
+
+var WebAuthnSupportedAlgorithms = []int64{
+	COSEAlgorithmES256,
+	COSEAlgorithmEdDSA,
+	COSEAlgorithmES384,
+	COSEAlgorithmES512,
+	COSEAlgorithmPS256,
+	COSEAlgorithmRS256,
+}
+
+// WebAuthnRelyingParty identifies the server to WebAuthn authenticators. Credentials are
+// scoped to the relying party ID, so changing the Site URL invalidates registered credentials.
+//
+// The ceremonies are verified with github.com/go-webauthn/webauthn and always require user
+// verification, so that a WebAuthn credential is a second factor on its own. Attestation
+// statements are checked for consistency but not against trust anchors: the server asks for no
+// attestation and accepts any authenticator.
+type WebAuthnRelyingParty struct {
+	ID     string
+	Origin string
+}
+
+// WebAuthnCredential is a credential that passed registration.
+type WebAuthnCredential struct {
+	ID        []byte
+	PublicKey []byte
+	SignCount uint32
+	AAGUID    []byte
+}
+
+// NewWebAuthnRelyingParty derives the relying party from the Site URL.
+func NewWebAuthnRelyingParty(siteURL string) (*WebAuthnRelyingParty, error) {
+	u, err := url.Parse(strings.TrimSpace(siteURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the site url")
+	}
+
+	if u.Hostname() == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, errors.New("the site url must be an absolute http or https url")
+	}
+
+	return &WebAuthnRelyingParty{
+		ID:     u.Hostname(),
+		Origin: u.Scheme + "://" + u.Host,
+	}, nil
+}
+
+// VerifyRegistration checks the response of a navigator.credentials.create() ceremony
+// started with the given challenge and returns the new credential.
+func (rp *WebAuthnRelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte) (*WebAuthnCredential, error) {
+	if len(challenge) == 0 {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, "missing challenge")
+	}
+
+	raw := protocol.AuthenticatorAttestationResponse{
+		AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+		AttestationObject:     attestationObject,
+	}
+	parsed, err := raw.Parse()
+	if err != nil {
+		return nil, invalidWebAuthnResponse(err)
+	}
+
+	credParams := make([]protocol.CredentialParameter, 0, len(WebAuthnSupportedAlgorithms))
+	for _, alg := range WebAuthnSupportedAlgorithms {
+		credParams = append(credParams, protocol.CredentialParameter{
+			Type:      protocol.PublicKeyCredentialType,
+			Algorithm: webauthncose.COSEAlgorithmIdentifier(alg),
+		})
+	}
+
+	creation := &protocol.ParsedCredentialCreationData{
+		Response: *parsed,
+		Raw:      protocol.CredentialCreationResponse{AttestationResponse: raw},
+	}
+	if _, err := creation.Verify(base64.RawURLEncoding.EncodeToString(challenge), true, true, rp.ID, []string{rp.Origin}, nil, protocol.TopOriginIgnoreVerificationMode, nil, credParams); err != nil {
+		return nil, invalidWebAuthnResponse(err)
+	}
+
+	authData := parsed.AttestationObject.AuthData
+	if len(authData.AttData.CredentialID) == 0 {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, "missing attested credential data")
+	}
+
+	return &WebAuthnCredential{
+		ID:        authData.AttData.CredentialID,
+		PublicKey: authData.AttData.CredentialPublicKey,
+		SignCount: authData.Counter,
+		AAGUID:    authData.AttData.AAGUID,
+	}, nil
+}
+
+// VerifyAssertion checks the response of a navigator.credentials.get() ceremony started
+// with the given challenge against a registered credential and returns the new signature counter.
+func (rp *WebAuthnRelyingParty) VerifyAssertion(challenge, credentialID, clientDataJSON, authenticatorData, signature, publicKey []byte, storedSignCount uint32) (uint32, error) {
+	if len(challenge) == 0 {
+		return 0, errors.Wrap(InvalidWebAuthnResponse, "missing challenge")
+	}
+
+	response := protocol.CredentialAssertionResponse{
+		PublicKeyCredential: protocol.PublicKeyCredential{
+			Credential: protocol.Credential{
+				ID:   base64.RawURLEncoding.EncodeToString(credentialID),
+				Type: string(protocol.PublicKeyCredentialType),
+			},
+			RawID: credentialID,
+		},
+		AssertionResponse: protocol.AuthenticatorAssertionResponse{
+			AuthenticatorResponse: protocol.AuthenticatorResponse{ClientDataJSON: clientDataJSON},
+			AuthenticatorData:     authenticatorData,
+			Signature:             signature,
+		},
+	}
+	parsed, err := response.Parse()
+	if err != nil {
+		return 0, invalidWebAuthnResponse(err)
+	}
+
+	if err := parsed.Verify(base64.RawURLEncoding.EncodeToString(challenge), rp.ID, []string{rp.Origin}, nil, protocol.TopOriginIgnoreVerificationMode, "", true, true, publicKey); err != nil {
+		return 0, invalidWebAuthnResponse(err)
+	}
+
+	// A counter that does not increase signals a possibly cloned authenticator. Authenticators
+	// that do not implement a counter always report zero.
+	signCount := parsed.Response.AuthenticatorData.Counter
+	if (signCount != 0 || storedSignCount != 0) && signCount <= storedSignCount {
+		return 0, errors.Wrap(InvalidWebAuthnResponse, "signature counter did not increase")
+	}
+
+	return signCount, nil
+}
+
+// WebAuthnClientDataChallenge returns the challenge a client response claims to answer, so
+// that the challenge issued by the server can be looked up before the response is verified.
+func WebAuthnClientDataChallenge(clientDataJSON []byte) ([]byte, error) {
+	var clientData protocol.CollectedClientData
+	if err := json.Unmarshal(clientDataJSON, &clientData); err != nil {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, "unable to parse client data")
+	}
+
+	challenge, err := base64.RawURLEncoding.DecodeString(clientData.Challenge)
+	if err != nil || len(challenge) == 0 {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, "invalid challenge")
+	}
+
+	return challenge, nil
+}
+
+// invalidWebAuthnResponse wraps an error of the WebAuthn library, keeping its debugging details.
+func invalidWebAuthnResponse(err error) error {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) && protocolErr.DevInfo != "" {
+		return errors.Wrapf(InvalidWebAuthnResponse, "%s: %s", protocolErr.Details, protocolErr.DevInfo)
+	}
+	return errors.Wrap(InvalidWebAuthnResponse, err.Error())
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestCBOR(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := webauthncbor.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
+
+// webAuthnTestFlags are the flags of an authenticator that checked the presence and identity of the user.
+const webAuthnTestFlags = protocol.FlagUserPresent | protocol.FlagUserVerified
+
+type testAuthenticator struct {
+	t            *testing.T
+	rp           *WebAuthnRelyingParty
+	credentialID []byte
+	ecKey        *ecdsa.PrivateKey
+	edKey        ed25519.PrivateKey
+	signCount    uint32
+}
+
+func newTestAuthenticator(t *testing.T, rp *WebAuthnRelyingParty, eddsa bool) *testAuthenticator {
+	a := &testAuthenticator{t: t, rp: rp, credentialID: make([]byte, 32), signCount: 1}
+	_, err := rand.Read(a.credentialID)
+	require.NoError(t, err)
+
+	if eddsa {
+		_, a.edKey, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		a.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	require.NoError(t, err)
+
+	return a
+}
+
+func (a *testAuthenticator) coseKey() []byte {
+	if a.edKey != nil {
+		return encodeTestCBOR(a.t, map[any]any{1: 1, 3: -8, -1: 6, -2: []byte(a.edKey.Public().(ed25519.PublicKey))})
+	}
+
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.ecKey.X.FillBytes(x)
+	a.ecKey.Y.FillBytes(y)
+	return encodeTestCBOR(a.t, map[any]any{1: 2, 3: -7, -1: 1, -2: x, -3: y})
+}
+
+func (a *testAuthenticator) authData(flags protocol.AuthenticatorFlags, attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rp.ID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *testAuthenticator) clientData(typ protocol.CeremonyType, challenge []byte, origin string) []byte {
+	data, err := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    origin,
+	})
+	require.NoError(a.t, err)
+	return data
+}
+
+func (a *testAuthenticator) sign(message []byte) []byte {
+	if a.edKey != nil {
+		return ed25519.Sign(a.edKey, message)
+	}
+
+	digest := sha256.Sum256(message)
+	sig, err := ecdsa.SignASN1(rand.Reader, a.ecKey, digest[:])
+	require.NoError(a.t, err)
+	return sig
+}
+
+func (a *testAuthenticator) register(challenge []byte) (clientDataJSON, attestationObject []byte) {
+	clientDataJSON = a.clientData(protocol.CreateCeremony, challenge, a.rp.Origin)
+	attestationObject = encodeTestCBOR(a.t, map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(webAuthnTestFlags|protocol.FlagAttestedCredentialData, true),
+	})
+	return clientDataJSON, attestationObject
+}
+
+func (a *testAuthenticator) assert(challenge []byte) (clientDataJSON, authenticatorData, signature []byte) {
+	a.signCount++
+	clientDataJSON = a.clientData(protocol.AssertCeremony, challenge, a.rp.Origin)
+	authenticatorData = a.authData(webAuthnTestFlags, false)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signature = a.sign(append(append([]byte(nil), authenticatorData...), clientDataHash[:]...))
+	return clientDataJSON, authenticatorData, signature
+}
+
+func TestNewWebAuthnRelyingParty(t *testing.T) {
+	rp, err := NewWebAuthnRelyingParty("https://chat.example.com:8443/subpath")
+	require.NoError(t, err)
+	assert.Equal(t, "chat.example.com", rp.ID)
+	assert.Equal(t, "https://chat.example.com:8443", rp.Origin)
+
+	_, err = NewWebAuthnRelyingParty("")
+	require.Error(t, err)
+
+	_, err = NewWebAuthnRelyingParty("ftp://example.com")
+	require.Error(t, err)
+}
+
+func TestWebAuthnCeremonies(t *testing.T) {
+	rp, err := NewWebAuthnRelyingParty("https://chat.example.com")
+	require.NoError(t, err)
+	challenge := []byte("registration-challenge-with-enough-entropy")
+
+	for name, eddsa := range map[string]bool{"ES256": false, "EdDSA": true} {
+		t.Run(name, func(t *testing.T) {
+			authenticator := newTestAuthenticator(t, rp, eddsa)
+
+			clientDataJSON, attestationObject := authenticator.register(challenge)
+			credential, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+			require.NoError(t, err)
+			assert.Equal(t, authenticator.credentialID, credential.ID)
+			assert.Equal(t, uint32(1), credential.SignCount)
+
+			t.Run("valid assertion", func(t *testing.T) {
+				loginChallenge := []byte("login-challenge")
+				clientDataJSON, authData, sig := authenticator.assert(loginChallenge)
+				signCount, err := rp.VerifyAssertion(loginChallenge, authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, credential.SignCount)
+				require.NoError(t, err)
+				assert.Equal(t, uint32(2), signCount)
+
+				_, err = rp.VerifyAssertion(loginChallenge, authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, signCount)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse, "a replayed counter must be rejected")
+			})
+
+			t.Run("wrong challenge", func(t *testing.T) {
+				clientDataJSON, authData, sig := authenticator.assert([]byte("other"))
+				_, err := rp.VerifyAssertion([]byte("expected"), authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, 0)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse)
+			})
+
+			t.Run("tampered signature", func(t *testing.T) {
+				clientDataJSON, authData, sig := authenticator.assert(challenge)
+				sig[len(sig)-1] ^= 0xff
+				_, err := rp.VerifyAssertion(challenge, authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, 0)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse)
+			})
+
+			t.Run("other relying party", func(t *testing.T) {
+				other, err := NewWebAuthnRelyingParty("https://evil.example.com")
+				require.NoError(t, err)
+				clientDataJSON, authData, sig := authenticator.assert(challenge)
+				_, err = other.VerifyAssertion(challenge, authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, 0)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse)
+			})
+
+			t.Run("registration response used as assertion", func(t *testing.T) {
+				_, err := rp.VerifyAssertion(challenge, authenticator.credentialID, clientDataJSON, authenticator.authData(webAuthnTestFlags, false), authenticator.sign([]byte("x")), credential.PublicKey, 0)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse)
+			})
+
+			t.Run("assertion without user verification", func(t *testing.T) {
+				authenticator.signCount++
+				clientDataJSON := authenticator.clientData(protocol.AssertCeremony, challenge, rp.Origin)
+				authData := authenticator.authData(protocol.FlagUserPresent, false)
+				clientDataHash := sha256.Sum256(clientDataJSON)
+				sig := authenticator.sign(append(append([]byte(nil), authData...), clientDataHash[:]...))
+				_, err := rp.VerifyAssertion(challenge, authenticator.credentialID, clientDataJSON, authData, sig, credential.PublicKey, 0)
+				require.ErrorIs(t, err, InvalidWebAuthnResponse)
+			})
+		})
+	}
+
+	t.Run("registration without user presence", func(t *testing.T) {
+		authenticator := newTestAuthenticator(t, rp, false)
+		clientDataJSON := authenticator.clientData(protocol.CreateCeremony, challenge, rp.Origin)
+		attestationObject := encodeTestCBOR(t, map[any]any{
+			"fmt":      "none",
+			"attStmt":  map[any]any{},
+			"authData": authenticator.authData(protocol.FlagUserVerified|protocol.FlagAttestedCredentialData, true),
+		})
+		_, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+	})
+
+	t.Run("registration without user verification", func(t *testing.T) {
+		authenticator := newTestAuthenticator(t, rp, false)
+		clientDataJSON := authenticator.clientData(protocol.CreateCeremony, challenge, rp.Origin)
+		attestationObject := encodeTestCBOR(t, map[any]any{
+			"fmt":      "none",
+			"attStmt":  map[any]any{},
+			"authData": authenticator.authData(protocol.FlagUserPresent|protocol.FlagAttestedCredentialData, true),
+		})
+		_, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+	})
+
+	t.Run("registration from another origin", func(t *testing.T) {
+		authenticator := newTestAuthenticator(t, rp, false)
+		_, attestationObject := authenticator.register(challenge)
+		clientDataJSON := authenticator.clientData(protocol.CreateCeremony, challenge, "https://evil.example.com")
+		_, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+	})
+
+	t.Run("packed self attestation", func(t *testing.T) {
+		authenticator := newTestAuthenticator(t, rp, false)
+		clientDataJSON := authenticator.clientData(protocol.CreateCeremony, challenge, rp.Origin)
+		authData := authenticator.authData(webAuthnTestFlags|protocol.FlagAttestedCredentialData, true)
+		clientDataHash := sha256.Sum256(clientDataJSON)
+		sig := authenticator.sign(append(append([]byte(nil), authData...), clientDataHash[:]...))
+
+		attestationObject := encodeTestCBOR(t, map[any]any{
+			"fmt":      "packed",
+			"attStmt":  map[any]any{"alg": -7, "sig": sig},
+			"authData": authData,
+		})
+		_, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+		require.NoError(t, err)
+
+		attestationObject = encodeTestCBOR(t, map[any]any{
+			"fmt":      "packed",
+			"attStmt":  map[any]any{"alg": -7, "sig": []byte("bogus")},
+			"authData": authData,
+		})
+		_, err = rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+	})
+}
+
+func TestWebAuthnClientDataChallenge(t *testing.T) {
+	challenge, err := WebAuthnClientDataChallenge([]byte(`{"type":"webauthn.get","challenge":"Y2hhbGxlbmdl","origin":"https://chat.example.com"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("challenge"), challenge)
+
+	_, err = WebAuthnClientDataChallenge([]byte(`{"type":"webauthn.get","challenge":""}`))
+	require.ErrorIs(t, err, InvalidWebAuthnResponse)
+
+	_, err = WebAuthnClientDataChallenge([]byte(`not json`))
+	require.ErrorIs(t, err, InvalidWebAuthnResponse)
+}
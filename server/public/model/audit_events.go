@@ -242,15 +242,18 @@ const (
 
 // Posts
 const (
-	AuditEventCreatePost         = "createPost"         // create post
-	AuditEventDeletePost         = "deletePost"         // delete post
-	AuditEventLocalDeletePost    = "localDeletePost"    // delete post locally
-	AuditEventMoveThread         = "moveThread"         // move thread and replies to different channel
-	AuditEventPatchPost          = "patchPost"          // update post meta properties
-	AuditEventRestorePostVersion = "restorePostVersion" // restore post to previous version
-	AuditEventSaveIsPinnedPost   = "saveIsPinnedPost"   // pin or unpin post
-	AuditEventSearchPosts        = "searchPosts"        // search for posts
-	AuditEventUpdatePost         = "updatePost"         // update post content
+	AuditEventClosePoll           = "closePoll"           // close poll to new votes
+	AuditEventCreatePoll          = "createPoll"          // create poll post
+	AuditEventCreatePost          = "createPost"          // create post
+	AuditEventDeletePost          = "deletePost"          // delete post
+	AuditEventLocalDeletePost     = "localDeletePost"     // delete post locally
+	AuditEventMoveThread          = "moveThread"          // move thread and replies to different channel
+	AuditEventPatchPost           = "patchPost"           // update post meta properties
+	AuditEventReceiveInboundEmail = "receiveInboundEmail" // create thread reply from inbound email
+	AuditEventRestorePostVersion  = "restorePostVersion"  // restore post to previous version
+	AuditEventSaveIsPinnedPost    = "saveIsPinnedPost"    // pin or unpin post
+	AuditEventSearchPosts         = "searchPosts"         // search for posts
+	AuditEventUpdatePost          = "updatePost"          // update post content
 )
 
 // Preferences
@@ -378,25 +381,32 @@ const (
 // Users
 const (
 	AuditEventAttachDeviceId               = "attachDeviceId"               // attach device ID to user session for mobile app
+	AuditEventCreateSavedSearch            = "createSavedSearch"            // create saved search for user
 	AuditEventCreateUser                   = "createUser"                   // create user account
 	AuditEventCreateUserAccessToken        = "createUserAccessToken"        // create personal access token for user API access
+	AuditEventDeleteSavedSearch            = "deleteSavedSearch"            // delete saved search of user
 	AuditEventDeleteUser                   = "deleteUser"                   // delete user account
+	AuditEventDeleteWebAuthnCredential     = "deleteWebAuthnCredential"     // delete user WebAuthn credential
 	AuditEventDemoteUserToGuest            = "demoteUserToGuest"            // demote regular user to guest account with limited permissions
 	AuditEventDisableUserAccessToken       = "disableUserAccessToken"       // disable user personal access token
 	AuditEventEnableUserAccessToken        = "enableUserAccessToken"        // enable user personal access token
 	AuditEventExtendSessionExpiry          = "extendSessionExpiry"          // extend user session expiration time
+	AuditEventGenerateMfaRecoveryCodes     = "generateMfaRecoveryCodes"     // generate new multi-factor authentication recovery codes for user
 	AuditEventLocalDeleteUser              = "localDeleteUser"              // delete user locally
 	AuditEventLocalPermanentDeleteAllUsers = "localPermanentDeleteAllUsers" // permanently delete all users locally
 	AuditEventLogin                        = "login"                        // user login to system
 	AuditEventLogout                       = "logout"                       // user logout from system
 	AuditEventMigrateAuthToLdap            = "migrateAuthToLdap"            // migrate user authentication method to LDAP
 	AuditEventMigrateAuthToSaml            = "migrateAuthToSaml"            // migrate user authentication method to SAML
+	AuditEventPatchSavedSearch             = "patchSavedSearch"             // update saved search name, terms or watch mode
 	AuditEventPatchUser                    = "patchUser"                    // update user properties
 	AuditEventPromoteGuestToUser           = "promoteGuestToUser"           // promote guest account to regular user
+	AuditEventRegisterWebAuthnCredential   = "registerWebAuthnCredential"   // register WebAuthn credential as second factor
 	AuditEventResetPassword                = "resetPassword"                // reset user password
 	AuditEventResetPasswordFailedAttempts  = "resetPasswordFailedAttempts"  // reset failed password attempt counter
 	AuditEventRevokeAllSessionsAllUsers    = "revokeAllSessionsAllUsers"    // revoke all active sessions for all users
 	AuditEventRevokeAllSessionsForUser     = "revokeAllSessionsForUser"     // revoke all active sessions for specific user
+	AuditEventRevokeMfaRecoveryCodes       = "revokeMfaRecoveryCodes"       // revoke the multi-factor authentication recovery codes of user
 	AuditEventRevokeSession                = "revokeSession"                // revoke specific user session
 	AuditEventRevokeUserAccessToken        = "revokeUserAccessToken"        // revoke user personal access token
 	AuditEventSendPasswordReset            = "sendPasswordReset"            // send password reset email to user
@@ -410,22 +420,25 @@ const (
 	AuditEventUpdateUserAuth               = "updateUserAuth"               // update user authentication method
 	AuditEventUpdateUserMfa                = "updateUserMfa"                // update user multi-factor authentication settings
 	AuditEventUpdateUserRoles              = "updateUserRoles"              // update user roles
+	AuditEventUseMfaRecoveryCode           = "useMfaRecoveryCode"           // use multi-factor authentication recovery code in place of a token
 	AuditEventVerifyUserEmail              = "verifyUserEmail"              // verify user email address using verification token
 	AuditEventVerifyUserEmailWithoutToken  = "verifyUserEmailWithoutToken"  // verify user email address without verification token
 )
 
 // Webhooks
 const (
-	AuditEventCreateIncomingHook      = "createIncomingHook"      // create incoming webhook
-	AuditEventCreateOutgoingHook      = "createOutgoingHook"      // create outgoing webhook
-	AuditEventDeleteIncomingHook      = "deleteIncomingHook"      // delete incoming webhook
-	AuditEventDeleteOutgoingHook      = "deleteOutgoingHook"      // delete outgoing webhook
-	AuditEventGetIncomingHook         = "getIncomingHook"         // get incoming webhook details
-	AuditEventGetOutgoingHook         = "getOutgoingHook"         // get outgoing webhook details
-	AuditEventLocalCreateIncomingHook = "localCreateIncomingHook" // create incoming webhook locally
-	AuditEventRegenOutgoingHookToken  = "regenOutgoingHookToken"  // regenerate authentication token
-	AuditEventUpdateIncomingHook      = "updateIncomingHook"      // update incoming webhook
-	AuditEventUpdateOutgoingHook      = "updateOutgoingHook"      // update outgoing webhook
+	AuditEventCreateIncomingHook             = "createIncomingHook"             // create incoming webhook
+	AuditEventCreateOutgoingHook             = "createOutgoingHook"             // create outgoing webhook
+	AuditEventDeleteIncomingHook             = "deleteIncomingHook"             // delete incoming webhook
+	AuditEventDeleteOutgoingHook             = "deleteOutgoingHook"             // delete outgoing webhook
+	AuditEventGetIncomingHook                = "getIncomingHook"                // get incoming webhook details
+	AuditEventGetOutgoingHook                = "getOutgoingHook"                // get outgoing webhook details
+	AuditEventLocalCreateIncomingHook        = "localCreateIncomingHook"        // create incoming webhook locally
+	AuditEventRedeliverOutgoingHookDelivery  = "redeliverOutgoingHookDelivery"  // retry failed outgoing webhook delivery
+	AuditEventRegenOutgoingHookSigningSecret = "regenOutgoingHookSigningSecret" // regenerate request signing secret
+	AuditEventRegenOutgoingHookToken         = "regenOutgoingHookToken"         // regenerate authentication token
+	AuditEventUpdateIncomingHook             = "updateIncomingHook"             // update incoming webhook
+	AuditEventUpdateOutgoingHook             = "updateOutgoingHook"             // update outgoing webhook
 )
 
 // Content Flagging
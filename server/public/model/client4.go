@@ -363,6 +363,10 @@ func (c *Client4) testEmailRoute() string {
 	return "/email/test"
 }
 
+func (c *Client4) inboundEmailRoute() string {
+	return "/email/inbound"
+}
+
 func (c *Client4) testNotificationRoute() string {
 	return "/notifications/test"
 }
@@ -487,6 +491,14 @@ func (c *Client4) reactionsRoute() string {
 	return "/reactions"
 }
 
+func (c *Client4) pollsRoute() string {
+	return "/polls"
+}
+
+func (c *Client4) pollRoute(postId string) string {
+	return c.postRoute(postId) + "/poll"
+}
+
 func (c *Client4) oAuthAppsRoute() string {
 	return "/oauth/apps"
 }
@@ -841,6 +853,27 @@ func (c *Client4) LoginWithMFA(ctx context.Context, loginId, password, mfaToken
 	return c.login(ctx, m)
 }
 
+// LoginWithWebAuthn authenticates a user by login id, password and a WebAuthn assertion
+// produced for a challenge returned by GetWebAuthnLoginOptions.
+func (c *Client4) LoginWithWebAuthn(ctx context.Context, loginId, password string, assertion *WebAuthnAssertionResponse) (*User, *Response, error) {
+	token, err := json.Marshal(assertion)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.LoginWithMFA(ctx, loginId, password, string(token))
+}
+
+// GetWebAuthnLoginOptions returns the options to pass to navigator.credentials.get() when
+// logging in with a WebAuthn credential.
+func (c *Client4) GetWebAuthnLoginOptions(ctx context.Context, loginId string) (*WebAuthnRequestOptions, *Response, error) {
+	r, err := c.DoAPIPostJSON(ctx, c.usersRoute()+"/login/webauthn", map[string]string{"login_id": loginId})
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*WebAuthnRequestOptions](r)
+}
+
 func (c *Client4) login(ctx context.Context, m map[string]string) (*User, *Response, error) {
 	r, err := c.DoAPIPostJSON(ctx, "/users/login", m)
 	if err != nil {
@@ -1348,6 +1381,41 @@ func (c *Client4) UpdateUserMfa(ctx context.Context, userId, code string, activa
 	return BuildResponse(r), nil
 }
 
+// ActivateUserMfa activates multi-factor authentication for a user using a code from the
+// authenticator app, and returns the recovery codes generated for the user.
+func (c *Client4) ActivateUserMfa(ctx context.Context, userId, code string) (*MfaRecoveryCodes, *Response, error) {
+	requestBody := map[string]any{"activate": true, "code": code}
+	r, err := c.DoAPIPutJSON(ctx, c.userRoute(userId)+"/mfa", requestBody)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*MfaRecoveryCodes](r)
+}
+
+// GenerateMfaRecoveryCodes replaces the multi-factor authentication recovery codes of the
+// current user and returns the new codes. The user re-authenticates with either their
+// password or a current MFA token.
+func (c *Client4) GenerateMfaRecoveryCodes(ctx context.Context, userId, password, code string) (*MfaRecoveryCodes, *Response, error) {
+	requestBody := map[string]string{"password": password, "code": code}
+	r, err := c.DoAPIPostJSON(ctx, c.userRoute(userId)+"/mfa/recovery_codes", requestBody)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*MfaRecoveryCodes](r)
+}
+
+// RevokeMfaRecoveryCodes removes the multi-factor authentication recovery codes of a user.
+func (c *Client4) RevokeMfaRecoveryCodes(ctx context.Context, userId string) (*Response, error) {
+	r, err := c.DoAPIDelete(ctx, c.userRoute(userId)+"/mfa/recovery_codes")
+	if err != nil {
+		return BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return BuildResponse(r), nil
+}
+
 // GenerateMfaSecret will generate a new MFA secret for a user and return it as a string and
 // as a base64 encoded image QR code.
 func (c *Client4) GenerateMfaSecret(ctx context.Context, userId string) (*MfaSecret, *Response, error) {
@@ -1359,6 +1427,101 @@ func (c *Client4) GenerateMfaSecret(ctx context.Context, userId string) (*MfaSec
 	return DecodeJSONFromResponse[*MfaSecret](r)
 }
 
+// StartWebAuthnRegistration returns the options to pass to navigator.credentials.create()
+// to register a new WebAuthn credential for the user.
+func (c *Client4) StartWebAuthnRegistration(ctx context.Context, userId string) (*WebAuthnCreationOptions, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.userRoute(userId)+"/webauthn/register/start", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*WebAuthnCreationOptions](r)
+}
+
+// FinishWebAuthnRegistration completes the registration of a WebAuthn credential for the user.
+func (c *Client4) FinishWebAuthnRegistration(ctx context.Context, userId string, response *WebAuthnAttestationResponse) (*WebAuthnCredential, *Response, error) {
+	r, err := c.DoAPIPostJSON(ctx, c.userRoute(userId)+"/webauthn/register/finish", response)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*WebAuthnCredential](r)
+}
+
+// GetWebAuthnCredentials returns the WebAuthn credentials registered by the user.
+func (c *Client4) GetWebAuthnCredentials(ctx context.Context, userId string) ([]*WebAuthnCredential, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.userRoute(userId)+"/webauthn/credentials", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*WebAuthnCredential](r)
+}
+
+// DeleteWebAuthnCredential removes one of the user's WebAuthn credentials.
+func (c *Client4) DeleteWebAuthnCredential(ctx context.Context, userId, credentialId string) (*Response, error) {
+	r, err := c.DoAPIDelete(ctx, c.userRoute(userId)+"/webauthn/credentials/"+credentialId)
+	if err != nil {
+		return BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return BuildResponse(r), nil
+}
+
+func (c *Client4) savedSearchesRoute(userId string) string {
+	return c.userRoute(userId) + "/saved_searches"
+}
+
+// CreateSavedSearch saves a search for the user.
+func (c *Client4) CreateSavedSearch(ctx context.Context, userId string, savedSearch *SavedSearch) (*SavedSearch, *Response, error) {
+	r, err := c.DoAPIPostJSON(ctx, c.savedSearchesRoute(userId), savedSearch)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*SavedSearch](r)
+}
+
+// GetSavedSearches returns the searches saved by the user.
+func (c *Client4) GetSavedSearches(ctx context.Context, userId string) ([]*SavedSearch, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.savedSearchesRoute(userId), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*SavedSearch](r)
+}
+
+// GetSavedSearch returns one of the searches saved by the user.
+func (c *Client4) GetSavedSearch(ctx context.Context, userId, savedSearchId string) (*SavedSearch, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.savedSearchesRoute(userId)+"/"+savedSearchId, "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*SavedSearch](r)
+}
+
+// PatchSavedSearch partially updates one of the searches saved by the user.
+func (c *Client4) PatchSavedSearch(ctx context.Context, userId, savedSearchId string, patch *SavedSearchPatch) (*SavedSearch, *Response, error) {
+	r, err := c.DoAPIPutJSON(ctx, c.savedSearchesRoute(userId)+"/"+savedSearchId+"/patch", patch)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*SavedSearch](r)
+}
+
+// DeleteSavedSearch deletes one of the searches saved by the user.
+func (c *Client4) DeleteSavedSearch(ctx context.Context, userId, savedSearchId string) (*Response, error) {
+	r, err := c.DoAPIDelete(ctx, c.savedSearchesRoute(userId)+"/"+savedSearchId)
+	if err != nil {
+		return BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return BuildResponse(r), nil
+}
+
 // UpdateUserPassword updates a user's password. Must be logged in as the user or be a system administrator.
 func (c *Client4) UpdateUserPassword(ctx context.Context, userId, currentPassword, newPassword string) (*Response, error) {
 	requestBody := map[string]string{"current_password": currentPassword, "new_password": newPassword}
@@ -3658,6 +3821,24 @@ func (c *Client4) SearchPostsWithMatches(ctx context.Context, teamId string, ter
 	return DecodeJSONFromResponse[*PostSearchResults](r)
 }
 
+// SearchPostsHybrid returns the posts matching the search terms, ranked by merging the
+// semantic similarity of the posts with the full text search results. An empty teamId
+// searches all the teams of the user.
+func (c *Client4) SearchPostsHybrid(ctx context.Context, teamId string, params *SearchParameter) (*PostSearchResults, *Response, error) {
+	var route string
+	if teamId == "" {
+		route = c.postsRoute() + "/search/hybrid"
+	} else {
+		route = c.teamRoute(teamId) + "/posts/search/hybrid"
+	}
+	r, err := c.DoAPIPostJSON(ctx, route, params)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*PostSearchResults](r)
+}
+
 // DoPostAction performs a post action.
 func (c *Client4) DoPostAction(ctx context.Context, postId, actionId string) (*Response, error) {
 	r, err := c.DoAPIPost(ctx, c.postRoute(postId)+"/actions/"+actionId, "")
@@ -3879,6 +4060,19 @@ func (c *Client4) GetFileInfosForPostIncludeDeleted(ctx context.Context, postId
 	return DecodeJSONFromResponse[[]*FileInfo](r)
 }
 
+// GetQuarantinedFileInfos gets a page of the infected files kept in quarantine, most recent first.
+func (c *Client4) GetQuarantinedFileInfos(ctx context.Context, page, perPage int) ([]*FileInfo, *Response, error) {
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("per_page", strconv.Itoa(perPage))
+	r, err := c.DoAPIGet(ctx, c.systemRoute()+"/quarantine/files?"+values.Encode(), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*FileInfo](r)
+}
+
 // General/System Section
 
 // GenerateSupportPacket generates and downloads a Support Packet.
@@ -3968,6 +4162,21 @@ func (c *Client4) TestEmail(ctx context.Context, config *Config) (*Response, err
 	return BuildResponse(r), nil
 }
 
+// ReceiveInboundEmail hands over a raw email message, replying to a notification email,
+// to be posted to the thread it was addressed to. No post is returned when the message
+// was sent automatically and got ignored.
+func (c *Client4) ReceiveInboundEmail(ctx context.Context, message []byte) (*Post, *Response, error) {
+	r, err := c.doAPIRequestReader(ctx, http.MethodPost, c.APIURL+c.inboundEmailRoute(), "message/rfc822", bytes.NewReader(message), nil)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	if r.StatusCode != http.StatusCreated {
+		return nil, BuildResponse(r), nil
+	}
+	return DecodeJSONFromResponse[*Post](r)
+}
+
 func (c *Client4) TestNotifications(ctx context.Context) (*Response, error) {
 	r, err := c.DoAPIPost(ctx, c.testNotificationRoute(), "")
 	if err != nil {
@@ -4348,6 +4557,53 @@ func (c *Client4) RegenOutgoingHookToken(ctx context.Context, hookId string) (*O
 	return DecodeJSONFromResponse[*OutgoingWebhook](r)
 }
 
+// RegenOutgoingHookSigningSecret regenerates the secret used to sign outgoing webhook requests.
+func (c *Client4) RegenOutgoingHookSigningSecret(ctx context.Context, hookId string) (*OutgoingWebhook, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.outgoingWebhookRoute(hookId)+"/regen_signing_secret", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*OutgoingWebhook](r)
+}
+
+// GetOutgoingWebhookDeliveries returns a page of the queued deliveries of an outgoing webhook,
+// optionally filtered by status. Page counting starts at 0.
+func (c *Client4) GetOutgoingWebhookDeliveries(ctx context.Context, hookId string, status string, page int, perPage int) ([]*OutgoingWebhookDelivery, *Response, error) {
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("per_page", strconv.Itoa(perPage))
+	if status != "" {
+		values.Set("status", status)
+	}
+	r, err := c.DoAPIGet(ctx, c.outgoingWebhookRoute(hookId)+"/deliveries?"+values.Encode(), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*OutgoingWebhookDelivery](r)
+}
+
+// GetOutgoingWebhookDelivery returns a queued delivery of an outgoing webhook.
+func (c *Client4) GetOutgoingWebhookDelivery(ctx context.Context, hookId, deliveryId string) (*OutgoingWebhookDelivery, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.outgoingWebhookRoute(hookId)+"/deliveries/"+deliveryId, "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*OutgoingWebhookDelivery](r)
+}
+
+// RedeliverOutgoingWebhookDelivery immediately retries a queued delivery of an outgoing webhook.
+func (c *Client4) RedeliverOutgoingWebhookDelivery(ctx context.Context, hookId, deliveryId string) (*OutgoingWebhookDelivery, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.outgoingWebhookRoute(hookId)+"/deliveries/"+deliveryId+"/redeliver", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*OutgoingWebhookDelivery](r)
+}
+
 // DeleteOutgoingWebhook delete the outgoing webhook on the system requested by Hook Id.
 func (c *Client4) DeleteOutgoingWebhook(ctx context.Context, hookId string) (*Response, error) {
 	r, err := c.DoAPIDelete(ctx, c.outgoingWebhookRoute(hookId))
@@ -5831,6 +6087,58 @@ func (c *Client4) GetBulkReactions(ctx context.Context, postIds []string) (map[s
 	return DecodeJSONFromResponse[map[string][]*Reaction](r)
 }
 
+// Poll Section
+
+// CreatePoll posts a poll to a channel. Returns the created poll, keyed by the ID of its post.
+func (c *Client4) CreatePoll(ctx context.Context, pollRequest *PollRequest) (*Poll, *Response, error) {
+	r, err := c.DoAPIPostJSON(ctx, c.pollsRoute(), pollRequest)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*Poll](r)
+}
+
+// GetPoll returns the poll of a post with its tally.
+func (c *Client4) GetPoll(ctx context.Context, postId string) (*Poll, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.pollRoute(postId), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*Poll](r)
+}
+
+// VotePoll votes for options of the poll of a post, replacing the previous votes of the user.
+func (c *Client4) VotePoll(ctx context.Context, postId string, optionIds []string) (*Poll, *Response, error) {
+	r, err := c.DoAPIPutJSON(ctx, c.pollRoute(postId)+"/votes", &PollVoteRequest{OptionIds: optionIds})
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*Poll](r)
+}
+
+// RetractPollVotes removes the votes of the user for the poll of a post.
+func (c *Client4) RetractPollVotes(ctx context.Context, postId string) (*Poll, *Response, error) {
+	r, err := c.DoAPIDelete(ctx, c.pollRoute(postId)+"/votes")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*Poll](r)
+}
+
+// ClosePoll closes the poll of a post to new votes.
+func (c *Client4) ClosePoll(ctx context.Context, postId string) (*Poll, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.pollRoute(postId)+"/close", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*Poll](r)
+}
+
 // Timezone Section
 
 // GetSupportedTimezone returns a page of supported timezones on the system.
@@ -7377,6 +7685,52 @@ func (c *Client4) GetTeamsUsage(ctx context.Context) (*TeamsUsage, *Response, er
 	return DecodeJSONFromResponse[*TeamsUsage](r)
 }
 
+// GetUserFileStorageUsage returns the storage used by the files uploaded by a user, along with the quota.
+func (c *Client4) GetUserFileStorageUsage(ctx context.Context, userId string) (*FileStorageUsage, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.usageRoute()+"/storage/users/"+userId, "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*FileStorageUsage](r)
+}
+
+// GetTeamFileStorageUsage returns the storage used by the files uploaded into a team, along with the quota.
+func (c *Client4) GetTeamFileStorageUsage(ctx context.Context, teamId string) (*FileStorageUsage, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.usageRoute()+"/storage/teams/"+teamId, "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[*FileStorageUsage](r)
+}
+
+// GetUsersFileStorageUsage returns a page of the users using the most file storage.
+func (c *Client4) GetUsersFileStorageUsage(ctx context.Context, page, perPage int) ([]*FileStorageUsage, *Response, error) {
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("per_page", strconv.Itoa(perPage))
+	r, err := c.DoAPIGet(ctx, c.usageRoute()+"/storage/users?"+values.Encode(), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*FileStorageUsage](r)
+}
+
+// GetTeamsFileStorageUsage returns a page of the teams using the most file storage.
+func (c *Client4) GetTeamsFileStorageUsage(ctx context.Context, page, perPage int) ([]*FileStorageUsage, *Response, error) {
+	values := url.Values{}
+	values.Set("page", strconv.Itoa(page))
+	values.Set("per_page", strconv.Itoa(perPage))
+	r, err := c.DoAPIGet(ctx, c.usageRoute()+"/storage/teams?"+values.Encode(), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return DecodeJSONFromResponse[[]*FileStorageUsage](r)
+}
+
 func (c *Client4) GetPostInfo(ctx context.Context, postId string) (*PostInfo, *Response, error) {
 	r, err := c.DoAPIGet(ctx, c.postRoute(postId)+"/info", "")
 	if err != nil {
@@ -5,6 +5,7 @@ package model
 
 import (
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"math"
@@ -19,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/mattermost/ldap"
 	"github.com/pkg/errors"
@@ -36,8 +38,19 @@ const (
 	ImageDriverLocal = "local"
 	ImageDriverS3    = "amazons3"
 
+	MalwareScanDriverClamd = "clamd"
+	MalwareScanDriverICAP  = "icap"
+
+	MalwareScanActionReject     = "reject"
+	MalwareScanActionQuarantine = "quarantine"
+
 	DatabaseDriverPostgres = "postgres"
 
+	DatabaseSearchModeFullText = "fulltext"
+	// DatabaseSearchModeNgram searches the database through an index of the words and of the
+	// character n-grams of Chinese, Japanese and Korean texts, which full text search can't split.
+	DatabaseSearchModeNgram = "ngram"
+
 	SearchengineElasticsearch = "elasticsearch"
 
 	MinioAccessKey = "minioaccesskey"
@@ -47,6 +60,16 @@ const (
 	PasswordMaximumLength = 72
 	PasswordMinimumLength = 5
 
+	PasswordHashingAlgorithmPBKDF2   = "pbkdf2"
+	PasswordHashingAlgorithmArgon2id = "argon2id"
+
+	PasswordSettingsDefaultArgon2idMemoryKiB   = 19 * 1024
+	PasswordSettingsDefaultArgon2idIterations  = 2
+	PasswordSettingsDefaultArgon2idParallelism = 1
+	PasswordSettingsMaxArgon2idMemoryKiB       = 1024 * 1024
+	PasswordSettingsMaxArgon2idIterations      = 64
+	PasswordSettingsMaxArgon2idParallelism     = 64
+
 	ServiceGitlab = "gitlab"
 
 	ServiceGoogle    = "google"
@@ -97,6 +120,9 @@ const (
 	EmailBatchingBufferSize = 256
 	EmailBatchingInterval   = 30
 
+	// EmailDigestHour is the default hour of the day, in each user's timezone, digests are sent at
+	EmailDigestHour = 8
+
 	EmailNotificationContentsFull    = "full"
 	EmailNotificationContentsGeneric = "generic"
 
@@ -105,6 +131,8 @@ const (
 
 	CacheTypeLRU   = "lru"
 	CacheTypeRedis = "redis"
+	// CacheTypeHybrid keeps a local LRU tier in front of Redis.
+	CacheTypeHybrid = "hybrid"
 
 	SitenameMaxLength = 30
 
@@ -224,6 +252,22 @@ const (
 	ElasticsearchSettingsESBackend                          = "elasticsearch"
 	ElasticsearchSettingsOSBackend                          = "opensearch"
 
+	EmbeddedSearchSettingsDefaultIndexDir  = "./searchindex/"
+	EmbeddedSearchSettingsDefaultBatchSize = 10000
+
+	SemanticSearchEmbeddingProviderOpenAI = "openai"
+	// SemanticSearchEmbeddingProviderLocal computes embeddings from hashed words, without any
+	// language model. It is deterministic and meant for tests and development.
+	SemanticSearchEmbeddingProviderLocal = "local"
+
+	SemanticSearchSettingsDefaultEmbeddingModel      = "text-embedding-3-small"
+	SemanticSearchSettingsDefaultEmbeddingDimensions = 1536
+	// SemanticSearchSettingsMaxEmbeddingDimensions is the most dimensions pgvector indexes.
+	SemanticSearchSettingsMaxEmbeddingDimensions     = 2000
+	SemanticSearchSettingsDefaultRequestTimeoutMs    = 30000
+	SemanticSearchSettingsDefaultBatchSize           = 100
+	SemanticSearchSettingsDefaultMaxVectorCandidates = 200
+
 	DataRetentionSettingsDefaultMessageRetentionDays           = 365
 	DataRetentionSettingsDefaultMessageRetentionHours          = 0
 	DataRetentionSettingsDefaultFileRetentionDays              = 365
@@ -367,6 +411,8 @@ type ServiceSettings struct {
 	AllowedUntrustedInternalConnections *string  `access:"environment_web_server,write_restrictable,cloud_restrictable"`
 	EnableMultifactorAuthentication     *bool    `access:"authentication_mfa"`
 	EnforceMultifactorAuthentication    *bool    `access:"authentication_mfa"`
+	EnableWebAuthn                      *bool    `access:"authentication_mfa"`
+	EnforceWebAuthn                     *bool    `access:"authentication_mfa"`
 	EnableUserAccessTokens              *bool    `access:"integrations_integration_management"`
 	AllowCorsFrom                       *string  `access:"integrations_cors,write_restrictable,cloud_restrictable"`
 	CorsExposedHeaders                  *string  `access:"integrations_cors,write_restrictable,cloud_restrictable"`
@@ -446,6 +492,7 @@ type ServiceSettings struct {
 	MaximumPayloadSizeBytes                           *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 	MaximumURLLength                                  *int    `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 	ScheduledPosts                                    *bool   `access:"site_posts"`
+	EnableSavedSearchWatch                            *bool   `access:"site_posts"`
 	EnableWebHubChannelIteration                      *bool   `access:"write_restrictable,cloud_restrictable"` // telemetry: none
 	FrameAncestors                                    *string `access:"write_restrictable,cloud_restrictable"` // telemetry: none
 	DeleteAccountLink                                 *string `access:"site_users_and_teams,write_restrictable,cloud_restrictable"`
@@ -531,6 +578,14 @@ func (s *ServiceSettings) SetDefaults(isUpdate bool) {
 		s.EnforceMultifactorAuthentication = NewPointer(false)
 	}
 
+	if s.EnableWebAuthn == nil {
+		s.EnableWebAuthn = NewPointer(false)
+	}
+
+	if s.EnforceWebAuthn == nil {
+		s.EnforceWebAuthn = NewPointer(false)
+	}
+
 	if s.EnableUserAccessTokens == nil {
 		s.EnableUserAccessTokens = NewPointer(false)
 	}
@@ -977,6 +1032,10 @@ func (s *ServiceSettings) SetDefaults(isUpdate bool) {
 		s.ScheduledPosts = NewPointer(true)
 	}
 
+	if s.EnableSavedSearchWatch == nil {
+		s.EnableSavedSearchWatch = NewPointer(false)
+	}
+
 	if s.EnableWebHubChannelIteration == nil {
 		s.EnableWebHubChannelIteration = NewPointer(false)
 	}
@@ -995,12 +1054,13 @@ func (s *ServiceSettings) SetDefaults(isUpdate bool) {
 }
 
 type CacheSettings struct {
-	CacheType          *string `access:",write_restrictable,cloud_restrictable"`
-	RedisAddress       *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
-	RedisPassword      *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
-	RedisDB            *int    `access:",write_restrictable,cloud_restrictable"` // telemetry: none
-	RedisCachePrefix   *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
-	DisableClientCache *bool   `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	CacheType                  *string `access:",write_restrictable,cloud_restrictable"`
+	RedisAddress               *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	RedisPassword              *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	RedisDB                    *int    `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	RedisCachePrefix           *string `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	DisableClientCache         *bool   `access:",write_restrictable,cloud_restrictable"` // telemetry: none
+	HybridLocalCachePercentage *int    `access:",write_restrictable,cloud_restrictable"`
 }
 
 func (s *CacheSettings) SetDefaults() {
@@ -1027,21 +1087,30 @@ func (s *CacheSettings) SetDefaults() {
 	if s.DisableClientCache == nil {
 		s.DisableClientCache = NewPointer(false)
 	}
+
+	if s.HybridLocalCachePercentage == nil {
+		s.HybridLocalCachePercentage = NewPointer(10)
+	}
 }
 
 func (s *CacheSettings) isValid() *AppError {
-	if *s.CacheType != CacheTypeLRU && *s.CacheType != CacheTypeRedis {
+	if *s.CacheType != CacheTypeLRU && *s.CacheType != CacheTypeRedis && *s.CacheType != CacheTypeHybrid {
 		return NewAppError("Config.IsValid", "model.config.is_valid.cache_type.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if *s.CacheType == CacheTypeRedis && *s.RedisAddress == "" {
+	usesRedis := *s.CacheType == CacheTypeRedis || *s.CacheType == CacheTypeHybrid
+	if usesRedis && *s.RedisAddress == "" {
 		return NewAppError("Config.IsValid", "model.config.is_valid.empty_redis_address.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if *s.CacheType == CacheTypeRedis && *s.RedisDB < 0 {
+	if usesRedis && *s.RedisDB < 0 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.invalid_redis_db.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if *s.HybridLocalCachePercentage < 1 || *s.HybridLocalCachePercentage > 100 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.hybrid_local_cache_percentage.app_error", map[string]any{"Value": *s.HybridLocalCachePercentage}, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -1372,6 +1441,7 @@ type SqlSettings struct {
 	AtRestEncryptKey                  *string               `access:"environment_database,write_restrictable,cloud_restrictable"` // telemetry: none
 	QueryTimeout                      *int                  `access:"environment_database,write_restrictable,cloud_restrictable"`
 	DisableDatabaseSearch             *bool                 `access:"environment_database,write_restrictable,cloud_restrictable"`
+	DatabaseSearchMode                *string               `access:"environment_database,write_restrictable,cloud_restrictable"`
 	MigrationsStatementTimeoutSeconds *int                  `access:"environment_database,write_restrictable,cloud_restrictable"`
 	ReplicaLagSettings                []*ReplicaLagSettings `access:"environment_database,write_restrictable,cloud_restrictable"` // telemetry: none
 	ReplicaMonitorIntervalSeconds     *int                  `access:"environment_database,write_restrictable,cloud_restrictable"`
@@ -1432,6 +1502,10 @@ func (s *SqlSettings) SetDefaults(isUpdate bool) {
 		s.DisableDatabaseSearch = NewPointer(false)
 	}
 
+	if s.DatabaseSearchMode == nil {
+		s.DatabaseSearchMode = NewPointer(DatabaseSearchModeFullText)
+	}
+
 	if s.MigrationsStatementTimeoutSeconds == nil {
 		s.MigrationsStatementTimeoutSeconds = NewPointer(100000)
 	}
@@ -1652,6 +1726,11 @@ type PasswordSettings struct {
 	Uppercase        *bool `access:"authentication_password"`
 	Symbol           *bool `access:"authentication_password"`
 	EnableForgotLink *bool `access:"authentication_password"`
+
+	HashingAlgorithm    *string `access:"authentication_password"`
+	Argon2idMemoryKiB   *int    `access:"authentication_password"`
+	Argon2idIterations  *int    `access:"authentication_password"`
+	Argon2idParallelism *int    `access:"authentication_password"`
 }
 
 func (s *PasswordSettings) SetDefaults() {
@@ -1678,6 +1757,47 @@ func (s *PasswordSettings) SetDefaults() {
 	if s.EnableForgotLink == nil {
 		s.EnableForgotLink = NewPointer(true)
 	}
+
+	if s.HashingAlgorithm == nil {
+		s.HashingAlgorithm = NewPointer(PasswordHashingAlgorithmPBKDF2)
+	}
+
+	if s.Argon2idMemoryKiB == nil {
+		s.Argon2idMemoryKiB = NewPointer(PasswordSettingsDefaultArgon2idMemoryKiB)
+	}
+
+	if s.Argon2idIterations == nil {
+		s.Argon2idIterations = NewPointer(PasswordSettingsDefaultArgon2idIterations)
+	}
+
+	if s.Argon2idParallelism == nil {
+		s.Argon2idParallelism = NewPointer(PasswordSettingsDefaultArgon2idParallelism)
+	}
+}
+
+func (s *PasswordSettings) isValid() *AppError {
+	if *s.MinimumLength < PasswordMinimumLength || *s.MinimumLength > PasswordMaximumLength {
+		return NewAppError("Config.IsValid", "model.config.is_valid.password_length.app_error", map[string]any{"MinLength": PasswordMinimumLength, "MaxLength": PasswordMaximumLength}, "", http.StatusBadRequest)
+	}
+
+	if *s.HashingAlgorithm != PasswordHashingAlgorithmPBKDF2 && *s.HashingAlgorithm != PasswordHashingAlgorithmArgon2id {
+		return NewAppError("Config.IsValid", "model.config.is_valid.password_hashing_algorithm.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.Argon2idParallelism < 1 || *s.Argon2idParallelism > PasswordSettingsMaxArgon2idParallelism {
+		return NewAppError("Config.IsValid", "model.config.is_valid.password_argon2id_parallelism.app_error", map[string]any{"Max": PasswordSettingsMaxArgon2idParallelism}, "", http.StatusBadRequest)
+	}
+
+	if *s.Argon2idIterations < 1 || *s.Argon2idIterations > PasswordSettingsMaxArgon2idIterations {
+		return NewAppError("Config.IsValid", "model.config.is_valid.password_argon2id_iterations.app_error", map[string]any{"Max": PasswordSettingsMaxArgon2idIterations}, "", http.StatusBadRequest)
+	}
+
+	// Argon2 requires at least 8 KiB of memory per thread
+	if minMemory := 8 * *s.Argon2idParallelism; *s.Argon2idMemoryKiB < minMemory || *s.Argon2idMemoryKiB > PasswordSettingsMaxArgon2idMemoryKiB {
+		return NewAppError("Config.IsValid", "model.config.is_valid.password_argon2id_memory.app_error", map[string]any{"Min": minMemory, "Max": PasswordSettingsMaxArgon2idMemoryKiB}, "", http.StatusBadRequest)
+	}
+
+	return nil
 }
 
 type FileSettings struct {
@@ -1687,11 +1807,15 @@ type FileSettings struct {
 	MaxFileSize                        *int64  `access:"environment_file_storage,cloud_restrictable"`
 	MaxImageResolution                 *int64  `access:"environment_file_storage,cloud_restrictable"`
 	MaxImageDecoderConcurrency         *int64  `access:"environment_file_storage,cloud_restrictable"`
+	UserStorageQuotaBytes              *int64  `access:"environment_file_storage,cloud_restrictable"`
+	TeamStorageQuotaBytes              *int64  `access:"environment_file_storage,cloud_restrictable"`
 	DriverName                         *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 	Directory                          *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 	EnablePublicLink                   *bool   `access:"site_public_links,cloud_restrictable"`
 	ExtractContent                     *bool   `access:"environment_file_storage,write_restrictable"`
 	ArchiveRecursion                   *bool   `access:"environment_file_storage,write_restrictable"`
+	ExtractContentMaxFileSizeBytes     *int64  `access:"environment_file_storage,write_restrictable"`
+	ExtractContentTimeoutMilliseconds  *int64  `access:"environment_file_storage,write_restrictable"`
 	PublicLinkSalt                     *string `access:"site_public_links,cloud_restrictable"`                           // telemetry: none
 	InitialFont                        *string `access:"environment_file_storage,cloud_restrictable"`                    // telemetry: none
 	AmazonS3AccessKeyId                *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
@@ -1707,6 +1831,16 @@ type FileSettings struct {
 	AmazonS3RequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
 	AmazonS3UploadPartSizeBytes        *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
 	AmazonS3StorageClass               *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EnableEncryptionAtRest             *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EncryptionMasterKey                *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EncryptionMasterKeyFile            *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EnableContentDeduplication         *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MalwareScanDriver                  *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MalwareScanAddress                 *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MalwareScanTimeoutMilliseconds     *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MalwareScanAction                  *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	EnableWebPPreviews                 *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	StripImageMetadata                 *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 	// Export store settings
 	DedicatedExportStore                     *bool   `access:"environment_file_storage,write_restrictable"`
 	ExportDriverName                         *string `access:"environment_file_storage,write_restrictable"`
@@ -1725,6 +1859,23 @@ type FileSettings struct {
 	ExportAmazonS3PresignExpiresSeconds      *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
 	ExportAmazonS3UploadPartSizeBytes        *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
 	ExportAmazonS3StorageClass               *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	// Migration target store settings
+	MigrationDriverName                         *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MigrationDirectory                          *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3AccessKeyId                *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3SecretAccessKey            *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3Bucket                     *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3PathPrefix                 *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3Region                     *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3Endpoint                   *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3SSL                        *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MigrationAmazonS3SignV2                     *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MigrationAmazonS3SSE                        *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MigrationAmazonS3Trace                      *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	MigrationAmazonS3RequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3UploadPartSizeBytes        *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	MigrationAmazonS3StorageClass               *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EnableMigrationCutover                      *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
 }
 
 func (s *FileSettings) SetDefaults(isUpdate bool) {
@@ -1748,6 +1899,14 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 		s.MaxImageResolution = NewPointer(int64(7680 * 4320)) // 8K, ~33MPX
 	}
 
+	if s.UserStorageQuotaBytes == nil {
+		s.UserStorageQuotaBytes = NewPointer(int64(0)) // Unlimited
+	}
+
+	if s.TeamStorageQuotaBytes == nil {
+		s.TeamStorageQuotaBytes = NewPointer(int64(0)) // Unlimited
+	}
+
 	if s.MaxImageDecoderConcurrency == nil {
 		s.MaxImageDecoderConcurrency = NewPointer(int64(-1)) // Default to NumCPU
 	}
@@ -1772,6 +1931,14 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 		s.ArchiveRecursion = NewPointer(false)
 	}
 
+	if s.ExtractContentMaxFileSizeBytes == nil {
+		s.ExtractContentMaxFileSizeBytes = NewPointer(int64(50 * 1024 * 1024)) // 50 MB
+	}
+
+	if s.ExtractContentTimeoutMilliseconds == nil {
+		s.ExtractContentTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
 	if isUpdate {
 		// When updating an existing configuration, ensure link salt has been specified.
 		if s.PublicLinkSalt == nil || *s.PublicLinkSalt == "" {
@@ -1841,6 +2008,46 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 		s.AmazonS3StorageClass = NewPointer("")
 	}
 
+	if s.EnableEncryptionAtRest == nil {
+		s.EnableEncryptionAtRest = NewPointer(false)
+	}
+
+	if s.EncryptionMasterKey == nil {
+		s.EncryptionMasterKey = NewPointer("")
+	}
+
+	if s.EncryptionMasterKeyFile == nil {
+		s.EncryptionMasterKeyFile = NewPointer("")
+	}
+
+	if s.EnableContentDeduplication == nil {
+		s.EnableContentDeduplication = NewPointer(false)
+	}
+
+	if s.MalwareScanDriver == nil {
+		s.MalwareScanDriver = NewPointer("")
+	}
+
+	if s.MalwareScanAddress == nil {
+		s.MalwareScanAddress = NewPointer("")
+	}
+
+	if s.MalwareScanTimeoutMilliseconds == nil {
+		s.MalwareScanTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.MalwareScanAction == nil {
+		s.MalwareScanAction = NewPointer(MalwareScanActionReject)
+	}
+
+	if s.EnableWebPPreviews == nil {
+		s.EnableWebPPreviews = NewPointer(false)
+	}
+
+	if s.StripImageMetadata == nil {
+		s.StripImageMetadata = NewPointer(false)
+	}
+
 	if s.DedicatedExportStore == nil {
 		s.DedicatedExportStore = NewPointer(false)
 	}
@@ -1910,6 +2117,70 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 	if s.ExportAmazonS3StorageClass == nil {
 		s.ExportAmazonS3StorageClass = NewPointer("")
 	}
+
+	if s.MigrationDriverName == nil {
+		s.MigrationDriverName = NewPointer("")
+	}
+
+	if s.MigrationDirectory == nil {
+		s.MigrationDirectory = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3AccessKeyId == nil {
+		s.MigrationAmazonS3AccessKeyId = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3SecretAccessKey == nil {
+		s.MigrationAmazonS3SecretAccessKey = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3Bucket == nil {
+		s.MigrationAmazonS3Bucket = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3PathPrefix == nil {
+		s.MigrationAmazonS3PathPrefix = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3Region == nil {
+		s.MigrationAmazonS3Region = NewPointer("")
+	}
+
+	if s.MigrationAmazonS3Endpoint == nil || *s.MigrationAmazonS3Endpoint == "" {
+		s.MigrationAmazonS3Endpoint = NewPointer("s3.amazonaws.com")
+	}
+
+	if s.MigrationAmazonS3SSL == nil {
+		s.MigrationAmazonS3SSL = NewPointer(true) // Secure by default.
+	}
+
+	if s.MigrationAmazonS3SignV2 == nil {
+		s.MigrationAmazonS3SignV2 = NewPointer(false)
+	}
+
+	if s.MigrationAmazonS3SSE == nil {
+		s.MigrationAmazonS3SSE = NewPointer(false) // Not Encrypted by default.
+	}
+
+	if s.MigrationAmazonS3Trace == nil {
+		s.MigrationAmazonS3Trace = NewPointer(false)
+	}
+
+	if s.MigrationAmazonS3RequestTimeoutMilliseconds == nil {
+		s.MigrationAmazonS3RequestTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.MigrationAmazonS3UploadPartSizeBytes == nil {
+		s.MigrationAmazonS3UploadPartSizeBytes = NewPointer(int64(FileSettingsDefaultS3UploadPartSizeBytes))
+	}
+
+	if s.MigrationAmazonS3StorageClass == nil {
+		s.MigrationAmazonS3StorageClass = NewPointer("")
+	}
+
+	if s.EnableMigrationCutover == nil {
+		s.EnableMigrationCutover = NewPointer(false)
+	}
 }
 
 type EmailSettings struct {
@@ -1935,7 +2206,7 @@ type EmailSettings struct {
 	PushNotificationContents          *string `access:"site_notifications"`
 	PushNotificationBuffer            *int    // telemetry: none
 	EnableEmailBatching               *bool   `access:"site_notifications"`
-	EmailBatchingBufferSize           *int    `access:"experimental_features"`
+	EmailBatchingBufferSize           *int    `access:"experimental_features"` // Deprecated: batched notifications are queued in the database
 	EmailBatchingInterval             *int    `access:"experimental_features"`
 	EnablePreviewModeBanner           *bool   `access:"site_notifications"`
 	SkipServerCertificateVerification *bool   `access:"environment_smtp,write_restrictable,cloud_restrictable"`
@@ -1943,6 +2214,11 @@ type EmailSettings struct {
 	LoginButtonColor                  *string `access:"experimental_features"`
 	LoginButtonBorderColor            *string `access:"experimental_features"`
 	LoginButtonTextColor              *string `access:"experimental_features"`
+	EnableReplyByEmail                *bool   `access:"site_notifications"`
+	ReplyByEmailAddress               *string `access:"site_notifications,cloud_restrictable"`
+	ReplyByEmailSigningKey            *string `access:"site_notifications,write_restrictable,cloud_restrictable"` // telemetry: none
+	EnableEmailDigest                 *bool   `access:"site_notifications"`
+	EmailDigestHour                   *int    `access:"site_notifications"`
 }
 
 func (s *EmailSettings) SetDefaults(isUpdate bool) {
@@ -2085,6 +2361,32 @@ func (s *EmailSettings) SetDefaults(isUpdate bool) {
 	if s.LoginButtonTextColor == nil {
 		s.LoginButtonTextColor = NewPointer("#2389D7")
 	}
+
+	if s.EnableReplyByEmail == nil {
+		s.EnableReplyByEmail = NewPointer(false)
+	}
+
+	if s.ReplyByEmailAddress == nil {
+		s.ReplyByEmailAddress = NewPointer("")
+	}
+
+	if isUpdate {
+		// When updating an existing configuration, ensure a signing key has been specified.
+		if s.ReplyByEmailSigningKey == nil || *s.ReplyByEmailSigningKey == "" {
+			s.ReplyByEmailSigningKey = NewPointer(NewRandomString(32))
+		}
+	} else {
+		// When generating a blank configuration, leave this key empty to be generated on server start.
+		s.ReplyByEmailSigningKey = NewPointer("")
+	}
+
+	if s.EnableEmailDigest == nil {
+		s.EnableEmailDigest = NewPointer(false)
+	}
+
+	if s.EmailDigestHour == nil {
+		s.EmailDigestHour = NewPointer(EmailDigestHour)
+	}
 }
 
 type RateLimitSettings struct {
@@ -3155,6 +3457,93 @@ func (s *ElasticsearchSettings) SetDefaults() {
 	}
 }
 
+// EmbeddedSearchSettings configures the search engine storing its indexes on disk, for the
+// deployments without Elasticsearch. In a cluster, IndexDir must be on storage shared by all
+// the nodes, one of them writing the indexes.
+type EmbeddedSearchSettings struct {
+	IndexDir           *string `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EnableIndexing     *bool   `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EnableSearching    *bool   `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EnableAutocomplete *bool   `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	BatchSize          *int    `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+}
+
+func (s *EmbeddedSearchSettings) SetDefaults() {
+	if s.IndexDir == nil {
+		s.IndexDir = NewPointer(EmbeddedSearchSettingsDefaultIndexDir)
+	}
+
+	if s.EnableIndexing == nil {
+		s.EnableIndexing = NewPointer(false)
+	}
+
+	if s.EnableSearching == nil {
+		s.EnableSearching = NewPointer(false)
+	}
+
+	if s.EnableAutocomplete == nil {
+		s.EnableAutocomplete = NewPointer(false)
+	}
+
+	if s.BatchSize == nil {
+		s.BatchSize = NewPointer(EmbeddedSearchSettingsDefaultBatchSize)
+	}
+}
+
+// SemanticSearchSettings configures the search of posts by the similarity of the embeddings
+// of their messages, stored with pgvector. The database must have the vector extension, 0.5.0
+// or later, available when it is migrated. EmbeddingDimensions must be the size of the
+// embeddings of the model of the openai provider, the local provider has its own size.
+type SemanticSearchSettings struct {
+	Enable              *bool   `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EmbeddingProvider   *string `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EmbeddingURL        *string `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"` // telemetry: none
+	EmbeddingAPIKey     *string `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"` // telemetry: none
+	EmbeddingModel      *string `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	EmbeddingDimensions *int    `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	RequestTimeoutMs    *int    `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	BatchSize           *int    `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+	MaxVectorCandidates *int    `access:"environment_elasticsearch,write_restrictable,cloud_restrictable"`
+}
+
+func (s *SemanticSearchSettings) SetDefaults() {
+	if s.Enable == nil {
+		s.Enable = NewPointer(false)
+	}
+
+	if s.EmbeddingProvider == nil {
+		s.EmbeddingProvider = NewPointer(SemanticSearchEmbeddingProviderOpenAI)
+	}
+
+	if s.EmbeddingURL == nil {
+		s.EmbeddingURL = NewPointer("")
+	}
+
+	if s.EmbeddingAPIKey == nil {
+		s.EmbeddingAPIKey = NewPointer("")
+	}
+
+	if s.EmbeddingModel == nil {
+		s.EmbeddingModel = NewPointer(SemanticSearchSettingsDefaultEmbeddingModel)
+	}
+
+	if s.EmbeddingDimensions == nil {
+		s.EmbeddingDimensions = NewPointer(SemanticSearchSettingsDefaultEmbeddingDimensions)
+	}
+
+	if s.RequestTimeoutMs == nil {
+		s.RequestTimeoutMs = NewPointer(SemanticSearchSettingsDefaultRequestTimeoutMs)
+	}
+
+	if s.BatchSize == nil {
+		s.BatchSize = NewPointer(SemanticSearchSettingsDefaultBatchSize)
+	}
+
+	if s.MaxVectorCandidates == nil {
+		s.MaxVectorCandidates = NewPointer(SemanticSearchSettingsDefaultMaxVectorCandidates)
+	}
+}
+
 type DataRetentionSettings struct {
 	EnableMessageDeletion          *bool   `access:"compliance_data_retention_policy"`
 	EnableFileDeletion             *bool   `access:"compliance_data_retention_policy"`
@@ -3860,6 +4249,8 @@ type Config struct {
 	ExperimentalSettings        ExperimentalSettings
 	AnalyticsSettings           AnalyticsSettings
 	ElasticsearchSettings       ElasticsearchSettings
+	EmbeddedSearchSettings      EmbeddedSearchSettings
+	SemanticSearchSettings      SemanticSearchSettings
 	DataRetentionSettings       DataRetentionSettings
 	MessageExportSettings       MessageExportSettings
 	JobSettings                 JobSettings
@@ -3973,6 +4364,8 @@ func (o *Config) SetDefaults() {
 	o.LocalizationSettings.SetDefaults()
 	o.AutoTranslationSettings.SetDefaults()
 	o.ElasticsearchSettings.SetDefaults()
+	o.EmbeddedSearchSettings.SetDefaults()
+	o.SemanticSearchSettings.SetDefaults()
 	o.NativeAppSettings.SetDefaults()
 	o.DataRetentionSettings.SetDefaults()
 	o.RateLimitSettings.SetDefaults()
@@ -4001,10 +4394,6 @@ func (o *Config) IsValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.site_url_email_batching.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if *o.ClusterSettings.Enable && *o.EmailSettings.EnableEmailBatching {
-		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_email_batching.app_error", nil, "", http.StatusBadRequest)
-	}
-
 	if appErr := o.MetricsSettings.isValid(); appErr != nil {
 		return appErr
 	}
@@ -4045,8 +4434,8 @@ func (o *Config) IsValid() *AppError {
 		return appErr
 	}
 
-	if *o.PasswordSettings.MinimumLength < PasswordMinimumLength || *o.PasswordSettings.MinimumLength > PasswordMaximumLength {
-		return NewAppError("Config.IsValid", "model.config.is_valid.password_length.app_error", map[string]any{"MinLength": PasswordMinimumLength, "MaxLength": PasswordMaximumLength}, "", http.StatusBadRequest)
+	if appErr := o.PasswordSettings.isValid(); appErr != nil {
+		return appErr
 	}
 
 	if appErr := o.RateLimitSettings.isValid(); appErr != nil {
@@ -4061,6 +4450,14 @@ func (o *Config) IsValid() *AppError {
 		return appErr
 	}
 
+	if appErr := o.EmbeddedSearchSettings.isValid(); appErr != nil {
+		return appErr
+	}
+
+	if appErr := o.SemanticSearchSettings.isValid(); appErr != nil {
+		return appErr
+	}
+
 	if appErr := o.DataRetentionSettings.isValid(); appErr != nil {
 		return appErr
 	}
@@ -4209,6 +4606,10 @@ func (s *SqlSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.sql_max_conn.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if *s.DatabaseSearchMode != DatabaseSearchModeFullText && *s.DatabaseSearchMode != DatabaseSearchModeNgram {
+		return NewAppError("Config.IsValid", "model.config.is_valid.sql_database_search_mode.app_error", map[string]any{"Mode": *s.DatabaseSearchMode}, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -4217,6 +4618,14 @@ func (s *FileSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.max_file_size.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if *s.UserStorageQuotaBytes < 0 || *s.TeamStorageQuotaBytes < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.storage_quota.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.ExtractContentMaxFileSizeBytes < 0 || *s.ExtractContentTimeoutMilliseconds < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.extract_content_limits.app_error", nil, "", http.StatusBadRequest)
+	}
+
 	if !(*s.DriverName == ImageDriverLocal || *s.DriverName == ImageDriverS3) {
 		return NewAppError("Config.IsValid", "model.config.is_valid.file_driver.app_error", nil, "", http.StatusBadRequest)
 	}
@@ -4262,9 +4671,80 @@ func (s *FileSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.directory_whitespace.app_error", map[string]any{"Setting": "FileSettings.ExportDirectory", "Value": *s.ExportDirectory}, "", http.StatusBadRequest)
 	}
 
+	if *s.MigrationDriverName != "" && !(*s.MigrationDriverName == ImageDriverLocal || *s.MigrationDriverName == ImageDriverS3) {
+		return NewAppError("Config.IsValid", "model.config.is_valid.migration_file_driver.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.EnableMigrationCutover && *s.MigrationDriverName == "" {
+		return NewAppError("Config.IsValid", "model.config.is_valid.migration_cutover.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.MigrationAmazonS3StorageClass != "" && !slices.Contains([]string{StorageClassStandard, StorageClassReducedRedundancy, StorageClassStandardIA, StorageClassOnezoneIA, StorageClassIntelligentTiering, StorageClassGlacier, StorageClassDeepArchive, StorageClassOutposts, StorageClassGlacierIR, StorageClassSnow, StorageClassExpressOnezone}, *s.MigrationAmazonS3StorageClass) {
+		return NewAppError("Config.IsValid", "model.config.is_valid.storage_class.app_error", map[string]any{"Value": *s.MigrationAmazonS3StorageClass}, "", http.StatusBadRequest)
+	}
+
+	if strings.TrimSpace(*s.MigrationDirectory) != *s.MigrationDirectory {
+		return NewAppError("Config.IsValid", "model.config.is_valid.directory_whitespace.app_error", map[string]any{"Setting": "FileSettings.MigrationDirectory", "Value": *s.MigrationDirectory}, "", http.StatusBadRequest)
+	}
+
+	if *s.EnableEncryptionAtRest {
+		if (*s.EncryptionMasterKey == "") == (*s.EncryptionMasterKeyFile == "") {
+			return NewAppError("Config.IsValid", "model.config.is_valid.encryption_master_key_source.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if *s.EncryptionMasterKey != "" {
+			if _, err := ParseEncryptionMasterKeys(*s.EncryptionMasterKey); err != nil {
+				return NewAppError("Config.IsValid", "model.config.is_valid.encryption_master_key.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+			}
+		}
+	}
+
+	if *s.MalwareScanDriver != "" {
+		if *s.MalwareScanDriver != MalwareScanDriverClamd && *s.MalwareScanDriver != MalwareScanDriverICAP {
+			return NewAppError("Config.IsValid", "model.config.is_valid.malware_scan_driver.app_error", map[string]any{"Value": *s.MalwareScanDriver}, "", http.StatusBadRequest)
+		}
+
+		if *s.MalwareScanAddress == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.malware_scan_address.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	if *s.MalwareScanTimeoutMilliseconds <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.malware_scan_timeout.app_error", map[string]any{"Value": *s.MalwareScanTimeoutMilliseconds}, "", http.StatusBadRequest)
+	}
+
+	if *s.MalwareScanAction != MalwareScanActionReject && *s.MalwareScanAction != MalwareScanActionQuarantine {
+		return NewAppError("Config.IsValid", "model.config.is_valid.malware_scan_action.app_error", map[string]any{"Value": *s.MalwareScanAction}, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
+// ParseEncryptionMasterKeys decodes the master keys used to encrypt files at rest. Keys are base64
+// encoded, 256 bits long and separated by commas or whitespace. The first key encrypts new files
+// while the others are only kept to read files until their data keys have been re-wrapped.
+func ParseEncryptionMasterKeys(keys string) ([][]byte, error) {
+	fields := strings.FieldsFunc(keys, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("no master key found")
+	}
+
+	masterKeys := make([][]byte, 0, len(fields))
+	for i, field := range fields {
+		key, err := base64.StdEncoding.DecodeString(field)
+		if err != nil {
+			return nil, errors.Wrapf(err, "master key %d is not base64 encoded", i+1)
+		}
+		if len(key) != 32 {
+			return nil, errors.Errorf("master key %d must be 32 bytes long, got %d", i+1, len(key))
+		}
+		masterKeys = append(masterKeys, key)
+	}
+	return masterKeys, nil
+}
+
 func (s *EmailSettings) isValid() *AppError {
 	if !(*s.ConnectionSecurity == ConnSecurityNone || *s.ConnectionSecurity == ConnSecurityTLS || *s.ConnectionSecurity == ConnSecurityStarttls || *s.ConnectionSecurity == ConnSecurityPlain) {
 		return NewAppError("Config.IsValid", "model.config.is_valid.email_security.app_error", nil, "", http.StatusBadRequest)
@@ -4282,6 +4762,21 @@ func (s *EmailSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.email_notification_contents_type.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if *s.EmailDigestHour < 0 || *s.EmailDigestHour > 23 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.email_digest_hour.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.ReplyByEmailSigningKey != "" && len(*s.ReplyByEmailSigningKey) < 32 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.reply_by_email_signing_key.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.EnableReplyByEmail {
+		// The thread and signature are appended to the local part as a subaddress
+		if !IsValidEmail(*s.ReplyByEmailAddress) || strings.Contains(*s.ReplyByEmailAddress, "+") {
+			return NewAppError("Config.IsValid", "model.config.is_valid.reply_by_email_address.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
 	return nil
 }
 
@@ -4631,6 +5126,65 @@ func (s *ElasticsearchSettings) isValid() *AppError {
 	return nil
 }
 
+func (s *EmbeddedSearchSettings) isValid() *AppError {
+	if *s.EnableIndexing && *s.IndexDir == "" {
+		return NewAppError("Config.IsValid", "model.config.is_valid.embedded_search.index_dir.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.EnableSearching && !*s.EnableIndexing {
+		return NewAppError("Config.IsValid", "model.config.is_valid.embedded_search.enable_searching.app_error", map[string]any{
+			"Searching":      "EmbeddedSearchSettings.EnableSearching",
+			"EnableIndexing": "EmbeddedSearchSettings.EnableIndexing",
+		}, "", http.StatusBadRequest)
+	}
+
+	if *s.EnableAutocomplete && !*s.EnableIndexing {
+		return NewAppError("Config.IsValid", "model.config.is_valid.embedded_search.enable_autocomplete.app_error", map[string]any{
+			"Autocomplete":   "EmbeddedSearchSettings.EnableAutocomplete",
+			"EnableIndexing": "EmbeddedSearchSettings.EnableIndexing",
+		}, "", http.StatusBadRequest)
+	}
+
+	minBatchSize := 1
+	if *s.BatchSize < minBatchSize {
+		return NewAppError("Config.IsValid", "model.config.is_valid.embedded_search.batch_size.app_error", map[string]any{"BatchSize": minBatchSize}, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (s *SemanticSearchSettings) isValid() *AppError {
+	if *s.EmbeddingProvider != SemanticSearchEmbeddingProviderOpenAI && *s.EmbeddingProvider != SemanticSearchEmbeddingProviderLocal {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.embedding_provider.app_error", map[string]any{"Value": *s.EmbeddingProvider}, "", http.StatusBadRequest)
+	}
+
+	if *s.Enable && *s.EmbeddingProvider == SemanticSearchEmbeddingProviderOpenAI && !IsValidHTTPURL(*s.EmbeddingURL) {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.embedding_url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.EmbeddingModel == "" {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.embedding_model.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.EmbeddingDimensions < 1 || *s.EmbeddingDimensions > SemanticSearchSettingsMaxEmbeddingDimensions {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.embedding_dimensions.app_error", map[string]any{"Value": *s.EmbeddingDimensions, "Max": SemanticSearchSettingsMaxEmbeddingDimensions}, "", http.StatusBadRequest)
+	}
+
+	if *s.RequestTimeoutMs <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.request_timeout.app_error", map[string]any{"Value": *s.RequestTimeoutMs}, "", http.StatusBadRequest)
+	}
+
+	if *s.BatchSize < 1 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.batch_size.app_error", map[string]any{"BatchSize": 1}, "", http.StatusBadRequest)
+	}
+
+	if *s.MaxVectorCandidates < 1 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.semantic_search.max_vector_candidates.app_error", map[string]any{"Value": *s.MaxVectorCandidates}, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 func (s *DataRetentionSettings) isValid() *AppError {
 	if s.MessageRetentionDays == nil || *s.MessageRetentionDays < 0 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.data_retention.message_retention_days_too_low.app_error", nil, "", http.StatusBadRequest)
@@ -4853,6 +5407,14 @@ func (o *Config) Sanitize(pluginManifests []*Manifest, opts *SanitizeOptions) {
 		*o.FileSettings.AmazonS3SecretAccessKey = FakeSetting
 	}
 
+	if o.FileSettings.EncryptionMasterKey != nil && *o.FileSettings.EncryptionMasterKey != "" {
+		*o.FileSettings.EncryptionMasterKey = FakeSetting
+	}
+
+	if o.FileSettings.MigrationAmazonS3SecretAccessKey != nil && *o.FileSettings.MigrationAmazonS3SecretAccessKey != "" {
+		*o.FileSettings.MigrationAmazonS3SecretAccessKey = FakeSetting
+	}
+
 	if o.EmailSettings.SMTPPassword != nil && *o.EmailSettings.SMTPPassword != "" {
 		*o.EmailSettings.SMTPPassword = FakeSetting
 	}
@@ -4881,6 +5443,10 @@ func (o *Config) Sanitize(pluginManifests []*Manifest, opts *SanitizeOptions) {
 		*o.SqlSettings.AtRestEncryptKey = FakeSetting
 	}
 
+	if o.EmailSettings.ReplyByEmailSigningKey != nil {
+		*o.EmailSettings.ReplyByEmailSigningKey = FakeSetting
+	}
+
 	if o.ElasticsearchSettings.Password != nil {
 		*o.ElasticsearchSettings.Password = FakeSetting
 	}
@@ -4914,6 +5480,10 @@ func (o *Config) Sanitize(pluginManifests []*Manifest, opts *SanitizeOptions) {
 		*o.CacheSettings.RedisPassword = FakeSetting
 	}
 
+	if o.SemanticSearchSettings.EmbeddingAPIKey != nil && *o.SemanticSearchSettings.EmbeddingAPIKey != "" {
+		*o.SemanticSearchSettings.EmbeddingAPIKey = FakeSetting
+	}
+
 	o.PluginSettings.Sanitize(pluginManifests)
 }
 
@@ -207,6 +207,251 @@ func TestConfigDefaultFileSettingsS3SSE(t *testing.T) {
 	require.False(t, *c1.FileSettings.AmazonS3SSE)
 }
 
+func TestFileSettingsIsValidEncryptionAtRest(t *testing.T) {
+	const (
+		key1 = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+		key2 = "ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8="
+	)
+
+	for name, test := range map[string]struct {
+		update  func(*FileSettings)
+		errorId string
+	}{
+		"disabled": {
+			update: func(*FileSettings) {},
+		},
+		"master key": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKey = NewPointer(key1)
+			},
+		},
+		"master key with a retired key": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKey = NewPointer(key1 + ", " + key2)
+			},
+		},
+		"master key file": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKeyFile = NewPointer("/etc/mattermost/master.key")
+			},
+		},
+		"no master key": {
+			update:  func(s *FileSettings) { s.EnableEncryptionAtRest = NewPointer(true) },
+			errorId: "model.config.is_valid.encryption_master_key_source.app_error",
+		},
+		"both master key and master key file": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKey = NewPointer(key1)
+				s.EncryptionMasterKeyFile = NewPointer("/etc/mattermost/master.key")
+			},
+			errorId: "model.config.is_valid.encryption_master_key_source.app_error",
+		},
+		"master key too short": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKey = NewPointer("AAECAwQFBgcICQoLDA0ODw==")
+			},
+			errorId: "model.config.is_valid.encryption_master_key.app_error",
+		},
+		"master key not base64 encoded": {
+			update: func(s *FileSettings) {
+				s.EnableEncryptionAtRest = NewPointer(true)
+				s.EncryptionMasterKey = NewPointer(key1 + ",not a key")
+			},
+			errorId: "model.config.is_valid.encryption_master_key.app_error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			cfg.SetDefaults()
+			test.update(&cfg.FileSettings)
+
+			appErr := cfg.FileSettings.isValid()
+			if test.errorId == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errorId, appErr.Id)
+			}
+		})
+	}
+}
+
+func TestFileSettingsIsValidMigration(t *testing.T) {
+	for name, test := range map[string]struct {
+		update  func(*FileSettings)
+		errorId string
+	}{
+		"no migration target": {
+			update: func(*FileSettings) {},
+		},
+		"s3 migration target": {
+			update: func(s *FileSettings) {
+				s.MigrationDriverName = NewPointer(ImageDriverS3)
+				s.MigrationAmazonS3Bucket = NewPointer("bucket")
+			},
+		},
+		"cutover to a local migration target": {
+			update: func(s *FileSettings) {
+				s.MigrationDriverName = NewPointer(ImageDriverLocal)
+				s.MigrationDirectory = NewPointer("/var/mattermost/data")
+				s.EnableMigrationCutover = NewPointer(true)
+			},
+		},
+		"invalid migration driver": {
+			update: func(s *FileSettings) {
+				s.MigrationDriverName = NewPointer("ftp")
+			},
+			errorId: "model.config.is_valid.migration_file_driver.app_error",
+		},
+		"cutover without a migration target": {
+			update: func(s *FileSettings) {
+				s.EnableMigrationCutover = NewPointer(true)
+			},
+			errorId: "model.config.is_valid.migration_cutover.app_error",
+		},
+		"invalid migration storage class": {
+			update: func(s *FileSettings) {
+				s.MigrationDriverName = NewPointer(ImageDriverS3)
+				s.MigrationAmazonS3StorageClass = NewPointer("INVALID")
+			},
+			errorId: "model.config.is_valid.storage_class.app_error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			cfg.SetDefaults()
+			test.update(&cfg.FileSettings)
+
+			appErr := cfg.FileSettings.isValid()
+			if test.errorId == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errorId, appErr.Id)
+			}
+		})
+	}
+}
+
+func TestFileSettingsIsValidStorageQuota(t *testing.T) {
+	for name, test := range map[string]struct {
+		userQuota int64
+		teamQuota int64
+		valid     bool
+	}{
+		"unlimited":           {valid: true},
+		"limited":             {userQuota: 1 << 30, teamQuota: 100 << 30, valid: true},
+		"negative user quota": {userQuota: -1},
+		"negative team quota": {teamQuota: -1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			cfg.SetDefaults()
+			cfg.FileSettings.UserStorageQuotaBytes = NewPointer(test.userQuota)
+			cfg.FileSettings.TeamStorageQuotaBytes = NewPointer(test.teamQuota)
+
+			appErr := cfg.FileSettings.isValid()
+			if test.valid {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, "model.config.is_valid.storage_quota.app_error", appErr.Id)
+			}
+		})
+	}
+}
+
+func TestFileSettingsIsValidMalwareScan(t *testing.T) {
+	for name, test := range map[string]struct {
+		driver  string
+		address string
+		timeout int64
+		action  string
+		errID   string
+	}{
+		"disabled":        {timeout: 30000, action: MalwareScanActionReject},
+		"clamd":           {driver: MalwareScanDriverClamd, address: "tcp://localhost:3310", timeout: 30000, action: MalwareScanActionQuarantine},
+		"icap":            {driver: MalwareScanDriverICAP, address: "icap://localhost:1344/avscan", timeout: 30000, action: MalwareScanActionReject},
+		"unknown driver":  {driver: "sophos", address: "tcp://localhost:3310", timeout: 30000, action: MalwareScanActionReject, errID: "model.config.is_valid.malware_scan_driver.app_error"},
+		"missing address": {driver: MalwareScanDriverClamd, timeout: 30000, action: MalwareScanActionReject, errID: "model.config.is_valid.malware_scan_address.app_error"},
+		"invalid timeout": {timeout: 0, action: MalwareScanActionReject, errID: "model.config.is_valid.malware_scan_timeout.app_error"},
+		"unknown action":  {timeout: 30000, action: "delete", errID: "model.config.is_valid.malware_scan_action.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			cfg.SetDefaults()
+			cfg.FileSettings.MalwareScanDriver = NewPointer(test.driver)
+			cfg.FileSettings.MalwareScanAddress = NewPointer(test.address)
+			cfg.FileSettings.MalwareScanTimeoutMilliseconds = NewPointer(test.timeout)
+			cfg.FileSettings.MalwareScanAction = NewPointer(test.action)
+
+			appErr := cfg.FileSettings.isValid()
+			if test.errID == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errID, appErr.Id)
+			}
+		})
+	}
+}
+
+func TestSemanticSearchSettingsIsValid(t *testing.T) {
+	for name, test := range map[string]struct {
+		enable     bool
+		provider   string
+		url        string
+		timeout    int
+		dimensions int
+		errID      string
+	}{
+		"defaults":                 {provider: SemanticSearchEmbeddingProviderOpenAI, timeout: 30000},
+		"openai":                   {enable: true, provider: SemanticSearchEmbeddingProviderOpenAI, url: "http://localhost:8080/v1/embeddings", timeout: 30000},
+		"local":                    {enable: true, provider: SemanticSearchEmbeddingProviderLocal, timeout: 30000},
+		"unknown provider":         {provider: "word2vec", timeout: 30000, errID: "model.config.is_valid.semantic_search.embedding_provider.app_error"},
+		"missing url when enabled": {enable: true, provider: SemanticSearchEmbeddingProviderOpenAI, timeout: 30000, errID: "model.config.is_valid.semantic_search.embedding_url.app_error"},
+		"invalid timeout":          {provider: SemanticSearchEmbeddingProviderLocal, timeout: 0, errID: "model.config.is_valid.semantic_search.request_timeout.app_error"},
+		"no dimensions":            {provider: SemanticSearchEmbeddingProviderOpenAI, timeout: 30000, dimensions: -1, errID: "model.config.is_valid.semantic_search.embedding_dimensions.app_error"},
+		"too many dimensions":      {provider: SemanticSearchEmbeddingProviderOpenAI, timeout: 30000, dimensions: 3072, errID: "model.config.is_valid.semantic_search.embedding_dimensions.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{}
+			cfg.SetDefaults()
+			cfg.SemanticSearchSettings.Enable = NewPointer(test.enable)
+			cfg.SemanticSearchSettings.EmbeddingProvider = NewPointer(test.provider)
+			cfg.SemanticSearchSettings.EmbeddingURL = NewPointer(test.url)
+			cfg.SemanticSearchSettings.RequestTimeoutMs = NewPointer(test.timeout)
+			if test.dimensions != 0 {
+				cfg.SemanticSearchSettings.EmbeddingDimensions = NewPointer(test.dimensions)
+			}
+
+			appErr := cfg.SemanticSearchSettings.isValid()
+			if test.errID == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errID, appErr.Id)
+			}
+		})
+	}
+}
+
+func TestParseEncryptionMasterKeys(t *testing.T) {
+	keys, err := ParseEncryptionMasterKeys("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=,\nICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=\n")
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, byte(0), keys[0][0])
+	assert.Equal(t, byte(32), keys[1][0])
+
+	_, err = ParseEncryptionMasterKeys(" \n")
+	require.Error(t, err)
+}
+
 func TestFileSettingsDirectoryWhitespaceValidation(t *testing.T) {
 	// Define Unicode whitespace characters to test
 	unicodeWhitespaces := []struct {
@@ -527,6 +772,109 @@ func TestTeamSettingsIsValidSiteNameEmpty(t *testing.T) {
 	require.Nil(t, c1.TeamSettings.isValid())
 }
 
+func TestPasswordSettingsIsValid(t *testing.T) {
+	for name, test := range map[string]struct {
+		update  func(*PasswordSettings)
+		errorId string
+	}{
+		"defaults": {
+			update: func(*PasswordSettings) {},
+		},
+		"argon2id": {
+			update: func(s *PasswordSettings) { s.HashingAlgorithm = NewPointer(PasswordHashingAlgorithmArgon2id) },
+		},
+		"minimum length too short": {
+			update:  func(s *PasswordSettings) { s.MinimumLength = NewPointer(PasswordMinimumLength - 1) },
+			errorId: "model.config.is_valid.password_length.app_error",
+		},
+		"unknown algorithm": {
+			update:  func(s *PasswordSettings) { s.HashingAlgorithm = NewPointer("md5") },
+			errorId: "model.config.is_valid.password_hashing_algorithm.app_error",
+		},
+		"no parallelism": {
+			update:  func(s *PasswordSettings) { s.Argon2idParallelism = NewPointer(0) },
+			errorId: "model.config.is_valid.password_argon2id_parallelism.app_error",
+		},
+		"too many iterations": {
+			update: func(s *PasswordSettings) {
+				s.Argon2idIterations = NewPointer(PasswordSettingsMaxArgon2idIterations + 1)
+			},
+			errorId: "model.config.is_valid.password_argon2id_iterations.app_error",
+		},
+		"not enough memory per thread": {
+			update: func(s *PasswordSettings) {
+				s.Argon2idParallelism = NewPointer(4)
+				s.Argon2idMemoryKiB = NewPointer(31)
+			},
+			errorId: "model.config.is_valid.password_argon2id_memory.app_error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := PasswordSettings{}
+			s.SetDefaults()
+			test.update(&s)
+
+			appErr := s.isValid()
+			if test.errorId == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errorId, appErr.Id)
+			}
+		})
+	}
+}
+
+func TestEmailSettingsIsValid(t *testing.T) {
+	for name, test := range map[string]struct {
+		update  func(*EmailSettings)
+		errorId string
+	}{
+		"disabled": {
+			update: func(*EmailSettings) {},
+		},
+		"enabled": {
+			update: func(s *EmailSettings) {
+				s.EnableReplyByEmail = NewPointer(true)
+				s.ReplyByEmailAddress = NewPointer("reply@example.com")
+			},
+		},
+		"enabled without address": {
+			update:  func(s *EmailSettings) { s.EnableReplyByEmail = NewPointer(true) },
+			errorId: "model.config.is_valid.reply_by_email_address.app_error",
+		},
+		"address with subaddress": {
+			update: func(s *EmailSettings) {
+				s.EnableReplyByEmail = NewPointer(true)
+				s.ReplyByEmailAddress = NewPointer("reply+mattermost@example.com")
+			},
+			errorId: "model.config.is_valid.reply_by_email_address.app_error",
+		},
+		"signing key too short": {
+			update:  func(s *EmailSettings) { s.ReplyByEmailSigningKey = NewPointer("short") },
+			errorId: "model.config.is_valid.reply_by_email_signing_key.app_error",
+		},
+		"digest hour out of range": {
+			update:  func(s *EmailSettings) { s.EmailDigestHour = NewPointer(24) },
+			errorId: "model.config.is_valid.email_digest_hour.app_error",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := EmailSettings{}
+			s.SetDefaults(true)
+			test.update(&s)
+
+			appErr := s.isValid()
+			if test.errorId == "" {
+				require.Nil(t, appErr)
+			} else {
+				require.NotNil(t, appErr)
+				require.Equal(t, test.errorId, appErr.Id)
+			}
+		})
+	}
+}
+
 func TestTeamSettingsDefaultJoinLeaveMessage(t *testing.T) {
 	c1 := Config{}
 	c1.SetDefaults()
@@ -1512,6 +1860,8 @@ func TestConfigSanitize(t *testing.T) {
 
 	*c.LdapSettings.BindPassword = "foo"
 	*c.FileSettings.AmazonS3SecretAccessKey = "bar"
+	*c.FileSettings.EncryptionMasterKey = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+	*c.FileSettings.MigrationAmazonS3SecretAccessKey = "qux"
 	*c.EmailSettings.SMTPPassword = "baz"
 	*c.GitLabSettings.Secret = "bingo"
 	*c.OpenIdSettings.Secret = "secret"
@@ -1528,11 +1878,14 @@ func TestConfigSanitize(t *testing.T) {
 	assert.Equal(t, FakeSetting, *c.LdapSettings.BindPassword)
 	assert.Equal(t, FakeSetting, *c.FileSettings.PublicLinkSalt)
 	assert.Equal(t, FakeSetting, *c.FileSettings.AmazonS3SecretAccessKey)
+	assert.Equal(t, FakeSetting, *c.FileSettings.EncryptionMasterKey)
+	assert.Equal(t, FakeSetting, *c.FileSettings.MigrationAmazonS3SecretAccessKey)
 	assert.Equal(t, FakeSetting, *c.EmailSettings.SMTPPassword)
 	assert.Equal(t, FakeSetting, *c.GitLabSettings.Secret)
 	assert.Equal(t, FakeSetting, *c.OpenIdSettings.Secret)
 	assert.Equal(t, FakeSetting, *c.SqlSettings.DataSource)
 	assert.Equal(t, FakeSetting, *c.SqlSettings.AtRestEncryptKey)
+	assert.Equal(t, FakeSetting, *c.EmailSettings.ReplyByEmailSigningKey)
 	assert.Equal(t, FakeSetting, *c.ElasticsearchSettings.Password)
 	assert.Equal(t, FakeSetting, c.SqlSettings.DataSourceReplicas[0])
 	assert.Equal(t, FakeSetting, c.SqlSettings.DataSourceSearchReplicas[0])
@@ -1857,6 +2210,22 @@ func TestConfigImportSettingsIsValid(t *testing.T) {
 	require.Equal(t, "model.config.is_valid.import.retention_days_too_low.app_error", appErr.Id)
 }
 
+func TestConfigSqlSettingsDatabaseSearchMode(t *testing.T) {
+	cfg := Config{}
+	cfg.SetDefaults()
+
+	require.Equal(t, DatabaseSearchModeFullText, *cfg.SqlSettings.DatabaseSearchMode)
+	require.Nil(t, cfg.SqlSettings.isValid())
+
+	*cfg.SqlSettings.DatabaseSearchMode = DatabaseSearchModeNgram
+	require.Nil(t, cfg.SqlSettings.isValid())
+
+	*cfg.SqlSettings.DatabaseSearchMode = "trigram"
+	appErr := cfg.SqlSettings.isValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.config.is_valid.sql_database_search_mode.app_error", appErr.Id)
+}
+
 func TestConfigExportSettingsDefaults(t *testing.T) {
 	cfg := Config{}
 	cfg.SetDefaults()
@@ -0,0 +1,14 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+// FileBlob tracks a deduplicated file stored once under its content hash and
+// the number of FileInfos referencing it.
+type FileBlob struct {
+	Hash     string `json:"hash"`
+	Size     int64  `json:"size"`
+	RefCount int64  `json:"ref_count"`
+	CreateAt int64  `json:"create_at"`
+	UpdateAt int64  `json:"update_at"`
+}
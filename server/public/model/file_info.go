@@ -4,15 +4,23 @@
 package model
 
 import (
+	"crypto/sha256"
 	"mime"
 	"net/http"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
 const (
 	FileinfoSortByCreated = "CreateAt"
 	FileinfoSortBySize    = "Size"
+
+	FileScanVerdictClean    = "clean"
+	FileScanVerdictInfected = "infected"
+
+	// FileScanSignatureMaxRunes is the size of the ScanSignature column.
+	FileScanSignatureMaxRunes = 256
 )
 
 // GetFileInfosOptions contains options for getting FileInfos
@@ -39,13 +47,16 @@ type FileInfo struct {
 	// potentially distinct from the ChannelId provided when the file is first uploaded and
 	// used to organize the directories in the file store, since in theory that same file
 	// could be attached to a post from a different channel (or not attached to a post at all).
-	ChannelId       string  `json:"channel_id"`
-	CreateAt        int64   `json:"create_at"`
-	UpdateAt        int64   `json:"update_at"`
-	DeleteAt        int64   `json:"delete_at"`
-	Path            string  `json:"-"` // not sent back to the client
-	ThumbnailPath   string  `json:"-"` // not sent back to the client
-	PreviewPath     string  `json:"-"` // not sent back to the client
+	ChannelId     string `json:"channel_id"`
+	CreateAt      int64  `json:"create_at"`
+	UpdateAt      int64  `json:"update_at"`
+	DeleteAt      int64  `json:"delete_at"`
+	Path          string `json:"-"` // not sent back to the client
+	ThumbnailPath string `json:"-"` // not sent back to the client
+	PreviewPath   string `json:"-"` // not sent back to the client
+	// ContentHash is the hex encoded SHA-256 of the file content when Path points to a
+	// deduplicated blob shared with other file infos.
+	ContentHash     string  `json:"-"`
 	Name            string  `json:"name"`
 	Extension       string  `json:"extension"`
 	Size            int64   `json:"size"`
@@ -57,6 +68,15 @@ type FileInfo struct {
 	Content         string  `json:"-"`
 	RemoteId        *string `json:"remote_id"`
 	Archived        bool    `json:"archived"`
+	// ScanVerdict is the result of the malware scan of the file, empty when it wasn't scanned.
+	ScanVerdict   string `json:"scan_verdict,omitempty"`
+	ScanSignature string `json:"scan_signature,omitempty"`
+	// MetadataStripped tells whether the EXIF, IPTC and XMP metadata were removed from the
+	// uploaded image.
+	MetadataStripped bool `json:"metadata_stripped,omitempty"`
+	// OriginalPath points to the image as uploaded, kept with its metadata for compliance
+	// export.
+	OriginalPath string `json:"-"` // not sent back to the client
 }
 
 func (fi *FileInfo) Auditable() map[string]any {
@@ -117,9 +137,36 @@ func (fi *FileInfo) IsValid() *AppError {
 		return NewAppError("FileInfo.IsValid", "model.file_info.is_valid.path.app_error", nil, "id="+fi.Id, http.StatusBadRequest)
 	}
 
+	if fi.ContentHash != "" && !isValidContentHash(fi.ContentHash) {
+		return NewAppError("FileInfo.IsValid", "model.file_info.is_valid.content_hash.app_error", nil, "id="+fi.Id, http.StatusBadRequest)
+	}
+
+	if fi.ScanVerdict != "" && fi.ScanVerdict != FileScanVerdictClean && fi.ScanVerdict != FileScanVerdictInfected {
+		return NewAppError("FileInfo.IsValid", "model.file_info.is_valid.scan_verdict.app_error", nil, "id="+fi.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(fi.ScanSignature) > FileScanSignatureMaxRunes {
+		return NewAppError("FileInfo.IsValid", "model.file_info.is_valid.scan_signature.app_error", nil, "id="+fi.Id, http.StatusBadRequest)
+	}
+
 	return nil
 }
 
+// isValidContentHash reports whether hash is a lowercase hex encoded SHA-256 digest.
+func isValidContentHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+
+	for _, r := range hash {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (fi *FileInfo) IsImage() bool {
 	return strings.HasPrefix(fi.MimeType, "image")
 }
@@ -6,6 +6,7 @@ package model
 import (
 	_ "image/gif"
 	_ "image/png"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -54,6 +55,39 @@ func TestFileInfoIsValid(t *testing.T) {
 		info.Path = "fake/path.png"
 	})
 
+	t.Run("Content hash must be a SHA-256 hex digest", func(t *testing.T) {
+		info.ContentHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		assert.Nil(t, info.IsValid())
+
+		info.ContentHash = "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"
+		assert.NotNil(t, info.IsValid(), "uppercase hash isn't valid")
+
+		info.ContentHash = "e3b0c442"
+		assert.NotNil(t, info.IsValid(), "short hash isn't valid")
+
+		info.ContentHash = ""
+	})
+
+	t.Run("Scan verdict must be known", func(t *testing.T) {
+		info.ScanVerdict = FileScanVerdictInfected
+		assert.Nil(t, info.IsValid())
+
+		info.ScanVerdict = "suspicious"
+		assert.NotNil(t, info.IsValid(), "unknown verdict isn't valid")
+
+		info.ScanVerdict = ""
+	})
+
+	t.Run("Scan signature must fit its column", func(t *testing.T) {
+		info.ScanSignature = strings.Repeat("é", FileScanSignatureMaxRunes)
+		assert.Nil(t, info.IsValid())
+
+		info.ScanSignature += "a"
+		assert.NotNil(t, info.IsValid(), "too long signature isn't valid")
+
+		info.ScanSignature = ""
+	})
+
 	t.Run("Creator ID for bookmarks is valid", func(t *testing.T) {
 		creatorId := info.CreatorId
 		info.CreatorId = BookmarkFileOwner
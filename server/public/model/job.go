@@ -44,6 +44,17 @@ const (
 	JobTypeDeleteDmsPreferencesMigration = "delete_dms_preferences_migration"
 	JobTypeMobileSessionMetadata         = "mobile_session_metadata"
 	JobTypeAccessControlSync             = "access_control_sync"
+	JobTypeOutgoingWebhookRetry          = "outgoing_webhook_retry"
+	JobTypeEmailBatching                 = "email_batching"
+	JobTypeEmailDigest                   = "email_digest"
+	JobTypeFileEncryptionRewrap          = "file_encryption_rewrap"
+	JobTypeFileDeduplication             = "file_deduplication"
+	JobTypeFileStorageMigration          = "file_storage_migration"
+	JobTypeRegenerateFilePreviews        = "regenerate_file_previews"
+	JobTypeEmbeddedSearchIndexing        = "embedded_search_indexing"
+	JobTypeSavedSearchWatch              = "saved_search_watch"
+	JobTypeSearchNgramBackfill           = "search_ngram_backfill"
+	JobTypePostEmbedding                 = "post_embedding"
 
 	JobStatusPending         = "pending"
 	JobStatusInProgress      = "in_progress"
@@ -76,6 +87,17 @@ var AllJobTypes = [...]string{
 	JobTypeCleanupDesktopTokens,
 	JobTypeRefreshMaterializedViews,
 	JobTypeMobileSessionMetadata,
+	JobTypeOutgoingWebhookRetry,
+	JobTypeEmailBatching,
+	JobTypeEmailDigest,
+	JobTypeFileEncryptionRewrap,
+	JobTypeFileDeduplication,
+	JobTypeFileStorageMigration,
+	JobTypeRegenerateFilePreviews,
+	JobTypeEmbeddedSearchIndexing,
+	JobTypeSavedSearchWatch,
+	JobTypeSearchNgramBackfill,
+	JobTypePostEmbedding,
 }
 
 type Job struct {
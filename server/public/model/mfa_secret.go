@@ -7,3 +7,8 @@ type MfaSecret struct {
 	Secret string `json:"secret"`
 	QRCode string `json:"qr_code"`
 }
+
+// MfaRecoveryCodes are single-use codes that can be used in place of an MFA token.
+type MfaRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
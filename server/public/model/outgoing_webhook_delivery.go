@@ -0,0 +1,182 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// OutgoingWebhookDeliveryStatusPending marks a failed delivery that is waiting to be retried.
+	OutgoingWebhookDeliveryStatusPending = "pending"
+	// OutgoingWebhookDeliveryStatusDelivered marks a previously failed delivery that eventually succeeded.
+	OutgoingWebhookDeliveryStatusDelivered = "delivered"
+	// OutgoingWebhookDeliveryStatusDead marks a delivery that exhausted its retries and will not be retried automatically.
+	OutgoingWebhookDeliveryStatusDead = "dead"
+
+	OutgoingWebhookDeliveryMaxAttempts    = 8
+	OutgoingWebhookDeliveryBaseRetryDelay = 30 * time.Second
+	OutgoingWebhookDeliveryMaxRetryDelay  = 6 * time.Hour
+
+	// OutgoingWebhookDeliveryRetention is how long delivered and dead deliveries are kept for inspection.
+	OutgoingWebhookDeliveryRetention = 30 * 24 * time.Hour
+
+	outgoingWebhookDeliveryLastErrorMaxRunes = 1024
+)
+
+// OutgoingWebhookDelivery records an outgoing webhook request to a single callback URL
+// that failed and is queued for retry.
+type OutgoingWebhookDelivery struct {
+	Id            string `json:"id"`
+	HookId        string `json:"hook_id"`
+	TeamId        string `json:"team_id"`
+	ChannelId     string `json:"channel_id"`
+	PostId        string `json:"post_id"`
+	CallbackURL   string `json:"callback_url"`
+	ContentType   string `json:"content_type"`
+	Payload       string `json:"payload"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error"`
+	NextAttemptAt int64  `json:"next_attempt_at"`
+	CreateAt      int64  `json:"create_at"`
+	UpdateAt      int64  `json:"update_at"`
+}
+
+func (d *OutgoingWebhookDelivery) Auditable() map[string]any {
+	return map[string]any{
+		"id":              d.Id,
+		"hook_id":         d.HookId,
+		"team_id":         d.TeamId,
+		"channel_id":      d.ChannelId,
+		"post_id":         d.PostId,
+		"callback_url":    d.CallbackURL,
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"create_at":       d.CreateAt,
+		"update_at":       d.UpdateAt,
+	}
+}
+
+func (d *OutgoingWebhookDelivery) PreSave() {
+	if d.Id == "" {
+		d.Id = NewId()
+	}
+
+	if d.Status == "" {
+		d.Status = OutgoingWebhookDeliveryStatusPending
+	}
+
+	d.CreateAt = GetMillis()
+	d.UpdateAt = d.CreateAt
+}
+
+func (d *OutgoingWebhookDelivery) PreUpdate() {
+	d.UpdateAt = GetMillis()
+}
+
+func (d *OutgoingWebhookDelivery) IsValid() *AppError {
+	if !IsValidId(d.Id) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(d.HookId) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.hook_id.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(d.TeamId) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.team_id.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.ChannelId != "" && !IsValidId(d.ChannelId) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.channel_id.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.PostId != "" && !IsValidId(d.PostId) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.post_id.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidHTTPURL(d.CallbackURL) || len(d.CallbackURL) > 1024 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.callback_url.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidOutgoingWebhookDeliveryStatus(d.Status) {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.status.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.CreateAt == 0 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.create_at.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	if d.UpdateAt == 0 {
+		return NewAppError("OutgoingWebhookDelivery.IsValid", "model.outgoing_hook_delivery.is_valid.update_at.app_error", nil, "id="+d.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// RecordFailure registers a failed attempt, scheduling the next retry with exponential
+// backoff or moving the delivery to the dead-letter state once all attempts are used.
+func (d *OutgoingWebhookDelivery) RecordFailure(err error, now time.Time) {
+	d.Attempts++
+	d.LastError = truncateOutgoingWebhookDeliveryError(err)
+
+	if d.Attempts >= OutgoingWebhookDeliveryMaxAttempts {
+		d.Status = OutgoingWebhookDeliveryStatusDead
+		d.NextAttemptAt = 0
+		return
+	}
+
+	d.Status = OutgoingWebhookDeliveryStatusPending
+	d.NextAttemptAt = GetMillisForTime(now.Add(OutgoingWebhookDeliveryRetryDelay(d.Attempts)))
+}
+
+// RecordSuccess marks the delivery as successfully redelivered.
+func (d *OutgoingWebhookDelivery) RecordSuccess() {
+	d.Attempts++
+	d.Status = OutgoingWebhookDeliveryStatusDelivered
+	d.LastError = ""
+	d.NextAttemptAt = 0
+}
+
+// OutgoingWebhookDeliveryRetryDelay returns how long to wait before retrying a delivery
+// that has failed the given number of times.
+func OutgoingWebhookDeliveryRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := OutgoingWebhookDeliveryBaseRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= OutgoingWebhookDeliveryMaxRetryDelay {
+			return OutgoingWebhookDeliveryMaxRetryDelay
+		}
+	}
+
+	return delay
+}
+
+func truncateOutgoingWebhookDeliveryError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	msg := []rune(err.Error())
+	if len(msg) > outgoingWebhookDeliveryLastErrorMaxRunes {
+		msg = msg[:outgoingWebhookDeliveryLastErrorMaxRunes]
+	}
+
+	return string(msg)
+}
+
+func IsValidOutgoingWebhookDeliveryStatus(status string) bool {
+	switch status {
+	case OutgoingWebhookDeliveryStatusPending, OutgoingWebhookDeliveryStatusDelivered, OutgoingWebhookDeliveryStatusDead:
+		return true
+	}
+	return false
+}
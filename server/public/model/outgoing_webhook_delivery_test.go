@@ -0,0 +1,77 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutgoingWebhookDeliveryIsValid(t *testing.T) {
+	d := OutgoingWebhookDelivery{
+		HookId:      NewId(),
+		TeamId:      NewId(),
+		ChannelId:   NewId(),
+		PostId:      NewId(),
+		CallbackURL: "http://nowhere.com",
+	}
+	d.PreSave()
+	require.Nil(t, d.IsValid())
+	assert.Equal(t, OutgoingWebhookDeliveryStatusPending, d.Status)
+
+	d.Status = "unknown"
+	require.NotNil(t, d.IsValid())
+	d.Status = OutgoingWebhookDeliveryStatusDead
+
+	d.CallbackURL = "nowhere"
+	require.NotNil(t, d.IsValid())
+	d.CallbackURL = "http://nowhere.com"
+
+	d.HookId = "junk"
+	require.NotNil(t, d.IsValid())
+	d.HookId = NewId()
+
+	d.ChannelId = ""
+	require.Nil(t, d.IsValid())
+}
+
+func TestOutgoingWebhookDeliveryRetryDelay(t *testing.T) {
+	assert.Equal(t, OutgoingWebhookDeliveryBaseRetryDelay, OutgoingWebhookDeliveryRetryDelay(0))
+	assert.Equal(t, OutgoingWebhookDeliveryBaseRetryDelay, OutgoingWebhookDeliveryRetryDelay(1))
+	assert.Equal(t, 2*OutgoingWebhookDeliveryBaseRetryDelay, OutgoingWebhookDeliveryRetryDelay(2))
+	assert.Equal(t, 8*OutgoingWebhookDeliveryBaseRetryDelay, OutgoingWebhookDeliveryRetryDelay(4))
+	assert.Equal(t, OutgoingWebhookDeliveryMaxRetryDelay, OutgoingWebhookDeliveryRetryDelay(100))
+}
+
+func TestOutgoingWebhookDeliveryRecordAttempts(t *testing.T) {
+	now := time.Now()
+	d := OutgoingWebhookDelivery{}
+
+	d.RecordFailure(errors.New("connection refused"), now)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, OutgoingWebhookDeliveryStatusPending, d.Status)
+	assert.Equal(t, "connection refused", d.LastError)
+	assert.Equal(t, GetMillisForTime(now.Add(OutgoingWebhookDeliveryBaseRetryDelay)), d.NextAttemptAt)
+
+	for d.Attempts < OutgoingWebhookDeliveryMaxAttempts-1 {
+		d.RecordFailure(errors.New("connection refused"), now)
+		assert.Equal(t, OutgoingWebhookDeliveryStatusPending, d.Status)
+	}
+
+	d.RecordFailure(errors.New(strings.Repeat("a", 2000)), now)
+	assert.Equal(t, OutgoingWebhookDeliveryMaxAttempts, d.Attempts)
+	assert.Equal(t, OutgoingWebhookDeliveryStatusDead, d.Status)
+	assert.Zero(t, d.NextAttemptAt)
+	assert.Len(t, d.LastError, outgoingWebhookDeliveryLastErrorMaxRunes)
+
+	d.RecordSuccess()
+	assert.Equal(t, OutgoingWebhookDeliveryMaxAttempts+1, d.Attempts)
+	assert.Equal(t, OutgoingWebhookDeliveryStatusDelivered, d.Status)
+	assert.Empty(t, d.LastError)
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// OutgoingWebhookSignatureHeader carries the HMAC-SHA256 signature of an outgoing webhook request.
+	OutgoingWebhookSignatureHeader = "X-Mattermost-Signature"
+	// OutgoingWebhookTimestampHeader carries the Unix time, in seconds, at which the request was signed.
+	OutgoingWebhookTimestampHeader = "X-Mattermost-Request-Timestamp"
+
+	OutgoingWebhookSignatureVersion = "v1"
+
+	// OutgoingWebhookSignatureTolerance is the default maximum age accepted by receivers
+	// verifying a signed request, to protect against replayed payloads.
+	OutgoingWebhookSignatureTolerance = 5 * time.Minute
+
+	OutgoingWebhookSigningSecretLength    = 32
+	OutgoingWebhookSigningSecretMaxLength = 64
+)
+
+var (
+	ErrOutgoingWebhookSignatureMissing   = errors.New("outgoing webhook signature or timestamp is missing")
+	ErrOutgoingWebhookSignatureMalformed = errors.New("outgoing webhook signature or timestamp is malformed")
+	ErrOutgoingWebhookSignatureExpired   = errors.New("outgoing webhook timestamp is outside the accepted tolerance")
+	ErrOutgoingWebhookSignatureMismatch  = errors.New("outgoing webhook signature does not match")
+)
+
+// NewOutgoingWebhookSigningSecret returns a new random secret suitable for signing outgoing webhook requests.
+func NewOutgoingWebhookSigningSecret() string {
+	return NewRandomString(OutgoingWebhookSigningSecretLength)
+}
+
+// SignOutgoingWebhookPayload computes the value of the OutgoingWebhookSignatureHeader for the given
+// raw request body. The signed content is "v1:<timestamp>:<body>", where timestamp is in Unix seconds,
+// and the result is formatted as "v1=<hex encoded HMAC-SHA256>".
+func SignOutgoingWebhookPayload(secret string, timestamp int64, body []byte) string {
+	return OutgoingWebhookSignatureVersion + "=" + hex.EncodeToString(computeOutgoingWebhookMAC(secret, timestamp, body))
+}
+
+// VerifyOutgoingWebhookSignature checks the signature and timestamp headers received with an outgoing
+// webhook request against the raw request body. Requests signed more than tolerance away from now are
+// rejected to prevent replays; a tolerance of zero uses OutgoingWebhookSignatureTolerance.
+func VerifyOutgoingWebhookSignature(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	return verifyOutgoingWebhookSignatureAt(secret, signature, timestamp, body, tolerance, time.Now())
+}
+
+func verifyOutgoingWebhookSignatureAt(secret, signature, timestamp string, body []byte, tolerance time.Duration, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrOutgoingWebhookSignatureMissing
+	}
+
+	if tolerance <= 0 {
+		tolerance = OutgoingWebhookSignatureTolerance
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrOutgoingWebhookSignatureMalformed
+	}
+
+	age := now.Sub(time.Unix(ts, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrOutgoingWebhookSignatureExpired
+	}
+
+	version, encoded, found := strings.Cut(signature, "=")
+	if !found || version != OutgoingWebhookSignatureVersion {
+		return ErrOutgoingWebhookSignatureMalformed
+	}
+
+	received, err := hex.DecodeString(encoded)
+	if err != nil {
+		return ErrOutgoingWebhookSignatureMalformed
+	}
+
+	if !hmac.Equal(received, computeOutgoingWebhookMAC(secret, ts, body)) {
+		return ErrOutgoingWebhookSignatureMismatch
+	}
+
+	return nil
+}
+
+func computeOutgoingWebhookMAC(secret string, timestamp int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(OutgoingWebhookSignatureVersion + ":" + strconv.FormatInt(timestamp, 10) + ":"))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
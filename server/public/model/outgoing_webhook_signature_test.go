@@ -0,0 +1,65 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutgoingWebhookSignature(t *testing.T) {
+	secret := NewOutgoingWebhookSigningSecret()
+	require.Len(t, secret, OutgoingWebhookSigningSecretLength)
+
+	body := []byte(`{"text":"hello"}`)
+	now := time.Now()
+	timestamp := now.Unix()
+	signature := SignOutgoingWebhookPayload(secret, timestamp, body)
+	ts := strconv.FormatInt(timestamp, 10)
+
+	t.Run("valid signature", func(t *testing.T) {
+		assert.NoError(t, verifyOutgoingWebhookSignatureAt(secret, signature, ts, body, 0, now))
+		assert.NoError(t, VerifyOutgoingWebhookSignature(secret, signature, ts, body, time.Minute))
+	})
+
+	t.Run("signature is deterministic", func(t *testing.T) {
+		assert.Equal(t, signature, SignOutgoingWebhookPayload(secret, timestamp, body))
+		assert.NotEqual(t, signature, SignOutgoingWebhookPayload(secret, timestamp+1, body))
+	})
+
+	t.Run("missing headers", func(t *testing.T) {
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, "", ts, body, 0, now), ErrOutgoingWebhookSignatureMissing)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, signature, "", body, 0, now), ErrOutgoingWebhookSignatureMissing)
+	})
+
+	t.Run("malformed headers", func(t *testing.T) {
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, signature, "abc", body, 0, now), ErrOutgoingWebhookSignatureMalformed)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, "v2=abcd", ts, body, 0, now), ErrOutgoingWebhookSignatureMalformed)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, "v1=zz", ts, body, 0, now), ErrOutgoingWebhookSignatureMalformed)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, "nosignature", ts, body, 0, now), ErrOutgoingWebhookSignatureMalformed)
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, signature, ts, []byte(`{"text":"bye"}`), 0, now), ErrOutgoingWebhookSignatureMismatch)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(NewOutgoingWebhookSigningSecret(), signature, ts, body, 0, now), ErrOutgoingWebhookSignatureMismatch)
+	})
+
+	t.Run("replayed request", func(t *testing.T) {
+		later := now.Add(OutgoingWebhookSignatureTolerance + time.Second)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, signature, ts, body, 0, later), ErrOutgoingWebhookSignatureExpired)
+		assert.NoError(t, verifyOutgoingWebhookSignatureAt(secret, signature, ts, body, time.Hour, later))
+	})
+
+	t.Run("timestamp from the future", func(t *testing.T) {
+		earlier := now.Add(-OutgoingWebhookSignatureTolerance - time.Second)
+		assert.ErrorIs(t, verifyOutgoingWebhookSignatureAt(secret, signature, ts, body, 0, earlier), ErrOutgoingWebhookSignatureExpired)
+	})
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+)
+
+// PendingEmailNotification is a post notification waiting to be sent to a user as part
+// of their next batched email.
+type PendingEmailNotification struct {
+	Id       string `json:"id"`
+	UserId   string `json:"user_id"`
+	PostId   string `json:"post_id"`
+	TeamName string `json:"team_name"`
+	CreateAt int64  `json:"create_at"`
+	// ClaimedAt is the time the notification was claimed to be sent, or 0 while it isn't.
+	// Claims expire, so that notifications claimed by a run that failed are sent again.
+	ClaimedAt int64 `json:"claimed_at"`
+}
+
+func (n *PendingEmailNotification) PreSave() {
+	if n.Id == "" {
+		n.Id = NewId()
+	}
+
+	if n.CreateAt == 0 {
+		n.CreateAt = GetMillis()
+	}
+}
+
+func (n *PendingEmailNotification) IsValid() *AppError {
+	if !IsValidId(n.Id) {
+		return NewAppError("PendingEmailNotification.IsValid", "model.pending_email_notification.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(n.UserId) {
+		return NewAppError("PendingEmailNotification.IsValid", "model.pending_email_notification.is_valid.user_id.app_error", nil, "id="+n.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(n.PostId) {
+		return NewAppError("PendingEmailNotification.IsValid", "model.pending_email_notification.is_valid.post_id.app_error", nil, "id="+n.Id, http.StatusBadRequest)
+	}
+
+	if n.TeamName == "" || len(n.TeamName) > TeamNameMaxLength {
+		return NewAppError("PendingEmailNotification.IsValid", "model.pending_email_notification.is_valid.team_name.app_error", nil, "id="+n.Id, http.StatusBadRequest)
+	}
+
+	if n.CreateAt == 0 {
+		return NewAppError("PendingEmailNotification.IsValid", "model.pending_email_notification.is_valid.create_at.app_error", nil, "id="+n.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingEmailNotificationIsValid(t *testing.T) {
+	valid := func() *PendingEmailNotification {
+		n := &PendingEmailNotification{
+			UserId:   NewId(),
+			PostId:   NewId(),
+			TeamName: "team",
+		}
+		n.PreSave()
+		return n
+	}
+
+	n := valid()
+	require.Nil(t, n.IsValid())
+	require.NotZero(t, n.CreateAt)
+
+	n = valid()
+	n.Id = "junk"
+	require.NotNil(t, n.IsValid())
+
+	n = valid()
+	n.UserId = ""
+	require.NotNil(t, n.IsValid())
+
+	n = valid()
+	n.PostId = "junk"
+	require.NotNil(t, n.IsValid())
+
+	n = valid()
+	n.TeamName = ""
+	require.NotNil(t, n.IsValid())
+
+	n = valid()
+	n.TeamName = strings.Repeat("a", TeamNameMaxLength+1)
+	require.NotNil(t, n.IsValid())
+
+	n = valid()
+	n.CreateAt = 0
+	require.NotNil(t, n.IsValid())
+}
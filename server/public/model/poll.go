@@ -0,0 +1,264 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	PollQuestionMaxRunes   = 1000
+	PollOptionTextMaxRunes = 300
+	PollMinOptions         = 2
+	PollMaxOptions         = 20
+)
+
+// PollOption is an option of a poll. Its tally is only set when the poll is returned with
+// its votes.
+type PollOption struct {
+	Id        string `json:"id"`
+	Text      string `json:"text"`
+	VoteCount int64  `json:"vote_count"`
+	// Voters are the IDs of the users who voted for the option. They are left empty for
+	// anonymous polls.
+	Voters []string `json:"voters,omitempty"`
+}
+
+// PollOptionList is the list of options of a poll, stored as JSON without their tally.
+type PollOptionList []*PollOption
+
+func (l *PollOptionList) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("expected []byte or string, got %T", value)
+	}
+}
+
+func (l PollOptionList) Value() (driver.Value, error) {
+	type storedOption struct {
+		Id   string `json:"id"`
+		Text string `json:"text"`
+	}
+
+	options := make([]storedOption, len(l))
+	for i, option := range l {
+		options[i] = storedOption{Id: option.Id, Text: option.Text}
+	}
+
+	j, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	return string(j), nil
+}
+
+// Poll is the poll of a post of type PostTypePoll, keyed by the ID of its post. The votes are
+// stored separately, and tallied when the poll is returned.
+type Poll struct {
+	PostId         string         `json:"post_id"`
+	Question       string         `json:"question"`
+	Options        PollOptionList `json:"options"`
+	Anonymous      bool           `json:"anonymous"`
+	MultipleChoice bool           `json:"multiple_choice"`
+	CreateAt       int64          `json:"create_at"`
+	UpdateAt       int64          `json:"update_at"`
+	// ClosedAt is the time the poll was closed to new votes, or 0 while it is open.
+	ClosedAt int64 `json:"closed_at"`
+
+	// VoterCount is the number of users who voted, set with the tally.
+	VoterCount int64 `json:"voter_count"`
+	// MyVotes are the IDs of the options voted for by the user the poll is returned to.
+	MyVotes []string `json:"my_votes,omitempty"`
+}
+
+// PollVote is the vote of a user for an option of a poll. Users have a single vote per poll,
+// or one per option of multiple choice polls.
+type PollVote struct {
+	PostId   string `json:"post_id"`
+	OptionId string `json:"option_id"`
+	UserId   string `json:"user_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// PollRequest is the request to post a poll to a channel, or to a thread when RootId is set.
+type PollRequest struct {
+	ChannelId      string   `json:"channel_id"`
+	RootId         string   `json:"root_id"`
+	Question       string   `json:"question"`
+	Options        []string `json:"options"`
+	Anonymous      bool     `json:"anonymous"`
+	MultipleChoice bool     `json:"multiple_choice"`
+}
+
+// PollVoteRequest is the request to vote for options of a poll, replacing the previous votes
+// of the user.
+type PollVoteRequest struct {
+	OptionIds []string `json:"option_ids"`
+}
+
+func (r *PollRequest) Auditable() map[string]any {
+	return map[string]any{
+		"channel_id":      r.ChannelId,
+		"root_id":         r.RootId,
+		"options":         len(r.Options),
+		"anonymous":       r.Anonymous,
+		"multiple_choice": r.MultipleChoice,
+	}
+}
+
+func (p *Poll) Auditable() map[string]any {
+	return map[string]any{
+		"post_id":         p.PostId,
+		"options":         len(p.Options),
+		"anonymous":       p.Anonymous,
+		"multiple_choice": p.MultipleChoice,
+		"create_at":       p.CreateAt,
+		"update_at":       p.UpdateAt,
+		"closed_at":       p.ClosedAt,
+	}
+}
+
+// ToPoll returns the poll of the request, to be saved once its post is created.
+func (r *PollRequest) ToPoll() *Poll {
+	poll := &Poll{
+		Question:       strings.TrimSpace(r.Question),
+		Anonymous:      r.Anonymous,
+		MultipleChoice: r.MultipleChoice,
+	}
+	for _, text := range r.Options {
+		poll.Options = append(poll.Options, &PollOption{Text: strings.TrimSpace(text)})
+	}
+	return poll
+}
+
+func (p *Poll) PreSave() {
+	for _, option := range p.Options {
+		if option.Id == "" {
+			option.Id = NewId()
+		}
+	}
+
+	if p.CreateAt == 0 {
+		p.CreateAt = GetMillis()
+	}
+	p.UpdateAt = p.CreateAt
+}
+
+func (p *Poll) IsClosed() bool {
+	return p.ClosedAt != 0
+}
+
+func (p *Poll) IsValid() *AppError {
+	if !IsValidId(p.PostId) {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if p.Question == "" || utf8.RuneCountInString(p.Question) > PollQuestionMaxRunes {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.question.app_error", map[string]any{"MaxLength": PollQuestionMaxRunes}, "post_id="+p.PostId, http.StatusBadRequest)
+	}
+
+	if len(p.Options) < PollMinOptions || len(p.Options) > PollMaxOptions {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.options.app_error", map[string]any{"Min": PollMinOptions, "Max": PollMaxOptions}, "post_id="+p.PostId, http.StatusBadRequest)
+	}
+
+	texts := make(map[string]bool, len(p.Options))
+	for _, option := range p.Options {
+		if !IsValidId(option.Id) {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.option_id.app_error", nil, "post_id="+p.PostId, http.StatusBadRequest)
+		}
+
+		if option.Text == "" || utf8.RuneCountInString(option.Text) > PollOptionTextMaxRunes {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.option_text.app_error", map[string]any{"MaxLength": PollOptionTextMaxRunes}, "post_id="+p.PostId, http.StatusBadRequest)
+		}
+
+		if texts[option.Text] {
+			return NewAppError("Poll.IsValid", "model.poll.is_valid.duplicate_option.app_error", map[string]any{"Option": option.Text}, "post_id="+p.PostId, http.StatusBadRequest)
+		}
+		texts[option.Text] = true
+	}
+
+	if p.CreateAt == 0 {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.create_at.app_error", nil, "post_id="+p.PostId, http.StatusBadRequest)
+	}
+
+	if p.UpdateAt == 0 {
+		return NewAppError("Poll.IsValid", "model.poll.is_valid.update_at.app_error", nil, "post_id="+p.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// IsValidVote checks that the options are options of the poll, and that a single one is voted
+// for unless the poll is multiple choice.
+func (p *Poll) IsValidVote(optionIDs []string) *AppError {
+	if len(optionIDs) == 0 || (!p.MultipleChoice && len(optionIDs) > 1) {
+		return NewAppError("Poll.IsValidVote", "model.poll.is_valid_vote.count.app_error", nil, "post_id="+p.PostId, http.StatusBadRequest)
+	}
+
+	for i, optionID := range optionIDs {
+		if slices.Contains(optionIDs[:i], optionID) || !slices.ContainsFunc(p.Options, func(option *PollOption) bool { return option.Id == optionID }) {
+			return NewAppError("Poll.IsValidVote", "model.poll.is_valid_vote.option.app_error", nil, "post_id="+p.PostId+", option_id="+optionID, http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
+// SetTally sets the vote counts of the options from the votes of the poll, and the options
+// voted for by the given user. The voters are only set when the poll isn't anonymous.
+func (p *Poll) SetTally(votes []*PollVote, userID string) {
+	options := make(map[string]*PollOption, len(p.Options))
+	for _, option := range p.Options {
+		option.VoteCount = 0
+		option.Voters = nil
+		options[option.Id] = option
+	}
+
+	voters := map[string]bool{}
+	p.MyVotes = nil
+	for _, vote := range votes {
+		option, ok := options[vote.OptionId]
+		if !ok {
+			continue
+		}
+
+		option.VoteCount++
+		if !p.Anonymous {
+			option.Voters = append(option.Voters, vote.UserId)
+		}
+		voters[vote.UserId] = true
+
+		if userID != "" && vote.UserId == userID {
+			p.MyVotes = append(p.MyVotes, vote.OptionId)
+		}
+	}
+	p.VoterCount = int64(len(voters))
+}
+
+// Clone returns a copy of the poll and its options.
+func (p *Poll) Clone() *Poll {
+	pCopy := *p
+	pCopy.Options = make(PollOptionList, len(p.Options))
+	for i, option := range p.Options {
+		optionCopy := *option
+		optionCopy.Voters = slices.Clone(option.Voters)
+		pCopy.Options[i] = &optionCopy
+	}
+	pCopy.MyVotes = slices.Clone(p.MyVotes)
+	return &pCopy
+}
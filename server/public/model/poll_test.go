@@ -0,0 +1,135 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollRequestToPoll(t *testing.T) {
+	r := &PollRequest{
+		ChannelId:      NewId(),
+		Question:       " Lunch? ",
+		Options:        []string{" Pizza ", "Sushi"},
+		MultipleChoice: true,
+	}
+
+	p := r.ToPoll()
+	p.PostId = NewId()
+	p.PreSave()
+
+	assert.Equal(t, "Lunch?", p.Question)
+	require.Len(t, p.Options, 2)
+	assert.Equal(t, "Pizza", p.Options[0].Text)
+	assert.True(t, IsValidId(p.Options[0].Id))
+	assert.True(t, p.MultipleChoice)
+	assert.False(t, p.Anonymous)
+	require.NotZero(t, p.CreateAt)
+	require.Equal(t, p.CreateAt, p.UpdateAt)
+	require.Nil(t, p.IsValid())
+}
+
+func TestPollIsValid(t *testing.T) {
+	valid := func() *Poll {
+		p := (&PollRequest{Question: "Lunch?", Options: []string{"Pizza", "Sushi"}}).ToPoll()
+		p.PostId = NewId()
+		p.PreSave()
+		return p
+	}
+
+	require.Nil(t, valid().IsValid())
+
+	for name, test := range map[string]struct {
+		update func(p *Poll)
+		errID  string
+	}{
+		"invalid post id":   {func(p *Poll) { p.PostId = "junk" }, "model.poll.is_valid.post_id.app_error"},
+		"empty question":    {func(p *Poll) { p.Question = "" }, "model.poll.is_valid.question.app_error"},
+		"question too long": {func(p *Poll) { p.Question = strings.Repeat("a", PollQuestionMaxRunes+1) }, "model.poll.is_valid.question.app_error"},
+		"too few options":   {func(p *Poll) { p.Options = p.Options[:1] }, "model.poll.is_valid.options.app_error"},
+		"invalid option id": {func(p *Poll) { p.Options[0].Id = "junk" }, "model.poll.is_valid.option_id.app_error"},
+		"empty option":      {func(p *Poll) { p.Options[0].Text = "" }, "model.poll.is_valid.option_text.app_error"},
+		"duplicate option":  {func(p *Poll) { p.Options[1].Text = p.Options[0].Text }, "model.poll.is_valid.duplicate_option.app_error"},
+		"missing create at": {func(p *Poll) { p.CreateAt = 0 }, "model.poll.is_valid.create_at.app_error"},
+		"missing update at": {func(p *Poll) { p.UpdateAt = 0 }, "model.poll.is_valid.update_at.app_error"},
+		"too many options": {func(p *Poll) {
+			for i := len(p.Options); i <= PollMaxOptions; i++ {
+				p.Options = append(p.Options, &PollOption{Id: NewId(), Text: NewId()})
+			}
+		}, "model.poll.is_valid.options.app_error"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			p := valid()
+			test.update(p)
+			appErr := p.IsValid()
+			require.NotNil(t, appErr)
+			assert.Equal(t, test.errID, appErr.Id)
+		})
+	}
+}
+
+func TestPollIsValidVote(t *testing.T) {
+	p := (&PollRequest{Question: "Lunch?", Options: []string{"Pizza", "Sushi", "Salad"}}).ToPoll()
+	p.PostId = NewId()
+	p.PreSave()
+	pizza, sushi := p.Options[0].Id, p.Options[1].Id
+
+	require.Nil(t, p.IsValidVote([]string{pizza}))
+	require.NotNil(t, p.IsValidVote(nil))
+	require.NotNil(t, p.IsValidVote([]string{pizza, sushi}))
+	require.NotNil(t, p.IsValidVote([]string{NewId()}))
+
+	p.MultipleChoice = true
+	require.Nil(t, p.IsValidVote([]string{pizza, sushi}))
+	require.NotNil(t, p.IsValidVote([]string{pizza, pizza}))
+}
+
+func TestPollSetTally(t *testing.T) {
+	p := (&PollRequest{Question: "Lunch?", Options: []string{"Pizza", "Sushi"}, MultipleChoice: true}).ToPoll()
+	p.PostId = NewId()
+	p.PreSave()
+	pizza, sushi := p.Options[0].Id, p.Options[1].Id
+	user1, user2 := NewId(), NewId()
+
+	votes := []*PollVote{
+		{PostId: p.PostId, OptionId: pizza, UserId: user1},
+		{PostId: p.PostId, OptionId: sushi, UserId: user1},
+		{PostId: p.PostId, OptionId: pizza, UserId: user2},
+		{PostId: p.PostId, OptionId: NewId(), UserId: user2},
+	}
+
+	p.SetTally(votes, user1)
+	assert.Equal(t, int64(2), p.VoterCount)
+	assert.Equal(t, int64(2), p.Options[0].VoteCount)
+	assert.Equal(t, []string{user1, user2}, p.Options[0].Voters)
+	assert.Equal(t, int64(1), p.Options[1].VoteCount)
+	assert.ElementsMatch(t, []string{pizza, sushi}, p.MyVotes)
+
+	t.Run("anonymous polls don't have voters", func(t *testing.T) {
+		p.Anonymous = true
+		p.SetTally(votes, "")
+		assert.Equal(t, int64(2), p.Options[0].VoteCount)
+		assert.Empty(t, p.Options[0].Voters)
+		assert.Empty(t, p.MyVotes)
+	})
+}
+
+func TestPollOptionListValue(t *testing.T) {
+	options := PollOptionList{{Id: NewId(), Text: "Pizza", VoteCount: 3, Voters: []string{NewId()}}}
+
+	value, err := options.Value()
+	require.NoError(t, err)
+	assert.NotContains(t, value, "vote_count")
+
+	var scanned PollOptionList
+	require.NoError(t, scanned.Scan(value))
+	require.Len(t, scanned, 1)
+	assert.Equal(t, options[0].Id, scanned[0].Id)
+	assert.Equal(t, "Pizza", scanned[0].Text)
+	assert.Zero(t, scanned[0].VoteCount)
+}
@@ -54,6 +54,7 @@ const (
 	PostTypeMe                   = "me"
 	PostCustomTypePrefix         = "custom_"
 	PostTypeReminder             = "reminder"
+	PostTypePoll                 = "poll"
 
 	PostFileidsMaxRunes   = 300
 	PostFilenamesMaxRunes = 4000
@@ -501,6 +502,7 @@ func (o *Post) IsValid(maxPostSize int) *AppError {
 		PostTypeChangeChannelPrivacy,
 		PostTypeAddBotTeamsChannels,
 		PostTypeReminder,
+		PostTypePoll,
 		PostTypeMe,
 		PostTypeWrangler,
 		PostTypeGMConvertedToChannel:
@@ -0,0 +1,17 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+// PostEmbedding is the embedding of the message of a post, used by the semantic search.
+type PostEmbedding struct {
+	PostId    string
+	ChannelId string
+	// Model is the embedding model the embedding was computed with. Only the embeddings of the
+	// configured model are searched.
+	Model string
+	// PostEditAt is the edit time of the post when it was embedded, to only embed it again
+	// when its message is edited.
+	PostEditAt int64
+	Embedding  []float32
+}
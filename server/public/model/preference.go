@@ -65,6 +65,8 @@ const (
 	// PreferenceCategoryNotifications is used to store the user's notification settings.
 	// Possible Name values are:
 	// - PreferenceNameEmailInterval
+	// - PreferenceNameEmailDigest
+	// - PreferenceNameEmailDigestLastSentAt
 	PreferenceCategoryNotifications = "notifications"
 
 	// Deprecated: PreferenceRecommendedNextSteps is not used anymore.
@@ -115,6 +117,13 @@ const (
 	PreferenceEmailIntervalHourAsSeconds     = "3600"
 	PreferenceCloudUserEphemeralInfo         = "cloud_user_ephemeral_info"
 
+	PreferenceNameEmailDigest           = "email_digest"
+	PreferenceNameEmailDigestLastSentAt = "email_digest_last_sent_at"
+
+	PreferenceEmailDigestOff    = "off"
+	PreferenceEmailDigestDaily  = "daily"
+	PreferenceEmailDigestWeekly = "weekly"
+
 	PreferenceNameRecommendedNextStepsHide = "hide"
 )
 
@@ -158,6 +167,12 @@ func (o *Preference) IsValid() *AppError {
 		}
 	}
 
+	if o.Category == PreferenceCategoryNotifications && o.Name == PreferenceNameEmailDigest {
+		if o.Value != PreferenceEmailDigestOff && o.Value != PreferenceEmailDigestDaily && o.Value != PreferenceEmailDigestWeekly {
+			return NewAppError("Preference.IsValid", "model.preference.is_valid.email_digest.app_error", nil, "value="+o.Value, http.StatusBadRequest)
+		}
+	}
+
 	return nil
 }
 
@@ -98,6 +98,20 @@ func TestPreferenceIsValid(t *testing.T) {
 		preference.Value = "-10"
 		require.NotNil(t, preference.IsValid())
 	})
+
+	t.Run("email_digest has a valid value", func(t *testing.T) {
+		preference.Category = PreferenceCategoryNotifications
+		preference.Name = PreferenceNameEmailDigest
+		preference.Value = PreferenceEmailDigestWeekly
+		require.Nil(t, preference.IsValid())
+	})
+
+	t.Run("email_digest has an invalid value", func(t *testing.T) {
+		preference.Category = PreferenceCategoryNotifications
+		preference.Name = PreferenceNameEmailDigest
+		preference.Value = "monthly"
+		require.NotNil(t, preference.IsValid())
+	})
 }
 
 func TestPreferencePreUpdate(t *testing.T) {
@@ -0,0 +1,160 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	SavedSearchNameMaxRunes  = 64
+	SavedSearchTermsMaxRunes = 1024
+	SavedSearchMaxPerUser    = 50
+)
+
+// SearchParamsList is the list of search params of a saved search, stored as JSON.
+type SearchParamsList []*SearchParams
+
+func (l *SearchParamsList) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("expected []byte or string, got %T", value)
+	}
+}
+
+func (l SearchParamsList) Value() (driver.Value, error) {
+	j, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(j), nil
+}
+
+// SavedSearch is a search saved by a user to run it again later. Its params are parsed from
+// its terms when saved. When watched, the user is notified of the new posts it matches.
+type SavedSearch struct {
+	Id     string `json:"id"`
+	UserId string `json:"user_id"`
+	// TeamId restricts the search to a team, it is empty to search all the teams of the user.
+	TeamId         string           `json:"team_id"`
+	Name           string           `json:"name"`
+	Terms          string           `json:"terms"`
+	IsOrSearch     bool             `json:"is_or_search"`
+	TimeZoneOffset int              `json:"time_zone_offset"`
+	Params         SearchParamsList `json:"params"`
+	Watch          bool             `json:"watch"`
+	CreateAt       int64            `json:"create_at"`
+	UpdateAt       int64            `json:"update_at"`
+}
+
+type SavedSearchPatch struct {
+	Name           *string `json:"name"`
+	Terms          *string `json:"terms"`
+	IsOrSearch     *bool   `json:"is_or_search"`
+	TimeZoneOffset *int    `json:"time_zone_offset"`
+	Watch          *bool   `json:"watch"`
+}
+
+func (s *SavedSearch) Auditable() map[string]any {
+	return map[string]any{
+		"id":        s.Id,
+		"user_id":   s.UserId,
+		"team_id":   s.TeamId,
+		"watch":     s.Watch,
+		"create_at": s.CreateAt,
+		"update_at": s.UpdateAt,
+	}
+}
+
+func (s *SavedSearch) PreSave() {
+	if s.Id == "" {
+		s.Id = NewId()
+	}
+
+	s.CreateAt = GetMillis()
+	s.UpdateAt = s.CreateAt
+	s.parseTerms()
+}
+
+func (s *SavedSearch) PreUpdate() {
+	s.UpdateAt = GetMillis()
+	s.parseTerms()
+}
+
+// parseTerms sets the params of the search from its terms, as they are parsed when searching.
+func (s *SavedSearch) parseTerms() {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Terms = strings.TrimSpace(s.Terms)
+	s.Params = ParseSearchParams(s.Terms, s.TimeZoneOffset)
+	for _, params := range s.Params {
+		params.OrTerms = s.IsOrSearch
+	}
+}
+
+func (s *SavedSearch) Patch(patch *SavedSearchPatch) {
+	if patch.Name != nil {
+		s.Name = *patch.Name
+	}
+
+	if patch.Terms != nil {
+		s.Terms = *patch.Terms
+	}
+
+	if patch.IsOrSearch != nil {
+		s.IsOrSearch = *patch.IsOrSearch
+	}
+
+	if patch.TimeZoneOffset != nil {
+		s.TimeZoneOffset = *patch.TimeZoneOffset
+	}
+
+	if patch.Watch != nil {
+		s.Watch = *patch.Watch
+	}
+}
+
+func (s *SavedSearch) IsValid() *AppError {
+	if !IsValidId(s.Id) {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(s.UserId) {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.user_id.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if s.TeamId != "" && !IsValidId(s.TeamId) {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.team_id.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if s.Name == "" || utf8.RuneCountInString(s.Name) > SavedSearchNameMaxRunes {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.name.app_error", map[string]any{"MaxLength": SavedSearchNameMaxRunes}, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(s.Terms) > SavedSearchTermsMaxRunes || len(s.Params) == 0 {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.terms.app_error", map[string]any{"MaxLength": SavedSearchTermsMaxRunes}, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if s.CreateAt == 0 {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.create_at.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	if s.UpdateAt == 0 {
+		return NewAppError("SavedSearch.IsValid", "model.saved_search.is_valid.update_at.app_error", nil, "id="+s.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedSearchPreSave(t *testing.T) {
+	s := &SavedSearch{
+		UserId:         NewId(),
+		Name:           " Outages ",
+		Terms:          " outage is:pinned #prod ",
+		IsOrSearch:     true,
+		TimeZoneOffset: 3600,
+	}
+	s.PreSave()
+
+	require.True(t, IsValidId(s.Id))
+	require.NotZero(t, s.CreateAt)
+	require.Equal(t, s.CreateAt, s.UpdateAt)
+	assert.Equal(t, "Outages", s.Name)
+	assert.Equal(t, "outage is:pinned #prod", s.Terms)
+
+	require.Len(t, s.Params, 2)
+	assert.Equal(t, "outage", s.Params[0].Terms)
+	assert.Equal(t, "#prod", s.Params[1].Terms)
+	for _, params := range s.Params {
+		assert.True(t, params.OrTerms)
+		assert.Equal(t, 3600, params.TimeZoneOffset)
+		assert.Equal(t, NewPointer(true), params.IsPinned)
+	}
+
+	s.Patch(&SavedSearchPatch{Terms: NewPointer("customer"), IsOrSearch: NewPointer(false), Watch: NewPointer(true)})
+	s.PreUpdate()
+	require.Len(t, s.Params, 1)
+	assert.Equal(t, "customer", s.Params[0].Terms)
+	assert.False(t, s.Params[0].OrTerms)
+	assert.True(t, s.Watch)
+	assert.Equal(t, "Outages", s.Name)
+}
+
+func TestSavedSearchIsValid(t *testing.T) {
+	valid := func() *SavedSearch {
+		s := &SavedSearch{
+			UserId: NewId(),
+			Name:   "Outages",
+			Terms:  "outage",
+		}
+		s.PreSave()
+		return s
+	}
+
+	s := valid()
+	require.Nil(t, s.IsValid())
+
+	s = valid()
+	s.Id = "junk"
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.UserId = ""
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.TeamId = "junk"
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.TeamId = NewId()
+	require.Nil(t, s.IsValid())
+
+	s = valid()
+	s.Name = ""
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.Name = strings.Repeat("a", SavedSearchNameMaxRunes+1)
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.Terms = " "
+	s.PreUpdate()
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.Terms = strings.Repeat("a", SavedSearchTermsMaxRunes+1)
+	s.PreUpdate()
+	require.NotNil(t, s.IsValid())
+
+	s = valid()
+	s.CreateAt = 0
+	require.NotNil(t, s.IsValid())
+}
+
+func TestSearchParamsListScan(t *testing.T) {
+	list := SearchParamsList{{Terms: "outage", IsPinned: NewPointer(true)}}
+	value, err := list.Value()
+	require.NoError(t, err)
+
+	var scanned SearchParamsList
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, list, scanned)
+
+	require.NoError(t, scanned.Scan(nil))
+	require.Error(t, scanned.Scan(42))
+}
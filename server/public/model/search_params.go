@@ -6,12 +6,15 @@ package model
 import (
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 )
 
 var searchTermPuncStart = regexp.MustCompile(`^[^\pL\d\s#"]+`)
 var searchTermPuncEnd = regexp.MustCompile(`[^\pL\p{M}\d\s*"]+$`)
+var searchLinkRegex = regexp.MustCompile(`(?i)(https?|ftp)://|www\.`)
 
 type SearchParams struct {
 	Terms                  string   `json:"terms,omitempty"`
@@ -32,6 +35,15 @@ type SearchParams struct {
 	OrTerms                bool     `json:"or_terms,omitempty"`
 	IncludeDeletedChannels bool     `json:"include_deleted_channels,omitempty"`
 	TimeZoneOffset         int      `json:"timezone_offset,omitempty"`
+	// Filters on the attributes of the posts, set by the is:, has: and in:thread modifiers and
+	// by from:bot. Nil doesn't filter, and false only matches the posts without the attribute.
+	IsPinned     *bool `json:"is_pinned,omitempty"`
+	HasFile      *bool `json:"has_file,omitempty"`
+	HasLink      *bool `json:"has_link,omitempty"`
+	HasReactions *bool `json:"has_reactions,omitempty"`
+	// InThread matches the replies of threads.
+	InThread *bool `json:"in_thread,omitempty"`
+	FromBots *bool `json:"from_bots,omitempty"`
 	// True if this search doesn't originate from a "current user".
 	SearchWithoutUserId bool   `json:"search_without_user_id,omitempty"`
 	Modifier            string `json:"modifier"`
@@ -109,7 +121,155 @@ func (p *SearchParams) GetExcludedDateMillis() (int64, int64) {
 	return GetStartOfDayMillis(date, p.TimeZoneOffset), GetEndOfDayMillis(date, p.TimeZoneOffset)
 }
 
-var searchFlags = [...]string{"from", "channel", "in", "before", "after", "on", "ext"}
+// HasPostAttributeFilters returns whether the posts are filtered on their attributes.
+func (p *SearchParams) HasPostAttributeFilters() bool {
+	return p.IsPinned != nil || p.HasFile != nil || p.HasLink != nil ||
+		p.HasReactions != nil || p.InThread != nil || p.FromBots != nil
+}
+
+// MatchesPost returns whether a post matches the params, as a search would find it. The
+// channel and user filters must hold IDs. Terms match whole words of the message, ignoring
+// case, and a trailing wildcard matches the words they start. When stem isn't nil, the other
+// words match when stem reduces them to the same stem, as the search engines do.
+func (p *SearchParams) MatchesPost(post *Post, stem func(word string) string) bool {
+	if !p.MatchesPostFilters(post) {
+		return false
+	}
+
+	words := searchTextWords(post.Message)
+	var stems []string
+	if stem != nil {
+		stems = make([]string, len(words))
+		for i, word := range words {
+			stems[i] = stem(word)
+		}
+	}
+	matchTerm := func(term string) bool {
+		return matchSearchTerm(term, words, stems, stem)
+	}
+	if p.IsHashtag {
+		hashtags := strings.Fields(post.Hashtags)
+		matchTerm = func(term string) bool {
+			return slices.ContainsFunc(hashtags, func(hashtag string) bool {
+				return strings.EqualFold(hashtag, term)
+			})
+		}
+	}
+
+	if terms := splitWords(p.Terms); len(terms) > 0 {
+		if p.OrTerms && !slices.ContainsFunc(terms, matchTerm) {
+			return false
+		}
+		if !p.OrTerms && slices.ContainsFunc(terms, func(term string) bool { return !matchTerm(term) }) {
+			return false
+		}
+	}
+
+	return !slices.ContainsFunc(splitWords(p.ExcludedTerms), matchTerm)
+}
+
+// MatchesPostFilters returns whether a post matches the channel, user, date and attribute
+// filters of the params, whatever its message. The channel and user filters must hold IDs.
+func (p *SearchParams) MatchesPostFilters(post *Post) bool {
+	if len(p.InChannels) > 0 && !slices.Contains(p.InChannels, post.ChannelId) {
+		return false
+	}
+	if slices.Contains(p.ExcludedChannels, post.ChannelId) {
+		return false
+	}
+	if len(p.FromUsers) > 0 && !slices.Contains(p.FromUsers, post.UserId) {
+		return false
+	}
+	if slices.Contains(p.ExcludedUsers, post.UserId) {
+		return false
+	}
+
+	return p.matchesPostDates(post.CreateAt) && p.matchesPostAttributes(post)
+}
+
+func (p *SearchParams) matchesPostDates(createAt int64) bool {
+	if p.OnDate != "" {
+		start, end := p.GetOnDateMillis()
+		return createAt >= start && createAt <= end
+	}
+
+	if p.ExcludedDate != "" {
+		start, end := p.GetExcludedDateMillis()
+		if createAt >= start && createAt <= end {
+			return false
+		}
+	}
+	if p.AfterDate != "" && createAt < p.GetAfterDateMillis() {
+		return false
+	}
+	if p.BeforeDate != "" && createAt > p.GetBeforeDateMillis() {
+		return false
+	}
+	if p.ExcludedAfterDate != "" && createAt >= p.GetExcludedAfterDateMillis() {
+		return false
+	}
+	if p.ExcludedBeforeDate != "" && createAt <= p.GetExcludedBeforeDateMillis() {
+		return false
+	}
+
+	return true
+}
+
+func (p *SearchParams) matchesPostAttributes(post *Post) bool {
+	matches := func(filter *bool, value bool) bool {
+		return filter == nil || *filter == value
+	}
+
+	return matches(p.IsPinned, post.IsPinned) &&
+		matches(p.HasFile, len(post.FileIds) > 0 || len(post.Filenames) > 0) &&
+		matches(p.HasLink, searchLinkRegex.MatchString(post.Message)) &&
+		matches(p.HasReactions, post.HasReactions) &&
+		matches(p.InThread, post.RootId != "") &&
+		matches(p.FromBots, post.GetProp(PostPropsFromBot) == "true")
+}
+
+// searchTextWords splits a text in lower case words.
+func searchTextWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// matchSearchTerm returns whether a term, or a quoted phrase, appears in the words of a text.
+// The stems are those of the words, reduced by stem when it isn't nil.
+func matchSearchTerm(term string, words, stems []string, stem func(word string) string) bool {
+	term = strings.Trim(term, `"`)
+	prefix := strings.HasSuffix(term, "*")
+	termWords := searchTextWords(term)
+	if len(termWords) == 0 {
+		return true
+	}
+
+	for i := 0; i+len(termWords) <= len(words); i++ {
+		found := true
+		for j, termWord := range termWords {
+			var matches bool
+			if prefix && j == len(termWords)-1 {
+				matches = strings.HasPrefix(words[i+j], termWord)
+			} else if stem != nil {
+				matches = stems[i+j] == stem(termWord)
+			} else {
+				matches = words[i+j] == termWord
+			}
+			if !matches {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+var searchFlags = [...]string{"from", "channel", "in", "before", "after", "on", "ext", "is", "has"}
 
 type flag struct {
 	name    string
@@ -232,6 +392,43 @@ func parseSearchFlags(input []string) ([]searchWord, []flag) {
 func ParseSearchParams(text string, timeZoneOffset int) []*SearchParams {
 	words, flags := parseSearchFlags(splitWords(text))
 
+	var isPinned, hasFile, hasLink, hasReactions, inThread, fromBots *bool
+	otherFlags := []flag{}
+	for _, flag := range flags {
+		var attribute **bool
+		if flag.name == "in" && strings.EqualFold(flag.value, "thread") {
+			// in:thread always filters the replies. A channel named thread can be searched with
+			// channel:thread instead.
+			attribute = &inThread
+		} else if flag.name == "from" && strings.EqualFold(flag.value, "bot") {
+			attribute = &fromBots
+		} else if flag.name == "is" {
+			if strings.EqualFold(flag.value, "pinned") {
+				attribute = &isPinned
+			}
+		} else if flag.name == "has" {
+			switch strings.ToLower(flag.value) {
+			case "file", "files", "attachment", "attachments":
+				attribute = &hasFile
+			case "link", "links":
+				attribute = &hasLink
+			case "reaction", "reactions":
+				attribute = &hasReactions
+			}
+		} else {
+			otherFlags = append(otherFlags, flag)
+			continue
+		}
+
+		if attribute != nil {
+			*attribute = NewPointer(!flag.exclude)
+		} else {
+			// The unknown is: and has: values are searched for, as they were before these modifiers existed.
+			words = append(words, searchWord{flag.name + ":" + flag.value, flag.exclude})
+		}
+	}
+	flags = otherFlags
+
 	hashtagTermList := []string{}
 	excludedHashtagTermList := []string{}
 	plainTermList := []string{}
@@ -331,6 +528,12 @@ func ParseSearchParams(text string, timeZoneOffset int) []*SearchParams {
 			OnDate:             onDate,
 			ExcludedDate:       excludedDate,
 			TimeZoneOffset:     timeZoneOffset,
+			IsPinned:           isPinned,
+			HasFile:            hasFile,
+			HasLink:            hasLink,
+			HasReactions:       hasReactions,
+			InThread:           inThread,
+			FromBots:           fromBots,
 		})
 	}
 
@@ -352,6 +555,12 @@ func ParseSearchParams(text string, timeZoneOffset int) []*SearchParams {
 			OnDate:             onDate,
 			ExcludedDate:       excludedDate,
 			TimeZoneOffset:     timeZoneOffset,
+			IsPinned:           isPinned,
+			HasFile:            hasFile,
+			HasLink:            hasLink,
+			HasReactions:       hasReactions,
+			InThread:           inThread,
+			FromBots:           fromBots,
 		})
 	}
 
@@ -363,7 +572,9 @@ func ParseSearchParams(text string, timeZoneOffset int) []*SearchParams {
 			len(extensions) != 0 || len(excludedExtensions) != 0 ||
 			afterDate != "" || excludedAfterDate != "" ||
 			beforeDate != "" || excludedBeforeDate != "" ||
-			onDate != "" || excludedDate != "") {
+			onDate != "" || excludedDate != "" ||
+			isPinned != nil || hasFile != nil || hasLink != nil ||
+			hasReactions != nil || inThread != nil || fromBots != nil) {
 		paramsList = append(paramsList, &SearchParams{
 			Terms:              "",
 			ExcludedTerms:      "",
@@ -381,6 +592,12 @@ func ParseSearchParams(text string, timeZoneOffset int) []*SearchParams {
 			OnDate:             onDate,
 			ExcludedDate:       excludedDate,
 			TimeZoneOffset:     timeZoneOffset,
+			IsPinned:           isPinned,
+			HasFile:            hasFile,
+			HasLink:            hasLink,
+			HasReactions:       hasReactions,
+			InThread:           inThread,
+			FromBots:           fromBots,
 		})
 	}
 
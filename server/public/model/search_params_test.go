@@ -4,6 +4,7 @@
 package model
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -1708,6 +1709,117 @@ func TestParseSearchParams(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:  "input with post attribute modifiers and a user should result in a single param with the filters",
+			Input: "is:pinned has:file from:ci-bot",
+			Output: []*SearchParams{
+				{
+					Terms:              "",
+					ExcludedTerms:      "",
+					IsHashtag:          false,
+					InChannels:         []string{},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{"ci-bot"},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+					IsPinned:           NewPointer(true),
+					HasFile:            NewPointer(true),
+				},
+			},
+		},
+		{
+			Name:  "input with excluded post attribute modifiers should result in false filters",
+			Input: "deploy -has:link -in:thread -from:bot",
+			Output: []*SearchParams{
+				{
+					Terms:              "deploy",
+					ExcludedTerms:      "",
+					IsHashtag:          false,
+					InChannels:         []string{},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+					HasLink:            NewPointer(false),
+					InThread:           NewPointer(false),
+					FromBots:           NewPointer(false),
+				},
+			},
+		},
+		{
+			Name:  "input with post attribute modifiers should be case insensitive and apply to hashtag searches",
+			Input: "#release HAS:Reactions in:Thread from:BOT in:town-square",
+			Output: []*SearchParams{
+				{
+					Terms:              "#release",
+					ExcludedTerms:      "",
+					IsHashtag:          true,
+					InChannels:         []string{"town-square"},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+					HasReactions:       NewPointer(true),
+					InThread:           NewPointer(true),
+					FromBots:           NewPointer(true),
+				},
+			},
+		},
+		{
+			Name:  "input with unknown post attribute modifiers should search for them as words",
+			Input: "words is:unknown -has:nothing",
+			Output: []*SearchParams{
+				{
+					Terms:              "words is:unknown",
+					ExcludedTerms:      "has:nothing",
+					IsHashtag:          false,
+					InChannels:         []string{},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+				},
+			},
+		},
+		{
+			Name:  "input with in:thread should filter the replies rather than search a channel named thread",
+			Input: "deploy in:thread channel:thread",
+			Output: []*SearchParams{
+				{
+					Terms:              "deploy",
+					ExcludedTerms:      "",
+					IsHashtag:          false,
+					InChannels:         []string{"thread"},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+					InThread:           NewPointer(true),
+				},
+			},
+		},
+		{
+			Name:  "input with is:reply and is:bot should search for them as words",
+			Input: "deploy is:reply is:bot",
+			Output: []*SearchParams{
+				{
+					Terms:              "deploy is:reply is:bot",
+					ExcludedTerms:      "",
+					IsHashtag:          false,
+					InChannels:         []string{},
+					ExcludedChannels:   []string{},
+					FromUsers:          []string{},
+					ExcludedUsers:      []string{},
+					Extensions:         []string{},
+					ExcludedExtensions: []string{},
+				},
+			},
+		},
 	} {
 		t.Run(testCase.Name, func(t *testing.T) {
 			require.Equal(t, testCase.Output, ParseSearchParams(testCase.Input, 0))
@@ -1841,3 +1953,82 @@ func TestIsSearchParamsListValid(t *testing.T) {
 	appErr = IsSearchParamsListValid([]*SearchParams{})
 	assert.Nil(t, appErr)
 }
+
+func TestSearchParamsMatchesPost(t *testing.T) {
+	channelID, userID := NewId(), NewId()
+	day := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC).UnixMilli()
+	post := &Post{
+		Id:        NewId(),
+		ChannelId: channelID,
+		UserId:    userID,
+		CreateAt:  day,
+		Message:   "Outage of the Billing-API, see https://status.example.com",
+		Hashtags:  "#Incident",
+		FileIds:   StringArray{NewId()},
+	}
+
+	for _, testCase := range []struct {
+		Name    string
+		Input   string
+		Matches bool
+	}{
+		{"single term", "outage", true},
+		{"term ignoring case", "OUTAGE", true},
+		{"missing term", "maintenance", false},
+		{"partial word", "out", false},
+		{"wildcard", "bill*", true},
+		{"all terms", "outage billing", true},
+		{"one of the terms missing", "outage maintenance", false},
+		{"phrase", `"billing api"`, true},
+		{"phrase out of order", `"api billing"`, false},
+		{"term with dash", "billing-api", true},
+		{"excluded term", "outage -billing", false},
+		{"other excluded term", "outage -maintenance", true},
+		{"hashtag", "#incident", true},
+		{"other hashtag", "#release", false},
+		{"date", "outage on:2024-03-15", true},
+		{"other date", "outage on:2024-03-16", false},
+		{"after date", "outage after:2024-03-14", true},
+		{"before date", "outage before:2024-03-15", false},
+		{"attributes", "outage has:file has:link -is:pinned -in:thread -from:bot", true},
+		{"pinned", "outage is:pinned", false},
+		{"reactions", "outage has:reactions", false},
+		{"filters only", "has:link", true},
+	} {
+		t.Run(testCase.Name, func(t *testing.T) {
+			paramsList := ParseSearchParams(testCase.Input, 0)
+			require.Len(t, paramsList, 1)
+			require.Equal(t, testCase.Matches, paramsList[0].MatchesPost(post, nil))
+		})
+	}
+
+	t.Run("any term", func(t *testing.T) {
+		params := ParseSearchParams("maintenance billing", 0)[0]
+		params.OrTerms = true
+		require.True(t, params.MatchesPost(post, nil))
+	})
+
+	t.Run("stemmed words", func(t *testing.T) {
+		stem := func(word string) string {
+			return strings.TrimSuffix(word, "s")
+		}
+		require.False(t, ParseSearchParams("outages", 0)[0].MatchesPost(post, nil))
+		require.True(t, ParseSearchParams("outages", 0)[0].MatchesPost(post, stem))
+		require.True(t, ParseSearchParams(`"outages of"`, 0)[0].MatchesPost(post, stem))
+		require.False(t, ParseSearchParams("outages -billings", 0)[0].MatchesPost(post, stem))
+		require.False(t, ParseSearchParams("outages*", 0)[0].MatchesPost(post, stem))
+	})
+
+	t.Run("channels and users", func(t *testing.T) {
+		require.True(t, (&SearchParams{Terms: "outage", InChannels: []string{channelID}, FromUsers: []string{userID}}).MatchesPost(post, nil))
+		require.False(t, (&SearchParams{Terms: "outage", InChannels: []string{NewId()}}).MatchesPost(post, nil))
+		require.False(t, (&SearchParams{Terms: "outage", ExcludedChannels: []string{channelID}}).MatchesPost(post, nil))
+		require.False(t, (&SearchParams{Terms: "outage", FromUsers: []string{NewId()}}).MatchesPost(post, nil))
+		require.False(t, (&SearchParams{Terms: "outage", ExcludedUsers: []string{userID}}).MatchesPost(post, nil))
+	})
+	t.Run("filters only", func(t *testing.T) {
+		require.True(t, ParseSearchParams("maintenance has:link", 0)[0].MatchesPostFilters(post))
+		require.False(t, ParseSearchParams("outage is:pinned", 0)[0].MatchesPostFilters(post))
+		require.False(t, (&SearchParams{Terms: "outage", InChannels: []string{NewId()}}).MatchesPostFilters(post))
+	})
+}
@@ -36,6 +36,9 @@ const (
 	SystemLastAccessiblePostTime           = "LastAccessiblePostTime"
 	SystemLastAccessibleFileTime           = "LastAccessibleFileTime"
 	SystemHostedPurchaseNeedsScreening     = "HostedPurchaseNeedsScreening"
+	SystemSavedSearchWatchCursor           = "SavedSearchWatchCursor"
+	SystemPostEmbeddingCursor              = "PostEmbeddingCursor"
+	SystemPostEmbeddingDimensions          = "PostEmbeddingDimensions"
 	AwsMeteringReportInterval              = 1
 	AwsMeteringDimensionUsageHrs           = "UsageHrs"
 	CloudRenewalEmail                      = "CloudRenewalEmail"
@@ -11,6 +11,25 @@ type StorageUsage struct {
 	Bytes int64 `json:"bytes"`
 }
 
+const (
+	FileStorageUsageOwnerTypeUser = "user"
+	FileStorageUsageOwnerTypeTeam = "team"
+)
+
+// FileStorageUsage is the size of the files uploaded by a user or into the channels of a team,
+// until they are permanently deleted. A quota of zero means there is no limit.
+type FileStorageUsage struct {
+	OwnerId    string `json:"owner_id"`
+	OwnerType  string `json:"owner_type"`
+	UsedBytes  int64  `json:"used_bytes"`
+	QuotaBytes int64  `json:"quota_bytes" db:"-"`
+}
+
+// IsOverQuota returns whether storing size more bytes would exceed the quota.
+func (u *FileStorageUsage) IsOverQuota(size int64) bool {
+	return u.QuotaBytes > 0 && u.UsedBytes+size > u.QuotaBytes
+}
+
 type TeamsUsage struct {
 	Active        int64 `json:"active"`
 	CloudArchived int64 `json:"cloud_archived"`
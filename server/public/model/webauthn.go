@@ -0,0 +1,200 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	WebAuthnCredentialNameMaxRunes = 64
+	WebAuthnCredentialIdMaxLength  = 1024
+
+	// WebAuthnChallengeTimeout is how long a registration or login challenge stays valid, in milliseconds.
+	WebAuthnChallengeTimeout = 5 * 60 * 1000
+
+	WebAuthnUserVerificationRequired = "required"
+	WebAuthnAttestationNone          = "none"
+	WebAuthnCredentialTypePublicKey  = "public-key"
+)
+
+// WebAuthnBytes is binary data exchanged with WebAuthn clients, encoded as unpadded base64url in JSON.
+type WebAuthnBytes []byte
+
+func (b WebAuthnBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *WebAuthnBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// WebAuthnCredential is a WebAuthn authenticator registered by a user as a second factor.
+type WebAuthnCredential struct {
+	Id           string `json:"id"`
+	UserId       string `json:"user_id"`
+	Name         string `json:"name"`
+	CredentialId string `json:"credential_id"`
+	PublicKey    []byte `json:"-"`
+	AAGUID       string `json:"aaguid"`
+	SignCount    int64  `json:"-"`
+	CreateAt     int64  `json:"create_at"`
+	LastUsedAt   int64  `json:"last_used_at"`
+}
+
+func (c *WebAuthnCredential) Auditable() map[string]any {
+	return map[string]any{
+		"id":            c.Id,
+		"user_id":       c.UserId,
+		"name":          c.Name,
+		"credential_id": c.CredentialId,
+		"aaguid":        c.AAGUID,
+		"create_at":     c.CreateAt,
+		"last_used_at":  c.LastUsedAt,
+	}
+}
+
+func (c *WebAuthnCredential) PreSave() {
+	if c.Id == "" {
+		c.Id = NewId()
+	}
+
+	c.Name = strings.TrimSpace(c.Name)
+	c.CreateAt = GetMillis()
+}
+
+func (c *WebAuthnCredential) IsValid() *AppError {
+	if !IsValidId(c.Id) {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(c.UserId) {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.user_id.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.Name == "" || utf8.RuneCountInString(c.Name) > WebAuthnCredentialNameMaxRunes {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.name.app_error", map[string]any{"MaxLength": WebAuthnCredentialNameMaxRunes}, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.CredentialId == "" || len(c.CredentialId) > WebAuthnCredentialIdMaxLength {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.credential_id.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if len(c.PublicKey) == 0 {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.public_key.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	if c.CreateAt == 0 {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.create_at.app_error", nil, "id="+c.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+type WebAuthnRelyingPartyEntity struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserEntity struct {
+	Id          WebAuthnBytes `json:"id"`
+	Name        string        `json:"name"`
+	DisplayName string        `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type string        `json:"type"`
+	Id   WebAuthnBytes `json:"id"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	UserVerification string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions are the PublicKeyCredentialCreationOptions passed to navigator.credentials.create().
+type WebAuthnCreationOptions struct {
+	Challenge              WebAuthnBytes                  `json:"challenge"`
+	RelyingParty           WebAuthnRelyingPartyEntity     `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+// WebAuthnRequestOptions are the PublicKeyCredentialRequestOptions passed to navigator.credentials.get().
+type WebAuthnRequestOptions struct {
+	Challenge        WebAuthnBytes                  `json:"challenge"`
+	RelyingPartyId   string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	Timeout          int64                          `json:"timeout"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+type WebAuthnAttestationResponseData struct {
+	ClientDataJSON    WebAuthnBytes `json:"clientDataJSON"`
+	AttestationObject WebAuthnBytes `json:"attestationObject"`
+}
+
+// WebAuthnAttestationResponse is the credential returned by navigator.credentials.create(),
+// along with the name the user gave to the authenticator.
+type WebAuthnAttestationResponse struct {
+	Id       string                          `json:"id"`
+	RawId    WebAuthnBytes                   `json:"rawId"`
+	Type     string                          `json:"type"`
+	Response WebAuthnAttestationResponseData `json:"response"`
+	Name     string                          `json:"name"`
+}
+
+type WebAuthnAssertionResponseData struct {
+	ClientDataJSON    WebAuthnBytes `json:"clientDataJSON"`
+	AuthenticatorData WebAuthnBytes `json:"authenticatorData"`
+	Signature         WebAuthnBytes `json:"signature"`
+	UserHandle        WebAuthnBytes `json:"userHandle,omitempty"`
+}
+
+// WebAuthnAssertionResponse is the credential returned by navigator.credentials.get().
+type WebAuthnAssertionResponse struct {
+	Id       string                        `json:"id"`
+	RawId    WebAuthnBytes                 `json:"rawId"`
+	Type     string                        `json:"type"`
+	Response WebAuthnAssertionResponseData `json:"response"`
+}
+
+// IsWebAuthnAssertion reports whether an MFA token holds a JSON encoded WebAuthn assertion
+// rather than a TOTP code, so that either can be sent wherever an MFA token is accepted.
+func IsWebAuthnAssertion(token string) bool {
+	return strings.HasPrefix(strings.TrimSpace(token), "{")
+}
+
+// WebAuthnAssertionFromToken decodes a WebAuthn assertion sent as an MFA token.
+func WebAuthnAssertionFromToken(token string) (*WebAuthnAssertionResponse, error) {
+	var assertion WebAuthnAssertionResponse
+	if err := json.Unmarshal([]byte(token), &assertion); err != nil {
+		return nil, err
+	}
+
+	return &assertion, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnBytesJSON(t *testing.T) {
+	data, err := json.Marshal(WebAuthnBytes{0xfb, 0xff, 0x01})
+	require.NoError(t, err)
+	assert.Equal(t, `"-_8B"`, string(data))
+
+	var b WebAuthnBytes
+	require.NoError(t, json.Unmarshal([]byte(`"-_8B"`), &b))
+	assert.Equal(t, WebAuthnBytes{0xfb, 0xff, 0x01}, b)
+
+	require.NoError(t, json.Unmarshal([]byte(`"AQI="`), &b), "padding should be accepted")
+	assert.Equal(t, WebAuthnBytes{0x01, 0x02}, b)
+
+	require.Error(t, json.Unmarshal([]byte(`"not base64!"`), &b))
+	require.Error(t, json.Unmarshal([]byte(`12`), &b))
+}
+
+func TestWebAuthnCredentialIsValid(t *testing.T) {
+	valid := func() *WebAuthnCredential {
+		c := &WebAuthnCredential{
+			UserId:       NewId(),
+			Name:         " Security key ",
+			CredentialId: "AQIDBA",
+			PublicKey:    []byte{0xa5},
+		}
+		c.PreSave()
+		return c
+	}
+
+	c := valid()
+	require.Nil(t, c.IsValid())
+	assert.Equal(t, "Security key", c.Name)
+
+	c = valid()
+	c.Id = "junk"
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.UserId = ""
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.Name = strings.Repeat("a", WebAuthnCredentialNameMaxRunes+1)
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.Name = ""
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.CredentialId = strings.Repeat("a", WebAuthnCredentialIdMaxLength+1)
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.PublicKey = nil
+	require.NotNil(t, c.IsValid())
+
+	c = valid()
+	c.CreateAt = 0
+	require.NotNil(t, c.IsValid())
+}
+
+func TestWebAuthnCredentialJSONOmitsKeyMaterial(t *testing.T) {
+	data, err := json.Marshal(&WebAuthnCredential{Id: NewId(), PublicKey: []byte{0xa5}, SignCount: 7})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "public_key")
+	assert.NotContains(t, string(data), "sign_count")
+}
+
+func TestWebAuthnAssertionFromToken(t *testing.T) {
+	assert.False(t, IsWebAuthnAssertion("123456"))
+	assert.False(t, IsWebAuthnAssertion(""))
+
+	token := ` {"id":"AQID","rawId":"AQID","type":"public-key","response":{"clientDataJSON":"e30","authenticatorData":"AA","signature":"AQ"}}`
+	require.True(t, IsWebAuthnAssertion(token))
+
+	assertion, err := WebAuthnAssertionFromToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "AQID", assertion.Id)
+	assert.Equal(t, WebAuthnBytes{0x01, 0x02, 0x03}, assertion.RawId)
+	assert.Equal(t, WebAuthnBytes("{}"), assertion.Response.ClientDataJSON)
+
+	_, err = WebAuthnAssertionFromToken("{")
+	require.Error(t, err)
+}
@@ -95,6 +95,17 @@ func ParseBlocks(markdown string, lines []Line) (*Document, []*ReferenceDefiniti
 
 		isBlank := strings.TrimSpace(markdown[r.Position:r.End]) == ""
 		if paragraph, ok := openBlocks[len(openBlocks)-1].(*Paragraph); ok && !isBlank {
+			// A delimiter row turns the last line of a paragraph into the header of a table, unless
+			// the paragraph is only continued lazily
+			if lastMatchIndex == len(openBlocks)-1 {
+				if container, ok := openBlocks[lastMatchIndex-1].(ContainerBlock); ok {
+					if table, closed := tableStartFromParagraph(markdown, r, container, paragraph); table != nil {
+						referenceDefinitions = closeBlocks(closed, referenceDefinitions)
+						openBlocks[lastMatchIndex] = table
+						continue
+					}
+				}
+			}
 			paragraph.Text = append(paragraph.Text, r)
 			continue
 		}
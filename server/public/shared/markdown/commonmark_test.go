@@ -1042,3 +1042,201 @@ http://🍄.ga/ http://x🍄.ga/`,
 		})
 	}
 }
+
+func TestCommonMarkReferenceExtensions(t *testing.T) {
+	// These tests are taken from the extension sections of version 0.29 of the GitHub flavored
+	// Markdown spec at https://github.github.com/gfm/. Since we didn't support emphasis when they were
+	// added, examples that rely on it have been adapted to leave it out, and the original examples
+	// follow them.
+	for name, tc := range map[string]struct {
+		Markdown     string
+		ExpectedHTML string
+	}{
+		"0.29-gfm-198": {
+			Markdown:     "| foo | bar |\n| --- | --- |\n| baz | bim |",
+			ExpectedHTML: "<table><thead><tr><th>foo</th><th>bar</th></tr></thead><tbody><tr><td>baz</td><td>bim</td></tr></tbody></table>",
+		},
+		"0.29-gfm-199": {
+			Markdown:     "| abc | defghi |\n:-: | -----------:\nbar | baz",
+			ExpectedHTML: `<table><thead><tr><th align="center">abc</th><th align="right">defghi</th></tr></thead><tbody><tr><td align="center">bar</td><td align="right">baz</td></tr></tbody></table>`,
+		},
+		"0.29-gfm-200": {
+			Markdown:     "| f\\|oo  |\n| ------ |\n| b `\\|` az |\n| b \\| im |",
+			ExpectedHTML: "<table><thead><tr><th>f|oo</th></tr></thead><tbody><tr><td>b <code>|</code> az</td></tr><tr><td>b | im</td></tr></tbody></table>",
+		},
+		"0.29-gfm-200-emphasis": {
+			Markdown:     "| f\\|oo  |\n| ------ |\n| b `\\|` az |\n| b **\\|** im |",
+			ExpectedHTML: "<table><thead><tr><th>f|oo</th></tr></thead><tbody><tr><td>b <code>|</code> az</td></tr><tr><td>b <strong>|</strong> im</td></tr></tbody></table>",
+		},
+		"0.29-gfm-201": {
+			Markdown:     "| abc | def |\n| --- | --- |\n| bar | baz |\n> bar",
+			ExpectedHTML: "<table><thead><tr><th>abc</th><th>def</th></tr></thead><tbody><tr><td>bar</td><td>baz</td></tr></tbody></table><blockquote><p>bar</p></blockquote>",
+		},
+		"0.29-gfm-202": {
+			Markdown:     "| abc | def |\n| --- | --- |\n| bar | baz |\nbar\n\nbar",
+			ExpectedHTML: "<table><thead><tr><th>abc</th><th>def</th></tr></thead><tbody><tr><td>bar</td><td>baz</td></tr><tr><td>bar</td><td></td></tr></tbody></table><p>bar</p>",
+		},
+		"0.29-gfm-203": {
+			Markdown:     "| abc | def |\n| --- |\n| bar |",
+			ExpectedHTML: "<p>| abc | def |\n| --- |\n| bar |</p>",
+		},
+		"0.29-gfm-204": {
+			Markdown:     "| abc | def |\n| --- | --- |\n| bar |\n| bar | baz | boo |",
+			ExpectedHTML: "<table><thead><tr><th>abc</th><th>def</th></tr></thead><tbody><tr><td>bar</td><td></td></tr><tr><td>bar</td><td>baz</td></tr></tbody></table>",
+		},
+		"0.29-gfm-205": {
+			Markdown:     "| abc | def |\n| --- | --- |",
+			ExpectedHTML: "<table><thead><tr><th>abc</th><th>def</th></tr></thead></table>",
+		},
+		"0.29-gfm-279": {
+			Markdown:     "- [ ] foo\n- [x] bar",
+			ExpectedHTML: `<ul><li><input disabled="" type="checkbox"> foo</li><li><input checked="" disabled="" type="checkbox"> bar</li></ul>`,
+		},
+		"0.29-gfm-280": {
+			Markdown:     "- [x] foo\n  - [ ] bar\n  - [x] baz\n- [ ] bim",
+			ExpectedHTML: `<ul><li><input checked="" disabled="" type="checkbox"> foo<ul><li><input disabled="" type="checkbox"> bar</li><li><input checked="" disabled="" type="checkbox"> baz</li></ul></li><li><input disabled="" type="checkbox"> bim</li></ul>`,
+		},
+		"0.29-gfm-491": {
+			Markdown:     "~~Hi~~ Hello, ~there~ world!",
+			ExpectedHTML: "<p><del>Hi</del> Hello, <del>there</del> world!</p>",
+		},
+		"0.29-gfm-492": {
+			Markdown:     "This ~~has a\n\nnew paragraph~~.",
+			ExpectedHTML: "<p>This ~~has a</p><p>new paragraph~~.</p>",
+		},
+		"0.29-gfm-493": {
+			Markdown:     "This will ~~~not~~~ strike.",
+			ExpectedHTML: "<p>This will ~~~not~~~ strike.</p>",
+		},
+		"table after paragraph": {
+			Markdown:     "Some text\n| a | b |\n|:--|--:|\n| `c` | [d](/url) |",
+			ExpectedHTML: `<p>Some text</p><table><thead><tr><th align="left">a</th><th align="right">b</th></tr></thead><tbody><tr><td align="left"><code>c</code></td><td align="right"><a href="/url">d</a></td></tr></tbody></table>`,
+		},
+		"table in block quote": {
+			Markdown:     "> | a |\n> | - |\n> | b |\n\nc",
+			ExpectedHTML: "<blockquote><table><thead><tr><th>a</th></tr></thead><tbody><tr><td>b</td></tr></tbody></table></blockquote><p>c</p>",
+		},
+		"delimiter row without pipes": {
+			Markdown:     "a\n---",
+			ExpectedHTML: "<p>a\n---</p>",
+		},
+		"loose task list": {
+			Markdown:     "- [x] foo\n\n- [ ] bar",
+			ExpectedHTML: `<ul><li><input checked="" disabled="" type="checkbox"> <p>foo</p></li><li><input disabled="" type="checkbox"> <p>bar</p></li></ul>`,
+		},
+		"task list marker without text": {
+			Markdown:     "- [ ]\n- [y] foo",
+			ExpectedHTML: "<ul><li>[ ]</li><li>[y] foo</li></ul>",
+		},
+		"strikethrough with mismatched tildes": {
+			Markdown:     "~foo~~ bar",
+			ExpectedHTML: "<p>~foo~~ bar</p>",
+		},
+		"strikethrough surrounded by spaces": {
+			Markdown:     "a ~~ b ~~ c",
+			ExpectedHTML: "<p>a ~~ b ~~ c</p>",
+		},
+		"strikethrough in link": {
+			Markdown:     "[~~foo~~](/url) ~~[bar](/url)~~",
+			ExpectedHTML: `<p><a href="/url"><del>foo</del></a> <del><a href="/url">bar</a></del></p>`,
+		},
+		"link in strikethrough takes precedence": {
+			Markdown:     "~~[foo~~](/url)",
+			ExpectedHTML: `<p>~~<a href="/url">foo~~</a></p>`,
+		},
+		"autolink in strikethrough": {
+			Markdown:     "Those were ~~https://example.com~~",
+			ExpectedHTML: `<p>Those were <del><a href="https://example.com">https://example.com</a></del></p>`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.ExpectedHTML, RenderHTML(tc.Markdown))
+		})
+	}
+}
+
+func TestCommonMarkReferenceEmphasis(t *testing.T) {
+	// These tests are taken from the emphasis section of the CommonMark spec at
+	// https://spec.commonmark.org/0.29/#emphasis-and-strong-emphasis.
+	for name, tc := range map[string]struct {
+		Markdown     string
+		ExpectedHTML string
+	}{
+		"asterisks": {
+			Markdown:     "*foo bar*",
+			ExpectedHTML: "<p><em>foo bar</em></p>",
+		},
+		"asterisk followed by whitespace": {
+			Markdown:     "a * foo bar*",
+			ExpectedHTML: "<p>a * foo bar*</p>",
+		},
+		"intraword asterisks": {
+			Markdown:     "foo*bar*",
+			ExpectedHTML: "<p>foo<em>bar</em></p>",
+		},
+		"underscores": {
+			Markdown:     "_foo bar_",
+			ExpectedHTML: "<p><em>foo bar</em></p>",
+		},
+		"intraword underscores": {
+			Markdown:     "foo_bar_ _foo_bar snake_case_name",
+			ExpectedHTML: "<p>foo_bar_ _foo_bar snake_case_name</p>",
+		},
+		"underscores inside of emphasis": {
+			Markdown:     "_foo_bar_",
+			ExpectedHTML: "<p><em>foo_bar</em></p>",
+		},
+		"strong": {
+			Markdown:     "**foo bar** __foo bar__",
+			ExpectedHTML: "<p><strong>foo bar</strong> <strong>foo bar</strong></p>",
+		},
+		"intraword double underscores": {
+			Markdown:     "foo__bar__",
+			ExpectedHTML: "<p>foo__bar__</p>",
+		},
+		"nested": {
+			Markdown:     "*foo**bar**baz*",
+			ExpectedHTML: "<p><em>foo<strong>bar</strong>baz</em></p>",
+		},
+		"triple": {
+			Markdown:     "***foo***",
+			ExpectedHTML: "<p><em><strong>foo</strong></em></p>",
+		},
+		"rule of three": {
+			Markdown:     "*foo**bar*",
+			ExpectedHTML: "<p><em>foo**bar</em></p>",
+		},
+		"unbalanced opener": {
+			Markdown:     "**foo*",
+			ExpectedHTML: "<p>*<em>foo</em></p>",
+		},
+		"unbalanced closer": {
+			Markdown:     "*foo**",
+			ExpectedHTML: "<p><em>foo</em>*</p>",
+		},
+		"links take precedence": {
+			Markdown:     "*[foo*](/url)",
+			ExpectedHTML: `<p>*<a href="/url">foo*</a></p>`,
+		},
+		"link inside of strong": {
+			Markdown:     "**a [b](/url)**",
+			ExpectedHTML: `<p><strong>a <a href="/url">b</a></strong></p>`,
+		},
+		"code spans take precedence": {
+			Markdown:     "*a `*`*",
+			ExpectedHTML: "<p><em>a <code>*</code></em></p>",
+		},
+		"escaped": {
+			Markdown:     "\\*foo*",
+			ExpectedHTML: "<p>*foo*</p>",
+		},
+		"mentions": {
+			Markdown:     "@user_name and @other_user_",
+			ExpectedHTML: "<p>@user_name and @other_user_</p>",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.ExpectedHTML, RenderHTML(tc.Markdown))
+		})
+	}
+}
@@ -40,9 +40,9 @@ func renderBlockHTML(block Block, referenceDefinitions []*ReferenceDefinition, i
 		if !isTightList {
 			result += "<p>"
 		}
-		for _, inline := range v.ParseInlines(referenceDefinitions) {
-			result += RenderInlineHTML(inline)
-		}
+		var inlines strings.Builder
+		renderInlinesHTML(&inlines, v.ParseInlines(referenceDefinitions))
+		result += inlines.String()
 		if !isTightList {
 			result += "</p>"
 		}
@@ -66,6 +66,13 @@ func renderBlockHTML(block Block, referenceDefinitions []*ReferenceDefinition, i
 		}
 	case *ListItem:
 		result += "<li>"
+		if v.IsTaskListItem {
+			if v.IsChecked {
+				result += `<input checked="" disabled="" type="checkbox"> `
+			} else {
+				result += `<input disabled="" type="checkbox"> `
+			}
+		}
 		for _, block := range v.Children {
 			result += renderBlockHTML(block, referenceDefinitions, isTightList)
 		}
@@ -76,6 +83,41 @@ func renderBlockHTML(block Block, referenceDefinitions []*ReferenceDefinition, i
 			result += RenderBlockHTML(block, referenceDefinitions)
 		}
 		result += "</blockquote>"
+	case *Table:
+		result += "<table><thead>" + renderBlockHTML(v.Header, referenceDefinitions, false) + "</thead>"
+		if len(v.Rows) > 0 {
+			result += "<tbody>"
+			for _, row := range v.Rows {
+				result += renderBlockHTML(row, referenceDefinitions, false)
+			}
+			result += "</tbody>"
+		}
+		result += "</table>"
+	case *TableRow:
+		result += "<tr>"
+		for _, cell := range v.Cells {
+			result += renderBlockHTML(cell, referenceDefinitions, false)
+		}
+		result += "</tr>"
+	case *TableCell:
+		tag := "td"
+		if v.IsHeader {
+			tag = "th"
+		}
+		switch v.Alignment {
+		case TableAlignmentLeft:
+			result += "<" + tag + ` align="left">`
+		case TableAlignmentCenter:
+			result += "<" + tag + ` align="center">`
+		case TableAlignmentRight:
+			result += "<" + tag + ` align="right">`
+		default:
+			result += "<" + tag + ">"
+		}
+		var inlines strings.Builder
+		renderInlinesHTML(&inlines, v.ParseInlines(referenceDefinitions))
+		result += inlines.String()
+		result += "</" + tag + ">"
 	case *FencedCode:
 		if info := v.Info(); info != "" {
 			language := strings.Fields(info)[0]
@@ -114,83 +156,108 @@ func escapeURL(url string) (result string) {
 	return
 }
 
-func RenderInlineHTML(inline Inline) (result string) {
+func RenderInlineHTML(inline Inline) string {
+	var result strings.Builder
+	renderInlineHTML(&result, inline)
+	return result.String()
+}
+
+// renderInlineHTML writes the HTML for an inline to result instead of returning it since emphasis
+// can be nested deeply enough that copying the HTML of the children at each level gets expensive.
+func renderInlineHTML(result *strings.Builder, inline Inline) {
 	switch v := inline.(type) {
 	case *Text:
-		return htmlEscaper.Replace(v.Text)
+		htmlEscaper.WriteString(result, v.Text)
 	case *HardLineBreak:
-		return "<br />"
+		result.WriteString("<br />")
 	case *SoftLineBreak:
-		return "\n"
+		result.WriteString("\n")
 	case *CodeSpan:
-		return "<code>" + htmlEscaper.Replace(v.Code) + "</code>"
+		result.WriteString("<code>" + htmlEscaper.Replace(v.Code) + "</code>")
 	case *InlineImage:
-		result += `<img src="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `" alt="` + htmlEscaper.Replace(renderImageAltText(v.Children)) + `"`
+		result.WriteString(`<img src="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `" alt="` + htmlEscaper.Replace(renderImageAltText(v.Children)) + `"`)
 		if title := v.Title(); title != "" {
-			result += ` title="` + htmlEscaper.Replace(title) + `"`
+			result.WriteString(` title="` + htmlEscaper.Replace(title) + `"`)
 		}
-		result += ` />`
+		result.WriteString(` />`)
 	case *ReferenceImage:
-		result += `<img src="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `" alt="` + htmlEscaper.Replace(renderImageAltText(v.Children)) + `"`
+		result.WriteString(`<img src="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `" alt="` + htmlEscaper.Replace(renderImageAltText(v.Children)) + `"`)
 		if title := v.Title(); title != "" {
-			result += ` title="` + htmlEscaper.Replace(title) + `"`
+			result.WriteString(` title="` + htmlEscaper.Replace(title) + `"`)
 		}
-		result += ` />`
+		result.WriteString(` />`)
 	case *InlineLink:
-		result += `<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `"`
+		result.WriteString(`<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `"`)
 		if title := v.Title(); title != "" {
-			result += ` title="` + htmlEscaper.Replace(title) + `"`
-		}
-		result += `>`
-		for _, inline := range v.Children {
-			result += RenderInlineHTML(inline)
+			result.WriteString(` title="` + htmlEscaper.Replace(title) + `"`)
 		}
-		result += "</a>"
+		result.WriteString(`>`)
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</a>")
 	case *ReferenceLink:
-		result += `<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `"`
+		result.WriteString(`<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `"`)
 		if title := v.Title(); title != "" {
-			result += ` title="` + htmlEscaper.Replace(title) + `"`
+			result.WriteString(` title="` + htmlEscaper.Replace(title) + `"`)
 		}
-		result += `>`
-		for _, inline := range v.Children {
-			result += RenderInlineHTML(inline)
-		}
-		result += "</a>"
+		result.WriteString(`>`)
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</a>")
 	case *Autolink:
-		result += `<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `">`
-		for _, inline := range v.Children {
-			result += RenderInlineHTML(inline)
-		}
-		result += "</a>"
+		result.WriteString(`<a href="` + htmlEscaper.Replace(escapeURL(v.Destination())) + `">`)
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</a>")
+	case *Emphasis:
+		result.WriteString("<em>")
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</em>")
+	case *Strong:
+		result.WriteString("<strong>")
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</strong>")
+	case *Strikethrough:
+		result.WriteString("<del>")
+		renderInlinesHTML(result, v.Children)
+		result.WriteString("</del>")
 	case *Emoji:
 		escapedName := htmlEscaper.Replace(v.Name)
-		result += fmt.Sprintf(`<span data-emoji-name="%s" data-literal=":%s:" />`, escapedName, escapedName)
+		fmt.Fprintf(result, `<span data-emoji-name="%s" data-literal=":%s:" />`, escapedName, escapedName)
 
 	default:
 		panic(fmt.Sprintf("missing case for type %T", v))
 	}
-	return
 }
 
-func renderImageAltText(children []Inline) (result string) {
+func renderInlinesHTML(result *strings.Builder, inlines []Inline) {
+	for _, inline := range inlines {
+		renderInlineHTML(result, inline)
+	}
+}
+
+func renderImageAltText(children []Inline) string {
+	var result strings.Builder
 	for _, inline := range children {
-		result += renderImageChildAltText(inline)
+		renderImageChildAltText(&result, inline)
 	}
-	return
+	return result.String()
 }
 
-func renderImageChildAltText(inline Inline) (result string) {
+func renderImageChildAltText(result *strings.Builder, inline Inline) {
+	var children []Inline
 	switch v := inline.(type) {
 	case *Text:
-		return v.Text
+		result.WriteString(v.Text)
 	case *InlineImage:
-		for _, inline := range v.Children {
-			result += renderImageChildAltText(inline)
-		}
+		children = v.Children
 	case *InlineLink:
-		for _, inline := range v.Children {
-			result += renderImageChildAltText(inline)
-		}
+		children = v.Children
+	case *Emphasis:
+		children = v.Children
+	case *Strong:
+		children = v.Children
+	case *Strikethrough:
+		children = v.Children
+	}
+	for _, inline := range children {
+		renderImageChildAltText(result, inline)
 	}
-	return
 }
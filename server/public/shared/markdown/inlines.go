@@ -101,6 +101,24 @@ func (i *Autolink) Destination() string {
 	return destination
 }
 
+type Emphasis struct {
+	inlineBase
+
+	Children []Inline
+}
+
+type Strong struct {
+	inlineBase
+
+	Children []Inline
+}
+
+type Strikethrough struct {
+	inlineBase
+
+	Children []Inline
+}
+
 type Emoji struct {
 	inlineBase
 
@@ -112,6 +130,7 @@ type delimiterType int
 const (
 	linkOpeningDelimiter delimiterType = iota
 	imageOpeningDelimiter
+	emphasisDelimiter
 )
 
 type delimiter struct {
@@ -119,6 +138,16 @@ type delimiter struct {
 	IsInactive bool
 	TextNode   int
 	Range      Range
+
+	// The remaining fields are only set for emphasis delimiters
+	Character      byte
+	Length         int
+	OriginalLength int
+	CanOpen        bool
+	CanClose       bool
+
+	// TextElement holds the text node while processEmphasis links the inlines together
+	TextElement *list.Element
 }
 
 type inlineParser struct {
@@ -130,6 +159,13 @@ type inlineParser struct {
 	position       int
 	inlines        []Inline
 	delimiterStack *list.List
+
+	// brackets holds the link and image delimiters of the stack so that they can be found without
+	// going through every emphasis delimiter. activeBrackets counts the ones which are active, and
+	// the links among the first inactiveLinks of them are known to be inactive.
+	brackets       []*list.Element
+	activeBrackets int
+	inactiveLinks  int
 }
 
 func newInlineParser(markdown string, ranges []Range, referenceDefinitions []*ReferenceDefinition) *inlineParser {
@@ -208,7 +244,17 @@ func (p *inlineParser) parseEscapeCharacter() {
 }
 
 func (p *inlineParser) parseText() {
-	if next := strings.IndexAny(p.raw[p.position:], "\r\n\\`&![]wW:"); next == -1 {
+	// Text can't span multiple ranges since its range would be wrong. This only happens inside of
+	// table cells with escaped pipes since other ranges end with a line ending.
+	end := relativeRangeEnd(p.ranges, p.position)
+	if next := strings.IndexAny(p.raw[p.position:end], "\r\n\\`&![]*_~wW:"); next == -1 && end < len(p.raw) {
+		absPos := relativeToAbsolutePosition(p.ranges, p.position)
+		p.inlines = append(p.inlines, &Text{
+			Text:  p.raw[p.position:end],
+			Range: Range{absPos, absPos + end - p.position},
+		})
+		p.position = end
+	} else if next == -1 {
 		absPos := relativeToAbsolutePosition(p.ranges, p.position)
 		p.inlines = append(p.inlines, &Text{
 			Text:  strings.TrimRightFunc(p.raw[p.position:], isWhitespace),
@@ -246,7 +292,7 @@ func (p *inlineParser) parseLinkOrImageDelimiter() {
 			Text:  "[",
 			Range: Range{absPos, absPos + 1},
 		})
-		p.delimiterStack.PushBack(&delimiter{
+		p.pushBracket(&delimiter{
 			Type:     linkOpeningDelimiter,
 			TextNode: len(p.inlines) - 1,
 			Range:    Range{p.position, p.position + 1},
@@ -257,7 +303,7 @@ func (p *inlineParser) parseLinkOrImageDelimiter() {
 			Text:  "![",
 			Range: Range{absPos, absPos + 2},
 		})
-		p.delimiterStack.PushBack(&delimiter{
+		p.pushBracket(&delimiter{
 			Type:     imageOpeningDelimiter,
 			TextNode: len(p.inlines) - 1,
 			Range:    Range{p.position, p.position + 2},
@@ -272,6 +318,22 @@ func (p *inlineParser) parseLinkOrImageDelimiter() {
 	}
 }
 
+func (p *inlineParser) pushBracket(d *delimiter) {
+	p.brackets = append(p.brackets, p.delimiterStack.PushBack(d))
+	p.activeBrackets++
+}
+
+// popBracket removes the last link or image delimiter from the stack.
+func (p *inlineParser) popBracket() {
+	element := p.brackets[len(p.brackets)-1]
+	p.brackets = p.brackets[:len(p.brackets)-1]
+	p.inactiveLinks = min(p.inactiveLinks, len(p.brackets))
+	if !element.Value.(*delimiter).IsInactive {
+		p.activeBrackets--
+	}
+	p.delimiterStack.Remove(element)
+}
+
 func (p *inlineParser) peekAtInlineLinkDestinationAndTitle(position int, isImage bool) (destination, title Range, end int, ok bool) {
 	if position >= len(p.raw) || p.raw[position] != '(' {
 		return
@@ -334,6 +396,9 @@ func (p *inlineParser) peekAtInlineLinkDestinationAndTitle(position int, isImage
 }
 
 func (p *inlineParser) referenceDefinition(label string) *ReferenceDefinition {
+	if len(p.referenceDefinitions) == 0 {
+		return nil
+	}
 	clean := strings.Join(strings.Fields(label), " ")
 	for _, d := range p.referenceDefinitions {
 		if strings.EqualFold(clean, strings.Join(strings.Fields(d.Label()), " ")) {
@@ -344,13 +409,11 @@ func (p *inlineParser) referenceDefinition(label string) *ReferenceDefinition {
 }
 
 func (p *inlineParser) lookForLinkOrImage() {
-	for element := p.delimiterStack.Back(); element != nil; element = element.Prev() {
+	for len(p.brackets) > 0 {
+		element := p.brackets[len(p.brackets)-1]
 		d := element.Value.(*delimiter)
-		if d.Type != imageOpeningDelimiter && d.Type != linkOpeningDelimiter {
-			continue
-		}
 		if d.IsInactive {
-			p.delimiterStack.Remove(element)
+			p.popBracket()
 			break
 		}
 
@@ -361,7 +424,6 @@ func (p *inlineParser) lookForLinkOrImage() {
 		if destination, title, next, ok := p.peekAtInlineLinkDestinationAndTitle(p.position+1, isImage); ok {
 			destinationMarkdownPosition := relativeToAbsolutePosition(p.ranges, destination.Position)
 			linkOrImage := InlineLinkOrImage{
-				Children:       append([]Inline(nil), p.inlines[d.TextNode+1:]...),
 				RawDestination: Range{destinationMarkdownPosition, destinationMarkdownPosition + destination.End - destination.Position},
 				markdown:       p.markdown,
 				rawTitle:       p.raw[title.Position:title.End],
@@ -387,7 +449,6 @@ func (p *inlineParser) lookForLinkOrImage() {
 				if reference := p.referenceDefinition(referenceLabel); reference != nil {
 					linkOrImage := ReferenceLinkOrImage{
 						ReferenceDefinition: reference,
-						Children:            append([]Inline(nil), p.inlines[d.TextNode+1:]...),
 					}
 					if d.Type == imageOpeningDelimiter {
 						inline = &ReferenceImage{linkOrImage}
@@ -400,20 +461,36 @@ func (p *inlineParser) lookForLinkOrImage() {
 		}
 
 		if inline != nil {
+			// Emphasis delimiters inside of the link or image can only match each other
+			p.processEmphasis(element)
+			switch v := inline.(type) {
+			case *InlineImage:
+				v.Children = append([]Inline(nil), p.inlines[d.TextNode+1:]...)
+			case *InlineLink:
+				v.Children = append([]Inline(nil), p.inlines[d.TextNode+1:]...)
+			case *ReferenceImage:
+				v.Children = append([]Inline(nil), p.inlines[d.TextNode+1:]...)
+			case *ReferenceLink:
+				v.Children = append([]Inline(nil), p.inlines[d.TextNode+1:]...)
+			}
+
+			p.popBracket()
 			if d.Type == imageOpeningDelimiter {
 				p.inlines = append(p.inlines[:d.TextNode], inline)
 			} else {
 				p.inlines = append(p.inlines[:d.TextNode], inline)
-				for inlineElement := element.Prev(); inlineElement != nil; inlineElement = inlineElement.Prev() {
-					if d := inlineElement.Value.(*delimiter); d.Type == linkOpeningDelimiter {
+				// Links can't contain other links
+				for _, element := range p.brackets[p.inactiveLinks:] {
+					if d := element.Value.(*delimiter); d.Type == linkOpeningDelimiter && !d.IsInactive {
 						d.IsInactive = true
+						p.activeBrackets--
 					}
 				}
+				p.inactiveLinks = len(p.brackets)
 			}
-			p.delimiterStack.Remove(element)
 			return
 		}
-		p.delimiterStack.Remove(element)
+		p.popBracket()
 		break
 	}
 	absPos := relativeToAbsolutePosition(p.ranges, p.position)
@@ -424,6 +501,231 @@ func (p *inlineParser) lookForLinkOrImage() {
 	p.position++
 }
 
+// parseEmphasisDelimiter reads a run of asterisks, underscores or tildes which may open or close
+// emphasis, or a strikethrough for tildes, depending on the characters around it.
+func (p *inlineParser) parseEmphasisDelimiter() {
+	character := p.raw[p.position]
+	start := p.position
+	for p.position < len(p.raw) && p.raw[p.position] == character {
+		p.position++
+	}
+
+	absPos := relativeToAbsolutePosition(p.ranges, start)
+	p.inlines = append(p.inlines, &Text{
+		Text:  p.raw[start:p.position],
+		Range: Range{absPos, absPos + p.position - start},
+	})
+
+	// Only runs of one or two tildes can be used for a strikethrough
+	if character == '~' && p.position-start > 2 {
+		return
+	}
+
+	before, after := ' ', ' '
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.raw[:start])
+	}
+	if p.position < len(p.raw) {
+		after, _ = utf8.DecodeRuneInString(p.raw[p.position:])
+	}
+
+	isLeftFlanking := !unicode.IsSpace(after) && (!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+	isRightFlanking := !unicode.IsSpace(before) && (!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+	canOpen, canClose := isLeftFlanking, isRightFlanking
+	if character == '_' {
+		// Underscores can't be used for emphasis inside of words
+		canOpen = isLeftFlanking && (!isRightFlanking || isPunctuation(before))
+		canClose = isRightFlanking && (!isLeftFlanking || isPunctuation(after))
+	}
+	if !canOpen && !canClose {
+		return
+	}
+
+	p.delimiterStack.PushBack(&delimiter{
+		Type:           emphasisDelimiter,
+		TextNode:       len(p.inlines) - 1,
+		Range:          Range{start, p.position},
+		Character:      character,
+		Length:         p.position - start,
+		OriginalLength: p.position - start,
+		CanOpen:        canOpen,
+		CanClose:       canClose,
+	})
+}
+
+// matchesEmphasisOpener returns true if the closing delimiter can close emphasis started by the
+// opening one.
+func matchesEmphasisOpener(opener, closer *delimiter) bool {
+	if opener.Type != emphasisDelimiter || !opener.CanOpen || opener.Character != closer.Character {
+		return false
+	}
+
+	if closer.Character == '~' {
+		return opener.Length == closer.Length
+	}
+
+	// When either delimiter can both open and close emphasis, the sum of the lengths of their runs
+	// can't be a multiple of 3 unless both lengths are
+	if (opener.CanClose || closer.CanOpen) && (opener.OriginalLength+closer.OriginalLength)%3 == 0 {
+		return opener.OriginalLength%3 == 0 && closer.OriginalLength%3 == 0
+	}
+
+	return true
+}
+
+// openersBottomKey groups the closing delimiters which can match the same openers, so that the
+// search for an opener doesn't go back over the ones that already failed to match a closer like it.
+type openersBottomKey struct {
+	Character byte
+	CanOpen   bool
+	Length    int
+}
+
+func (d *delimiter) openersBottomKey() openersBottomKey {
+	if d.Character == '~' {
+		return openersBottomKey{Character: d.Character, Length: d.Length}
+	}
+	return openersBottomKey{Character: d.Character, CanOpen: d.CanOpen, Length: d.OriginalLength % 3}
+}
+
+// processEmphasis matches the emphasis delimiters above stackBottom, replacing the inlines between
+// each pair with emphasis or a strikethrough, and then removes them from the stack.
+func (p *inlineParser) processEmphasis(stackBottom *list.Element) {
+	first := p.delimiterStack.Front()
+	base := 0
+	if stackBottom != nil {
+		first = stackBottom.Next()
+		base = stackBottom.Value.(*delimiter).TextNode + 1
+	}
+
+	// The inlines are linked together while matching so that each match only touches the inlines
+	// between its delimiters, and the slice is rebuilt once at the end
+	inlines := list.New()
+	elements := make([]*list.Element, 0, len(p.inlines)-base)
+	for _, inline := range p.inlines[base:] {
+		elements = append(elements, inlines.PushBack(inline))
+	}
+	for element := first; element != nil; element = element.Next() {
+		if d := element.Value.(*delimiter); d.Type == emphasisDelimiter {
+			d.TextElement = elements[d.TextNode-base]
+		}
+	}
+
+	// Any opener at or below the position stored for a kind of closer is known not to match it
+	openersBottom := map[openersBottomKey]int{}
+
+	for closerElement := first; closerElement != nil; {
+		closer := closerElement.Value.(*delimiter)
+		if closer.Type != emphasisDelimiter || !closer.CanClose {
+			closerElement = closerElement.Next()
+			continue
+		}
+
+		key := closer.openersBottomKey()
+		bottom, ok := openersBottom[key]
+		if !ok {
+			bottom = -1
+		}
+
+		var openerElement *list.Element
+		for element := closerElement.Prev(); element != nil && element != stackBottom; element = element.Prev() {
+			opener := element.Value.(*delimiter)
+			if opener.Range.Position <= bottom {
+				break
+			}
+			if matchesEmphasisOpener(opener, closer) {
+				openerElement = element
+				break
+			}
+		}
+
+		if openerElement == nil {
+			openersBottom[key] = closer.Range.Position - 1
+
+			next := closerElement.Next()
+			if !closer.CanOpen {
+				p.delimiterStack.Remove(closerElement)
+			}
+			closerElement = next
+			continue
+		}
+
+		opener := openerElement.Value.(*delimiter)
+
+		used := 1
+		if closer.Character == '~' {
+			used = closer.Length
+		} else if opener.Length >= 2 && closer.Length >= 2 {
+			used = 2
+		}
+
+		var children []Inline
+		for element := opener.TextElement.Next(); element != closer.TextElement; {
+			next := element.Next()
+			children = append(children, inlines.Remove(element).(Inline))
+			element = next
+		}
+		children = MergeInlineText(children)
+
+		var inline Inline
+		switch {
+		case closer.Character == '~':
+			inline = &Strikethrough{Children: children}
+		case used == 2:
+			inline = &Strong{Children: children}
+		default:
+			inline = &Emphasis{Children: children}
+		}
+		inlines.InsertBefore(inline, closer.TextElement)
+
+		// The delimiters used are removed from the end of the opener and the start of the closer
+		openerText := opener.TextElement.Value.(*Text)
+		openerText.Text = openerText.Text[:len(openerText.Text)-used]
+		openerText.Range.End -= used
+		opener.Length -= used
+		if opener.Length == 0 {
+			inlines.Remove(opener.TextElement)
+		}
+
+		closerText := closer.TextElement.Value.(*Text)
+		closerText.Text = closerText.Text[used:]
+		closerText.Range.Position += used
+		closer.Length -= used
+		if closer.Length == 0 {
+			inlines.Remove(closer.TextElement)
+		}
+
+		// Delimiters between the opener and the closer can no longer match anything
+		for element := openerElement.Next(); element != closerElement; {
+			next := element.Next()
+			p.delimiterStack.Remove(element)
+			element = next
+		}
+		if opener.Length == 0 {
+			p.delimiterStack.Remove(openerElement)
+		}
+		if closer.Length == 0 {
+			next := closerElement.Next()
+			p.delimiterStack.Remove(closerElement)
+			closerElement = next
+		}
+	}
+
+	for element := first; element != nil; {
+		next := element.Next()
+		if element.Value.(*delimiter).Type == emphasisDelimiter {
+			p.delimiterStack.Remove(element)
+		}
+		element = next
+	}
+
+	p.inlines = p.inlines[:base]
+	for element := inlines.Front(); element != nil; element = element.Next() {
+		p.inlines = append(p.inlines, element.Value.(Inline))
+	}
+}
+
 func CharacterReference(ref string) string {
 	if ref == "" {
 		return ""
@@ -496,11 +798,8 @@ func (p *inlineParser) parseCharacterReference() {
 }
 
 func (p *inlineParser) parseAutolink(c rune) bool {
-	for element := p.delimiterStack.Back(); element != nil; element = element.Prev() {
-		d := element.Value.(*delimiter)
-		if !d.IsInactive {
-			return false
-		}
+	if p.activeBrackets > 0 {
+		return false
 	}
 
 	var link Range
@@ -579,6 +878,8 @@ func (p *inlineParser) Parse() []Inline {
 			p.parseCharacterReference()
 		case '!', '[':
 			p.parseLinkOrImageDelimiter()
+		case '*', '_', '~':
+			p.parseEmphasisDelimiter()
 		case ']':
 			p.lookForLinkOrImage()
 		case 'w', 'W':
@@ -604,6 +905,8 @@ func (p *inlineParser) Parse() []Inline {
 		}
 	}
 
+	p.processEmphasis(nil)
+
 	return p.inlines
 }
 
@@ -27,6 +27,12 @@ func Inspect(markdown string, f func(any) bool) {
 					return f(inline)
 				})
 			}
+		case *TableCell:
+			for _, inline := range MergeInlineText(v.ParseInlines(referenceDefinitions)) {
+				InspectInline(inline, func(inline Inline) bool {
+					return f(inline)
+				})
+			}
 		}
 		return true
 	})
@@ -73,6 +79,15 @@ func InspectBlock(block Block, f func(Block) bool) {
 			for i := len(v.Children) - 1; i >= 0; i-- {
 				stack = append(stack, v.Children[i])
 			}
+		case *Table:
+			for i := len(v.Rows) - 1; i >= 0; i-- {
+				stack = append(stack, v.Rows[i])
+			}
+			stack = append(stack, v.Header)
+		case *TableRow:
+			for i := len(v.Cells) - 1; i >= 0; i-- {
+				stack = append(stack, v.Cells[i])
+			}
 		}
 	}
 }
@@ -118,6 +133,18 @@ func InspectInline(inline Inline, f func(Inline) bool) {
 			for i := len(v.Children) - 1; i >= 0; i-- {
 				stack = append(stack, v.Children[i])
 			}
+		case *Emphasis:
+			for i := len(v.Children) - 1; i >= 0; i-- {
+				stack = append(stack, v.Children[i])
+			}
+		case *Strong:
+			for i := len(v.Children) - 1; i >= 0; i-- {
+				stack = append(stack, v.Children[i])
+			}
+		case *Strikethrough:
+			for i := len(v.Children) - 1; i >= 0; i-- {
+				stack = append(stack, v.Children[i])
+			}
 		}
 	}
 }
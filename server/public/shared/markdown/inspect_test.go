@@ -54,6 +54,47 @@ func TestInspect(t *testing.T) {
 		}, visited)
 	})
 
+	t.Run("github flavored extensions", func(t *testing.T) {
+		markdown := `
+| a | ~~b~~ |
+| - | ----- |
+| @c | d |
+- [x] e
+`
+
+		visited := []string{}
+		level := 0
+		Inspect(markdown, func(blockOrInline any) bool {
+			if blockOrInline == nil {
+				level--
+			} else {
+				visited = append(visited, strings.Repeat(" ", level*4)+strings.TrimPrefix(fmt.Sprintf("%T", blockOrInline), "*markdown."))
+				level++
+			}
+			return true
+		})
+
+		assert.Equal(t, []string{
+			"Document",
+			"    Table",
+			"        TableRow",
+			"            TableCell",
+			"                Text",
+			"            TableCell",
+			"                Strikethrough",
+			"                    Text",
+			"        TableRow",
+			"            TableCell",
+			"                Text",
+			"            TableCell",
+			"                Text",
+			"    List",
+			"        ListItem",
+			"            Paragraph",
+			"                Text",
+		}, visited)
+	})
+
 	t.Run("visit nodes when len is smaller than maxLen", func(t *testing.T) {
 		n := maxLen / 5
 		markdown := strings.Repeat(`![`, n) + strings.Repeat(`]()`, n)
@@ -13,8 +13,10 @@ type ListItem struct {
 	hasTrailingBlankLine        bool
 	hasBlankLineBetweenChildren bool
 
-	Indentation int
-	Children    []Block
+	Indentation    int
+	IsTaskListItem bool
+	IsChecked      bool
+	Children       []Block
 }
 
 func (b *ListItem) Continuation(indentation int, r Range) *continuation {
@@ -213,8 +215,35 @@ func listStart(markdown string, indent int, r Range, matchedBlocks, unmatchedBlo
 	}
 	ret := []Block{list, listItem}
 	if descendants := blockStartOrParagraph(markdown, indentAfterMarker-consumedIndentAfterMarker, remaining, nil, nil); descendants != nil {
+		if paragraph, ok := descendants[0].(*Paragraph); ok {
+			listItem.IsTaskListItem, listItem.IsChecked, paragraph.Text[0] = parseTaskListItemMarker(markdown, paragraph.Text[0])
+		}
 		listItem.Children = append(listItem.Children, descendants[0])
 		ret = append(ret, descendants...)
 	}
 	return ret
 }
+
+// parseTaskListItemMarker looks for a "[ ]" or "[x]" marker at the start of the first paragraph of
+// a list item and returns the text following it.
+func parseTaskListItemMarker(markdown string, r Range) (isTaskListItem, isChecked bool, remaining Range) {
+	s := markdown[r.Position:r.End]
+	if len(s) < 4 || s[0] != '[' || s[2] != ']' || (s[3] != ' ' && s[3] != '\t') {
+		return false, false, r
+	}
+
+	switch s[1] {
+	case ' ', '\t':
+	case 'x', 'X':
+		isChecked = true
+	default:
+		return false, false, r
+	}
+
+	remaining = trimLeftSpace(markdown, Range{r.Position + 3, r.End})
+	if remaining.Position == remaining.End {
+		return false, false, r
+	}
+
+	return true, isChecked, remaining
+}
@@ -9,6 +9,7 @@ package markdown
 
 import (
 	"strings"
+	"unicode"
 )
 
 func isEscapable(c rune) bool {
@@ -19,6 +20,12 @@ func isEscapableByte(c byte) bool {
 	return isEscapable(rune(c))
 }
 
+// isPunctuation returns true for ASCII punctuation characters and characters in the Unicode
+// punctuation categories
+func isPunctuation(c rune) bool {
+	return isEscapable(c) || unicode.IsPunct(c)
+}
+
 func isWhitespace(c rune) bool {
 	switch c {
 	case ' ', '\t', '\n', '\u000b', '\u000c', '\r':
@@ -108,7 +115,7 @@ func countIndentation(markdown string, r Range) (spaces, bytes int) {
 func trimLeftSpace(markdown string, r Range) Range {
 	s := markdown[r.Position:r.End]
 	trimmed := strings.TrimLeftFunc(s, isWhitespace)
-	return Range{r.Position, r.End - (len(s) - len(trimmed))}
+	return Range{r.Position + (len(s) - len(trimmed)), r.End}
 }
 
 func trimRightSpace(markdown string, r Range) Range {
@@ -132,6 +139,19 @@ func relativeToAbsolutePosition(ranges []Range, position int) int {
 	return ranges[len(ranges)-1].End
 }
 
+// relativeRangeEnd returns the position, relative to the concatenation of the ranges, at which the
+// range containing the given position ends.
+func relativeRangeEnd(ranges []Range, position int) int {
+	end := 0
+	for _, r := range ranges {
+		end += r.End - r.Position
+		if position < end {
+			return end
+		}
+	}
+	return end
+}
+
 func trimBytesFromRanges(ranges []Range, bytes int) (result []Range) {
 	rem := bytes
 	for _, r := range ranges {
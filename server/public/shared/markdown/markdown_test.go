@@ -0,0 +1,128 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrimSpace(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Markdown      string
+		Range         Range
+		ExpectedLeft  Range
+		ExpectedRight Range
+	}{
+		"no whitespace": {
+			Markdown:      "hello",
+			Range:         Range{0, 5},
+			ExpectedLeft:  Range{0, 5},
+			ExpectedRight: Range{0, 5},
+		},
+		"leading whitespace": {
+			Markdown:      "  \thello",
+			Range:         Range{0, 8},
+			ExpectedLeft:  Range{3, 8},
+			ExpectedRight: Range{0, 8},
+		},
+		"trailing whitespace": {
+			Markdown:      "hello \t ",
+			Range:         Range{0, 8},
+			ExpectedLeft:  Range{0, 8},
+			ExpectedRight: Range{0, 5},
+		},
+		"both": {
+			Markdown:      "  hello  ",
+			Range:         Range{0, 9},
+			ExpectedLeft:  Range{2, 9},
+			ExpectedRight: Range{0, 7},
+		},
+		"within a larger document": {
+			Markdown:      "ab  cd  ef",
+			Range:         Range{2, 8},
+			ExpectedLeft:  Range{4, 8},
+			ExpectedRight: Range{2, 6},
+		},
+		"only whitespace": {
+			Markdown:      "   ",
+			Range:         Range{0, 3},
+			ExpectedLeft:  Range{3, 3},
+			ExpectedRight: Range{0, 0},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.ExpectedLeft, trimLeftSpace(tc.Markdown, tc.Range))
+			assert.Equal(t, tc.ExpectedRight, trimRightSpace(tc.Markdown, tc.Range))
+		})
+	}
+}
+
+func TestParagraphRanges(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Markdown       string
+		ExpectedRanges []Range
+	}{
+		"leading whitespace is trimmed": {
+			Markdown:       "   hello",
+			ExpectedRanges: []Range{{3, 8}},
+		},
+		"trailing whitespace is kept until the last line": {
+			Markdown:       "hello  \nworld  ",
+			ExpectedRanges: []Range{{0, 8}, {8, 13}},
+		},
+		"indented continuation line": {
+			Markdown:       "hello\n   world",
+			ExpectedRanges: []Range{{0, 6}, {9, 14}},
+		},
+		"text after a reference definition": {
+			Markdown:       "[foo]: /url\n  bar",
+			ExpectedRanges: []Range{{14, 17}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			document, _ := Parse(tc.Markdown)
+			if assert.Len(t, document.Children, 1) {
+				paragraph, ok := document.Children[0].(*Paragraph)
+				if assert.True(t, ok) {
+					assert.Equal(t, tc.ExpectedRanges, paragraph.Text)
+				}
+			}
+		})
+	}
+}
+
+// pathologicalInlines are messages which used to take time quadratic in their length to parse and
+// render since every delimiter went back over all of the ones before it.
+var pathologicalInlines = map[string]string{
+	"alternating emphasis":        strings.Repeat("*_", 32000),
+	"unmatched openers":           strings.Repeat("*a _", 16000),
+	"emphasis before autolinks":   strings.Repeat("*w", 32000),
+	"emphasis in nested brackets": strings.Repeat("[*", 16000) + strings.Repeat("]", 16000),
+	"links after unclosed images": strings.Repeat("![", 16000) + strings.Repeat("[a](b)", 8000),
+}
+
+func TestPathologicalInlines(t *testing.T) {
+	for name, markdown := range pathologicalInlines {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			RenderHTML(markdown)
+			RenderPlainText(markdown)
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func BenchmarkPathologicalInlines(b *testing.B) {
+	for name, markdown := range pathologicalInlines {
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				RenderPlainText(markdown)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RenderPlainText produces human-readable text without any markdown syntax for places where
+// markdown can't be displayed, such as push notifications. Blocks are separated by line breaks,
+// links are replaced by their text and images by their alt text. Mentions and emojis are kept as
+// they are.
+func RenderPlainText(markdown string) string {
+	return strings.TrimSpace(RenderBlockPlainText(Parse(markdown)))
+}
+
+func RenderBlockPlainText(block Block, referenceDefinitions []*ReferenceDefinition) string {
+	switch v := block.(type) {
+	case *Document:
+		return renderChildrenPlainText(v.Children, referenceDefinitions)
+	case *BlockQuote:
+		return renderChildrenPlainText(v.Children, referenceDefinitions)
+	case *Paragraph:
+		return renderInlinesPlainText(v.ParseInlines(referenceDefinitions))
+	case *List:
+		items := make([]string, 0, len(v.Children))
+		for i, item := range v.Children {
+			marker := "- "
+			if v.IsOrdered {
+				marker = strconv.Itoa(v.OrderedStart+i) + string(v.BulletOrDelimiter) + " "
+			}
+			if item.IsTaskListItem {
+				if item.IsChecked {
+					marker += "[x] "
+				} else {
+					marker += "[ ] "
+				}
+			}
+
+			// Indent the following lines of an item to line up with its first one
+			text := renderChildrenPlainText(item.Children, referenceDefinitions)
+			text = strings.ReplaceAll(text, "\n", "\n"+strings.Repeat(" ", len(marker)))
+			items = append(items, marker+text)
+		}
+		return strings.Join(items, "\n")
+	case *FencedCode:
+		return strings.TrimSuffix(v.Code(), "\n")
+	case *IndentedCode:
+		return strings.TrimSuffix(v.Code(), "\n")
+	case *Table:
+		rows := make([]string, 0, len(v.Rows)+1)
+		for _, row := range append([]*TableRow{v.Header}, v.Rows...) {
+			cells := make([]string, 0, len(row.Cells))
+			for _, cell := range row.Cells {
+				cells = append(cells, renderInlinesPlainText(cell.ParseInlines(referenceDefinitions)))
+			}
+			rows = append(rows, strings.Join(cells, " | "))
+		}
+		return strings.Join(rows, "\n")
+	}
+	return ""
+}
+
+func renderChildrenPlainText(children []Block, referenceDefinitions []*ReferenceDefinition) string {
+	lines := make([]string, 0, len(children))
+	for _, child := range children {
+		if text := RenderBlockPlainText(child, referenceDefinitions); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderInlinesPlainText(inlines []Inline) string {
+	var result strings.Builder
+	writeInlinesPlainText(&result, inlines)
+	return result.String()
+}
+
+func RenderInlinePlainText(inline Inline) string {
+	var result strings.Builder
+	writeInlinePlainText(&result, inline)
+	return result.String()
+}
+
+func writeInlinesPlainText(result *strings.Builder, inlines []Inline) {
+	for _, inline := range inlines {
+		writeInlinePlainText(result, inline)
+	}
+}
+
+// writeInlinePlainText writes the text of an inline to result instead of returning it since
+// emphasis can be nested deeply enough that copying the text of the children at each level gets
+// expensive.
+func writeInlinePlainText(result *strings.Builder, inline Inline) {
+	switch v := inline.(type) {
+	case *Text:
+		result.WriteString(v.Text)
+	case *HardLineBreak, *SoftLineBreak:
+		result.WriteString("\n")
+	case *CodeSpan:
+		result.WriteString(v.Code)
+	case *InlineLink:
+		if text := renderInlinesPlainText(v.Children); text != "" {
+			result.WriteString(text)
+		} else {
+			result.WriteString(v.Destination())
+		}
+	case *ReferenceLink:
+		if text := renderInlinesPlainText(v.Children); text != "" {
+			result.WriteString(text)
+		} else {
+			result.WriteString(v.Destination())
+		}
+	case *Autolink:
+		writeInlinesPlainText(result, v.Children)
+	case *InlineImage:
+		result.WriteString(renderImageAltText(v.Children))
+	case *ReferenceImage:
+		result.WriteString(renderImageAltText(v.Children))
+	case *Emphasis:
+		writeInlinesPlainText(result, v.Children)
+	case *Strong:
+		writeInlinesPlainText(result, v.Children)
+	case *Strikethrough:
+		writeInlinesPlainText(result, v.Children)
+	case *Emoji:
+		result.WriteString(":" + v.Name + ":")
+	}
+}
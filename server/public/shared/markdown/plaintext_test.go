@@ -0,0 +1,90 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPlainText(t *testing.T) {
+	for name, tc := range map[string]struct {
+		Markdown string
+		Expected string
+	}{
+		"empty": {
+			Markdown: "",
+			Expected: "",
+		},
+		"plain text": {
+			Markdown: "This is plain text.\nHere is the next line.\n",
+			Expected: "This is plain text.\nHere is the next line.",
+		},
+		"emphasis": {
+			Markdown: "Bold with **asterisks** or __underscores__, *italics* and ~~strikethrough~~.",
+			Expected: "Bold with asterisks or underscores, italics and strikethrough.",
+		},
+		"mentions": {
+			Markdown: "Hey @user_name, please look at ~town-square with @all",
+			Expected: "Hey @user_name, please look at ~town-square with @all",
+		},
+		"emojis": {
+			Markdown: "Thanks :+1: :smile:",
+			Expected: "Thanks :+1: :smile:",
+		},
+		"escaped characters and entities": {
+			Markdown: `1 \* 2 &lt; 3 &amp; you & me`,
+			Expected: "1 * 2 < 3 & you & me",
+		},
+		"links": {
+			Markdown: "See [the docs](https://example.com/docs), [ref] and https://example.com\n\n[ref]: https://example.com/ref",
+			Expected: "See the docs, ref and https://example.com",
+		},
+		"link without text": {
+			Markdown: "[](https://example.com)",
+			Expected: "https://example.com",
+		},
+		"images": {
+			Markdown: "![a **cat**](https://example.com/cat.png) ![](https://example.com/dog.png)",
+			Expected: "a cat",
+		},
+		"code span": {
+			Markdown: "Run `make run` first",
+			Expected: "Run make run first",
+		},
+		"fenced code": {
+			Markdown: "Multiline\n```go\nfunc main() {\n  return\n}\n```\nafter",
+			Expected: "Multiline\nfunc main() {\n  return\n}\nafter",
+		},
+		"indented code": {
+			Markdown: "    code\n    block",
+			Expected: "code\nblock",
+		},
+		"block quote": {
+			Markdown: "> Hey quote.\n> Hello quote.\n\nReply",
+			Expected: "Hey quote.\nHello quote.\nReply",
+		},
+		"unordered list": {
+			Markdown: "* one\n* two\n  - nested\n",
+			Expected: "- one\n- two\n  - nested",
+		},
+		"ordered list": {
+			Markdown: "3. three\n4. four\n\n   more about four",
+			Expected: "3. three\n4. four\n   more about four",
+		},
+		"task list": {
+			Markdown: "- [x] done\n- [ ] to do",
+			Expected: "- [x] done\n- [ ] to do",
+		},
+		"table": {
+			Markdown: "| Name | Value |\n| --- | ---: |\n| **a** | 1 |\n| b | 2 |",
+			Expected: "Name | Value\na | 1\nb | 2",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, RenderPlainText(tc.Markdown))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package markdown
+
+import (
+	"strings"
+)
+
+type TableAlignment int
+
+const (
+	TableAlignmentNone TableAlignment = iota
+	TableAlignmentLeft
+	TableAlignmentCenter
+	TableAlignmentRight
+)
+
+type Table struct {
+	blockBase
+	markdown string
+
+	Alignments []TableAlignment
+	Header     *TableRow
+	Rows       []*TableRow
+}
+
+func (b *Table) Continuation(indentation int, r Range) *continuation {
+	s := b.markdown[r.Position:r.End]
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return &continuation{
+		Indentation: indentation,
+		Remaining:   r,
+	}
+}
+
+func (b *Table) AddLine(indentation int, r Range) bool {
+	s := b.markdown[r.Position:r.End]
+	if strings.TrimSpace(s) == "" {
+		return false
+	}
+	b.Rows = append(b.Rows, b.newRow(r, false))
+	return true
+}
+
+// newRow splits a line into cells, dropping those past the number of columns of the table and
+// adding empty ones for those that are missing.
+func (b *Table) newRow(r Range, isHeader bool) *TableRow {
+	row := &TableRow{}
+	for i, cell := range splitTableRow(b.markdown, r) {
+		if i >= len(b.Alignments) {
+			break
+		}
+		row.Cells = append(row.Cells, &TableCell{
+			markdown:  b.markdown,
+			Alignment: b.Alignments[i],
+			IsHeader:  isHeader,
+			Text:      cell,
+		})
+	}
+	for i := len(row.Cells); i < len(b.Alignments); i++ {
+		row.Cells = append(row.Cells, &TableCell{
+			markdown:  b.markdown,
+			Alignment: b.Alignments[i],
+			IsHeader:  isHeader,
+		})
+	}
+	return row
+}
+
+type TableRow struct {
+	blockBase
+
+	Cells []*TableCell
+}
+
+func (b *TableRow) Continuation(indentation int, r Range) *continuation {
+	return nil
+}
+
+type TableCell struct {
+	blockBase
+	markdown string
+
+	Alignment TableAlignment
+	IsHeader  bool
+	Text      []Range
+}
+
+func (b *TableCell) Continuation(indentation int, r Range) *continuation {
+	return nil
+}
+
+func (b *TableCell) ParseInlines(referenceDefinitions []*ReferenceDefinition) []Inline {
+	return ParseInlines(b.markdown, b.Text, referenceDefinitions)
+}
+
+// splitTableRow returns the content of each cell of a table row with surrounding whitespace
+// removed. Escaped pipes are left out of the ranges so that they're unescaped even inside of code
+// spans.
+func splitTableRow(markdown string, r Range) (cells [][]Range) {
+	r = trimRightSpace(markdown, trimLeftSpace(markdown, r))
+	if r.Position < r.End && markdown[r.Position] == '|' {
+		r.Position++
+	}
+
+	var cell []Range
+	start := r.Position
+	for i := r.Position; i < r.End; i++ {
+		switch markdown[i] {
+		case '\\':
+			if i+1 < r.End && markdown[i+1] == '|' {
+				cell = append(cell, Range{start, i})
+				start = i + 1
+			}
+			i++
+		case '|':
+			cells = append(cells, trimTableCell(markdown, append(cell, Range{start, i})))
+			cell = nil
+			start = i + 1
+		}
+	}
+
+	// A trailing pipe closes the last cell rather than starting a new one
+	if start < r.End || len(cells) == 0 {
+		cells = append(cells, trimTableCell(markdown, append(cell, Range{start, r.End})))
+	}
+
+	return cells
+}
+
+func trimTableCell(markdown string, cell []Range) []Range {
+	for len(cell) > 0 {
+		cell[0] = trimLeftSpace(markdown, cell[0])
+		if cell[0].Position < cell[0].End {
+			break
+		}
+		cell = cell[1:]
+	}
+	for len(cell) > 0 {
+		cell[len(cell)-1] = trimRightSpace(markdown, cell[len(cell)-1])
+		if cell[len(cell)-1].Position < cell[len(cell)-1].End {
+			break
+		}
+		cell = cell[:len(cell)-1]
+	}
+	return cell
+}
+
+// parseTableDelimiterRow parses the row separating the header of a table from its body, such as
+// "| :--- | :---: | ---: |", and returns the alignment of each column.
+func parseTableDelimiterRow(markdown string, r Range) []TableAlignment {
+	if !strings.Contains(markdown[r.Position:r.End], "|") {
+		return nil
+	}
+
+	var alignments []TableAlignment
+	for _, cell := range splitTableRow(markdown, r) {
+		if len(cell) != 1 {
+			return nil
+		}
+
+		s := markdown[cell[0].Position:cell[0].End]
+		left := strings.HasPrefix(s, ":")
+		right := strings.HasSuffix(s, ":")
+		dashes := strings.TrimSuffix(strings.TrimPrefix(s, ":"), ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil
+		}
+
+		switch {
+		case left && right:
+			alignments = append(alignments, TableAlignmentCenter)
+		case left:
+			alignments = append(alignments, TableAlignmentLeft)
+		case right:
+			alignments = append(alignments, TableAlignmentRight)
+		default:
+			alignments = append(alignments, TableAlignmentNone)
+		}
+	}
+	return alignments
+}
+
+// newTable returns a table if r is a delimiter row with as many columns as the header row.
+func newTable(markdown string, header, r Range) *Table {
+	alignments := parseTableDelimiterRow(markdown, r)
+	if alignments == nil || len(splitTableRow(markdown, header)) != len(alignments) {
+		return nil
+	}
+
+	table := &Table{
+		markdown:   markdown,
+		Alignments: alignments,
+	}
+	table.Header = table.newRow(header, true)
+	return table
+}
+
+// tableStartFromParagraph turns the last line of the paragraph into the header of a table if r is
+// a matching delimiter row. The paragraph is replaced by the table when nothing else remains of it.
+func tableStartFromParagraph(markdown string, r Range, container ContainerBlock, paragraph *Paragraph) (table *Table, closed []Block) {
+	table = newTable(markdown, paragraph.Text[len(paragraph.Text)-1], r)
+	if table == nil {
+		return nil, nil
+	}
+
+	if len(paragraph.Text) == 1 {
+		switch v := container.(type) {
+		case *Document:
+			v.Children[len(v.Children)-1] = table
+		case *BlockQuote:
+			v.Children[len(v.Children)-1] = table
+		case *ListItem:
+			v.Children[len(v.Children)-1] = table
+		default:
+			return nil, nil
+		}
+		return table, nil
+	}
+
+	paragraph.Text = paragraph.Text[:len(paragraph.Text)-1]
+	container.AddChild([]Block{table})
+	return table, []Block{paragraph}
+}
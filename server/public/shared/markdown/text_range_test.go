@@ -95,6 +95,21 @@ func TestTextRanges(t *testing.T) {
 			ExpectedRanges: []Range{{0, 13}},
 			ExpectedValues: []string{"Hello & World"},
 		},
+		"strikethrough": {
+			Markdown:       "hello ~~world~~",
+			ExpectedRanges: []Range{{0, 6}, {8, 13}},
+			ExpectedValues: []string{"hello ", "world"},
+		},
+		"table": {
+			Markdown:       "| a | b\\|c |\n| - | - |\n| d |",
+			ExpectedRanges: []Range{{2, 3}, {6, 7}, {8, 10}, {25, 26}},
+			ExpectedValues: []string{"a", "b", "|c", "d"},
+		},
+		"task list item": {
+			Markdown:       "- [x] hello",
+			ExpectedRanges: []Range{{6, 11}},
+			ExpectedValues: []string{"hello"},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			var ranges []Range